@@ -6,10 +6,13 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	wh "github.com/kubesphere/notification-manager/pkg/webhook"
 	"gopkg.in/alecthomas/kingpin.v2"
+	v1 "k8s.io/api/core/v1"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 )
@@ -42,6 +45,11 @@ var (
 		"Processing timeout for each incoming alerts or notifications",
 	).Default("30s").String()
 
+	shutdownTimeout = kingpin.Flag(
+		"webhook.shutdown-timeout",
+		"The maximum time to wait for the notifications being sent when shutting down",
+	).Default("30s").String()
+
 	wkrQueue = kingpin.Flag(
 		"worker.queue",
 		"Notification worker queue capacity",
@@ -52,6 +60,112 @@ var (
 		"notification manager namespaces",
 	).Default("").String()
 
+	tokenStore = kingpin.Flag(
+		"token.store",
+		fmt.Sprintf("Where to store the access tokens. Possible values: %s", strings.Join(tokenStores, ", ")),
+	).Default(tokenStoreMemory).String()
+
+	tokenSecret = kingpin.Flag(
+		"token.secret",
+		"The name of the secret used to store the access tokens, it is in the namespace which notification manager in",
+	).Default("notification-manager-tokens").String()
+
+	deadLetterSink = kingpin.Flag(
+		"deadletter.sink",
+		fmt.Sprintf("Where to save the notifications failed to send. Possible values: %s", strings.Join(deadLetterSinks, ", ")),
+	).Default(deadLetterSinkNone).String()
+
+	deadLetterWebhook = kingpin.Flag(
+		"deadletter.webhook",
+		"The url of the webhook which the failed notifications are posted to",
+	).Default("").String()
+
+	deadLetterConfigMap = kingpin.Flag(
+		"deadletter.configmap",
+		"The name of the configmap used to save the failed notifications, it is in the namespace which notification manager in",
+	).Default("notification-manager-deadletters").String()
+
+	deadLetterMaxSize = kingpin.Flag(
+		"deadletter.max-size",
+		"The maximum number of the failed notifications saved in the configmap",
+	).Default("100").Int()
+
+	tokenRefreshBefore = kingpin.Flag(
+		"token.refresh-before",
+		"The time before the access token expires to refresh it in background, the refreshing is disabled if it is 0",
+	).Default("5m").Duration()
+
+	tokenRefreshJitter = kingpin.Flag(
+		"token.refresh-jitter",
+		"The maximum random time to advance the refreshing of each access token, it avoids refreshing all tokens at the same time",
+	).Default("1m").Duration()
+
+	secretCacheTTL = kingpin.Flag(
+		"secret.cache-ttl",
+		"The time to live of the cached secrets, the cache is disabled if it is 0",
+	).Default("1m").Duration()
+
+	secretRefAllowList = kingpin.Flag(
+		"secret.ref-allow",
+		"The secret references, such as env://NAME or vault://path#key, allowed to be used by the configs out of the namespace of notification manager, in form of namespace=prefix, such as tenant-a=vault://secret/tenant-a/. It can be repeated",
+	).Strings()
+
+	vaultAddress = kingpin.Flag(
+		"vault.address",
+		"The address of the Vault, the secrets in form of vault://path#key are read from it",
+	).Default("").String()
+
+	vaultRole = kingpin.Flag(
+		"vault.role",
+		"The role used to log in the Vault with the kubernetes auth method, it is required if the Vault address is set",
+	).Default("").String()
+
+	vaultAuthPath = kingpin.Flag(
+		"vault.auth-path",
+		"The path of the kubernetes auth method of the Vault",
+	).Default(config.DefaultVaultAuthPath).String()
+
+	vaultTokenFile = kingpin.Flag(
+		"vault.token-file",
+		"The token file used to log in the Vault",
+	).Default(config.DefaultVaultTokenFile).String()
+
+	shortenerURL = kingpin.Flag(
+		"shortener.url",
+		"The url of the url shortener used by the template function shortURL, the shortening is disabled if it is empty",
+	).Default("").String()
+
+	shortenerSecret = kingpin.Flag(
+		"shortener.secret",
+		"The name of the secret which stores the token of the url shortener in the key token, it is in the namespace which notification manager in",
+	).Default("").String()
+
+	shortenerTimeout = kingpin.Flag(
+		"shortener.timeout",
+		"The timeout of shortening a url",
+	).Default("3s").Duration()
+
+	sendQueueSize = kingpin.Flag(
+		"send.queue-size",
+		"The maximum number of notifications waiting for the global send budget, the notifications beyond it are dropped",
+	).Default(strconv.Itoa(notifier.DefaultSendBudgetQueueSize)).Int()
+
+	paused = kingpin.Flag(
+		"paused",
+		"Pause the notifications at startup, the pause state stored in the pause configmap overrides it",
+	).Default("false").Bool()
+
+	pauseConfigMap = kingpin.Flag(
+		"pause.configmap",
+		"The name of the configmap which stores the pause state in the key paused, it is in the namespace which notification manager in",
+	).Default(config.DefaultPauseConfigMap).String()
+
+	adminTokenSecret = kingpin.Flag(
+		"admin.token-secret",
+		"The name of the secret which stores the bearer token of the admin endpoints such as POST /-/pause and POST /-/resume in the key token, "+
+			"it is in the namespace which notification manager in, the admin endpoints are disabled if it is empty",
+	).Default("").String()
+
 	logLevels = []string{
 		logLevelDebug,
 		logLevelInfo,
@@ -63,6 +177,17 @@ var (
 		logFormatLogfmt,
 		logFormatJson,
 	}
+
+	tokenStores = []string{
+		tokenStoreMemory,
+		tokenStoreSecret,
+	}
+
+	deadLetterSinks = []string{
+		deadLetterSinkNone,
+		deadLetterSinkWebhook,
+		deadLetterSinkConfigMap,
+	}
 )
 
 const (
@@ -72,6 +197,15 @@ const (
 	logLevelInfo    = "info"
 	logLevelWarn    = "warn"
 	logLevelError   = "error"
+
+	tokenStoreMemory = "memory"
+	tokenStoreSecret = "secret"
+
+	deadLetterSinkNone      = "none"
+	deadLetterSinkWebhook   = "webhook"
+	deadLetterSinkConfigMap = "configmap"
+
+	shortenerTokenKey = "token"
 )
 
 func Main() int {
@@ -97,6 +231,7 @@ func Main() int {
 
 	logger = log.With(logger, "ts", log.DefaultTimestamp)
 	logger = log.With(logger, "caller", log.DefaultCaller)
+	notifier.SetTemplateLogger(logger)
 	_ = level.Info(logger).Log("msg", "Starting notification manager...", "addr", *listenAddress, "timeout", *webhookTimeout)
 
 	ctxHttp, cancelHttp := context.WithCancel(context.Background())
@@ -107,20 +242,78 @@ func Main() int {
 	if cfg, err = config.New(ctxHttp, logger, *nmns); err != nil {
 		_ = level.Error(logger).Log("msg", "Failed to create notification manager config")
 	}
+	cfg.SetSecretCacheTTL(*secretCacheTTL)
+	cfg.SetPauseConfigMap(*pauseConfigMap)
+	// Set before syncing the config, so that the pause state stored in the configmap overrides it.
+	notifier.SetPaused(*paused)
+	if err := cfg.SetSecretRefAllowList(*secretRefAllowList); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s", err.Error())
+		return 1
+	}
+	if len(*vaultAddress) > 0 {
+		if len(*vaultRole) == 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "the vault role is empty, it is required to log in the vault")
+			return 1
+		}
+		cfg.RegisterSecretResolver(config.SecretSchemeVault, config.NewVaultResolver(*vaultAddress, *vaultRole, *vaultAuthPath, *vaultTokenFile))
+	}
 	// Sync notification manager config
 	if err := cfg.Run(); err != nil {
 		_ = level.Error(logger).Log("msg", "Failed to create sync notification manager config")
 	}
 
+	switch *tokenStore {
+	case tokenStoreMemory:
+	case tokenStoreSecret:
+		notifier.GetAccessTokenService().SetStore(notifier.NewSecretTokenStore(ctxHttp, cfg.GetClient(), os.Getenv("NAMESPACE"), *tokenSecret))
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "token store %v unknown, %v are possible values", *tokenStore, tokenStores)
+		return 1
+	}
+
+	notifier.GetAccessTokenService().SetRefreshJitter(*tokenRefreshJitter)
+	notifier.GetAccessTokenService().SetRefreshBefore(*tokenRefreshBefore, logger)
+	notifier.GetSendBudget().SetQueueSize(*sendQueueSize)
+
+	if len(*shortenerURL) > 0 {
+		getToken := func() (string, error) {
+			if len(*shortenerSecret) == 0 {
+				return "", nil
+			}
+			return cfg.GetSecretData(os.Getenv("NAMESPACE"), &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: *shortenerSecret},
+				Key:                  shortenerTokenKey,
+			})
+		}
+		notifier.SetURLShortener(notifier.NewURLShortener(*shortenerURL, getToken, *shortenerTimeout))
+	}
+
+	switch *deadLetterSink {
+	case deadLetterSinkNone:
+	case deadLetterSinkWebhook:
+		if len(*deadLetterWebhook) == 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "the url of dead letter webhook is empty")
+			return 1
+		}
+		notifier.SetDeadLetterSink(notifier.NewWebhookDeadLetterSink(*deadLetterWebhook))
+	case deadLetterSinkConfigMap:
+		notifier.SetDeadLetterSink(notifier.NewConfigMapDeadLetterSink(cfg.GetClient(), os.Getenv("NAMESPACE"), *deadLetterConfigMap, *deadLetterMaxSize))
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "dead letter sink %v unknown, %v are possible values", *deadLetterSink, deadLetterSinks)
+		return 1
+	}
+
 	// Setup webhook to receive alert/notification msg
 	webhook := wh.New(
 		logger,
 		cfg,
 		&wh.Options{
-			ListenAddress:  *listenAddress,
-			WebhookTimeout: *webhookTimeout,
-			WorkerTimeout:  *wkrTimeout,
-			WorkerQueue:    *wkrQueue,
+			ListenAddress:    *listenAddress,
+			WebhookTimeout:   *webhookTimeout,
+			WorkerTimeout:    *wkrTimeout,
+			WorkerQueue:      *wkrQueue,
+			ShutdownTimeout:  *shutdownTimeout,
+			AdminTokenSecret: *adminTokenSecret,
 		})
 
 	srvCh := make(chan error, 1)
@@ -24,6 +24,15 @@ import (
 type DingTalkReceiverSpec struct {
 	// WebhookConfig to be selected for this receiver
 	DingTalkConfigSelector *metav1.LabelSelector `json:"dingTalkConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The user ids to be mentioned in the chatbot message, the element can be a template which is rendered with the alerts,
+	// such as `{{ .CommonLabels.owner }}`, and the result can contain multiple users separated by comma.
+	MentionedUsers []string `json:"mentionedUsers,omitempty"`
+	// The mobiles to be mentioned in the chatbot message, the element can be a template as the MentionedUsers.
+	MentionedMobiles []string `json:"mentionedMobiles,omitempty"`
 }
 
 // DingTalkReceiverStatus defines the observed state of DingTalkReceiver
@@ -0,0 +1,64 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// DiscordConfigSpec defines the desired state of DiscordConfig
+type DiscordConfigSpec struct {
+	// The secret stores the url of the webhook, the url contains the token of the webhook.
+	Webhook *v1.SecretKeySelector `json:"webhook"`
+	// The secret stores the key which signs the image urls generated by the receivers. The signed url has
+	// the `expires` parameter, the unix time the url expires, and the last `signature` parameter, the hex encoded
+	// HMAC-SHA256 of the url before it. The render service such as Grafana does not verify the signature, so a proxy
+	// which verifies it and forwards the request with the credentials of the render service is required.
+	ImageRenderToken *v1.SecretKeySelector `json:"imageRenderToken,omitempty"`
+	// The time the signed image url is valid, it is one hour by default.
+	ImageURLExpires time.Duration `json:"imageURLExpires,omitempty"`
+}
+
+// DiscordConfigStatus defines the observed state of DiscordConfig
+type DiscordConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// DiscordConfig is the Schema for the discordconfigs API
+type DiscordConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DiscordConfigSpec   `json:"spec,omitempty"`
+	Status DiscordConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DiscordConfigList contains a list of DiscordConfig
+type DiscordConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiscordConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DiscordConfig{}, &DiscordConfigList{})
+}
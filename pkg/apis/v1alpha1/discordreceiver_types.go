@@ -0,0 +1,66 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DiscordReceiverSpec defines the desired state of DiscordReceiver
+type DiscordReceiverSpec struct {
+	// DiscordConfig to be selected for this receiver
+	DiscordConfigSelector *metav1.LabelSelector `json:"discordConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The type of message sent to the receiver, content or embed, default is content.
+	// +kubebuilder:validation:Enum=content;embed
+	Type string `json:"type,omitempty"`
+	// The template to generate the url of the image attached to the message, such as the url of a graph
+	// rendered by the Grafana render API from the labels of the alerts. The url is signed by the token of
+	// the render service if it is set in the config. No image is attached if it renders empty.
+	ImageURLTemplate string `json:"imageURLTemplate,omitempty"`
+}
+
+// DiscordReceiverStatus defines the observed state of DiscordReceiver
+type DiscordReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// DiscordReceiver is the Schema for the discordreceivers API
+type DiscordReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DiscordReceiverSpec   `json:"spec,omitempty"`
+	Status DiscordReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DiscordReceiverList contains a list of DiscordReceiver
+type DiscordReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiscordReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DiscordReceiver{}, &DiscordReceiverList{})
+}
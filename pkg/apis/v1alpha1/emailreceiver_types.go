@@ -26,6 +26,10 @@ type EmailReceiverSpec struct {
 	To []string `json:"to"`
 	// EmailConfig to be selected for this receiver
 	EmailConfigSelector *metav1.LabelSelector `json:"emailConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
 }
 
 // EmailReceiverStatus defines the observed state of EmailReceiver
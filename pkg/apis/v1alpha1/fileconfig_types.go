@@ -0,0 +1,59 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FileConfigSpec defines the desired state of FileConfig
+type FileConfigSpec struct {
+	// The path of the file which the notifications are appended to, the notifications are written to stdout if it is not set.
+	Path string `json:"path,omitempty"`
+	// The maximum size of the file in megabytes before it is rotated, the file is not rotated if it is 0.
+	MaxSize int `json:"maxSize,omitempty"`
+	// The maximum number of the rotated files to retain, default is 1.
+	MaxBackups int `json:"maxBackups,omitempty"`
+}
+
+// FileConfigStatus defines the observed state of FileConfig
+type FileConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// FileConfig is the Schema for the fileconfigs API
+type FileConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FileConfigSpec   `json:"spec,omitempty"`
+	Status FileConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FileConfigList contains a list of FileConfig
+type FileConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FileConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FileConfig{}, &FileConfigList{})
+}
@@ -0,0 +1,56 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GoogleChatConfigSpec defines the desired state of GoogleChatConfig
+type GoogleChatConfigSpec struct {
+	// The secret stores the url of the incoming webhook of the space.
+	Webhook *v1.SecretKeySelector `json:"webhook"`
+}
+
+// GoogleChatConfigStatus defines the observed state of GoogleChatConfig
+type GoogleChatConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// GoogleChatConfig is the Schema for the googlechatconfigs API
+type GoogleChatConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GoogleChatConfigSpec   `json:"spec,omitempty"`
+	Status GoogleChatConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GoogleChatConfigList contains a list of GoogleChatConfig
+type GoogleChatConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GoogleChatConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GoogleChatConfig{}, &GoogleChatConfigList{})
+}
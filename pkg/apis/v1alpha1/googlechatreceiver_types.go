@@ -0,0 +1,62 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GoogleChatReceiverSpec defines the desired state of GoogleChatReceiver
+type GoogleChatReceiverSpec struct {
+	// GoogleChatConfig to be selected for this receiver
+	GoogleChatConfigSelector *metav1.LabelSelector `json:"googleChatConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The type of message sent to the space, text or card, default is text.
+	// +kubebuilder:validation:Enum=text;card
+	MsgType string `json:"msgType,omitempty"`
+}
+
+// GoogleChatReceiverStatus defines the observed state of GoogleChatReceiver
+type GoogleChatReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// GoogleChatReceiver is the Schema for the googlechatreceivers API
+type GoogleChatReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GoogleChatReceiverSpec   `json:"spec,omitempty"`
+	Status GoogleChatReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GoogleChatReceiverList contains a list of GoogleChatReceiver
+type GoogleChatReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GoogleChatReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GoogleChatReceiver{}, &GoogleChatReceiverList{})
+}
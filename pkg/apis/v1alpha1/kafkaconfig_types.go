@@ -0,0 +1,69 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaConfigSpec defines the desired state of KafkaConfig
+type KafkaConfigSpec struct {
+	// The addresses of the brokers, such as kafka-0.kafka:9092.
+	Brokers []string `json:"brokers"`
+	// The TLS config used to connect to the brokers, TLS is disabled if it is not set.
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// The SASL authentication used to connect to the brokers.
+	SASL *KafkaSASL `json:"sasl,omitempty"`
+}
+
+type KafkaSASL struct {
+	// The SASL mechanism, default is PLAIN.
+	// +kubebuilder:validation:Enum=PLAIN;SCRAM-SHA-256;SCRAM-SHA-512
+	Mechanism string `json:"mechanism,omitempty"`
+	Username  string `json:"username"`
+	// The secret stores the password.
+	Password *v1.SecretKeySelector `json:"password"`
+}
+
+// KafkaConfigStatus defines the observed state of KafkaConfig
+type KafkaConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaConfig is the Schema for the kafkaconfigs API
+type KafkaConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaConfigSpec   `json:"spec,omitempty"`
+	Status KafkaConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaConfigList contains a list of KafkaConfig
+type KafkaConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaConfig{}, &KafkaConfigList{})
+}
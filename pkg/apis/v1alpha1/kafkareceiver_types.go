@@ -0,0 +1,65 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaReceiverSpec defines the desired state of KafkaReceiver
+type KafkaReceiverSpec struct {
+	// KafkaConfig to be selected for this receiver
+	KafkaConfigSelector *metav1.LabelSelector `json:"kafkaConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The topic which the alerts will be produced to.
+	Topic string `json:"topic"`
+	// The format of the message, json or text, default is json.
+	// The message of json format is the alert encoded in json, the message of text format is generated by the template.
+	// +kubebuilder:validation:Enum=json;text
+	Format string `json:"format,omitempty"`
+}
+
+// KafkaReceiverStatus defines the observed state of KafkaReceiver
+type KafkaReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaReceiver is the Schema for the kafkareceivers API
+type KafkaReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaReceiverSpec   `json:"spec,omitempty"`
+	Status KafkaReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaReceiverList contains a list of KafkaReceiver
+type KafkaReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaReceiver{}, &KafkaReceiverList{})
+}
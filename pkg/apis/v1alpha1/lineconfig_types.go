@@ -0,0 +1,58 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LineConfigSpec defines the desired state of LineConfig
+type LineConfigSpec struct {
+	// The LINE Notify API URL, default is https://notify-api.line.me.
+	APIURL string `json:"apiUrl,omitempty"`
+	// The secret stores the access token of LINE Notify.
+	Token *v1.SecretKeySelector `json:"token"`
+}
+
+// LineConfigStatus defines the observed state of LineConfig
+type LineConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// LineConfig is the Schema for the lineconfigs API
+type LineConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LineConfigSpec   `json:"spec,omitempty"`
+	Status LineConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LineConfigList contains a list of LineConfig
+type LineConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LineConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LineConfig{}, &LineConfigList{})
+}
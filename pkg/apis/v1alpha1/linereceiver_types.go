@@ -0,0 +1,66 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LineReceiverSpec defines the desired state of LineReceiver
+type LineReceiverSpec struct {
+	// LineConfig to be selected for this receiver
+	LineConfigSelector *metav1.LabelSelector `json:"lineConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The sticker sent with the message, it must be set together with the sticker id.
+	StickerPackageID int `json:"stickerPackageId,omitempty"`
+	StickerID        int `json:"stickerId,omitempty"`
+	// The url of the image thumbnail sent with the message, the image must be JPEG and up to 240x240px.
+	ImageThumbnail string `json:"imageThumbnail,omitempty"`
+	// The url of the full size image sent with the message, the image must be JPEG and up to 2048x2048px.
+	ImageFullsize string `json:"imageFullsize,omitempty"`
+}
+
+// LineReceiverStatus defines the observed state of LineReceiver
+type LineReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// LineReceiver is the Schema for the linereceivers API
+type LineReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LineReceiverSpec   `json:"spec,omitempty"`
+	Status LineReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LineReceiverList contains a list of LineReceiver
+type LineReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LineReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LineReceiver{}, &LineReceiverList{})
+}
@@ -0,0 +1,58 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MatrixConfigSpec defines the desired state of MatrixConfig
+type MatrixConfigSpec struct {
+	// The URL of the homeserver, such as https://matrix.org.
+	Homeserver string `json:"homeserver"`
+	// The secret stores the access token of the user which sends the message.
+	AccessToken *v1.SecretKeySelector `json:"accessToken"`
+}
+
+// MatrixConfigStatus defines the observed state of MatrixConfig
+type MatrixConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// MatrixConfig is the Schema for the matrixconfigs API
+type MatrixConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MatrixConfigSpec   `json:"spec,omitempty"`
+	Status MatrixConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MatrixConfigList contains a list of MatrixConfig
+type MatrixConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MatrixConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MatrixConfig{}, &MatrixConfigList{})
+}
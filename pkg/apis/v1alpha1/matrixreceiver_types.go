@@ -0,0 +1,61 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MatrixReceiverSpec defines the desired state of MatrixReceiver
+type MatrixReceiverSpec struct {
+	// MatrixConfig to be selected for this receiver
+	MatrixConfigSelector *metav1.LabelSelector `json:"matrixConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The ids of the rooms which the message will be sent to, the user must have joined the rooms.
+	RoomIDs []string `json:"roomIds"`
+}
+
+// MatrixReceiverStatus defines the observed state of MatrixReceiver
+type MatrixReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// MatrixReceiver is the Schema for the matrixreceivers API
+type MatrixReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MatrixReceiverSpec   `json:"spec,omitempty"`
+	Status MatrixReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MatrixReceiverList contains a list of MatrixReceiver
+type MatrixReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MatrixReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MatrixReceiver{}, &MatrixReceiverList{})
+}
@@ -87,6 +87,40 @@ type GlobalOptions struct {
 	// The name of the template to generate message.
 	// If the receiver dose not setup template, it will use this.
 	Template string `json:"template,omitempty"`
+	// The identical message sent to the same receiver within this window will be dropped.
+	// The dedup will be disabled if it is not set or is 0.
+	DedupWindow time.Duration `json:"dedupWindow,omitempty"`
+	// The external URL used by the templates to generate links, such as the URL of Grafana or Alertmanager.
+	// It can be got by the template function externalURL.
+	ExternalURL string `json:"externalURL,omitempty"`
+	// The header added to the beginning of each message, it can be a template, such as `cluster {{ .CommonLabels.cluster }}`.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it can be a template.
+	Footer string `json:"footer,omitempty"`
+	// The User-Agent header of the requests sent to the notification services, default is `notification-manager/<version>`.
+	UserAgent string `json:"userAgent,omitempty"`
+	// The maximum number of notifications sent by all notifiers per minute, it is unlimited if it is not set or is 0.
+	// The notifications exceeding it will wait, and will be dropped if too many notifications are waiting.
+	MaxSendsPerMinute int `json:"maxSendsPerMinute,omitempty"`
+	// The maximum number of idle connections kept for all hosts, default is 100.
+	MaxIdleConns int `json:"maxIdleConns,omitempty"`
+	// The maximum number of idle connections kept for each host, default is 2.
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"`
+	// The time an idle connection is kept before it is closed, default is 90s.
+	IdleConnTimeout time.Duration `json:"idleConnTimeout,omitempty"`
+	// The maximum number of labels and annotations rendered for each alert by the default templates,
+	// the omitted ones are indicated by the number of them. It is unlimited if it is not set or is 0.
+	// +kubebuilder:validation:Minimum=0
+	MaxLabelsPerAlert int `json:"maxLabelsPerAlert,omitempty"`
+	// The static labels which can be accessed by the templates as `{{ .ExternalLabels.cluster }}`,
+	// such as the cluster, the region or the environment the alerts come from.
+	ExternalLabels map[string]string `json:"externalLabels,omitempty"`
+	// The maximum percentage of the requests which can be retried within a sliding window of 10s, it is shared by all notifiers,
+	// such as 10 means 10% of the requests can be retried. The retries exceeding it are suppressed and the failures are
+	// returned immediately, except a few retries which are always allowed. It is unlimited if it is not set or is 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	RetryBudgetPercent int `json:"retryBudgetPercent,omitempty"`
 }
 
 type EmailOptions struct {
@@ -108,10 +142,45 @@ type WechatOptions struct {
 	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
 	// The name of the template to generate wechat message.
 	Template string `json:"template,omitempty"`
+	// The name of the template to generate wechat markdown message.
+	MarkdownTemplate string `json:"markdownTemplate,omitempty"`
+	// The name of the template to generate wechat news message,
+	// the template should generate a json array of articles which has the fields title, description, url and picurl.
+	NewsTemplate string `json:"newsTemplate,omitempty"`
+	// The name of the template to generate wechat template card message,
+	// the template should generate a json array of cards in the form of WeChat template card.
+	TemplateCardTemplate string `json:"templateCardTemplate,omitempty"`
 	// The maximum message size that can be sent in a request.
 	MessageMaxSize int `json:"messageMaxSize,omitempty"`
 	// The time of token expired.
 	TokenExpires time.Duration `json:"tokenExpires,omitempty"`
+	// The retry policy of sending message.
+	Retry *Retry `json:"retry,omitempty"`
+	// The rate limit of sending message, it works on each wechat application.
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// The circuit breaker of sending message, it works on each wechat application.
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+	// The maximum number of messages sent at the same time, it is unlimited if it is not set or is 0.
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+	// Send the messages of each receiver one by one in the order they are generated rather than concurrently,
+	// so that the messages arrive in order, such as the resolved message arrives after the firing message.
+	// It lowers the throughput of sending.
+	SerialDelivery bool `json:"serialDelivery,omitempty"`
+	// Whether to send the resolved alerts, default is true.
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// The mode to split the alerts into messages, size or alert. The size mode renders the alerts together and splits the text
+	// by size, the alert mode renders each alert independently and never splits an alert unless it is too large. Default is size.
+	// +kubebuilder:validation:Enum=size;alert
+	SplitMode string `json:"splitMode,omitempty"`
+	// Regroup the alerts by these labels, and each group is sent in its own messages,
+	// the alerts missing any of the labels are sent together.
+	GroupBy []string `json:"groupBy,omitempty"`
+	// Only render the messages and log them rather than sending them, it is used to validate the templates.
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 type SlackOptions struct {
@@ -128,6 +197,9 @@ type WebhookOptions struct {
 	// The name of the template to generate webhook message.
 	// If the global template is not set, it will use default.
 	Template string `json:"template,omitempty"`
+	// The retry policy of sending message, the request is retried when it failed because of
+	// network errors or server errors. It is not retried by default.
+	Retry *Retry `json:"retry,omitempty"`
 }
 
 // The config of flow control.
@@ -141,6 +213,88 @@ type Throttle struct {
 	MaxWaitTime time.Duration `json:"maxWaitTime,omitempty"`
 }
 
+// PriorityThrottle buffers the low priority alerts and sends them in one message,
+// the critical alerts are sent immediately.
+type PriorityThrottle struct {
+	// The label which the priority of alerts is derived from, default is severity.
+	PriorityLabel string `json:"priorityLabel,omitempty"`
+	// The priorities of the alerts sent immediately, default is critical.
+	CriticalPriorities []string `json:"criticalPriorities,omitempty"`
+	// The buffered alerts are sent when the window elapses, default is 5m.
+	Window time.Duration `json:"window,omitempty"`
+	// The buffered alerts are sent immediately when the number of them reaches the maximum, default is 100.
+	MaxAlerts int `json:"maxAlerts,omitempty"`
+}
+
+// QuietHours suppresses the alerts below the severity within the time ranges, such as the non-critical alerts at night.
+type QuietHours struct {
+	// The time ranges in form of `HH:MM-HH:MM`, such as `22:00-07:00`, the range crossing midnight is allowed.
+	Ranges []string `json:"ranges"`
+	// The time zone of the ranges, such as `Asia/Shanghai`, default is UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// The label which the severity of alerts is derived from, default is severity.
+	SeverityLabel string `json:"severityLabel,omitempty"`
+	// The alerts of this severity or higher are sent during the quiet hours, default is critical.
+	// The severities from low to high are info, warning, error and critical.
+	// +kubebuilder:validation:Enum=info;warning;error;critical
+	Severity string `json:"severity,omitempty"`
+}
+
+// Digest accumulates the alerts suppressed during the quiet hours, and sends them in one message on the schedule.
+type Digest struct {
+	// The times of day to send the digest in form of `HH:MM`, such as `08:00`.
+	// The digest is sent at the interval if it is not set.
+	At []string `json:"at,omitempty"`
+	// The time zone of the times, such as `Asia/Shanghai`, default is UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// The interval of sending the digest, default is 1h. It is ignored if the times are set.
+	Interval time.Duration `json:"interval,omitempty"`
+	// The name of the template to generate the digest, default is nm.default.digest.
+	Template string `json:"template,omitempty"`
+}
+
+// The config of retry.
+type Retry struct {
+	// The maximum times to retry after the first sending failed.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// The waiting time before the first retry, it grows exponentially with a random jitter.
+	Backoff time.Duration `json:"backoff,omitempty"`
+}
+
+// The config of rate limit.
+type RateLimit struct {
+	// The number of requests allowed per second.
+	RequestsPerSecond int `json:"requestsPerSecond"`
+	// The maximum number of requests allowed at once, default is the RequestsPerSecond.
+	Burst int `json:"burst,omitempty"`
+}
+
+// The config of circuit breaker.
+type CircuitBreaker struct {
+	// The number of consecutive failures to open the circuit.
+	FailureThreshold int `json:"failureThreshold"`
+	// The time to reject the sending after the circuit is opened, default is 1m.
+	Cooldown time.Duration `json:"cooldown,omitempty"`
+}
+
+// AlertSelector selects the alerts by the labels, the alerts must match all the requirements.
+type AlertSelector struct {
+	// The labels the alerts must have, the value is matched exactly.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// The requirements of the labels.
+	MatchExpressions []AlertSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+type AlertSelectorRequirement struct {
+	// The label key that the requirement applies to.
+	Key string `json:"key"`
+	// The relationship between the label and the values, Regex and NotRegex match the label value with the regular expressions.
+	// +kubebuilder:validation:Enum=In;NotIn;Exists;DoesNotExist;Regex;NotRegex
+	Operator string `json:"operator"`
+	// The values of In and NotIn, or the regular expressions of Regex and NotRegex.
+	Values []string `json:"values,omitempty"`
+}
+
 type DingTalkOptions struct {
 	// Notification Sending Timeout
 	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
@@ -157,15 +311,196 @@ type DingTalkOptions struct {
 	ChatBotThrottle *Throttle `json:"chatBotThrottle,omitempty"`
 	// The flow control fo conversation.
 	ConversationThrottle *Throttle `json:"conversationThrottle,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type TeamsOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate Teams message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The maximum message size that can be sent in a request.
+	MessageMaxSize int `json:"messageMaxSize,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type DiscordOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate Discord message content.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type PagerDutyOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate the summary of the event, the template is rendered with each alert.
+	Template string `json:"template,omitempty"`
+	// The label which the severity of the event comes from, default is severity.
+	SeverityLabel string `json:"severityLabel,omitempty"`
+}
+
+type OpsgenieOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate the message of the alert, the template is rendered with each alert.
+	Template string `json:"template,omitempty"`
+	// The name of the template to generate the description of the alert, the template is rendered with each alert.
+	DescriptionTemplate string `json:"descriptionTemplate,omitempty"`
+}
+
+type TelegramOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate telegram message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type SMSOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate SMS message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+}
+
+type MatrixOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate the body of matrix message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The name of the template to generate the HTML formatted body of matrix message,
+	// the message is sent without formatted body if it is not set.
+	HTMLTemplate string `json:"htmlTemplate,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type GoogleChatOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate google chat message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The maximum message size that can be sent in a request, the message will be split if it is too large.
+	MessageMaxSize int `json:"messageMaxSize,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type SNSOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate SNS message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type KafkaOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate the message of text format.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+}
+
+type LineOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate LINE message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type FileOptions struct {
+	// The name of the template to generate the notification written to the file.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+}
+
+type ZoomOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate the body of zoom message.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The name of the template to generate the head of zoom message.
+	TitleTemplate string `json:"titleTemplate,omitempty"`
+	// The maximum message size that can be sent in a request, the message will be split if it is too large.
+	MessageMaxSize int `json:"messageMaxSize,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
+}
+
+type RocketChatOptions struct {
+	// Notification Sending Timeout
+	NotificationTimeout *int32 `json:"notificationTimeout,omitempty"`
+	// The name of the template to generate Rocket.Chat message text.
+	// If the global template is not set, it will use default.
+	Template string `json:"template,omitempty"`
+	// The name of the template to generate the text of the attachment message.
+	TitleTemplate string `json:"titleTemplate,omitempty"`
+	// The maximum message size that can be sent in a request, the message will be split if it is too large.
+	MessageMaxSize int `json:"messageMaxSize,omitempty"`
+	// The header added to the beginning of each message, it overrides the global header.
+	Header string `json:"header,omitempty"`
+	// The footer added to the end of each message, it overrides the global footer.
+	Footer string `json:"footer,omitempty"`
 }
 
 type Options struct {
-	Global   *GlobalOptions   `json:"global,omitempty"`
-	Email    *EmailOptions    `json:"email,omitempty"`
-	Wechat   *WechatOptions   `json:"wechat,omitempty"`
-	Slack    *SlackOptions    `json:"slack,omitempty"`
-	Webhook  *WebhookOptions  `json:"webhook,omitempty"`
-	DingTalk *DingTalkOptions `json:"dingtalk,omitempty"`
+	Global     *GlobalOptions     `json:"global,omitempty"`
+	Email      *EmailOptions      `json:"email,omitempty"`
+	Wechat     *WechatOptions     `json:"wechat,omitempty"`
+	Slack      *SlackOptions      `json:"slack,omitempty"`
+	Webhook    *WebhookOptions    `json:"webhook,omitempty"`
+	DingTalk   *DingTalkOptions   `json:"dingtalk,omitempty"`
+	Teams      *TeamsOptions      `json:"teams,omitempty"`
+	Discord    *DiscordOptions    `json:"discord,omitempty"`
+	PagerDuty  *PagerDutyOptions  `json:"pagerduty,omitempty"`
+	Opsgenie   *OpsgenieOptions   `json:"opsgenie,omitempty"`
+	Telegram   *TelegramOptions   `json:"telegram,omitempty"`
+	SMS        *SMSOptions        `json:"sms,omitempty"`
+	Matrix     *MatrixOptions     `json:"matrix,omitempty"`
+	GoogleChat *GoogleChatOptions `json:"googleChat,omitempty"`
+	SNS        *SNSOptions        `json:"sns,omitempty"`
+	Kafka      *KafkaOptions      `json:"kafka,omitempty"`
+	Line       *LineOptions       `json:"line,omitempty"`
+	File       *FileOptions       `json:"file,omitempty"`
+	Zoom       *ZoomOptions       `json:"zoom,omitempty"`
+	RocketChat *RocketChatOptions `json:"rocketChat,omitempty"`
 }
 
 // NotificationManagerStatus defines the observed state of NotificationManager
@@ -0,0 +1,58 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpsgenieConfigSpec defines the desired state of OpsgenieConfig
+type OpsgenieConfigSpec struct {
+	// The Opsgenie API URL, default is https://api.opsgenie.com.
+	APIURL string `json:"apiUrl,omitempty"`
+	// The secret stores the API key of the Opsgenie integration.
+	APIKey *v1.SecretKeySelector `json:"apiKey"`
+}
+
+// OpsgenieConfigStatus defines the observed state of OpsgenieConfig
+type OpsgenieConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// OpsgenieConfig is the Schema for the opsgenieconfigs API
+type OpsgenieConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpsgenieConfigSpec   `json:"spec,omitempty"`
+	Status OpsgenieConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpsgenieConfigList contains a list of OpsgenieConfig
+type OpsgenieConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpsgenieConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpsgenieConfig{}, &OpsgenieConfigList{})
+}
@@ -0,0 +1,73 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type OpsgenieResponder struct {
+	// The type of the responder.
+	// +kubebuilder:validation:Enum=team;user;escalation;schedule
+	Type string `json:"type"`
+	// The id of the responder, either id or name must be specified.
+	ID string `json:"id,omitempty"`
+	// The name of the responder, it is the username if the type is user.
+	Name string `json:"name,omitempty"`
+}
+
+// OpsgenieReceiverSpec defines the desired state of OpsgenieReceiver
+type OpsgenieReceiverSpec struct {
+	// OpsgenieConfig to be selected for this receiver
+	OpsgenieConfigSelector *metav1.LabelSelector `json:"opsgenieConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The responders which the alert will be routed to.
+	Responders []OpsgenieResponder `json:"responders,omitempty"`
+	// The tags of the alert.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// OpsgenieReceiverStatus defines the observed state of OpsgenieReceiver
+type OpsgenieReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// OpsgenieReceiver is the Schema for the opsgeniereceivers API
+type OpsgenieReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpsgenieReceiverSpec   `json:"spec,omitempty"`
+	Status OpsgenieReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpsgenieReceiverList contains a list of OpsgenieReceiver
+type OpsgenieReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpsgenieReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpsgenieReceiver{}, &OpsgenieReceiverList{})
+}
@@ -0,0 +1,58 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PagerDutyConfigSpec defines the desired state of PagerDutyConfig
+type PagerDutyConfigSpec struct {
+	// The Events API v2 URL, default is https://events.pagerduty.com/v2/enqueue.
+	APIURL string `json:"apiUrl,omitempty"`
+	// The secret stores the integration key of the service.
+	RoutingKey *v1.SecretKeySelector `json:"routingKey"`
+}
+
+// PagerDutyConfigStatus defines the observed state of PagerDutyConfig
+type PagerDutyConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// PagerDutyConfig is the Schema for the pagerdutyconfigs API
+type PagerDutyConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PagerDutyConfigSpec   `json:"spec,omitempty"`
+	Status PagerDutyConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PagerDutyConfigList contains a list of PagerDutyConfig
+type PagerDutyConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PagerDutyConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PagerDutyConfig{}, &PagerDutyConfigList{})
+}
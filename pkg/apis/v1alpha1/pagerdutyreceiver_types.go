@@ -0,0 +1,59 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PagerDutyReceiverSpec defines the desired state of PagerDutyReceiver
+type PagerDutyReceiverSpec struct {
+	// PagerDutyConfig to be selected for this receiver
+	PagerDutyConfigSelector *metav1.LabelSelector `json:"pagerDutyConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// PagerDutyReceiverStatus defines the observed state of PagerDutyReceiver
+type PagerDutyReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// PagerDutyReceiver is the Schema for the pagerdutyreceivers API
+type PagerDutyReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PagerDutyReceiverSpec   `json:"spec,omitempty"`
+	Status PagerDutyReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PagerDutyReceiverList contains a list of PagerDutyReceiver
+type PagerDutyReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PagerDutyReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PagerDutyReceiver{}, &PagerDutyReceiverList{})
+}
@@ -0,0 +1,63 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RocketChatConfigSpec defines the desired state of RocketChatConfig
+type RocketChatConfigSpec struct {
+	// The secret stores the url of the incoming webhook, the url contains the token of the webhook.
+	// The message is sent through the webhook if it is set, otherwise it is sent by the chat.postMessage REST API.
+	Webhook *v1.SecretKeySelector `json:"webhook,omitempty"`
+	// The Rocket.Chat server URL used by the REST API, such as https://chat.example.com.
+	APIURL string `json:"apiUrl,omitempty"`
+	// The secret stores the id of the user which sends the message by the REST API.
+	UserID *v1.SecretKeySelector `json:"userId,omitempty"`
+	// The secret stores the personal access token of the user.
+	Token *v1.SecretKeySelector `json:"token,omitempty"`
+}
+
+// RocketChatConfigStatus defines the observed state of RocketChatConfig
+type RocketChatConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// RocketChatConfig is the Schema for the rocketchatconfigs API
+type RocketChatConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RocketChatConfigSpec   `json:"spec,omitempty"`
+	Status RocketChatConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RocketChatConfigList contains a list of RocketChatConfig
+type RocketChatConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RocketChatConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RocketChatConfig{}, &RocketChatConfigList{})
+}
@@ -0,0 +1,66 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RocketChatReceiverSpec defines the desired state of RocketChatReceiver
+type RocketChatReceiverSpec struct {
+	// RocketChatConfig to be selected for this receiver
+	RocketChatConfigSelector *metav1.LabelSelector `json:"rocketChatConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The channels or users which the messages are sent to, such as `#general` or `@admin`.
+	// It is required by the REST API, the default channel of the webhook is used if it is not set.
+	Channels []string `json:"channels,omitempty"`
+	// The type of message sent to the receiver, text or attachment, default is text.
+	// The attachment message has an attachment colored by the severity for each alert.
+	// +kubebuilder:validation:Enum=text;attachment
+	Type string `json:"type,omitempty"`
+}
+
+// RocketChatReceiverStatus defines the observed state of RocketChatReceiver
+type RocketChatReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// RocketChatReceiver is the Schema for the rocketchatreceivers API
+type RocketChatReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RocketChatReceiverSpec   `json:"spec,omitempty"`
+	Status RocketChatReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RocketChatReceiverList contains a list of RocketChatReceiver
+type RocketChatReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RocketChatReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RocketChatReceiver{}, &RocketChatReceiverList{})
+}
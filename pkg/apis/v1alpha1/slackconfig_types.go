@@ -19,12 +19,20 @@ package v1alpha1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
 )
 
 // SlackConfigSpec defines the desired state of SlackConfig
 type SlackConfigSpec struct {
 	// The token of user or bot.
 	SlackTokenSecret *v1.SecretKeySelector `json:"slackTokenSecret,omitempty"`
+	// The secret stores the key which signs the image urls generated by the receivers. The signed url has
+	// the `expires` parameter, the unix time the url expires, and the last `signature` parameter, the hex encoded
+	// HMAC-SHA256 of the url before it. The render service such as Grafana does not verify the signature, so a proxy
+	// which verifies it and forwards the request with the credentials of the render service is required.
+	ImageRenderToken *v1.SecretKeySelector `json:"imageRenderToken,omitempty"`
+	// The time the signed image url is valid, it is one hour by default.
+	ImageURLExpires time.Duration `json:"imageURLExpires,omitempty"`
 }
 
 // SlackConfigStatus defines the observed state of SlackConfig
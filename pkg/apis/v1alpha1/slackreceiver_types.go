@@ -24,8 +24,16 @@ import (
 type SlackReceiverSpec struct {
 	// SlackConfig to be selected for this receiver
 	SlackConfigSelector *metav1.LabelSelector `json:"slackConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
 	// The channel or user to send notifications to.
 	Channel string `json:"channel"`
+	// The template to generate the url of the image attached to the message, such as the url of a graph
+	// rendered by the Grafana render API from the labels of the alerts. The url is signed by the token of
+	// the render service if it is set in the config. No image is attached if it renders empty.
+	ImageURLTemplate string `json:"imageURLTemplate,omitempty"`
 }
 
 // SlackReceiverStatus defines the observed state of SlackReceiver
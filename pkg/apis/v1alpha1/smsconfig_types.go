@@ -0,0 +1,92 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type AliyunSMS struct {
+	// The endpoint of the SMS service, default is https://dysmsapi.aliyuncs.com.
+	Endpoint string `json:"endpoint,omitempty"`
+	// The name of the SMS signature.
+	SignName string `json:"signName"`
+	// The code of the SMS template.
+	TemplateCode string `json:"templateCode"`
+	// The name of the template parameter which the message will be filled in, default is content.
+	TemplateParam string `json:"templateParam,omitempty"`
+	// The secret stores the AccessKey ID.
+	AccessKeyID *v1.SecretKeySelector `json:"accessKeyId"`
+	// The secret stores the AccessKey secret.
+	AccessKeySecret *v1.SecretKeySelector `json:"accessKeySecret"`
+}
+
+type TencentSMS struct {
+	// The endpoint of the SMS service, default is https://sms.tencentcloudapi.com.
+	Endpoint string `json:"endpoint,omitempty"`
+	// The region of the SMS service, default is ap-guangzhou.
+	Region string `json:"region,omitempty"`
+	// The id of the SMS application.
+	SmsSdkAppID string `json:"smsSdkAppId"`
+	// The name of the SMS signature.
+	SignName string `json:"signName"`
+	// The id of the SMS template, the template should have only one parameter which the message will be filled in.
+	TemplateID string `json:"templateId"`
+	// The secret stores the SecretId.
+	SecretID *v1.SecretKeySelector `json:"secretId"`
+	// The secret stores the SecretKey.
+	SecretKey *v1.SecretKeySelector `json:"secretKey"`
+}
+
+// SMSConfigSpec defines the desired state of SMSConfig
+type SMSConfigSpec struct {
+	// The provider of the SMS service, such as aliyun or tencent.
+	Provider string `json:"provider"`
+	// The config of Aliyun SMS, it is required if the provider is aliyun.
+	Aliyun *AliyunSMS `json:"aliyun,omitempty"`
+	// The config of Tencent Cloud SMS, it is required if the provider is tencent.
+	Tencent *TencentSMS `json:"tencent,omitempty"`
+}
+
+// SMSConfigStatus defines the observed state of SMSConfig
+type SMSConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// SMSConfig is the Schema for the smsconfigs API
+type SMSConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SMSConfigSpec   `json:"spec,omitempty"`
+	Status SMSConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SMSConfigList contains a list of SMSConfig
+type SMSConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SMSConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SMSConfig{}, &SMSConfigList{})
+}
@@ -0,0 +1,61 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SMSReceiverSpec defines the desired state of SMSReceiver
+type SMSReceiverSpec struct {
+	// SMSConfig to be selected for this receiver
+	SMSConfigSelector *metav1.LabelSelector `json:"smsConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The phone numbers which the message will be sent to.
+	PhoneNumbers []string `json:"phoneNumbers"`
+}
+
+// SMSReceiverStatus defines the observed state of SMSReceiver
+type SMSReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// SMSReceiver is the Schema for the smsreceivers API
+type SMSReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SMSReceiverSpec   `json:"spec,omitempty"`
+	Status SMSReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SMSReceiverList contains a list of SMSReceiver
+type SMSReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SMSReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SMSReceiver{}, &SMSReceiverList{})
+}
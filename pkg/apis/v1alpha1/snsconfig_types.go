@@ -0,0 +1,62 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SNSConfigSpec defines the desired state of SNSConfig
+type SNSConfigSpec struct {
+	// The region of the SNS topic, such as us-east-1.
+	Region string `json:"region"`
+	// The endpoint of SNS API, default is https://sns.<region>.amazonaws.com.
+	Endpoint string `json:"endpoint,omitempty"`
+	// The secret stores the access key id, the credentials of the instance role are used if it is not set.
+	AccessKeyID *v1.SecretKeySelector `json:"accessKeyID,omitempty"`
+	// The secret stores the secret access key, it must be set together with the access key id.
+	SecretAccessKey *v1.SecretKeySelector `json:"secretAccessKey,omitempty"`
+}
+
+// SNSConfigStatus defines the observed state of SNSConfig
+type SNSConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// SNSConfig is the Schema for the snsconfigs API
+type SNSConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SNSConfigSpec   `json:"spec,omitempty"`
+	Status SNSConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SNSConfigList contains a list of SNSConfig
+type SNSConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SNSConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SNSConfig{}, &SNSConfigList{})
+}
@@ -0,0 +1,64 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SNSReceiverSpec defines the desired state of SNSReceiver
+type SNSReceiverSpec struct {
+	// SNSConfig to be selected for this receiver
+	SNSConfigSelector *metav1.LabelSelector `json:"snsConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The ARN of the topic which the message will be published to.
+	TopicARN string `json:"topicARN"`
+	// The labels of alerts sent as the message attributes, so they can be used by the filter policies of subscriptions.
+	// Only the labels shared by all alerts in a notification are sent.
+	AttributeLabels []string `json:"attributeLabels,omitempty"`
+}
+
+// SNSReceiverStatus defines the observed state of SNSReceiver
+type SNSReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// SNSReceiver is the Schema for the snsreceivers API
+type SNSReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SNSReceiverSpec   `json:"spec,omitempty"`
+	Status SNSReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SNSReceiverList contains a list of SNSReceiver
+type SNSReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SNSReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SNSReceiver{}, &SNSReceiverList{})
+}
@@ -0,0 +1,63 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TeamsReceiverSpec defines the desired state of TeamsReceiver
+type TeamsReceiverSpec struct {
+	// TeamsConfig to be selected for this receiver
+	TeamsConfigSelector *metav1.LabelSelector `json:"teamsConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The template to generate the url of the image attached to the message, such as the url of a graph
+	// rendered by the Grafana render API from the labels of the alerts. The url is signed by the token of
+	// the render service if it is set in the config. No image is attached if it renders empty.
+	ImageURLTemplate string `json:"imageURLTemplate,omitempty"`
+}
+
+// TeamsReceiverStatus defines the observed state of TeamsReceiver
+type TeamsReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// TeamsReceiver is the Schema for the teamsreceivers API
+type TeamsReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TeamsReceiverSpec   `json:"spec,omitempty"`
+	Status TeamsReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TeamsReceiverList contains a list of TeamsReceiver
+type TeamsReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TeamsReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TeamsReceiver{}, &TeamsReceiverList{})
+}
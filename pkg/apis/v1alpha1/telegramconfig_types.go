@@ -0,0 +1,58 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TelegramConfigSpec defines the desired state of TelegramConfig
+type TelegramConfigSpec struct {
+	// The Bot API URL, default is https://api.telegram.org.
+	APIURL string `json:"apiUrl,omitempty"`
+	// The secret stores the token of the bot.
+	BotToken *v1.SecretKeySelector `json:"botToken"`
+}
+
+// TelegramConfigStatus defines the observed state of TelegramConfig
+type TelegramConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// TelegramConfig is the Schema for the telegramconfigs API
+type TelegramConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TelegramConfigSpec   `json:"spec,omitempty"`
+	Status TelegramConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TelegramConfigList contains a list of TelegramConfig
+type TelegramConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TelegramConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TelegramConfig{}, &TelegramConfigList{})
+}
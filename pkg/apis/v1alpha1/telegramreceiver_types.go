@@ -0,0 +1,64 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TelegramReceiverSpec defines the desired state of TelegramReceiver
+type TelegramReceiverSpec struct {
+	// TelegramConfig to be selected for this receiver
+	TelegramConfigSelector *metav1.LabelSelector `json:"telegramConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The ids of the chats which the message will be sent to.
+	ChatIDs []string `json:"chatIds"`
+	// The parse mode of the message, HTML or MarkdownV2, the message is sent as plain text if it is not set.
+	// +kubebuilder:validation:Enum=HTML;MarkdownV2
+	ParseMode string `json:"parseMode,omitempty"`
+}
+
+// TelegramReceiverStatus defines the observed state of TelegramReceiver
+type TelegramReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// TelegramReceiver is the Schema for the telegramreceivers API
+type TelegramReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TelegramReceiverSpec   `json:"spec,omitempty"`
+	Status TelegramReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TelegramReceiverList contains a list of TelegramReceiver
+type TelegramReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TelegramReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TelegramReceiver{}, &TelegramReceiverList{})
+}
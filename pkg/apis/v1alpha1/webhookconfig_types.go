@@ -55,6 +55,8 @@ type HTTPClientConfig struct {
 	BearerToken *v1.SecretKeySelector `json:"bearerToken,omitempty"`
 	// HTTP proxy server to use to connect to the targets.
 	ProxyURL string `json:"proxyUrl,omitempty"`
+	// The HTTP basic authentication credentials for the proxy server.
+	ProxyAuth *BasicAuth `json:"proxyAuth,omitempty"`
 	// TLSConfig to use to connect to the targets.
 	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
 }
@@ -123,6 +125,38 @@ type WebhookConfigSpec struct {
 	Service *ServiceReference `json:"service,omitempty"`
 
 	HTTPConfig *HTTPClientConfig `json:"httpConfig,omitempty"`
+
+	// The HTTP method of the request, default is POST. The request body is not sent if the method is GET.
+	// +kubebuilder:validation:Enum=GET;POST;PUT;PATCH
+	Method string `json:"method,omitempty"`
+	// The headers added to the request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// The headers added to the request, the values of these headers are stored in secrets.
+	HeaderSecrets map[string]v1.SecretKeySelector `json:"headerSecrets,omitempty"`
+	// Sign the request body with HMAC, so the receiver can verify the request.
+	Signature *WebhookSignature `json:"signature,omitempty"`
+	// The template used to generate the request body, it is executed with the full template data and
+	// must produce a valid JSON, such as `{"alerts": {{ len .Alerts }}, "status": {{ toJson .Status }}}`.
+	// It takes precedence over the template in the options.
+	BodyTemplate string `json:"bodyTemplate,omitempty"`
+	// Rename the standard fields of the request body, such as `message: text`, the standard fields are status, receiver,
+	// labels, annotations, alerts and message. The labels and annotations are the common ones of the alerts, and the message
+	// is generated by the template of the options. The fields not in the mapping keep their names, and the fields mapped to
+	// an empty name are omitted. It is ignored if the body template is set.
+	FieldMapping map[string]string `json:"fieldMapping,omitempty"`
+	// Compress the request body with gzip, the receiver must support the gzip request body.
+	Compress bool `json:"compress,omitempty"`
+}
+
+// WebhookSignature defines how to sign the request body.
+type WebhookSignature struct {
+	// The secret key used to sign the request body.
+	Secret *v1.SecretKeySelector `json:"secret"`
+	// The header which the signature is set to, default is `X-NM-Signature`.
+	Header string `json:"header,omitempty"`
+	// The hash algorithm, default is sha256.
+	// +kubebuilder:validation:Enum=sha1;sha256
+	Algorithm string `json:"algorithm,omitempty"`
 }
 
 // WebhookConfigStatus defines the observed state of WebhookConfig
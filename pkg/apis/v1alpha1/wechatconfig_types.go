@@ -23,14 +23,47 @@ import (
 
 // WechatConfigSpec defines the desired state of WechatConfig
 type WechatConfigSpec struct {
-	// The WeChat API URL.
+	// The WeChat API URL, it can contain the environment variables in form of ${ENV_VAR}. The variables used out of
+	// the namespace of notification manager must be allowed in form of `env://ENV_VAR` by the flag `--secret.ref-allow`.
 	WechatApiUrl string `json:"wechatApiUrl,omitempty"`
-	// The corp id for authentication.
+	// The corp id for authentication, it can be a secret reference in form of `secret://name#key`,
+	// `env://NAME` or `vault://path#key`. The `env` and `vault` references used out of the namespace of
+	// notification manager must be allowed by the flag `--secret.ref-allow`.
 	WechatApiCorpId string `json:"wechatApiCorpId"`
-	// The id of the application which sending message.
+	// The id of the application which sending message, it can be a secret reference like the corp id.
 	WechatApiAgentId string `json:"wechatApiAgentId"`
 	// The API key to use when talking to the WeChat API.
 	WechatApiSecret *v1.SecretKeySelector `json:"wechatApiSecret"`
+	// HTTP proxy server to use to connect to the WeChat API.
+	ProxyURL string `json:"proxyUrl,omitempty"`
+	// The HTTP basic authentication credentials for the proxy server.
+	ProxyAuth *BasicAuth `json:"proxyAuth,omitempty"`
+	// TLSConfig to use to connect to the WeChat API.
+	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
+	// The path relative to the API URL to send the message, default is message/send.
+	// It is used when the message is sent through a relay which exposes a different path.
+	SendPath string `json:"sendPath,omitempty"`
+	// The path relative to the API URL to get the access token, default is gettoken.
+	TokenPath string `json:"tokenPath,omitempty"`
+	// The errcode in the response which means the message is sent successfully, default is 0.
+	// It is used when the relay responds a different errcode when succeed.
+	SuccessCodes []int `json:"successCodes,omitempty"`
+	// The relays which the messages are sent through, a relay is chosen for each message by weighted round-robin,
+	// and the next relay is tried if the sending fails. The relays whose circuit breaker is open are skipped.
+	// The access token and media are requested from the WeChat API URL, or the first relay if it is not set.
+	WechatApiUrls []WechatRelay `json:"wechatApiUrls,omitempty"`
+	// Compress the message with gzip when it is sent through the relays or the WeChat API URL which is not the
+	// official one, the relays must support the gzip request body. The official WeChat API is never compressed.
+	Compress bool `json:"compress,omitempty"`
+}
+
+// WechatRelay is the WeChat API URL exposed by a relay.
+type WechatRelay struct {
+	// The API URL of the relay, it can contain the environment variables in form of ${ENV_VAR} like the WeChat API URL.
+	URL string `json:"url"`
+	// The weight of the relay, default is 1.
+	// +kubebuilder:validation:Minimum=0
+	Weight int `json:"weight,omitempty"`
 }
 
 // WechatConfigStatus defines the observed state of WechatConfig
@@ -17,18 +17,107 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
 )
 
 // WechatReceiverSpec defines the desired state of WechatReceiver
 type WechatReceiverSpec struct {
 	// WechatConfig to be selected for this receiver
 	WechatConfigSelector *metav1.LabelSelector `json:"wechatConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
 	// +optional
 	ToUser string `json:"toUser,omitempty"`
 
 	ToParty string `json:"toParty,omitempty"`
 	ToTag   string `json:"toTag,omitempty"`
+	// The template to generate the tags from the alerts, such as `{{ .CommonLabels.team }}`, multiple tags are separated by `|`.
+	// It is rendered with each alert, the alerts with the same tags are sent together, and the alerts are sent
+	// to the tags of ToTag if the result is empty.
+	ToTagTemplate string `json:"toTagTemplate,omitempty"`
+	// The id of the application chat, the message will be sent to the chat rather than users, parties and tags if it is set.
+	ChatID string `json:"chatId,omitempty"`
+	// Whether the message is confidential, the confidential message can not be forwarded or copied.
+	Confidential bool `json:"confidential,omitempty"`
+	// Whether to enable the duplicate check of WeChat, the duplicate message will not be sent within the interval.
+	EnableDuplicateCheck bool `json:"enableDuplicateCheck,omitempty"`
+	// The interval of the duplicate check in seconds, default is 1800, maximum is 14400.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=14400
+	DuplicateCheckInterval int `json:"duplicateCheckInterval,omitempty"`
+	// Whether the duplicate check is keyed by the fingerprints and the status of the alerts rather than the content.
+	// The content sent first is reused when the same alerts are sent again within the interval,
+	// so that WeChat treats the re-fired alerts as duplicates even if the content changes slightly.
+	// It takes effect only if the duplicate check is enabled.
+	DuplicateCheckByFingerprint bool `json:"duplicateCheckByFingerprint,omitempty"`
+	// The users to be mentioned in the markdown message, the element can be a template which is rendered with the alerts,
+	// such as `{{ .CommonLabels.owner }}`, and the result can contain multiple users separated by comma.
+	MentionedUsers []string `json:"mentionedUsers,omitempty"`
+	// The annotation used as the title of the text or markdown message, such as summary. The title is the first line
+	// of the message, and is bold in the markdown message. The alertname is used if the alerts do not have the annotation.
+	TitleAnnotation string `json:"titleAnnotation,omitempty"`
+	// The type of message sent to the receiver, text, markdown, news, image, file or template_card, default is text.
+	// +kubebuilder:validation:Enum=text;markdown;news;image;file;template_card
+	MsgType string `json:"msgType,omitempty"`
+	// The name of the template to generate the message of this receiver,
+	// it overrides the template of the wechat options.
+	Template string `json:"template,omitempty"`
+	// The media sent in the image or file message.
+	Media *WechatMedia `json:"media,omitempty"`
+	// Route the alerts to different applications or recipients by the severity label of alerts,
+	// the key is the severity, such as critical. The alerts not matching any route are sent by the default config.
+	SeverityRouting map[string]WechatRoute `json:"severityRouting,omitempty"`
+	// Buffer the low priority alerts and send them in one message when the window elapses.
+	Throttle *PriorityThrottle `json:"throttle,omitempty"`
+	// Suppress the low severity alerts during the quiet hours.
+	QuietHours *QuietHours `json:"quietHours,omitempty"`
+	// Send the alerts suppressed during the quiet hours in one message on the schedule, the suppressed alerts are dropped if it is not set.
+	Digest *Digest `json:"digest,omitempty"`
+	// The timeout of each request sent to the receiver, it overrides the notification timeout of the wechat options,
+	// such as for the receiver behind a slow relay.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// The maximum number of alerts in a message, the alerts exceeding it are sent in the following messages.
+	// It is not limited if it is 0.
+	// +kubebuilder:validation:Minimum=0
+	MaxAlertsPerMessage int `json:"maxAlertsPerMessage,omitempty"`
+	// A summary message generated by the nm.default.summary template is sent rather than the alerts if the number of
+	// alerts in a group exceeds the threshold, such as `247 alerts firing in namespace X`. It is disabled if it is 0.
+	// +kubebuilder:validation:Minimum=0
+	SummaryThreshold int `json:"summaryThreshold,omitempty"`
+	// The notifications received within the delay are buffered and merged into one message,
+	// so that the near-simultaneous notifications of the receiver are sent together. It is disabled if it is 0.
+	CoalesceDelay time.Duration `json:"coalesceDelay,omitempty"`
+	// The buffered alerts are sent immediately when the number of them reaches the maximum, default is 100.
+	// +kubebuilder:validation:Minimum=0
+	CoalesceMaxAlerts int `json:"coalesceMaxAlerts,omitempty"`
+}
+
+// WechatMedia is the source of the media, either URL or Secret must be set.
+type WechatMedia struct {
+	// The URL which the media is downloaded from.
+	URL string `json:"url,omitempty"`
+	// The secret which the content of the media is stored in.
+	Secret *v1.SecretKeySelector `json:"secret,omitempty"`
+	// The file name of the media, the name must have the extension of the media type, such as png.
+	// The last element of the URL path or the key of secret is used if it is not set.
+	FileName string `json:"fileName,omitempty"`
+}
+
+// WechatRoute is the application and recipients which the alerts of a severity are sent to.
+type WechatRoute struct {
+	// The agent id of the application, the agent id of the wechat config is used if it is not set.
+	AgentID string `json:"agentId,omitempty"`
+	// The secret of the application, it must be set if the agent id is different from the wechat config.
+	APISecret *v1.SecretKeySelector `json:"apiSecret,omitempty"`
+	// The recipients of the alerts, the recipients of the receiver are used if none of them is set.
+	ToUser  string `json:"toUser,omitempty"`
+	ToParty string `json:"toParty,omitempty"`
+	ToTag   string `json:"toTag,omitempty"`
+	ChatID  string `json:"chatId,omitempty"`
 }
 
 // WechatReceiverStatus defines the observed state of WechatReceiver
@@ -0,0 +1,66 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ZoomConfigSpec defines the desired state of ZoomConfig
+type ZoomConfigSpec struct {
+	// The Zoom API URL, default is https://api.zoom.us.
+	APIURL string `json:"apiUrl,omitempty"`
+	// The Zoom OAuth URL which the access token is requested from, default is https://zoom.us.
+	OAuthURL string `json:"oauthUrl,omitempty"`
+	// The client id of the Zoom chatbot app.
+	ClientID string `json:"clientId"`
+	// The secret stores the client secret of the Zoom chatbot app.
+	ClientSecret *v1.SecretKeySelector `json:"clientSecret"`
+	// The JID of the chatbot, it is shown as Bot JID in the app.
+	RobotJID string `json:"robotJid"`
+	// The id of the account which the chatbot is installed in.
+	AccountID string `json:"accountId"`
+}
+
+// ZoomConfigStatus defines the observed state of ZoomConfig
+type ZoomConfigStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// ZoomConfig is the Schema for the zoomconfigs API
+type ZoomConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ZoomConfigSpec   `json:"spec,omitempty"`
+	Status ZoomConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ZoomConfigList contains a list of ZoomConfig
+type ZoomConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ZoomConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ZoomConfig{}, &ZoomConfigList{})
+}
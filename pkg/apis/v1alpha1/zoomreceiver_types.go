@@ -0,0 +1,61 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ZoomReceiverSpec defines the desired state of ZoomReceiver
+type ZoomReceiverSpec struct {
+	// ZoomConfig to be selected for this receiver
+	ZoomConfigSelector *metav1.LabelSelector `json:"zoomConfigSelector,omitempty"`
+	// Only the alerts matching the selector are sent to the receiver, all alerts are sent if it is not set.
+	AlertSelector *AlertSelector `json:"alertSelector,omitempty"`
+	// Whether the receiver is enabled, the disabled receiver is kept but nothing is sent to it, default is true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// The JIDs of the channels or users which the messages are sent to.
+	ToJIDs []string `json:"toJids"`
+}
+
+// ZoomReceiverStatus defines the observed state of ZoomReceiver
+type ZoomReceiverStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// ZoomReceiver is the Schema for the zoomreceivers API
+type ZoomReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ZoomReceiverSpec   `json:"spec,omitempty"`
+	Status ZoomReceiverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ZoomReceiverList contains a list of ZoomReceiver
+type ZoomReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ZoomReceiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ZoomReceiver{}, &ZoomReceiverList{})
+}
@@ -26,6 +26,80 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertSelector) DeepCopyInto(out *AlertSelector) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]AlertSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSelector.
+func (in *AlertSelector) DeepCopy() *AlertSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertSelectorRequirement) DeepCopyInto(out *AlertSelectorRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSelectorRequirement.
+func (in *AlertSelectorRequirement) DeepCopy() *AlertSelectorRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertSelectorRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AliyunSMS) DeepCopyInto(out *AliyunSMS) {
+	*out = *in
+	if in.AccessKeyID != nil {
+		in, out := &in.AccessKeyID, &out.AccessKeyID
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessKeySecret != nil {
+		in, out := &in.AccessKeySecret, &out.AccessKeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AliyunSMS.
+func (in *AliyunSMS) DeepCopy() *AliyunSMS {
+	if in == nil {
+		return nil
+	}
+	out := new(AliyunSMS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
 	*out = *in
@@ -46,6 +120,21 @@ func (in *BasicAuth) DeepCopy() *BasicAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreaker) DeepCopyInto(out *CircuitBreaker) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreaker.
+func (in *CircuitBreaker) DeepCopy() *CircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClientCertificate) DeepCopyInto(out *ClientCertificate) {
 	*out = *in
@@ -71,6 +160,26 @@ func (in *ClientCertificate) DeepCopy() *ClientCertificate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Digest) DeepCopyInto(out *Digest) {
+	*out = *in
+	if in.At != nil {
+		in, out := &in.At, &out.At
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Digest.
+func (in *Digest) DeepCopy() *Digest {
+	if in == nil {
+		return nil
+	}
+	out := new(Digest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DingTalkChatBot) DeepCopyInto(out *DingTalkChatBot) {
 	*out = *in
@@ -322,6 +431,26 @@ func (in *DingTalkReceiverSpec) DeepCopyInto(out *DingTalkReceiverSpec) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MentionedUsers != nil {
+		in, out := &in.MentionedUsers, &out.MentionedUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MentionedMobiles != nil {
+		in, out := &in.MentionedMobiles, &out.MentionedMobiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DingTalkReceiverSpec.
@@ -350,7 +479,7 @@ func (in *DingTalkReceiverStatus) DeepCopy() *DingTalkReceiverStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailConfig) DeepCopyInto(out *EmailConfig) {
+func (in *DiscordConfig) DeepCopyInto(out *DiscordConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -358,18 +487,18 @@ func (in *EmailConfig) DeepCopyInto(out *EmailConfig) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfig.
-func (in *EmailConfig) DeepCopy() *EmailConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordConfig.
+func (in *DiscordConfig) DeepCopy() *DiscordConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailConfig)
+	out := new(DiscordConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EmailConfig) DeepCopyObject() runtime.Object {
+func (in *DiscordConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -377,31 +506,31 @@ func (in *EmailConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailConfigList) DeepCopyInto(out *EmailConfigList) {
+func (in *DiscordConfigList) DeepCopyInto(out *DiscordConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EmailConfig, len(*in))
+		*out = make([]DiscordConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfigList.
-func (in *EmailConfigList) DeepCopy() *EmailConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordConfigList.
+func (in *DiscordConfigList) DeepCopy() *DiscordConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailConfigList)
+	out := new(DiscordConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EmailConfigList) DeepCopyObject() runtime.Object {
+func (in *DiscordConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -409,68 +538,47 @@ func (in *EmailConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailConfigSpec) DeepCopyInto(out *EmailConfigSpec) {
+func (in *DiscordConfigSpec) DeepCopyInto(out *DiscordConfigSpec) {
 	*out = *in
-	out.SmartHost = in.SmartHost
-	if in.Hello != nil {
-		in, out := &in.Hello, &out.Hello
-		*out = new(string)
-		**out = **in
-	}
-	if in.AuthUsername != nil {
-		in, out := &in.AuthUsername, &out.AuthUsername
-		*out = new(string)
-		**out = **in
-	}
-	if in.AuthIdentify != nil {
-		in, out := &in.AuthIdentify, &out.AuthIdentify
-		*out = new(string)
-		**out = **in
-	}
-	if in.AuthPassword != nil {
-		in, out := &in.AuthPassword, &out.AuthPassword
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.AuthSecret != nil {
-		in, out := &in.AuthSecret, &out.AuthSecret
+	if in.ImageRenderToken != nil {
+		in, out := &in.ImageRenderToken, &out.ImageRenderToken
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.RequireTLS != nil {
-		in, out := &in.RequireTLS, &out.RequireTLS
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfigSpec.
-func (in *EmailConfigSpec) DeepCopy() *EmailConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordConfigSpec.
+func (in *DiscordConfigSpec) DeepCopy() *DiscordConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailConfigSpec)
+	out := new(DiscordConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailConfigStatus) DeepCopyInto(out *EmailConfigStatus) {
+func (in *DiscordConfigStatus) DeepCopyInto(out *DiscordConfigStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfigStatus.
-func (in *EmailConfigStatus) DeepCopy() *EmailConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordConfigStatus.
+func (in *DiscordConfigStatus) DeepCopy() *DiscordConfigStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailConfigStatus)
+	out := new(DiscordConfigStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailOptions) DeepCopyInto(out *EmailOptions) {
+func (in *DiscordOptions) DeepCopyInto(out *DiscordOptions) {
 	*out = *in
 	if in.NotificationTimeout != nil {
 		in, out := &in.NotificationTimeout, &out.NotificationTimeout
@@ -479,18 +587,18 @@ func (in *EmailOptions) DeepCopyInto(out *EmailOptions) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailOptions.
-func (in *EmailOptions) DeepCopy() *EmailOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordOptions.
+func (in *DiscordOptions) DeepCopy() *DiscordOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailOptions)
+	out := new(DiscordOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailReceiver) DeepCopyInto(out *EmailReceiver) {
+func (in *DiscordReceiver) DeepCopyInto(out *DiscordReceiver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -498,18 +606,18 @@ func (in *EmailReceiver) DeepCopyInto(out *EmailReceiver) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiver.
-func (in *EmailReceiver) DeepCopy() *EmailReceiver {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordReceiver.
+func (in *DiscordReceiver) DeepCopy() *DiscordReceiver {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailReceiver)
+	out := new(DiscordReceiver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EmailReceiver) DeepCopyObject() runtime.Object {
+func (in *DiscordReceiver) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -517,31 +625,31 @@ func (in *EmailReceiver) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailReceiverList) DeepCopyInto(out *EmailReceiverList) {
+func (in *DiscordReceiverList) DeepCopyInto(out *DiscordReceiverList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EmailReceiver, len(*in))
+		*out = make([]DiscordReceiver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiverList.
-func (in *EmailReceiverList) DeepCopy() *EmailReceiverList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordReceiverList.
+func (in *DiscordReceiverList) DeepCopy() *DiscordReceiverList {
 	if in == nil {
 		return nil
 	}
-	out := new(EmailReceiverList)
+	out := new(DiscordReceiverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EmailReceiverList) DeepCopyObject() runtime.Object {
+func (in *DiscordReceiverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -549,112 +657,52 @@ func (in *EmailReceiverList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailReceiverSpec) DeepCopyInto(out *EmailReceiverSpec) {
+func (in *DiscordReceiverSpec) DeepCopyInto(out *DiscordReceiverSpec) {
 	*out = *in
-	if in.To != nil {
-		in, out := &in.To, &out.To
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.EmailConfigSelector != nil {
-		in, out := &in.EmailConfigSelector, &out.EmailConfigSelector
+	if in.DiscordConfigSelector != nil {
+		in, out := &in.DiscordConfigSelector, &out.DiscordConfigSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiverSpec.
-func (in *EmailReceiverSpec) DeepCopy() *EmailReceiverSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(EmailReceiverSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmailReceiverStatus) DeepCopyInto(out *EmailReceiverStatus) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiverStatus.
-func (in *EmailReceiverStatus) DeepCopy() *EmailReceiverStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(EmailReceiverStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlobalOptions) DeepCopyInto(out *GlobalOptions) {
-	*out = *in
-	if in.TemplateFiles != nil {
-		in, out := &in.TemplateFiles, &out.TemplateFiles
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalOptions.
-func (in *GlobalOptions) DeepCopy() *GlobalOptions {
-	if in == nil {
-		return nil
-	}
-	out := new(GlobalOptions)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HTTPClientConfig) DeepCopyInto(out *HTTPClientConfig) {
-	*out = *in
-	if in.BasicAuth != nil {
-		in, out := &in.BasicAuth, &out.BasicAuth
-		*out = new(BasicAuth)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.BearerToken != nil {
-		in, out := &in.BearerToken, &out.BearerToken
-		*out = new(v1.SecretKeySelector)
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.TLSConfig != nil {
-		in, out := &in.TLSConfig, &out.TLSConfig
-		*out = new(TLSConfig)
-		(*in).DeepCopyInto(*out)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPClientConfig.
-func (in *HTTPClientConfig) DeepCopy() *HTTPClientConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordReceiverSpec.
+func (in *DiscordReceiverSpec) DeepCopy() *DiscordReceiverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HTTPClientConfig)
+	out := new(DiscordReceiverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HostPort) DeepCopyInto(out *HostPort) {
+func (in *DiscordReceiverStatus) DeepCopyInto(out *DiscordReceiverStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostPort.
-func (in *HostPort) DeepCopy() *HostPort {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscordReceiverStatus.
+func (in *DiscordReceiverStatus) DeepCopy() *DiscordReceiverStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HostPort)
+	out := new(DiscordReceiverStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotificationManager) DeepCopyInto(out *NotificationManager) {
+func (in *EmailConfig) DeepCopyInto(out *EmailConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -662,18 +710,18 @@ func (in *NotificationManager) DeepCopyInto(out *NotificationManager) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManager.
-func (in *NotificationManager) DeepCopy() *NotificationManager {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfig.
+func (in *EmailConfig) DeepCopy() *EmailConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(NotificationManager)
+	out := new(EmailConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NotificationManager) DeepCopyObject() runtime.Object {
+func (in *EmailConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -681,31 +729,31 @@ func (in *NotificationManager) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotificationManagerList) DeepCopyInto(out *NotificationManagerList) {
+func (in *EmailConfigList) DeepCopyInto(out *EmailConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NotificationManager, len(*in))
+		*out = make([]EmailConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManagerList.
-func (in *NotificationManagerList) DeepCopy() *NotificationManagerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfigList.
+func (in *EmailConfigList) DeepCopy() *EmailConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(NotificationManagerList)
+	out := new(EmailConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NotificationManagerList) DeepCopyObject() runtime.Object {
+func (in *EmailConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -713,211 +761,3676 @@ func (in *NotificationManagerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotificationManagerSpec) DeepCopyInto(out *NotificationManagerSpec) {
+func (in *EmailConfigSpec) DeepCopyInto(out *EmailConfigSpec) {
 	*out = *in
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(string)
-		**out = **in
+	out.SmartHost = in.SmartHost
+	if in.Hello != nil {
+		in, out := &in.Hello, &out.Hello
+		*out = new(string)
+		**out = **in
 	}
-	if in.ImagePullPolicy != nil {
-		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
-		*out = new(v1.PullPolicy)
+	if in.AuthUsername != nil {
+		in, out := &in.AuthUsername, &out.AuthUsername
+		*out = new(string)
 		**out = **in
 	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
+	if in.AuthIdentify != nil {
+		in, out := &in.AuthIdentify, &out.AuthIdentify
+		*out = new(string)
 		**out = **in
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.AuthPassword != nil {
+		in, out := &in.AuthPassword, &out.AuthPassword
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+	if in.AuthSecret != nil {
+		in, out := &in.AuthSecret, &out.AuthSecret
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+	if in.RequireTLS != nil {
+		in, out := &in.RequireTLS, &out.RequireTLS
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfigSpec.
+func (in *EmailConfigSpec) DeepCopy() *EmailConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailConfigStatus) DeepCopyInto(out *EmailConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfigStatus.
+func (in *EmailConfigStatus) DeepCopy() *EmailConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailOptions) DeepCopyInto(out *EmailOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailOptions.
+func (in *EmailOptions) DeepCopy() *EmailOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailReceiver) DeepCopyInto(out *EmailReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiver.
+func (in *EmailReceiver) DeepCopy() *EmailReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EmailReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailReceiverList) DeepCopyInto(out *EmailReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EmailReceiver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.DefaultConfigSelector != nil {
-		in, out := &in.DefaultConfigSelector, &out.DefaultConfigSelector
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiverList.
+func (in *EmailReceiverList) DeepCopy() *EmailReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EmailReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailReceiverSpec) DeepCopyInto(out *EmailReceiverSpec) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EmailConfigSelector != nil {
+		in, out := &in.EmailConfigSelector, &out.EmailConfigSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Receivers != nil {
-		in, out := &in.Receivers, &out.Receivers
-		*out = new(ReceiversSpec)
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.NotificationManagerNamespaces != nil {
-		in, out := &in.NotificationManagerNamespaces, &out.NotificationManagerNamespaces
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiverSpec.
+func (in *EmailReceiverSpec) DeepCopy() *EmailReceiverSpec {
+	if in == nil {
+		return nil
 	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+	out := new(EmailReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailReceiverStatus) DeepCopyInto(out *EmailReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailReceiverStatus.
+func (in *EmailReceiverStatus) DeepCopy() *EmailReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileConfig) DeepCopyInto(out *FileConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileConfig.
+func (in *FileConfig) DeepCopy() *FileConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FileConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FileConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileConfigList) DeepCopyInto(out *FileConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FileConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileConfigList.
+func (in *FileConfigList) DeepCopy() *FileConfigList {
+	if in == nil {
+		return nil
 	}
+	out := new(FileConfigList)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManagerSpec.
-func (in *NotificationManagerSpec) DeepCopy() *NotificationManagerSpec {
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FileConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileConfigSpec) DeepCopyInto(out *FileConfigSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileConfigSpec.
+func (in *FileConfigSpec) DeepCopy() *FileConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FileConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileConfigStatus) DeepCopyInto(out *FileConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileConfigStatus.
+func (in *FileConfigStatus) DeepCopy() *FileConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FileConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileOptions) DeepCopyInto(out *FileOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileOptions.
+func (in *FileOptions) DeepCopy() *FileOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(FileOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileReceiver) DeepCopyInto(out *FileReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileReceiver.
+func (in *FileReceiver) DeepCopy() *FileReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(FileReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FileReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileReceiverList) DeepCopyInto(out *FileReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FileReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileReceiverList.
+func (in *FileReceiverList) DeepCopy() *FileReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(FileReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FileReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileReceiverSpec) DeepCopyInto(out *FileReceiverSpec) {
+	*out = *in
+	if in.FileConfigSelector != nil {
+		in, out := &in.FileConfigSelector, &out.FileConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileReceiverSpec.
+func (in *FileReceiverSpec) DeepCopy() *FileReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FileReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileReceiverStatus) DeepCopyInto(out *FileReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileReceiverStatus.
+func (in *FileReceiverStatus) DeepCopy() *FileReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FileReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalOptions) DeepCopyInto(out *GlobalOptions) {
+	*out = *in
+	if in.TemplateFiles != nil {
+		in, out := &in.TemplateFiles, &out.TemplateFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalOptions.
+func (in *GlobalOptions) DeepCopy() *GlobalOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatConfig) DeepCopyInto(out *GoogleChatConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatConfig.
+func (in *GoogleChatConfig) DeepCopy() *GoogleChatConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoogleChatConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatConfigList) DeepCopyInto(out *GoogleChatConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GoogleChatConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatConfigList.
+func (in *GoogleChatConfigList) DeepCopy() *GoogleChatConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoogleChatConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatConfigSpec) DeepCopyInto(out *GoogleChatConfigSpec) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatConfigSpec.
+func (in *GoogleChatConfigSpec) DeepCopy() *GoogleChatConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatConfigStatus) DeepCopyInto(out *GoogleChatConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatConfigStatus.
+func (in *GoogleChatConfigStatus) DeepCopy() *GoogleChatConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatOptions) DeepCopyInto(out *GoogleChatOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatOptions.
+func (in *GoogleChatOptions) DeepCopy() *GoogleChatOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatReceiver) DeepCopyInto(out *GoogleChatReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatReceiver.
+func (in *GoogleChatReceiver) DeepCopy() *GoogleChatReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoogleChatReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatReceiverList) DeepCopyInto(out *GoogleChatReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GoogleChatReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatReceiverList.
+func (in *GoogleChatReceiverList) DeepCopy() *GoogleChatReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoogleChatReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatReceiverSpec) DeepCopyInto(out *GoogleChatReceiverSpec) {
+	*out = *in
+	if in.GoogleChatConfigSelector != nil {
+		in, out := &in.GoogleChatConfigSelector, &out.GoogleChatConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatReceiverSpec.
+func (in *GoogleChatReceiverSpec) DeepCopy() *GoogleChatReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleChatReceiverStatus) DeepCopyInto(out *GoogleChatReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleChatReceiverStatus.
+func (in *GoogleChatReceiverStatus) DeepCopy() *GoogleChatReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleChatReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPClientConfig) DeepCopyInto(out *HTTPClientConfig) {
+	*out = *in
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyAuth != nil {
+		in, out := &in.ProxyAuth, &out.ProxyAuth
+		*out = new(BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPClientConfig.
+func (in *HTTPClientConfig) DeepCopy() *HTTPClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostPort) DeepCopyInto(out *HostPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostPort.
+func (in *HostPort) DeepCopy() *HostPort {
+	if in == nil {
+		return nil
+	}
+	out := new(HostPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConfig) DeepCopyInto(out *KafkaConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConfig.
+func (in *KafkaConfig) DeepCopy() *KafkaConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConfigList) DeepCopyInto(out *KafkaConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConfigList.
+func (in *KafkaConfigList) DeepCopy() *KafkaConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConfigSpec) DeepCopyInto(out *KafkaConfigSpec) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SASL != nil {
+		in, out := &in.SASL, &out.SASL
+		*out = new(KafkaSASL)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConfigSpec.
+func (in *KafkaConfigSpec) DeepCopy() *KafkaConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConfigStatus) DeepCopyInto(out *KafkaConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConfigStatus.
+func (in *KafkaConfigStatus) DeepCopy() *KafkaConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaOptions) DeepCopyInto(out *KafkaOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaOptions.
+func (in *KafkaOptions) DeepCopy() *KafkaOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaReceiver) DeepCopyInto(out *KafkaReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaReceiver.
+func (in *KafkaReceiver) DeepCopy() *KafkaReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaReceiverList) DeepCopyInto(out *KafkaReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaReceiverList.
+func (in *KafkaReceiverList) DeepCopy() *KafkaReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaReceiverSpec) DeepCopyInto(out *KafkaReceiverSpec) {
+	*out = *in
+	if in.KafkaConfigSelector != nil {
+		in, out := &in.KafkaConfigSelector, &out.KafkaConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaReceiverSpec.
+func (in *KafkaReceiverSpec) DeepCopy() *KafkaReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaReceiverStatus) DeepCopyInto(out *KafkaReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaReceiverStatus.
+func (in *KafkaReceiverStatus) DeepCopy() *KafkaReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSASL) DeepCopyInto(out *KafkaSASL) {
+	*out = *in
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSASL.
+func (in *KafkaSASL) DeepCopy() *KafkaSASL {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSASL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineConfig) DeepCopyInto(out *LineConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineConfig.
+func (in *LineConfig) DeepCopy() *LineConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LineConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LineConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineConfigList) DeepCopyInto(out *LineConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LineConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineConfigList.
+func (in *LineConfigList) DeepCopy() *LineConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(LineConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LineConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineConfigSpec) DeepCopyInto(out *LineConfigSpec) {
+	*out = *in
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineConfigSpec.
+func (in *LineConfigSpec) DeepCopy() *LineConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LineConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineConfigStatus) DeepCopyInto(out *LineConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineConfigStatus.
+func (in *LineConfigStatus) DeepCopy() *LineConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LineConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineOptions) DeepCopyInto(out *LineOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineOptions.
+func (in *LineOptions) DeepCopy() *LineOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(LineOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineReceiver) DeepCopyInto(out *LineReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineReceiver.
+func (in *LineReceiver) DeepCopy() *LineReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(LineReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LineReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineReceiverList) DeepCopyInto(out *LineReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LineReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineReceiverList.
+func (in *LineReceiverList) DeepCopy() *LineReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(LineReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LineReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineReceiverSpec) DeepCopyInto(out *LineReceiverSpec) {
+	*out = *in
+	if in.LineConfigSelector != nil {
+		in, out := &in.LineConfigSelector, &out.LineConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineReceiverSpec.
+func (in *LineReceiverSpec) DeepCopy() *LineReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LineReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineReceiverStatus) DeepCopyInto(out *LineReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineReceiverStatus.
+func (in *LineReceiverStatus) DeepCopy() *LineReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LineReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixConfig) DeepCopyInto(out *MatrixConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixConfig.
+func (in *MatrixConfig) DeepCopy() *MatrixConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MatrixConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixConfigList) DeepCopyInto(out *MatrixConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MatrixConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixConfigList.
+func (in *MatrixConfigList) DeepCopy() *MatrixConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MatrixConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixConfigSpec) DeepCopyInto(out *MatrixConfigSpec) {
+	*out = *in
+	if in.AccessToken != nil {
+		in, out := &in.AccessToken, &out.AccessToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixConfigSpec.
+func (in *MatrixConfigSpec) DeepCopy() *MatrixConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixConfigStatus) DeepCopyInto(out *MatrixConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixConfigStatus.
+func (in *MatrixConfigStatus) DeepCopy() *MatrixConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixOptions) DeepCopyInto(out *MatrixOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixOptions.
+func (in *MatrixOptions) DeepCopy() *MatrixOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixReceiver) DeepCopyInto(out *MatrixReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixReceiver.
+func (in *MatrixReceiver) DeepCopy() *MatrixReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MatrixReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixReceiverList) DeepCopyInto(out *MatrixReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MatrixReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixReceiverList.
+func (in *MatrixReceiverList) DeepCopy() *MatrixReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MatrixReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixReceiverSpec) DeepCopyInto(out *MatrixReceiverSpec) {
+	*out = *in
+	if in.MatrixConfigSelector != nil {
+		in, out := &in.MatrixConfigSelector, &out.MatrixConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RoomIDs != nil {
+		in, out := &in.RoomIDs, &out.RoomIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixReceiverSpec.
+func (in *MatrixReceiverSpec) DeepCopy() *MatrixReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixReceiverStatus) DeepCopyInto(out *MatrixReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixReceiverStatus.
+func (in *MatrixReceiverStatus) DeepCopy() *MatrixReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationManager) DeepCopyInto(out *NotificationManager) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManager.
+func (in *NotificationManager) DeepCopy() *NotificationManager {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationManager) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationManagerList) DeepCopyInto(out *NotificationManagerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NotificationManager, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManagerList.
+func (in *NotificationManagerList) DeepCopy() *NotificationManagerList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationManagerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationManagerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationManagerSpec) DeepCopyInto(out *NotificationManagerSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImagePullPolicy != nil {
+		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
+		*out = new(v1.PullPolicy)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultConfigSelector != nil {
+		in, out := &in.DefaultConfigSelector, &out.DefaultConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = new(ReceiversSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NotificationManagerNamespaces != nil {
+		in, out := &in.NotificationManagerNamespaces, &out.NotificationManagerNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManagerSpec.
+func (in *NotificationManagerSpec) DeepCopy() *NotificationManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationManagerStatus) DeepCopyInto(out *NotificationManagerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManagerStatus.
+func (in *NotificationManagerStatus) DeepCopy() *NotificationManagerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationManagerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieConfig) DeepCopyInto(out *OpsgenieConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieConfig.
+func (in *OpsgenieConfig) DeepCopy() *OpsgenieConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpsgenieConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieConfigList) DeepCopyInto(out *OpsgenieConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpsgenieConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieConfigList.
+func (in *OpsgenieConfigList) DeepCopy() *OpsgenieConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpsgenieConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieConfigSpec) DeepCopyInto(out *OpsgenieConfigSpec) {
+	*out = *in
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieConfigSpec.
+func (in *OpsgenieConfigSpec) DeepCopy() *OpsgenieConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieConfigStatus) DeepCopyInto(out *OpsgenieConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieConfigStatus.
+func (in *OpsgenieConfigStatus) DeepCopy() *OpsgenieConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieOptions) DeepCopyInto(out *OpsgenieOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieOptions.
+func (in *OpsgenieOptions) DeepCopy() *OpsgenieOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieReceiver) DeepCopyInto(out *OpsgenieReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieReceiver.
+func (in *OpsgenieReceiver) DeepCopy() *OpsgenieReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpsgenieReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieReceiverList) DeepCopyInto(out *OpsgenieReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpsgenieReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieReceiverList.
+func (in *OpsgenieReceiverList) DeepCopy() *OpsgenieReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpsgenieReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieReceiverSpec) DeepCopyInto(out *OpsgenieReceiverSpec) {
+	*out = *in
+	if in.OpsgenieConfigSelector != nil {
+		in, out := &in.OpsgenieConfigSelector, &out.OpsgenieConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Responders != nil {
+		in, out := &in.Responders, &out.Responders
+		*out = make([]OpsgenieResponder, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieReceiverSpec.
+func (in *OpsgenieReceiverSpec) DeepCopy() *OpsgenieReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieReceiverStatus) DeepCopyInto(out *OpsgenieReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieReceiverStatus.
+func (in *OpsgenieReceiverStatus) DeepCopy() *OpsgenieReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieResponder) DeepCopyInto(out *OpsgenieResponder) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieResponder.
+func (in *OpsgenieResponder) DeepCopy() *OpsgenieResponder {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieResponder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Options) DeepCopyInto(out *Options) {
+	*out = *in
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = new(GlobalOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Wechat != nil {
+		in, out := &in.Wechat, &out.Wechat
+		*out = new(WechatOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DingTalk != nil {
+		in, out := &in.DingTalk, &out.DingTalk
+		*out = new(DingTalkOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Teams != nil {
+		in, out := &in.Teams, &out.Teams
+		*out = new(TeamsOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Discord != nil {
+		in, out := &in.Discord, &out.Discord
+		*out = new(DiscordOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PagerDuty != nil {
+		in, out := &in.PagerDuty, &out.PagerDuty
+		*out = new(PagerDutyOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Opsgenie != nil {
+		in, out := &in.Opsgenie, &out.Opsgenie
+		*out = new(OpsgenieOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Telegram != nil {
+		in, out := &in.Telegram, &out.Telegram
+		*out = new(TelegramOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SMS != nil {
+		in, out := &in.SMS, &out.SMS
+		*out = new(SMSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = new(MatrixOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GoogleChat != nil {
+		in, out := &in.GoogleChat, &out.GoogleChat
+		*out = new(GoogleChatOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SNS != nil {
+		in, out := &in.SNS, &out.SNS
+		*out = new(SNSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Line != nil {
+		in, out := &in.Line, &out.Line
+		*out = new(LineOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(FileOptions)
+		**out = **in
+	}
+	if in.Zoom != nil {
+		in, out := &in.Zoom, &out.Zoom
+		*out = new(ZoomOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RocketChat != nil {
+		in, out := &in.RocketChat, &out.RocketChat
+		*out = new(RocketChatOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Options.
+func (in *Options) DeepCopy() *Options {
+	if in == nil {
+		return nil
+	}
+	out := new(Options)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyConfig) DeepCopyInto(out *PagerDutyConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyConfig.
+func (in *PagerDutyConfig) DeepCopy() *PagerDutyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyConfigList) DeepCopyInto(out *PagerDutyConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PagerDutyConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyConfigList.
+func (in *PagerDutyConfigList) DeepCopy() *PagerDutyConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyConfigSpec) DeepCopyInto(out *PagerDutyConfigSpec) {
+	*out = *in
+	if in.RoutingKey != nil {
+		in, out := &in.RoutingKey, &out.RoutingKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyConfigSpec.
+func (in *PagerDutyConfigSpec) DeepCopy() *PagerDutyConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyConfigStatus) DeepCopyInto(out *PagerDutyConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyConfigStatus.
+func (in *PagerDutyConfigStatus) DeepCopy() *PagerDutyConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyOptions) DeepCopyInto(out *PagerDutyOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyOptions.
+func (in *PagerDutyOptions) DeepCopy() *PagerDutyOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyReceiver) DeepCopyInto(out *PagerDutyReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyReceiver.
+func (in *PagerDutyReceiver) DeepCopy() *PagerDutyReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyReceiverList) DeepCopyInto(out *PagerDutyReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PagerDutyReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyReceiverList.
+func (in *PagerDutyReceiverList) DeepCopy() *PagerDutyReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyReceiverSpec) DeepCopyInto(out *PagerDutyReceiverSpec) {
+	*out = *in
+	if in.PagerDutyConfigSelector != nil {
+		in, out := &in.PagerDutyConfigSelector, &out.PagerDutyConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyReceiverSpec.
+func (in *PagerDutyReceiverSpec) DeepCopy() *PagerDutyReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyReceiverStatus) DeepCopyInto(out *PagerDutyReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyReceiverStatus.
+func (in *PagerDutyReceiverStatus) DeepCopy() *PagerDutyReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityThrottle) DeepCopyInto(out *PriorityThrottle) {
+	*out = *in
+	if in.CriticalPriorities != nil {
+		in, out := &in.CriticalPriorities, &out.CriticalPriorities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityThrottle.
+func (in *PriorityThrottle) DeepCopy() *PriorityThrottle {
+	if in == nil {
+		return nil
+	}
+	out := new(PriorityThrottle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuietHours) DeepCopyInto(out *QuietHours) {
+	*out = *in
+	if in.Ranges != nil {
+		in, out := &in.Ranges, &out.Ranges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuietHours.
+func (in *QuietHours) DeepCopy() *QuietHours {
+	if in == nil {
+		return nil
+	}
+	out := new(QuietHours)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReceiversSpec) DeepCopyInto(out *ReceiversSpec) {
+	*out = *in
+	if in.GlobalReceiverSelector != nil {
+		in, out := &in.GlobalReceiverSelector, &out.GlobalReceiverSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantReceiverSelector != nil {
+		in, out := &in.TenantReceiverSelector, &out.TenantReceiverSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(Options)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiversSpec.
+func (in *ReceiversSpec) DeepCopy() *ReceiversSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReceiversSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Retry) DeepCopyInto(out *Retry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retry.
+func (in *Retry) DeepCopy() *Retry {
+	if in == nil {
+		return nil
+	}
+	out := new(Retry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatConfig) DeepCopyInto(out *RocketChatConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatConfig.
+func (in *RocketChatConfig) DeepCopy() *RocketChatConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RocketChatConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatConfigList) DeepCopyInto(out *RocketChatConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RocketChatConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatConfigList.
+func (in *RocketChatConfigList) DeepCopy() *RocketChatConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RocketChatConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatConfigSpec) DeepCopyInto(out *RocketChatConfigSpec) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserID != nil {
+		in, out := &in.UserID, &out.UserID
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatConfigSpec.
+func (in *RocketChatConfigSpec) DeepCopy() *RocketChatConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatConfigStatus) DeepCopyInto(out *RocketChatConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatConfigStatus.
+func (in *RocketChatConfigStatus) DeepCopy() *RocketChatConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatOptions) DeepCopyInto(out *RocketChatOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatOptions.
+func (in *RocketChatOptions) DeepCopy() *RocketChatOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatReceiver) DeepCopyInto(out *RocketChatReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatReceiver.
+func (in *RocketChatReceiver) DeepCopy() *RocketChatReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RocketChatReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatReceiverList) DeepCopyInto(out *RocketChatReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RocketChatReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatReceiverList.
+func (in *RocketChatReceiverList) DeepCopy() *RocketChatReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RocketChatReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatReceiverSpec) DeepCopyInto(out *RocketChatReceiverSpec) {
+	*out = *in
+	if in.RocketChatConfigSelector != nil {
+		in, out := &in.RocketChatConfigSelector, &out.RocketChatConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatReceiverSpec.
+func (in *RocketChatReceiverSpec) DeepCopy() *RocketChatReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocketChatReceiverStatus) DeepCopyInto(out *RocketChatReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocketChatReceiverStatus.
+func (in *RocketChatReceiverStatus) DeepCopy() *RocketChatReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RocketChatReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSConfig) DeepCopyInto(out *SMSConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSConfig.
+func (in *SMSConfig) DeepCopy() *SMSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SMSConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSConfigList) DeepCopyInto(out *SMSConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SMSConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSConfigList.
+func (in *SMSConfigList) DeepCopy() *SMSConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SMSConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSConfigSpec) DeepCopyInto(out *SMSConfigSpec) {
+	*out = *in
+	if in.Aliyun != nil {
+		in, out := &in.Aliyun, &out.Aliyun
+		*out = new(AliyunSMS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tencent != nil {
+		in, out := &in.Tencent, &out.Tencent
+		*out = new(TencentSMS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSConfigSpec.
+func (in *SMSConfigSpec) DeepCopy() *SMSConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSConfigStatus) DeepCopyInto(out *SMSConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSConfigStatus.
+func (in *SMSConfigStatus) DeepCopy() *SMSConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSOptions) DeepCopyInto(out *SMSOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSOptions.
+func (in *SMSOptions) DeepCopy() *SMSOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSReceiver) DeepCopyInto(out *SMSReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSReceiver.
+func (in *SMSReceiver) DeepCopy() *SMSReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SMSReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSReceiverList) DeepCopyInto(out *SMSReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SMSReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSReceiverList.
+func (in *SMSReceiverList) DeepCopy() *SMSReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SMSReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSReceiverSpec) DeepCopyInto(out *SMSReceiverSpec) {
+	*out = *in
+	if in.SMSConfigSelector != nil {
+		in, out := &in.SMSConfigSelector, &out.SMSConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PhoneNumbers != nil {
+		in, out := &in.PhoneNumbers, &out.PhoneNumbers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSReceiverSpec.
+func (in *SMSReceiverSpec) DeepCopy() *SMSReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMSReceiverStatus) DeepCopyInto(out *SMSReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMSReceiverStatus.
+func (in *SMSReceiverStatus) DeepCopy() *SMSReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SMSReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSConfig) DeepCopyInto(out *SNSConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSConfig.
+func (in *SNSConfig) DeepCopy() *SNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SNSConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSConfigList) DeepCopyInto(out *SNSConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SNSConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSConfigList.
+func (in *SNSConfigList) DeepCopy() *SNSConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SNSConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSConfigSpec) DeepCopyInto(out *SNSConfigSpec) {
+	*out = *in
+	if in.AccessKeyID != nil {
+		in, out := &in.AccessKeyID, &out.AccessKeyID
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretAccessKey != nil {
+		in, out := &in.SecretAccessKey, &out.SecretAccessKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSConfigSpec.
+func (in *SNSConfigSpec) DeepCopy() *SNSConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSConfigStatus) DeepCopyInto(out *SNSConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSConfigStatus.
+func (in *SNSConfigStatus) DeepCopy() *SNSConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSOptions) DeepCopyInto(out *SNSOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSOptions.
+func (in *SNSOptions) DeepCopy() *SNSOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSReceiver) DeepCopyInto(out *SNSReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSReceiver.
+func (in *SNSReceiver) DeepCopy() *SNSReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SNSReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSReceiverList) DeepCopyInto(out *SNSReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SNSReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSReceiverList.
+func (in *SNSReceiverList) DeepCopy() *SNSReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SNSReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSReceiverSpec) DeepCopyInto(out *SNSReceiverSpec) {
+	*out = *in
+	if in.SNSConfigSelector != nil {
+		in, out := &in.SNSConfigSelector, &out.SNSConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AttributeLabels != nil {
+		in, out := &in.AttributeLabels, &out.AttributeLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSReceiverSpec.
+func (in *SNSReceiverSpec) DeepCopy() *SNSReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNSReceiverStatus) DeepCopyInto(out *SNSReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNSReceiverStatus.
+func (in *SNSReceiverStatus) DeepCopy() *SNSReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SNSReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceReference) DeepCopyInto(out *ServiceReference) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Scheme != nil {
+		in, out := &in.Scheme, &out.Scheme
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceReference.
+func (in *ServiceReference) DeepCopy() *ServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackConfig) DeepCopyInto(out *SlackConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfig.
+func (in *SlackConfig) DeepCopy() *SlackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlackConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackConfigList) DeepCopyInto(out *SlackConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlackConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfigList.
+func (in *SlackConfigList) DeepCopy() *SlackConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlackConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackConfigSpec) DeepCopyInto(out *SlackConfigSpec) {
+	*out = *in
+	if in.SlackTokenSecret != nil {
+		in, out := &in.SlackTokenSecret, &out.SlackTokenSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRenderToken != nil {
+		in, out := &in.ImageRenderToken, &out.ImageRenderToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfigSpec.
+func (in *SlackConfigSpec) DeepCopy() *SlackConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackConfigStatus) DeepCopyInto(out *SlackConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfigStatus.
+func (in *SlackConfigStatus) DeepCopy() *SlackConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackOptions) DeepCopyInto(out *SlackOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackOptions.
+func (in *SlackOptions) DeepCopy() *SlackOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackReceiver) DeepCopyInto(out *SlackReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiver.
+func (in *SlackReceiver) DeepCopy() *SlackReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlackReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackReceiverList) DeepCopyInto(out *SlackReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SlackReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiverList.
+func (in *SlackReceiverList) DeepCopy() *SlackReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SlackReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackReceiverSpec) DeepCopyInto(out *SlackReceiverSpec) {
+	*out = *in
+	if in.SlackConfigSelector != nil {
+		in, out := &in.SlackConfigSelector, &out.SlackConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiverSpec.
+func (in *SlackReceiverSpec) DeepCopy() *SlackReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackReceiverStatus) DeepCopyInto(out *SlackReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiverStatus.
+func (in *SlackReceiverStatus) DeepCopy() *SlackReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+	*out = *in
+	if in.RootCA != nil {
+		in, out := &in.RootCA, &out.RootCA
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsConfig) DeepCopyInto(out *TeamsConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsConfig.
+func (in *TeamsConfig) DeepCopy() *TeamsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TeamsConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsConfigList) DeepCopyInto(out *TeamsConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TeamsConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsConfigList.
+func (in *TeamsConfigList) DeepCopy() *TeamsConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TeamsConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsConfigSpec) DeepCopyInto(out *TeamsConfigSpec) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRenderToken != nil {
+		in, out := &in.ImageRenderToken, &out.ImageRenderToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsConfigSpec.
+func (in *TeamsConfigSpec) DeepCopy() *TeamsConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsConfigStatus) DeepCopyInto(out *TeamsConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsConfigStatus.
+func (in *TeamsConfigStatus) DeepCopy() *TeamsConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsOptions) DeepCopyInto(out *TeamsOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsOptions.
+func (in *TeamsOptions) DeepCopy() *TeamsOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsReceiver) DeepCopyInto(out *TeamsReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsReceiver.
+func (in *TeamsReceiver) DeepCopy() *TeamsReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TeamsReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsReceiverList) DeepCopyInto(out *TeamsReceiverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TeamsReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsReceiverList.
+func (in *TeamsReceiverList) DeepCopy() *TeamsReceiverList {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsReceiverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TeamsReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsReceiverSpec) DeepCopyInto(out *TeamsReceiverSpec) {
+	*out = *in
+	if in.TeamsConfigSelector != nil {
+		in, out := &in.TeamsConfigSelector, &out.TeamsConfigSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsReceiverSpec.
+func (in *TeamsReceiverSpec) DeepCopy() *TeamsReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsReceiverStatus) DeepCopyInto(out *TeamsReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsReceiverStatus.
+func (in *TeamsReceiverStatus) DeepCopy() *TeamsReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelegramConfig) DeepCopyInto(out *TelegramConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramConfig.
+func (in *TelegramConfig) DeepCopy() *TelegramConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TelegramConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TelegramConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelegramConfigList) DeepCopyInto(out *TelegramConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TelegramConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramConfigList.
+func (in *TelegramConfigList) DeepCopy() *TelegramConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(TelegramConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TelegramConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelegramConfigSpec) DeepCopyInto(out *TelegramConfigSpec) {
+	*out = *in
+	if in.BotToken != nil {
+		in, out := &in.BotToken, &out.BotToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramConfigSpec.
+func (in *TelegramConfigSpec) DeepCopy() *TelegramConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TelegramConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelegramConfigStatus) DeepCopyInto(out *TelegramConfigStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramConfigStatus.
+func (in *TelegramConfigStatus) DeepCopy() *TelegramConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TelegramConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelegramOptions) DeepCopyInto(out *TelegramOptions) {
+	*out = *in
+	if in.NotificationTimeout != nil {
+		in, out := &in.NotificationTimeout, &out.NotificationTimeout
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramOptions.
+func (in *TelegramOptions) DeepCopy() *TelegramOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TelegramOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelegramReceiver) DeepCopyInto(out *TelegramReceiver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramReceiver.
+func (in *TelegramReceiver) DeepCopy() *TelegramReceiver {
 	if in == nil {
 		return nil
 	}
-	out := new(NotificationManagerSpec)
+	out := new(TelegramReceiver)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TelegramReceiver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotificationManagerStatus) DeepCopyInto(out *NotificationManagerStatus) {
+func (in *TelegramReceiverList) DeepCopyInto(out *TelegramReceiverList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TelegramReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationManagerStatus.
-func (in *NotificationManagerStatus) DeepCopy() *NotificationManagerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramReceiverList.
+func (in *TelegramReceiverList) DeepCopy() *TelegramReceiverList {
 	if in == nil {
 		return nil
 	}
-	out := new(NotificationManagerStatus)
+	out := new(TelegramReceiverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TelegramReceiverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Options) DeepCopyInto(out *Options) {
+func (in *TelegramReceiverSpec) DeepCopyInto(out *TelegramReceiverSpec) {
 	*out = *in
-	if in.Global != nil {
-		in, out := &in.Global, &out.Global
-		*out = new(GlobalOptions)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Email != nil {
-		in, out := &in.Email, &out.Email
-		*out = new(EmailOptions)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Wechat != nil {
-		in, out := &in.Wechat, &out.Wechat
-		*out = new(WechatOptions)
+	if in.TelegramConfigSelector != nil {
+		in, out := &in.TelegramConfigSelector, &out.TelegramConfigSelector
+		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Slack != nil {
-		in, out := &in.Slack, &out.Slack
-		*out = new(SlackOptions)
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Webhook != nil {
-		in, out := &in.Webhook, &out.Webhook
-		*out = new(WebhookOptions)
-		(*in).DeepCopyInto(*out)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
-	if in.DingTalk != nil {
-		in, out := &in.DingTalk, &out.DingTalk
-		*out = new(DingTalkOptions)
-		(*in).DeepCopyInto(*out)
+	if in.ChatIDs != nil {
+		in, out := &in.ChatIDs, &out.ChatIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Options.
-func (in *Options) DeepCopy() *Options {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramReceiverSpec.
+func (in *TelegramReceiverSpec) DeepCopy() *TelegramReceiverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Options)
+	out := new(TelegramReceiverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReceiversSpec) DeepCopyInto(out *ReceiversSpec) {
+func (in *TelegramReceiverStatus) DeepCopyInto(out *TelegramReceiverStatus) {
 	*out = *in
-	if in.GlobalReceiverSelector != nil {
-		in, out := &in.GlobalReceiverSelector, &out.GlobalReceiverSelector
-		*out = new(metav1.LabelSelector)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelegramReceiverStatus.
+func (in *TelegramReceiverStatus) DeepCopy() *TelegramReceiverStatus {
+	if in == nil {
+		return nil
 	}
-	if in.TenantReceiverSelector != nil {
-		in, out := &in.TenantReceiverSelector, &out.TenantReceiverSelector
-		*out = new(metav1.LabelSelector)
+	out := new(TelegramReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TencentSMS) DeepCopyInto(out *TencentSMS) {
+	*out = *in
+	if in.SecretID != nil {
+		in, out := &in.SecretID, &out.SecretID
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Options != nil {
-		in, out := &in.Options, &out.Options
-		*out = new(Options)
+	if in.SecretKey != nil {
+		in, out := &in.SecretKey, &out.SecretKey
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiversSpec.
-func (in *ReceiversSpec) DeepCopy() *ReceiversSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TencentSMS.
+func (in *TencentSMS) DeepCopy() *TencentSMS {
 	if in == nil {
 		return nil
 	}
-	out := new(ReceiversSpec)
+	out := new(TencentSMS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceReference) DeepCopyInto(out *ServiceReference) {
+func (in *Throttle) DeepCopyInto(out *Throttle) {
 	*out = *in
-	if in.Path != nil {
-		in, out := &in.Path, &out.Path
-		*out = new(string)
-		**out = **in
-	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Scheme != nil {
-		in, out := &in.Scheme, &out.Scheme
-		*out = new(string)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceReference.
-func (in *ServiceReference) DeepCopy() *ServiceReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Throttle.
+func (in *Throttle) DeepCopy() *Throttle {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceReference)
+	out := new(Throttle)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackConfig) DeepCopyInto(out *SlackConfig) {
+func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -925,18 +4438,18 @@ func (in *SlackConfig) DeepCopyInto(out *SlackConfig) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfig.
-func (in *SlackConfig) DeepCopy() *SlackConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfig.
+func (in *WebhookConfig) DeepCopy() *WebhookConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackConfig)
+	out := new(WebhookConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SlackConfig) DeepCopyObject() runtime.Object {
+func (in *WebhookConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -944,31 +4457,31 @@ func (in *SlackConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackConfigList) DeepCopyInto(out *SlackConfigList) {
+func (in *WebhookConfigList) DeepCopyInto(out *WebhookConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SlackConfig, len(*in))
+		*out = make([]WebhookConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfigList.
-func (in *SlackConfigList) DeepCopy() *SlackConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigList.
+func (in *WebhookConfigList) DeepCopy() *WebhookConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackConfigList)
+	out := new(WebhookConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SlackConfigList) DeepCopyObject() runtime.Object {
+func (in *WebhookConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -976,62 +4489,103 @@ func (in *SlackConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackConfigSpec) DeepCopyInto(out *SlackConfigSpec) {
+func (in *WebhookConfigSpec) DeepCopyInto(out *WebhookConfigSpec) {
 	*out = *in
-	if in.SlackTokenSecret != nil {
-		in, out := &in.SlackTokenSecret, &out.SlackTokenSecret
-		*out = new(v1.SecretKeySelector)
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(string)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPConfig != nil {
+		in, out := &in.HTTPConfig, &out.HTTPConfig
+		*out = new(HTTPClientConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HeaderSecrets != nil {
+		in, out := &in.HeaderSecrets, &out.HeaderSecrets
+		*out = make(map[string]v1.SecretKeySelector, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(WebhookSignature)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FieldMapping != nil {
+		in, out := &in.FieldMapping, &out.FieldMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfigSpec.
-func (in *SlackConfigSpec) DeepCopy() *SlackConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigSpec.
+func (in *WebhookConfigSpec) DeepCopy() *WebhookConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackConfigSpec)
+	out := new(WebhookConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackConfigStatus) DeepCopyInto(out *SlackConfigStatus) {
+func (in *WebhookConfigStatus) DeepCopyInto(out *WebhookConfigStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfigStatus.
-func (in *SlackConfigStatus) DeepCopy() *SlackConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigStatus.
+func (in *WebhookConfigStatus) DeepCopy() *WebhookConfigStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackConfigStatus)
+	out := new(WebhookConfigStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackOptions) DeepCopyInto(out *SlackOptions) {
+func (in *WebhookOptions) DeepCopyInto(out *WebhookOptions) {
 	*out = *in
 	if in.NotificationTimeout != nil {
 		in, out := &in.NotificationTimeout, &out.NotificationTimeout
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(Retry)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackOptions.
-func (in *SlackOptions) DeepCopy() *SlackOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookOptions.
+func (in *WebhookOptions) DeepCopy() *WebhookOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackOptions)
+	out := new(WebhookOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackReceiver) DeepCopyInto(out *SlackReceiver) {
+func (in *WebhookReceiver) DeepCopyInto(out *WebhookReceiver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1039,18 +4593,18 @@ func (in *SlackReceiver) DeepCopyInto(out *SlackReceiver) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiver.
-func (in *SlackReceiver) DeepCopy() *SlackReceiver {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiver.
+func (in *WebhookReceiver) DeepCopy() *WebhookReceiver {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackReceiver)
+	out := new(WebhookReceiver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SlackReceiver) DeepCopyObject() runtime.Object {
+func (in *WebhookReceiver) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1058,31 +4612,31 @@ func (in *SlackReceiver) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackReceiverList) DeepCopyInto(out *SlackReceiverList) {
+func (in *WebhookReceiverList) DeepCopyInto(out *WebhookReceiverList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SlackReceiver, len(*in))
+		*out = make([]WebhookReceiver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiverList.
-func (in *SlackReceiverList) DeepCopy() *SlackReceiverList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiverList.
+func (in *WebhookReceiverList) DeepCopy() *WebhookReceiverList {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackReceiverList)
+	out := new(WebhookReceiverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SlackReceiverList) DeepCopyObject() runtime.Object {
+func (in *WebhookReceiverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1090,82 +4644,72 @@ func (in *SlackReceiverList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackReceiverSpec) DeepCopyInto(out *SlackReceiverSpec) {
+func (in *WebhookReceiverSpec) DeepCopyInto(out *WebhookReceiverSpec) {
 	*out = *in
-	if in.SlackConfigSelector != nil {
-		in, out := &in.SlackConfigSelector, &out.SlackConfigSelector
+	if in.WebhookConfigSelector != nil {
+		in, out := &in.WebhookConfigSelector, &out.WebhookConfigSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiverSpec.
-func (in *SlackReceiverSpec) DeepCopy() *SlackReceiverSpec {
-	if in == nil {
-		return nil
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
 	}
-	out := new(SlackReceiverSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SlackReceiverStatus) DeepCopyInto(out *SlackReceiverStatus) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiverStatus.
-func (in *SlackReceiverStatus) DeepCopy() *SlackReceiverStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiverSpec.
+func (in *WebhookReceiverSpec) DeepCopy() *WebhookReceiverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SlackReceiverStatus)
+	out := new(WebhookReceiverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
-	*out = *in
-	if in.RootCA != nil {
-		in, out := &in.RootCA, &out.RootCA
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ClientCertificate != nil {
-		in, out := &in.ClientCertificate, &out.ClientCertificate
-		*out = new(ClientCertificate)
-		(*in).DeepCopyInto(*out)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
-func (in *TLSConfig) DeepCopy() *TLSConfig {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookReceiverStatus) DeepCopyInto(out *WebhookReceiverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiverStatus.
+func (in *WebhookReceiverStatus) DeepCopy() *WebhookReceiverStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TLSConfig)
+	out := new(WebhookReceiverStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Throttle) DeepCopyInto(out *Throttle) {
+func (in *WebhookSignature) DeepCopyInto(out *WebhookSignature) {
 	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Throttle.
-func (in *Throttle) DeepCopy() *Throttle {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSignature.
+func (in *WebhookSignature) DeepCopy() *WebhookSignature {
 	if in == nil {
 		return nil
 	}
-	out := new(Throttle)
+	out := new(WebhookSignature)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
+func (in *WechatConfig) DeepCopyInto(out *WechatConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1173,18 +4717,18 @@ func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfig.
-func (in *WebhookConfig) DeepCopy() *WebhookConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfig.
+func (in *WechatConfig) DeepCopy() *WechatConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookConfig)
+	out := new(WechatConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WebhookConfig) DeepCopyObject() runtime.Object {
+func (in *WechatConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1192,31 +4736,31 @@ func (in *WebhookConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookConfigList) DeepCopyInto(out *WebhookConfigList) {
+func (in *WechatConfigList) DeepCopyInto(out *WechatConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]WebhookConfig, len(*in))
+		*out = make([]WechatConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigList.
-func (in *WebhookConfigList) DeepCopy() *WebhookConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfigList.
+func (in *WechatConfigList) DeepCopy() *WechatConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookConfigList)
+	out := new(WechatConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WebhookConfigList) DeepCopyObject() runtime.Object {
+func (in *WechatConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1224,72 +4768,127 @@ func (in *WebhookConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookConfigSpec) DeepCopyInto(out *WebhookConfigSpec) {
+func (in *WechatConfigSpec) DeepCopyInto(out *WechatConfigSpec) {
 	*out = *in
-	if in.URL != nil {
-		in, out := &in.URL, &out.URL
-		*out = new(string)
-		**out = **in
+	if in.WechatApiSecret != nil {
+		in, out := &in.WechatApiSecret, &out.WechatApiSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(ServiceReference)
+	if in.ProxyAuth != nil {
+		in, out := &in.ProxyAuth, &out.ProxyAuth
+		*out = new(BasicAuth)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.HTTPConfig != nil {
-		in, out := &in.HTTPConfig, &out.HTTPConfig
-		*out = new(HTTPClientConfig)
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(TLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SuccessCodes != nil {
+		in, out := &in.SuccessCodes, &out.SuccessCodes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.WechatApiUrls != nil {
+		in, out := &in.WechatApiUrls, &out.WechatApiUrls
+		*out = make([]WechatRelay, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigSpec.
-func (in *WebhookConfigSpec) DeepCopy() *WebhookConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfigSpec.
+func (in *WechatConfigSpec) DeepCopy() *WechatConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookConfigSpec)
+	out := new(WechatConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookConfigStatus) DeepCopyInto(out *WebhookConfigStatus) {
+func (in *WechatConfigStatus) DeepCopyInto(out *WechatConfigStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigStatus.
-func (in *WebhookConfigStatus) DeepCopy() *WebhookConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfigStatus.
+func (in *WechatConfigStatus) DeepCopy() *WechatConfigStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookConfigStatus)
+	out := new(WechatConfigStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookOptions) DeepCopyInto(out *WebhookOptions) {
+func (in *WechatMedia) DeepCopyInto(out *WechatMedia) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatMedia.
+func (in *WechatMedia) DeepCopy() *WechatMedia {
+	if in == nil {
+		return nil
+	}
+	out := new(WechatMedia)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WechatOptions) DeepCopyInto(out *WechatOptions) {
 	*out = *in
 	if in.NotificationTimeout != nil {
 		in, out := &in.NotificationTimeout, &out.NotificationTimeout
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(Retry)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		**out = **in
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreaker)
+		**out = **in
+	}
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookOptions.
-func (in *WebhookOptions) DeepCopy() *WebhookOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatOptions.
+func (in *WechatOptions) DeepCopy() *WechatOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookOptions)
+	out := new(WechatOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookReceiver) DeepCopyInto(out *WebhookReceiver) {
+func (in *WechatReceiver) DeepCopyInto(out *WechatReceiver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1297,18 +4896,18 @@ func (in *WebhookReceiver) DeepCopyInto(out *WebhookReceiver) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiver.
-func (in *WebhookReceiver) DeepCopy() *WebhookReceiver {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiver.
+func (in *WechatReceiver) DeepCopy() *WechatReceiver {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookReceiver)
+	out := new(WechatReceiver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WebhookReceiver) DeepCopyObject() runtime.Object {
+func (in *WechatReceiver) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1316,31 +4915,31 @@ func (in *WebhookReceiver) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookReceiverList) DeepCopyInto(out *WebhookReceiverList) {
+func (in *WechatReceiverList) DeepCopyInto(out *WechatReceiverList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]WebhookReceiver, len(*in))
+		*out = make([]WechatReceiver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiverList.
-func (in *WebhookReceiverList) DeepCopy() *WebhookReceiverList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiverList.
+func (in *WechatReceiverList) DeepCopy() *WechatReceiverList {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookReceiverList)
+	out := new(WechatReceiverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WebhookReceiverList) DeepCopyObject() runtime.Object {
+func (in *WechatReceiverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1348,42 +4947,119 @@ func (in *WebhookReceiverList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookReceiverSpec) DeepCopyInto(out *WebhookReceiverSpec) {
+func (in *WechatReceiverSpec) DeepCopyInto(out *WechatReceiverSpec) {
 	*out = *in
-	if in.WebhookConfigSelector != nil {
-		in, out := &in.WebhookConfigSelector, &out.WebhookConfigSelector
+	if in.WechatConfigSelector != nil {
+		in, out := &in.WechatConfigSelector, &out.WechatConfigSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MentionedUsers != nil {
+		in, out := &in.MentionedUsers, &out.MentionedUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Media != nil {
+		in, out := &in.Media, &out.Media
+		*out = new(WechatMedia)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SeverityRouting != nil {
+		in, out := &in.SeverityRouting, &out.SeverityRouting
+		*out = make(map[string]WechatRoute, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Throttle != nil {
+		in, out := &in.Throttle, &out.Throttle
+		*out = new(PriorityThrottle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QuietHours != nil {
+		in, out := &in.QuietHours, &out.QuietHours
+		*out = new(QuietHours)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Digest != nil {
+		in, out := &in.Digest, &out.Digest
+		*out = new(Digest)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiverSpec.
-func (in *WebhookReceiverSpec) DeepCopy() *WebhookReceiverSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiverSpec.
+func (in *WechatReceiverSpec) DeepCopy() *WechatReceiverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookReceiverSpec)
+	out := new(WechatReceiverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WebhookReceiverStatus) DeepCopyInto(out *WebhookReceiverStatus) {
+func (in *WechatReceiverStatus) DeepCopyInto(out *WechatReceiverStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookReceiverStatus.
-func (in *WebhookReceiverStatus) DeepCopy() *WebhookReceiverStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiverStatus.
+func (in *WechatReceiverStatus) DeepCopy() *WechatReceiverStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(WebhookReceiverStatus)
+	out := new(WechatReceiverStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatConfig) DeepCopyInto(out *WechatConfig) {
+func (in *WechatRelay) DeepCopyInto(out *WechatRelay) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatRelay.
+func (in *WechatRelay) DeepCopy() *WechatRelay {
+	if in == nil {
+		return nil
+	}
+	out := new(WechatRelay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WechatRoute) DeepCopyInto(out *WechatRoute) {
+	*out = *in
+	if in.APISecret != nil {
+		in, out := &in.APISecret, &out.APISecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatRoute.
+func (in *WechatRoute) DeepCopy() *WechatRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(WechatRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoomConfig) DeepCopyInto(out *ZoomConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1391,18 +5067,18 @@ func (in *WechatConfig) DeepCopyInto(out *WechatConfig) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfig.
-func (in *WechatConfig) DeepCopy() *WechatConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomConfig.
+func (in *ZoomConfig) DeepCopy() *ZoomConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatConfig)
+	out := new(ZoomConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WechatConfig) DeepCopyObject() runtime.Object {
+func (in *ZoomConfig) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1410,31 +5086,31 @@ func (in *WechatConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatConfigList) DeepCopyInto(out *WechatConfigList) {
+func (in *ZoomConfigList) DeepCopyInto(out *ZoomConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]WechatConfig, len(*in))
+		*out = make([]ZoomConfig, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfigList.
-func (in *WechatConfigList) DeepCopy() *WechatConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomConfigList.
+func (in *ZoomConfigList) DeepCopy() *ZoomConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatConfigList)
+	out := new(ZoomConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WechatConfigList) DeepCopyObject() runtime.Object {
+func (in *ZoomConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1442,42 +5118,42 @@ func (in *WechatConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatConfigSpec) DeepCopyInto(out *WechatConfigSpec) {
+func (in *ZoomConfigSpec) DeepCopyInto(out *ZoomConfigSpec) {
 	*out = *in
-	if in.WechatApiSecret != nil {
-		in, out := &in.WechatApiSecret, &out.WechatApiSecret
+	if in.ClientSecret != nil {
+		in, out := &in.ClientSecret, &out.ClientSecret
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfigSpec.
-func (in *WechatConfigSpec) DeepCopy() *WechatConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomConfigSpec.
+func (in *ZoomConfigSpec) DeepCopy() *ZoomConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatConfigSpec)
+	out := new(ZoomConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatConfigStatus) DeepCopyInto(out *WechatConfigStatus) {
+func (in *ZoomConfigStatus) DeepCopyInto(out *ZoomConfigStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatConfigStatus.
-func (in *WechatConfigStatus) DeepCopy() *WechatConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomConfigStatus.
+func (in *ZoomConfigStatus) DeepCopy() *ZoomConfigStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatConfigStatus)
+	out := new(ZoomConfigStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatOptions) DeepCopyInto(out *WechatOptions) {
+func (in *ZoomOptions) DeepCopyInto(out *ZoomOptions) {
 	*out = *in
 	if in.NotificationTimeout != nil {
 		in, out := &in.NotificationTimeout, &out.NotificationTimeout
@@ -1486,18 +5162,18 @@ func (in *WechatOptions) DeepCopyInto(out *WechatOptions) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatOptions.
-func (in *WechatOptions) DeepCopy() *WechatOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomOptions.
+func (in *ZoomOptions) DeepCopy() *ZoomOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatOptions)
+	out := new(ZoomOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatReceiver) DeepCopyInto(out *WechatReceiver) {
+func (in *ZoomReceiver) DeepCopyInto(out *ZoomReceiver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1505,18 +5181,18 @@ func (in *WechatReceiver) DeepCopyInto(out *WechatReceiver) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiver.
-func (in *WechatReceiver) DeepCopy() *WechatReceiver {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomReceiver.
+func (in *ZoomReceiver) DeepCopy() *ZoomReceiver {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatReceiver)
+	out := new(ZoomReceiver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WechatReceiver) DeepCopyObject() runtime.Object {
+func (in *ZoomReceiver) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1524,31 +5200,31 @@ func (in *WechatReceiver) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatReceiverList) DeepCopyInto(out *WechatReceiverList) {
+func (in *ZoomReceiverList) DeepCopyInto(out *ZoomReceiverList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]WechatReceiver, len(*in))
+		*out = make([]ZoomReceiver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiverList.
-func (in *WechatReceiverList) DeepCopy() *WechatReceiverList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomReceiverList.
+func (in *ZoomReceiverList) DeepCopy() *ZoomReceiverList {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatReceiverList)
+	out := new(ZoomReceiverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WechatReceiverList) DeepCopyObject() runtime.Object {
+func (in *ZoomReceiverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1556,36 +5232,51 @@ func (in *WechatReceiverList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatReceiverSpec) DeepCopyInto(out *WechatReceiverSpec) {
+func (in *ZoomReceiverSpec) DeepCopyInto(out *ZoomReceiverSpec) {
 	*out = *in
-	if in.WechatConfigSelector != nil {
-		in, out := &in.WechatConfigSelector, &out.WechatConfigSelector
+	if in.ZoomConfigSelector != nil {
+		in, out := &in.ZoomConfigSelector, &out.ZoomConfigSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AlertSelector != nil {
+		in, out := &in.AlertSelector, &out.AlertSelector
+		*out = new(AlertSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ToJIDs != nil {
+		in, out := &in.ToJIDs, &out.ToJIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiverSpec.
-func (in *WechatReceiverSpec) DeepCopy() *WechatReceiverSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomReceiverSpec.
+func (in *ZoomReceiverSpec) DeepCopy() *ZoomReceiverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatReceiverSpec)
+	out := new(ZoomReceiverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WechatReceiverStatus) DeepCopyInto(out *WechatReceiverStatus) {
+func (in *ZoomReceiverStatus) DeepCopyInto(out *ZoomReceiverStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WechatReceiverStatus.
-func (in *WechatReceiverStatus) DeepCopy() *WechatReceiverStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoomReceiverStatus.
+func (in *ZoomReceiverStatus) DeepCopy() *ZoomReceiverStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(WechatReceiverStatus)
+	out := new(ZoomReceiverStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -11,6 +11,8 @@ type Group struct {
 	workers []func(stopCh chan interface{})
 	stopCh  chan interface{}
 	ctx     context.Context
+	// The maximum number of workers executed at the same time, it is unlimited if it is 0.
+	limit int
 }
 
 func NewGroup(ctx context.Context) *Group {
@@ -19,6 +21,14 @@ func NewGroup(ctx context.Context) *Group {
 	}
 }
 
+// NewGroupWithLimit creates a group which executes at most limit workers at the same time.
+func NewGroupWithLimit(ctx context.Context, limit int) *Group {
+	return &Group{
+		ctx:   ctx,
+		limit: limit,
+	}
+}
+
 // Add a worker to group
 func (g *Group) Add(w func(stopCh chan interface{})) {
 	g.workers = append(g.workers, w)
@@ -33,8 +43,24 @@ func (g *Group) Wait() []error {
 
 	g.stopCh = make(chan interface{}, len(g.workers))
 
-	for _, worker := range g.workers {
-		go worker(g.stopCh)
+	if g.limit > 0 {
+		sem := make(chan struct{}, g.limit)
+		for _, worker := range g.workers {
+			w := worker
+			go func() {
+				select {
+				case sem <- struct{}{}:
+				case <-g.ctx.Done():
+					return
+				}
+				defer func() { <-sem }()
+				w(g.stopCh)
+			}()
+		}
+	} else {
+		for _, worker := range g.workers {
+			go worker(g.stopCh)
+		}
 	}
 
 	var errs []error
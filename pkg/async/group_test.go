@@ -0,0 +1,97 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Add n workers tracking the number of workers running at the same time.
+func addWorkers(g *Group, n int, running, peak *int32) {
+	for i := 0; i < n; i++ {
+		g.Add(func(stopCh chan interface{}) {
+			cur := atomic.AddInt32(running, 1)
+			for {
+				p := atomic.LoadInt32(peak)
+				if cur <= p || atomic.CompareAndSwapInt32(peak, p, cur) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt32(running, -1)
+			stopCh <- errors.New("failed")
+		})
+	}
+}
+
+func TestGroupWithLimit(t *testing.T) {
+
+	tests := []struct {
+		limit   int
+		workers int
+	}{
+		{1, 5},
+		{3, 20},
+		{10, 5},
+	}
+
+	for _, tt := range tests {
+		var running, peak int32
+		g := NewGroupWithLimit(context.Background(), tt.limit)
+		addWorkers(g, tt.workers, &running, &peak)
+
+		// The results of all workers are collected.
+		if errs := g.Wait(); len(errs) != tt.workers {
+			t.Errorf("limit %d: expected %d errors, got %d", tt.limit, tt.workers, len(errs))
+		}
+
+		if peak > int32(tt.limit) {
+			t.Errorf("limit %d: %d workers ran at the same time", tt.limit, peak)
+		}
+	}
+}
+
+func TestGroupUnlimited(t *testing.T) {
+
+	var running, peak int32
+	g := NewGroup(context.Background())
+	addWorkers(g, 20, &running, &peak)
+
+	if errs := g.Wait(); len(errs) != 20 {
+		t.Errorf("expected 20 errors, got %d", len(errs))
+	}
+
+	if peak < 2 {
+		t.Errorf("expected the workers ran concurrently, the peak is %d", peak)
+	}
+}
+
+func TestGroupWithLimitTimeout(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	var started int32
+	g := NewGroupWithLimit(ctx, 1)
+	for i := 0; i < 3; i++ {
+		g.Add(func(stopCh chan interface{}) {
+			atomic.AddInt32(&started, 1)
+			time.Sleep(time.Millisecond * 100)
+			stopCh <- nil
+		})
+	}
+
+	errs := g.Wait()
+	if len(errs) != 1 || errs[0].Error() != "time out" {
+		t.Errorf("expected the time out error, got %v", errs)
+	}
+
+	// The workers waiting for a slot are not started after the time out.
+	time.Sleep(time.Millisecond * 150)
+	if n := atomic.LoadInt32(&started); n != 1 {
+		t.Errorf("expected 1 worker started, got %d", n)
+	}
+}
@@ -59,11 +59,12 @@ type NotificationManagerReconciler struct {
 
 // Reconcile reads that state of NotificationManager objects and makes changes based on the state read
 // and what is in the NotificationManagerSpec
-// +kubebuilder:rbac:groups=notification.kubesphere.io,resources=notificationmanagers;receivers;dingtalkconfigs;dingtalkreceivers;emailconfigs;emailreceivers;webhookconfigs;webhookreceivers;wechatconfigs;wechatreceivers;slackconfigs;slackreceivers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=notification.kubesphere.io,resources=notificationmanagers;receivers;dingtalkconfigs;dingtalkreceivers;emailconfigs;emailreceivers;webhookconfigs;webhookreceivers;wechatconfigs;wechatreceivers;slackconfigs;slackreceivers;teamsconfigs;teamsreceivers;discordconfigs;discordreceivers;pagerdutyconfigs;pagerdutyreceivers;opsgenieconfigs;opsgeniereceivers;telegramconfigs;telegramreceivers;smsconfigs;smsreceivers;matrixconfigs;matrixreceivers;googlechatconfigs;googlechatreceivers;snsconfigs;snsreceivers;kafkaconfigs;kafkareceivers;lineconfigs;linereceivers;fileconfigs;filereceivers;zoomconfigs;zoomreceivers;rocketchatconfigs;rocketchatreceivers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=notification.kubesphere.io,resources=notificationmanagers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;
 
 func (r *NotificationManagerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
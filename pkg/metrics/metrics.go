@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+const (
+	namespace = "nm"
+	subsystem = "notifier"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	// The number of messages sent by the notifiers.
+	SentTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "sent_total",
+			Help:      "The total number of messages sent by the notifiers.",
+		},
+		[]string{"type", "receiver", "result"},
+	)
+
+	// The number of retries made by the notifiers.
+	RetryTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retry_total",
+			Help:      "The total number of retries made by the notifiers.",
+		},
+		[]string{"type"},
+	)
+
+	// The time used to send a message.
+	SendDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "send_duration_seconds",
+			Help:      "The time used by the notifiers to send a message.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"type"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(SentTotal, RetryTotal, SendDuration)
+}
+
+// ObserveSend records the result and the time used of a sending which started at `start`.
+func ObserveSend(notifierType, receiver string, start time.Time, err error) {
+
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+	}
+
+	SentTotal.WithLabelValues(notifierType, receiver, result).Inc()
+	SendDuration.WithLabelValues(notifierType).Observe(time.Since(start).Seconds())
+}
+
+// ObserveRetry records a retry made by the notifier.
+func ObserveRetry(notifierType string) {
+	RetryTotal.WithLabelValues(notifierType).Inc()
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"testing"
+	"time"
+)
+
+// Get the value of the counter or the sample count of the histogram which has the labels.
+func value(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics error, %s", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			if !match(m, labels) {
+				continue
+			}
+
+			if m.GetCounter() != nil {
+				return m.GetCounter().GetValue()
+			}
+			return float64(m.GetHistogram().GetSampleCount())
+		}
+	}
+
+	return 0
+}
+
+func match(m *dto.Metric, labels map[string]string) bool {
+
+	if len(m.GetLabel()) != len(labels) {
+		return false
+	}
+
+	for _, l := range m.GetLabel() {
+		if labels[l.GetName()] != l.GetValue() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestObserveSend(t *testing.T) {
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(SentTotal, RetryTotal, SendDuration)
+
+	success := map[string]string{"type": "test", "receiver": "r1", "result": ResultSuccess}
+	failure := map[string]string{"type": "test", "receiver": "r1", "result": ResultFailure}
+	s0 := value(t, registry, "nm_notifier_sent_total", success)
+	f0 := value(t, registry, "nm_notifier_sent_total", failure)
+	d0 := value(t, registry, "nm_notifier_send_duration_seconds", map[string]string{"type": "test"})
+
+	ObserveSend("test", "r1", time.Now(), nil)
+	ObserveSend("test", "r1", time.Now(), nil)
+	ObserveSend("test", "r1", time.Now(), errors.New("send error"))
+
+	if v := value(t, registry, "nm_notifier_sent_total", success) - s0; v != 2 {
+		t.Errorf("expected 2 successful sends, got %v", v)
+	}
+
+	if v := value(t, registry, "nm_notifier_sent_total", failure) - f0; v != 1 {
+		t.Errorf("expected 1 failed send, got %v", v)
+	}
+
+	if v := value(t, registry, "nm_notifier_send_duration_seconds", map[string]string{"type": "test"}) - d0; v != 3 {
+		t.Errorf("expected 3 observed durations, got %v", v)
+	}
+}
+
+func TestObserveRetry(t *testing.T) {
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(SentTotal, RetryTotal, SendDuration)
+
+	labels := map[string]string{"type": "retry-test"}
+	r0 := value(t, registry, "nm_notifier_retry_total", labels)
+
+	ObserveRetry("retry-test")
+
+	if v := value(t, registry, "nm_notifier_retry_total", labels) - r0; v != 1 {
+		t.Errorf("expected 1 retry, got %v", v)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	"k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -36,6 +37,20 @@ const (
 	slack               = "slack"
 	webhook             = "webhook"
 	dingtalk            = "dingtalk"
+	teams               = "teams"
+	discord             = "discord"
+	pagerduty           = "pagerduty"
+	opsgenie            = "opsgenie"
+	telegram            = "telegram"
+	sms                 = "sms"
+	matrix              = "matrix"
+	googlechat          = "googlechat"
+	sns                 = "sns"
+	kafka               = "kafka"
+	line                = "line"
+	file                = "file"
+	zoom                = "zoom"
+	rocketchat          = "rocketchat"
 	opAdd               = "add"
 	opDel               = "delete"
 	opGet               = "get"
@@ -70,6 +85,16 @@ type Config struct {
 	nmNamespaces []string
 	// Dose the notification manager crd add.
 	nmAdd bool
+	// The cache of secrets used by the notifiers.
+	secrets *secretCache
+	// The resolvers of the secrets which are not stored in kubernetes secrets, the key is the scheme of secret reference.
+	resolvers map[string]SecretResolver
+	// The namespace which notification manager in, the configs in it can use any secret reference.
+	namespace string
+	// The prefixes of the secret references which the configs in each namespace can use, the key is the namespace.
+	secretRefAllowList map[string][]string
+	// The name of the configmap which stores the pause state, it is in the namespace which notification manager in.
+	pauseConfigMap string
 }
 
 type param struct {
@@ -135,6 +160,13 @@ func New(ctx context.Context, logger log.Logger, namespaces string) (*Config, er
 		return nil, err
 	}
 
+	return NewWithClient(ctx, logger, informerCache, c, nmNamespaces), nil
+}
+
+// NewWithClient creates the config with the given cache and client, the cache is used to watch the resources,
+// and the client is used to read the resources which are not cached.
+func NewWithClient(ctx context.Context, logger log.Logger, informerCache cache.Cache, c client.Client, nmNamespaces []string) *Config {
+
 	f := make(map[string]factory)
 	register := func(key string, newReceiverFunc func() Receiver,
 		newReceiverObjectFunc func() runtime.Object, newReceiverObjectListFunc func() runtime.Object,
@@ -215,6 +247,202 @@ func New(ctx context.Context, logger log.Logger, namespaces string) (*Config, er
 			return &v1alpha1.WechatConfigList{}
 		})
 
+	register(teams, NewTeamsReceiver,
+		func() runtime.Object {
+			return &v1alpha1.TeamsReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.TeamsReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.TeamsConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.TeamsConfigList{}
+		})
+
+	register(discord, NewDiscordReceiver,
+		func() runtime.Object {
+			return &v1alpha1.DiscordReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.DiscordReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.DiscordConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.DiscordConfigList{}
+		})
+
+	register(pagerduty, NewPagerDutyReceiver,
+		func() runtime.Object {
+			return &v1alpha1.PagerDutyReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.PagerDutyReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.PagerDutyConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.PagerDutyConfigList{}
+		})
+
+	register(opsgenie, NewOpsgenieReceiver,
+		func() runtime.Object {
+			return &v1alpha1.OpsgenieReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.OpsgenieReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.OpsgenieConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.OpsgenieConfigList{}
+		})
+
+	register(telegram, NewTelegramReceiver,
+		func() runtime.Object {
+			return &v1alpha1.TelegramReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.TelegramReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.TelegramConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.TelegramConfigList{}
+		})
+
+	register(sms, NewSMSReceiver,
+		func() runtime.Object {
+			return &v1alpha1.SMSReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.SMSReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.SMSConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.SMSConfigList{}
+		})
+
+	register(matrix, NewMatrixReceiver,
+		func() runtime.Object {
+			return &v1alpha1.MatrixReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.MatrixReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.MatrixConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.MatrixConfigList{}
+		})
+
+	register(googlechat, NewGoogleChatReceiver,
+		func() runtime.Object {
+			return &v1alpha1.GoogleChatReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.GoogleChatReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.GoogleChatConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.GoogleChatConfigList{}
+		})
+
+	register(sns, NewSNSReceiver,
+		func() runtime.Object {
+			return &v1alpha1.SNSReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.SNSReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.SNSConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.SNSConfigList{}
+		})
+
+	register(kafka, NewKafkaReceiver,
+		func() runtime.Object {
+			return &v1alpha1.KafkaReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.KafkaReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.KafkaConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.KafkaConfigList{}
+		})
+
+	register(line, NewLineReceiver,
+		func() runtime.Object {
+			return &v1alpha1.LineReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.LineReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.LineConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.LineConfigList{}
+		})
+
+	register(file, NewFileReceiver,
+		func() runtime.Object {
+			return &v1alpha1.FileReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.FileReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.FileConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.FileConfigList{}
+		})
+
+	register(zoom, NewZoomReceiver,
+		func() runtime.Object {
+			return &v1alpha1.ZoomReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.ZoomReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.ZoomConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.ZoomConfigList{}
+		})
+
+	register(rocketchat, NewRocketChatReceiver,
+		func() runtime.Object {
+			return &v1alpha1.RocketChatReceiver{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.RocketChatReceiverList{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.RocketChatConfig{}
+		},
+		func() runtime.Object {
+			return &v1alpha1.RocketChatConfigList{}
+		})
+
 	return &Config{
 		ctx:                    ctx,
 		logger:                 logger,
@@ -230,7 +458,13 @@ func New(ctx context.Context, logger log.Logger, namespaces string) (*Config, er
 		ReceiverOpts:           nil,
 		ch:                     make(chan *param, ChannelCapacity),
 		nmNamespaces:           nmNamespaces,
-	}, nil
+		secrets:                newSecretCache(DefaultSecretCacheTTL),
+		resolvers: map[string]SecretResolver{
+			SecretSchemeEnv: NewEnvResolver(),
+		},
+		namespace:          os.Getenv("NAMESPACE"),
+		secretRefAllowList: make(map[string][]string),
+	}
 }
 
 // Setting up client
@@ -289,6 +523,39 @@ func (c *Config) Run() error {
 		DeleteFunc: c.onNmDel,
 	})
 
+	// Drop the cached secret when it changed.
+	secretInf, err := c.cache.GetInformer(&v1.Secret{})
+	if err != nil {
+		_ = level.Error(c.logger).Log("msg", "Failed to get informer for Secret", "err", err)
+		return err
+	}
+	secretInf.AddEventHandler(kcache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.secrets.invalidate(newObj)
+		},
+		DeleteFunc: c.secrets.invalidate,
+	})
+
+	// Pause or resume the notifications when the pause state stored in the configmap changed.
+	if len(c.pauseConfigMap) > 0 {
+		cmInf, err := c.cache.GetInformer(&v1.ConfigMap{})
+		if err != nil {
+			_ = level.Error(c.logger).Log("msg", "Failed to get informer for ConfigMap", "err", err)
+			return err
+		}
+		cmInf.AddEventHandler(kcache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				c.onPauseConfigMapChanged(obj, false)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.onPauseConfigMapChanged(newObj, false)
+			},
+			DeleteFunc: func(obj interface{}) {
+				c.onPauseConfigMapChanged(obj, true)
+			},
+		})
+	}
+
 	addInformer := func(f factory) error {
 		informer, err := c.cache.GetInformer(f.newReceiverObjectFunc())
 		if err != nil {
@@ -488,9 +755,27 @@ func (c *Config) nmChange(p *param) {
 		c.ReceiverOpts = nil
 	}
 
+	applyGlobalOptions(c.ReceiverOpts)
+
 	p.done <- struct{}{}
 }
 
+// Apply the global options to the settings shared by all notifiers, it is called when the options are loaded or
+// changed rather than on every notification. The default settings are used if the options are not set.
+func applyGlobalOptions(opts *v1alpha1.Options) {
+
+	global := &v1alpha1.GlobalOptions{}
+	if opts != nil && opts.Global != nil {
+		global = opts.Global
+	}
+
+	notifier.GetSendBudget().SetLimit(global.MaxSendsPerMinute)
+	notifier.SetConnectionPool(global.MaxIdleConns, global.MaxIdleConnsPerHost, global.IdleConnTimeout)
+	notifier.SetMaxLabels(global.MaxLabelsPerAlert)
+	notifier.SetExternalLabels(global.ExternalLabels)
+	notifier.GetRetryBudget().SetRatio(float64(global.RetryBudgetPercent) / 100)
+}
+
 func (c *Config) tenantIDFromNs(namespace *string) ([]string, error) {
 	tenantIDs := make([]string, 0)
 	// Use namespace as TenantID directly if tenantKey is "namespace"
@@ -731,6 +1016,7 @@ func (c *Config) getReceiver(p *param) {
 			return
 		}
 
+		receiver.SetName(p.name)
 		receiver.SetNamespace(p.namespace)
 
 		if p.isConfig {
@@ -773,10 +1059,144 @@ func (c *Config) GetSecretData(namespace string, selector *v1.SecretKeySelector)
 		return "", fmt.Errorf("SecretKeySelector is nil")
 	}
 
+	if scheme, path, key := parseSecretRef(selector.Name, selector.Key); len(scheme) > 0 {
+		return c.resolveSecret(namespace, scheme, path, key)
+	}
+
+	if data, ok := c.secrets.get(namespace, selector.Name); ok {
+		return string(data[selector.Key]), nil
+	}
+
 	secret := v1.Secret{}
 	if err := c.cache.Get(c.ctx, types.NamespacedName{Namespace: namespace, Name: selector.Name}, &secret); err != nil {
 		return "", err
 	}
 
+	c.secrets.set(namespace, selector.Name, secret.Data)
 	return string(secret.Data[selector.Key]), nil
 }
+
+// GetSecretValue returns the value itself if it is a plaintext, or the data of the secret if the value is a
+// secret reference in form of `secret://name#key`, `env://NAME` or `vault://path#key`.
+func (c *Config) GetSecretValue(namespace, value string) (string, error) {
+
+	scheme, path, key := parseSecretRef(value, "")
+	if len(scheme) == 0 {
+		return value, nil
+	}
+
+	if scheme == SecretSchemeSecret {
+		if len(path) == 0 || len(key) == 0 {
+			return "", fmt.Errorf("invalid secret reference %s", value)
+		}
+		return c.GetSecretData(namespace, &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: path},
+			Key:                  key,
+		})
+	}
+
+	// The reference of the scheme without resolver is rejected rather than used as a plaintext.
+	return c.resolveSecret(namespace, scheme, path, key)
+}
+
+func (c *Config) resolveSecret(namespace, scheme, path, key string) (string, error) {
+
+	r, ok := c.resolvers[scheme]
+	if !ok {
+		return "", fmt.Errorf("unknown secret scheme %s", scheme)
+	}
+
+	if !c.secretRefAllowed(namespace, scheme, path) {
+		return "", fmt.Errorf("secret reference %s%s%s is not allowed in namespace %s", scheme, secretSchemeSeparator, path, namespace)
+	}
+
+	name := path + "#" + key
+	if data, ok := c.secrets.get(scheme+secretSchemeSeparator, name); ok {
+		return string(data[key]), nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, secretResolveTimeout)
+	defer cancel()
+
+	v, err := r.Resolve(ctx, path, key)
+	if err != nil {
+		return "", err
+	}
+
+	c.secrets.set(scheme+secretSchemeSeparator, name, map[string][]byte{key: []byte(v)})
+	return v, nil
+}
+
+// RegisterSecretResolver registers the resolver of the secrets referenced in form of `scheme://path#key`.
+// It should be called before the config runs.
+func (c *Config) RegisterSecretResolver(scheme string, r SecretResolver) {
+	c.resolvers[scheme] = r
+}
+
+// SetSecretRefAllowList sets the secret references which the configs out of the namespace of notification manager can use.
+// Each rule is in form of `namespace=prefix`, such as `tenant-a=vault://secret/tenant-a/`, the references used in the
+// namespace must start with one of its prefixes. The configs in the namespace of notification manager can use any reference.
+func (c *Config) SetSecretRefAllowList(rules []string) error {
+
+	allowList := make(map[string][]string)
+	for _, rule := range rules {
+		i := strings.Index(rule, "=")
+		if i <= 0 || i == len(rule)-1 {
+			return fmt.Errorf("invalid secret reference rule %s", rule)
+		}
+
+		namespace, prefix := rule[:i], rule[i+1:]
+		if !strings.Contains(prefix, secretSchemeSeparator) {
+			return fmt.Errorf("invalid secret reference rule %s, the prefix must start with the scheme", rule)
+		}
+		allowList[namespace] = append(allowList[namespace], prefix)
+	}
+
+	c.secretRefAllowList = allowList
+	return nil
+}
+
+// ExpandEnv expands the environment variables in form of ${ENV_VAR} in the value. The configs out of the namespace of
+// notification manager can only use the variables allowed in form of `env://ENV_VAR`, the others are expanded to empty.
+func (c *Config) ExpandEnv(namespace, value string) string {
+
+	return os.Expand(value, func(name string) string {
+		if !c.secretRefAllowed(namespace, SecretSchemeEnv, name) {
+			_ = level.Warn(c.logger).Log("msg", "environment variable is not allowed", "name", name, "namespace", namespace)
+			return ""
+		}
+		return os.Getenv(name)
+	})
+}
+
+// Whether the configs in the namespace can use the secret reference. The references out of the namespace of notification
+// manager are limited to the prefixes of the namespace, so that the tenants can not read the secrets of notification manager.
+func (c *Config) secretRefAllowed(namespace, scheme, path string) bool {
+
+	if len(c.namespace) > 0 && namespace == c.namespace {
+		return true
+	}
+
+	// The reference going up the path could escape from the prefix.
+	if strings.Contains(path, "..") {
+		return false
+	}
+
+	ref := scheme + secretSchemeSeparator + path
+	for _, prefix := range c.secretRefAllowList[namespace] {
+		if strings.HasPrefix(ref, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetSecretCacheTTL sets the time to live of the cached secrets, the cache is disabled if the ttl is 0.
+func (c *Config) SetSecretCacheTTL(ttl time.Duration) {
+	c.secrets.setTTL(ttl)
+}
+
+func (c *Config) GetClient() client.Client {
+	return c.client
+}
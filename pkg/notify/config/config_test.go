@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"github.com/go-kit/kit/log"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sync/atomic"
+	"testing"
+)
+
+const testNamespace = "kubesphere-monitoring-system"
+
+// A cache reading the objects from the fake client, it counts the objects read.
+type fakeCache struct {
+	*informertest.FakeInformers
+	reader client.Reader
+	gets   int32
+}
+
+func (c *fakeCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	atomic.AddInt32(&c.gets, 1)
+	return c.reader.Get(ctx, key, obj)
+}
+
+func (c *fakeCache) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+// Create the config reading the objects given, the namespace of notification manager is testNamespace.
+func newTestConfig(t testing.TB, objs ...runtime.Object) *Config {
+
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	if err := os.Setenv("NAMESPACE", testNamespace); err != nil {
+		t.Fatalf("set namespace error, %s", err)
+	}
+
+	c := fake.NewFakeClientWithScheme(scheme, objs...)
+	return NewWithClient(context.Background(), log.NewNopLogger(), &fakeCache{FakeInformers: &informertest.FakeInformers{Scheme: scheme}, reader: c}, c, nil)
+}
+
+func newWechatConfig(namespace string) *v1alpha1.WechatConfig {
+	return &v1alpha1.WechatConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wechat-config",
+			Namespace: namespace,
+			Labels:    map[string]string{"type": "default"},
+		},
+		Spec: v1alpha1.WechatConfigSpec{
+			WechatApiCorpId:  "corp",
+			WechatApiAgentId: "1000002",
+			WechatApiSecret: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: "wechat-secret"},
+				Key:                  "secret",
+			},
+		},
+	}
+}
+
+func newWechatReceiver(namespace string, spec v1alpha1.WechatReceiverSpec) *v1alpha1.WechatReceiver {
+	spec.WechatConfigSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"type": "default"}}
+	return &v1alpha1.WechatReceiver{
+		ObjectMeta: metav1.ObjectMeta{Name: "wechat-receiver", Namespace: namespace},
+		Spec:       spec,
+	}
+}
+
+func TestWechatGenerateReceiverFlags(t *testing.T) {
+
+	c := newTestConfig(t, newWechatConfig(testNamespace))
+
+	w := NewWechatReceiver().(*Wechat)
+	w.GenerateReceiver(c, newWechatReceiver(testNamespace, v1alpha1.WechatReceiverSpec{
+		ToUser:                 "user1",
+		Confidential:           true,
+		EnableDuplicateCheck:   true,
+		DuplicateCheckInterval: 600,
+	}))
+
+	if w.WechatConfig == nil {
+		t.Fatal("expected the wechat config selected")
+	}
+
+	if !w.Confidential || !w.EnableDuplicateCheck || w.DuplicateCheckInterval != 600 {
+		t.Errorf("expected the flags copied, got confidential %t, duplicate check %t, interval %d",
+			w.Confidential, w.EnableDuplicateCheck, w.DuplicateCheckInterval)
+	}
+
+	if w.MsgType != WechatText {
+		t.Errorf("expected the default message type %s, got %s", WechatText, w.MsgType)
+	}
+
+	if err := w.Validate(); err != nil {
+		t.Errorf("expected the receiver valid, got %s", err)
+	}
+}
+
+func TestReceiverEnabled(t *testing.T) {
+
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		enabled  *bool
+		expected bool
+	}{
+		{"not set", nil, true},
+		{"enabled", &enabled, true},
+		{"disabled", &disabled, false},
+	}
+
+	c := newTestConfig(t, newWechatConfig(testNamespace))
+	for _, test := range tests {
+		w := NewWechatReceiver().(*Wechat)
+		w.GenerateReceiver(c, newWechatReceiver(testNamespace, v1alpha1.WechatReceiverSpec{
+			ToUser:  "user1",
+			Enabled: test.enabled,
+		}))
+
+		if w.Enabled() != test.expected {
+			t.Errorf("%s: expected enabled %t, got %t", test.name, test.expected, w.Enabled())
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kcache "k8s.io/client-go/tools/cache"
+	"strconv"
+)
+
+const (
+	DefaultPauseConfigMap = "notification-manager-pause"
+	pauseKey              = "paused"
+)
+
+// SetPauseConfigMap sets the name of the configmap which stores the pause state, it is in the namespace which
+// notification manager in. The pause state is not stored if the name is empty.
+func (c *Config) SetPauseConfigMap(name string) {
+	c.pauseConfigMap = name
+}
+
+// SetPaused pauses or resumes the notifications, and stores the state in the pause configmap,
+// so that it is shared by all replicas and kept after restarting.
+func (c *Config) SetPaused(ctx context.Context, paused bool) error {
+
+	if len(c.pauseConfigMap) > 0 {
+		cm := &v1.ConfigMap{}
+		if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: c.pauseConfigMap}, cm); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      c.pauseConfigMap,
+					Namespace: c.namespace,
+				},
+			}
+		}
+
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[pauseKey] = strconv.FormatBool(paused)
+
+		var err error
+		if len(cm.ResourceVersion) == 0 {
+			err = c.client.Create(ctx, cm)
+		} else {
+			err = c.client.Update(ctx, cm)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	notifier.SetPaused(paused)
+	return nil
+}
+
+// Pause or resume the notifications when the pause configmap changed, deleting the configmap resumes the notifications.
+func (c *Config) onPauseConfigMapChanged(obj interface{}, deleted bool) {
+
+	if d, ok := obj.(kcache.DeletedFinalStateUnknown); ok {
+		obj = d.Obj
+	}
+
+	cm, ok := obj.(*v1.ConfigMap)
+	if !ok || cm.Namespace != c.namespace || cm.Name != c.pauseConfigMap {
+		return
+	}
+
+	paused := false
+	if !deleted {
+		v, err := strconv.ParseBool(cm.Data[pauseKey])
+		if err != nil {
+			_ = level.Error(c.logger).Log("msg", "invalid pause state", "configmap", cm.Name, "value", cm.Data[pauseKey])
+			return
+		}
+		paused = v
+	}
+
+	if notifier.Paused() != paused {
+		notifier.SetPaused(paused)
+		_ = level.Info(c.logger).Log("msg", "pause state changed", "configmap", cm.Name, "paused", paused)
+	}
+}
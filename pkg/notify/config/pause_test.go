@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"strconv"
+	"testing"
+)
+
+func newPauseConfigMap(namespace, paused string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: DefaultPauseConfigMap, Namespace: namespace},
+		Data:       map[string]string{pauseKey: paused},
+	}
+}
+
+func TestSetPausedStored(t *testing.T) {
+
+	c := newTestConfig(t)
+	c.SetPauseConfigMap(DefaultPauseConfigMap)
+	defer notifier.SetPaused(false)
+
+	for _, paused := range []bool{true, true, false} {
+		if err := c.SetPaused(context.Background(), paused); err != nil {
+			t.Fatalf("set paused error, %s", err)
+		}
+
+		if notifier.Paused() != paused {
+			t.Errorf("expected paused %t, got %t", paused, notifier.Paused())
+		}
+
+		cm := &v1.ConfigMap{}
+		if err := c.client.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: DefaultPauseConfigMap}, cm); err != nil {
+			t.Fatalf("get pause configmap error, %s", err)
+		}
+
+		if got := cm.Data[pauseKey]; got != strconv.FormatBool(paused) {
+			t.Errorf("expected stored pause state %t, got %q", paused, got)
+		}
+	}
+}
+
+func TestPauseConfigMapWatched(t *testing.T) {
+
+	c := newTestConfig(t)
+	c.SetPauseConfigMap(DefaultPauseConfigMap)
+	defer notifier.SetPaused(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.ctx = ctx
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("run error, %s", err)
+	}
+
+	informer, err := c.cache.(*fakeCache).FakeInformerFor(&v1.ConfigMap{})
+	if err != nil {
+		t.Fatalf("get configmap informer error, %s", err)
+	}
+
+	paused := newPauseConfigMap(testNamespace, "true")
+	resumed := newPauseConfigMap(testNamespace, "false")
+
+	tests := []struct {
+		name   string
+		event  func()
+		paused bool
+	}{
+		{"added", func() { informer.Add(paused) }, true},
+		{"other namespace", func() { informer.Update(paused, newPauseConfigMap("default", "false")) }, true},
+		{"invalid", func() { informer.Update(paused, newPauseConfigMap(testNamespace, "maybe")) }, true},
+		{"updated", func() { informer.Update(paused, resumed) }, false},
+		{"paused again", func() { informer.Update(resumed, paused) }, true},
+		{"deleted", func() { informer.Delete(paused) }, false},
+	}
+
+	for _, tt := range tests {
+		tt.event()
+		if notifier.Paused() != tt.paused {
+			t.Errorf("%s: expected paused %t, got %t", tt.name, tt.paused, notifier.Paused())
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	json "github.com/json-iterator/go"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SecretSchemeEnv   = "env"
+	SecretSchemeVault = "vault"
+	// The value in form of `secret://name#key` references the key of a kubernetes secret.
+	SecretSchemeSecret = "secret"
+
+	secretSchemeSeparator = "://"
+
+	DefaultVaultAuthPath  = "kubernetes"
+	DefaultVaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	secretResolveTimeout = 10 * time.Second
+)
+
+// SecretResolver resolves the value of the secret which is referenced in form of `scheme://path#key`.
+type SecretResolver interface {
+	Resolve(ctx context.Context, path, key string) (string, error)
+}
+
+// Split the secret reference into scheme, path and key, the scheme is empty if the secret is a kubernetes secret.
+// The key in the reference takes precedence over the key of the SecretKeySelector.
+func parseSecretRef(name, key string) (string, string, string) {
+
+	i := strings.Index(name, secretSchemeSeparator)
+	if i < 0 {
+		return "", name, key
+	}
+
+	scheme := name[:i]
+	path := name[i+len(secretSchemeSeparator):]
+	if j := strings.LastIndex(path, "#"); j >= 0 {
+		if len(path[j+1:]) > 0 {
+			key = path[j+1:]
+		}
+		path = path[:j]
+	}
+
+	return scheme, path, key
+}
+
+// envResolver reads the secret from the environment variable of the notification manager, in form of `env://NAME`.
+type envResolver struct{}
+
+func NewEnvResolver() SecretResolver {
+	return &envResolver{}
+}
+
+func (r *envResolver) Resolve(_ context.Context, path, _ string) (string, error) {
+
+	v, ok := os.LookupEnv(path)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not found", path)
+	}
+
+	return v, nil
+}
+
+// vaultResolver reads the secret from the Vault, in form of `vault://path#key`.
+// It logs in the Vault with the kubernetes auth method using the service account token of the pod,
+// the service account token itself is never sent as the Vault token.
+type vaultResolver struct {
+	address   string
+	role      string
+	authPath  string
+	tokenFile string
+	client    *http.Client
+
+	mutex sync.Mutex
+	token string
+	// The time to renew the token, the token never expires if it is zero.
+	expireAt time.Time
+}
+
+func NewVaultResolver(address, role, authPath, tokenFile string) SecretResolver {
+
+	if len(authPath) == 0 {
+		authPath = DefaultVaultAuthPath
+	}
+
+	if len(tokenFile) == 0 {
+		tokenFile = DefaultVaultTokenFile
+	}
+
+	return &vaultResolver{
+		address:   strings.TrimSuffix(address, "/"),
+		role:      role,
+		authPath:  strings.Trim(authPath, "/"),
+		tokenFile: tokenFile,
+		client:    &http.Client{},
+	}
+}
+
+type vaultLogin struct {
+	Role string `json:"role"`
+	JWT  string `json:"jwt"`
+}
+
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Auth   *vaultAuth             `json:"auth"`
+	Errors []string               `json:"errors"`
+}
+
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+}
+
+func (r *vaultResolver) Resolve(ctx context.Context, path, key string) (string, error) {
+
+	token, err := r.getToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := r.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), token, nil)
+	if err != nil {
+		return "", err
+	}
+
+	data := res.Data
+	// The secret of the KV version 2 engine is nested in the `data` field.
+	if d, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = d
+		}
+	}
+
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, path)
+	}
+
+	return fmt.Sprint(v), nil
+}
+
+func (r *vaultResolver) getToken(ctx context.Context) (string, error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.token) > 0 && (r.expireAt.IsZero() || time.Now().Before(r.expireAt)) {
+		return r.token, nil
+	}
+
+	if len(r.role) == 0 {
+		return "", fmt.Errorf("vault role is empty")
+	}
+
+	bs, err := ioutil.ReadFile(r.tokenFile)
+	if err != nil {
+		return "", err
+	}
+	jwt := strings.TrimSpace(string(bs))
+
+	body, err := json.Marshal(&vaultLogin{
+		Role: r.role,
+		JWT:  jwt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	res, err := r.do(ctx, http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", r.authPath), "", body)
+	if err != nil {
+		return "", err
+	}
+
+	if res.Auth == nil || len(res.Auth.ClientToken) == 0 {
+		return "", fmt.Errorf("vault login error, empty client token")
+	}
+
+	r.token = res.Auth.ClientToken
+	// Renew the token before it expired. The token with the lease duration 0 never expires,
+	// it is only renewed when it is rejected by the Vault.
+	r.expireAt = time.Time{}
+	if res.Auth.LeaseDuration > 0 {
+		r.expireAt = time.Now().Add(time.Duration(res.Auth.LeaseDuration) * time.Second / 2)
+	}
+	return r.token, nil
+}
+
+func (r *vaultResolver) do(ctx context.Context, method, path, token string, body []byte) (*vaultResponse, error) {
+
+	request, err := http.NewRequest(method, r.address+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+	if len(token) > 0 {
+		request.Header.Set("X-Vault-Token", token)
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	bs, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &vaultResponse{}
+	if len(bs) > 0 {
+		if err := json.Unmarshal(bs, res); err != nil {
+			return nil, err
+		}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		if response.StatusCode == http.StatusForbidden && len(token) > 0 {
+			r.resetToken(token)
+		}
+		return nil, fmt.Errorf("vault request error, status code: %d, errors: %s", response.StatusCode, strings.Join(res.Errors, ", "))
+	}
+
+	return res, nil
+}
+
+// Drop the token if it is rejected by the Vault, so that a new token will be used next time.
+func (r *vaultResolver) resetToken(token string) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.token == token {
+		r.token = ""
+	}
+}
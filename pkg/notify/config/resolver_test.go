@@ -0,0 +1,273 @@
+package config
+
+import (
+	"fmt"
+	json "github.com/json-iterator/go"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func refSelector(name, key string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: key}
+}
+
+func TestParseSecretRef(t *testing.T) {
+
+	tests := []struct {
+		name, key                 string
+		scheme, wantPath, wantKey string
+	}{
+		{"wechat-secret", "secret", "", "wechat-secret", "secret"},
+		{"env://WECHAT_SECRET", "", "env", "WECHAT_SECRET", ""},
+		{"vault://secret/data/wechat#secret", "", "vault", "secret/data/wechat", "secret"},
+		// The key in the reference takes precedence over the key of the selector.
+		{"vault://secret/data/wechat#token", "secret", "vault", "secret/data/wechat", "token"},
+		{"vault://secret/data/wechat#", "secret", "vault", "secret/data/wechat", "secret"},
+	}
+
+	for _, tt := range tests {
+		scheme, path, key := parseSecretRef(tt.name, tt.key)
+		if scheme != tt.scheme || path != tt.wantPath || key != tt.wantKey {
+			t.Errorf("%s: expected %q, %q, %q, got %q, %q, %q", tt.name, tt.scheme, tt.wantPath, tt.wantKey, scheme, path, key)
+		}
+	}
+}
+
+func TestEnvSecret(t *testing.T) {
+
+	_ = os.Setenv("TEST_ENV_SECRET", "env-value")
+	defer os.Unsetenv("TEST_ENV_SECRET")
+
+	c := newTestConfig(t)
+
+	if v, err := c.GetSecretData(testNamespace, refSelector("env://TEST_ENV_SECRET", "")); err != nil || v != "env-value" {
+		t.Errorf("expected env-value, got %q, %v", v, err)
+	}
+
+	if v, err := c.GetSecretValue(testNamespace, "env://TEST_ENV_SECRET"); err != nil || v != "env-value" {
+		t.Errorf("expected env-value, got %q, %v", v, err)
+	}
+
+	if _, err := c.GetSecretData(testNamespace, refSelector("env://TEST_ENV_MISSING", "")); err == nil {
+		t.Error("expected the error of the missing environment variable")
+	}
+
+	// The plaintext is returned as it is.
+	if v, err := c.GetSecretValue(testNamespace, "plain"); err != nil || v != "plain" {
+		t.Errorf("expected the plaintext, got %q, %v", v, err)
+	}
+}
+
+func TestKubernetesSecretValue(t *testing.T) {
+
+	c := newTestConfig(t, newSecret("v1"))
+
+	if v, err := c.GetSecretValue(testNamespace, "secret://wechat-secret#secret"); err != nil || v != "v1" {
+		t.Errorf("expected v1, got %q, %v", v, err)
+	}
+
+	if _, err := c.GetSecretValue(testNamespace, "secret://wechat-secret"); err == nil {
+		t.Error("expected the error of the reference without key")
+	}
+}
+
+func TestUnregisteredSecretSchemeValue(t *testing.T) {
+
+	// The vault resolver is not registered if the vault address is not set.
+	c := newTestConfig(t)
+
+	v, err := c.GetSecretValue(testNamespace, "vault://kv/wechat#corpid")
+	if err == nil || !strings.Contains(err.Error(), "unknown secret scheme vault") {
+		t.Errorf("expected the error of the unknown scheme, got %q, %v", v, err)
+	}
+}
+
+func TestUnknownSecretScheme(t *testing.T) {
+
+	c := newTestConfig(t)
+
+	_, err := c.GetSecretData(testNamespace, refSelector("aws://wechat", "secret"))
+	if err == nil || !strings.Contains(err.Error(), "unknown secret scheme aws") {
+		t.Errorf("expected the error of the unknown scheme, got %v", err)
+	}
+}
+
+// A vault server with the kubernetes auth method and a KV version 2 engine.
+type vaultServer struct {
+	*httptest.Server
+	logins int32
+	// The secret is rejected with 403 once if it is set.
+	reject int32
+	// The lease duration of the tokens in seconds.
+	leaseDuration int32
+}
+
+func newVaultServer(t *testing.T) *vaultServer {
+
+	s := &vaultServer{leaseDuration: 3600}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var login vaultLogin
+		if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
+			t.Errorf("decode login error, %s", err)
+		}
+
+		if login.Role != "notification-manager" || login.JWT != "sa-token" {
+			t.Errorf("unexpected login %+v", login)
+		}
+
+		n := atomic.AddInt32(&s.logins, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": fmt.Sprintf("vault-token-%d", n), "lease_duration": atomic.LoadInt32(&s.leaseDuration)},
+		})
+	})
+	mux.HandleFunc("/v1/secret/data/wechat", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.CompareAndSwapInt32(&s.reject, 1, 0) || !strings.HasPrefix(r.Header.Get("X-Vault-Token"), "vault-token-") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"data":{"secret":"vault-value"},"metadata":{"version":1}}}`))
+	})
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Write the service account token used to login the vault, the file is removed by the function returned.
+func newTokenFile(t *testing.T) (string, func()) {
+
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatalf("create dir error, %s", err)
+	}
+
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("sa-token\n"), 0600); err != nil {
+		t.Fatalf("write token error, %s", err)
+	}
+
+	return tokenFile, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestVaultSecret(t *testing.T) {
+
+	s := newVaultServer(t)
+	defer s.Close()
+
+	tokenFile, remove := newTokenFile(t)
+	defer remove()
+
+	c := newTestConfig(t)
+	c.SetSecretCacheTTL(0)
+	c.RegisterSecretResolver(SecretSchemeVault, NewVaultResolver(s.URL+"/", "notification-manager", "", tokenFile))
+
+	for i := 0; i < 2; i++ {
+		v, err := c.GetSecretData(testNamespace, refSelector("vault://secret/data/wechat#secret", ""))
+		if err != nil || v != "vault-value" {
+			t.Fatalf("expected vault-value, got %q, %v", v, err)
+		}
+	}
+
+	// The token is reused until it is rejected.
+	if n := atomic.LoadInt32(&s.logins); n != 1 {
+		t.Errorf("expected 1 login, got %d", n)
+	}
+
+	atomic.StoreInt32(&s.reject, 1)
+	if _, err := c.GetSecretData(testNamespace, refSelector("vault://secret/data/wechat#secret", "")); err == nil {
+		t.Error("expected the error of the rejected token")
+	}
+
+	if v, err := c.GetSecretData(testNamespace, refSelector("vault://secret/data/wechat#secret", "")); err != nil || v != "vault-value" {
+		t.Errorf("expected vault-value with the new token, got %q, %v", v, err)
+	}
+
+	if n := atomic.LoadInt32(&s.logins); n != 2 {
+		t.Errorf("expected to login again after the token is rejected, got %d logins", n)
+	}
+
+	if _, err := c.GetSecretData(testNamespace, refSelector("vault://secret/data/wechat#token", "")); err == nil {
+		t.Error("expected the error of the missing key")
+	}
+}
+
+func TestVaultSecretNonExpiringToken(t *testing.T) {
+
+	// The token with the lease duration 0 never expires.
+	s := newVaultServer(t)
+	s.leaseDuration = 0
+	defer s.Close()
+
+	tokenFile, remove := newTokenFile(t)
+	defer remove()
+
+	c := newTestConfig(t)
+	c.SetSecretCacheTTL(0)
+	c.RegisterSecretResolver(SecretSchemeVault, NewVaultResolver(s.URL, "notification-manager", "", tokenFile))
+
+	for i := 0; i < 3; i++ {
+		v, err := c.GetSecretData(testNamespace, refSelector("vault://secret/data/wechat#secret", ""))
+		if err != nil || v != "vault-value" {
+			t.Fatalf("expected vault-value, got %q, %v", v, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&s.logins); n != 1 {
+		t.Errorf("expected the token reused, got %d logins", n)
+	}
+}
+
+func TestSecretRefAllowList(t *testing.T) {
+
+	_ = os.Setenv("TEST_ENV_SECRET", "env-value")
+	defer os.Unsetenv("TEST_ENV_SECRET")
+	_ = os.Setenv("TENANT_A_SECRET", "tenant-value")
+	defer os.Unsetenv("TENANT_A_SECRET")
+
+	c := newTestConfig(t)
+	if err := c.SetSecretRefAllowList([]string{"tenant-a=env://TENANT_A_"}); err != nil {
+		t.Fatalf("set allow list error, %s", err)
+	}
+
+	tests := []struct {
+		namespace, ref string
+		allowed        bool
+	}{
+		// The configs in the namespace of notification manager can use any reference.
+		{testNamespace, "env://TEST_ENV_SECRET", true},
+		{"tenant-a", "env://TENANT_A_SECRET", true},
+		{"tenant-a", "env://TEST_ENV_SECRET", false},
+		{"tenant-b", "env://TENANT_A_SECRET", false},
+	}
+
+	for _, tt := range tests {
+		_, err := c.GetSecretData(tt.namespace, refSelector(tt.ref, ""))
+		if tt.allowed && err != nil {
+			t.Errorf("%s in %s: expected allowed, got %s", tt.ref, tt.namespace, err)
+		}
+
+		if !tt.allowed && (err == nil || !strings.Contains(err.Error(), "is not allowed")) {
+			t.Errorf("%s in %s: expected not allowed, got %v", tt.ref, tt.namespace, err)
+		}
+	}
+
+	// The variables not allowed are expanded to empty.
+	if v := c.ExpandEnv("tenant-a", "${TENANT_A_SECRET}/${TEST_ENV_SECRET}"); v != "tenant-value/" {
+		t.Errorf("expected tenant-value/, got %q", v)
+	}
+
+	for _, rule := range []string{"tenant-a", "=env://", "tenant-a=", "tenant-a=TENANT_"} {
+		if err := c.SetSecretRefAllowList([]string{rule}); err == nil {
+			t.Errorf("expected the error of the invalid rule %q", rule)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"k8s.io/api/core/v1"
+	kcache "k8s.io/client-go/tools/cache"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSecretCacheTTL = time.Minute
+)
+
+// secretCache caches the data of secrets, so that the secrets are not read from the informer cache, which deep copies
+// the whole secret for each read, or from the external backends such as vault every time a notification is sent.
+// The secret is dropped from the cache when it expired, or it is updated or deleted.
+type secretCache struct {
+	mutex   sync.RWMutex
+	ttl     time.Duration
+	secrets map[string]*secretItem
+	// The last time the expired secrets were dropped.
+	evictedAt time.Time
+}
+
+type secretItem struct {
+	data     map[string][]byte
+	expireAt time.Time
+}
+
+func newSecretCache(ttl time.Duration) *secretCache {
+	return &secretCache{
+		ttl:     ttl,
+		secrets: make(map[string]*secretItem),
+	}
+}
+
+func secretKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// Get the data of the secret, the second return value is false if the secret is not cached or expired.
+func (c *secretCache) get(namespace, name string) (map[string][]byte, bool) {
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	item, ok := c.secrets[secretKey(namespace, name)]
+	if !ok || time.Now().After(item.expireAt) {
+		return nil, false
+	}
+
+	return item.data, true
+}
+
+func (c *secretCache) set(namespace, name string, data map[string][]byte) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	c.evict(now)
+	c.secrets[secretKey(namespace, name)] = &secretItem{
+		data:     data,
+		expireAt: now.Add(c.ttl),
+	}
+}
+
+// Drop the expired secrets at most once per ttl, so the secrets which are not used any more are not kept,
+// and the cache holds at most the secrets set within two ttl. It must be called with the mutex held.
+func (c *secretCache) evict(now time.Time) {
+
+	if now.Sub(c.evictedAt) < c.ttl {
+		return
+	}
+
+	for key, item := range c.secrets {
+		if now.After(item.expireAt) {
+			delete(c.secrets, key)
+		}
+	}
+	c.evictedAt = now
+}
+
+func (c *secretCache) invalidate(obj interface{}) {
+
+	if tombstone, ok := obj.(kcache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.secrets, secretKey(secret.Namespace, secret.Name))
+}
+
+// Set the time to live of the cached secrets, the cache is disabled if the ttl is 0.
+func (c *secretCache) setTTL(ttl time.Duration) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.ttl = ttl
+	if ttl <= 0 {
+		c.secrets = make(map[string]*secretItem)
+	}
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kcache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSecret(value string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "wechat-secret", Namespace: testNamespace},
+		Data:       map[string][]byte{"secret": []byte(value)},
+	}
+}
+
+func secretSelector() *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{
+		LocalObjectReference: v1.LocalObjectReference{Name: "wechat-secret"},
+		Key:                  "secret",
+	}
+}
+
+func getSecret(t testing.TB, c *Config, want string) {
+
+	t.Helper()
+
+	got, err := c.GetSecretData(testNamespace, secretSelector())
+	if err != nil {
+		t.Fatalf("get secret error, %s", err)
+	}
+
+	if got != want {
+		t.Errorf("expected secret %q, got %q", want, got)
+	}
+}
+
+func gets(c *Config) int32 {
+	return atomic.LoadInt32(&c.cache.(*fakeCache).gets)
+}
+
+func TestGetSecretDataCached(t *testing.T) {
+
+	c := newTestConfig(t, newSecret("v1"))
+
+	for i := 0; i < 10; i++ {
+		getSecret(t, c, "v1")
+	}
+
+	if n := gets(c); n != 1 {
+		t.Errorf("expected the secret read once, got %d", n)
+	}
+
+	// The cache is disabled if the ttl is 0.
+	c.SetSecretCacheTTL(0)
+	getSecret(t, c, "v1")
+	getSecret(t, c, "v1")
+
+	if n := gets(c); n != 3 {
+		t.Errorf("expected the secret read every time, got %d reads", n)
+	}
+}
+
+func TestGetSecretDataExpired(t *testing.T) {
+
+	c := newTestConfig(t, newSecret("v1"))
+	c.SetSecretCacheTTL(time.Millisecond * 10)
+
+	getSecret(t, c, "v1")
+	getSecret(t, c, "v1")
+	time.Sleep(time.Millisecond * 20)
+	getSecret(t, c, "v1")
+
+	if n := gets(c); n != 2 {
+		t.Errorf("expected the expired secret read again, got %d reads", n)
+	}
+}
+
+func TestGetSecretDataConcurrent(t *testing.T) {
+
+	c := newTestConfig(t, newSecret("v1"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			getSecret(t, c, "v1")
+		}()
+	}
+	wg.Wait()
+
+	if n := gets(c); n > 50 {
+		t.Errorf("expected at most 50 reads, got %d", n)
+	}
+}
+
+func TestSecretCacheInvalidation(t *testing.T) {
+
+	old := newSecret("v1")
+	c := newTestConfig(t, old)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.ctx = ctx
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("run error, %s", err)
+	}
+
+	informer, err := c.cache.(*fakeCache).FakeInformerFor(&v1.Secret{})
+	if err != nil {
+		t.Fatalf("get secret informer error, %s", err)
+	}
+
+	getSecret(t, c, "v1")
+
+	// Update the secret, the cached value is dropped.
+	updated := newSecret("v2")
+	updated.ResourceVersion = old.ResourceVersion
+	if err := c.client.Update(context.Background(), updated); err != nil {
+		t.Fatalf("update secret error, %s", err)
+	}
+
+	getSecret(t, c, "v1")
+	informer.Update(old, updated)
+	getSecret(t, c, "v2")
+
+	// The deleted secret is dropped, even if the last state is unknown.
+	c.secrets.invalidate(kcache.DeletedFinalStateUnknown{Key: "kubesphere-monitoring-system/wechat-secret", Obj: updated})
+	if err := c.client.Delete(context.Background(), updated); err != nil {
+		t.Fatalf("delete secret error, %s", err)
+	}
+
+	if _, err := c.GetSecretData(testNamespace, secretSelector()); err == nil {
+		t.Error("expected the error of the deleted secret")
+	}
+}
+
+func TestSecretCacheEviction(t *testing.T) {
+
+	c := newSecretCache(time.Millisecond * 10)
+	for i := 0; i < 10; i++ {
+		c.set(testNamespace, fmt.Sprintf("secret-%d", i), nil)
+	}
+
+	// The expired secrets are dropped when the next secret is set, so the churned secrets are not kept.
+	time.Sleep(time.Millisecond * 20)
+	c.set(testNamespace, "secret-new", nil)
+
+	c.mutex.RLock()
+	n := len(c.secrets)
+	c.mutex.RUnlock()
+	if n != 1 {
+		t.Errorf("expected the expired secrets dropped, got %d secrets", n)
+	}
+
+	if _, ok := c.get(testNamespace, "secret-new"); !ok {
+		t.Error("expected the new secret cached")
+	}
+}
+
+// A reader serving the secrets as the informer cache of controller-runtime does,
+// the secret in the store is deep copied for each read.
+type informerReader struct {
+	client.Reader
+	store kcache.Store
+}
+
+func (r *informerReader) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+
+	item, exists, err := r.store.GetByKey(key.Namespace + "/" + key.Name)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return errors.NewNotFound(v1.Resource("secrets"), key.Name)
+	}
+
+	item.(*v1.Secret).DeepCopyInto(obj.(*v1.Secret))
+	return nil
+}
+
+// The secret read from the informer cache is deep copied, the cost grows with the size of the secret,
+// such as the secret storing a CA bundle.
+func BenchmarkGetSecretData(b *testing.B) {
+
+	secret := newSecret("v1")
+	secret.Data["ca.crt"] = []byte(strings.Repeat("x", 16*1024))
+
+	store := kcache.NewStore(kcache.MetaNamespaceKeyFunc)
+	if err := store.Add(secret); err != nil {
+		b.Fatalf("add secret error, %s", err)
+	}
+
+	for _, ttl := range []time.Duration{0, DefaultSecretCacheTTL} {
+		b.Run("ttl="+ttl.String(), func(b *testing.B) {
+
+			fc := &fakeCache{FakeInformers: &informertest.FakeInformers{}, reader: &informerReader{store: store}}
+			c := NewWithClient(context.Background(), log.NewNopLogger(), fc, nil, nil)
+			c.SetSecretCacheTTL(ttl)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.GetSecretData(testNamespace, secretSelector()); err != nil {
+					b.Fatalf("get secret error, %s", err)
+				}
+			}
+
+			b.ReportMetric(float64(gets(c))/float64(b.N), "reads/op")
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
 )
 
 type factory struct {
@@ -37,7 +38,15 @@ type Receiver interface {
 	SetConfig(c interface{}) error
 	GetTenantID() string
 	SetTenantID(id string)
+	// GetName returns the name of the receiver resource.
+	GetName() string
+	SetName(name string)
 	SetNamespace(ns string)
+	GetAlertSelector() *v1alpha1.AlertSelector
+	// Enabled returns false if the receiver is disabled, nothing is sent to the disabled receiver.
+	Enabled() bool
+	// Validate checks whether the receiver is usable, the invalid receiver will be ignored.
+	Validate() error
 	GenerateConfig(c *Config, obj interface{})
 	GenerateReceiver(c *Config, obj interface{})
 }
@@ -46,7 +55,20 @@ type common struct {
 	// True means receiver use the default config.
 	useDefault bool
 	tenantID   string
+	name       string
 	namespace  string
+	// The alerts sent to the receiver.
+	alertSelector *v1alpha1.AlertSelector
+	// Whether the receiver is enabled, it is enabled if it is not set.
+	enabled *bool
+}
+
+func (c *common) GetAlertSelector() *v1alpha1.AlertSelector {
+	return c.alertSelector
+}
+
+func (c *common) Enabled() bool {
+	return c.enabled == nil || *c.enabled
 }
 
 func (c *common) UseDefault() bool {
@@ -65,6 +87,14 @@ func (c *common) SetTenantID(id string) {
 	c.tenantID = id
 }
 
+func (c *common) GetName() string {
+	return c.name
+}
+
+func (c *common) SetName(name string) {
+	c.name = name
+}
+
 func (c *common) GetNamespace() string {
 	return c.namespace
 }
@@ -74,7 +104,10 @@ func (c *common) SetNamespace(ns string) {
 }
 
 type DingTalk struct {
-	DingTalkConfig *DingTalkConfig
+	// The users and mobiles to be mentioned, the element can be a template.
+	MentionedUsers   []string
+	MentionedMobiles []string
+	DingTalkConfig   *DingTalkConfig
 	*common
 }
 
@@ -161,6 +194,9 @@ func (d *DingTalk) GenerateReceiver(c *Config, obj interface{}) {
 		return
 	}
 
+	d.alertSelector = dr.Spec.AlertSelector
+	d.enabled = dr.Spec.Enabled
+
 	dcList := v1alpha1.DingTalkConfigList{}
 	dcSel, _ := metav1.LabelSelectorAsSelector(dr.Spec.DingTalkConfigSelector)
 	if err := c.cache.List(c.ctx, &dcList, client.MatchingLabelsSelector{Selector: dcSel}); client.IgnoreNotFound(err) != nil {
@@ -168,6 +204,9 @@ func (d *DingTalk) GenerateReceiver(c *Config, obj interface{}) {
 		return
 	}
 
+	d.MentionedUsers = dr.Spec.MentionedUsers
+	d.MentionedMobiles = dr.Spec.MentionedMobiles
+
 	for _, dc := range dcList.Items {
 		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, dc.Namespace) {
 			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", dc.Name, "namespace", dc.Namespace)
@@ -275,6 +314,9 @@ func (e *Email) GenerateReceiver(c *Config, obj interface{}) {
 		return
 	}
 
+	e.alertSelector = er.Spec.AlertSelector
+	e.enabled = er.Spec.Enabled
+
 	e.To = er.Spec.To
 
 	ecList := v1alpha1.EmailConfigList{}
@@ -300,14 +342,20 @@ func (e *Email) GenerateReceiver(c *Config, obj interface{}) {
 
 type Slack struct {
 	// The channel or user to send notifications to.
-	Channel     string
-	SlackConfig *SlackConfig
+	Channel string
+	// The template to generate the url of the image attached to the message.
+	ImageURLTemplate string
+	SlackConfig      *SlackConfig
 	*common
 }
 
 type SlackConfig struct {
 	// The token of user or bot.
 	Token *v1.SecretKeySelector
+	// The secret stores the key which signs the image urls.
+	ImageRenderToken *v1.SecretKeySelector
+	// The time the signed image url is valid.
+	ImageURLExpires time.Duration
 }
 
 func NewSlackReceiver() Receiver {
@@ -350,7 +398,9 @@ func (s *Slack) GenerateConfig(c *Config, obj interface{}) {
 	}
 
 	s.SlackConfig = &SlackConfig{
-		Token: sc.Spec.SlackTokenSecret,
+		Token:            sc.Spec.SlackTokenSecret,
+		ImageRenderToken: sc.Spec.ImageRenderToken,
+		ImageURLExpires:  sc.Spec.ImageURLExpires,
 	}
 
 	return
@@ -364,6 +414,9 @@ func (s *Slack) GenerateReceiver(c *Config, obj interface{}) {
 		return
 	}
 
+	s.alertSelector = sr.Spec.AlertSelector
+	s.enabled = sr.Spec.Enabled
+
 	scList := v1alpha1.SlackConfigList{}
 	scSel, _ := metav1.LabelSelectorAsSelector(sr.Spec.SlackConfigSelector)
 	if err := c.cache.List(c.ctx, &scList, client.MatchingLabelsSelector{Selector: scSel}); client.IgnoreNotFound(err) != nil {
@@ -372,6 +425,7 @@ func (s *Slack) GenerateReceiver(c *Config, obj interface{}) {
 	}
 
 	s.Channel = sr.Spec.Channel
+	s.ImageURLTemplate = sr.Spec.ImageURLTemplate
 
 	for _, sc := range scList.Items {
 		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, sc.Namespace) {
@@ -397,6 +451,18 @@ type WebhookConfig struct {
 	// `url` gives the location of the webhook, in standard URL form.
 	URL        string
 	HttpConfig *v1alpha1.HTTPClientConfig
+	// The HTTP method of the request.
+	Method string
+	// The headers added to the request, and the headers which values are stored in secrets.
+	Headers       map[string]string
+	HeaderSecrets map[string]v1.SecretKeySelector
+	Signature     *v1alpha1.WebhookSignature
+	// The template used to generate the request body, the result must be a valid JSON.
+	BodyTemplate string
+	// The names of the standard fields in the request body, the key is the standard field.
+	FieldMapping map[string]string
+	// Whether to compress the request body with gzip.
+	Compress bool
 }
 
 func NewWebhookReceiver() Receiver {
@@ -434,7 +500,14 @@ func (w *Webhook) GenerateConfig(c *Config, obj interface{}) {
 	}
 
 	webhookConfig := &WebhookConfig{
-		HttpConfig: wc.Spec.HTTPConfig,
+		HttpConfig:    wc.Spec.HTTPConfig,
+		Method:        wc.Spec.Method,
+		Headers:       wc.Spec.Headers,
+		HeaderSecrets: wc.Spec.HeaderSecrets,
+		Signature:     wc.Spec.Signature,
+		BodyTemplate:  wc.Spec.BodyTemplate,
+		FieldMapping:  wc.Spec.FieldMapping,
+		Compress:      wc.Spec.Compress,
 	}
 
 	if wc.Spec.URL != nil {
@@ -470,6 +543,9 @@ func (w *Webhook) GenerateReceiver(c *Config, obj interface{}) {
 		return
 	}
 
+	w.alertSelector = wr.Spec.AlertSelector
+	w.enabled = wr.Spec.Enabled
+
 	wcList := v1alpha1.WebhookConfigList{}
 	wcSel, _ := metav1.LabelSelectorAsSelector(wr.Spec.WebhookConfigSelector)
 	if err := c.cache.List(c.ctx, &wcList, client.MatchingLabelsSelector{Selector: wcSel}); client.IgnoreNotFound(err) != nil {
@@ -491,11 +567,77 @@ func (w *Webhook) GenerateReceiver(c *Config, obj interface{}) {
 	}
 }
 
+// The standard fields of the webhook request body which can be renamed.
+const (
+	WebhookFieldStatus      = "status"
+	WebhookFieldReceiver    = "receiver"
+	WebhookFieldLabels      = "labels"
+	WebhookFieldAnnotations = "annotations"
+	WebhookFieldAlerts      = "alerts"
+	WebhookFieldMessage     = "message"
+)
+
+var WebhookFields = []string{
+	WebhookFieldStatus,
+	WebhookFieldReceiver,
+	WebhookFieldLabels,
+	WebhookFieldAnnotations,
+	WebhookFieldAlerts,
+	WebhookFieldMessage,
+}
+
+const (
+	WechatText         = "text"
+	WechatMarkdown     = "markdown"
+	WechatNews         = "news"
+	WechatImage        = "image"
+	WechatFile         = "file"
+	WechatTemplateCard = "template_card"
+)
+
 type Wechat struct {
-	ToUser       string
-	ToParty      string
-	ToTag        string
-	WechatConfig *WechatConfig
+	ToUser  string
+	ToParty string
+	ToTag   string
+	// The template to generate the tags from the alerts, the ToTag is used if the result is empty.
+	ToTagTemplate string
+	// The id of the application chat which the message will be sent to.
+	ChatID string
+	// Whether the message is confidential.
+	Confidential bool
+	// Whether to enable the duplicate check of WeChat, and the interval of the check in seconds.
+	EnableDuplicateCheck   bool
+	DuplicateCheckInterval int
+	// Whether the duplicate check is keyed by the fingerprints of the alerts.
+	DuplicateCheckByFingerprint bool
+	// The users to be mentioned in the markdown message, the element can be a template.
+	MentionedUsers []string
+	// The annotation used as the title of the text or markdown message.
+	TitleAnnotation string
+	// The type of message, text or markdown.
+	MsgType string
+	// The template of the message, it overrides the template of the notifier.
+	Template string
+	// The media of the image or file message.
+	Media *v1alpha1.WechatMedia
+	// The routes of the alerts, the key is the severity.
+	SeverityRouting map[string]v1alpha1.WechatRoute
+	// Buffer the low priority alerts.
+	Throttle *v1alpha1.PriorityThrottle
+	// Suppress the low severity alerts during the quiet hours.
+	QuietHours *v1alpha1.QuietHours
+	// Send the suppressed alerts in one message on the schedule.
+	Digest *v1alpha1.Digest
+	// The timeout of each request sent to the receiver, it overrides the timeout of the notifier.
+	Timeout time.Duration
+	// The maximum number of alerts in a message.
+	MaxAlertsPerMessage int
+	// Send a summary message rather than the alerts if the number of alerts in a group exceeds it.
+	SummaryThreshold int
+	// Merge the notifications received within the delay into one message.
+	CoalesceDelay     time.Duration
+	CoalesceMaxAlerts int
+	WechatConfig      *WechatConfig
 	*common
 }
 
@@ -504,6 +646,17 @@ type WechatConfig struct {
 	CorpID    string
 	APIURL    string
 	AgentID   string
+	ProxyURL  string
+	ProxyAuth *v1alpha1.BasicAuth
+	TLSConfig *v1alpha1.TLSConfig
+	SendPath  string
+	TokenPath string
+	// The errcode which means the sending succeeded.
+	SuccessCodes []int
+	// The relays which the messages are sent through.
+	APIURLs []v1alpha1.WechatRelay
+	// Whether to compress the message sent to the relays.
+	Compress bool
 }
 
 func NewWechatReceiver() Receiver {
@@ -545,10 +698,18 @@ func (w *Wechat) GenerateConfig(c *Config, obj interface{}) {
 	}
 
 	w.WechatConfig = &WechatConfig{
-		APIURL:    wc.Spec.WechatApiUrl,
-		AgentID:   wc.Spec.WechatApiAgentId,
-		CorpID:    wc.Spec.WechatApiCorpId,
-		APISecret: wc.Spec.WechatApiSecret,
+		APIURL:       wc.Spec.WechatApiUrl,
+		AgentID:      wc.Spec.WechatApiAgentId,
+		CorpID:       wc.Spec.WechatApiCorpId,
+		APISecret:    wc.Spec.WechatApiSecret,
+		ProxyURL:     wc.Spec.ProxyURL,
+		ProxyAuth:    wc.Spec.ProxyAuth,
+		TLSConfig:    wc.Spec.TLSConfig,
+		SendPath:     wc.Spec.SendPath,
+		TokenPath:    wc.Spec.TokenPath,
+		SuccessCodes: wc.Spec.SuccessCodes,
+		APIURLs:      wc.Spec.WechatApiUrls,
+		Compress:     wc.Spec.Compress,
 	}
 }
 
@@ -560,6 +721,9 @@ func (w *Wechat) GenerateReceiver(c *Config, obj interface{}) {
 		return
 	}
 
+	w.alertSelector = wr.Spec.AlertSelector
+	w.enabled = wr.Spec.Enabled
+
 	wcList := v1alpha1.WechatConfigList{}
 	wcSel, _ := metav1.LabelSelectorAsSelector(wr.Spec.WechatConfigSelector)
 	if err := c.cache.List(c.ctx, &wcList, client.MatchingLabelsSelector{Selector: wcSel}); client.IgnoreNotFound(err) != nil {
@@ -570,6 +734,29 @@ func (w *Wechat) GenerateReceiver(c *Config, obj interface{}) {
 	w.ToUser = wr.Spec.ToUser
 	w.ToParty = wr.Spec.ToParty
 	w.ToTag = wr.Spec.ToTag
+	w.ToTagTemplate = wr.Spec.ToTagTemplate
+	w.ChatID = wr.Spec.ChatID
+	w.Confidential = wr.Spec.Confidential
+	w.EnableDuplicateCheck = wr.Spec.EnableDuplicateCheck
+	w.DuplicateCheckInterval = wr.Spec.DuplicateCheckInterval
+	w.DuplicateCheckByFingerprint = wr.Spec.DuplicateCheckByFingerprint
+	w.MentionedUsers = wr.Spec.MentionedUsers
+	w.TitleAnnotation = wr.Spec.TitleAnnotation
+	w.MsgType = wr.Spec.MsgType
+	w.SeverityRouting = wr.Spec.SeverityRouting
+	w.Media = wr.Spec.Media
+	w.Template = wr.Spec.Template
+	w.Throttle = wr.Spec.Throttle
+	w.QuietHours = wr.Spec.QuietHours
+	w.Digest = wr.Spec.Digest
+	w.Timeout = wr.Spec.Timeout
+	w.MaxAlertsPerMessage = wr.Spec.MaxAlertsPerMessage
+	w.SummaryThreshold = wr.Spec.SummaryThreshold
+	w.CoalesceDelay = wr.Spec.CoalesceDelay
+	w.CoalesceMaxAlerts = wr.Spec.CoalesceMaxAlerts
+	if len(w.MsgType) == 0 {
+		w.MsgType = WechatText
+	}
 
 	for _, wc := range wcList.Items {
 
@@ -589,17 +776,1397 @@ func (w *Wechat) Clone() *Wechat {
 
 	return &Wechat{
 		common: &common{
+			tenantID:  w.tenantID,
+			name:      w.name,
 			namespace: w.namespace,
 		},
 		WechatConfig: &WechatConfig{
-			APISecret: w.WechatConfig.APISecret,
-			CorpID:    w.WechatConfig.CorpID,
-			APIURL:    w.WechatConfig.APIURL,
-			AgentID:   w.WechatConfig.AgentID,
+			APISecret:    w.WechatConfig.APISecret,
+			CorpID:       w.WechatConfig.CorpID,
+			APIURL:       w.WechatConfig.APIURL,
+			AgentID:      w.WechatConfig.AgentID,
+			ProxyURL:     w.WechatConfig.ProxyURL,
+			ProxyAuth:    w.WechatConfig.ProxyAuth,
+			TLSConfig:    w.WechatConfig.TLSConfig,
+			SendPath:     w.WechatConfig.SendPath,
+			TokenPath:    w.WechatConfig.TokenPath,
+			SuccessCodes: w.WechatConfig.SuccessCodes,
+			APIURLs:      append([]v1alpha1.WechatRelay(nil), w.WechatConfig.APIURLs...),
+			Compress:     w.WechatConfig.Compress,
 		},
-		ToUser:  w.ToUser,
-		ToParty: w.ToParty,
-		ToTag:   w.ToTag,
+		ToUser:                      w.ToUser,
+		ToParty:                     w.ToParty,
+		ToTag:                       w.ToTag,
+		ToTagTemplate:               w.ToTagTemplate,
+		ChatID:                      w.ChatID,
+		Confidential:                w.Confidential,
+		EnableDuplicateCheck:        w.EnableDuplicateCheck,
+		DuplicateCheckInterval:      w.DuplicateCheckInterval,
+		DuplicateCheckByFingerprint: w.DuplicateCheckByFingerprint,
+		MentionedUsers:              w.MentionedUsers,
+		TitleAnnotation:             w.TitleAnnotation,
+		MsgType:                     w.MsgType,
+		Media:                       w.Media,
+		Template:                    w.Template,
+		SeverityRouting:             w.SeverityRouting,
+		Throttle:                    w.Throttle,
+		QuietHours:                  w.QuietHours,
+		Digest:                      w.Digest,
+		Timeout:                     w.Timeout,
+		MaxAlertsPerMessage:         w.MaxAlertsPerMessage,
+		SummaryThreshold:            w.SummaryThreshold,
+		CoalesceDelay:               w.CoalesceDelay,
+		CoalesceMaxAlerts:           w.CoalesceMaxAlerts,
+	}
+}
+
+type Teams struct {
+	// The template to generate the url of the image attached to the message.
+	ImageURLTemplate string
+	TeamsConfig      *TeamsConfig
+	*common
+}
+
+type TeamsConfig struct {
+	// The secret stores the url of the incoming webhook.
+	Webhook *v1.SecretKeySelector
+	// The secret stores the key which signs the image urls.
+	ImageRenderToken *v1.SecretKeySelector
+	// The time the signed image url is valid.
+	ImageURLExpires time.Duration
+}
+
+func NewTeamsReceiver() Receiver {
+	return &Teams{
+		common: &common{},
+	}
+}
+
+func (t *Teams) GetConfig() interface{} {
+	return t.TeamsConfig
+}
+
+func (t *Teams) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		t.TeamsConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*TeamsConfig)
+	if !ok {
+		return errors.New("set teams config error, wrong config type")
+	}
+
+	t.TeamsConfig = c
+	return nil
+}
+
+func (t *Teams) GenerateConfig(c *Config, obj interface{}) {
+
+	tc, ok := obj.(*v1alpha1.TeamsConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate teams config error, wrong config type")
+		return
+	}
+
+	if tc.Spec.Webhook == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore teams config because of empty webhook", "name", tc.Name, "namespace", tc.Namespace)
+		return
+	}
+
+	t.TeamsConfig = &TeamsConfig{
+		Webhook:          tc.Spec.Webhook,
+		ImageRenderToken: tc.Spec.ImageRenderToken,
+		ImageURLExpires:  tc.Spec.ImageURLExpires,
+	}
+}
+
+func (t *Teams) GenerateReceiver(c *Config, obj interface{}) {
+
+	tr, ok := obj.(*v1alpha1.TeamsReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate teams receiver error, wrong receiver type")
+		return
+	}
+
+	t.alertSelector = tr.Spec.AlertSelector
+	t.enabled = tr.Spec.Enabled
+	t.ImageURLTemplate = tr.Spec.ImageURLTemplate
+
+	tcList := v1alpha1.TeamsConfigList{}
+	tcSel, _ := metav1.LabelSelectorAsSelector(tr.Spec.TeamsConfigSelector)
+	if err := c.cache.List(c.ctx, &tcList, client.MatchingLabelsSelector{Selector: tcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list TeamsConfig", "err", err)
+		return
+	}
+
+	for _, tc := range tcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, tc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", tc.Name, "namespace", tc.Namespace)
+			continue
+		}
+
+		t.GenerateConfig(c, &tc)
+		if t.TeamsConfig != nil {
+			break
+		}
+	}
+}
+
+const (
+	DiscordContent = "content"
+	DiscordEmbed   = "embed"
+)
+
+type Discord struct {
+	// The type of message, content or embed.
+	Type string
+	// The template to generate the url of the image attached to the message.
+	ImageURLTemplate string
+	DiscordConfig    *DiscordConfig
+	*common
+}
+
+type DiscordConfig struct {
+	// The secret stores the url of the webhook.
+	Webhook *v1.SecretKeySelector
+	// The secret stores the key which signs the image urls.
+	ImageRenderToken *v1.SecretKeySelector
+	// The time the signed image url is valid.
+	ImageURLExpires time.Duration
+}
+
+func NewDiscordReceiver() Receiver {
+	return &Discord{
+		common: &common{},
+	}
+}
+
+func (d *Discord) GetConfig() interface{} {
+	return d.DiscordConfig
+}
+
+func (d *Discord) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		d.DiscordConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*DiscordConfig)
+	if !ok {
+		return errors.New("set discord config error, wrong config type")
+	}
+
+	d.DiscordConfig = c
+	return nil
+}
+
+func (d *Discord) GenerateConfig(c *Config, obj interface{}) {
+
+	dc, ok := obj.(*v1alpha1.DiscordConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate discord config error, wrong config type")
+		return
+	}
+
+	if dc.Spec.Webhook == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore discord config because of empty webhook", "name", dc.Name, "namespace", dc.Namespace)
+		return
+	}
+
+	d.DiscordConfig = &DiscordConfig{
+		Webhook:          dc.Spec.Webhook,
+		ImageRenderToken: dc.Spec.ImageRenderToken,
+		ImageURLExpires:  dc.Spec.ImageURLExpires,
+	}
+}
+
+func (d *Discord) GenerateReceiver(c *Config, obj interface{}) {
+
+	dr, ok := obj.(*v1alpha1.DiscordReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate discord receiver error, wrong receiver type")
+		return
+	}
+
+	d.alertSelector = dr.Spec.AlertSelector
+	d.enabled = dr.Spec.Enabled
+
+	dcList := v1alpha1.DiscordConfigList{}
+	dcSel, _ := metav1.LabelSelectorAsSelector(dr.Spec.DiscordConfigSelector)
+	if err := c.cache.List(c.ctx, &dcList, client.MatchingLabelsSelector{Selector: dcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list DiscordConfig", "err", err)
+		return
+	}
+
+	d.Type = dr.Spec.Type
+	if len(d.Type) == 0 {
+		d.Type = DiscordContent
+	}
+	d.ImageURLTemplate = dr.Spec.ImageURLTemplate
+
+	for _, dc := range dcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, dc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", dc.Name, "namespace", dc.Namespace)
+			continue
+		}
+
+		d.GenerateConfig(c, &dc)
+		if d.DiscordConfig != nil {
+			break
+		}
+	}
+}
+
+type PagerDuty struct {
+	PagerDutyConfig *PagerDutyConfig
+	*common
+}
+
+type PagerDutyConfig struct {
+	APIURL string
+	// The secret stores the integration key of the service.
+	RoutingKey *v1.SecretKeySelector
+}
+
+func NewPagerDutyReceiver() Receiver {
+	return &PagerDuty{
+		common: &common{},
+	}
+}
+
+func (p *PagerDuty) GetConfig() interface{} {
+	return p.PagerDutyConfig
+}
+
+func (p *PagerDuty) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		p.PagerDutyConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*PagerDutyConfig)
+	if !ok {
+		return errors.New("set pagerduty config error, wrong config type")
+	}
+
+	p.PagerDutyConfig = c
+	return nil
+}
+
+func (p *PagerDuty) GenerateConfig(c *Config, obj interface{}) {
+
+	pc, ok := obj.(*v1alpha1.PagerDutyConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate pagerduty config error, wrong config type")
+		return
+	}
+
+	if pc.Spec.RoutingKey == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore pagerduty config because of empty routing key", "name", pc.Name, "namespace", pc.Namespace)
+		return
+	}
+
+	p.PagerDutyConfig = &PagerDutyConfig{
+		APIURL:     pc.Spec.APIURL,
+		RoutingKey: pc.Spec.RoutingKey,
+	}
+}
+
+func (p *PagerDuty) GenerateReceiver(c *Config, obj interface{}) {
+
+	pr, ok := obj.(*v1alpha1.PagerDutyReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate pagerduty receiver error, wrong receiver type")
+		return
+	}
+
+	p.alertSelector = pr.Spec.AlertSelector
+	p.enabled = pr.Spec.Enabled
+
+	pcList := v1alpha1.PagerDutyConfigList{}
+	pcSel, _ := metav1.LabelSelectorAsSelector(pr.Spec.PagerDutyConfigSelector)
+	if err := c.cache.List(c.ctx, &pcList, client.MatchingLabelsSelector{Selector: pcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list PagerDutyConfig", "err", err)
+		return
+	}
+
+	for _, pc := range pcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, pc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", pc.Name, "namespace", pc.Namespace)
+			continue
+		}
+
+		p.GenerateConfig(c, &pc)
+		if p.PagerDutyConfig != nil {
+			break
+		}
+	}
+}
+
+type Opsgenie struct {
+	// The responders which the alert will be routed to.
+	Responders []v1alpha1.OpsgenieResponder
+	// The tags of the alert.
+	Tags           []string
+	OpsgenieConfig *OpsgenieConfig
+	*common
+}
+
+type OpsgenieConfig struct {
+	APIURL string
+	// The secret stores the API key of the integration.
+	APIKey *v1.SecretKeySelector
+}
+
+func NewOpsgenieReceiver() Receiver {
+	return &Opsgenie{
+		common: &common{},
+	}
+}
+
+func (o *Opsgenie) GetConfig() interface{} {
+	return o.OpsgenieConfig
+}
+
+func (o *Opsgenie) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		o.OpsgenieConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*OpsgenieConfig)
+	if !ok {
+		return errors.New("set opsgenie config error, wrong config type")
+	}
+
+	o.OpsgenieConfig = c
+	return nil
+}
+
+func (o *Opsgenie) GenerateConfig(c *Config, obj interface{}) {
+
+	oc, ok := obj.(*v1alpha1.OpsgenieConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate opsgenie config error, wrong config type")
+		return
+	}
+
+	if oc.Spec.APIKey == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore opsgenie config because of empty api key", "name", oc.Name, "namespace", oc.Namespace)
+		return
+	}
+
+	o.OpsgenieConfig = &OpsgenieConfig{
+		APIURL: oc.Spec.APIURL,
+		APIKey: oc.Spec.APIKey,
+	}
+}
+
+func (o *Opsgenie) GenerateReceiver(c *Config, obj interface{}) {
+
+	or, ok := obj.(*v1alpha1.OpsgenieReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate opsgenie receiver error, wrong receiver type")
+		return
+	}
+
+	o.alertSelector = or.Spec.AlertSelector
+	o.enabled = or.Spec.Enabled
+
+	ocList := v1alpha1.OpsgenieConfigList{}
+	ocSel, _ := metav1.LabelSelectorAsSelector(or.Spec.OpsgenieConfigSelector)
+	if err := c.cache.List(c.ctx, &ocList, client.MatchingLabelsSelector{Selector: ocSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list OpsgenieConfig", "err", err)
+		return
+	}
+
+	o.Responders = or.Spec.Responders
+	o.Tags = or.Spec.Tags
+
+	for _, oc := range ocList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, oc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", oc.Name, "namespace", oc.Namespace)
+			continue
+		}
+
+		o.GenerateConfig(c, &oc)
+		if o.OpsgenieConfig != nil {
+			break
+		}
+	}
+}
+
+const (
+	TelegramHTML       = "HTML"
+	TelegramMarkdownV2 = "MarkdownV2"
+)
+
+type Telegram struct {
+	// The ids of the chats which the message will be sent to.
+	ChatIDs []string
+	// The parse mode of the message, HTML or MarkdownV2.
+	ParseMode      string
+	TelegramConfig *TelegramConfig
+	*common
+}
+
+type TelegramConfig struct {
+	APIURL string
+	// The secret stores the token of the bot.
+	BotToken *v1.SecretKeySelector
+}
+
+func NewTelegramReceiver() Receiver {
+	return &Telegram{
+		common: &common{},
+	}
+}
+
+func (t *Telegram) GetConfig() interface{} {
+	return t.TelegramConfig
+}
+
+func (t *Telegram) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		t.TelegramConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*TelegramConfig)
+	if !ok {
+		return errors.New("set telegram config error, wrong config type")
+	}
+
+	t.TelegramConfig = c
+	return nil
+}
+
+func (t *Telegram) GenerateConfig(c *Config, obj interface{}) {
+
+	tc, ok := obj.(*v1alpha1.TelegramConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate telegram config error, wrong config type")
+		return
+	}
+
+	if tc.Spec.BotToken == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore telegram config because of empty bot token", "name", tc.Name, "namespace", tc.Namespace)
+		return
+	}
+
+	t.TelegramConfig = &TelegramConfig{
+		APIURL:   tc.Spec.APIURL,
+		BotToken: tc.Spec.BotToken,
+	}
+}
+
+func (t *Telegram) GenerateReceiver(c *Config, obj interface{}) {
+
+	tr, ok := obj.(*v1alpha1.TelegramReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate telegram receiver error, wrong receiver type")
+		return
+	}
+
+	t.alertSelector = tr.Spec.AlertSelector
+	t.enabled = tr.Spec.Enabled
+
+	tcList := v1alpha1.TelegramConfigList{}
+	tcSel, _ := metav1.LabelSelectorAsSelector(tr.Spec.TelegramConfigSelector)
+	if err := c.cache.List(c.ctx, &tcList, client.MatchingLabelsSelector{Selector: tcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list TelegramConfig", "err", err)
+		return
+	}
+
+	t.ChatIDs = tr.Spec.ChatIDs
+	t.ParseMode = tr.Spec.ParseMode
+
+	for _, tc := range tcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, tc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", tc.Name, "namespace", tc.Namespace)
+			continue
+		}
+
+		t.GenerateConfig(c, &tc)
+		if t.TelegramConfig != nil {
+			break
+		}
+	}
+}
+
+type SMS struct {
+	// The phone numbers which the message will be sent to.
+	PhoneNumbers []string
+	SMSConfig    *SMSConfig
+	*common
+}
+
+type SMSConfig struct {
+	// The provider of the SMS service.
+	Provider string
+	Aliyun   *v1alpha1.AliyunSMS
+	Tencent  *v1alpha1.TencentSMS
+}
+
+func NewSMSReceiver() Receiver {
+	return &SMS{
+		common: &common{},
+	}
+}
+
+func (s *SMS) GetConfig() interface{} {
+	return s.SMSConfig
+}
+
+func (s *SMS) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		s.SMSConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*SMSConfig)
+	if !ok {
+		return errors.New("set sms config error, wrong config type")
+	}
+
+	s.SMSConfig = c
+	return nil
+}
+
+func (s *SMS) GenerateConfig(c *Config, obj interface{}) {
+
+	sc, ok := obj.(*v1alpha1.SMSConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate sms config error, wrong config type")
+		return
+	}
+
+	if len(sc.Spec.Provider) == 0 {
+		_ = level.Error(c.logger).Log("msg", "ignore sms config because of empty provider", "name", sc.Name, "namespace", sc.Namespace)
+		return
+	}
+
+	s.SMSConfig = &SMSConfig{
+		Provider: sc.Spec.Provider,
+		Aliyun:   sc.Spec.Aliyun,
+		Tencent:  sc.Spec.Tencent,
+	}
+}
+
+func (s *SMS) GenerateReceiver(c *Config, obj interface{}) {
+
+	sr, ok := obj.(*v1alpha1.SMSReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate sms receiver error, wrong receiver type")
+		return
+	}
+
+	s.alertSelector = sr.Spec.AlertSelector
+	s.enabled = sr.Spec.Enabled
+
+	scList := v1alpha1.SMSConfigList{}
+	scSel, _ := metav1.LabelSelectorAsSelector(sr.Spec.SMSConfigSelector)
+	if err := c.cache.List(c.ctx, &scList, client.MatchingLabelsSelector{Selector: scSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list SMSConfig", "err", err)
+		return
+	}
+
+	s.PhoneNumbers = sr.Spec.PhoneNumbers
+
+	for _, sc := range scList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, sc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", sc.Name, "namespace", sc.Namespace)
+			continue
+		}
+
+		s.GenerateConfig(c, &sc)
+		if s.SMSConfig != nil {
+			break
+		}
+	}
+}
+
+type Matrix struct {
+	// The ids of the rooms which the message will be sent to.
+	RoomIDs      []string
+	MatrixConfig *MatrixConfig
+	*common
+}
+
+type MatrixConfig struct {
+	Homeserver string
+	// The secret stores the access token.
+	AccessToken *v1.SecretKeySelector
+}
+
+func NewMatrixReceiver() Receiver {
+	return &Matrix{
+		common: &common{},
+	}
+}
+
+func (m *Matrix) GetConfig() interface{} {
+	return m.MatrixConfig
+}
+
+func (m *Matrix) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		m.MatrixConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*MatrixConfig)
+	if !ok {
+		return errors.New("set matrix config error, wrong config type")
+	}
+
+	m.MatrixConfig = c
+	return nil
+}
+
+func (m *Matrix) GenerateConfig(c *Config, obj interface{}) {
+
+	mc, ok := obj.(*v1alpha1.MatrixConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate matrix config error, wrong config type")
+		return
+	}
+
+	if len(mc.Spec.Homeserver) == 0 || mc.Spec.AccessToken == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore matrix config because of empty homeserver or access token", "name", mc.Name, "namespace", mc.Namespace)
+		return
+	}
+
+	m.MatrixConfig = &MatrixConfig{
+		Homeserver:  mc.Spec.Homeserver,
+		AccessToken: mc.Spec.AccessToken,
+	}
+}
+
+func (m *Matrix) GenerateReceiver(c *Config, obj interface{}) {
+
+	mr, ok := obj.(*v1alpha1.MatrixReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate matrix receiver error, wrong receiver type")
+		return
+	}
+
+	m.alertSelector = mr.Spec.AlertSelector
+	m.enabled = mr.Spec.Enabled
+
+	mcList := v1alpha1.MatrixConfigList{}
+	mcSel, _ := metav1.LabelSelectorAsSelector(mr.Spec.MatrixConfigSelector)
+	if err := c.cache.List(c.ctx, &mcList, client.MatchingLabelsSelector{Selector: mcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list MatrixConfig", "err", err)
+		return
+	}
+
+	m.RoomIDs = mr.Spec.RoomIDs
+
+	for _, mc := range mcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, mc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", mc.Name, "namespace", mc.Namespace)
+			continue
+		}
+
+		m.GenerateConfig(c, &mc)
+		if m.MatrixConfig != nil {
+			break
+		}
+	}
+}
+
+const (
+	GoogleChatText = "text"
+	GoogleChatCard = "card"
+)
+
+type GoogleChat struct {
+	// The type of message, text or card.
+	MsgType          string
+	GoogleChatConfig *GoogleChatConfig
+	*common
+}
+
+type GoogleChatConfig struct {
+	// The secret stores the url of the incoming webhook of the space.
+	Webhook *v1.SecretKeySelector
+}
+
+func NewGoogleChatReceiver() Receiver {
+	return &GoogleChat{
+		common: &common{},
+	}
+}
+
+func (g *GoogleChat) GetConfig() interface{} {
+	return g.GoogleChatConfig
+}
+
+func (g *GoogleChat) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		g.GoogleChatConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*GoogleChatConfig)
+	if !ok {
+		return errors.New("set google chat config error, wrong config type")
+	}
+
+	g.GoogleChatConfig = c
+	return nil
+}
+
+func (g *GoogleChat) GenerateConfig(c *Config, obj interface{}) {
+
+	gc, ok := obj.(*v1alpha1.GoogleChatConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate google chat config error, wrong config type")
+		return
+	}
+
+	if gc.Spec.Webhook == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore google chat config because of empty webhook", "name", gc.Name, "namespace", gc.Namespace)
+		return
+	}
+
+	g.GoogleChatConfig = &GoogleChatConfig{
+		Webhook: gc.Spec.Webhook,
+	}
+}
+
+func (g *GoogleChat) GenerateReceiver(c *Config, obj interface{}) {
+
+	gr, ok := obj.(*v1alpha1.GoogleChatReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate google chat receiver error, wrong receiver type")
+		return
+	}
+
+	g.alertSelector = gr.Spec.AlertSelector
+	g.enabled = gr.Spec.Enabled
+
+	gcList := v1alpha1.GoogleChatConfigList{}
+	gcSel, _ := metav1.LabelSelectorAsSelector(gr.Spec.GoogleChatConfigSelector)
+	if err := c.cache.List(c.ctx, &gcList, client.MatchingLabelsSelector{Selector: gcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list GoogleChatConfig", "err", err)
+		return
+	}
+
+	g.MsgType = GoogleChatText
+	if len(gr.Spec.MsgType) > 0 {
+		g.MsgType = gr.Spec.MsgType
+	}
+
+	for _, gc := range gcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, gc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", gc.Name, "namespace", gc.Namespace)
+			continue
+		}
+
+		g.GenerateConfig(c, &gc)
+		if g.GoogleChatConfig != nil {
+			break
+		}
+	}
+}
+
+type SNS struct {
+	// The ARN of the topic which the message will be published to.
+	TopicARN string
+	// The labels sent as the message attributes.
+	AttributeLabels []string
+	SNSConfig       *SNSConfig
+	*common
+}
+
+type SNSConfig struct {
+	Region   string
+	Endpoint string
+	// The secret stores the access key id.
+	AccessKeyID *v1.SecretKeySelector
+	// The secret stores the secret access key.
+	SecretAccessKey *v1.SecretKeySelector
+}
+
+func NewSNSReceiver() Receiver {
+	return &SNS{
+		common: &common{},
+	}
+}
+
+func (s *SNS) GetConfig() interface{} {
+	return s.SNSConfig
+}
+
+func (s *SNS) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		s.SNSConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*SNSConfig)
+	if !ok {
+		return errors.New("set sns config error, wrong config type")
+	}
+
+	s.SNSConfig = c
+	return nil
+}
+
+func (s *SNS) GenerateConfig(c *Config, obj interface{}) {
+
+	sc, ok := obj.(*v1alpha1.SNSConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate sns config error, wrong config type")
+		return
+	}
+
+	if len(sc.Spec.Region) == 0 {
+		_ = level.Error(c.logger).Log("msg", "ignore sns config because of empty region", "name", sc.Name, "namespace", sc.Namespace)
+		return
+	}
+
+	s.SNSConfig = &SNSConfig{
+		Region:          sc.Spec.Region,
+		Endpoint:        sc.Spec.Endpoint,
+		AccessKeyID:     sc.Spec.AccessKeyID,
+		SecretAccessKey: sc.Spec.SecretAccessKey,
+	}
+}
+
+func (s *SNS) GenerateReceiver(c *Config, obj interface{}) {
+
+	sr, ok := obj.(*v1alpha1.SNSReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate sns receiver error, wrong receiver type")
+		return
+	}
+
+	s.alertSelector = sr.Spec.AlertSelector
+	s.enabled = sr.Spec.Enabled
+
+	scList := v1alpha1.SNSConfigList{}
+	scSel, _ := metav1.LabelSelectorAsSelector(sr.Spec.SNSConfigSelector)
+	if err := c.cache.List(c.ctx, &scList, client.MatchingLabelsSelector{Selector: scSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list SNSConfig", "err", err)
+		return
+	}
+
+	s.TopicARN = sr.Spec.TopicARN
+	s.AttributeLabels = sr.Spec.AttributeLabels
+
+	for _, sc := range scList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, sc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", sc.Name, "namespace", sc.Namespace)
+			continue
+		}
+
+		s.GenerateConfig(c, &sc)
+		if s.SNSConfig != nil {
+			break
+		}
+	}
+}
+
+const (
+	KafkaJSON = "json"
+	KafkaText = "text"
+)
+
+type Kafka struct {
+	// The topic which the alerts will be produced to.
+	Topic string
+	// The format of message, json or text.
+	Format      string
+	KafkaConfig *KafkaConfig
+	*common
+}
+
+type KafkaConfig struct {
+	Brokers []string
+	TLS     *v1alpha1.TLSConfig
+	SASL    *v1alpha1.KafkaSASL
+}
+
+func NewKafkaReceiver() Receiver {
+	return &Kafka{
+		common: &common{},
+	}
+}
+
+func (k *Kafka) GetConfig() interface{} {
+	return k.KafkaConfig
+}
+
+func (k *Kafka) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		k.KafkaConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*KafkaConfig)
+	if !ok {
+		return errors.New("set kafka config error, wrong config type")
+	}
+
+	k.KafkaConfig = c
+	return nil
+}
+
+func (k *Kafka) GenerateConfig(c *Config, obj interface{}) {
+
+	kc, ok := obj.(*v1alpha1.KafkaConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate kafka config error, wrong config type")
+		return
+	}
+
+	if len(kc.Spec.Brokers) == 0 {
+		_ = level.Error(c.logger).Log("msg", "ignore kafka config because of empty brokers", "name", kc.Name, "namespace", kc.Namespace)
+		return
+	}
+
+	k.KafkaConfig = &KafkaConfig{
+		Brokers: kc.Spec.Brokers,
+		TLS:     kc.Spec.TLS,
+		SASL:    kc.Spec.SASL,
+	}
+}
+
+func (k *Kafka) GenerateReceiver(c *Config, obj interface{}) {
+
+	kr, ok := obj.(*v1alpha1.KafkaReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate kafka receiver error, wrong receiver type")
+		return
+	}
+
+	k.alertSelector = kr.Spec.AlertSelector
+	k.enabled = kr.Spec.Enabled
+
+	kcList := v1alpha1.KafkaConfigList{}
+	kcSel, _ := metav1.LabelSelectorAsSelector(kr.Spec.KafkaConfigSelector)
+	if err := c.cache.List(c.ctx, &kcList, client.MatchingLabelsSelector{Selector: kcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list KafkaConfig", "err", err)
+		return
+	}
+
+	k.Topic = kr.Spec.Topic
+	k.Format = KafkaJSON
+	if len(kr.Spec.Format) > 0 {
+		k.Format = kr.Spec.Format
+	}
+
+	for _, kc := range kcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, kc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", kc.Name, "namespace", kc.Namespace)
+			continue
+		}
+
+		k.GenerateConfig(c, &kc)
+		if k.KafkaConfig != nil {
+			break
+		}
+	}
+}
+
+type Line struct {
+	// The sticker sent with the message.
+	StickerPackageID int
+	StickerID        int
+	// The urls of the image sent with the message.
+	ImageThumbnail string
+	ImageFullsize  string
+	LineConfig     *LineConfig
+	*common
+}
+
+type LineConfig struct {
+	APIURL string
+	// The secret stores the access token.
+	Token *v1.SecretKeySelector
+}
+
+func NewLineReceiver() Receiver {
+	return &Line{
+		common: &common{},
+	}
+}
+
+func (l *Line) GetConfig() interface{} {
+	return l.LineConfig
+}
+
+func (l *Line) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		l.LineConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*LineConfig)
+	if !ok {
+		return errors.New("set line config error, wrong config type")
+	}
+
+	l.LineConfig = c
+	return nil
+}
+
+func (l *Line) GenerateConfig(c *Config, obj interface{}) {
+
+	lc, ok := obj.(*v1alpha1.LineConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate line config error, wrong config type")
+		return
+	}
+
+	if lc.Spec.Token == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore line config because of empty token", "name", lc.Name, "namespace", lc.Namespace)
+		return
+	}
+
+	l.LineConfig = &LineConfig{
+		APIURL: lc.Spec.APIURL,
+		Token:  lc.Spec.Token,
+	}
+}
+
+func (l *Line) GenerateReceiver(c *Config, obj interface{}) {
+
+	lr, ok := obj.(*v1alpha1.LineReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate line receiver error, wrong receiver type")
+		return
+	}
+
+	l.alertSelector = lr.Spec.AlertSelector
+	l.enabled = lr.Spec.Enabled
+
+	lcList := v1alpha1.LineConfigList{}
+	lcSel, _ := metav1.LabelSelectorAsSelector(lr.Spec.LineConfigSelector)
+	if err := c.cache.List(c.ctx, &lcList, client.MatchingLabelsSelector{Selector: lcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list LineConfig", "err", err)
+		return
+	}
+
+	l.StickerPackageID = lr.Spec.StickerPackageID
+	l.StickerID = lr.Spec.StickerID
+	l.ImageThumbnail = lr.Spec.ImageThumbnail
+	l.ImageFullsize = lr.Spec.ImageFullsize
+
+	for _, lc := range lcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, lc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", lc.Name, "namespace", lc.Namespace)
+			continue
+		}
+
+		l.GenerateConfig(c, &lc)
+		if l.LineConfig != nil {
+			break
+		}
+	}
+}
+
+type File struct {
+	FileConfig *FileConfig
+	*common
+}
+
+type FileConfig struct {
+	// The path of the file, the notifications are written to stdout if it is empty.
+	Path string
+	// The maximum size of the file in megabytes.
+	MaxSize    int
+	MaxBackups int
+}
+
+func NewFileReceiver() Receiver {
+	return &File{
+		common: &common{},
+	}
+}
+
+func (f *File) GetConfig() interface{} {
+	return f.FileConfig
+}
+
+func (f *File) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		f.FileConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*FileConfig)
+	if !ok {
+		return errors.New("set file config error, wrong config type")
+	}
+
+	f.FileConfig = c
+	return nil
+}
+
+func (f *File) GenerateConfig(c *Config, obj interface{}) {
+
+	fc, ok := obj.(*v1alpha1.FileConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate file config error, wrong config type")
+		return
+	}
+
+	f.FileConfig = &FileConfig{
+		Path:       fc.Spec.Path,
+		MaxSize:    fc.Spec.MaxSize,
+		MaxBackups: fc.Spec.MaxBackups,
+	}
+}
+
+func (f *File) GenerateReceiver(c *Config, obj interface{}) {
+
+	fr, ok := obj.(*v1alpha1.FileReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate file receiver error, wrong receiver type")
+		return
+	}
+
+	f.alertSelector = fr.Spec.AlertSelector
+	f.enabled = fr.Spec.Enabled
+
+	fcList := v1alpha1.FileConfigList{}
+	fcSel, _ := metav1.LabelSelectorAsSelector(fr.Spec.FileConfigSelector)
+	if err := c.cache.List(c.ctx, &fcList, client.MatchingLabelsSelector{Selector: fcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list FileConfig", "err", err)
+		return
+	}
+
+	for _, fc := range fcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, fc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", fc.Name, "namespace", fc.Namespace)
+			continue
+		}
+
+		f.GenerateConfig(c, &fc)
+		if f.FileConfig != nil {
+			break
+		}
+	}
+}
+
+type Zoom struct {
+	// The JIDs of the channels or users which the messages are sent to.
+	ToJIDs     []string
+	ZoomConfig *ZoomConfig
+	*common
+}
+
+type ZoomConfig struct {
+	APIURL   string
+	OAuthURL string
+	ClientID string
+	// The secret stores the client secret.
+	ClientSecret *v1.SecretKeySelector
+	RobotJID     string
+	AccountID    string
+}
+
+func NewZoomReceiver() Receiver {
+	return &Zoom{
+		common: &common{},
+	}
+}
+
+func (z *Zoom) GetConfig() interface{} {
+	return z.ZoomConfig
+}
+
+func (z *Zoom) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		z.ZoomConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*ZoomConfig)
+	if !ok {
+		return errors.New("set zoom config error, wrong config type")
+	}
+
+	z.ZoomConfig = c
+	return nil
+}
+
+func (z *Zoom) GenerateConfig(c *Config, obj interface{}) {
+
+	zc, ok := obj.(*v1alpha1.ZoomConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate zoom config error, wrong config type")
+		return
+	}
+
+	if zc.Spec.ClientSecret == nil {
+		_ = level.Error(c.logger).Log("msg", "ignore zoom config because of empty client secret", "name", zc.Name, "namespace", zc.Namespace)
+		return
+	}
+
+	z.ZoomConfig = &ZoomConfig{
+		APIURL:       zc.Spec.APIURL,
+		OAuthURL:     zc.Spec.OAuthURL,
+		ClientID:     zc.Spec.ClientID,
+		ClientSecret: zc.Spec.ClientSecret,
+		RobotJID:     zc.Spec.RobotJID,
+		AccountID:    zc.Spec.AccountID,
+	}
+}
+
+func (z *Zoom) GenerateReceiver(c *Config, obj interface{}) {
+
+	zr, ok := obj.(*v1alpha1.ZoomReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate zoom receiver error, wrong receiver type")
+		return
+	}
+
+	z.ToJIDs = zr.Spec.ToJIDs
+	z.alertSelector = zr.Spec.AlertSelector
+	z.enabled = zr.Spec.Enabled
+
+	zcList := v1alpha1.ZoomConfigList{}
+	zcSel, _ := metav1.LabelSelectorAsSelector(zr.Spec.ZoomConfigSelector)
+	if err := c.cache.List(c.ctx, &zcList, client.MatchingLabelsSelector{Selector: zcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list ZoomConfig", "err", err)
+		return
+	}
+
+	for _, zc := range zcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, zc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", zc.Name, "namespace", zc.Namespace)
+			continue
+		}
+
+		z.GenerateConfig(c, &zc)
+		if z.ZoomConfig != nil {
+			break
+		}
+	}
+}
+
+const (
+	RocketChatText       = "text"
+	RocketChatAttachment = "attachment"
+)
+
+type RocketChat struct {
+	// The channels or users which the messages are sent to.
+	Channels []string
+	// The type of message, text or attachment.
+	Type             string
+	RocketChatConfig *RocketChatConfig
+	*common
+}
+
+type RocketChatConfig struct {
+	// The secret stores the url of the incoming webhook.
+	Webhook *v1.SecretKeySelector
+	APIURL  string
+	// The secrets store the user id and the token used by the REST API.
+	UserID *v1.SecretKeySelector
+	Token  *v1.SecretKeySelector
+}
+
+func NewRocketChatReceiver() Receiver {
+	return &RocketChat{
+		common: &common{},
+	}
+}
+
+func (r *RocketChat) GetConfig() interface{} {
+	return r.RocketChatConfig
+}
+
+func (r *RocketChat) SetConfig(obj interface{}) error {
+
+	if obj == nil {
+		r.RocketChatConfig = nil
+		return nil
+	}
+
+	c, ok := obj.(*RocketChatConfig)
+	if !ok {
+		return errors.New("set rocketchat config error, wrong config type")
+	}
+
+	r.RocketChatConfig = c
+	return nil
+}
+
+func (r *RocketChat) GenerateConfig(c *Config, obj interface{}) {
+
+	rc, ok := obj.(*v1alpha1.RocketChatConfig)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate rocketchat config error, wrong config type")
+		return
+	}
+
+	if rc.Spec.Webhook == nil && (rc.Spec.UserID == nil || rc.Spec.Token == nil) {
+		_ = level.Error(c.logger).Log("msg", "ignore rocketchat config because of empty webhook and token", "name", rc.Name, "namespace", rc.Namespace)
+		return
+	}
+
+	r.RocketChatConfig = &RocketChatConfig{
+		Webhook: rc.Spec.Webhook,
+		APIURL:  rc.Spec.APIURL,
+		UserID:  rc.Spec.UserID,
+		Token:   rc.Spec.Token,
+	}
+}
+
+func (r *RocketChat) GenerateReceiver(c *Config, obj interface{}) {
+
+	rr, ok := obj.(*v1alpha1.RocketChatReceiver)
+	if !ok {
+		_ = level.Warn(c.logger).Log("msg", "generate rocketchat receiver error, wrong receiver type")
+		return
+	}
+
+	r.Channels = rr.Spec.Channels
+	r.Type = rr.Spec.Type
+	if len(r.Type) == 0 {
+		r.Type = RocketChatText
+	}
+	r.alertSelector = rr.Spec.AlertSelector
+	r.enabled = rr.Spec.Enabled
+
+	rcList := v1alpha1.RocketChatConfigList{}
+	rcSel, _ := metav1.LabelSelectorAsSelector(rr.Spec.RocketChatConfigSelector)
+	if err := c.cache.List(c.ctx, &rcList, client.MatchingLabelsSelector{Selector: rcSel}); client.IgnoreNotFound(err) != nil {
+		_ = level.Error(c.logger).Log("msg", "Unable to list RocketChatConfig", "err", err)
+		return
+	}
+
+	for _, rc := range rcList.Items {
+		if len(c.nmNamespaces) > 0 && !sliceIn(c.nmNamespaces, rc.Namespace) {
+			_ = level.Warn(c.logger).Log("msg", "don't need to be watched", "name", rc.Name, "namespace", rc.Namespace)
+			continue
+		}
+
+		r.GenerateConfig(c, &rc)
+		if r.RocketChatConfig != nil {
+			break
+		}
 	}
 }
 
@@ -0,0 +1,540 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	errEmptyConfig = errors.New("config is empty")
+)
+
+// Check whether the url is an absolute url, the empty url is valid if it is optional.
+func validateURL(name, u string, optional bool) error {
+
+	if len(u) == 0 {
+		if optional {
+			return nil
+		}
+		return fmt.Errorf("%s is empty", name)
+	}
+
+	pu, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("%s is invalid, %s", name, err.Error())
+	}
+
+	if len(pu.Scheme) == 0 || len(pu.Host) == 0 {
+		return fmt.Errorf("%s %s is not an absolute url", name, u)
+	}
+
+	return nil
+}
+
+// Check whether the path is a relative path which can be appended to the api url, the empty path is valid.
+func validatePath(name, p string) error {
+
+	if len(p) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(p)
+	if err != nil {
+		return fmt.Errorf("%s is invalid, %s", name, err.Error())
+	}
+
+	if u.IsAbs() || len(u.Host) > 0 || strings.HasPrefix(p, "/") || len(u.RawQuery) > 0 {
+		return fmt.Errorf("%s %s is not a relative path", name, p)
+	}
+
+	return nil
+}
+
+func (d *DingTalk) Validate() error {
+
+	if d.DingTalkConfig == nil {
+		return errEmptyConfig
+	}
+
+	if d.DingTalkConfig.ChatBot == nil && d.DingTalkConfig.Conversation == nil {
+		return errors.New("neither chatbot nor conversation is set")
+	}
+
+	if c := d.DingTalkConfig.ChatBot; c != nil && c.Webhook == nil {
+		return errors.New("the webhook of chatbot is empty")
+	}
+
+	if c := d.DingTalkConfig.Conversation; c != nil {
+		if c.AppKey == nil || c.AppSecret == nil {
+			return errors.New("the appkey or appsecret of conversation is empty")
+		}
+
+		if len(c.ChatID) == 0 {
+			return errors.New("the chatid of conversation is empty")
+		}
+	}
+
+	return nil
+}
+
+func (e *Email) Validate() error {
+
+	if e.EmailConfig == nil {
+		return errEmptyConfig
+	}
+
+	if len(e.To) == 0 {
+		return errors.New("receivers' email addresses are empty")
+	}
+
+	if len(e.EmailConfig.From) == 0 {
+		return errors.New("the sender's email address is empty")
+	}
+
+	if len(e.EmailConfig.SmartHost.Host) == 0 || len(e.EmailConfig.SmartHost.Port) == 0 {
+		return errors.New("the smart host is invalid")
+	}
+
+	return nil
+}
+
+func (s *Slack) Validate() error {
+
+	if s.SlackConfig == nil {
+		return errEmptyConfig
+	}
+
+	if s.SlackConfig.Token == nil {
+		return errors.New("token is empty")
+	}
+
+	if len(s.Channel) == 0 {
+		return errors.New("channel is empty")
+	}
+
+	return nil
+}
+
+func (w *Webhook) Validate() error {
+
+	if w.WebhookConfig == nil {
+		return errEmptyConfig
+	}
+
+	if err := validateURL("url", w.WebhookConfig.URL, false); err != nil {
+		return err
+	}
+
+	switch w.WebhookConfig.Method {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("unsupported method %s", w.WebhookConfig.Method)
+	}
+
+	if s := w.WebhookConfig.Signature; s != nil && s.Secret == nil {
+		return errors.New("signature secret is empty")
+	}
+
+	if len(w.WebhookConfig.BodyTemplate) > 0 {
+		if err := notifier.ValidateText(w.WebhookConfig.BodyTemplate); err != nil {
+			return fmt.Errorf("body template is invalid, %s", err.Error())
+		}
+	}
+
+	// The names of the fields in the request body must be unique.
+	names := make(map[string]string)
+	for _, field := range WebhookFields {
+		name, ok := w.WebhookConfig.FieldMapping[field]
+		if !ok {
+			name = field
+		}
+
+		if len(name) == 0 {
+			continue
+		}
+
+		if f, ok := names[name]; ok {
+			return fmt.Errorf("field %s and %s are both mapped to %s", f, field, name)
+		}
+		names[name] = field
+	}
+
+	for field := range w.WebhookConfig.FieldMapping {
+		if !sliceIn(WebhookFields, field) {
+			return fmt.Errorf("unknown field %s in field mapping", field)
+		}
+	}
+
+	return nil
+}
+
+func (w *Wechat) Validate() error {
+
+	if w.WechatConfig == nil {
+		return errEmptyConfig
+	}
+
+	if len(w.WechatConfig.CorpID) == 0 {
+		return errors.New("corpid is empty")
+	}
+
+	if len(w.WechatConfig.AgentID) == 0 && len(w.ChatID) == 0 {
+		return errors.New("agentid is empty")
+	}
+
+	if w.WechatConfig.APISecret == nil {
+		return errors.New("api secret is empty")
+	}
+
+	if err := validateURL("api url", w.WechatConfig.APIURL, true); err != nil {
+		return err
+	}
+
+	for _, r := range w.WechatConfig.APIURLs {
+		if err := validateURL("relay url", r.URL, false); err != nil {
+			return err
+		}
+	}
+
+	if err := validatePath("send path", w.WechatConfig.SendPath); err != nil {
+		return err
+	}
+
+	if err := validatePath("token path", w.WechatConfig.TokenPath); err != nil {
+		return err
+	}
+
+	switch w.MsgType {
+	case WechatText, WechatMarkdown, WechatNews:
+	case WechatImage, WechatFile:
+		if w.Media == nil || (len(w.Media.URL) == 0 && w.Media.Secret == nil) {
+			return fmt.Errorf("media of %s message is empty", w.MsgType)
+		}
+	case WechatTemplateCard:
+		// The application chat does not support the template card message.
+		if len(w.ChatID) > 0 {
+			return errors.New("template card message can not be sent to the chat")
+		}
+	default:
+		return fmt.Errorf("unknown message type %s", w.MsgType)
+	}
+
+	if len(w.ChatID) == 0 && len(w.ToUser) == 0 && len(w.ToParty) == 0 && len(w.ToTag) == 0 && len(w.ToTagTemplate) == 0 {
+		return errors.New("chatid, touser, toparty, totag and totag template are all empty")
+	}
+
+	if len(w.ToTagTemplate) > 0 {
+		if err := notifier.ValidateText(w.ToTagTemplate); err != nil {
+			return fmt.Errorf("totag template is invalid, %s", err.Error())
+		}
+	}
+
+	if w.QuietHours != nil {
+		if err := notifier.ValidateQuietHours(w.QuietHours); err != nil {
+			return err
+		}
+	}
+
+	if w.Digest != nil {
+		if err := notifier.ValidateDigest(w.Digest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Teams) Validate() error {
+
+	if t.TeamsConfig == nil {
+		return errEmptyConfig
+	}
+
+	if t.TeamsConfig.Webhook == nil {
+		return errors.New("webhook is empty")
+	}
+
+	return nil
+}
+
+func (d *Discord) Validate() error {
+
+	if d.DiscordConfig == nil {
+		return errEmptyConfig
+	}
+
+	if d.DiscordConfig.Webhook == nil {
+		return errors.New("webhook is empty")
+	}
+
+	if d.Type != DiscordContent && d.Type != DiscordEmbed {
+		return fmt.Errorf("unknown message type %s", d.Type)
+	}
+
+	return nil
+}
+
+func (p *PagerDuty) Validate() error {
+
+	if p.PagerDutyConfig == nil {
+		return errEmptyConfig
+	}
+
+	if p.PagerDutyConfig.RoutingKey == nil {
+		return errors.New("routing key is empty")
+	}
+
+	return validateURL("api url", p.PagerDutyConfig.APIURL, true)
+}
+
+func (o *Opsgenie) Validate() error {
+
+	if o.OpsgenieConfig == nil {
+		return errEmptyConfig
+	}
+
+	if o.OpsgenieConfig.APIKey == nil {
+		return errors.New("api key is empty")
+	}
+
+	return validateURL("api url", o.OpsgenieConfig.APIURL, true)
+}
+
+func (t *Telegram) Validate() error {
+
+	if t.TelegramConfig == nil {
+		return errEmptyConfig
+	}
+
+	if t.TelegramConfig.BotToken == nil {
+		return errors.New("bot token is empty")
+	}
+
+	if len(t.ChatIDs) == 0 {
+		return errors.New("chat id is empty")
+	}
+
+	return validateURL("api url", t.TelegramConfig.APIURL, true)
+}
+
+func (s *SMS) Validate() error {
+
+	if s.SMSConfig == nil {
+		return errEmptyConfig
+	}
+
+	if len(s.SMSConfig.Provider) == 0 {
+		return errors.New("provider is empty")
+	}
+
+	if len(s.PhoneNumbers) == 0 {
+		return errors.New("phone numbers are empty")
+	}
+
+	return nil
+}
+
+func (m *Matrix) Validate() error {
+
+	if m.MatrixConfig == nil {
+		return errEmptyConfig
+	}
+
+	if m.MatrixConfig.AccessToken == nil {
+		return errors.New("access token is empty")
+	}
+
+	if len(m.RoomIDs) == 0 {
+		return errors.New("room id is empty")
+	}
+
+	return validateURL("homeserver", m.MatrixConfig.Homeserver, false)
+}
+
+func (g *GoogleChat) Validate() error {
+
+	if g.GoogleChatConfig == nil {
+		return errEmptyConfig
+	}
+
+	if g.GoogleChatConfig.Webhook == nil {
+		return errors.New("webhook is empty")
+	}
+
+	if g.MsgType != GoogleChatText && g.MsgType != GoogleChatCard {
+		return fmt.Errorf("unsupported message type %s", g.MsgType)
+	}
+
+	return nil
+}
+
+func (s *SNS) Validate() error {
+
+	if s.SNSConfig == nil {
+		return errEmptyConfig
+	}
+
+	if len(s.SNSConfig.Region) == 0 {
+		return errors.New("region is empty")
+	}
+
+	if (s.SNSConfig.AccessKeyID == nil) != (s.SNSConfig.SecretAccessKey == nil) {
+		return errors.New("access key id and secret access key must be set together")
+	}
+
+	if !strings.HasPrefix(s.TopicARN, "arn:") {
+		return fmt.Errorf("invalid topic arn %s", s.TopicARN)
+	}
+
+	return validateURL("endpoint", s.SNSConfig.Endpoint, true)
+}
+
+func (k *Kafka) Validate() error {
+
+	if k.KafkaConfig == nil {
+		return errEmptyConfig
+	}
+
+	if len(k.KafkaConfig.Brokers) == 0 {
+		return errors.New("brokers is empty")
+	}
+
+	if sasl := k.KafkaConfig.SASL; sasl != nil {
+		switch sasl.Mechanism {
+		case "", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		default:
+			return fmt.Errorf("unsupported sasl mechanism %s", sasl.Mechanism)
+		}
+
+		if len(sasl.Username) == 0 || sasl.Password == nil {
+			return errors.New("sasl username or password is empty")
+		}
+	}
+
+	if len(k.Topic) == 0 {
+		return errors.New("topic is empty")
+	}
+
+	if k.Format != KafkaJSON && k.Format != KafkaText {
+		return fmt.Errorf("unsupported format %s", k.Format)
+	}
+
+	return nil
+}
+
+func (l *Line) Validate() error {
+
+	if l.LineConfig == nil {
+		return errEmptyConfig
+	}
+
+	if l.LineConfig.Token == nil {
+		return errors.New("token is empty")
+	}
+
+	if (l.StickerPackageID == 0) != (l.StickerID == 0) {
+		return errors.New("sticker package id and sticker id must be set together")
+	}
+
+	if len(l.ImageThumbnail) > 0 && len(l.ImageFullsize) == 0 {
+		return errors.New("image fullsize must be set if image thumbnail is set")
+	}
+
+	if err := validateURL("image thumbnail", l.ImageThumbnail, true); err != nil {
+		return err
+	}
+
+	if err := validateURL("image fullsize", l.ImageFullsize, true); err != nil {
+		return err
+	}
+
+	return validateURL("api url", l.LineConfig.APIURL, true)
+}
+
+func (f *File) Validate() error {
+
+	if f.FileConfig == nil {
+		return errEmptyConfig
+	}
+
+	if f.FileConfig.MaxSize < 0 || f.FileConfig.MaxBackups < 0 {
+		return errors.New("max size and max backups can not be negative")
+	}
+
+	return nil
+}
+
+func (z *Zoom) Validate() error {
+
+	if z.ZoomConfig == nil {
+		return errEmptyConfig
+	}
+
+	if len(z.ZoomConfig.ClientID) == 0 {
+		return errors.New("client id is empty")
+	}
+
+	if z.ZoomConfig.ClientSecret == nil {
+		return errors.New("client secret is empty")
+	}
+
+	if len(z.ZoomConfig.RobotJID) == 0 {
+		return errors.New("robot jid is empty")
+	}
+
+	if len(z.ZoomConfig.AccountID) == 0 {
+		return errors.New("account id is empty")
+	}
+
+	if err := validateURL("api url", z.ZoomConfig.APIURL, true); err != nil {
+		return err
+	}
+
+	if err := validateURL("oauth url", z.ZoomConfig.OAuthURL, true); err != nil {
+		return err
+	}
+
+	if len(z.ToJIDs) == 0 {
+		return errors.New("to jids is empty")
+	}
+
+	return nil
+}
+
+func (r *RocketChat) Validate() error {
+
+	if r.RocketChatConfig == nil {
+		return errEmptyConfig
+	}
+
+	if r.Type != RocketChatText && r.Type != RocketChatAttachment {
+		return fmt.Errorf("unknown message type %s", r.Type)
+	}
+
+	// The message is sent through the webhook.
+	if r.RocketChatConfig.Webhook != nil {
+		return nil
+	}
+
+	if err := validateURL("api url", r.RocketChatConfig.APIURL, false); err != nil {
+		return err
+	}
+
+	if r.RocketChatConfig.UserID == nil {
+		return errors.New("user id is empty")
+	}
+
+	if r.RocketChatConfig.Token == nil {
+		return errors.New("token is empty")
+	}
+
+	if len(r.Channels) == 0 {
+		return errors.New("channels is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"k8s.io/api/core/v1"
+	"strings"
+	"testing"
+)
+
+func keySelector(name string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: "key"}
+}
+
+func newValidWechat() *Wechat {
+	w := NewWechatReceiver().(*Wechat)
+	w.ToUser = "user1"
+	w.MsgType = WechatText
+	w.WechatConfig = &WechatConfig{
+		CorpID:    "corp",
+		AgentID:   "1000002",
+		APISecret: keySelector("wechat"),
+	}
+	return w
+}
+
+func TestWechatValidate(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		modify func(w *Wechat)
+		// The error expected, the config is valid if it is empty.
+		err string
+	}{
+		{"valid", func(w *Wechat) {}, ""},
+		{"api url", func(w *Wechat) { w.WechatConfig.APIURL = "https://qyapi.weixin.qq.com/cgi-bin/" }, ""},
+		{"chat without agent", func(w *Wechat) { w.WechatConfig.AgentID, w.ToUser, w.ChatID = "", "", "chat1" }, ""},
+		{"markdown", func(w *Wechat) { w.MsgType = WechatMarkdown }, ""},
+		{"image", func(w *Wechat) {
+			w.MsgType = WechatImage
+			w.Media = &v1alpha1.WechatMedia{URL: "https://example.com/logo.png"}
+		}, ""},
+		{"empty config", func(w *Wechat) { w.WechatConfig = nil }, "config is empty"},
+		{"empty corpid", func(w *Wechat) { w.WechatConfig.CorpID = "" }, "corpid is empty"},
+		{"empty agentid", func(w *Wechat) { w.WechatConfig.AgentID = "" }, "agentid is empty"},
+		{"empty secret", func(w *Wechat) { w.WechatConfig.APISecret = nil }, "api secret is empty"},
+		{"relative api url", func(w *Wechat) { w.WechatConfig.APIURL = "qyapi.weixin.qq.com" }, "not an absolute url"},
+		{"invalid api url", func(w *Wechat) { w.WechatConfig.APIURL = "http://[::1" }, "api url is invalid"},
+		{"invalid relay url", func(w *Wechat) {
+			w.WechatConfig.APIURLs = []v1alpha1.WechatRelay{{URL: "relay"}}
+		}, "relay url"},
+		{"absolute send path", func(w *Wechat) { w.WechatConfig.SendPath = "/message/send" }, "not a relative path"},
+		{"token path with query", func(w *Wechat) { w.WechatConfig.TokenPath = "gettoken?a=b" }, "not a relative path"},
+		{"unknown type", func(w *Wechat) { w.MsgType = "voice" }, "unknown message type voice"},
+		{"image without media", func(w *Wechat) { w.MsgType = WechatImage }, "media of image message is empty"},
+		{"card to chat", func(w *Wechat) { w.MsgType, w.ChatID = WechatTemplateCard, "chat1" }, "can not be sent to the chat"},
+		{"no recipient", func(w *Wechat) { w.ToUser = "" }, "are all empty"},
+		{"invalid totag template", func(w *Wechat) { w.ToTagTemplate = "{{ .Unknown" }, "totag template is invalid"},
+	}
+
+	for _, tt := range tests {
+		w := newValidWechat()
+		tt.modify(w)
+
+		err := w.Validate()
+		if len(tt.err) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected valid, got %s", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		config *WebhookConfig
+		err    string
+	}{
+		{"valid", &WebhookConfig{URL: "https://example.com/alerts"}, ""},
+		{"put", &WebhookConfig{URL: "https://example.com/alerts", Method: "PUT"}, ""},
+		{"empty config", nil, "config is empty"},
+		{"empty url", &WebhookConfig{}, "url is empty"},
+		{"relative url", &WebhookConfig{URL: "example.com"}, "not an absolute url"},
+		{"unsupported method", &WebhookConfig{URL: "https://example.com", Method: "DELETE"}, "unsupported method DELETE"},
+		{"empty signature secret", &WebhookConfig{URL: "https://example.com", Signature: &v1alpha1.WebhookSignature{}}, "signature secret is empty"},
+		{"invalid body template", &WebhookConfig{URL: "https://example.com", BodyTemplate: "{{ .Alerts"}, "body template is invalid"},
+		{"field mapping", &WebhookConfig{URL: "https://example.com", FieldMapping: map[string]string{"message": "text", "labels": ""}}, ""},
+		{"swapped fields", &WebhookConfig{URL: "https://example.com", FieldMapping: map[string]string{"status": "state", "receiver": "status"}}, ""},
+		{"duplicate field", &WebhookConfig{URL: "https://example.com", FieldMapping: map[string]string{"message": "status"}}, "are both mapped to status"},
+		{"unknown field", &WebhookConfig{URL: "https://example.com", FieldMapping: map[string]string{"severity": "level"}}, "unknown field severity"},
+	}
+
+	for _, tt := range tests {
+		w := NewWebhookReceiver().(*Webhook)
+		w.WebhookConfig = tt.config
+
+		err := w.Validate()
+		if len(tt.err) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected valid, got %s", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestDingTalkValidate(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		config *DingTalkConfig
+		err    string
+	}{
+		{"chatbot", &DingTalkConfig{ChatBot: &DingTalkChatBot{Webhook: keySelector("webhook")}}, ""},
+		{"conversation", &DingTalkConfig{Conversation: &DingTalkConversation{
+			AppKey: keySelector("key"), AppSecret: keySelector("secret"), ChatID: "chat1",
+		}}, ""},
+		{"empty config", nil, "config is empty"},
+		{"neither", &DingTalkConfig{}, "neither chatbot nor conversation is set"},
+		{"chatbot without webhook", &DingTalkConfig{ChatBot: &DingTalkChatBot{}}, "the webhook of chatbot is empty"},
+		{"conversation without secret", &DingTalkConfig{Conversation: &DingTalkConversation{
+			AppKey: keySelector("key"), ChatID: "chat1",
+		}}, "the appkey or appsecret of conversation is empty"},
+		{"conversation without chat", &DingTalkConfig{Conversation: &DingTalkConversation{
+			AppKey: keySelector("key"), AppSecret: keySelector("secret"),
+		}}, "the chatid of conversation is empty"},
+	}
+
+	for _, tt := range tests {
+		d := NewDingTalkReceiver().(*DingTalk)
+		d.DingTalkConfig = tt.config
+
+		err := d.Validate()
+		if len(tt.err) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected valid, got %s", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestEmailAndSlackValidate(t *testing.T) {
+
+	e := NewEmailReceiver().(*Email)
+	if err := e.Validate(); err != errEmptyConfig {
+		t.Errorf("expected the error of empty config, got %v", err)
+	}
+
+	e.EmailConfig = &EmailConfig{From: "nm@example.com", SmartHost: v1alpha1.HostPort{Host: "smtp.example.com", Port: "25"}}
+	if err := e.Validate(); err == nil {
+		t.Error("expected the error of empty receivers")
+	}
+
+	e.To = []string{"ops@example.com"}
+	if err := e.Validate(); err != nil {
+		t.Errorf("expected valid, got %s", err)
+	}
+
+	s := NewSlackReceiver().(*Slack)
+	s.SlackConfig = &SlackConfig{Token: keySelector("token")}
+	if err := s.Validate(); err == nil || err.Error() != "channel is empty" {
+		t.Errorf("expected the error of empty channel, got %v", err)
+	}
+
+	s.Channel = "alerts"
+	if err := s.Validate(); err != nil {
+		t.Errorf("expected valid, got %s", err)
+	}
+}
+
+func TestRocketChatValidate(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		msgType  string
+		channels []string
+		config   *RocketChatConfig
+		err      string
+	}{
+		{"webhook", RocketChatText, nil, &RocketChatConfig{Webhook: keySelector("webhook")}, ""},
+		{"api", RocketChatAttachment, []string{"#alerts"}, &RocketChatConfig{APIURL: "https://chat.example.com", UserID: keySelector("user"), Token: keySelector("token")}, ""},
+		{"empty config", RocketChatText, nil, nil, "config is empty"},
+		{"unknown type", "card", nil, &RocketChatConfig{Webhook: keySelector("webhook")}, "unknown message type card"},
+		{"empty api url", RocketChatText, []string{"#alerts"}, &RocketChatConfig{UserID: keySelector("user"), Token: keySelector("token")}, "api url"},
+		{"empty user id", RocketChatText, []string{"#alerts"}, &RocketChatConfig{APIURL: "https://chat.example.com", Token: keySelector("token")}, "user id is empty"},
+		{"empty token", RocketChatText, []string{"#alerts"}, &RocketChatConfig{APIURL: "https://chat.example.com", UserID: keySelector("user")}, "token is empty"},
+		{"empty channels", RocketChatText, nil, &RocketChatConfig{APIURL: "https://chat.example.com", UserID: keySelector("user"), Token: keySelector("token")}, "channels is empty"},
+	}
+
+	for _, tt := range tests {
+		r := NewRocketChatReceiver().(*RocketChat)
+		r.Type = tt.msgType
+		r.Channels = tt.channels
+		r.RocketChatConfig = tt.config
+
+		err := r.Validate()
+		if len(tt.err) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected valid, got %s", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package notifier
+
+import (
+	"sort"
+	"sync"
+)
+
+// Balancer chooses the endpoint of each request by smooth weighted round-robin, the endpoints of a
+// group are identified by the key, such as `CorpID | AgentID`.
+type Balancer struct {
+	mutex sync.Mutex
+	// The current weights of the endpoints, the first key is the key of group, and the second key is the endpoint.
+	current map[string]map[string]int
+}
+
+var balancer *Balancer
+
+func init() {
+	balancer = &Balancer{
+		current: make(map[string]map[string]int),
+	}
+}
+
+func GetBalancer() *Balancer {
+	return balancer
+}
+
+// Order returns the endpoints in the order to try, the endpoint chosen by weighted round-robin is the first,
+// and the others follow in the descending order of weights for failover. The weight less than 1 is treated as 1.
+func (b *Balancer) Order(key string, endpoints []string, weights []int) []string {
+
+	if len(endpoints) <= 1 {
+		return endpoints
+	}
+
+	weight := func(i int) int {
+		if i < len(weights) && weights[i] > 0 {
+			return weights[i]
+		}
+		return 1
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// The endpoints removed are dropped.
+	old := b.current[key]
+	current := make(map[string]int)
+	total, best := 0, 0
+	for i, e := range endpoints {
+		current[e] = old[e] + weight(i)
+		total += weight(i)
+		if current[e] > current[endpoints[best]] {
+			best = i
+		}
+	}
+	current[endpoints[best]] -= total
+	b.current[key] = current
+
+	var others []int
+	for i := range endpoints {
+		if i != best {
+			others = append(others, i)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return weight(others[i]) > weight(others[j])
+	})
+
+	ordered := []string{endpoints[best]}
+	for _, i := range others {
+		ordered = append(ordered, endpoints[i])
+	}
+
+	return ordered
+}
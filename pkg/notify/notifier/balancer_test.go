@@ -0,0 +1,80 @@
+package notifier
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBalancerOrder(t *testing.T) {
+
+	b := &Balancer{current: make(map[string]map[string]int)}
+	endpoints := []string{"a", "b", "c"}
+	weights := []int{5, 1, 2}
+
+	// The requests are distributed by the weights, and the others are ordered by the weights for failover.
+	counts := make(map[string]int)
+	for i := 0; i < 80; i++ {
+		ordered := b.Order("corp | agent", endpoints, weights)
+		if len(ordered) != len(endpoints) {
+			t.Fatalf("expected %d endpoints, got %v", len(endpoints), ordered)
+		}
+		counts[ordered[0]]++
+
+		var failover []string
+		for _, e := range []string{"a", "c", "b"} {
+			if e != ordered[0] {
+				failover = append(failover, e)
+			}
+		}
+		if !reflect.DeepEqual(ordered[1:], failover) {
+			t.Errorf("expected the failover order %v, got %v", failover, ordered[1:])
+		}
+	}
+
+	if want := map[string]int{"a": 50, "b": 10, "c": 20}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("expected the distribution %v, got %v", want, counts)
+	}
+}
+
+func TestBalancerSmooth(t *testing.T) {
+
+	b := &Balancer{current: make(map[string]map[string]int)}
+
+	// The smooth weighted round-robin interleaves the endpoints rather than sending bursts to one.
+	var got []string
+	for i := 0; i < 7; i++ {
+		got = append(got, b.Order("key", []string{"a", "b", "c"}, []int{5, 1, 1})[0])
+	}
+
+	if want := []string{"a", "a", "b", "a", "c", "a", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBalancerDefaults(t *testing.T) {
+
+	b := &Balancer{current: make(map[string]map[string]int)}
+
+	if got := b.Order("key", []string{"a"}, nil); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("expected the single endpoint, got %v", got)
+	}
+
+	// The weight not set or less than 1 is treated as 1.
+	counts := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		counts[b.Order("key", []string{"a", "b"}, []int{0})[0]]++
+	}
+	if counts["a"] != 5 || counts["b"] != 5 {
+		t.Errorf("expected the endpoints used equally, got %v", counts)
+	}
+
+	// The groups are balanced independently, and the endpoint removed is dropped.
+	b.Order("key", []string{"a", "b", "c"}, nil)
+	b.Order("key", []string{"a", "b"}, nil)
+	if _, ok := b.current["key"]["c"]; ok {
+		t.Error("expected the removed endpoint dropped")
+	}
+	if len(b.current) != 1 {
+		t.Errorf("expected 1 group, got %d", len(b.current))
+	}
+}
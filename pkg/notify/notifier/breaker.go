@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type circuit struct {
+	state    int
+	failures int
+	openedAt time.Time
+	// The time the probe request is allowed when the circuit is half open.
+	probedAt time.Time
+}
+
+// CircuitBreaker stops sending to the endpoint which fails repeatedly, the endpoint is identified by the key,
+// such as `CorpID | AgentID`. The circuit is opened after consecutive failures, and the requests will be rejected
+// during the cooldown. After the cooldown, one request is allowed to probe whether the endpoint is recovered,
+// and another probe is allowed if the result of the probe is not reported within the cooldown.
+type CircuitBreaker struct {
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+}
+
+var circuitBreaker *CircuitBreaker
+
+func init() {
+	circuitBreaker = &CircuitBreaker{
+		circuits: make(map[string]*circuit),
+	}
+}
+
+func GetCircuitBreaker() *CircuitBreaker {
+	return circuitBreaker
+}
+
+// Allow reports whether the request of the key can be sent.
+func (b *CircuitBreaker) Allow(key string, cooldown time.Duration) bool {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return true
+	}
+
+	switch c.state {
+	case circuitOpen:
+		if time.Since(c.openedAt) < cooldown {
+			return false
+		}
+		// Only one request is allowed when the circuit is half open.
+		c.state = circuitHalfOpen
+		c.probedAt = time.Now()
+		return true
+	case circuitHalfOpen:
+		if time.Since(c.probedAt) < cooldown {
+			return false
+		}
+		c.probedAt = time.Now()
+		return true
+	default:
+		return true
+	}
+}
+
+// Success closes the circuit of the key.
+func (b *CircuitBreaker) Success(key string) {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.circuits, key)
+}
+
+// Failure records a failure of the key, the circuit will be opened if the number of consecutive failures
+// reaches the threshold, or the probe request fails. It returns true if the circuit is opened by this failure.
+func (b *CircuitBreaker) Failure(key string, threshold int) bool {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	switch c.state {
+	case circuitOpen:
+		return false
+	case circuitHalfOpen:
+		c.state = circuitOpen
+		c.openedAt = time.Now()
+		return true
+	default:
+		c.failures++
+		if c.failures < threshold {
+			return false
+		}
+
+		c.state = circuitOpen
+		c.openedAt = time.Now()
+		return true
+	}
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{circuits: make(map[string]*circuit)}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+
+	const (
+		key       = "corp | agent"
+		threshold = 3
+		cooldown  = time.Millisecond * 50
+	)
+
+	b := newTestCircuitBreaker()
+
+	// Closed, the circuit is not opened until the consecutive failures reach the threshold.
+	for i := 1; i < threshold; i++ {
+		if b.Failure(key, threshold) {
+			t.Fatalf("expected the circuit closed after %d failures", i)
+		}
+		if !b.Allow(key, cooldown) {
+			t.Fatalf("expected the request allowed after %d failures", i)
+		}
+	}
+
+	// A success resets the failures.
+	b.Success(key)
+	for i := 1; i < threshold; i++ {
+		b.Failure(key, threshold)
+	}
+	if !b.Allow(key, cooldown) {
+		t.Fatal("expected the failures reset by the success")
+	}
+
+	// Open, the requests are rejected during the cooldown.
+	if !b.Failure(key, threshold) {
+		t.Fatal("expected the circuit opened")
+	}
+	if b.Allow(key, cooldown) {
+		t.Fatal("expected the request rejected when the circuit is open")
+	}
+	if b.Failure(key, threshold) {
+		t.Error("expected the open circuit not to be opened again")
+	}
+
+	// The other keys are not affected.
+	if !b.Allow("another", cooldown) {
+		t.Error("expected the request of another key allowed")
+	}
+
+	// Half open, only one probe is allowed after the cooldown.
+	time.Sleep(cooldown)
+	if !b.Allow(key, cooldown) {
+		t.Fatal("expected the probe allowed after the cooldown")
+	}
+	if b.Allow(key, cooldown) {
+		t.Fatal("expected only one probe allowed")
+	}
+
+	// The failed probe opens the circuit again.
+	if !b.Failure(key, threshold) {
+		t.Fatal("expected the circuit opened by the failed probe")
+	}
+	if b.Allow(key, cooldown) {
+		t.Fatal("expected the request rejected after the failed probe")
+	}
+
+	// The successful probe closes the circuit.
+	time.Sleep(cooldown)
+	if !b.Allow(key, cooldown) {
+		t.Fatal("expected the probe allowed after the cooldown")
+	}
+	b.Success(key)
+	for i := 0; i < threshold; i++ {
+		if !b.Allow(key, cooldown) {
+			t.Fatal("expected the requests allowed after the successful probe")
+		}
+	}
+}
+
+func TestCircuitBreakerProbeTimeout(t *testing.T) {
+
+	const cooldown = time.Millisecond * 50
+
+	b := newTestCircuitBreaker()
+	b.Failure("key", 1)
+
+	time.Sleep(cooldown)
+	if !b.Allow("key", cooldown) {
+		t.Fatal("expected the probe allowed after the cooldown")
+	}
+
+	// The result of the probe is never reported, another probe is allowed after the cooldown.
+	time.Sleep(cooldown)
+	if !b.Allow("key", cooldown) {
+		t.Fatal("expected another probe allowed")
+	}
+}
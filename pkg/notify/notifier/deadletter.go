@@ -0,0 +1,190 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// The timeout of writing a dead letter.
+	DeadLetterTimeout = time.Second * 5
+	// The maximum number of dead letters saved in the configmap.
+	DefaultDeadLetterMaxSize = 100
+)
+
+// DeadLetter is the notification which failed to send after all retries.
+type DeadLetter struct {
+	NotifierType string `json:"notifierType"`
+	Receiver     string `json:"receiver"`
+	Target       string `json:"target"`
+	// The rendered message, it can be sent to the target again.
+	Payload string    `json:"payload"`
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+}
+
+// DeadLetterSink saves the dead letters, so that they can be inspected and replayed.
+type DeadLetterSink interface {
+	Write(ctx context.Context, dl *DeadLetter) error
+}
+
+var (
+	deadLetterMutex sync.RWMutex
+	deadLetterSink  DeadLetterSink
+)
+
+// SetDeadLetterSink sets the sink of dead letters, the dead letters are dropped if the sink is nil.
+func SetDeadLetterSink(sink DeadLetterSink) {
+
+	deadLetterMutex.Lock()
+	defer deadLetterMutex.Unlock()
+
+	deadLetterSink = sink
+}
+
+// WriteDeadLetter writes the payload failed to send to the dead letter sink, it does nothing if the sink is not set.
+func WriteDeadLetter(l log.Logger, e *SendError, payload []byte) {
+
+	deadLetterMutex.RLock()
+	sink := deadLetterSink
+	deadLetterMutex.RUnlock()
+
+	if sink == nil {
+		return
+	}
+
+	dl := &DeadLetter{
+		NotifierType: e.NotifierType,
+		Receiver:     e.Receiver,
+		Target:       e.Target,
+		Payload:      string(payload),
+		Error:        e.Err.Error(),
+		Time:         time.Now(),
+	}
+
+	// The context of the sending may be done, so the dead letter uses its own context.
+	ctx, cancel := context.WithTimeout(context.Background(), DeadLetterTimeout)
+	defer cancel()
+
+	if err := sink.Write(ctx, dl); err != nil {
+		_ = level.Error(l).Log("msg", "write dead letter error", "type", e.NotifierType, "receiver", e.Receiver, "error", err.Error())
+		return
+	}
+
+	_ = level.Debug(l).Log("msg", "write dead letter", "type", e.NotifierType, "receiver", e.Receiver, "target", e.Target)
+}
+
+// webhookDeadLetterSink posts the dead letters to a webhook.
+type webhookDeadLetterSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookDeadLetterSink(url string) DeadLetterSink {
+	return &webhookDeadLetterSink{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+func (s *webhookDeadLetterSink) Write(ctx context.Context, dl *DeadLetter) error {
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(dl); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.url, &buf)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", DefaultUserAgent())
+
+	_, err = DoHttpRequest(ctx, s.client, request)
+	return err
+}
+
+// configMapDeadLetterSink saves the dead letters to a configmap, the oldest dead letters are dropped
+// when the number of them exceeds the maximum.
+type configMapDeadLetterSink struct {
+	client    client.Client
+	namespace string
+	name      string
+	maxSize   int
+	mutex     sync.Mutex
+}
+
+func NewConfigMapDeadLetterSink(c client.Client, namespace, name string, maxSize int) DeadLetterSink {
+
+	if maxSize <= 0 {
+		maxSize = DefaultDeadLetterMaxSize
+	}
+
+	return &configMapDeadLetterSink{
+		client:    c,
+		namespace: namespace,
+		name:      name,
+		maxSize:   maxSize,
+	}
+}
+
+func (s *configMapDeadLetterSink) Write(ctx context.Context, dl *DeadLetter) error {
+
+	bs, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cm := &v1.ConfigMap{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: s.name}, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+			},
+		}
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	// The key is sortable by the time of the dead letter.
+	cm.Data[fmt.Sprintf("%d-%s", dl.Time.UnixNano(), dl.NotifierType)] = string(bs)
+
+	if len(cm.Data) > s.maxSize {
+		var keys []string
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys[:len(keys)-s.maxSize] {
+			delete(cm.Data, k)
+		}
+	}
+
+	if len(cm.ResourceVersion) == 0 {
+		return s.client.Create(ctx, cm)
+	}
+
+	return s.client.Update(ctx, cm)
+}
@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A sink records the dead letters in memory.
+type memoryDeadLetterSink struct {
+	mutex   sync.Mutex
+	letters []*DeadLetter
+	err     error
+}
+
+func (s *memoryDeadLetterSink) Write(_ context.Context, dl *DeadLetter) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.letters = append(s.letters, dl)
+	return nil
+}
+
+func TestWriteDeadLetter(t *testing.T) {
+
+	se := NewSendError("wechat", "corp/1000002", "toUser: user1", errors.New("system busy"))
+
+	// The dead letter is dropped without a sink.
+	SetDeadLetterSink(nil)
+	WriteDeadLetter(log.NewNopLogger(), se, []byte("payload"))
+
+	sink := &memoryDeadLetterSink{}
+	SetDeadLetterSink(sink)
+	defer SetDeadLetterSink(nil)
+
+	WriteDeadLetter(log.NewNopLogger(), se, []byte("payload"))
+	if len(sink.letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(sink.letters))
+	}
+
+	dl := sink.letters[0]
+	if dl.NotifierType != "wechat" || dl.Receiver != "corp/1000002" || dl.Target != "toUser: user1" {
+		t.Errorf("expected the dead letter of the send error, got %+v", dl)
+	}
+	if dl.Payload != "payload" {
+		t.Errorf("expected payload %q, got %q", "payload", dl.Payload)
+	}
+	if dl.Error != "system busy" {
+		t.Errorf("expected error %q, got %q", "system busy", dl.Error)
+	}
+
+	// The error of the sink is logged only.
+	sink.err = errors.New("sink error")
+	WriteDeadLetter(log.NewNopLogger(), se, []byte("payload"))
+	if len(sink.letters) != 1 {
+		t.Errorf("expected 1 dead letter, got %d", len(sink.letters))
+	}
+}
+
+func TestWebhookDeadLetterSink(t *testing.T) {
+
+	ch := make(chan *DeadLetter, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected content type application/json, got %s", ct)
+		}
+		dl := &DeadLetter{}
+		if err := json.NewDecoder(r.Body).Decode(dl); err != nil {
+			t.Errorf("decode dead letter error, %s", err)
+		}
+		ch <- dl
+	}))
+	defer s.Close()
+
+	want := &DeadLetter{
+		NotifierType: "wechat",
+		Receiver:     "corp/1000002",
+		Target:       "toUser: user1",
+		Payload:      `{"msgtype":"text"}`,
+		Error:        "system busy",
+		Time:         time.Now().Round(time.Second),
+	}
+	if err := NewWebhookDeadLetterSink(s.URL).Write(context.Background(), want); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	got := <-ch
+	if got.Payload != want.Payload || got.Error != want.Error || !got.Time.Equal(want.Time) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestWebhookDeadLetterSinkError(t *testing.T) {
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	if err := NewWebhookDeadLetterSink(s.URL).Write(context.Background(), &DeadLetter{}); err == nil {
+		t.Error("expected the error of the webhook, got nil")
+	}
+}
+
+func TestConfigMapDeadLetterSink(t *testing.T) {
+
+	c := fake.NewFakeClient()
+	sink := NewConfigMapDeadLetterSink(c, "default", "dead-letters", 2)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		dl := &DeadLetter{NotifierType: "wechat", Payload: string(rune('a' + i)), Time: now.Add(time.Duration(i) * time.Second)}
+		if err := sink.Write(context.Background(), dl); err != nil {
+			t.Fatalf("expected no error, got %s", err)
+		}
+	}
+
+	cm := &v1.ConfigMap{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "dead-letters"}, cm); err != nil {
+		t.Fatalf("get configmap error, %s", err)
+	}
+
+	// The oldest dead letter is dropped.
+	if len(cm.Data) != 2 {
+		t.Fatalf("expected 2 dead letters, got %d", len(cm.Data))
+	}
+	var payloads []string
+	for _, v := range cm.Data {
+		dl := &DeadLetter{}
+		if err := json.Unmarshal([]byte(v), dl); err != nil {
+			t.Fatalf("decode dead letter error, %s", err)
+		}
+		payloads = append(payloads, dl.Payload)
+	}
+	for _, p := range payloads {
+		if p == "a" {
+			t.Errorf("expected the oldest dead letter to be dropped, got %v", payloads)
+		}
+	}
+}
+
+func TestConfigMapDeadLetterSinkExisting(t *testing.T) {
+
+	existing := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dead-letters", ResourceVersion: "1"},
+		Data:       map[string]string{"0-wechat": "{}"},
+	}
+	c := fake.NewFakeClient(existing)
+
+	if err := NewConfigMapDeadLetterSink(c, "default", "dead-letters", 0).Write(context.Background(), &DeadLetter{Time: time.Now()}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+
+	cm := &v1.ConfigMap{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "dead-letters"}, cm); err != nil {
+		t.Fatalf("get configmap error, %s", err)
+	}
+	if len(cm.Data) != 2 {
+		t.Errorf("expected 2 dead letters, got %d", len(cm.Data))
+	}
+}
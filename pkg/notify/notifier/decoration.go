@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/alertmanager/template"
+	"strings"
+)
+
+// Decoration is the header and footer added to each message, both of them can be templates.
+type Decoration struct {
+	Header string
+	Footer string
+}
+
+func (d *Decoration) render(t *Template, s string, data template.Data, l log.Logger) (string, error) {
+
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	return t.Text(s, data, l)
+}
+
+// Decorate adds the header and footer to the message which is not split.
+func (d *Decoration) Decorate(t *Template, msg string, data template.Data, l log.Logger) (string, error) {
+
+	if d == nil {
+		return msg, nil
+	}
+
+	header, err := d.render(t, d.Header, data, l)
+	if err != nil {
+		return "", err
+	}
+
+	footer, err := d.render(t, d.Footer, data, l)
+	if err != nil {
+		return "", err
+	}
+
+	if len(header) > 0 {
+		msg = header + "\n" + msg
+	}
+	if len(footer) > 0 {
+		msg = msg + "\n" + footer
+	}
+
+	return msg, nil
+}
+
+// SplitWithDecoration splits the alerts into messages like Split, and adds the header and footer to each message.
+// The space of the header and footer is reserved when splitting, so the messages with them will not exceed maxSize.
+func (t *Template) SplitWithDecoration(data template.Data, maxSize int, templateName string, d *Decoration, l log.Logger) ([]string, error) {
+	return t.SplitMessages(data, maxSize, templateName, SplitModeSize, d, l)
+}
+
+// SplitMessages splits the alerts into messages in the split mode, and adds the header and footer to each message.
+func (t *Template) SplitMessages(data template.Data, maxSize int, templateName, mode string, d *Decoration, l log.Logger) ([]string, error) {
+
+	split := t.Split
+	if mode == SplitModeAlert {
+		split = t.SplitByAlert
+	}
+
+	splitAndWarn := func(maxSize int) ([]string, error) {
+		messages, truncated, err := split(data, maxSize, templateName, l)
+		if err != nil {
+			return nil, err
+		}
+
+		if truncated > 0 {
+			_ = level.Warn(l).Log("msg", "alerts are too large, split them", "alerts", truncated, "maxSize", maxSize)
+		}
+
+		return messages, nil
+	}
+
+	if d == nil || (len(d.Header) == 0 && len(d.Footer) == 0) {
+		return splitAndWarn(maxSize)
+	}
+
+	header, err := d.render(t, d.Header, data, l)
+	if err != nil {
+		return nil, err
+	}
+
+	footer, err := d.render(t, d.Footer, data, l)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 0
+	if len(header) > 0 {
+		size += Len(header + "\n")
+	}
+	if len(footer) > 0 {
+		size += Len("\n" + footer)
+	}
+
+	if size >= maxSize {
+		_ = level.Warn(l).Log("msg", "header and footer are too large, ignore them")
+		return splitAndWarn(maxSize)
+	}
+
+	messages, err := splitAndWarn(maxSize - size)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range messages {
+		if len(header) > 0 {
+			messages[i] = header + "\n" + messages[i]
+		}
+		if len(footer) > 0 {
+			messages[i] = messages[i] + "\n" + footer
+		}
+	}
+
+	return messages, nil
+}
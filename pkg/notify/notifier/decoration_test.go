@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/template"
+	"strings"
+	"testing"
+)
+
+func newDecorationData(n int) template.Data {
+
+	data := template.Data{}
+	for i := 0; i < n; i++ {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": fmt.Sprintf("alert%d", i)},
+			Annotations: template.KV{"message": strings.Repeat("m", 50)},
+		})
+	}
+
+	return data
+}
+
+func TestSplitWithDecoration(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := newDecorationData(20)
+
+	const maxSize = 300
+	d := &Decoration{Header: "[prod-1]", Footer: "Sent by notification-manager, {{ len .Alerts }} alerts"}
+
+	messages, err := tmpl.SplitWithDecoration(data, maxSize, "test.text", d, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	if len(messages) < 2 {
+		t.Fatalf("expected the alerts to be split, got %d message", len(messages))
+	}
+
+	alerts := 0
+	for i, msg := range messages {
+		if !strings.HasPrefix(msg, "[prod-1]\n") {
+			t.Errorf("message %d has no header, %q", i, msg)
+		}
+
+		// The footer is rendered with all of the alerts.
+		if !strings.HasSuffix(msg, "\nSent by notification-manager, 20 alerts") {
+			t.Errorf("message %d has no footer, %q", i, msg)
+		}
+
+		if Len(msg) > maxSize {
+			t.Errorf("message %d has %d bytes, exceeds %d", i, Len(msg), maxSize)
+		}
+
+		alerts += strings.Count(msg, "alert")
+	}
+
+	// Each alert is in the messages once, plus the word of the footer in each message.
+	if alerts-len(messages) != 20 {
+		t.Errorf("expected 20 alerts in the messages, got %d", alerts-len(messages))
+	}
+}
+
+func TestSplitWithDecorationTooLarge(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := newDecorationData(5)
+
+	const maxSize = 100
+	d := &Decoration{Footer: strings.Repeat("f", maxSize)}
+
+	messages, err := tmpl.SplitWithDecoration(data, maxSize, "test.text", d, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	// The footer that does not fit in a message is ignored rather than exceeding the max size.
+	for i, msg := range messages {
+		if strings.Contains(msg, "fff") {
+			t.Errorf("message %d contains the too large footer", i)
+		}
+
+		if Len(msg) > maxSize {
+			t.Errorf("message %d has %d bytes, exceeds %d", i, Len(msg), maxSize)
+		}
+	}
+}
+
+func TestSplitWithoutDecoration(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := newDecorationData(20)
+
+	expected, _, err := tmpl.Split(data, 300, "test.text", log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	for _, d := range []*Decoration{nil, {}} {
+		messages, err := tmpl.SplitWithDecoration(data, 300, "test.text", d, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("split error, %s", err)
+		}
+
+		if strings.Join(messages, "|") != strings.Join(expected, "|") {
+			t.Errorf("expected the messages of split without decoration, got %q", messages)
+		}
+	}
+}
+
+func TestDecorate(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := newDecorationData(2)
+
+	tests := []struct {
+		d    *Decoration
+		want string
+	}{
+		{nil, "msg"},
+		{&Decoration{}, "msg"},
+		{&Decoration{Header: "header"}, "header\nmsg"},
+		{&Decoration{Footer: "{{ len .Alerts }} alerts"}, "msg\n2 alerts"},
+		{&Decoration{Header: "header", Footer: "footer"}, "header\nmsg\nfooter"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.d.Decorate(tmpl, "msg", data, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("decorate error, %s", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+
+	if _, err := (&Decoration{Header: "{{ .Unknown"}).Decorate(tmpl, "msg", data, log.NewNopLogger()); err == nil {
+		t.Error("expected the error of the invalid header template")
+	}
+}
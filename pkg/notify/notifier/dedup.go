@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// The minimum interval between two cleanups of the expired records.
+	dedupCleanInterval = time.Minute
+)
+
+// Deduplicator records the messages sent recently, and suppresses the message
+// which has been sent within the dedup window.
+type Deduplicator struct {
+	mutex sync.Mutex
+	// The key is the hash of the message and the receiver, the value is the time the record expired.
+	records   map[string]time.Time
+	lastClean time.Time
+}
+
+var deduplicator *Deduplicator
+
+func init() {
+	deduplicator = &Deduplicator{
+		records:   make(map[string]time.Time),
+		lastClean: time.Now(),
+	}
+}
+
+func GetDeduplicator() *Deduplicator {
+	return deduplicator
+}
+
+// DedupKey returns the key used to deduplicate the message sent to the receiver.
+func DedupKey(receiver interface{}, msg interface{}) (string, error) {
+	return Md5key(struct {
+		Receiver interface{} `json:"receiver"`
+		Message  interface{} `json:"message"`
+	}{receiver, msg})
+}
+
+// Allow returns false if the message of the key has been sent within the window,
+// otherwise it records the key and returns true. The dedup is disabled if the window is 0.
+func (d *Deduplicator) Allow(key string, window time.Duration) bool {
+
+	if window <= 0 {
+		return true
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	d.clean(now)
+
+	if expireAt, ok := d.records[key]; ok && now.Before(expireAt) {
+		return false
+	}
+
+	d.records[key] = now.Add(window)
+	return true
+}
+
+// Forget removes the record of the key, so the message can be sent again.
+// It should be called when the message failed to send.
+func (d *Deduplicator) Forget(key string) {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.records, key)
+}
+
+// Remove the expired records, so the records will not grow without limit.
+func (d *Deduplicator) clean(now time.Time) {
+
+	if now.Sub(d.lastClean) < dedupCleanInterval {
+		return
+	}
+
+	for k, expireAt := range d.records {
+		if !now.Before(expireAt) {
+			delete(d.records, k)
+		}
+	}
+
+	d.lastClean = now
+}
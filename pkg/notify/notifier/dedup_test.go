@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestDeduplicator() *Deduplicator {
+	return &Deduplicator{records: make(map[string]time.Time), lastClean: time.Now()}
+}
+
+func TestDeduplicatorWindow(t *testing.T) {
+
+	const window = time.Millisecond * 50
+
+	d := newTestDeduplicator()
+	if !d.Allow("key", window) {
+		t.Fatal("expected the first message allowed")
+	}
+
+	// The repeat within the window is suppressed, the other messages are not.
+	if d.Allow("key", window) {
+		t.Error("expected the repeat within the window suppressed")
+	}
+	if !d.Allow("other", window) {
+		t.Error("expected the other message allowed")
+	}
+
+	// The repeat after the window is sent.
+	time.Sleep(window)
+	if !d.Allow("key", window) {
+		t.Error("expected the repeat after the window allowed")
+	}
+}
+
+func TestDeduplicatorDisabled(t *testing.T) {
+
+	d := newTestDeduplicator()
+	for i := 0; i < 3; i++ {
+		if !d.Allow("key", 0) {
+			t.Fatalf("expected the message %d allowed while the dedup is disabled", i)
+		}
+	}
+
+	if len(d.records) != 0 {
+		t.Errorf("expected no record while the dedup is disabled, got %d", len(d.records))
+	}
+}
+
+func TestDeduplicatorForget(t *testing.T) {
+
+	d := newTestDeduplicator()
+	if !d.Allow("key", time.Hour) {
+		t.Fatal("expected the first message allowed")
+	}
+
+	// The message failed to send is forgotten, so the retry is not suppressed.
+	d.Forget("key")
+	if !d.Allow("key", time.Hour) {
+		t.Error("expected the retry of the failed message allowed")
+	}
+}
+
+func TestDeduplicatorClean(t *testing.T) {
+
+	d := newTestDeduplicator()
+	d.Allow("expired", time.Millisecond)
+	d.Allow("alive", time.Hour)
+	time.Sleep(time.Millisecond * 2)
+
+	// The expired records are kept until the clean interval passed.
+	d.Allow("new", time.Hour)
+	if _, ok := d.records["expired"]; !ok {
+		t.Fatal("expected the expired record kept within the clean interval")
+	}
+
+	d.lastClean = time.Now().Add(-dedupCleanInterval)
+	d.Allow("new", time.Hour)
+	if _, ok := d.records["expired"]; ok {
+		t.Error("expected the expired record removed by the clean")
+	}
+	if len(d.records) != 2 {
+		t.Errorf("expected the alive records kept, got %d records", len(d.records))
+	}
+}
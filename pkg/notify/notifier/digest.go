@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDigestInterval = time.Hour
+	DefaultDigestTemplate = `{{ template "nm.default.digest" . }}`
+	// The template of the summary message sent rather than the alerts when there are too many alerts.
+	DefaultSummaryTemplate = `{{ template "nm.default.summary" . }}`
+	// The timeout of sending the digest.
+	DefaultDigestSendTimeout = time.Second * 30
+)
+
+// Digester accumulates the alerts of each receiver, and sends them in one notification on the schedule of the digest.
+type Digester struct {
+	mutex   sync.Mutex
+	buffers map[string]*digestBuffer
+}
+
+type digestBuffer struct {
+	data  template.Data
+	timer *time.Timer
+	flush func(data template.Data)
+}
+
+var digester *Digester
+
+func init() {
+	digester = &Digester{
+		buffers: make(map[string]*digestBuffer),
+	}
+}
+
+func GetDigester() *Digester {
+	return digester
+}
+
+// ValidateDigest checks the times and the time zone of the digest.
+func ValidateDigest(d *v1alpha1.Digest) error {
+
+	for _, at := range d.At {
+		if _, err := time.Parse("15:04", at); err != nil {
+			return err
+		}
+	}
+
+	_, err := time.LoadLocation(d.TimeZone)
+	return err
+}
+
+// NextDigestTime returns the time the digest is sent after the time t.
+func NextDigestTime(d *v1alpha1.Digest, t time.Time) time.Time {
+
+	loc, err := time.LoadLocation(d.TimeZone)
+	if err != nil || len(d.At) == 0 {
+		interval := DefaultDigestInterval
+		if d.Interval > 0 {
+			interval = d.Interval
+		}
+		return t.Add(interval)
+	}
+
+	t = t.In(loc)
+	var next time.Time
+	for _, at := range d.At {
+		a, err := time.Parse("15:04", at)
+		if err != nil {
+			continue
+		}
+
+		n := time.Date(t.Year(), t.Month(), t.Day(), a.Hour(), a.Minute(), 0, 0, loc)
+		if !n.After(t) {
+			n = n.AddDate(0, 0, 1)
+		}
+
+		if next.IsZero() || n.Before(next) {
+			next = n
+		}
+	}
+
+	if next.IsZero() {
+		return t.Add(DefaultDigestInterval)
+	}
+
+	return next
+}
+
+// Add accumulates the alerts of the key, the alerts with the same fingerprint are counted once.
+// The alerts are passed to the flush function at the next time of the digest, and the latest flush function is used.
+func (d *Digester) Add(key string, data template.Data, digest *v1alpha1.Digest, flush func(data template.Data)) {
+
+	if len(data.Alerts) == 0 {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	b, ok := d.buffers[key]
+	if !ok {
+		b = &digestBuffer{data: data}
+		b.timer = time.AfterFunc(time.Until(NextDigestTime(digest, time.Now())), func() {
+			d.flush(key, b)
+		})
+		d.buffers[key] = b
+	} else {
+		b.data = mergeData(b.data, data)
+	}
+	b.flush = flush
+}
+
+func (d *Digester) flush(key string, b *digestBuffer) {
+
+	d.mutex.Lock()
+	// The buffer has been flushed on shutdown.
+	if d.buffers[key] != b {
+		d.mutex.Unlock()
+		return
+	}
+	delete(d.buffers, key)
+	data, flush := b.data, b.flush
+	d.mutex.Unlock()
+
+	flush(data)
+}
+
+// Flush passes all the accumulated alerts to the flush functions and waits for them to finish, it is called on shutdown.
+func (d *Digester) Flush() {
+
+	d.mutex.Lock()
+	buffers := d.buffers
+	d.buffers = make(map[string]*digestBuffer)
+	d.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, buffer := range buffers {
+		b := buffer
+		b.timer.Stop()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.flush(b.data)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,133 @@
+package notifier
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateDigest(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		digest *v1alpha1.Digest
+		valid  bool
+	}{
+		{"interval", &v1alpha1.Digest{Interval: time.Hour}, true},
+		{"times", &v1alpha1.Digest{At: []string{"08:00", "18:30"}, TimeZone: "Asia/Shanghai"}, true},
+		{"invalid time", &v1alpha1.Digest{At: []string{"8am"}}, false},
+		{"invalid time zone", &v1alpha1.Digest{At: []string{"08:00"}, TimeZone: "Mars/Olympus"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateDigest(tt.digest); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %t, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestNextDigestTime(t *testing.T) {
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2020, 10, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		digest *v1alpha1.Digest
+		want   time.Time
+	}{
+		{"default interval", &v1alpha1.Digest{}, now.Add(DefaultDigestInterval)},
+		{"interval", &v1alpha1.Digest{Interval: time.Minute * 10}, now.Add(time.Minute * 10)},
+		{"later today", &v1alpha1.Digest{At: []string{"08:00", "18:00"}}, time.Date(2020, 10, 1, 18, 0, 0, 0, time.UTC)},
+		{"tomorrow", &v1alpha1.Digest{At: []string{"08:00"}}, time.Date(2020, 10, 2, 8, 0, 0, 0, time.UTC)},
+		{"now is not next", &v1alpha1.Digest{At: []string{"09:30"}}, time.Date(2020, 10, 2, 9, 30, 0, 0, time.UTC)},
+		// It is 17:30 in Shanghai.
+		{"time zone", &v1alpha1.Digest{At: []string{"08:00", "18:00"}, TimeZone: "Asia/Shanghai"}, time.Date(2020, 10, 1, 18, 0, 0, 0, shanghai)},
+		{"invalid time zone", &v1alpha1.Digest{At: []string{"08:00"}, TimeZone: "Mars/Olympus", Interval: time.Minute}, now.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		if got := NextDigestTime(tt.digest, now); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDigesterAdd(t *testing.T) {
+
+	d := &Digester{buffers: make(map[string]*digestBuffer)}
+	digest := &v1alpha1.Digest{Interval: time.Millisecond * 200}
+	r := newFlushRecorder()
+
+	// The alerts fed over time are accumulated, the alert with the same fingerprint is counted once.
+	d.Add("wechat/receiver", newPriorityData("warning"), digest, r.flush)
+	time.Sleep(time.Millisecond * 50)
+	d.Add("wechat/receiver", newPriorityData("info", "warning"), digest, r.flush)
+	d.Add("wechat/receiver", template.Data{}, digest, r.flush)
+
+	if len(r.get()) != 0 {
+		t.Fatal("expected the alerts accumulated until the digest time")
+	}
+
+	r.wait(t, time.Second)
+
+	flushed := r.get()
+	if len(flushed) != 1 {
+		t.Fatalf("expected 1 digest, got %d", len(flushed))
+	}
+
+	var fingerprints []string
+	for _, a := range flushed[0].Alerts {
+		fingerprints = append(fingerprints, a.Fingerprint)
+	}
+	if want := []string{"warning", "info"}; !reflect.DeepEqual(fingerprints, want) {
+		t.Errorf("expected the alerts %v, got %v", want, fingerprints)
+	}
+
+	// The digest is sent once, and the next alerts start a new digest.
+	time.Sleep(time.Millisecond * 250)
+	if len(r.get()) != 1 {
+		t.Errorf("expected 1 digest, got %d", len(r.get()))
+	}
+}
+
+func TestDigesterFlush(t *testing.T) {
+
+	d := &Digester{buffers: make(map[string]*digestBuffer)}
+	digest := &v1alpha1.Digest{Interval: time.Hour}
+	r := newFlushRecorder()
+
+	d.Add("wechat/receiver", newPriorityData("warning"), digest, r.flush)
+	d.Add("wechat/other", newPriorityData("info"), digest, r.flush)
+
+	// All digests are sent on shutdown.
+	d.Flush()
+	if len(r.get()) != 2 {
+		t.Errorf("expected 2 digests flushed, got %d", len(r.get()))
+	}
+	if len(d.buffers) != 0 {
+		t.Errorf("expected the buffers dropped, got %d", len(d.buffers))
+	}
+}
+
+func TestCountBy(t *testing.T) {
+
+	alerts := template.Alerts{
+		{Labels: template.KV{"alertname": "b"}},
+		{Labels: template.KV{"alertname": "a"}},
+		{Labels: template.KV{"alertname": "c"}},
+		{Labels: template.KV{"alertname": "c"}},
+		{Labels: template.KV{}},
+	}
+
+	// The counts are sorted in descending order, and the values of the same count are sorted.
+	want := []LabelCount{{"c", 2}, {"", 1}, {"a", 1}, {"b", 1}}
+	if got := countBy(alerts, "alertname"); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
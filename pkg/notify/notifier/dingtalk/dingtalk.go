@@ -15,7 +15,6 @@ import (
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	"github.com/prometheus/alertmanager/template"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 )
@@ -41,6 +40,7 @@ type Notifier struct {
 	logger                     log.Logger
 	template                   *notifier.Template
 	templateName               string
+	decoration                 *notifier.Decoration
 	throttle                   *Throttle
 	ats                        *notifier.AccessTokenService
 	tokenExpires               time.Duration
@@ -58,8 +58,14 @@ type dingtalkMessageContent struct {
 	Content string `json:"content"`
 }
 
+type dingtalkAt struct {
+	AtMobiles []string `yaml:"atMobiles,omitempty" json:"atMobiles,omitempty"`
+	AtUserIds []string `yaml:"atUserIds,omitempty" json:"atUserIds,omitempty"`
+}
+
 type dingtalkMessage struct {
 	Text dingtalkMessageContent `yaml:"text,omitempty" json:"text,omitempty"`
+	At   *dingtalkAt            `yaml:"at,omitempty" json:"at,omitempty"`
 	ID   string                 `yaml:"chatid,omitempty" json:"chatid,omitempty"`
 	Type string                 `yaml:"msgtype,omitempty" json:"msgtype,omitempty"`
 }
@@ -72,17 +78,20 @@ type response struct {
 	Punish  string `json:"punish"`
 }
 
-func NewDingTalkNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) notifier.Notifier {
+func NewDingTalkNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
 
 	var path []string
+	var externalURL string
+	var header, footer string
 	opts := notifierCfg.ReceiverOpts
 	if opts != nil && opts.Global != nil {
 		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
 	}
-	tmpl, err := notifier.NewTemplate(path)
+	tmpl, err := notifier.NewTemplate(path, externalURL)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "DingTalkNotifier: get template error", "error", err.Error())
-		return nil
+		return nil, fmt.Errorf("get template error, %s", err.Error())
 	}
 
 	n := &Notifier{
@@ -91,6 +100,7 @@ func NewDingTalkNotifier(logger log.Logger, receivers []config.Receiver, notifie
 		logger:                     logger,
 		template:                   tmpl,
 		templateName:               DefaultTemplate,
+		decoration:                 &notifier.Decoration{Header: header, Footer: footer},
 		throttle:                   GetThrottle(),
 		ats:                        notifier.GetAccessTokenService(),
 		tokenExpires:               DefaultExpires,
@@ -118,6 +128,14 @@ func NewDingTalkNotifier(logger log.Logger, receivers []config.Receiver, notifie
 			n.templateName = opts.Global.Template
 		}
 
+		if len(opts.DingTalk.Header) > 0 {
+			n.decoration.Header = opts.DingTalk.Header
+		}
+
+		if len(opts.DingTalk.Footer) > 0 {
+			n.decoration.Footer = opts.DingTalk.Footer
+		}
+
 		if d.TokenExpires != 0 {
 			n.tokenExpires = d.TokenExpires
 		}
@@ -167,15 +185,15 @@ func NewDingTalkNotifier(logger log.Logger, receivers []config.Receiver, notifie
 			continue
 		}
 
-		if receiver.DingTalkConfig == nil {
-			_ = level.Warn(logger).Log("msg", "DingTalkNotifier: ignore receiver because of empty config")
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "DingTalkNotifier: ignore invalid receiver", "error", err.Error())
 			continue
 		}
 
 		n.DingTalk = append(n.DingTalk, receiver)
 	}
 
-	return n
+	return n, nil
 }
 
 func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
@@ -210,6 +228,11 @@ func (n *Notifier) sendToChatBot(ctx context.Context, d *config.DingTalk, data t
 		return []error{err}
 	}
 
+	at, err := n.mention(d, data)
+	if err != nil {
+		return []error{err}
+	}
+
 	send := func(msg string) error {
 
 		start := time.Now()
@@ -222,6 +245,7 @@ func (n *Notifier) sendToChatBot(ctx context.Context, d *config.DingTalk, data t
 			Text: dingtalkMessageContent{
 				Content: msg,
 			},
+			At: at,
 		}
 
 		var buf bytes.Buffer
@@ -230,15 +254,20 @@ func (n *Notifier) sendToChatBot(ctx context.Context, d *config.DingTalk, data t
 			return err
 		}
 
-		secret, err := n.notifierCfg.GetSecretData(d.GetNamespace(), bot.Secret)
-		if err != nil {
-			_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: get chatbot secret error", "error", err.Error())
-			return err
+		// The secret is optional, the message is signed only if the secret is set.
+		secret := ""
+		if bot.Secret != nil {
+			secret, err = n.notifierCfg.GetSecretData(d.GetNamespace(), bot.Secret)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: get chatbot secret error", "error", err.Error())
+				return err
+			}
 		}
 
 		u := webhook
 		if len(secret) > 0 {
-			timestamp, sign := calcSign(secret)
+			// The timestamp is generated right before sending to minimize the clock skew.
+			timestamp, sign := calcSign(secret, time.Now())
 			p := make(map[string]string)
 			p["timestamp"] = timestamp
 			p["sign"] = sign
@@ -256,6 +285,10 @@ func (n *Notifier) sendToChatBot(ctx context.Context, d *config.DingTalk, data t
 		}
 		request.Header.Set("Content-Type", "application/json")
 
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
 		body, err := notifier.DoHttpRequest(context.Background(), nil, request)
 		if err != nil {
 			_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: do http error", "error", err)
@@ -293,7 +326,19 @@ func (n *Notifier) sendToChatBot(ctx context.Context, d *config.DingTalk, data t
 		keywords = strings.TrimSuffix(keywords, ", ")
 	}
 
-	messages, err := n.template.Split(data, n.chatbotMessageMaxSize-len(keywords), n.templateName, n.logger)
+	// The mentioned users and mobiles must be in the content, otherwise they will not be notified.
+	if at != nil {
+		mention := "\n\n"
+		for _, m := range at.AtMobiles {
+			mention = fmt.Sprintf("%s@%s ", mention, m)
+		}
+		for _, u := range at.AtUserIds {
+			mention = fmt.Sprintf("%s@%s ", mention, u)
+		}
+		keywords = strings.TrimSuffix(mention, " ") + keywords
+	}
+
+	messages, err := n.template.SplitWithDecoration(data, n.chatbotMessageMaxSize-len(keywords), n.templateName, n.decoration, n.logger)
 	if err != nil {
 		_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: split message error", "error", err.Error())
 		return []error{err}
@@ -316,6 +361,35 @@ func (n *Notifier) sendToChatBot(ctx context.Context, d *config.DingTalk, data t
 	return group.Wait()
 }
 
+// Generate the users and mobiles to be mentioned in the chatbot message, nil means no one is mentioned.
+func (n *Notifier) mention(d *config.DingTalk, data template.Data) (*dingtalkAt, error) {
+
+	if len(d.MentionedUsers) == 0 && len(d.MentionedMobiles) == 0 {
+		return nil, nil
+	}
+
+	users, err := n.template.RenderList(d.MentionedUsers, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: generate mentioned users error", "error", err.Error())
+		return nil, err
+	}
+
+	mobiles, err := n.template.RenderList(d.MentionedMobiles, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: generate mentioned mobiles error", "error", err.Error())
+		return nil, err
+	}
+
+	if len(users) == 0 && len(mobiles) == 0 {
+		return nil, nil
+	}
+
+	return &dingtalkAt{
+		AtMobiles: mobiles,
+		AtUserIds: users,
+	}, nil
+}
+
 func (n *Notifier) sendToConversation(ctx context.Context, d *config.DingTalk, data template.Data) []error {
 
 	appkey, err := n.notifierCfg.GetSecretData(d.GetNamespace(), d.DingTalkConfig.Conversation.AppKey)
@@ -378,6 +452,10 @@ func (n *Notifier) sendToConversation(ctx context.Context, d *config.DingTalk, d
 		}
 		request.Header.Set("Content-Type", "application/json")
 
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
 		body, err := notifier.DoHttpRequest(context.Background(), nil, request)
 		if err != nil {
 			_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: do http error", "error", err)
@@ -400,7 +478,7 @@ func (n *Notifier) sendToConversation(ctx context.Context, d *config.DingTalk, d
 		return nil
 	}
 
-	messages, err := n.template.Split(data, n.conversationMessageMaxSize, n.templateName, n.logger)
+	messages, err := n.template.SplitWithDecoration(data, n.conversationMessageMaxSize, n.templateName, n.decoration, n.logger)
 	if err != nil {
 		_ = level.Error(n.logger).Log("msg", "DingTalkNotifier: split message error", "error", err.Error())
 		return nil
@@ -467,13 +545,15 @@ func (n *Notifier) getToken(ctx context.Context, appkey, appsecret string) (stri
 	return n.ats.GetToken(ctx, appkey+" | "+appsecret, get)
 }
 
-func calcSign(secret string) (string, string) {
+// Calculate the signature of the chatbot, sign = base64(hmacSHA256(timestamp + "\n" + secret)), the timestamp
+// is in milliseconds. The signature is not escaped, it will be escaped when it is added to the url.
+func calcSign(secret string, t time.Time) (string, string) {
 
-	timestamp := fmt.Sprintf("%d", time.Now().Unix()*1000)
+	timestamp := fmt.Sprintf("%d", t.UnixNano()/int64(time.Millisecond))
 	msg := fmt.Sprintf("%s\n%s", timestamp, secret)
 	h := hmac.New(sha256.New, []byte(secret))
 	h.Write([]byte(msg))
 	sign := base64.StdEncoding.EncodeToString(h.Sum(nil))
 
-	return timestamp, url.QueryEscape(sign)
+	return timestamp, sign
 }
@@ -0,0 +1,233 @@
+package dingtalk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A stub of the DingTalk chatbot, it records the requests received.
+type chatbotServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	messages []dingtalkMessage
+}
+
+func newChatbotServer(t *testing.T) *chatbotServer {
+
+	s := &chatbotServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg dingtalkMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		s.messages = append(s.messages, msg)
+		s.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+
+	return s
+}
+
+func (s *chatbotServer) sent() ([]*http.Request, []dingtalkMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...), append([]dingtalkMessage(nil), s.messages...)
+}
+
+// Create a chatbot receiver sending to the webhook, the webhook is read from the secret.
+func newChatbotReceiver(t *testing.T, webhook string) *config.DingTalk {
+
+	secret := secrets.NewSecret(t, webhook)
+
+	d := config.NewDingTalkReceiver().(*config.DingTalk)
+	d.SetNamespace(testNamespace)
+	d.DingTalkConfig = &config.DingTalkConfig{
+		ChatBot: &config.DingTalkChatBot{Webhook: secret},
+	}
+
+	return d
+}
+
+func newNotifier(t *testing.T, receivers ...*config.DingTalk) *Notifier {
+
+	c := testutil.NewConfig(secrets, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewDingTalkNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(alerts ...template.Alert) template.Data {
+	return template.Data{Receiver: "test", Status: "firing", Alerts: alerts}
+}
+
+func TestCalcSign(t *testing.T) {
+
+	timestamp, sign := calcSign("SEC1234567890", time.Unix(1600000000, 0))
+
+	if timestamp != "1600000000000" {
+		t.Errorf("expected the timestamp in milliseconds, got %s", timestamp)
+	}
+
+	if want := "ArMc2aNRCbmatW1aZ8LYMu14F+D1Wpt6oCc68GxXngs="; sign != want {
+		t.Errorf("expected sign %s, got %s", want, sign)
+	}
+}
+
+func TestSendToChatBotSigned(t *testing.T) {
+
+	s := newChatbotServer(t)
+	defer s.Close()
+
+	d := newChatbotReceiver(t, s.URL+"/robot/send?access_token=token")
+	d.DingTalkConfig.ChatBot.Secret = secrets.NewSecret(t, "SEC1234567890")
+	n := newNotifier(t, d)
+
+	before := time.Now()
+	if errs := n.Notify(context.Background(), newData(template.Alert{Status: "firing", Labels: template.KV{"alertname": "a1"}})); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests, _ := s.sent()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	q := requests[0].URL.Query()
+	if q.Get("access_token") != "token" {
+		t.Errorf("expected the access token kept, got %s", q.Get("access_token"))
+	}
+
+	// The receiver can recompute the signature from the timestamp.
+	timestamp := q.Get("timestamp")
+	h := hmac.New(sha256.New, []byte("SEC1234567890"))
+	_, _ = h.Write([]byte(timestamp + "\nSEC1234567890"))
+	if want := base64.StdEncoding.EncodeToString(h.Sum(nil)); q.Get("sign") != want {
+		t.Errorf("expected sign %s, got %s", want, q.Get("sign"))
+	}
+
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("parse timestamp error, %s", err)
+	}
+	if at := time.Unix(0, ms*int64(time.Millisecond)); at.Before(before.Truncate(time.Millisecond)) || at.After(time.Now()) {
+		t.Errorf("expected the timestamp generated when sending, got %s", at)
+	}
+}
+
+func TestSendToChatBotUnsigned(t *testing.T) {
+
+	s := newChatbotServer(t)
+	defer s.Close()
+
+	n := newNotifier(t, newChatbotReceiver(t, s.URL+"/robot/send?access_token=token"))
+	if errs := n.Notify(context.Background(), newData(template.Alert{Status: "firing", Labels: template.KV{"alertname": "a1"}})); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests, _ := s.sent()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	if q := requests[0].URL.Query(); len(q.Get("timestamp")) > 0 || len(q.Get("sign")) > 0 {
+		t.Errorf("expected the message not signed without the secret, got %s", requests[0].URL.RawQuery)
+	}
+}
+
+func TestSendToChatBotMention(t *testing.T) {
+
+	s := newChatbotServer(t)
+	defer s.Close()
+
+	d := newChatbotReceiver(t, s.URL+"/robot/send?access_token=token")
+	d.MentionedUsers = []string{`{{ range .Alerts }}{{ .Labels.owner }},{{ end }}`, "admin"}
+	d.MentionedMobiles = []string{"13800000000"}
+	n := newNotifier(t, d)
+
+	data := newData(
+		template.Alert{Status: "firing", Labels: template.KV{"alertname": "a1", "owner": "alice"}},
+		template.Alert{Status: "firing", Labels: template.KV{"alertname": "a2", "owner": "bob"}},
+	)
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	_, messages := s.sent()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+
+	m := messages[0]
+	if m.At == nil {
+		t.Fatal("expected the at object in the message")
+	}
+
+	if want := []string{"alice", "bob", "admin"}; !reflect.DeepEqual(m.At.AtUserIds, want) {
+		t.Errorf("expected users %v, got %v", want, m.At.AtUserIds)
+	}
+
+	if want := []string{"13800000000"}; !reflect.DeepEqual(m.At.AtMobiles, want) {
+		t.Errorf("expected mobiles %v, got %v", want, m.At.AtMobiles)
+	}
+
+	// The mentioned users must be in the content to be notified.
+	for _, s := range []string{"@13800000000", "@alice", "@bob", "@admin"} {
+		if !strings.Contains(m.Text.Content, s) {
+			t.Errorf("expected %s in the content %q", s, m.Text.Content)
+		}
+	}
+}
+
+func TestSendToChatBotNoMention(t *testing.T) {
+
+	s := newChatbotServer(t)
+	defer s.Close()
+
+	// The mentions rendered empty are dropped.
+	d := newChatbotReceiver(t, s.URL+"/robot/send?access_token=token")
+	d.MentionedUsers = []string{`{{ .CommonLabels.owner }}`}
+	n := newNotifier(t, d)
+
+	if errs := n.Notify(context.Background(), newData(template.Alert{Status: "firing", Labels: template.KV{"alertname": "a1"}})); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	_, messages := s.sent()
+	if len(messages) != 1 || messages[0].At != nil {
+		t.Errorf("expected 1 message without the at object, got %+v", messages)
+	}
+}
@@ -0,0 +1,386 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The limits of the message, see https://discord.com/developers/docs/resources/channel#embed-limits.
+	ContentMaxSize     = 2000
+	EmbedsMaxSize      = 10
+	FieldsMaxSize      = 25
+	TitleMaxSize       = 256
+	DescriptionMaxSize = 4096
+	FieldNameMaxSize   = 256
+	FieldValueMaxSize  = 1024
+	ColorResolved      = 0x2DC72D
+	ColorCritical      = 0xE6522C
+	ColorWarning       = 0xF5A623
+	ColorInfo          = 0x3498DB
+	ColorDefault       = 0x95A5A6
+	alertNameLabel     = "alertname"
+	severityLabel      = "severity"
+	statusResolved     = "resolved"
+)
+
+// The annotations used as the description of the embed in order.
+var descriptionAnnotations = []string{"message", "summary", "description"}
+
+type Notifier struct {
+	notifierCfg  *config.Config
+	discord      map[string]*config.Discord
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+	decoration   *notifier.Decoration
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type discordImage struct {
+	URL string `json:"url"`
+}
+
+type discordEmbed struct {
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	URL         string          `json:"url,omitempty"`
+	Color       int             `json:"color,omitempty"`
+	Timestamp   string          `json:"timestamp,omitempty"`
+	Fields      []*discordField `json:"fields,omitempty"`
+	Image       *discordImage   `json:"image,omitempty"`
+}
+
+type discordMessage struct {
+	Content string          `json:"content,omitempty"`
+	Embeds  []*discordEmbed `json:"embeds,omitempty"`
+}
+
+func NewDiscordNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		discord:      make(map[string]*config.Discord),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+		decoration:   &notifier.Decoration{Header: header, Footer: footer},
+	}
+
+	if opts != nil && opts.Discord != nil {
+
+		if opts.Discord.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Discord.NotificationTimeout)
+		}
+
+		if len(opts.Discord.Template) > 0 {
+			n.templateName = opts.Discord.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.Discord.Header) > 0 {
+			n.decoration.Header = opts.Discord.Header
+		}
+
+		if len(opts.Discord.Footer) > 0 {
+			n.decoration.Footer = opts.Discord.Footer
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Discord)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "DiscordNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which use the same webhook, message type and image url template only need to be sent once.
+		key, err := notifier.Md5key(struct {
+			Type             string
+			ImageURLTemplate string
+			Config           *config.DiscordConfig
+		}{receiver.Type, receiver.ImageURLTemplate, receiver.DiscordConfig})
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "DiscordNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.discord[key] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	send := func(d *config.Discord, msg *discordMessage) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "DiscordNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(msg); err != nil {
+			_ = level.Error(n.logger).Log("msg", "DiscordNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		webhook, err := n.notifierCfg.GetSecretData(d.GetNamespace(), d.DiscordConfig.Webhook)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "DiscordNotifier: get webhook secret", "error", err.Error())
+			return err
+		}
+
+		// Wait for the message to be created, so the errors can be returned.
+		u, err := notifier.UrlWithParameters(webhook, map[string]string{"wait": "true"})
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "DiscordNotifier: set parameters error", "error", err)
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, u, &buf)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		_, err = notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "DiscordNotifier: do http error", "error", err)
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "DiscordNotifier: send message", "webhook", d.DiscordConfig.Webhook.Name)
+
+		return nil
+	}
+
+	// Messages of each message type.
+	messages := make(map[string][]*discordMessage)
+	for _, d := range n.discord {
+		if _, ok := messages[d.Type]; ok {
+			continue
+		}
+
+		var msgs []*discordMessage
+		if d.Type == config.DiscordEmbed {
+			msgs = n.embedMessages(data)
+		} else {
+			contents, err := n.template.SplitWithDecoration(data, ContentMaxSize, n.templateName, n.decoration, n.logger)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "DiscordNotifier: split message error", "error", err.Error())
+				return []error{err}
+			}
+
+			for _, content := range contents {
+				msgs = append(msgs, &discordMessage{Content: content})
+			}
+		}
+
+		messages[d.Type] = msgs
+	}
+
+	group := async.NewGroup(ctx)
+	for _, discord := range n.discord {
+		d := discord
+		for _, m := range n.withImages(d, messages[d.Type], data) {
+			msg := m
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(d, msg)
+			})
+		}
+	}
+
+	return group.Wait()
+}
+
+// Generate an embed for each alert, the embeds will be split into multiple messages
+// if the number of embeds is greater than the limit.
+func (n *Notifier) embedMessages(data template.Data) []*discordMessage {
+
+	var embeds []*discordEmbed
+	for _, alert := range data.Alerts {
+		embed := &discordEmbed{
+			Title: truncate(fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Status), alert.Labels[alertNameLabel]), TitleMaxSize),
+			URL:   alert.GeneratorURL,
+			Color: color(alert),
+		}
+
+		for _, name := range descriptionAnnotations {
+			if v := alert.Annotations[name]; len(v) > 0 {
+				embed.Description = truncate(v, DescriptionMaxSize)
+				break
+			}
+		}
+
+		if !alert.StartsAt.IsZero() {
+			embed.Timestamp = alert.StartsAt.Format(time.RFC3339)
+		}
+
+		for _, pair := range alert.Labels.SortedPairs() {
+			if pair.Name == alertNameLabel {
+				continue
+			}
+
+			if len(embed.Fields) >= FieldsMaxSize {
+				break
+			}
+
+			embed.Fields = append(embed.Fields, &discordField{
+				Name:   truncate(pair.Name, FieldNameMaxSize),
+				Value:  truncate(pair.Value, FieldValueMaxSize),
+				Inline: true,
+			})
+		}
+
+		embeds = append(embeds, embed)
+	}
+
+	var messages []*discordMessage
+	for i := 0; i < len(embeds); i += EmbedsMaxSize {
+		end := i + EmbedsMaxSize
+		if end > len(embeds) {
+			end = len(embeds)
+		}
+
+		messages = append(messages, &discordMessage{Embeds: embeds[i:end]})
+	}
+
+	return messages
+}
+
+// Attach the images to the messages, the messages are not changed if the image url template is not set.
+// The image of the embed is generated from the alert of the embed, and the image of the content is attached
+// to the first message as an embed.
+func (n *Notifier) withImages(d *config.Discord, msgs []*discordMessage, data template.Data) []*discordMessage {
+
+	if len(d.ImageURLTemplate) == 0 || len(msgs) == 0 {
+		return msgs
+	}
+
+	var token string
+	if d.DiscordConfig.ImageRenderToken != nil {
+		t, err := n.notifierCfg.GetSecretData(d.GetNamespace(), d.DiscordConfig.ImageRenderToken)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "DiscordNotifier: get image render token secret", "error", err.Error())
+			return msgs
+		}
+		token = t
+	}
+
+	imageURL := func(data template.Data) string {
+		u, err := n.template.ImageURL(d.ImageURLTemplate, data, token, d.DiscordConfig.ImageURLExpires, n.logger)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "DiscordNotifier: generate image url error", "error", err.Error())
+			return ""
+		}
+		return u
+	}
+
+	// The messages are shared by the receivers, so they are copied before changed.
+	var res []*discordMessage
+	if d.Type == config.DiscordEmbed {
+		i := 0
+		for _, m := range msgs {
+			msg := &discordMessage{Content: m.Content}
+			for _, e := range m.Embeds {
+				embed := *e
+				if i < len(data.Alerts) {
+					alertData := data
+					alertData.Alerts = template.Alerts{data.Alerts[i]}
+					if u := imageURL(alertData); len(u) > 0 {
+						embed.Image = &discordImage{URL: u}
+					}
+				}
+				msg.Embeds = append(msg.Embeds, &embed)
+				i++
+			}
+			res = append(res, msg)
+		}
+		return res
+	}
+
+	u := imageURL(data)
+	if len(u) == 0 {
+		return msgs
+	}
+
+	res = append(res, &discordMessage{
+		Content: msgs[0].Content,
+		Embeds:  []*discordEmbed{{Image: &discordImage{URL: u}}},
+	})
+	return append(res, msgs[1:]...)
+}
+
+// The color of the embed is decided by the status and severity of the alert.
+func color(alert template.Alert) int {
+
+	if alert.Status == statusResolved {
+		return ColorResolved
+	}
+
+	switch strings.ToLower(alert.Labels[severityLabel]) {
+	case "critical", "error":
+		return ColorCritical
+	case "warning":
+		return ColorWarning
+	case "info":
+		return ColorInfo
+	default:
+		return ColorDefault
+	}
+}
+
+func truncate(s string, size int) string {
+
+	rs := []rune(s)
+	if len(rs) <= size {
+		return s
+	}
+
+	return string(rs[:size-3]) + "..."
+}
@@ -0,0 +1,459 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A stub of the discord webhook, it records the messages and the queries received.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	messages []discordMessage
+	queries  []string
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.queries = append(s.queries, r.URL.RawQuery)
+		s.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	return s
+}
+
+func (s *webhookServer) sent() []discordMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]discordMessage(nil), s.messages...)
+}
+
+// Create a receiver sending to the webhook, the webhook is read from the secret.
+func newReceiver(t *testing.T, webhook, msgType string) *config.Discord {
+
+	d := config.NewDiscordReceiver().(*config.Discord)
+	d.SetNamespace(testNamespace)
+	d.Type = msgType
+	d.DiscordConfig = &config.DiscordConfig{
+		Webhook: secrets.NewSecret(t, webhook),
+	}
+
+	return d
+}
+
+func newNotifier(t *testing.T, receivers ...*config.Discord) *Notifier {
+
+	c := testutil.NewConfig(secrets, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewDiscordNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(status string, n int) template.Data {
+
+	data := template.Data{Receiver: "test", Status: status}
+	for i := 0; i < n; i++ {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status: status,
+			Labels: template.KV{"alertname": fmt.Sprintf("alert%d", i+1)},
+		})
+	}
+
+	return data
+}
+
+func TestColor(t *testing.T) {
+
+	tests := []struct {
+		status   string
+		severity string
+		expected int
+	}{
+		{"firing", "critical", ColorCritical},
+		{"firing", "Error", ColorCritical},
+		{"firing", "warning", ColorWarning},
+		{"firing", "info", ColorInfo},
+		{"firing", "", ColorDefault},
+		{"firing", "unknown", ColorDefault},
+		// The resolved alerts have the same color whatever the severity is.
+		{"resolved", "critical", ColorResolved},
+	}
+
+	for _, test := range tests {
+		alert := template.Alert{Status: test.status, Labels: template.KV{"severity": test.severity}}
+		if got := color(alert); got != test.expected {
+			t.Errorf("%s %s: expected %x, got %x", test.status, test.severity, test.expected, got)
+		}
+	}
+}
+
+func TestNotifyEmbed(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	alert := template.Alert{
+		Status: "firing",
+		Labels: template.KV{
+			"alertname": "alert1",
+			"severity":  "warning",
+			"namespace": "default",
+			"pod":       strings.Repeat("p", FieldValueMaxSize+10),
+		},
+		Annotations:  template.KV{"summary": "cpu is high", "description": "the description"},
+		StartsAt:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		GeneratorURL: "https://prometheus.test/graph",
+	}
+
+	n := newNotifier(t, newReceiver(t, s.URL, config.DiscordEmbed))
+	if errs := n.Notify(context.Background(), template.Data{Receiver: "test", Status: "firing", Alerts: template.Alerts{alert}}); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 || len(msgs[0].Embeds) != 1 {
+		t.Fatalf("expected 1 message with 1 embed, got %v", msgs)
+	}
+
+	// The message is created synchronously, so the errors are returned.
+	s.mu.Lock()
+	query := s.queries[0]
+	s.mu.Unlock()
+	if query != "wait=true" {
+		t.Errorf("expected waiting for the message created, got %s", query)
+	}
+
+	e := msgs[0].Embeds[0]
+	if e.Title != "[FIRING] alert1" || e.URL != alert.GeneratorURL || e.Color != ColorWarning || e.Timestamp != "2021-01-01T00:00:00Z" {
+		t.Errorf("expected the embed of the alert, got %s %s %x %s", e.Title, e.URL, e.Color, e.Timestamp)
+	}
+
+	// The summary is preferred to the description.
+	if e.Description != "cpu is high" {
+		t.Errorf("expected the description from the summary, got %q", e.Description)
+	}
+
+	// The labels except the alert name are the inline fields sorted by the name, the long value is truncated.
+	var names []string
+	for _, f := range e.Fields {
+		names = append(names, f.Name)
+		if !f.Inline {
+			t.Errorf("%s: expected the inline field", f.Name)
+		}
+	}
+	if expected := []string{"namespace", "pod", "severity"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the fields %v, got %v", expected, names)
+	}
+	if v := e.Fields[1].Value; len(v) != FieldValueMaxSize || !strings.HasSuffix(v, "...") {
+		t.Errorf("expected the value truncated to %d, got %d", FieldValueMaxSize, len(v))
+	}
+}
+
+func TestNotifyEmbedFieldsLimit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	labels := template.KV{"alertname": "alert1"}
+	for i := 0; i < FieldsMaxSize+5; i++ {
+		labels[fmt.Sprintf("label%02d", i)] = "value"
+	}
+
+	n := newNotifier(t, newReceiver(t, s.URL, config.DiscordEmbed))
+	data := template.Data{Receiver: "test", Status: "firing", Alerts: template.Alerts{{Status: "firing", Labels: labels}}}
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 || len(msgs[0].Embeds) != 1 {
+		t.Fatalf("expected 1 message with 1 embed, got %v", msgs)
+	}
+
+	if fields := msgs[0].Embeds[0].Fields; len(fields) != FieldsMaxSize {
+		t.Errorf("expected %d fields, got %d", FieldsMaxSize, len(fields))
+	}
+}
+
+func TestNotifyEmbedSplit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The embeds are split into the messages of at most 10 embeds.
+	alerts := EmbedsMaxSize*2 + 5
+
+	n := newNotifier(t, newReceiver(t, s.URL, config.DiscordEmbed))
+	if errs := n.Notify(context.Background(), newData("firing", alerts)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var sizes []int
+	titles := make(map[string]bool)
+	for _, msg := range s.sent() {
+		sizes = append(sizes, len(msg.Embeds))
+		for _, e := range msg.Embeds {
+			titles[e.Title] = true
+		}
+	}
+	sort.Ints(sizes)
+
+	if expected := []int{5, EmbedsMaxSize, EmbedsMaxSize}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("expected the embeds %v, got %v", expected, sizes)
+	}
+	if len(titles) != alerts {
+		t.Errorf("expected an embed for each alert, got %d", len(titles))
+	}
+}
+
+func TestNotifyContentSplit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	n := ContentMaxSize / 10
+
+	d := newNotifier(t, newReceiver(t, s.URL, config.DiscordContent))
+	if errs := d.Notify(context.Background(), newData("firing", n)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) < 2 {
+		t.Fatalf("expected the content split, got %d", len(msgs))
+	}
+
+	// The content of each message is within the limit, and the alerts are not lost.
+	alerts := make(map[string]bool)
+	for i, msg := range msgs {
+		if l := len([]rune(msg.Content)); l > ContentMaxSize {
+			t.Errorf("message %d: expected the content at most %d, got %d", i, ContentMaxSize, l)
+		}
+		if len(msg.Embeds) != 0 {
+			t.Errorf("message %d: expected no embeds, got %d", i, len(msg.Embeds))
+		}
+		for _, line := range strings.Split(msg.Content, "\n") {
+			if line = strings.TrimSpace(line); len(line) > 0 {
+				alerts[line] = true
+			}
+		}
+	}
+
+	if len(alerts) != n {
+		t.Errorf("expected %d alerts sent, got %d", n, len(alerts))
+	}
+}
+
+const imageTemplate = `{{ with .CommonLabels.panel }}https://grafana.test/render/d-solo/nm?panelId={{ . }}{{ end }}`
+
+func imageURL(panel string) string {
+	return "https://grafana.test/render/d-solo/nm?panelId=" + panel
+}
+
+// Set the panel labels of the alerts in order, the alert has no panel label if the panel is empty.
+func withPanels(data template.Data, panels ...string) template.Data {
+
+	for i, panel := range panels {
+		if i < len(data.Alerts) && len(panel) > 0 {
+			data.Alerts[i].Labels["panel"] = panel
+		}
+	}
+
+	return data
+}
+
+// Create the receiver attaching the images generated by the template.
+func newImageReceiver(t *testing.T, webhook, msgType, imageURLTemplate string) *config.Discord {
+
+	d := newReceiver(t, webhook, msgType)
+	d.ImageURLTemplate = imageURLTemplate
+	return d
+}
+
+func TestNotifyContentImage(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		template string
+		panels   []string
+		image    string
+	}{
+		{"not set", "", []string{"2"}, ""},
+		{"render url", imageTemplate, []string{"2", "2"}, imageURL("2")},
+		// The panel is not a common label, so the template renders empty and no image is attached.
+		{"render empty", imageTemplate, []string{"2", "3"}, ""},
+	}
+
+	for _, test := range tests {
+		s := newWebhookServer(t)
+
+		n := newNotifier(t, newImageReceiver(t, s.URL, config.DiscordContent, test.template))
+		data := withPanels(newData("firing", len(test.panels)), test.panels...)
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", test.name, errs)
+		}
+
+		msgs := s.sent()
+		s.Close()
+		if len(msgs) != 1 {
+			t.Errorf("%s: expected 1 message, got %d", test.name, len(msgs))
+			continue
+		}
+
+		if len(msgs[0].Content) == 0 {
+			t.Errorf("%s: expected the content sent", test.name)
+		}
+
+		if len(test.image) == 0 {
+			if len(msgs[0].Embeds) != 0 {
+				t.Errorf("%s: expected no image, got %v", test.name, msgs[0].Embeds)
+			}
+			continue
+		}
+
+		if len(msgs[0].Embeds) != 1 || msgs[0].Embeds[0].Image == nil || msgs[0].Embeds[0].Image.URL != test.image {
+			t.Errorf("%s: expected the image %s embedded, got %v", test.name, test.image, msgs[0].Embeds)
+		}
+	}
+}
+
+func TestNotifyContentImageSplit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The content is split, the image is only attached to the first part.
+	alerts := ContentMaxSize / 10
+	var panels []string
+	for i := 0; i < alerts; i++ {
+		panels = append(panels, "2")
+	}
+
+	n := newNotifier(t, newImageReceiver(t, s.URL, config.DiscordContent, imageTemplate))
+	if errs := n.Notify(context.Background(), withPanels(newData("firing", alerts), panels...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) < 2 {
+		t.Fatalf("expected the message split, got %d", len(msgs))
+	}
+
+	var attached []string
+	for _, msg := range msgs {
+		for _, e := range msg.Embeds {
+			if e.Image == nil {
+				continue
+			}
+			attached = append(attached, e.Image.URL)
+			if !strings.Contains(msg.Content, "alert1\n") {
+				t.Errorf("expected the image attached to the first part, got %q", msg.Content)
+			}
+		}
+	}
+
+	if len(attached) != 1 || attached[0] != imageURL("2") {
+		t.Errorf("expected the image attached once, got %v", attached)
+	}
+}
+
+func TestNotifyEmbedImage(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The image of each embed is generated from the alert of the embed.
+	n := newNotifier(t, newImageReceiver(t, s.URL, config.DiscordEmbed, imageTemplate))
+	if errs := n.Notify(context.Background(), withPanels(newData("firing", 3), "2", "", "3")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	var urls []string
+	for _, e := range msgs[0].Embeds {
+		u := ""
+		if e.Image != nil {
+			u = e.Image.URL
+		}
+		urls = append(urls, u)
+	}
+
+	if expected := []string{imageURL("2"), "", imageURL("3")}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected the images %v, got %v", expected, urls)
+	}
+}
+
+func TestNotifyImageShared(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The messages are shared by the receivers of the same type, the image of one receiver is not sent to the other.
+	n := newNotifier(t,
+		newImageReceiver(t, s.URL, config.DiscordEmbed, imageTemplate),
+		newReceiver(t, s.URL, config.DiscordEmbed))
+	if errs := n.Notify(context.Background(), withPanels(newData("firing", 1), "2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	var urls []string
+	for _, msg := range msgs {
+		u := ""
+		if len(msg.Embeds) == 1 && msg.Embeds[0].Image != nil {
+			u = msg.Embeds[0].Image.URL
+		}
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	if expected := []string{"", imageURL("2")}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected the images %v, got %v", expected, urls)
+	}
+}
@@ -43,17 +43,18 @@ type Notifier struct {
 	maxEmailReceivers int
 }
 
-func NewEmailNotifier(logger log.Logger, receivers []nmconfig.Receiver, notifierCfg *nmconfig.Config) notifier.Notifier {
+func NewEmailNotifier(logger log.Logger, receivers []nmconfig.Receiver, notifierCfg *nmconfig.Config) (notifier.Notifier, error) {
 
 	var path []string
+	var externalURL string
 	opts := notifierCfg.ReceiverOpts
 	if opts != nil && opts.Global != nil {
 		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
 	}
-	tmpl, err := notifier.NewTemplate(path)
+	tmpl, err := notifier.NewTemplate(path, externalURL)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "EmailNotifier: get template error", "error", err.Error())
-		return nil
+		return nil, fmt.Errorf("get template error, %s", err.Error())
 	}
 
 	n := &Notifier{
@@ -98,8 +99,8 @@ func NewEmailNotifier(logger log.Logger, receivers []nmconfig.Receiver, notifier
 			continue
 		}
 
-		if receiver.EmailConfig == nil {
-			_ = level.Warn(logger).Log("msg", "EmailNotifier: ignore receiver because of empty config")
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "EmailNotifier: ignore invalid receiver", "error", err.Error())
 			continue
 		}
 
@@ -115,6 +116,7 @@ func NewEmailNotifier(logger log.Logger, receivers []nmconfig.Receiver, notifier
 			if !ok {
 				e = nmconfig.NewEmail(nil)
 				_ = e.SetConfig(c)
+				e.SetName(receiver.GetName())
 				e.SetNamespace(receiver.GetNamespace())
 			}
 
@@ -129,12 +131,13 @@ func NewEmailNotifier(logger log.Logger, receivers []nmconfig.Receiver, notifier
 
 			e := nmconfig.NewEmail(receiver.To)
 			_ = e.SetConfig(n.clone(receiver.EmailConfig))
+			e.SetName(receiver.GetName())
 			e.SetNamespace(receiver.GetNamespace())
 			n.email[key] = e
 		}
 	}
 
-	return n
+	return n, nil
 }
 
 func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
@@ -167,7 +170,11 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 		emailConfig.To = to
 		emailConfig.HTML = n.templateName
 		emailConfig.Headers["Subject"] = n.subjectTemplateName
-		sender := email.New(emailConfig, n.template.Tmpl, n.logger)
+		sender := email.New(emailConfig, n.template.Get(), n.logger)
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
 		ctx = notify.WithGroupLabels(ctx, notifier.KvToLabelSet(data.GroupLabels))
@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// SendError is the error of sending notification, it records the receiver and the target which the error belongs to.
+type SendError struct {
+	// The type of the notifier, such as wechat.
+	NotifierType string
+	// The key to identify the receiver, such as `CorpID | AgentID` of wechat.
+	Receiver string
+	// The target which the notification is sent to, such as the batch of users of wechat.
+	Target string
+	Err    error
+}
+
+func NewSendError(notifierType, receiver, target string, err error) *SendError {
+	return &SendError{
+		NotifierType: notifierType,
+		Receiver:     receiver,
+		Target:       target,
+		Err:          err,
+	}
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("%s notifier send to %s [%s] error, %s", e.NotifierType, e.Receiver, e.Target, e.Err)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError means the notification is only delivered to part of the recipients.
+type PartialError struct {
+	// The number of recipients the notification is delivered to.
+	Delivered int
+	Total     int
+	// The recipients the notification is not delivered to.
+	Invalid string
+}
+
+func NewPartialError(delivered, total int, invalid string) *PartialError {
+	return &PartialError{
+		Delivered: delivered,
+		Total:     total,
+		Invalid:   invalid,
+	}
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d of %d recipients delivered, invalid recipients: %s", e.Delivered, e.Total, e.Invalid)
+}
+
+// HttpError is the error returned when the response status code is not 2xx.
+type HttpError struct {
+	StatusCode int
+	Message    string
+	// The time to wait before retrying, parsed from the `Retry-After` header.
+	RetryAfter time.Duration
+}
+
+func (e *HttpError) Error() string {
+	return fmt.Sprintf("http error, code: %d, message: %s", e.StatusCode, e.Message)
+}
+
+// RetryAfter returns the time to wait before retrying indicated by the error,
+// it returns 0 if the error is not caused by a `Retry-After` response.
+func RetryAfter(err error) time.Duration {
+
+	var he *HttpError
+	if errors.As(err, &he) {
+		return he.RetryAfter
+	}
+
+	return 0
+}
+
+// RetryableError marks the error as temporary, the operation failed with it can be retried.
+type RetryableError struct {
+	Err error
+}
+
+func NewRetryableError(err error) *RetryableError {
+	return &RetryableError{Err: err}
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable checks whether the operation failed with the error can be retried, the network errors, the server errors,
+// the rate limit and the errors marked as retryable can be retried, others such as the invalid credentials can not.
+func IsRetryable(err error) bool {
+
+	var re *RetryableError
+	if errors.As(err, &re) {
+		return true
+	}
+
+	var he *HttpError
+	if errors.As(err, &he) {
+		return he.StatusCode >= http.StatusInternalServerError || he.StatusCode == http.StatusTooManyRequests
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// ThrottledError means the send is rejected by the local rate limiter or the send budget before it is made,
+// it is not a failure of the remote service.
+type ThrottledError struct {
+	Err error
+}
+
+func NewThrottledError(err error) *ThrottledError {
+	return &ThrottledError{Err: err}
+}
+
+func (e *ThrottledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// IsThrottled checks whether the send is rejected locally, such errors should not be counted against the remote service.
+func IsThrottled(err error) bool {
+
+	var te *ThrottledError
+	return errors.As(err, &te)
+}
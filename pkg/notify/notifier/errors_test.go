@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestSendError(t *testing.T) {
+
+	cause := errors.New("invalid userid")
+	err := fmt.Errorf("send error, %w", NewSendError("wechat", "corp | 1000002", "toUser: user1", cause))
+
+	var se *SendError
+	if !errors.As(err, &se) {
+		t.Fatal("expected the send error")
+	}
+
+	if se.NotifierType != "wechat" || se.Receiver != "corp | 1000002" || se.Target != "toUser: user1" {
+		t.Errorf("unexpected send error %+v", se)
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("expected the send error to wrap the cause")
+	}
+
+	for _, s := range []string{"wechat", "corp | 1000002", "toUser: user1", "invalid userid"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected %q in the error message %s", s, err.Error())
+		}
+	}
+}
+
+func TestPartialError(t *testing.T) {
+
+	err := NewSendError("wechat", "corp | 1000002", "toUser: u1|u2", NewPartialError(1, 2, "user: u2"))
+
+	var pe *PartialError
+	if !errors.As(err, &pe) || pe.Delivered != 1 || pe.Total != 2 {
+		t.Fatalf("expected the partial error wrapped, got %v", err)
+	}
+
+	if msg := pe.Error(); msg != "1 of 2 recipients delivered, invalid recipients: user: u2" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("invalid secret"), false},
+		{"retryable", NewRetryableError(errors.New("system busy")), true},
+		{"wrapped retryable", fmt.Errorf("get token error, %w", NewRetryableError(errors.New("system busy"))), true},
+		{"server error", &HttpError{StatusCode: 503}, true},
+		{"rate limited", &HttpError{StatusCode: 429}, true},
+		{"client error", &HttpError{StatusCode: 403}, false},
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"send error", NewSendError("wechat", "corp", "toUser: u1", &HttpError{StatusCode: 502}), true},
+	}
+
+	for _, test := range tests {
+		if got := IsRetryable(test.err); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
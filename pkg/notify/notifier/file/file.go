@@ -0,0 +1,215 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTemplate   = `{{ template "nm.default.text" . }}`
+	DefaultMaxBackups = 1
+	megabyte          = 1024 * 1024
+)
+
+type Notifier struct {
+	file         map[string]*config.File
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+}
+
+// The notification written to the file, each notification is a line of json.
+type entry struct {
+	Time     time.Time `json:"time"`
+	Receiver string    `json:"receiver"`
+	Status   string    `json:"status"`
+	Message  string    `json:"message"`
+}
+
+// The files opened by the notifiers, the key is the path of file, the stdout uses the empty key.
+// The writing of a file is serialized, because the notifiers may write the same file at the same time.
+var writers = make(map[string]*writer)
+var writersMutex sync.Mutex
+
+type writer struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+}
+
+func NewFileNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		file:         make(map[string]*config.File),
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+	}
+
+	if opts != nil && opts.File != nil {
+		if len(opts.File.Template) > 0 {
+			n.templateName = opts.File.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.File)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "FileNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which write the same file only need to be written once.
+		n.file[receiver.FileConfig.Path] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	msg, err := n.template.TempleText(n.templateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "FileNotifier: generate message error", "error", err.Error())
+		return []error{err}
+	}
+
+	bs, err := json.Marshal(&entry{
+		Time:     time.Now(),
+		Receiver: data.Receiver,
+		Status:   data.Status,
+		Message:  msg,
+	})
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "FileNotifier: encode notification error", "error", err.Error())
+		return []error{err}
+	}
+	bs = append(bs, '\n')
+
+	group := async.NewGroup(ctx)
+	for _, file := range n.file {
+		f := file
+		group.Add(func(stopCh chan interface{}) {
+			if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+				stopCh <- err
+				return
+			}
+
+			if err := getWriter(f.FileConfig).write(bs); err != nil {
+				_ = level.Error(n.logger).Log("msg", "FileNotifier: write notification error", "path", f.FileConfig.Path, "error", err.Error())
+				stopCh <- err
+				return
+			}
+			_ = level.Debug(n.logger).Log("msg", "FileNotifier: write notification", "path", f.FileConfig.Path)
+			stopCh <- nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// Get the writer of the file, the rotation settings of the writer are updated to the latest.
+func getWriter(c *config.FileConfig) *writer {
+
+	writersMutex.Lock()
+	defer writersMutex.Unlock()
+
+	w, ok := writers[c.Path]
+	if !ok {
+		w = &writer{path: c.Path}
+		writers[c.Path] = w
+	}
+
+	w.mutex.Lock()
+	w.maxSize = int64(c.MaxSize) * megabyte
+	w.maxBackups = c.MaxBackups
+	if w.maxBackups == 0 {
+		w.maxBackups = DefaultMaxBackups
+	}
+	w.mutex.Unlock()
+
+	return w
+}
+
+func (w *writer) write(bs []byte) error {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.path) == 0 {
+		_, err := os.Stdout.Write(bs)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+
+	if w.maxSize > 0 {
+		if info, err := os.Stat(w.path); err == nil && info.Size() > 0 && info.Size()+int64(len(bs)) > w.maxSize {
+			if err := w.rotate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(bs)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+
+	return err
+}
+
+// Rotate the file, the file is renamed to `<path>.1`, and the older backups are shifted,
+// the oldest backup is dropped once the number of backups exceeds the maximum.
+func (w *writer) rotate() error {
+
+	for i := w.maxBackups - 1; i > 0; i-- {
+		src := fmt.Sprintf("%s.%d", w.path, i)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+
+		if err := os.Rename(src, fmt.Sprintf("%s.%d", w.path, i+1)); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(w.path, w.path+".1")
+}
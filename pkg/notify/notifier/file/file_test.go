@@ -0,0 +1,209 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newReceiver(path string) *config.File {
+
+	f := config.NewFileReceiver().(*config.File)
+	f.FileConfig = &config.FileConfig{Path: path}
+	return f
+}
+
+func newNotifier(t *testing.T, receivers ...*config.File) *Notifier {
+
+	c := testutil.NewConfig(nil, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewFileNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(receiver string, names ...string) template.Data {
+
+	data := template.Data{Receiver: receiver, Status: "firing"}
+	for _, name := range names {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:   "firing",
+			Labels:   template.KV{"alertname": name},
+			StartsAt: time.Now(),
+		})
+	}
+
+	return data
+}
+
+// Read the notifications written to the file, each line is a notification.
+func readEntries(t *testing.T, path string) []entry {
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s error, %s", path, err)
+	}
+	defer f.Close()
+
+	var entries []entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("decode %q error, %s", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+func tempDir(t *testing.T) string {
+
+	dir, err := ioutil.TempDir("", "file-notifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestNotify(t *testing.T) {
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// The directory of the file is created if it does not exist.
+	path := filepath.Join(dir, "notifications", "alerts.log")
+	n := newNotifier(t, newReceiver(path))
+
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		if errs := n.Notify(context.Background(), newData(fmt.Sprintf("receiver%d", i), fmt.Sprintf("alert%d", i))); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 notifications, got %d", len(entries))
+	}
+
+	for i, e := range entries {
+		if want := fmt.Sprintf("receiver%d", i+1); e.Receiver != want {
+			t.Errorf("notification %d: expected receiver %s, got %s", i, want, e.Receiver)
+		}
+		if e.Status != "firing" {
+			t.Errorf("notification %d: expected status firing, got %s", i, e.Status)
+		}
+		if want := fmt.Sprintf("[firing] alert%d", i+1); strings.TrimSpace(e.Message) != want {
+			t.Errorf("notification %d: expected message %q, got %q", i, want, e.Message)
+		}
+		if e.Time.Before(start.Add(-time.Second)) || e.Time.After(time.Now()) {
+			t.Errorf("notification %d: unexpected time %s", i, e.Time)
+		}
+	}
+}
+
+func TestNotifySamePath(t *testing.T) {
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// The receivers writing the same file are written once.
+	path := filepath.Join(dir, "alerts.log")
+	other := filepath.Join(dir, "other.log")
+	n := newNotifier(t, newReceiver(path), newReceiver(path), newReceiver(other))
+
+	if errs := n.Notify(context.Background(), newData("receiver", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if entries := readEntries(t, path); len(entries) != 1 {
+		t.Errorf("expected 1 notification in %s, got %d", path, len(entries))
+	}
+	if entries := readEntries(t, other); len(entries) != 1 {
+		t.Errorf("expected 1 notification in %s, got %d", other, len(entries))
+	}
+}
+
+func TestWriterRotate(t *testing.T) {
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// Each line is 10 bytes, so the file is rotated after every 3 lines, and 2 backups are kept.
+	path := filepath.Join(dir, "alerts.log")
+	w := &writer{path: path, maxSize: 30, maxBackups: 2}
+	for i := 0; i < 10; i++ {
+		if err := w.write([]byte(fmt.Sprintf("line-%04d\n", i))); err != nil {
+			t.Fatalf("write error, %s", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "line-0009\n",
+		path + ".1": "line-0006\nline-0007\nline-0008\n",
+		path + ".2": "line-0003\nline-0004\nline-0005\n",
+	}
+	for p, content := range want {
+		bs, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read %s error, %s", p, err)
+		}
+		if string(bs) != content {
+			t.Errorf("%s: expected %q, got %q", p, content, string(bs))
+		}
+	}
+
+	// The oldest backup is dropped.
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no more than 2 backups, got %v", err)
+	}
+}
+
+func TestGetWriter(t *testing.T) {
+
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("writer-%d.log", time.Now().UnixNano()))
+
+	// The writer of the same path is shared, and the rotation settings are updated.
+	w := getWriter(&config.FileConfig{Path: path, MaxSize: 1})
+	if w.maxSize != megabyte || w.maxBackups != DefaultMaxBackups {
+		t.Errorf("expected max size %d and max backups %d, got %d and %d", megabyte, DefaultMaxBackups, w.maxSize, w.maxBackups)
+	}
+
+	if getWriter(&config.FileConfig{Path: path, MaxSize: 2, MaxBackups: 3}) != w {
+		t.Fatal("expected the writer shared")
+	}
+	if w.maxSize != 2*megabyte || w.maxBackups != 3 {
+		t.Errorf("expected max size %d and max backups 3, got %d and %d", 2*megabyte, w.maxSize, w.maxBackups)
+	}
+}
+
+func TestNotifyInvalidReceiver(t *testing.T) {
+
+	r := newReceiver("alerts.log")
+	r.FileConfig.MaxSize = -1
+	if n := newNotifier(t, r); len(n.file) != 0 {
+		t.Errorf("expected the invalid receiver ignored, got %d", len(n.file))
+	}
+}
@@ -0,0 +1,349 @@
+package notifier
+
+import (
+	"fmt"
+	json "github.com/json-iterator/go"
+	"github.com/prometheus/alertmanager/template"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+func init() {
+	// The functions must be added before the templates are parsed.
+	template.DefaultFuncs["humanizeDuration"] = humanizeDuration
+	template.DefaultFuncs["since"] = since
+	template.DefaultFuncs["externalURL"] = getExternalURL
+	template.DefaultFuncs["queryEscape"] = queryEscape
+	template.DefaultFuncs["shortURL"] = shortURL
+	template.DefaultFuncs["alertsTable"] = alertsTable
+	template.DefaultFuncs["countBy"] = countBy
+	template.DefaultFuncs["toJson"] = toJson
+	template.DefaultFuncs["limitLabels"] = limitLabels
+}
+
+// Return the external URL without the trailing slash, so that the path can be appended directly.
+func getExternalURL() string {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return strings.TrimRight(templateExternalURL, "/")
+}
+
+// Return the maximum number of labels rendered for each alert by default.
+func getMaxLabels() int {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return templateMaxLabels
+}
+
+// Escape the string so it can be safely placed in a URL query, the space is escaped as `%20`
+// rather than `+` as required by RFC 3986.
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// Format the duration into a compact string like `3m` or `2h15m`, the duration can be
+// a time.Duration, the number of seconds, or a string which can be parsed by time.ParseDuration.
+// Only the two most significant non-zero units are kept, the zero units between them are kept too, such as `1d0h5m`.
+func humanizeDuration(v interface{}) (string, error) {
+
+	d, err := toDuration(v)
+	if err != nil {
+		return "", err
+	}
+
+	return formatDuration(d), nil
+}
+
+// Return the compact string of the time elapsed since the given time. If the value is an alert or
+// a list of alerts, the earliest start time is used.
+func since(v interface{}) (string, error) {
+
+	var t time.Time
+	switch val := v.(type) {
+	case time.Time:
+		t = val
+	case *time.Time:
+		if val != nil {
+			t = *val
+		}
+	case template.Alert:
+		t = val.StartsAt
+	case *template.Alert:
+		if val != nil {
+			t = val.StartsAt
+		}
+	case template.Alerts:
+		t = earliest(val)
+	case []template.Alert:
+		t = earliest(val)
+	default:
+		return "", fmt.Errorf("since: unsupported type %T", v)
+	}
+
+	if t.IsZero() {
+		return "", nil
+	}
+
+	return formatDuration(time.Since(t)), nil
+}
+
+func earliest(alerts []template.Alert) time.Time {
+
+	var t time.Time
+	for _, a := range alerts {
+		if a.StartsAt.IsZero() {
+			continue
+		}
+
+		if t.IsZero() || a.StartsAt.Before(t) {
+			t = a.StartsAt
+		}
+	}
+
+	return t
+}
+
+func toDuration(v interface{}) (time.Duration, error) {
+
+	switch val := v.(type) {
+	case time.Duration:
+		return val, nil
+	case int:
+		return time.Duration(val) * time.Second, nil
+	case int32:
+		return time.Duration(val) * time.Second, nil
+	case int64:
+		return time.Duration(val) * time.Second, nil
+	case float32:
+		return time.Duration(float64(val) * float64(time.Second)), nil
+	case float64:
+		return time.Duration(val * float64(time.Second)), nil
+	case string:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return time.Duration(f * float64(time.Second)), nil
+		}
+		return time.ParseDuration(val)
+	default:
+		return 0, fmt.Errorf("humanizeDuration: unsupported type %T", v)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	if d == 0 {
+		return "0s"
+	}
+
+	if d < time.Second {
+		if d < time.Millisecond {
+			return sign + d.String()
+		}
+		return fmt.Sprintf("%s%dms", sign, d/time.Millisecond)
+	}
+
+	units := []struct {
+		unit string
+		size time.Duration
+	}{
+		{"d", time.Hour * 24},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var parts []string
+	// The number of the parts before the trailing zero units.
+	end, nonZero := 0, 0
+	for _, u := range units {
+		if nonZero == 2 {
+			break
+		}
+
+		n := d / u.size
+		if n == 0 && len(parts) == 0 {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%d%s", n, u.unit))
+		d -= n * u.size
+		if n > 0 {
+			nonZero++
+			end = len(parts)
+		}
+	}
+
+	return sign + strings.Join(parts[:end], "")
+}
+
+// Render the alerts into an aligned markdown table, each column is a label, such as
+// `{{ alertsTable .Alerts "alertname" "namespace" "severity" }}`. All labels of the alerts are used
+// if no label is given. The cell of a missing label is empty.
+func alertsTable(v interface{}, labels ...string) (string, error) {
+
+	var alerts []template.Alert
+	switch val := v.(type) {
+	case template.Alerts:
+		alerts = val
+	case []template.Alert:
+		alerts = val
+	default:
+		return "", fmt.Errorf("alertsTable: unsupported type %T", v)
+	}
+
+	if len(labels) == 0 {
+		set := make(map[string]bool)
+		for _, a := range alerts {
+			for k := range a.Labels {
+				if !set[k] {
+					set[k] = true
+					labels = append(labels, k)
+				}
+			}
+		}
+		sort.Strings(labels)
+	}
+
+	if len(labels) == 0 {
+		return "", nil
+	}
+
+	rows := [][]string{make([]string, len(labels))}
+	for i, l := range labels {
+		rows[0][i] = escapeTableCell(l)
+	}
+	for _, a := range alerts {
+		row := make([]string, len(labels))
+		for i, l := range labels {
+			row[i] = escapeTableCell(a.Labels[l])
+		}
+		rows = append(rows, row)
+	}
+
+	// The width of the separator must be at least 3.
+	widths := make([]int, len(labels))
+	for i := range widths {
+		widths[i] = 3
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	separator := make([]string, len(labels))
+	for i, w := range widths {
+		separator[i] = strings.Repeat("-", w)
+	}
+	rows = append(rows[:1], append([][]string{separator}, rows[1:]...)...)
+
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.WriteString("|")
+		for i, cell := range row {
+			sb.WriteString(" ")
+			sb.WriteString(cell)
+			sb.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			sb.WriteString(" |")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+// LabelCount is the number of alerts with the value of a label.
+type LabelCount struct {
+	Value string
+	Count int
+}
+
+// Count the alerts by the value of the label, such as `{{ range countBy .Alerts "alertname" }}`.
+// The result is sorted by the count in descending order.
+func countBy(alerts []template.Alert, label string) []LabelCount {
+
+	var counts []LabelCount
+	index := make(map[string]int)
+	for _, a := range alerts {
+		v := a.Labels[label]
+		if i, ok := index[v]; ok {
+			counts[i].Count++
+			continue
+		}
+
+		index[v] = len(counts)
+		counts = append(counts, LabelCount{Value: v, Count: 1})
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+
+	return counts
+}
+
+// LimitedLabels is the labels or annotations limited to a maximum number.
+type LimitedLabels struct {
+	Pairs template.Pairs
+	// The number of labels omitted.
+	Omitted int
+}
+
+// Limit the number of labels or annotations rendered for an alert, such as
+// `{{ $l := limitLabels .Labels 10 }}{{ range $l.Pairs }}...{{ end }}{{ if $l.Omitted }}{{ $l.Omitted }} more{{ end }}`.
+// The labels are sorted by name, the global maximum number of labels is used if it is not given, and 0 means unlimited.
+func limitLabels(kv template.KV, max ...int) (*LimitedLabels, error) {
+
+	if len(max) > 1 {
+		return nil, fmt.Errorf("limitLabels: too many arguments")
+	}
+
+	limit := getMaxLabels()
+	if len(max) > 0 {
+		limit = max[0]
+	}
+
+	pairs := kv.SortedPairs()
+	if limit <= 0 || len(pairs) <= limit {
+		return &LimitedLabels{Pairs: pairs}, nil
+	}
+
+	return &LimitedLabels{
+		Pairs:   pairs[:limit],
+		Omitted: len(pairs) - limit,
+	}, nil
+}
+
+// Escape the backslash and the pipe which ends the cell, and replace the line breaks which end the row.
+func escapeTableCell(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "|", "\\|", "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// Encode the value as JSON, it is used to place the strings safely in a template producing JSON.
+func toJson(v interface{}) (string, error) {
+
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bs), nil
+}
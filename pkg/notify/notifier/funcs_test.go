@@ -0,0 +1,350 @@
+package notifier
+
+import (
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/template"
+	"testing"
+	"time"
+)
+
+func newTestTemplate(t *testing.T) *Template {
+
+	tmpl, err := NewTemplate([]string{"testdata/template.tmpl"}, "")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	return tmpl
+}
+
+func TestHumanizeDuration(t *testing.T) {
+
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{time.Duration(0), "0s"},
+		{time.Microsecond * 300, "300µs"},
+		{time.Millisecond * 500, "500ms"},
+		{time.Second * 45, "45s"},
+		{time.Second * 90, "1m30s"},
+		{time.Minute * 3, "3m"},
+		{time.Hour * 2, "2h"},
+		{time.Hour*2 + time.Minute*15 + time.Second*10, "2h15m"},
+		{time.Hour*24 + time.Minute*5, "1d0h5m"},
+		{time.Hour*74 + time.Minute*30, "3d2h"},
+		{time.Hour * 24 * 7, "7d"},
+		{-time.Second * 90, "-1m30s"},
+		{90, "1m30s"},
+		{int64(3600), "1h"},
+		{1.5, "1s"},
+		{"1h30m", "1h30m"},
+		{"120", "2m"},
+	}
+
+	for _, tt := range tests {
+		got, err := humanizeDuration(tt.value)
+		if err != nil {
+			t.Errorf("humanizeDuration(%v) error, %s", tt.value, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("humanizeDuration(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	for _, v := range []interface{}{"not a duration", []string{}} {
+		if _, err := humanizeDuration(v); err == nil {
+			t.Errorf("humanizeDuration(%v) expected error", v)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+
+	now := time.Now()
+	alerts := template.Alerts{
+		{StartsAt: now.Add(-time.Minute * 3)},
+		{StartsAt: now.Add(-time.Hour*2 - time.Minute*15)},
+		{},
+	}
+
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{now.Add(-time.Minute * 3), "3m"},
+		{alerts[0], "3m"},
+		{&alerts[1], "2h15m"},
+		{alerts, "2h15m"},
+		{[]template.Alert(alerts), "2h15m"},
+		{template.Alert{}, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := since(tt.value)
+		if err != nil {
+			t.Errorf("since(%v) error, %s", tt.value, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("since(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := since("yesterday"); err == nil {
+		t.Error("expected the error of the unsupported type")
+	}
+}
+
+func TestTemplateSince(t *testing.T) {
+
+	now := time.Now()
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "a1"}, StartsAt: now.Add(-time.Minute * 3)},
+			{Status: "firing", Labels: template.KV{"alertname": "a2"}, StartsAt: now.Add(-time.Hour*2 - time.Minute*15)},
+			{Status: "resolved", Labels: template.KV{"alertname": "a3"}, StartsAt: now.Add(-time.Hour * 48), EndsAt: now},
+		},
+	}
+
+	tmpl := newTestTemplate(t)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{ since .Alerts.Firing }}`, "2h15m"},
+		{`{{ range .Alerts.Firing }}{{ .Labels.alertname }}: {{ since . }} {{ end }}`, "a1: 3m a2: 2h15m "},
+		{`{{ humanizeDuration 5400 }}`, "1h30m"},
+		{`{{ humanizeDuration "36h" }}`, "1d12h"},
+	}
+
+	for _, tt := range tests {
+		got, err := tmpl.Text(tt.text, data, log.NewNopLogger())
+		if err != nil {
+			t.Errorf("render %s error, %s", tt.text, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("render %s = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestQueryEscape(t *testing.T) {
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"kube-system", "kube-system"},
+		{"my namespace", "my%20namespace"},
+		{"a/b", "a%2Fb"},
+		{"a b/c&d=e", "a%20b%2Fc%26d%3De"},
+		{"100%", "100%25"},
+		{"a+b", "a%2Bb"},
+		{"命名空间", "%E5%91%BD%E5%90%8D%E7%A9%BA%E9%97%B4"},
+	}
+
+	for _, tt := range tests {
+		if got := queryEscape(tt.value); got != tt.want {
+			t.Errorf("queryEscape(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateExternalURL(t *testing.T) {
+
+	tmpl, err := NewTemplate(nil, "https://grafana.example.com/")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"namespace": "my ns/prod", "pod": "web 1"}},
+		},
+	}
+
+	text := `{{ range .Alerts }}{{ externalURL }}/d/abc?var-ns={{ .Labels.namespace | queryEscape }}&var-pod={{ .Labels.pod | queryEscape }}{{ end }}`
+	got, err := tmpl.Text(text, data, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("render error, %s", err)
+	}
+
+	want := "https://grafana.example.com/d/abc?var-ns=my%20ns%2Fprod&var-pod=web%201"
+	if got != want {
+		t.Errorf("render = %s, want %s", got, want)
+	}
+
+	// The default external url is used if it is not set.
+	tmpl = newTestTemplate(t)
+	got, err = tmpl.Text(`{{ externalURL }}`, data, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("render error, %s", err)
+	}
+
+	if got != DefaultExternalURL {
+		t.Errorf("render = %s, want %s", got, DefaultExternalURL)
+	}
+}
+
+func TestAlertsTable(t *testing.T) {
+
+	alerts := template.Alerts{
+		{Labels: template.KV{"alertname": "KubePodCrashLooping", "namespace": "kube-system", "severity": "critical"}},
+		// The missing label is rendered as an empty cell.
+		{Labels: template.KV{"alertname": "HighLatency", "severity": "warning"}},
+		// The pipes and the line breaks would break the table.
+		{Labels: template.KV{"alertname": "a|b", "namespace": "line1\nline2", "severity": `c\d`}},
+	}
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   string
+	}{
+		{
+			name:   "labels",
+			labels: []string{"alertname", "namespace"},
+			want: "| alertname           | namespace   |\n" +
+				"| ------------------- | ----------- |\n" +
+				"| KubePodCrashLooping | kube-system |\n" +
+				"| HighLatency         |             |\n" +
+				"| a\\|b                | line1 line2 |\n",
+		},
+		{
+			name: "all labels",
+			want: "| alertname           | namespace   | severity |\n" +
+				"| ------------------- | ----------- | -------- |\n" +
+				"| KubePodCrashLooping | kube-system | critical |\n" +
+				"| HighLatency         |             | warning  |\n" +
+				"| a\\|b                | line1 line2 | c\\\\d     |\n",
+		},
+		{
+			// The separator is at least 3 characters.
+			name:   "narrow column",
+			labels: []string{"ns"},
+			want:   "| ns  |\n| --- |\n|     |\n|     |\n|     |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := alertsTable(alerts, tt.labels...)
+		if err != nil {
+			t.Errorf("%s: render error, %s", tt.name, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, got)
+		}
+	}
+
+	if got, err := alertsTable(template.Alerts{}); err != nil || got != "" {
+		t.Errorf("expected the empty table of no alerts, got %q, %v", got, err)
+	}
+
+	if _, err := alertsTable("alerts"); err == nil {
+		t.Error("expected the error of the unsupported type")
+	}
+}
+
+func TestTemplateAlertsTable(t *testing.T) {
+
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "a1", "pod": "web-1"}},
+			{Status: "resolved", Labels: template.KV{"alertname": "a2", "pod": "web-2"}, EndsAt: time.Now()},
+		},
+	}
+
+	// The resolved alerts are filtered out, and the trailing line break of the table is trimmed.
+	got, err := newTestTemplate(t).Text(`{{ alertsTable .Alerts.Firing "alertname" "pod" }}`, data, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("render error, %s", err)
+	}
+
+	want := "| alertname | pod   |\n| --------- | ----- |\n| a1        | web-1 |"
+	if got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+}
+
+// An alert carrying the labels label00 to label(n-1).
+func newLabels(n int) template.KV {
+
+	kv := template.KV{}
+	for i := 0; i < n; i++ {
+		kv[fmt.Sprintf("label%02d", i)] = fmt.Sprintf("value%02d", i)
+	}
+
+	return kv
+}
+
+func TestLimitLabels(t *testing.T) {
+
+	defer SetMaxLabels(0)
+
+	tests := []struct {
+		name    string
+		global  int
+		max     []int
+		first   string
+		pairs   int
+		omitted int
+	}{
+		{"unlimited", 0, nil, "label00", 50, 0},
+		{"cap", 0, []int{10}, "label00", 10, 40},
+		{"at cap", 0, []int{50}, "label00", 50, 0},
+		{"global", 5, nil, "label00", 5, 45},
+		// The limit given overrides the global one.
+		{"override global", 5, []int{20}, "label00", 20, 30},
+		{"override unlimited", 5, []int{0}, "label00", 50, 0},
+	}
+
+	for _, tt := range tests {
+		SetMaxLabels(tt.global)
+		l, err := limitLabels(newLabels(50), tt.max...)
+		if err != nil {
+			t.Errorf("%s: limit labels error, %s", tt.name, err)
+			continue
+		}
+
+		if len(l.Pairs) != tt.pairs || l.Omitted != tt.omitted {
+			t.Errorf("%s: expected %d labels and %d omitted, got %d and %d", tt.name, tt.pairs, tt.omitted, len(l.Pairs), l.Omitted)
+		}
+
+		// The labels are sorted by name.
+		if l.Pairs[0].Name != tt.first || l.Pairs[len(l.Pairs)-1].Name != fmt.Sprintf("label%02d", tt.pairs-1) {
+			t.Errorf("%s: expected the labels sorted, got %v", tt.name, l.Pairs)
+		}
+	}
+
+	if _, err := limitLabels(newLabels(1), 1, 2); err == nil {
+		t.Error("expected the error of too many arguments")
+	}
+}
+
+func TestTemplateLimitLabels(t *testing.T) {
+
+	data := template.Data{
+		Alerts: template.Alerts{{Status: "firing", Labels: newLabels(50)}},
+	}
+
+	text := `{{ range .Alerts }}{{ $l := limitLabels .Labels 3 }}{{ range $l.Pairs }}{{ .Name }}={{ .Value }} {{ end }}` +
+		`{{ if $l.Omitted }}{{ $l.Omitted }} more{{ end }}{{ end }}`
+	got, err := newTestTemplate(t).Text(text, data, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("render error, %s", err)
+	}
+
+	want := "label00=value00 label01=value01 label02=value02 47 more"
+	if got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+}
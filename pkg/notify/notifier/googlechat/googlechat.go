@@ -0,0 +1,279 @@
+package googlechat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	DefaultTitle       = `{{ template "nm.default.subject" . }}`
+	// The text of google chat message is limited to 4096 characters.
+	MessageMaxSize = 4096
+	ColorResolved  = "#2DC72D"
+	ColorCritical  = "#E6522C"
+	ColorWarning   = "#F5A623"
+	ColorInfo      = "#3498DB"
+	ColorDefault   = "#95A5A6"
+	severityLabel  = "severity"
+	cardID         = "notification"
+)
+
+type Notifier struct {
+	notifierCfg    *config.Config
+	googleChat     map[string]*config.GoogleChat
+	timeout        time.Duration
+	logger         log.Logger
+	template       *notifier.Template
+	templateName   string
+	decoration     *notifier.Decoration
+	messageMaxSize int
+}
+
+type googleChatMessage struct {
+	Text    string            `json:"text,omitempty"`
+	CardsV2 []*googleChatCard `json:"cardsV2,omitempty"`
+}
+
+type googleChatCard struct {
+	CardID string `json:"cardId"`
+	Card   *card  `json:"card"`
+}
+
+type card struct {
+	Header   *cardHeader    `json:"header,omitempty"`
+	Sections []*cardSection `json:"sections"`
+}
+
+type cardHeader struct {
+	Title string `json:"title"`
+}
+
+type cardSection struct {
+	Widgets []*cardWidget `json:"widgets"`
+}
+
+type cardWidget struct {
+	DecoratedText *decoratedText `json:"decoratedText,omitempty"`
+	TextParagraph *textParagraph `json:"textParagraph,omitempty"`
+}
+
+type decoratedText struct {
+	TopLabel string `json:"topLabel,omitempty"`
+	Text     string `json:"text"`
+}
+
+type textParagraph struct {
+	Text string `json:"text"`
+}
+
+func NewGoogleChatNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:    notifierCfg,
+		googleChat:     make(map[string]*config.GoogleChat),
+		timeout:        DefaultSendTimeout,
+		logger:         logger,
+		template:       tmpl,
+		templateName:   DefaultTemplate,
+		decoration:     &notifier.Decoration{Header: header, Footer: footer},
+		messageMaxSize: MessageMaxSize,
+	}
+
+	if opts != nil && opts.GoogleChat != nil {
+
+		if opts.GoogleChat.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.GoogleChat.NotificationTimeout)
+		}
+
+		if len(opts.GoogleChat.Template) > 0 {
+			n.templateName = opts.GoogleChat.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.GoogleChat.Header) > 0 {
+			n.decoration.Header = opts.GoogleChat.Header
+		}
+
+		if len(opts.GoogleChat.Footer) > 0 {
+			n.decoration.Footer = opts.GoogleChat.Footer
+		}
+
+		if opts.GoogleChat.MessageMaxSize > 0 {
+			n.messageMaxSize = opts.GoogleChat.MessageMaxSize
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.GoogleChat)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "GoogleChatNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which use the same webhook and message type only need to be sent once.
+		key, err := notifier.Md5key(receiver.GoogleChatConfig.Webhook.Name + receiver.GoogleChatConfig.Webhook.Key + receiver.GetNamespace() + receiver.MsgType)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "GoogleChatNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.googleChat[key] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	title, err := n.template.TempleText(DefaultTitle, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "GoogleChatNotifier: generate title error", "error", err.Error())
+		return []error{err}
+	}
+
+	messages, err := n.template.SplitWithDecoration(data, n.messageMaxSize, n.templateName, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "GoogleChatNotifier: split message error", "error", err.Error())
+		return []error{err}
+	}
+
+	status := statusText(data)
+
+	send := func(g *config.GoogleChat, msg string) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "GoogleChatNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		gm := &googleChatMessage{}
+		if g.MsgType == config.GoogleChatCard {
+			gm.CardsV2 = []*googleChatCard{
+				{
+					CardID: cardID,
+					Card: &card{
+						Header: &cardHeader{Title: title},
+						Sections: []*cardSection{
+							{
+								Widgets: []*cardWidget{
+									{DecoratedText: &decoratedText{TopLabel: "Status", Text: status}},
+									{TextParagraph: &textParagraph{Text: msg}},
+								},
+							},
+						},
+					},
+				},
+			}
+		} else {
+			gm.Text = msg
+		}
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(gm); err != nil {
+			_ = level.Error(n.logger).Log("msg", "GoogleChatNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		webhook, err := n.notifierCfg.GetSecretData(g.GetNamespace(), g.GoogleChatConfig.Webhook)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "GoogleChatNotifier: get webhook secret", "error", err.Error())
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, webhook, &buf)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		if _, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request); err != nil {
+			_ = level.Error(n.logger).Log("msg", "GoogleChatNotifier: do http error", "error", err)
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "GoogleChatNotifier: send message", "webhook", g.GoogleChatConfig.Webhook.Name)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, googleChat := range n.googleChat {
+		g := googleChat
+		for _, m := range messages {
+			msg := m
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(g, msg)
+			})
+		}
+	}
+
+	return group.Wait()
+}
+
+// The status shown in the card, it is colored by the highest severity of the firing alerts.
+func statusText(data template.Data) string {
+
+	firing := data.Alerts.Firing()
+	if len(firing) == 0 {
+		return fmt.Sprintf(`<font color="%s">RESOLVED</font>`, ColorResolved)
+	}
+
+	color, severity := ColorDefault, ""
+	for _, alert := range firing {
+		switch s := strings.ToLower(alert.Labels[severityLabel]); s {
+		case "critical", "error":
+			color, severity = ColorCritical, s
+		case "warning":
+			if color != ColorCritical {
+				color, severity = ColorWarning, s
+			}
+		case "info":
+			if color == ColorDefault {
+				color, severity = ColorInfo, s
+			}
+		}
+	}
+
+	text := fmt.Sprintf("FIRING: %d", len(firing))
+	if len(severity) > 0 {
+		text = fmt.Sprintf("%s, %s", text, strings.ToUpper(severity))
+	}
+
+	return fmt.Sprintf(`<font color="%s">%s</font>`, color, text)
+}
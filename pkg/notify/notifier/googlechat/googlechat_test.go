@@ -0,0 +1,288 @@
+package googlechat
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	os.Exit(m.Run())
+}
+
+// A stub of the incoming webhook of google chat, it records the messages received as they are encoded.
+type webhookServer struct {
+	*httptest.Server
+	mu           sync.Mutex
+	messages     []map[string]interface{}
+	contentTypes []string
+	// The status responded, it is 200 if it is 0.
+	status int
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.contentTypes = append(s.contentTypes, r.Header.Get("Content-Type"))
+		status := s.status
+		s.mu.Unlock()
+
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	return s
+}
+
+func (s *webhookServer) sent() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}(nil), s.messages...)
+}
+
+// Create a receiver sending to the webhook, the webhook is read from the environment variable.
+func newReceiver(webhook, msgType string) *config.GoogleChat {
+
+	name := "GOOGLECHAT_WEBHOOK_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	_ = os.Setenv(name, webhook)
+
+	g := config.NewGoogleChatReceiver().(*config.GoogleChat)
+	g.SetNamespace(testNamespace)
+	g.MsgType = msgType
+	g.GoogleChatConfig = &config.GoogleChatConfig{
+		Webhook: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://" + name}},
+	}
+
+	return g
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.GoogleChatOptions, receivers ...*config.GoogleChat) *Notifier {
+
+	c := testutil.NewConfig(nil, &v1alpha1.Options{GoogleChat: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewGoogleChatNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(status string, severities ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: status}
+	for i, severity := range severities {
+		alert := template.Alert{
+			Status:   status,
+			Labels:   template.KV{"alertname": fmt.Sprintf("alert%d", i+1)},
+			StartsAt: time.Now(),
+		}
+		if len(severity) > 0 {
+			alert.Labels[severityLabel] = severity
+		}
+		if status == "resolved" {
+			alert.EndsAt = time.Now()
+		}
+		data.Alerts = append(data.Alerts, alert)
+	}
+
+	return data
+}
+
+func TestNotifyText(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, config.GoogleChatText))
+	if errs := n.Notify(context.Background(), newData("firing", "critical")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	want := map[string]interface{}{"text": "[firing] alert1"}
+	if !reflect.DeepEqual(msgs[0], want) {
+		t.Errorf("expected %v, got %v", want, msgs[0])
+	}
+
+	if ct := s.contentTypes[0]; ct != "application/json; charset=UTF-8" {
+		t.Errorf("expected the json content type, got %s", ct)
+	}
+}
+
+func TestNotifyCard(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, config.GoogleChatCard))
+	if errs := n.Notify(context.Background(), newData("firing", "warning", "critical")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	want := map[string]interface{}{
+		"cardsV2": []interface{}{
+			map[string]interface{}{
+				"cardId": cardID,
+				"card": map[string]interface{}{
+					"header": map[string]interface{}{"title": "2 alerts firing"},
+					"sections": []interface{}{
+						map[string]interface{}{
+							"widgets": []interface{}{
+								map[string]interface{}{
+									"decoratedText": map[string]interface{}{
+										"topLabel": "Status",
+										"text":     `<font color="` + ColorCritical + `">FIRING: 2, CRITICAL</font>`,
+									},
+								},
+								map[string]interface{}{
+									"textParagraph": map[string]interface{}{"text": "[firing] alert1\n[firing] alert2"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(msgs[0], want) {
+		bs, _ := json.MarshalIndent(msgs[0], "", "  ")
+		t.Errorf("expected the card message, got %s", string(bs))
+	}
+}
+
+func TestNotifySplit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.GoogleChatOptions{MessageMaxSize: 40}, newReceiver(s.URL, config.GoogleChatText))
+
+	var severities []string
+	for i := 0; i < 10; i++ {
+		severities = append(severities, "warning")
+	}
+	if errs := n.Notify(context.Background(), newData("firing", severities...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) < 2 {
+		t.Fatalf("expected the message split, got %d messages", len(msgs))
+	}
+
+	alerts := 0
+	for _, msg := range msgs {
+		text := msg["text"].(string)
+		if len(text) > 40 {
+			t.Errorf("expected the message no larger than 40 bytes, got %d", len(text))
+		}
+		alerts += strings.Count(text, "[firing]")
+	}
+	if alerts != 10 {
+		t.Errorf("expected 10 alerts sent, got %d", alerts)
+	}
+}
+
+func TestNotifyWebhooks(t *testing.T) {
+
+	s1 := newWebhookServer(t)
+	defer s1.Close()
+	s2 := newWebhookServer(t)
+	defer s2.Close()
+	s2.status = http.StatusBadRequest
+
+	n := newNotifier(t, nil, newReceiver(s1.URL, config.GoogleChatText), newReceiver(s2.URL, config.GoogleChatCard))
+
+	// The failure of a webhook does not affect the others.
+	errs := n.Notify(context.Background(), newData("firing", "info"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if len(s1.sent()) != 1 || len(s2.sent()) != 1 {
+		t.Errorf("expected 1 message sent to each webhook, got %d and %d", len(s1.sent()), len(s2.sent()))
+	}
+}
+
+func TestNotifyInvalidReceiver(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	invalid := newReceiver(s.URL, "html")
+	noWebhook := newReceiver(s.URL, config.GoogleChatText)
+	noWebhook.GoogleChatConfig.Webhook = nil
+
+	n := newNotifier(t, nil, invalid, noWebhook)
+	if len(n.googleChat) != 0 {
+		t.Errorf("expected the invalid receivers ignored, got %d", len(n.googleChat))
+	}
+}
+
+func TestStatusText(t *testing.T) {
+
+	tests := []struct {
+		name string
+		data template.Data
+		want string
+	}{
+		{"resolved", newData("resolved", "critical"), `<font color="` + ColorResolved + `">RESOLVED</font>`},
+		{"critical", newData("firing", "info", "critical", "warning"), `<font color="` + ColorCritical + `">FIRING: 3, CRITICAL</font>`},
+		{"error", newData("firing", "error"), `<font color="` + ColorCritical + `">FIRING: 1, ERROR</font>`},
+		{"warning", newData("firing", "info", "Warning"), `<font color="` + ColorWarning + `">FIRING: 2, WARNING</font>`},
+		{"info", newData("firing", "info", ""), `<font color="` + ColorInfo + `">FIRING: 2, INFO</font>`},
+		{"no severity", newData("firing", ""), `<font color="` + ColorDefault + `">FIRING: 1</font>`},
+	}
+
+	for _, tt := range tests {
+		if got := statusText(tt.data); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/common/model"
+	"strings"
+)
+
+// GroupAlerts regroups the alerts by the values of the labels, so that each group can be sent in its own messages.
+// The alerts missing any of the labels are put into the default group, which is the last one.
+// It returns the notification itself if the labels are empty.
+func GroupAlerts(data template.Data, labels []string) []template.Data {
+
+	if len(labels) == 0 || len(data.Alerts) == 0 {
+		return []template.Data{data}
+	}
+
+	var keys []string
+	groups := make(map[string]template.Alerts)
+	var others template.Alerts
+	for _, alert := range data.Alerts {
+
+		var values []string
+		for _, label := range labels {
+			v, ok := alert.Labels[label]
+			if !ok {
+				break
+			}
+			values = append(values, v)
+		}
+
+		if len(values) < len(labels) {
+			others = append(others, alert)
+			continue
+		}
+
+		// The label values are joined by a character which is not allowed in the label value.
+		key := strings.Join(values, "\xff")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], alert)
+	}
+
+	var res []template.Data
+	for _, key := range keys {
+		alerts := groups[key]
+		d := newGroupData(data, alerts)
+		d.GroupLabels = template.KV{}
+		for _, label := range labels {
+			d.GroupLabels[label] = alerts[0].Labels[label]
+		}
+		res = append(res, d)
+	}
+
+	if len(others) > 0 {
+		res = append(res, newGroupData(data, others))
+	}
+
+	return res
+}
+
+// ChunkAlerts splits the alerts into the notifications of at most size alerts each.
+// It returns the notification itself if the size is 0 or the alerts do not exceed it.
+func ChunkAlerts(data template.Data, size int) []template.Data {
+
+	if size <= 0 || len(data.Alerts) <= size {
+		return []template.Data{data}
+	}
+
+	var res []template.Data
+	for i := 0; i < len(data.Alerts); i += size {
+		end := i + size
+		if end > len(data.Alerts) {
+			end = len(data.Alerts)
+		}
+		res = append(res, newGroupData(data, data.Alerts[i:end]))
+	}
+
+	return res
+}
+
+// Generate the notification of the alerts, the common labels and annotations are those shared by all the alerts.
+func newGroupData(data template.Data, alerts template.Alerts) template.Data {
+
+	d := data
+	d.Alerts = alerts
+	d.Status = string(model.AlertResolved)
+	if len(alerts.Firing()) > 0 {
+		d.Status = string(model.AlertFiring)
+	}
+
+	d.CommonLabels = alerts[0].Labels
+	d.CommonAnnotations = alerts[0].Annotations
+	for _, alert := range alerts[1:] {
+		d.CommonLabels = commonKV(d.CommonLabels, alert.Labels)
+		d.CommonAnnotations = commonKV(d.CommonAnnotations, alert.Annotations)
+	}
+
+	return d
+}
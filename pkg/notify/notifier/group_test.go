@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"github.com/prometheus/alertmanager/template"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newGroupTestData() template.Data {
+
+	alert := func(name string, labels template.KV) template.Alert {
+		labels["alertname"] = name
+		return template.Alert{
+			Status:   "firing",
+			Labels:   labels,
+			StartsAt: time.Now(),
+		}
+	}
+
+	return template.Data{
+		Receiver: "test",
+		Status:   "firing",
+		Alerts: template.Alerts{
+			alert("alert1", template.KV{"service": "api", "cluster": "c1"}),
+			alert("alert2", template.KV{"service": "db", "cluster": "c1"}),
+			alert("alert3", template.KV{"cluster": "c1"}),
+			alert("alert4", template.KV{"service": "api", "cluster": "c2"}),
+			alert("alert5", template.KV{"service": "api", "cluster": "c1"}),
+		},
+		CommonLabels: template.KV{"cluster": "c1"},
+	}
+}
+
+// The alert names of each group.
+func groupNames(groups []template.Data) [][]string {
+
+	var res [][]string
+	for _, d := range groups {
+		var names []string
+		for _, alert := range d.Alerts {
+			names = append(names, alert.Labels["alertname"])
+		}
+		res = append(res, names)
+	}
+
+	return res
+}
+
+func TestGroupAlerts(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   [][]string
+		// The group labels of each group, the default group has no group labels.
+		groupLabels []template.KV
+	}{
+		{
+			name:        "no labels",
+			want:        [][]string{{"alert1", "alert2", "alert3", "alert4", "alert5"}},
+			groupLabels: []template.KV{nil},
+		},
+		{
+			name:   "single label",
+			labels: []string{"service"},
+			want:   [][]string{{"alert1", "alert4", "alert5"}, {"alert2"}, {"alert3"}},
+			groupLabels: []template.KV{
+				{"service": "api"},
+				{"service": "db"},
+				nil,
+			},
+		},
+		{
+			name:   "composite key",
+			labels: []string{"service", "cluster"},
+			want:   [][]string{{"alert1", "alert5"}, {"alert2"}, {"alert4"}, {"alert3"}},
+			groupLabels: []template.KV{
+				{"service": "api", "cluster": "c1"},
+				{"service": "db", "cluster": "c1"},
+				{"service": "api", "cluster": "c2"},
+				nil,
+			},
+		},
+		{
+			name:        "missing label",
+			labels:      []string{"namespace"},
+			want:        [][]string{{"alert1", "alert2", "alert3", "alert4", "alert5"}},
+			groupLabels: []template.KV{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		groups := GroupAlerts(newGroupTestData(), tt.labels)
+		if got := groupNames(groups); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected groups %v, got %v", tt.name, tt.want, got)
+			continue
+		}
+
+		for i, d := range groups {
+			if !reflect.DeepEqual(d.GroupLabels, tt.groupLabels[i]) {
+				t.Errorf("%s: expected group labels %v of group %d, got %v", tt.name, tt.groupLabels[i], i, d.GroupLabels)
+			}
+		}
+	}
+}
+
+func TestGroupAlertsCommonLabels(t *testing.T) {
+
+	data := newGroupTestData()
+	data.Alerts[1].Status = "resolved"
+
+	groups := GroupAlerts(data, []string{"service"})
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+
+	// The common labels are those shared by the alerts of the group.
+	want := template.KV{"service": "api"}
+	if !reflect.DeepEqual(groups[0].CommonLabels, want) {
+		t.Errorf("expected common labels %v, got %v", want, groups[0].CommonLabels)
+	}
+
+	// The status is decided by the alerts of the group.
+	if groups[0].Status != "firing" || groups[1].Status != "resolved" {
+		t.Errorf("expected the status firing and resolved, got %s and %s", groups[0].Status, groups[1].Status)
+	}
+
+	if groups[0].Receiver != data.Receiver {
+		t.Errorf("expected receiver %s, got %s", data.Receiver, groups[0].Receiver)
+	}
+}
+
+func TestChunkAlerts(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		size     int
+		expected [][]string
+	}{
+		{"unlimited", 0, [][]string{{"alert1", "alert2", "alert3", "alert4", "alert5"}}},
+		{"above", 6, [][]string{{"alert1", "alert2", "alert3", "alert4", "alert5"}}},
+		{"at", 5, [][]string{{"alert1", "alert2", "alert3", "alert4", "alert5"}}},
+		{"below", 2, [][]string{{"alert1", "alert2"}, {"alert3", "alert4"}, {"alert5"}}},
+		{"one", 1, [][]string{{"alert1"}, {"alert2"}, {"alert3"}, {"alert4"}, {"alert5"}}},
+	}
+
+	for _, test := range tests {
+		chunks := ChunkAlerts(newGroupTestData(), test.size)
+		if got := groupNames(chunks); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+
+	// The common labels are recomputed for each chunk.
+	chunks := ChunkAlerts(newGroupTestData(), 1)
+	if chunks[0].CommonLabels["service"] != "api" || chunks[3].CommonLabels["cluster"] != "c2" {
+		t.Errorf("expected the common labels of each chunk, got %v and %v", chunks[0].CommonLabels, chunks[3].CommonLabels)
+	}
+}
@@ -0,0 +1,299 @@
+package notifier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"golang.org/x/net/http/httpproxy"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Version is the version of notification manager, it can be set at build time by
+// `-ldflags "-X github.com/kubesphere/notification-manager/pkg/notify/notifier.Version=<version>"`.
+var Version = "latest"
+
+// DefaultUserAgent returns the User-Agent header of the requests sent by notification manager.
+func DefaultUserAgent() string {
+	return "notification-manager/" + Version
+}
+
+// The transport shared by the requests which do not set the transport, so the connections can be reused across sends.
+type connectionPool struct {
+	mutex               sync.Mutex
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	transport           *http.Transport
+	// The transports with the proxy or the tls config, the key is generated by TransportKey.
+	transports map[string]*cachedTransport
+}
+
+type cachedTransport struct {
+	transport *http.Transport
+	lastUsed  time.Time
+}
+
+// The max number of the transports with the proxy or the tls config kept in the pool.
+const MaxCachedTransports = 64
+
+var pool = &connectionPool{
+	transport:  newTransport(0, 0, 0),
+	transports: make(map[string]*cachedTransport),
+}
+
+func newTransport(maxIdleConns, maxIdleConnsPerHost int, idleConnTimeout time.Duration) *http.Transport {
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if maxIdleConns > 0 {
+		transport.MaxIdleConns = maxIdleConns
+	}
+
+	if maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+
+	if idleConnTimeout > 0 {
+		transport.IdleConnTimeout = idleConnTimeout
+	}
+
+	return transport
+}
+
+// SetConnectionPool sets the idle connections kept by the shared transport, the settings of
+// http.DefaultTransport are used if they are 0. The shared transport and the cached transports will be recreated
+// if the settings are changed.
+func SetConnectionPool(maxIdleConns, maxIdleConnsPerHost int, idleConnTimeout time.Duration) {
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if pool.maxIdleConns == maxIdleConns &&
+		pool.maxIdleConnsPerHost == maxIdleConnsPerHost &&
+		pool.idleConnTimeout == idleConnTimeout {
+		return
+	}
+
+	old := pool.transport
+	pool.maxIdleConns = maxIdleConns
+	pool.maxIdleConnsPerHost = maxIdleConnsPerHost
+	pool.idleConnTimeout = idleConnTimeout
+	pool.transport = newTransport(maxIdleConns, maxIdleConnsPerHost, idleConnTimeout)
+	// The connections in use are closed after the requests finished.
+	old.CloseIdleConnections()
+
+	// The cached transports are created from the old settings, they are recreated by the following sends.
+	for key, t := range pool.transports {
+		t.transport.CloseIdleConnections()
+		delete(pool.transports, key)
+	}
+}
+
+// SharedTransport returns the transport shared by the notifiers, it is safe for concurrent use.
+func SharedTransport() http.RoundTripper {
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	return pool.transport
+}
+
+// PooledTransport returns a new transport with the settings of the connection pool, it is used to create
+// the transports with the proxy or the tls config, which are cached by CachedTransport.
+func PooledTransport() *http.Transport {
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	return pool.transport.Clone()
+}
+
+// TransportKey returns the key of the transport with the proxy and the tls config. The secrets are resolved,
+// so a new transport is used after the secrets are updated. The name identifies the other settings of the transport.
+func TransportKey(name, proxyURL string, proxyAuth *v1alpha1.BasicAuth, tlsConfig *v1alpha1.TLSConfig, getSecret SecretFunc) (string, error) {
+
+	var selectors []*v1.SecretKeySelector
+	if proxyAuth != nil {
+		selectors = append(selectors, proxyAuth.Password)
+	}
+	if tlsConfig != nil {
+		selectors = append(selectors, tlsConfig.RootCA)
+		if tlsConfig.ClientCertificate != nil {
+			selectors = append(selectors, tlsConfig.Cert, tlsConfig.Key)
+		}
+	}
+
+	var secrets []string
+	for _, selector := range selectors {
+		value := ""
+		if selector != nil {
+			var err error
+			if value, err = getSecret(selector); err != nil {
+				return "", err
+			}
+		}
+		secrets = append(secrets, value)
+	}
+
+	return Md5key(struct {
+		Name      string
+		ProxyURL  string
+		ProxyAuth *v1alpha1.BasicAuth
+		TLSConfig *v1alpha1.TLSConfig
+		Secrets   []string
+	}{
+		Name:      name,
+		ProxyURL:  proxyURL,
+		ProxyAuth: proxyAuth,
+		TLSConfig: tlsConfig,
+		Secrets:   secrets,
+	})
+}
+
+// CachedTransport returns the transport of the key cached in the pool, so the connections are reused across the sends.
+// The transport is created by newTransport if it is not cached, and the least recently used transport is dropped
+// if there are too many transports cached.
+func CachedTransport(key string, newTransport func() (*http.Transport, error)) (*http.Transport, error) {
+
+	pool.mutex.Lock()
+	if t, ok := pool.transports[key]; ok {
+		t.lastUsed = time.Now()
+		pool.mutex.Unlock()
+		return t.transport, nil
+	}
+	pool.mutex.Unlock()
+
+	// The secrets may be read when creating the transport, so it is created out of the lock.
+	transport, err := newTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	// The transport may be created by another sending at the same time.
+	if t, ok := pool.transports[key]; ok {
+		t.lastUsed = time.Now()
+		return t.transport, nil
+	}
+
+	if len(pool.transports) >= MaxCachedTransports {
+		oldest := ""
+		for k, t := range pool.transports {
+			if oldest == "" || t.lastUsed.Before(pool.transports[oldest].lastUsed) {
+				oldest = k
+			}
+		}
+		pool.transports[oldest].transport.CloseIdleConnections()
+		delete(pool.transports, oldest)
+	}
+
+	pool.transports[key] = &cachedTransport{
+		transport: transport,
+		lastUsed:  time.Now(),
+	}
+	return transport, nil
+}
+
+// SecretFunc returns the data of the secret selected by the selector.
+type SecretFunc func(selector *v1.SecretKeySelector) (string, error)
+
+// ProxyFunc returns the function used by the http.Transport to choose the proxy of a request.
+// The credentials of the proxy are read from the secret if the auth is set.
+// The requests to the hosts in the environment variable NO_PROXY will not use the proxy,
+// so the intra-cluster services can be excluded.
+func ProxyFunc(proxyURL string, auth *v1alpha1.BasicAuth, getSecret SecretFunc) (func(*http.Request) (*url.URL, error), error) {
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return nil, fmt.Errorf("invalid proxy url %s", proxyURL)
+	}
+
+	if auth != nil {
+		pass := ""
+		if auth.Password != nil {
+			pass, err = getSecret(auth.Password)
+			if err != nil {
+				return nil, err
+			}
+		}
+		u.User = url.UserPassword(auth.Username, pass)
+	}
+
+	noProxy := os.Getenv("NO_PROXY")
+	if len(noProxy) == 0 {
+		noProxy = os.Getenv("no_proxy")
+	}
+
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  u.String(),
+		HTTPSProxy: u.String(),
+		NoProxy:    noProxy,
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// NewTLSConfig creates the tls.Config with the CA and client certificate read from the secrets.
+func NewTLSConfig(c *v1alpha1.TLSConfig, getSecret SecretFunc) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	// If a CA cert is provided then let's read it in so we can validate the
+	// target's certificate properly.
+	if c.RootCA != nil {
+		ca, err := getSecret(c.RootCA)
+		if err != nil {
+			return nil, err
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM([]byte(ca)) {
+			return nil, fmt.Errorf("unable to use the specified CA cert")
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if len(c.ServerName) > 0 {
+		tlsConfig.ServerName = c.ServerName
+	}
+
+	// If a client cert & key is provided then configure TLS config accordingly.
+	if c.ClientCertificate != nil {
+		if c.Cert != nil && c.Key == nil {
+			return nil, fmt.Errorf("client cert file specified without client key file")
+		} else if c.Cert == nil && c.Key != nil {
+			return nil, fmt.Errorf("client key file specified without client cert file")
+		} else if c.Cert != nil && c.Key != nil {
+			key, err := getSecret(c.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			cert, err := getSecret(c.Cert)
+			if err != nil {
+				return nil, err
+			}
+
+			tlsCert, err := tls.X509KeyPair([]byte(cert), []byte(key))
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{tlsCert}
+		}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,500 @@
+package notifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The secrets are looked up by the name of the selector.
+func secrets(data map[string]string) SecretFunc {
+	return func(selector *v1.SecretKeySelector) (string, error) {
+		v, ok := data[selector.Name]
+		if !ok {
+			return "", fmt.Errorf("secret %s not found", selector.Name)
+		}
+		return v, nil
+	}
+}
+
+func selector(name string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}}
+}
+
+func certPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// Generate a self-signed client certificate, it returns the certificate and the key in PEM.
+func newClientCert(t *testing.T) (*x509.Certificate, string, string) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key error, %s", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "notification-manager"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate error, %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate error, %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key error, %s", err)
+	}
+
+	return cert, certPEM(cert), string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func get(c *tls.Config, u string) error {
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: c}}
+	resp, err := client.Get(u)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func TestNewTLSConfig(t *testing.T) {
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// The handshake errors of the clients which do not trust the server are expected.
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	s.StartTLS()
+	defer s.Close()
+
+	getSecret := secrets(map[string]string{
+		"ca":      certPEM(s.Certificate()),
+		"invalid": "not a cert",
+	})
+
+	tests := []struct {
+		name    string
+		config  *v1alpha1.TLSConfig
+		wantErr bool
+	}{
+		{"custom ca", &v1alpha1.TLSConfig{RootCA: selector("ca")}, false},
+		{"unknown ca", &v1alpha1.TLSConfig{}, true},
+		{"insecure skip verify", &v1alpha1.TLSConfig{InsecureSkipVerify: true}, false},
+	}
+
+	for _, tt := range tests {
+		c, err := NewTLSConfig(tt.config, getSecret)
+		if err != nil {
+			t.Fatalf("%s: new tls config error, %s", tt.name, err)
+		}
+
+		if err := get(c, s.URL); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	if _, err := NewTLSConfig(&v1alpha1.TLSConfig{RootCA: selector("invalid")}, getSecret); err == nil {
+		t.Error("expected the error of the invalid ca")
+	}
+
+	if _, err := NewTLSConfig(&v1alpha1.TLSConfig{RootCA: selector("missing")}, getSecret); err == nil {
+		t.Error("expected the error of the missing secret")
+	}
+}
+
+func TestNewTLSConfigClientCertificate(t *testing.T) {
+
+	cert, certData, keyData := newClientCert(t)
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	s.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	// The handshake errors of the clients without the certificate are expected.
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	s.StartTLS()
+	defer s.Close()
+
+	getSecret := secrets(map[string]string{
+		"ca":   certPEM(s.Certificate()),
+		"cert": certData,
+		"key":  keyData,
+	})
+
+	c, err := NewTLSConfig(&v1alpha1.TLSConfig{
+		RootCA: selector("ca"),
+		ClientCertificate: &v1alpha1.ClientCertificate{
+			Cert: selector("cert"),
+			Key:  selector("key"),
+		},
+	}, getSecret)
+	if err != nil {
+		t.Fatalf("new tls config error, %s", err)
+	}
+
+	if err := get(c, s.URL); err != nil {
+		t.Errorf("expected the client certificate accepted, got %s", err)
+	}
+
+	// The server rejects the client without the certificate.
+	c, err = NewTLSConfig(&v1alpha1.TLSConfig{RootCA: selector("ca")}, getSecret)
+	if err != nil {
+		t.Fatalf("new tls config error, %s", err)
+	}
+
+	if err := get(c, s.URL); err == nil {
+		t.Error("expected the client without certificate rejected")
+	}
+
+	_, err = NewTLSConfig(&v1alpha1.TLSConfig{
+		ClientCertificate: &v1alpha1.ClientCertificate{Cert: selector("cert")},
+	}, getSecret)
+	if err == nil {
+		t.Error("expected the error of the client cert without key")
+	}
+}
+
+// Replace the transports cached in the pool for the test, the returned function restores them.
+func resetTransports() func() {
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	old := pool.transports
+	pool.transports = make(map[string]*cachedTransport)
+	return func() {
+		pool.mutex.Lock()
+		defer pool.mutex.Unlock()
+		pool.transports = old
+	}
+}
+
+// A server counting the connections dialed to it.
+func newCountingServer() (*httptest.Server, *int32) {
+
+	var conns int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	s.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	s.Start()
+
+	return s, &conns
+}
+
+func send(transport http.RoundTripper, u string) error {
+
+	resp, err := (&http.Client{Transport: transport}).Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The connection is reused only if the body is read to the end.
+	_, err = ioutil.ReadAll(resp.Body)
+	return err
+}
+
+func TestCachedTransportReuse(t *testing.T) {
+
+	defer resetTransports()()
+
+	s, conns := newCountingServer()
+	defer s.Close()
+
+	creates := 0
+	newTransport := func() (*http.Transport, error) {
+		creates++
+		return &http.Transport{}, nil
+	}
+
+	for i := 0; i < 10; i++ {
+		transport, err := CachedTransport("key", newTransport)
+		if err != nil {
+			t.Fatalf("get transport error, %s", err)
+		}
+		if err := send(transport, s.URL); err != nil {
+			t.Fatalf("send error, %s", err)
+		}
+	}
+
+	if creates != 1 {
+		t.Errorf("expected the transport created once, got %d", creates)
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("expected the connection reused, got %d dials", n)
+	}
+}
+
+func TestCachedTransportEviction(t *testing.T) {
+
+	defer resetTransports()()
+
+	creates := make(map[string]int)
+	newTransport := func(key string) func() (*http.Transport, error) {
+		return func() (*http.Transport, error) {
+			creates[key]++
+			return &http.Transport{}, nil
+		}
+	}
+
+	get := func(key string) {
+		if _, err := CachedTransport(key, newTransport(key)); err != nil {
+			t.Fatalf("get transport error, %s", err)
+		}
+		// Make sure the transports are used at different times.
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < MaxCachedTransports; i++ {
+		get("key-" + strconv.Itoa(i))
+	}
+
+	// The first transport is used again, so the second one is the least recently used.
+	get("key-0")
+	get("key-new")
+
+	if len(pool.transports) != MaxCachedTransports {
+		t.Errorf("expected %d transports cached, got %d", MaxCachedTransports, len(pool.transports))
+	}
+
+	get("key-0")
+	if creates["key-0"] != 1 {
+		t.Errorf("expected the recently used transport kept, created %d times", creates["key-0"])
+	}
+
+	get("key-1")
+	if creates["key-1"] != 2 {
+		t.Errorf("expected the least recently used transport dropped, created %d times", creates["key-1"])
+	}
+}
+
+func TestTransportKey(t *testing.T) {
+
+	getSecret := secrets(map[string]string{
+		"password":  "password",
+		"password2": "password2",
+		"ca":        "ca",
+		"cert":      "cert",
+		"key":       "key",
+	})
+
+	key := func(name, proxyURL string, auth *v1alpha1.BasicAuth, tlsConfig *v1alpha1.TLSConfig, getSecret SecretFunc) string {
+		k, err := TransportKey(name, proxyURL, auth, tlsConfig, getSecret)
+		if err != nil {
+			t.Fatalf("get transport key error, %s", err)
+		}
+		return k
+	}
+
+	auth := func(username, password string) *v1alpha1.BasicAuth {
+		return &v1alpha1.BasicAuth{Username: username, Password: selector(password)}
+	}
+
+	tlsConfig := func(serverName string) *v1alpha1.TLSConfig {
+		return &v1alpha1.TLSConfig{
+			RootCA:            selector("ca"),
+			ClientCertificate: &v1alpha1.ClientCertificate{Cert: selector("cert"), Key: selector("key")},
+			ServerName:        serverName,
+		}
+	}
+
+	base := key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), getSecret)
+	if k := key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), getSecret); k != base {
+		t.Errorf("expected the same key of the same settings, got %s and %s", base, k)
+	}
+
+	// The secrets are updated in place, the selectors are not changed.
+	updated := func(name, value string) SecretFunc {
+		return func(selector *v1.SecretKeySelector) (string, error) {
+			if selector.Name == name {
+				return value, nil
+			}
+			return getSecret(selector)
+		}
+	}
+
+	insecure := tlsConfig("nm")
+	insecure.InsecureSkipVerify = true
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"name", key("wechat", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), getSecret)},
+		{"proxy url", key("webhook", "http://proxy:8081", auth("user", "password"), tlsConfig("nm"), getSecret)},
+		{"proxy user", key("webhook", "http://proxy:8080", auth("admin", "password"), tlsConfig("nm"), getSecret)},
+		{"proxy password selector", key("webhook", "http://proxy:8080", auth("user", "password2"), tlsConfig("nm"), getSecret)},
+		{"proxy password value", key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), updated("password", "new"))},
+		{"no proxy auth", key("webhook", "http://proxy:8080", nil, tlsConfig("nm"), getSecret)},
+		{"server name", key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("other"), getSecret)},
+		{"insecure skip verify", key("webhook", "http://proxy:8080", auth("user", "password"), insecure, getSecret)},
+		{"ca value", key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), updated("ca", "new"))},
+		{"cert value", key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), updated("cert", "new"))},
+		{"key value", key("webhook", "http://proxy:8080", auth("user", "password"), tlsConfig("nm"), updated("key", "new"))},
+		{"no tls", key("webhook", "http://proxy:8080", auth("user", "password"), nil, getSecret)},
+	}
+
+	seen := map[string]string{base: "base"}
+	for _, test := range tests {
+		if other, ok := seen[test.key]; ok {
+			t.Errorf("%s: expected a different key, got the key of %s", test.name, other)
+		}
+		seen[test.key] = test.name
+	}
+
+	if _, err := TransportKey("webhook", "", auth("user", "missing"), nil, getSecret); err == nil {
+		t.Error("expected the error of the missing secret")
+	}
+}
+
+func TestSetConnectionPool(t *testing.T) {
+
+	pool.mutex.Lock()
+	maxIdleConns, maxIdleConnsPerHost, idleConnTimeout := pool.maxIdleConns, pool.maxIdleConnsPerHost, pool.idleConnTimeout
+	pool.mutex.Unlock()
+	defer SetConnectionPool(maxIdleConns, maxIdleConnsPerHost, idleConnTimeout)
+
+	SetConnectionPool(10, 5, time.Minute)
+	transport := SharedTransport().(*http.Transport)
+	if transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 5 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("expected the settings applied, got %d %d %s", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	// The transport is kept if the settings are not changed, so the connections are reused.
+	SetConnectionPool(10, 5, time.Minute)
+	if SharedTransport() != transport {
+		t.Error("expected the transport kept")
+	}
+
+	// The settings of the default transport are used if they are 0.
+	SetConnectionPool(0, 0, 0)
+	transport = SharedTransport().(*http.Transport)
+	def := http.DefaultTransport.(*http.Transport)
+	if transport.MaxIdleConns != def.MaxIdleConns || transport.IdleConnTimeout != def.IdleConnTimeout {
+		t.Errorf("expected the default settings, got %d %s", transport.MaxIdleConns, transport.IdleConnTimeout)
+	}
+}
+
+func TestPooledTransport(t *testing.T) {
+
+	defer resetTransports()()
+
+	pool.mutex.Lock()
+	maxIdleConns, maxIdleConnsPerHost, idleConnTimeout := pool.maxIdleConns, pool.maxIdleConnsPerHost, pool.idleConnTimeout
+	pool.mutex.Unlock()
+	defer SetConnectionPool(maxIdleConns, maxIdleConnsPerHost, idleConnTimeout)
+
+	// The transports with the proxy or the tls config use the settings of the pool.
+	SetConnectionPool(10, 5, time.Minute)
+	transport := PooledTransport()
+	if transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 5 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("expected the settings applied, got %d %d %s", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if transport == SharedTransport() {
+		t.Error("expected a new transport")
+	}
+
+	creates := 0
+	newTransport := func() (*http.Transport, error) {
+		creates++
+		return PooledTransport(), nil
+	}
+	if _, err := CachedTransport("key", newTransport); err != nil {
+		t.Fatalf("get transport error, %s", err)
+	}
+
+	// The cached transports are recreated with the new settings.
+	SetConnectionPool(20, 10, time.Minute)
+	transport, err := CachedTransport("key", newTransport)
+	if err != nil {
+		t.Fatalf("get transport error, %s", err)
+	}
+	if creates != 2 || transport.MaxIdleConns != 20 || transport.MaxIdleConnsPerHost != 10 {
+		t.Errorf("expected the transport recreated with the new settings, created %d times, got %d %d",
+			creates, transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+}
+
+func BenchmarkCachedTransport(b *testing.B) {
+
+	defer resetTransports()()
+
+	s, conns := newCountingServer()
+	defer s.Close()
+
+	transports := map[string]func() http.RoundTripper{
+		// A new transport is created for each send, as the notifiers did before the transports were cached.
+		"new": func() http.RoundTripper {
+			return &http.Transport{}
+		},
+		"cached": func() http.RoundTripper {
+			transport, err := CachedTransport("benchmark", func() (*http.Transport, error) {
+				return &http.Transport{}, nil
+			})
+			if err != nil {
+				b.Fatalf("get transport error, %s", err)
+			}
+			return transport
+		},
+	}
+
+	for _, name := range []string{"new", "cached"} {
+		b.Run(name, func(b *testing.B) {
+
+			atomic.StoreInt32(conns, 0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				transport := transports[name]()
+				if err := send(transport, s.URL); err != nil {
+					b.Fatalf("send error, %s", err)
+				}
+				if name == "new" {
+					transport.(*http.Transport).CloseIdleConnections()
+				}
+			}
+
+			b.ReportMetric(float64(atomic.LoadInt32(conns))/float64(b.N), "dials/op")
+		})
+	}
+}
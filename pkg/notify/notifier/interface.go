@@ -8,3 +8,10 @@ import (
 type Notifier interface {
 	Notify(ctx context.Context, data template.Data) []error
 }
+
+// Deferrer is implemented by the notifiers which buffer the alerts and send them later, such as the throttled alerts.
+type Deferrer interface {
+	// Deferred returns the names of the receivers which nothing was sent to in the notification,
+	// the results of them are recorded when the buffered alerts are sent.
+	Deferred() []string
+}
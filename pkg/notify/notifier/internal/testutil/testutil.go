@@ -0,0 +1,73 @@
+// Package testutil contains the helpers shared by the tests of the notifiers.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sync/atomic"
+	"testing"
+)
+
+// Namespace is the namespace of the receivers and the secrets used by the tests.
+const Namespace = "default"
+
+// SecretCache is a cache serving the secrets of the tests, only the secrets are read from it.
+type SecretCache struct {
+	cache.Cache
+	secrets client.Client
+	// The sequence of the secrets created.
+	seq int32
+}
+
+// NewSecretCache creates the cache serving the secrets given.
+func NewSecretCache(secrets ...runtime.Object) *SecretCache {
+	return &SecretCache{secrets: fake.NewFakeClient(secrets...)}
+}
+
+func (c *SecretCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return c.secrets.Get(ctx, key, obj)
+}
+
+// NewSecret creates a secret with the value in the key value, and returns the selector of it.
+// The name of the secret is unique, so that the tests can create the secrets repeatedly.
+func (c *SecretCache) NewSecret(t testing.TB, value string) *v1.SecretKeySelector {
+
+	t.Helper()
+
+	name := fmt.Sprintf("secret-%d", atomic.AddInt32(&c.seq, 1))
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: Namespace},
+		Data:       map[string][]byte{"value": []byte(value)},
+	}
+	if err := c.secrets.Create(context.Background(), secret); err != nil {
+		t.Fatalf("create secret error, %s", err)
+	}
+
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: "value"}
+}
+
+// NewConfig creates the config reading the secrets from the cache, the template is testdata/template.tmpl
+// of the package tested.
+func NewConfig(c cache.Cache, opts *v1alpha1.Options) *config.Config {
+
+	cfg := config.NewWithClient(context.Background(), log.NewNopLogger(), c, nil, nil)
+	if opts == nil {
+		opts = &v1alpha1.Options{}
+	}
+	if opts.Global == nil {
+		opts.Global = &v1alpha1.GlobalOptions{}
+	}
+	opts.Global.TemplateFiles = []string{"testdata/template.tmpl"}
+	cfg.ReceiverOpts = opts
+
+	return cfg
+}
@@ -0,0 +1,268 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	v1 "k8s.io/api/core/v1"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The messages are written in batch, the batch is sent when it is full or the timeout is reached.
+	DefaultBatchTimeout = time.Millisecond * 10
+	mechanismPlain      = "PLAIN"
+	mechanismSHA256     = "SCRAM-SHA-256"
+	mechanismSHA512     = "SCRAM-SHA-512"
+)
+
+type Notifier struct {
+	notifierCfg  *config.Config
+	kafka        map[string]*config.Kafka
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+	// Create the writer of the receiver, it can be replaced by the tests.
+	newWriter func(k *config.Kafka) (messageWriter, error)
+}
+
+// The writer which produces the messages to kafka.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+func NewKafkaNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		kafka:        make(map[string]*config.Kafka),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+	}
+	n.newWriter = func(k *config.Kafka) (messageWriter, error) {
+		w, err := n.writer(k)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	if opts != nil && opts.Kafka != nil {
+
+		if opts.Kafka.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Kafka.NotificationTimeout)
+		}
+
+		if len(opts.Kafka.Template) > 0 {
+			n.templateName = opts.Kafka.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Kafka)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "KafkaNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which produce the messages of the same format to the same topic only need to be sent once.
+		key, err := notifier.Md5key(receiver)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "KafkaNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.kafka[key] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	send := func(k *config.Kafka) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "KafkaNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		messages, err := n.messages(data, k.Format)
+		if err != nil {
+			return err
+		}
+
+		writer, err := n.newWriter(k)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "KafkaNotifier: create writer error", "topic", k.Topic, "error", err.Error())
+			return err
+		}
+
+		defer func() {
+			_ = writer.Close()
+		}()
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+
+		if err := writer.WriteMessages(ctx, messages...); err != nil {
+			// Report the error of each message so that the failed alerts can be found.
+			if errs, ok := err.(kafka.WriteErrors); ok {
+				for i, e := range errs {
+					if e != nil {
+						_ = level.Error(n.logger).Log("msg", "KafkaNotifier: produce message error", "topic", k.Topic,
+							"key", string(messages[i].Key), "error", e.Error())
+					}
+				}
+				return fmt.Errorf("produce %d of %d messages to topic %s error", errs.Count(), len(errs), k.Topic)
+			}
+
+			_ = level.Error(n.logger).Log("msg", "KafkaNotifier: produce message error", "topic", k.Topic, "error", err.Error())
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "KafkaNotifier: produce message", "topic", k.Topic, "count", len(messages))
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, kafka := range n.kafka {
+		k := kafka
+		group.Add(func(stopCh chan interface{}) {
+			stopCh <- send(k)
+		})
+	}
+
+	return group.Wait()
+}
+
+// Generate a message for each alert, the message is keyed by the fingerprint of the alert,
+// so that the messages of the same alert are always produced to the same partition.
+func (n *Notifier) messages(data template.Data, format string) ([]kafka.Message, error) {
+
+	var messages []kafka.Message
+	for _, alert := range data.Alerts {
+
+		var value []byte
+		if format == config.KafkaText {
+			d := template.Data{
+				Receiver:          data.Receiver,
+				Status:            alert.Status,
+				Alerts:            template.Alerts{alert},
+				GroupLabels:       data.GroupLabels,
+				CommonLabels:      alert.Labels,
+				CommonAnnotations: alert.Annotations,
+				ExternalURL:       data.ExternalURL,
+			}
+
+			msg, err := n.template.TempleText(n.templateName, d, n.logger)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "KafkaNotifier: generate message error", "error", err.Error())
+				return nil, err
+			}
+			value = []byte(msg)
+		} else {
+			bs, err := json.Marshal(alert)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "KafkaNotifier: encode message error", "error", err.Error())
+				return nil, err
+			}
+			value = bs
+		}
+
+		messages = append(messages, kafka.Message{
+			Key:   []byte(alert.Fingerprint),
+			Value: value,
+		})
+	}
+
+	return messages, nil
+}
+
+func (n *Notifier) writer(k *config.Kafka) (*kafka.Writer, error) {
+
+	getSecret := func(selector *v1.SecretKeySelector) (string, error) {
+		return n.notifierCfg.GetSecretData(k.GetNamespace(), selector)
+	}
+
+	transport := &kafka.Transport{
+		DialTimeout: n.timeout,
+	}
+
+	if k.KafkaConfig.TLS != nil {
+		tlsConfig, err := notifier.NewTLSConfig(k.KafkaConfig.TLS, getSecret)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLS = tlsConfig
+	}
+
+	if c := k.KafkaConfig.SASL; c != nil {
+		password, err := getSecret(c.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		var mechanism sasl.Mechanism
+		switch c.Mechanism {
+		case mechanismSHA256:
+			mechanism, err = scram.Mechanism(scram.SHA256, c.Username, password)
+		case mechanismSHA512:
+			mechanism, err = scram.Mechanism(scram.SHA512, c.Username, password)
+		default:
+			mechanism = plain.Mechanism{Username: c.Username, Password: password}
+		}
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+
+	return &kafka.Writer{
+		Addr:         kafka.TCP(k.KafkaConfig.Brokers...),
+		Topic:        k.Topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: DefaultBatchTimeout,
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+	}, nil
+}
@@ -0,0 +1,279 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/segmentio/kafka-go"
+	"k8s.io/api/core/v1"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	_ = os.Setenv("KAFKA_PASSWORD", "password")
+	os.Exit(m.Run())
+}
+
+// A producer records the messages written, the messages are keyed by the topic.
+type mockProducer struct {
+	mu       sync.Mutex
+	messages map[string][]kafka.Message
+	closed   int
+	// The error of writing the messages.
+	err error
+}
+
+func newMockProducer(n *Notifier) *mockProducer {
+
+	p := &mockProducer{messages: make(map[string][]kafka.Message)}
+	n.newWriter = func(k *config.Kafka) (messageWriter, error) {
+		return &mockWriter{producer: p, topic: k.Topic}, nil
+	}
+
+	return p
+}
+
+func (p *mockProducer) written(topic string) []kafka.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]kafka.Message(nil), p.messages[topic]...)
+}
+
+type mockWriter struct {
+	producer *mockProducer
+	topic    string
+}
+
+func (w *mockWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.producer.mu.Lock()
+	defer w.producer.mu.Unlock()
+
+	if w.producer.err != nil {
+		return w.producer.err
+	}
+	w.producer.messages[w.topic] = append(w.producer.messages[w.topic], msgs...)
+	return nil
+}
+
+func (w *mockWriter) Close() error {
+	w.producer.mu.Lock()
+	defer w.producer.mu.Unlock()
+	w.producer.closed++
+	return nil
+}
+
+func newReceiver(topic, format string) *config.Kafka {
+
+	k := config.NewKafkaReceiver().(*config.Kafka)
+	k.SetNamespace(testNamespace)
+	k.Topic = topic
+	k.Format = format
+	k.KafkaConfig = &config.KafkaConfig{Brokers: []string{"broker1:9092", "broker2:9092"}}
+
+	return k
+}
+
+func newNotifier(t *testing.T, receivers ...*config.Kafka) *Notifier {
+
+	c := testutil.NewConfig(nil, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewKafkaNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(alertnames ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range alertnames {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": name},
+			Annotations: template.KV{},
+			StartsAt:    time.Now(),
+			Fingerprint: "fp-" + name,
+		})
+	}
+
+	return data
+}
+
+func TestNotifyJSON(t *testing.T) {
+
+	n := newNotifier(t, newReceiver("alerts", config.KafkaJSON))
+	p := newMockProducer(n)
+
+	data := newData("alert1", "alert2")
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := p.written("alerts")
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	for i, msg := range msgs {
+		// The message is keyed by the fingerprint, so the messages of an alert are in the same partition.
+		if key := string(msg.Key); key != data.Alerts[i].Fingerprint {
+			t.Errorf("expected key %s, got %s", data.Alerts[i].Fingerprint, key)
+		}
+
+		var alert template.Alert
+		if err := json.Unmarshal(msg.Value, &alert); err != nil {
+			t.Fatalf("decode message error, %s", err)
+		}
+		if !reflect.DeepEqual(alert.Labels, data.Alerts[i].Labels) || alert.Fingerprint != data.Alerts[i].Fingerprint {
+			t.Errorf("expected the alert %v, got %v", data.Alerts[i], alert)
+		}
+	}
+
+	if p.closed != 1 {
+		t.Errorf("expected the writer closed, got %d", p.closed)
+	}
+}
+
+func TestNotifyText(t *testing.T) {
+
+	n := newNotifier(t, newReceiver("alerts-text", config.KafkaText))
+	p := newMockProducer(n)
+
+	if errs := n.Notify(context.Background(), newData("alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// Each alert is rendered into its own message.
+	var got []string
+	for _, msg := range p.written("alerts-text") {
+		got = append(got, string(msg.Key)+": "+strings.TrimSpace(string(msg.Value)))
+	}
+	want := []string{"fp-alert1: [firing] alert1", "fp-alert2: [firing] alert2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected messages %q, got %q", want, got)
+	}
+}
+
+func TestNotifyTopics(t *testing.T) {
+
+	// The receivers producing the same messages to the same topic are sent once.
+	n := newNotifier(t, newReceiver("topic1", config.KafkaJSON), newReceiver("topic1", config.KafkaJSON), newReceiver("topic2", config.KafkaJSON))
+	p := newMockProducer(n)
+
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(p.written("topic1")) != 1 || len(p.written("topic2")) != 1 {
+		t.Errorf("expected 1 message produced to each topic, got %d and %d", len(p.written("topic1")), len(p.written("topic2")))
+	}
+}
+
+func TestNotifyProduceError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"write errors", kafka.WriteErrors{nil, errors.New("leader not available")}, "produce 1 of 2 messages to topic alerts error"},
+		{"other error", errors.New("dial error"), "dial error"},
+	}
+
+	for _, tt := range tests {
+		n := newNotifier(t, newReceiver("alerts", config.KafkaJSON))
+		p := newMockProducer(n)
+		p.err = tt.err
+
+		errs := n.Notify(context.Background(), newData("alert1", "alert2"))
+		if len(errs) != 1 || errs[0].Error() != tt.want {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.want, errs)
+		}
+	}
+}
+
+func TestNotifyInvalidReceiver(t *testing.T) {
+
+	noTopic := newReceiver("", config.KafkaJSON)
+	unknownFormat := newReceiver("alerts", "avro")
+	noBrokers := newReceiver("alerts", config.KafkaJSON)
+	noBrokers.KafkaConfig.Brokers = nil
+
+	n := newNotifier(t, noTopic, unknownFormat, noBrokers)
+	if len(n.kafka) != 0 {
+		t.Errorf("expected the invalid receivers ignored, got %d", len(n.kafka))
+	}
+}
+
+func TestWriter(t *testing.T) {
+
+	password := &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://KAFKA_PASSWORD"}}
+
+	tests := []struct {
+		name      string
+		sasl      *v1alpha1.KafkaSASL
+		mechanism string
+	}{
+		{"no sasl", nil, ""},
+		{"plain", &v1alpha1.KafkaSASL{Username: "user", Password: password}, mechanismPlain},
+		{"scram sha256", &v1alpha1.KafkaSASL{Mechanism: mechanismSHA256, Username: "user", Password: password}, mechanismSHA256},
+		{"scram sha512", &v1alpha1.KafkaSASL{Mechanism: mechanismSHA512, Username: "user", Password: password}, mechanismSHA512},
+	}
+
+	for _, tt := range tests {
+		k := newReceiver("alerts", config.KafkaJSON)
+		k.KafkaConfig.SASL = tt.sasl
+		n := newNotifier(t, k)
+
+		w, err := n.writer(k)
+		if err != nil {
+			t.Fatalf("%s: create writer error, %s", tt.name, err)
+		}
+
+		if w.Topic != "alerts" || w.Addr.String() != "broker1:9092,broker2:9092" {
+			t.Errorf("%s: expected the writer of the topic and brokers, got %s and %s", tt.name, w.Topic, w.Addr)
+		}
+
+		if _, ok := w.Balancer.(*kafka.Hash); !ok {
+			t.Errorf("%s: expected the hash balancer, got %T", tt.name, w.Balancer)
+		}
+
+		transport := w.Transport.(*kafka.Transport)
+		var mechanism string
+		if transport.SASL != nil {
+			mechanism = transport.SASL.Name()
+		}
+		if mechanism != tt.mechanism {
+			t.Errorf("%s: expected the sasl mechanism %q, got %q", tt.name, tt.mechanism, mechanism)
+		}
+	}
+
+	// The password is required by the sasl.
+	k := newReceiver("alerts", config.KafkaJSON)
+	k.KafkaConfig.SASL = &v1alpha1.KafkaSASL{Username: "user", Password: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://KAFKA_MISSING"}}}
+	if _, err := newNotifier(t).writer(k); err == nil {
+		t.Error("expected the error of the missing password")
+	}
+}
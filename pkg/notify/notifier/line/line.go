@@ -0,0 +1,286 @@
+package line
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultApiURL      = "https://notify-api.line.me"
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The message is limited to 1000 characters.
+	MessageMaxSize = 1000
+	// The maximum times to retry when the request is rate limited.
+	DefaultMaxRetries = 3
+	// The waiting time when the request is rate limited but the response does not tell how long to wait.
+	DefaultRetryAfter = time.Second
+	// The maximum waiting time when the request is rate limited, the rate limit of LINE Notify is reset every hour.
+	MaxRetryAfter = time.Minute
+	notifyPath    = "/api/notify"
+)
+
+type Notifier struct {
+	notifierCfg  *config.Config
+	line         map[string]*config.Line
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+	decoration   *notifier.Decoration
+}
+
+type lineResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func NewLineNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		line:         make(map[string]*config.Line),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+		decoration:   &notifier.Decoration{Header: header, Footer: footer},
+	}
+
+	if opts != nil && opts.Line != nil {
+
+		if opts.Line.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Line.NotificationTimeout)
+		}
+
+		if len(opts.Line.Template) > 0 {
+			n.templateName = opts.Line.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.Line.Header) > 0 {
+			n.decoration.Header = opts.Line.Header
+		}
+
+		if len(opts.Line.Footer) > 0 {
+			n.decoration.Footer = opts.Line.Footer
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Line)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "LineNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receiver is shared by the notifications, so the default api url is set in a copy of it.
+		c := *receiver.LineConfig
+		if len(c.APIURL) == 0 {
+			c.APIURL = DefaultApiURL
+		}
+		l := *receiver
+		l.LineConfig = &c
+
+		// The receivers which use the same token and send the same sticker and image only need to be sent once.
+		key, err := notifier.Md5key(&l)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "LineNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.line[key] = &l
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	messages, err := n.template.SplitWithDecoration(data, MessageMaxSize, n.templateName, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "LineNotifier: split message error", "error", err.Error())
+		return []error{err}
+	}
+
+	send := func(l *config.Line) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "LineNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		token, err := n.notifierCfg.GetSecretData(l.GetNamespace(), l.LineConfig.Token)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "LineNotifier: get token secret", "error", err.Error())
+			return err
+		}
+
+		u, err := notifier.UrlWithPath(l.LineConfig.APIURL, notifyPath)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "LineNotifier: set path error", "error", err)
+			return err
+		}
+
+		// The messages are sent in order, the sticker and image are only sent with the first message.
+		for i, msg := range messages {
+			form := url.Values{}
+			form.Set("message", msg)
+			if i == 0 {
+				if l.StickerPackageID > 0 {
+					form.Set("stickerPackageId", strconv.Itoa(l.StickerPackageID))
+					form.Set("stickerId", strconv.Itoa(l.StickerID))
+				}
+				if len(l.ImageFullsize) > 0 {
+					// The thumbnail is required by LINE Notify, use the full size image if it is not set.
+					thumbnail := l.ImageThumbnail
+					if len(thumbnail) == 0 {
+						thumbnail = l.ImageFullsize
+					}
+					form.Set("imageThumbnail", thumbnail)
+					form.Set("imageFullsize", l.ImageFullsize)
+				}
+			}
+
+			if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+				return err
+			}
+
+			if err := n.sendMessage(ctx, u, token, form); err != nil {
+				_ = level.Error(n.logger).Log("msg", "LineNotifier: send message error", "error", err.Error())
+				return err
+			}
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "LineNotifier: send message", "token", l.LineConfig.Token.Name)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, line := range n.line {
+		l := line
+		group.Add(func(stopCh chan interface{}) {
+			stopCh <- send(l)
+		})
+	}
+
+	return group.Wait()
+}
+
+// Send the message, it will be retried if the request is rate limited.
+func (n *Notifier) sendMessage(ctx context.Context, u, token string, form url.Values) error {
+
+	notifier.GetRetryBudget().Request()
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequest(http.MethodPost, u, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		wait, err := n.do(ctx, request)
+		if err == nil {
+			return nil
+		}
+
+		if wait == 0 || attempt >= DefaultMaxRetries {
+			return err
+		}
+
+		// The failure is returned immediately if the retry budget shared by all notifiers is exhausted.
+		if !notifier.GetRetryBudget().AllowRetry() {
+			_ = level.Warn(n.logger).Log("msg", "LineNotifier: retry budget exhausted, stop retrying", "attempt", attempt+1)
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "LineNotifier: rate limited, retry to send message", "wait", wait.String())
+		if e := notifier.Sleep(ctx, wait); e != nil {
+			return err
+		}
+	}
+}
+
+// Do the request, the duration returned is the time to wait before retrying if the request is rate limited.
+func (n *Notifier) do(ctx context.Context, request *http.Request) (time.Duration, error) {
+
+	client := &http.Client{Timeout: n.timeout}
+	resp, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return 0, nil
+	}
+
+	var lr lineResponse
+	_ = json.Unmarshal(body, &lr)
+	err = fmt.Errorf("line error, code: %d, message: %s", resp.StatusCode, lr.Message)
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, err
+	}
+
+	now := time.Now()
+	wait := DefaultRetryAfter
+	if d := notifier.ParseRetryAfter(resp.Header.Get("Retry-After"), now); d > 0 {
+		wait = d
+	} else if reset, e := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); e == nil && reset > 0 {
+		// The time when the limit is reset, in UTC epoch seconds.
+		if d := time.Unix(reset, 0).Sub(now); d > 0 {
+			wait = d
+		}
+	}
+
+	// Do not wait too long, the sending fails if the limit will not be reset soon.
+	if wait > MaxRetryAfter {
+		return 0, err
+	}
+
+	return wait, err
+}
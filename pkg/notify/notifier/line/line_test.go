@@ -0,0 +1,352 @@
+package line
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	os.Exit(m.Run())
+}
+
+// A request received by the stub of LINE Notify.
+type lineRequest struct {
+	path          string
+	contentType   string
+	authorization string
+	form          url.Values
+}
+
+// A stub of LINE Notify, it records the requests and responds with the handler.
+type lineServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []lineRequest
+}
+
+// Create the stub, it responds 200 if the handler is nil.
+func newLineServer(t *testing.T, handler func(w http.ResponseWriter, n int)) *lineServer {
+
+	s := &lineServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, lineRequest{
+			path:          r.URL.Path,
+			contentType:   r.Header.Get("Content-Type"),
+			authorization: r.Header.Get("Authorization"),
+			form:          r.PostForm,
+		})
+		n := len(s.requests)
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(w, n)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":200,"message":"ok"}`))
+	}))
+
+	return s
+}
+
+func (s *lineServer) received() []lineRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]lineRequest(nil), s.requests...)
+}
+
+// Create a receiver sending to the stub, the token is read from the environment variable.
+func newReceiver(apiURL, token string) *config.Line {
+
+	name := "LINE_TOKEN_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	_ = os.Setenv(name, token)
+
+	l := config.NewLineReceiver().(*config.Line)
+	l.SetNamespace(testNamespace)
+	l.LineConfig = &config.LineConfig{
+		APIURL: apiURL,
+		Token:  &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://" + name}},
+	}
+
+	return l
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.LineOptions, receivers ...*config.Line) *Notifier {
+
+	c := testutil.NewConfig(nil, &v1alpha1.Options{Line: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewLineNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(names ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range names {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:   "firing",
+			Labels:   template.KV{"alertname": name},
+			StartsAt: time.Now(),
+		})
+	}
+
+	return data
+}
+
+func TestNotify(t *testing.T) {
+
+	s := newLineServer(t, nil)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "token1")
+	r.StickerPackageID = 446
+	r.StickerID = 1988
+	r.ImageFullsize = "https://example.com/full.png"
+	n := newNotifier(t, nil, r)
+
+	if errs := n.Notify(context.Background(), newData("alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests := s.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	req := requests[0]
+	if req.path != notifyPath {
+		t.Errorf("expected path %s, got %s", notifyPath, req.path)
+	}
+	if req.contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("expected the form encoded, got %s", req.contentType)
+	}
+	if req.authorization != "Bearer token1" {
+		t.Errorf("expected the bearer token, got %s", req.authorization)
+	}
+
+	want := map[string]string{
+		"message":          "[firing] alert1\n[firing] alert2",
+		"stickerPackageId": "446",
+		"stickerId":        "1988",
+		// The full size image is used as the thumbnail if the thumbnail is not set.
+		"imageThumbnail": "https://example.com/full.png",
+		"imageFullsize":  "https://example.com/full.png",
+	}
+	for k, v := range want {
+		if got := strings.TrimSpace(req.form.Get(k)); got != v {
+			t.Errorf("expected %s %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestNotifySplit(t *testing.T) {
+
+	s := newLineServer(t, nil)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "token1")
+	r.StickerPackageID = 446
+	r.StickerID = 1988
+	n := newNotifier(t, nil, r)
+
+	var names []string
+	for i := 0; i < 100; i++ {
+		names = append(names, fmt.Sprintf("alert-with-a-long-name-%03d", i))
+	}
+	if errs := n.Notify(context.Background(), newData(names...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests := s.received()
+	if len(requests) < 2 {
+		t.Fatalf("expected the message split, got %d requests", len(requests))
+	}
+
+	for i, req := range requests {
+		if size := len([]rune(req.form.Get("message"))); size > MessageMaxSize {
+			t.Errorf("message %d: expected at most %d characters, got %d", i, MessageMaxSize, size)
+		}
+
+		// The sticker is only sent with the first message.
+		if sticker := req.form.Get("stickerId"); (i == 0) != (sticker == "1988") {
+			t.Errorf("message %d: unexpected sticker %q", i, sticker)
+		}
+	}
+}
+
+func TestNotifyTokens(t *testing.T) {
+
+	s := newLineServer(t, nil)
+	defer s.Close()
+
+	// The receivers with the same token are sent once.
+	r1 := newReceiver(s.URL, "token1")
+	r2 := newReceiver(s.URL, "token2")
+	r2.LineConfig.Token = r1.LineConfig.Token
+	r3 := newReceiver(s.URL, "token3")
+	n := newNotifier(t, nil, r1, r2, r3)
+
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var tokens []string
+	for _, req := range s.received() {
+		tokens = append(tokens, req.authorization)
+	}
+	sort.Strings(tokens)
+
+	if want := "Bearer token1,Bearer token3"; strings.Join(tokens, ",") != want {
+		t.Errorf("expected the tokens %s, got %v", want, tokens)
+	}
+}
+
+func TestNotifyRateLimited(t *testing.T) {
+
+	// The first request is rate limited.
+	s := newLineServer(t, func(w http.ResponseWriter, n int) {
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"status":429,"message":"Too Many Requests"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":200,"message":"ok"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "token1"))
+
+	start := time.Now()
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.received()) != 2 {
+		t.Errorf("expected the message retried, got %d requests", len(s.received()))
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected waiting for the rate limit, returned after %s", elapsed)
+	}
+}
+
+func TestNotifyRateLimitedTooLong(t *testing.T) {
+
+	// The limit is reset after an hour.
+	s := newLineServer(t, func(w http.ResponseWriter, n int) {
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status":429,"message":"Too Many Requests"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "token1"))
+
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "code: 429") {
+		t.Fatalf("expected the rate limit error, got %v", errs)
+	}
+
+	if len(s.received()) != 1 {
+		t.Errorf("expected no retry, got %d requests", len(s.received()))
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+
+	s := newLineServer(t, func(w http.ResponseWriter, n int) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"status":401,"message":"Invalid access token"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "token1"))
+
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if want := "line error, code: 401, message: Invalid access token"; errs[0].Error() != want {
+		t.Errorf("expected %q, got %q", want, errs[0].Error())
+	}
+}
+
+func TestNotifyInvalidReceiver(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		modify func(l *config.Line)
+	}{
+		{"no token", func(l *config.Line) { l.LineConfig.Token = nil }},
+		{"sticker without package", func(l *config.Line) { l.StickerID = 1 }},
+		{"thumbnail without image", func(l *config.Line) { l.ImageThumbnail = "https://example.com/thumb.png" }},
+	}
+
+	for _, tt := range tests {
+		r := newReceiver("", "token1")
+		tt.modify(r)
+		if n := newNotifier(t, nil, r); len(n.line) != 0 {
+			t.Errorf("%s: expected the receiver ignored, got %d", tt.name, len(n.line))
+		}
+	}
+}
+
+func TestNewNotifierDefaultURL(t *testing.T) {
+
+	r := newReceiver("", "token1")
+
+	// The notifiers are created concurrently with the same receiver, the default api url is not written to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newNotifier(t, nil, r)
+			for _, l := range n.line {
+				if l.LineConfig.APIURL != DefaultApiURL {
+					t.Errorf("expected the api url %s, got %s", DefaultApiURL, l.LineConfig.APIURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(r.LineConfig.APIURL) != 0 {
+		t.Errorf("expected the receiver not changed, got %s", r.LineConfig.APIURL)
+	}
+}
@@ -0,0 +1,327 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The size of the event is limited to 65536 bytes, leave some space for the other fields.
+	MessageMaxSize = 32 * 1024
+	// The maximum times to retry when the request is rate limited.
+	DefaultMaxRetries = 3
+	// The waiting time when the request is rate limited but the response does not tell how long to wait.
+	DefaultRetryAfter = time.Second
+	messagePath       = "/_matrix/client/r0/rooms/%s/send/m.room.message/%s"
+	formatHTML        = "org.matrix.custom.html"
+	msgTypeText       = "m.text"
+)
+
+type Notifier struct {
+	notifierCfg      *config.Config
+	matrix           map[string]*config.Matrix
+	timeout          time.Duration
+	logger           log.Logger
+	template         *notifier.Template
+	templateName     string
+	decoration       *notifier.Decoration
+	htmlTemplateName string
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+type matrixResponse struct {
+	EventID      string `json:"event_id,omitempty"`
+	ErrCode      string `json:"errcode,omitempty"`
+	Error        string `json:"error,omitempty"`
+	RetryAfterMs int64  `json:"retry_after_ms,omitempty"`
+}
+
+func NewMatrixNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		matrix:       make(map[string]*config.Matrix),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+		decoration:   &notifier.Decoration{Header: header, Footer: footer},
+	}
+
+	if opts != nil && opts.Matrix != nil {
+
+		if opts.Matrix.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Matrix.NotificationTimeout)
+		}
+
+		if len(opts.Matrix.Template) > 0 {
+			n.templateName = opts.Matrix.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.Matrix.Header) > 0 {
+			n.decoration.Header = opts.Matrix.Header
+		}
+
+		if len(opts.Matrix.Footer) > 0 {
+			n.decoration.Footer = opts.Matrix.Footer
+		}
+
+		n.htmlTemplateName = opts.Matrix.HTMLTemplate
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Matrix)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "MatrixNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which use the same user will be merged.
+		key, err := notifier.Md5key(receiver.MatrixConfig)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "MatrixNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		m, ok := n.matrix[key]
+		if !ok {
+			c := *receiver
+			c.RoomIDs = nil
+			m = &c
+		}
+
+		for _, id := range receiver.RoomIDs {
+			if !contains(m.RoomIDs, id) {
+				m.RoomIDs = append(m.RoomIDs, id)
+			}
+		}
+
+		n.matrix[key] = m
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	messages, err := n.messages(data)
+	if err != nil {
+		return []error{err}
+	}
+
+	send := func(m *config.Matrix, roomID string, msg *matrixMessage) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "MatrixNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		token, err := n.notifierCfg.GetSecretData(m.GetNamespace(), m.MatrixConfig.AccessToken)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "MatrixNotifier: get access token secret", "error", err.Error())
+			return err
+		}
+
+		bs, err := json.Marshal(msg)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "MatrixNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		// The transaction id makes the retried request idempotent.
+		txnID, err := notifier.Md5key(fmt.Sprintf("%s%d", bs, start.UnixNano()))
+		if err != nil {
+			return err
+		}
+
+		u, err := messageURL(m.MatrixConfig.Homeserver, roomID, txnID)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "MatrixNotifier: set path error", "error", err)
+			return err
+		}
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		notifier.GetRetryBudget().Request()
+		for attempt := 0; ; attempt++ {
+			request, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(bs))
+			if err != nil {
+				return err
+			}
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			wait, err := n.do(ctx, request)
+			if err == nil {
+				_ = level.Debug(n.logger).Log("msg", "MatrixNotifier: send message", "room", roomID)
+				return nil
+			}
+
+			if wait == 0 || attempt >= DefaultMaxRetries {
+				_ = level.Error(n.logger).Log("msg", "MatrixNotifier: send message error", "room", roomID, "error", err.Error())
+				return err
+			}
+
+			// The failure is returned immediately if the retry budget shared by all notifiers is exhausted.
+			if !notifier.GetRetryBudget().AllowRetry() {
+				_ = level.Warn(n.logger).Log("msg", "MatrixNotifier: retry budget exhausted, stop retrying", "room", roomID, "attempt", attempt+1)
+				return err
+			}
+
+			_ = level.Debug(n.logger).Log("msg", "MatrixNotifier: rate limited, retry to send message", "room", roomID, "wait", wait.String())
+			if e := notifier.Sleep(ctx, wait); e != nil {
+				_ = level.Error(n.logger).Log("msg", "MatrixNotifier: stop retrying", "room", roomID, "error", e.Error())
+				return err
+			}
+		}
+	}
+
+	group := async.NewGroup(ctx)
+	for _, matrix := range n.matrix {
+		m := matrix
+		for _, id := range m.RoomIDs {
+			roomID := id
+			for _, msg := range messages {
+				mm := msg
+				group.Add(func(stopCh chan interface{}) {
+					stopCh <- send(m, roomID, mm)
+				})
+			}
+		}
+	}
+
+	return group.Wait()
+}
+
+// Return the url sending the message to the room, the room id is escaped in the path.
+func messageURL(homeserver, roomID, txnID string) (string, error) {
+
+	u, err := url.Parse(homeserver)
+	if err != nil {
+		return "", err
+	}
+
+	u.RawPath = u.EscapedPath() + fmt.Sprintf(messagePath, url.PathEscape(roomID), txnID)
+	u.Path = u.Path + fmt.Sprintf(messagePath, roomID, txnID)
+	return u.String(), nil
+}
+
+// Do the request, the duration returned is the time to wait before retrying if the request is rate limited.
+func (n *Notifier) do(ctx context.Context, request *http.Request) (time.Duration, error) {
+
+	_, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+	if err == nil {
+		return 0, nil
+	}
+
+	var he *notifier.HttpError
+	if !errors.As(err, &he) {
+		return 0, err
+	}
+
+	var mr matrixResponse
+	_ = json.Unmarshal([]byte(he.Message), &mr)
+	err = fmt.Errorf("matrix error, code: %d, errcode: %s, error: %s", he.StatusCode, mr.ErrCode, mr.Error)
+
+	if he.StatusCode != http.StatusTooManyRequests {
+		return 0, err
+	}
+
+	// The waiting time in the body is preferred, the Retry-After header is used if it is not set.
+	wait := DefaultRetryAfter
+	if mr.RetryAfterMs > 0 {
+		wait = time.Duration(mr.RetryAfterMs) * time.Millisecond
+	} else if d := notifier.RetryAfter(he); d > 0 {
+		wait = d
+	}
+
+	return wait, err
+}
+
+// Generate the messages. The formatted body is only added when the alerts can be sent in one message,
+// because the HTML can not be split in the same way as the plain text.
+func (n *Notifier) messages(data template.Data) ([]*matrixMessage, error) {
+
+	bodies, err := n.template.SplitWithDecoration(data, MessageMaxSize, n.templateName, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "MatrixNotifier: split message error", "error", err.Error())
+		return nil, err
+	}
+
+	var messages []*matrixMessage
+	for _, body := range bodies {
+		messages = append(messages, &matrixMessage{
+			MsgType: msgTypeText,
+			Body:    body,
+		})
+	}
+
+	if len(n.htmlTemplateName) == 0 || len(messages) != 1 {
+		return messages, nil
+	}
+
+	html, err := n.template.TempleText(n.htmlTemplateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "MatrixNotifier: generate formatted body error", "error", err.Error())
+		return nil, err
+	}
+
+	if notifier.Len(html)+notifier.Len(messages[0].Body) < MessageMaxSize {
+		messages[0].Format = formatHTML
+		messages[0].FormattedBody = html
+	}
+
+	return messages, nil
+}
+
+func contains(src []string, elem string) bool {
+	for _, s := range src {
+		if s == elem {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,369 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testNamespace = testutil.Namespace
+	roomPrefix    = "/_matrix/client/r0/rooms/"
+)
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A request received by the stub of the homeserver.
+type matrixRequest struct {
+	room          string
+	txnID         string
+	authorization string
+	message       matrixMessage
+}
+
+// A stub of the homeserver, it records the requests and responds with the handler.
+type homeserver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []matrixRequest
+}
+
+// Create the stub, it responds with the event id if the handler is nil.
+func newHomeserver(t *testing.T, handler func(w http.ResponseWriter, n int)) *homeserver {
+
+	s := &homeserver{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.URL.EscapedPath(), roomPrefix) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+
+		// The path is in the form of `<room>/send/m.room.message/<txn>`, the room is escaped.
+		parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), roomPrefix), "/")
+		req := matrixRequest{authorization: r.Header.Get("Authorization")}
+		if len(parts) == 4 {
+			room, err := url.PathUnescape(parts[0])
+			if err != nil {
+				t.Errorf("unescape room error, %s", err)
+			}
+			req.room, req.txnID = room, parts[3]
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req.message); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		n := len(s.requests)
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(w, n)
+			return
+		}
+		_, _ = w.Write([]byte(`{"event_id":"$1"}`))
+	}))
+
+	return s
+}
+
+func (s *homeserver) received() []matrixRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]matrixRequest(nil), s.requests...)
+}
+
+func rateLimited(w http.ResponseWriter, body string) {
+	w.WriteHeader(http.StatusTooManyRequests)
+	_, _ = w.Write([]byte(body))
+}
+
+// Create a receiver sending to the stub, the access token is read from the secret.
+func newReceiver(t *testing.T, homeserver, token string, rooms ...string) *config.Matrix {
+
+	secret := secrets.NewSecret(t, token)
+
+	m := config.NewMatrixReceiver().(*config.Matrix)
+	m.SetNamespace(testNamespace)
+	m.RoomIDs = rooms
+	m.MatrixConfig = &config.MatrixConfig{
+		Homeserver:  homeserver,
+		AccessToken: secret,
+	}
+
+	return m
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.MatrixOptions, receivers ...*config.Matrix) *Notifier {
+
+	c := testutil.NewConfig(secrets, &v1alpha1.Options{Matrix: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewMatrixNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(names ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range names {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status: "firing",
+			Labels: template.KV{"alertname": name},
+		})
+	}
+
+	return data
+}
+
+func TestNotifyRooms(t *testing.T) {
+
+	s := newHomeserver(t, nil)
+	defer s.Close()
+
+	// The receivers with the same user are merged, the message is sent to each room once.
+	r1 := newReceiver(t, s.URL, "token1", "!a:matrix.test", "!b:matrix.test")
+	r2 := newReceiver(t, s.URL, "token2", "!b:matrix.test", "!c d:matrix.test")
+	r2.MatrixConfig.AccessToken = r1.MatrixConfig.AccessToken
+	n := newNotifier(t, nil, r1, r2)
+
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var rooms []string
+	for _, req := range s.received() {
+		rooms = append(rooms, req.room)
+		if req.authorization != "Bearer token1" {
+			t.Errorf("%s: expected the access token, got %s", req.room, req.authorization)
+		}
+		if req.message.MsgType != msgTypeText || strings.TrimSpace(req.message.Body) != "[firing] alert1" {
+			t.Errorf("%s: expected the text message, got %v", req.room, req.message)
+		}
+		if len(req.txnID) == 0 {
+			t.Errorf("%s: expected the transaction id", req.room)
+		}
+	}
+	sort.Strings(rooms)
+
+	// The room id is escaped once.
+	if expected := []string{"!a:matrix.test", "!b:matrix.test", "!c d:matrix.test"}; !reflect.DeepEqual(rooms, expected) {
+		t.Errorf("expected the rooms %v, got %v", expected, rooms)
+	}
+}
+
+func TestNotifyRateLimited(t *testing.T) {
+
+	tests := []struct {
+		name string
+		// The response of the first request.
+		handler func(w http.ResponseWriter)
+		wait    time.Duration
+	}{
+		{"retry after ms", func(w http.ResponseWriter) {
+			rateLimited(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":300}`)
+		}, time.Millisecond * 300},
+		{"retry after header", func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "1")
+			rateLimited(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests"}`)
+		}, time.Second},
+		// The time in the body is preferred.
+		{"both", func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "10")
+			rateLimited(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":100}`)
+		}, time.Millisecond * 100},
+	}
+
+	for _, test := range tests {
+		s := newHomeserver(t, func(w http.ResponseWriter, n int) {
+			if n == 1 {
+				test.handler(w)
+				return
+			}
+			_, _ = w.Write([]byte(`{"event_id":"$1"}`))
+		})
+
+		n := newNotifier(t, nil, newReceiver(t, s.URL, "token1", "!a:matrix.test"))
+
+		start := time.Now()
+		errs := n.Notify(context.Background(), newData("alert1"))
+		elapsed := time.Since(start)
+		requests := s.received()
+		s.Close()
+
+		if len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", test.name, errs)
+		}
+		if elapsed < test.wait || elapsed > test.wait+time.Second*2 {
+			t.Errorf("%s: expected waiting %s, returned after %s", test.name, test.wait, elapsed)
+		}
+
+		// The retried request uses the same transaction id, so the message is not sent twice.
+		if len(requests) != 2 || requests[0].txnID != requests[1].txnID {
+			t.Errorf("%s: expected the message retried with the same transaction, got %v", test.name, requests)
+		}
+	}
+}
+
+func TestNotifyRateLimitedMaxRetries(t *testing.T) {
+
+	s := newHomeserver(t, func(w http.ResponseWriter, n int) {
+		rateLimited(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":10}`)
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "token1", "!a:matrix.test"))
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "code: 429, errcode: M_LIMIT_EXCEEDED") {
+		t.Errorf("expected the rate limit error, got %v", errs)
+	}
+
+	if l := len(s.received()); l != DefaultMaxRetries+1 {
+		t.Errorf("expected %d requests, got %d", DefaultMaxRetries+1, l)
+	}
+}
+
+func TestNotifyRateLimitedDeadline(t *testing.T) {
+
+	s := newHomeserver(t, func(w http.ResponseWriter, n int) {
+		rateLimited(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":10000}`)
+	})
+	defer s.Close()
+
+	// The message is not retried if the waiting exceeds the deadline of the notification.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "token1", "!a:matrix.test"))
+	start := time.Now()
+	if errs := n.Notify(ctx, newData("alert1")); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("expected returning without waiting, returned after %s", elapsed)
+	}
+	if l := len(s.received()); l != 1 {
+		t.Errorf("expected 1 request, got %d", l)
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+
+	s := newHomeserver(t, func(w http.ResponseWriter, n int) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"User is not in the room"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "token1", "!a:matrix.test"))
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	// The error is not retried.
+	if want := "matrix error, code: 403, errcode: M_FORBIDDEN, error: User is not in the room"; errs[0].Error() != want {
+		t.Errorf("expected %q, got %q", want, errs[0].Error())
+	}
+	if l := len(s.received()); l != 1 {
+		t.Errorf("expected 1 request, got %d", l)
+	}
+}
+
+func TestNotifyFormattedBody(t *testing.T) {
+
+	// The alerts with the names of 100 characters.
+	names := func(count int) []string {
+		var names []string
+		for i := 0; i < count; i++ {
+			names = append(names, fmt.Sprintf("%0100d", i))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		alerts    []string
+		messages  int
+		formatted bool
+	}{
+		{"formatted", []string{"alert1", "alert2"}, 1, true},
+		// The HTML and the plain text can not be sent in one message.
+		{"too long", names(200), 1, false},
+		// The HTML can not be split, so it is not sent with the split messages.
+		{"split", names(400), 2, false},
+	}
+
+	for _, test := range tests {
+		s := newHomeserver(t, nil)
+
+		n := newNotifier(t, &v1alpha1.MatrixOptions{HTMLTemplate: "nm.matrix.html"}, newReceiver(t, s.URL, "token1", "!a:matrix.test"))
+		if errs := n.Notify(context.Background(), newData(test.alerts...)); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", test.name, errs)
+		}
+
+		requests := s.received()
+		s.Close()
+		if len(requests) != test.messages {
+			t.Errorf("%s: expected %d messages, got %d", test.name, test.messages, len(requests))
+			continue
+		}
+
+		for _, req := range requests {
+			msg := req.message
+			if len(msg.Body) == 0 {
+				t.Errorf("%s: expected the plain text body", test.name)
+			}
+
+			if !test.formatted {
+				if len(msg.Format) != 0 || len(msg.FormattedBody) != 0 {
+					t.Errorf("%s: expected no formatted body, got %s", test.name, msg.Format)
+				}
+				continue
+			}
+
+			if msg.Format != formatHTML || msg.FormattedBody != "<p>alert1</p><p>alert2</p>" {
+				t.Errorf("%s: expected the formatted body, got %s %q", test.name, msg.Format, msg.FormattedBody)
+			}
+		}
+	}
+}
+
+func TestNotifyNoFormattedBody(t *testing.T) {
+
+	s := newHomeserver(t, nil)
+	defer s.Close()
+
+	// The formatted body is not sent without the HTML template.
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "token1", "!a:matrix.test"))
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if requests := s.received(); len(requests) != 1 || len(requests[0].message.FormattedBody) != 0 {
+		t.Errorf("expected the message without the formatted body, got %v", requests)
+	}
+}
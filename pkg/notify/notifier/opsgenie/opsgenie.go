@@ -0,0 +1,355 @@
+package opsgenie
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultApiURL      = "https://api.opsgenie.com"
+	// The limits of the fields of the alert.
+	MessageMaxSize     = 130
+	DescriptionMaxSize = 15000
+	AliasMaxSize       = 512
+	TagMaxSize         = 50
+	TagsMaxCount       = 20
+	DetailKeyMaxSize   = 8000
+	DetailValueMaxSize = 8000
+	alertsPath         = "/v2/alerts"
+	statusResolved     = "resolved"
+	source             = "notification-manager"
+)
+
+type Notifier struct {
+	notifierCfg         *config.Config
+	opsgenie            map[string]*config.Opsgenie
+	timeout             time.Duration
+	logger              log.Logger
+	template            *notifier.Template
+	templateName        string
+	descriptionTemplate string
+}
+
+type opsgenieResponder struct {
+	Type     string `json:"type"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type opsgenieCreateMessage struct {
+	Alias       string               `json:"alias"`
+	Message     string               `json:"message"`
+	Description string               `json:"description,omitempty"`
+	Responders  []*opsgenieResponder `json:"responders,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Details     map[string]string    `json:"details,omitempty"`
+	Source      string               `json:"source,omitempty"`
+}
+
+type opsgenieCloseMessage struct {
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+type opsgenieResponse struct {
+	Result    string `json:"result"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+func NewOpsgenieNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg: notifierCfg,
+		opsgenie:    make(map[string]*config.Opsgenie),
+		timeout:     DefaultSendTimeout,
+		logger:      logger,
+		template:    tmpl,
+	}
+
+	if opts != nil && opts.Opsgenie != nil {
+
+		if opts.Opsgenie.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Opsgenie.NotificationTimeout)
+		}
+
+		// The global template is not used, because the message is generated for each alert.
+		if len(opts.Opsgenie.Template) > 0 {
+			n.templateName = opts.Opsgenie.Template
+		}
+
+		if len(opts.Opsgenie.DescriptionTemplate) > 0 {
+			n.descriptionTemplate = opts.Opsgenie.DescriptionTemplate
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Opsgenie)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "OpsgenieNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receiver is shared by the notifications, so the default api url is set in a copy of it.
+		c := *receiver.OpsgenieConfig
+		if len(c.APIURL) == 0 {
+			c.APIURL = DefaultApiURL
+		}
+		o := *receiver
+		o.OpsgenieConfig = &c
+
+		// The receivers which use the same integration, responders and tags only need to be sent once.
+		key, err := notifier.Md5key(struct {
+			Config     *config.OpsgenieConfig
+			Responders []v1alpha1.OpsgenieResponder
+			Tags       []string
+		}{o.OpsgenieConfig, o.Responders, o.Tags})
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "OpsgenieNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.opsgenie[key] = &o
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	send := func(o *config.Opsgenie, alert template.Alert) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "OpsgenieNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		apiKey, err := n.notifierCfg.GetSecretData(o.GetNamespace(), o.OpsgenieConfig.APIKey)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "OpsgenieNotifier: get api key secret", "error", err.Error())
+			return err
+		}
+
+		alias, err := getAlias(alert)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "OpsgenieNotifier: get alias error", "error", err.Error())
+			return err
+		}
+
+		// The firing alert creates an Opsgenie alert, and the resolved alert closes the Opsgenie alert with the same alias.
+		var u string
+		var msg interface{}
+		if alert.Status == statusResolved {
+			u, err = closeURL(o.OpsgenieConfig.APIURL, alias)
+			if err != nil {
+				return err
+			}
+
+			u, err = notifier.UrlWithParameters(u, map[string]string{"identifierType": "alias"})
+			if err != nil {
+				return err
+			}
+
+			msg = &opsgenieCloseMessage{
+				Source: source,
+				Note:   "The alert is resolved.",
+			}
+		} else {
+			u, err = notifier.UrlWithPath(o.OpsgenieConfig.APIURL, alertsPath)
+			if err != nil {
+				return err
+			}
+
+			msg, err = n.newCreateMessage(o, data, alert, alias)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "OpsgenieNotifier: generate message error", "error", err.Error())
+				return err
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(msg); err != nil {
+			_ = level.Error(n.logger).Log("msg", "OpsgenieNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, u, &buf)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "GenieKey "+apiKey)
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		// The Alert API responds 202 when the request is accepted, the request is processed asynchronously.
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "OpsgenieNotifier: do http error", "error", err)
+			return err
+		}
+
+		var resp opsgenieResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			_ = level.Error(n.logger).Log("msg", "OpsgenieNotifier: decode response body error", "error", err)
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "OpsgenieNotifier: send message", "alias", alias, "requestId", resp.RequestID)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, opsgenie := range n.opsgenie {
+		o := opsgenie
+		for _, a := range data.Alerts {
+			alert := a
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(o, alert)
+			})
+		}
+	}
+
+	return group.Wait()
+}
+
+// Return the url closing the alert of the alias, the alias is escaped in the path, so it can contain the slashes.
+func closeURL(apiURL, alias string) (string, error) {
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.RawPath = u.EscapedPath() + alertsPath + "/" + url.PathEscape(alias) + "/close"
+	u.Path = u.Path + alertsPath + "/" + alias + "/close"
+	return u.String(), nil
+}
+
+// The alert fingerprint is used as the alias, so the resolved alert can close the alert created by the firing alert.
+func getAlias(alert template.Alert) (string, error) {
+
+	if len(alert.Fingerprint) > 0 {
+		return truncate(alert.Fingerprint, AliasMaxSize), nil
+	}
+
+	return notifier.Md5key(alert.Labels)
+}
+
+func (n *Notifier) newCreateMessage(o *config.Opsgenie, data template.Data, alert template.Alert, alias string) (*opsgenieCreateMessage, error) {
+
+	d := template.Data{
+		Receiver:    data.Receiver,
+		GroupLabels: data.GroupLabels,
+		Alerts:      template.Alerts{alert},
+	}
+
+	message := fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Status), alert.Labels["alertname"])
+	if len(n.templateName) > 0 {
+		s, err := n.template.TempleText(n.templateName, d, n.logger)
+		if err != nil {
+			return nil, err
+		}
+		message = s
+	}
+
+	var description string
+	if len(n.descriptionTemplate) > 0 {
+		s, err := n.template.TempleText(n.descriptionTemplate, d, n.logger)
+		if err != nil {
+			return nil, err
+		}
+		description = s
+	} else {
+		for _, name := range []string{"message", "summary", "description"} {
+			if v := alert.Annotations[name]; len(v) > 0 {
+				description = v
+				break
+			}
+		}
+	}
+
+	details := make(map[string]string)
+	for k, v := range alert.Labels {
+		details[truncate(k, DetailKeyMaxSize)] = truncate(v, DetailValueMaxSize)
+	}
+
+	var tags []string
+	for _, t := range o.Tags {
+		if len(tags) >= TagsMaxCount {
+			break
+		}
+		tags = append(tags, truncate(t, TagMaxSize))
+	}
+
+	var responders []*opsgenieResponder
+	for _, r := range o.Responders {
+		responder := &opsgenieResponder{
+			Type: r.Type,
+			ID:   r.ID,
+		}
+
+		// The user responder is identified by the username.
+		if r.Type == "user" {
+			responder.Username = r.Name
+		} else {
+			responder.Name = r.Name
+		}
+		responders = append(responders, responder)
+	}
+
+	return &opsgenieCreateMessage{
+		Alias:       alias,
+		Message:     truncate(message, MessageMaxSize),
+		Description: truncate(description, DescriptionMaxSize),
+		Responders:  responders,
+		Tags:        tags,
+		Details:     details,
+		Source:      source,
+	}, nil
+}
+
+// Truncate the string to the max size, the string is cut on the rune boundary.
+func truncate(s string, max int) string {
+
+	rs := []rune(s)
+	if len(rs) <= max {
+		return s
+	}
+
+	return string(rs[:max])
+}
@@ -0,0 +1,397 @@
+package opsgenie
+
+import (
+	"context"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A request received by the stub of the Alert API.
+type alertRequest struct {
+	path          string
+	query         string
+	authorization string
+	body          []byte
+}
+
+// A stub of the Alert API, it records the requests and responds with the handler.
+type alertServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []alertRequest
+}
+
+// Create the stub, it accepts the requests with 202 if the handler is nil.
+func newAlertServer(t *testing.T, handler func(w http.ResponseWriter)) *alertServer {
+
+	s := &alertServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, alertRequest{
+			path:          r.URL.EscapedPath(),
+			query:         r.URL.RawQuery,
+			authorization: r.Header.Get("Authorization"),
+			body:          body,
+		})
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(w)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"result":"Request will be processed","took":0.1,"requestId":"1"}`))
+	}))
+
+	return s
+}
+
+// Return the requests received, they are sorted by the path.
+func (s *alertServer) received() []alertRequest {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := append([]alertRequest(nil), s.requests...)
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].path < requests[j].path
+	})
+	return requests
+}
+
+// Create a receiver sending to the stub, the api key is read from the secret.
+func newReceiver(t *testing.T, apiURL, apiKey string) *config.Opsgenie {
+
+	secret := secrets.NewSecret(t, apiKey)
+
+	o := config.NewOpsgenieReceiver().(*config.Opsgenie)
+	o.SetNamespace(testNamespace)
+	o.OpsgenieConfig = &config.OpsgenieConfig{
+		APIURL: apiURL,
+		APIKey: secret,
+	}
+
+	return o
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.OpsgenieOptions, receivers ...*config.Opsgenie) *Notifier {
+
+	c := testutil.NewConfig(secrets, &v1alpha1.Options{Opsgenie: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewOpsgenieNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newAlert(status, fingerprint, name string) template.Alert {
+	return template.Alert{
+		Status:      status,
+		Fingerprint: fingerprint,
+		Labels:      template.KV{"alertname": name},
+		Annotations: template.KV{},
+	}
+}
+
+func newData(alerts ...template.Alert) template.Data {
+	return template.Data{Receiver: "test", Status: "firing", Alerts: alerts}
+}
+
+func TestNotifyCreateClose(t *testing.T) {
+
+	s := newAlertServer(t, nil)
+	defer s.Close()
+
+	firing := newAlert("firing", "fp1", "alert1")
+	firing.Annotations["summary"] = "cpu is high"
+	// The alias is escaped in the path of the close request.
+	resolved := newAlert("resolved", "fp/2", "alert2")
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "api-key"))
+	if errs := n.Notify(context.Background(), newData(firing, resolved)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests := s.received()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	for _, req := range requests {
+		if req.authorization != "GenieKey api-key" {
+			t.Errorf("%s: expected the GenieKey header, got %q", req.path, req.authorization)
+		}
+	}
+
+	create, close := requests[0], requests[1]
+	if create.path != alertsPath || len(create.query) != 0 {
+		t.Errorf("expected the firing alert created, got %s?%s", create.path, create.query)
+	}
+
+	// The resolved alert closes the alert created with the same alias.
+	if close.path != alertsPath+"/fp%2F2/close" || close.query != "identifierType=alias" {
+		t.Errorf("expected the resolved alert closed by the alias, got %s?%s", close.path, close.query)
+	}
+
+	var msg opsgenieCreateMessage
+	if err := json.Unmarshal(create.body, &msg); err != nil {
+		t.Fatalf("decode create message error, %s", err)
+	}
+	if msg.Alias != "fp1" || msg.Message != "[FIRING] alert1" || msg.Description != "cpu is high" || msg.Source != source {
+		t.Errorf("expected the message of alert1, got %v", msg)
+	}
+	if msg.Details["alertname"] != "alert1" {
+		t.Errorf("expected the labels in the details, got %v", msg.Details)
+	}
+
+	var closeMsg opsgenieCloseMessage
+	if err := json.Unmarshal(close.body, &closeMsg); err != nil || closeMsg.Source != source {
+		t.Errorf("expected the close message, got %v, %v", closeMsg, err)
+	}
+}
+
+func TestCloseURL(t *testing.T) {
+
+	tests := []struct {
+		apiURL   string
+		alias    string
+		expected string
+	}{
+		{"https://api.opsgenie.com", "fp1", "https://api.opsgenie.com/v2/alerts/fp1/close"},
+		{"https://opsgenie.test/proxy", "fp1", "https://opsgenie.test/proxy/v2/alerts/fp1/close"},
+		// The alias is escaped once.
+		{"https://api.opsgenie.com", "fp/1 2", "https://api.opsgenie.com/v2/alerts/fp%2F1%202/close"},
+	}
+
+	for _, test := range tests {
+		got, err := closeURL(test.apiURL, test.alias)
+		if err != nil || got != test.expected {
+			t.Errorf("%s %s: expected %s, got %s, %v", test.apiURL, test.alias, test.expected, got, err)
+		}
+	}
+}
+
+func TestNotifyAlias(t *testing.T) {
+
+	s := newAlertServer(t, nil)
+	defer s.Close()
+
+	// The alerts without the fingerprint use the key of the labels as the alias.
+	alert := newAlert("firing", "", "alert1")
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "api-key"))
+	if errs := n.Notify(context.Background(), newData(alert)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	expected, err := notifier.Md5key(alert.Labels)
+	if err != nil {
+		t.Fatalf("get key error, %s", err)
+	}
+
+	requests := s.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	var msg opsgenieCreateMessage
+	if err := json.Unmarshal(requests[0].body, &msg); err != nil || msg.Alias != expected {
+		t.Errorf("expected the alias %s, got %s, %v", expected, msg.Alias, err)
+	}
+}
+
+func TestNotifyRespondersTags(t *testing.T) {
+
+	s := newAlertServer(t, nil)
+	defer s.Close()
+
+	r := newReceiver(t, s.URL, "api-key")
+	r.Responders = []v1alpha1.OpsgenieResponder{
+		{Type: "team", Name: "ops"},
+		{Type: "user", Name: "admin@example.com"},
+		{Type: "schedule", ID: "4513b7ea"},
+	}
+	for i := 0; i < TagsMaxCount+5; i++ {
+		r.Tags = append(r.Tags, "tag"+strconv.Itoa(i))
+	}
+	r.Tags[0] = strings.Repeat("t", TagMaxSize+10)
+
+	n := newNotifier(t, nil, r)
+	if errs := n.Notify(context.Background(), newData(newAlert("firing", "fp1", "alert1"))); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests := s.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	var msg opsgenieCreateMessage
+	if err := json.Unmarshal(requests[0].body, &msg); err != nil {
+		t.Fatalf("decode create message error, %s", err)
+	}
+
+	// The user responder is identified by the username.
+	expected := []*opsgenieResponder{
+		{Type: "team", Name: "ops"},
+		{Type: "user", Username: "admin@example.com"},
+		{Type: "schedule", ID: "4513b7ea"},
+	}
+	if !reflect.DeepEqual(msg.Responders, expected) {
+		t.Errorf("expected the responders %v, got %v", expected, msg.Responders)
+	}
+
+	// The tags are limited in count and size.
+	if len(msg.Tags) != TagsMaxCount || len(msg.Tags[0]) != TagMaxSize || msg.Tags[1] != "tag1" {
+		t.Errorf("expected %d tags truncated to %d, got %v", TagsMaxCount, TagMaxSize, msg.Tags)
+	}
+}
+
+func TestNotifyTemplates(t *testing.T) {
+
+	s := newAlertServer(t, nil)
+	defer s.Close()
+
+	opts := &v1alpha1.OpsgenieOptions{Template: "nm.default.text", DescriptionTemplate: "nm.default.subject"}
+	n := newNotifier(t, opts, newReceiver(t, s.URL, "api-key"))
+	if errs := n.Notify(context.Background(), newData(newAlert("firing", "fp1", "alert1"))); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests := s.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	// The templates are rendered with each alert.
+	var msg opsgenieCreateMessage
+	if err := json.Unmarshal(requests[0].body, &msg); err != nil {
+		t.Fatalf("decode create message error, %s", err)
+	}
+	if strings.TrimSpace(msg.Message) != "[firing] alert1" || msg.Description != "1 alerts firing" {
+		t.Errorf("expected the message and description from the templates, got %q %q", msg.Message, msg.Description)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+
+	tests := []struct {
+		s        string
+		max      int
+		expected string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello"},
+		// The string is cut on the rune boundary.
+		{"告警恢复了", 2, "告警"},
+		{"a告警", 2, "a告"},
+	}
+
+	for _, test := range tests {
+		if got := truncate(test.s, test.max); got != test.expected {
+			t.Errorf("%q %d: expected %q, got %q", test.s, test.max, test.expected, got)
+		}
+	}
+}
+
+func TestNotifyTruncate(t *testing.T) {
+
+	s := newAlertServer(t, nil)
+	defer s.Close()
+
+	alert := newAlert("firing", "fp1", strings.Repeat("告", MessageMaxSize))
+	alert.Annotations["message"] = strings.Repeat("警", DescriptionMaxSize+1)
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "api-key"))
+	if errs := n.Notify(context.Background(), newData(alert)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests := s.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	var msg opsgenieCreateMessage
+	if err := json.Unmarshal(requests[0].body, &msg); err != nil {
+		t.Fatalf("decode create message error, %s", err)
+	}
+
+	if n := utf8.RuneCountInString(msg.Message); n != MessageMaxSize || !utf8.ValidString(msg.Message) {
+		t.Errorf("expected the message truncated to %d characters, got %d", MessageMaxSize, n)
+	}
+	if n := utf8.RuneCountInString(msg.Description); n != DescriptionMaxSize || !utf8.ValidString(msg.Description) {
+		t.Errorf("expected the description truncated to %d characters, got %d", DescriptionMaxSize, n)
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+
+	s := newAlertServer(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Key format is not valid!","took":0.0,"requestId":"1"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "api-key"))
+	if errs := n.Notify(context.Background(), newData(newAlert("firing", "fp1", "alert1"))); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+}
+
+func TestNewNotifierDefaultURL(t *testing.T) {
+
+	r := newReceiver(t, "", "api-key")
+
+	// The notifiers are created concurrently with the same receiver, the default api url is not written to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newNotifier(t, nil, r)
+			for _, o := range n.opsgenie {
+				if o.OpsgenieConfig.APIURL != DefaultApiURL {
+					t.Errorf("expected the api url %s, got %s", DefaultApiURL, o.OpsgenieConfig.APIURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(r.OpsgenieConfig.APIURL) != 0 {
+		t.Errorf("expected the receiver not changed, got %s", r.OpsgenieConfig.APIURL)
+	}
+}
@@ -0,0 +1,322 @@
+package pagerduty
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout   = time.Second * 3
+	DefaultApiURL        = "https://events.pagerduty.com/v2/enqueue"
+	DefaultSeverityLabel = "severity"
+	DefaultSeverity      = "error"
+	DefaultSource        = "notification-manager"
+	// The maximum length of the summary.
+	SummaryMaxSize = 1024
+	actionTrigger  = "trigger"
+	actionResolve  = "resolve"
+	statusResolved = "resolved"
+)
+
+type Notifier struct {
+	notifierCfg   *config.Config
+	pagerduty     map[string]*config.PagerDuty
+	timeout       time.Duration
+	logger        log.Logger
+	template      *notifier.Template
+	templateName  string
+	severityLabel string
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []*pagerDutyLink  `json:"links,omitempty"`
+}
+
+type pagerDutyResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	DedupKey string `json:"dedup_key"`
+}
+
+func NewPagerDutyNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:   notifierCfg,
+		pagerduty:     make(map[string]*config.PagerDuty),
+		timeout:       DefaultSendTimeout,
+		logger:        logger,
+		template:      tmpl,
+		severityLabel: DefaultSeverityLabel,
+	}
+
+	if opts != nil && opts.PagerDuty != nil {
+
+		if opts.PagerDuty.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.PagerDuty.NotificationTimeout)
+		}
+
+		// The global template is not used, because the summary is generated for each alert.
+		if len(opts.PagerDuty.Template) > 0 {
+			n.templateName = opts.PagerDuty.Template
+		}
+
+		if len(opts.PagerDuty.SeverityLabel) > 0 {
+			n.severityLabel = opts.PagerDuty.SeverityLabel
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.PagerDuty)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "PagerDutyNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receiver is shared by the notifications, so the default api url is set in a copy of it.
+		c := *receiver.PagerDutyConfig
+		if len(c.APIURL) == 0 {
+			c.APIURL = DefaultApiURL
+		}
+		p := *receiver
+		p.PagerDutyConfig = &c
+
+		// The receivers which use the same service only need to be sent once.
+		key, err := notifier.Md5key(p.PagerDutyConfig)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "PagerDutyNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.pagerduty[key] = &p
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	send := func(p *config.PagerDuty, alert template.Alert) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "PagerDutyNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		routingKey, err := n.notifierCfg.GetSecretData(p.GetNamespace(), p.PagerDutyConfig.RoutingKey)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "PagerDutyNotifier: get routing key secret", "error", err.Error())
+			return err
+		}
+
+		event, err := n.newEvent(data, alert)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "PagerDutyNotifier: generate event error", "error", err.Error())
+			return err
+		}
+		event.RoutingKey = routingKey
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(event); err != nil {
+			_ = level.Error(n.logger).Log("msg", "PagerDutyNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, p.PagerDutyConfig.APIURL, &buf)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		// The Events API responds 202 when the event is accepted.
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "PagerDutyNotifier: do http error", "error", err)
+			return err
+		}
+
+		var resp pagerDutyResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			_ = level.Error(n.logger).Log("msg", "PagerDutyNotifier: decode response body error", "error", err)
+			return err
+		}
+
+		if resp.Status != "success" {
+			_ = level.Error(n.logger).Log("msg", "PagerDutyNotifier: pagerduty error", "status", resp.Status, "message", resp.Message)
+			return fmt.Errorf("%s: %s", resp.Status, resp.Message)
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "PagerDutyNotifier: send message", "action", event.EventAction, "dedupKey", resp.DedupKey)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, pagerduty := range n.pagerduty {
+		p := pagerduty
+		for _, a := range data.Alerts {
+			alert := a
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(p, alert)
+			})
+		}
+	}
+
+	return group.Wait()
+}
+
+// Generate the event of the alert, the alert fingerprint is used as the dedup key,
+// so the resolve event can be correlated with the trigger event.
+func (n *Notifier) newEvent(data template.Data, alert template.Alert) (*pagerDutyEvent, error) {
+
+	dedupKey := alert.Fingerprint
+	if len(dedupKey) == 0 {
+		key, err := notifier.Md5key(alert.Labels)
+		if err != nil {
+			return nil, err
+		}
+		dedupKey = key
+	}
+
+	event := &pagerDutyEvent{
+		EventAction: actionTrigger,
+		DedupKey:    dedupKey,
+	}
+
+	// The payload is not required by the resolve event.
+	if alert.Status == statusResolved {
+		event.EventAction = actionResolve
+		return event, nil
+	}
+
+	summary, err := n.summary(data, alert)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make(map[string]string)
+	for k, v := range alert.Labels {
+		details[k] = v
+	}
+	for k, v := range alert.Annotations {
+		details[k] = v
+	}
+
+	source := DefaultSource
+	if v := alert.Labels["instance"]; len(v) > 0 {
+		source = v
+	}
+
+	event.Payload = &pagerDutyPayload{
+		Summary:       summary,
+		Source:        source,
+		Severity:      severity(alert.Labels[n.severityLabel]),
+		CustomDetails: details,
+	}
+
+	if !alert.StartsAt.IsZero() {
+		event.Payload.Timestamp = alert.StartsAt.Format(time.RFC3339)
+	}
+
+	if len(alert.GeneratorURL) > 0 {
+		event.Links = append(event.Links, &pagerDutyLink{Href: alert.GeneratorURL, Text: "Source"})
+	}
+
+	return event, nil
+}
+
+func (n *Notifier) summary(data template.Data, alert template.Alert) (string, error) {
+
+	var summary string
+	if len(n.templateName) > 0 {
+		d := template.Data{
+			Receiver:    data.Receiver,
+			GroupLabels: data.GroupLabels,
+			Alerts:      template.Alerts{alert},
+		}
+
+		s, err := n.template.TempleText(n.templateName, d, n.logger)
+		if err != nil {
+			return "", err
+		}
+		summary = s
+	} else {
+		summary = fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Status), alert.Labels["alertname"])
+		for _, name := range []string{"message", "summary", "description"} {
+			if v := alert.Annotations[name]; len(v) > 0 {
+				summary = fmt.Sprintf("%s: %s", summary, v)
+				break
+			}
+		}
+	}
+
+	if rs := []rune(summary); len(rs) > SummaryMaxSize {
+		summary = string(rs[:SummaryMaxSize])
+	}
+
+	return summary, nil
+}
+
+// Map the severity of the alert to the severity of PagerDuty, which is one of critical, error, warning and info.
+func severity(s string) string {
+
+	switch strings.ToLower(s) {
+	case "critical", "fatal", "emergency", "page":
+		return "critical"
+	case "error", "major":
+		return "error"
+	case "warning", "minor":
+		return "warning"
+	case "info", "none", "low":
+		return "info"
+	default:
+		return DefaultSeverity
+	}
+}
@@ -0,0 +1,362 @@
+package pagerduty
+
+import (
+	"context"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A stub of the Events API, it records the events and responds with the handler.
+type eventsServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []pagerDutyEvent
+}
+
+// Create the stub, it accepts the events with 202 if the handler is nil.
+func newEventsServer(t *testing.T, handler func(w http.ResponseWriter)) *eventsServer {
+
+	s := &eventsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode event error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.events = append(s.events, event)
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(w)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success","message":"Event processed","dedup_key":"` + event.DedupKey + `"}`))
+	}))
+
+	return s
+}
+
+// Return the events received, they are sorted by the dedup key.
+func (s *eventsServer) received() []pagerDutyEvent {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := append([]pagerDutyEvent(nil), s.events...)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].DedupKey < events[j].DedupKey
+	})
+	return events
+}
+
+// Create a receiver sending to the stub, the routing key is read from the secret.
+func newReceiver(t *testing.T, apiURL, routingKey string) *config.PagerDuty {
+
+	secret := secrets.NewSecret(t, routingKey)
+
+	p := config.NewPagerDutyReceiver().(*config.PagerDuty)
+	p.SetNamespace(testNamespace)
+	p.PagerDutyConfig = &config.PagerDutyConfig{
+		APIURL:     apiURL,
+		RoutingKey: secret,
+	}
+
+	return p
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.PagerDutyOptions, receivers ...*config.PagerDuty) *Notifier {
+
+	c := testutil.NewConfig(secrets, &v1alpha1.Options{PagerDuty: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewPagerDutyNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newAlert(status, fingerprint, name string) template.Alert {
+	return template.Alert{
+		Status:      status,
+		Fingerprint: fingerprint,
+		Labels:      template.KV{"alertname": name},
+		Annotations: template.KV{},
+	}
+}
+
+func newData(alerts ...template.Alert) template.Data {
+	return template.Data{Receiver: "test", Status: "firing", Alerts: alerts}
+}
+
+func TestNotifyTriggerResolve(t *testing.T) {
+
+	s := newEventsServer(t, nil)
+	defer s.Close()
+
+	firing := newAlert("firing", "fp1", "alert1")
+	firing.Labels["severity"] = "critical"
+	firing.Labels["instance"] = "host1"
+	firing.Annotations["summary"] = "cpu is high"
+	firing.StartsAt = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	firing.GeneratorURL = "https://prometheus.test/graph"
+	resolved := newAlert("resolved", "fp2", "alert2")
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "routing-key"))
+	if errs := n.Notify(context.Background(), newData(firing, resolved)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	events := s.received()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	// The alert fingerprint is used as the dedup key, so the events of the alert are correlated.
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != actionTrigger || trigger.DedupKey != "fp1" || trigger.RoutingKey != "routing-key" {
+		t.Errorf("expected the trigger event of fp1, got %s %s %s", trigger.EventAction, trigger.DedupKey, trigger.RoutingKey)
+	}
+	if resolve.EventAction != actionResolve || resolve.DedupKey != "fp2" || resolve.RoutingKey != "routing-key" {
+		t.Errorf("expected the resolve event of fp2, got %s %s %s", resolve.EventAction, resolve.DedupKey, resolve.RoutingKey)
+	}
+
+	// The payload is not sent with the resolve event.
+	if resolve.Payload != nil {
+		t.Errorf("expected no payload of the resolve event, got %v", resolve.Payload)
+	}
+
+	p := trigger.Payload
+	if p == nil {
+		t.Fatal("expected the payload of the trigger event")
+	}
+	if p.Summary != "[FIRING] alert1: cpu is high" {
+		t.Errorf("expected the summary from the annotation, got %q", p.Summary)
+	}
+	if p.Source != "host1" || p.Severity != "critical" || p.Timestamp != "2021-01-01T00:00:00Z" {
+		t.Errorf("expected the source, severity and timestamp of the alert, got %s %s %s", p.Source, p.Severity, p.Timestamp)
+	}
+	if p.CustomDetails["alertname"] != "alert1" || p.CustomDetails["summary"] != "cpu is high" {
+		t.Errorf("expected the labels and annotations in the details, got %v", p.CustomDetails)
+	}
+	if len(trigger.Links) != 1 || trigger.Links[0].Href != firing.GeneratorURL {
+		t.Errorf("expected the link of the generator url, got %v", trigger.Links)
+	}
+}
+
+func TestNotifyDedupKey(t *testing.T) {
+
+	s := newEventsServer(t, nil)
+	defer s.Close()
+
+	// The alerts without the fingerprint are deduplicated by the labels.
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "routing-key"))
+	alert := newAlert("firing", "", "alert1")
+	for _, status := range []string{"firing", "resolved"} {
+		alert.Status = status
+		if errs := n.Notify(context.Background(), newData(alert)); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	expected, err := notifier.Md5key(alert.Labels)
+	if err != nil {
+		t.Fatalf("get key error, %s", err)
+	}
+
+	events := s.received()
+	if len(events) != 2 || events[0].DedupKey != expected || events[1].DedupKey != expected {
+		t.Errorf("expected the dedup key %s of both events, got %v", expected, events)
+	}
+
+	// The default source and severity are used if the alert has no instance and severity labels.
+	for _, e := range events {
+		if e.EventAction == actionTrigger && (e.Payload.Source != DefaultSource || e.Payload.Severity != DefaultSeverity) {
+			t.Errorf("expected the default source and severity, got %s %s", e.Payload.Source, e.Payload.Severity)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+
+	tests := []struct {
+		severity string
+		expected string
+	}{
+		{"critical", "critical"},
+		{"Fatal", "critical"},
+		{"emergency", "critical"},
+		{"page", "critical"},
+		{"error", "error"},
+		{"MAJOR", "error"},
+		{"warning", "warning"},
+		{"minor", "warning"},
+		{"info", "info"},
+		{"none", "info"},
+		{"low", "info"},
+		{"", DefaultSeverity},
+		{"unknown", DefaultSeverity},
+	}
+
+	for _, test := range tests {
+		if got := severity(test.severity); got != test.expected {
+			t.Errorf("%q: expected %s, got %s", test.severity, test.expected, got)
+		}
+	}
+}
+
+func TestNotifySeverityLabel(t *testing.T) {
+
+	s := newEventsServer(t, nil)
+	defer s.Close()
+
+	alert := newAlert("firing", "fp1", "alert1")
+	alert.Labels["severity"] = "info"
+	alert.Labels["priority"] = "major"
+
+	n := newNotifier(t, &v1alpha1.PagerDutyOptions{SeverityLabel: "priority"}, newReceiver(t, s.URL, "routing-key"))
+	if errs := n.Notify(context.Background(), newData(alert)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if events := s.received(); len(events) != 1 || events[0].Payload.Severity != "error" {
+		t.Errorf("expected the severity from the priority label, got %v", events)
+	}
+}
+
+func TestNotifyResponse(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		err    string
+	}{
+		{"accepted", http.StatusAccepted, `{"status":"success","message":"Event processed"}`, ""},
+		{"ok", http.StatusOK, `{"status":"success","message":"Event processed"}`, ""},
+		{"not success", http.StatusAccepted, `{"status":"invalid event","message":"Event object is invalid"}`, "invalid event: Event object is invalid"},
+		{"bad request", http.StatusBadRequest, `{"status":"invalid event","message":"Event object is invalid"}`, "400"},
+		{"invalid body", http.StatusAccepted, `not json`, "not json"},
+	}
+
+	for _, test := range tests {
+		s := newEventsServer(t, func(w http.ResponseWriter) {
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(test.body))
+		})
+
+		n := newNotifier(t, nil, newReceiver(t, s.URL, "routing-key"))
+		errs := n.Notify(context.Background(), newData(newAlert("firing", "fp1", "alert1")))
+		s.Close()
+
+		if len(test.err) == 0 {
+			if len(errs) != 0 {
+				t.Errorf("%s: expected no error, got %v", test.name, errs)
+			}
+			continue
+		}
+
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), test.err) {
+			t.Errorf("%s: expected the error of %s, got %v", test.name, test.err, errs)
+		}
+	}
+}
+
+func TestNotifySummaryTruncate(t *testing.T) {
+
+	s := newEventsServer(t, nil)
+	defer s.Close()
+
+	// The summary is truncated on the rune boundary.
+	alert := newAlert("firing", "fp1", "alert1")
+	alert.Annotations["message"] = strings.Repeat("告警", SummaryMaxSize)
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL, "routing-key"))
+	if errs := n.Notify(context.Background(), newData(alert)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	events := s.received()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	summary := events[0].Payload.Summary
+	if n := utf8.RuneCountInString(summary); n != SummaryMaxSize || !utf8.ValidString(summary) {
+		t.Errorf("expected the summary truncated to %d characters, got %d", SummaryMaxSize, n)
+	}
+	if !strings.HasPrefix(summary, "[FIRING] alert1: 告警") {
+		t.Errorf("expected the summary from the message, got %q", summary[:32])
+	}
+}
+
+func TestNotifySummaryTemplate(t *testing.T) {
+
+	s := newEventsServer(t, nil)
+	defer s.Close()
+
+	// The template is rendered with each alert.
+	n := newNotifier(t, &v1alpha1.PagerDutyOptions{Template: "nm.default.text"}, newReceiver(t, s.URL, "routing-key"))
+	if errs := n.Notify(context.Background(), newData(newAlert("firing", "fp1", "alert1"), newAlert("firing", "fp2", "alert2"))); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var summaries []string
+	for _, e := range s.received() {
+		summaries = append(summaries, strings.TrimSpace(e.Payload.Summary))
+	}
+
+	if strings.Join(summaries, ",") != "[firing] alert1,[firing] alert2" {
+		t.Errorf("expected the summary of each alert, got %v", summaries)
+	}
+}
+
+func TestNewNotifierDefaultURL(t *testing.T) {
+
+	r := newReceiver(t, "", "routing-key")
+
+	// The notifiers are created concurrently with the same receiver, the default api url is not written to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newNotifier(t, nil, r)
+			for _, p := range n.pagerduty {
+				if p.PagerDutyConfig.APIURL != DefaultApiURL {
+					t.Errorf("expected the api url %s, got %s", DefaultApiURL, p.PagerDutyConfig.APIURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(r.PagerDutyConfig.APIURL) != 0 {
+		t.Errorf("expected the receiver not changed, got %s", r.PagerDutyConfig.APIURL)
+	}
+}
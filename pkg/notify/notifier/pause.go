@@ -0,0 +1,24 @@
+package notifier
+
+import (
+	"sync/atomic"
+)
+
+// Whether the notifications are paused, such as during the maintenance window.
+// It is read by all notifiers concurrently, so it is accessed atomically.
+var paused int32
+
+// SetPaused pauses or resumes the notifications, the notifications sent while paused are dropped.
+func SetPaused(p bool) {
+
+	var v int32
+	if p {
+		v = 1
+	}
+
+	atomic.StoreInt32(&paused, v)
+}
+
+func Paused() bool {
+	return atomic.LoadInt32(&paused) == 1
+}
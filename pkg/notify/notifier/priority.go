@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/common/model"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPriorityLabel     = "severity"
+	DefaultCriticalPriority  = "critical"
+	DefaultThrottleWindow    = time.Minute * 5
+	DefaultThrottleMaxAlerts = 100
+	// The timeout of sending the buffered alerts.
+	DefaultThrottleFlushTimeout = time.Second * 30
+)
+
+// PriorityThrottler buffers the low priority alerts of each receiver, and flushes them in one notification
+// when the window elapses or the buffer fills.
+type PriorityThrottler struct {
+	mutex   sync.Mutex
+	buffers map[string]*throttleBuffer
+}
+
+type throttleBuffer struct {
+	data  template.Data
+	timer *time.Timer
+	flush func(data template.Data)
+}
+
+var priorityThrottler *PriorityThrottler
+
+func init() {
+	priorityThrottler = &PriorityThrottler{
+		buffers: make(map[string]*throttleBuffer),
+	}
+}
+
+func GetPriorityThrottler() *PriorityThrottler {
+	return priorityThrottler
+}
+
+// SplitByPriority splits the alerts into the critical alerts and the low priority alerts.
+func SplitByPriority(data template.Data, t *v1alpha1.PriorityThrottle) (template.Data, template.Data) {
+
+	label := DefaultPriorityLabel
+	if len(t.PriorityLabel) > 0 {
+		label = t.PriorityLabel
+	}
+
+	priorities := []string{DefaultCriticalPriority}
+	if len(t.CriticalPriorities) > 0 {
+		priorities = t.CriticalPriorities
+	}
+
+	isCritical := func(alert template.Alert) bool {
+		for _, p := range priorities {
+			if alert.Labels[label] == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	critical, low := data, data
+	critical.Alerts, low.Alerts = nil, nil
+	for _, alert := range data.Alerts {
+		if isCritical(alert) {
+			critical.Alerts = append(critical.Alerts, alert)
+		} else {
+			low.Alerts = append(low.Alerts, alert)
+		}
+	}
+
+	return critical, low
+}
+
+// Add buffers the alerts of the key, the buffered alerts are passed to the flush function
+// when the window elapses or the number of them reaches the maximum. The latest flush function is used.
+func (t *PriorityThrottler) Add(key string, data template.Data, throttle *v1alpha1.PriorityThrottle, flush func(data template.Data)) {
+
+	if len(data.Alerts) == 0 {
+		return
+	}
+
+	window := DefaultThrottleWindow
+	if throttle.Window > 0 {
+		window = throttle.Window
+	}
+
+	maxAlerts := DefaultThrottleMaxAlerts
+	if throttle.MaxAlerts > 0 {
+		maxAlerts = throttle.MaxAlerts
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	b, ok := t.buffers[key]
+	if !ok {
+		b = &throttleBuffer{data: data}
+		b.timer = time.AfterFunc(window, func() {
+			t.flush(key, b)
+		})
+		t.buffers[key] = b
+	} else {
+		b.data = mergeData(b.data, data)
+	}
+	b.flush = flush
+
+	if len(b.data.Alerts) >= maxAlerts {
+		b.timer.Stop()
+		delete(t.buffers, key)
+		go b.flush(b.data)
+	}
+}
+
+func (t *PriorityThrottler) flush(key string, b *throttleBuffer) {
+
+	t.mutex.Lock()
+	// The buffer has been flushed because it is full.
+	if t.buffers[key] != b {
+		t.mutex.Unlock()
+		return
+	}
+	delete(t.buffers, key)
+	data, flush := b.data, b.flush
+	t.mutex.Unlock()
+
+	flush(data)
+}
+
+// Flush passes all the buffered alerts to the flush functions and waits for them to finish, it is called on shutdown.
+func (t *PriorityThrottler) Flush() {
+
+	t.mutex.Lock()
+	buffers := t.buffers
+	t.buffers = make(map[string]*throttleBuffer)
+	t.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, buffer := range buffers {
+		b := buffer
+		b.timer.Stop()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.flush(b.data)
+		}()
+	}
+	wg.Wait()
+}
+
+// Merge the alerts of two notifications, the alert with the same fingerprint is replaced by the newer one.
+func mergeData(old, new template.Data) template.Data {
+
+	d := new
+	d.Alerts = nil
+	index := make(map[string]int)
+	for _, alert := range append(old.Alerts, new.Alerts...) {
+		if i, ok := index[alert.Fingerprint]; ok && len(alert.Fingerprint) > 0 {
+			d.Alerts[i] = alert
+			continue
+		}
+		index[alert.Fingerprint] = len(d.Alerts)
+		d.Alerts = append(d.Alerts, alert)
+	}
+
+	d.Status = string(model.AlertResolved)
+	for _, alert := range d.Alerts {
+		if alert.Status == string(model.AlertFiring) {
+			d.Status = string(model.AlertFiring)
+			break
+		}
+	}
+
+	d.GroupLabels = commonKV(old.GroupLabels, new.GroupLabels)
+	d.CommonLabels = commonKV(old.CommonLabels, new.CommonLabels)
+	d.CommonAnnotations = commonKV(old.CommonAnnotations, new.CommonAnnotations)
+	return d
+}
+
+func commonKV(a, b template.KV) template.KV {
+
+	kv := template.KV{}
+	for k, v := range a {
+		if b[k] == v {
+			kv[k] = v
+		}
+	}
+
+	return kv
+}
@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newPriorityData(severities ...string) template.Data {
+
+	data := template.Data{Status: "firing"}
+	for _, s := range severities {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"severity": s},
+			Fingerprint: s,
+		})
+	}
+
+	return data
+}
+
+// Record the data flushed.
+type flushRecorder struct {
+	mu      sync.Mutex
+	flushed []template.Data
+	ch      chan struct{}
+}
+
+func newFlushRecorder() *flushRecorder {
+	return &flushRecorder{ch: make(chan struct{}, 10)}
+}
+
+func (r *flushRecorder) flush(data template.Data) {
+	r.mu.Lock()
+	r.flushed = append(r.flushed, data)
+	r.mu.Unlock()
+	r.ch <- struct{}{}
+}
+
+func (r *flushRecorder) wait(t *testing.T, timeout time.Duration) {
+	select {
+	case <-r.ch:
+	case <-time.After(timeout):
+		t.Fatal("expected the alerts flushed")
+	}
+}
+
+func (r *flushRecorder) get() []template.Data {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]template.Data(nil), r.flushed...)
+}
+
+func TestSplitByPriority(t *testing.T) {
+
+	data := newPriorityData("critical", "warning", "info", "page")
+
+	critical, low := SplitByPriority(data, &v1alpha1.PriorityThrottle{})
+	if len(critical.Alerts) != 1 || critical.Alerts[0].Fingerprint != "critical" || len(low.Alerts) != 3 {
+		t.Errorf("expected 1 critical and 3 low priority alerts, got %d and %d", len(critical.Alerts), len(low.Alerts))
+	}
+
+	critical, low = SplitByPriority(data, &v1alpha1.PriorityThrottle{CriticalPriorities: []string{"critical", "page"}})
+	if len(critical.Alerts) != 2 || len(low.Alerts) != 2 {
+		t.Errorf("expected 2 critical and 2 low priority alerts, got %d and %d", len(critical.Alerts), len(low.Alerts))
+	}
+
+	// The priority is derived from the label given.
+	critical, _ = SplitByPriority(data, &v1alpha1.PriorityThrottle{PriorityLabel: "priority"})
+	if len(critical.Alerts) != 0 {
+		t.Errorf("expected no critical alert, got %d", len(critical.Alerts))
+	}
+}
+
+func TestPriorityThrottlerWindow(t *testing.T) {
+
+	throttler := &PriorityThrottler{buffers: make(map[string]*throttleBuffer)}
+	throttle := &v1alpha1.PriorityThrottle{Window: time.Millisecond * 100}
+	r := newFlushRecorder()
+
+	throttler.Add("wechat/receiver", newPriorityData("warning"), throttle, r.flush)
+	throttler.Add("wechat/receiver", newPriorityData("info", "warning"), throttle, r.flush)
+
+	if len(r.get()) != 0 {
+		t.Fatal("expected the alerts buffered until the window elapsed")
+	}
+
+	r.wait(t, time.Second)
+
+	// The alerts are coalesced into one notification, the alert with the same fingerprint is replaced.
+	flushed := r.get()
+	if len(flushed) != 1 || len(flushed[0].Alerts) != 2 {
+		t.Fatalf("expected 1 notification with 2 alerts, got %v", flushed)
+	}
+
+	if flushed[0].Alerts[0].Fingerprint != "warning" || flushed[0].Alerts[1].Fingerprint != "info" {
+		t.Errorf("unexpected alerts %v", flushed[0].Alerts)
+	}
+
+	// The buffer is dropped after flushing.
+	time.Sleep(time.Millisecond * 150)
+	if len(r.get()) != 1 {
+		t.Errorf("expected the alerts flushed once, got %d", len(r.get()))
+	}
+}
+
+func TestPriorityThrottlerMaxAlerts(t *testing.T) {
+
+	throttler := &PriorityThrottler{buffers: make(map[string]*throttleBuffer)}
+	throttle := &v1alpha1.PriorityThrottle{Window: time.Hour, MaxAlerts: 3}
+	r := newFlushRecorder()
+
+	throttler.Add("wechat/receiver", newPriorityData("warning", "info"), throttle, r.flush)
+	throttler.Add("wechat/other", newPriorityData("warning"), throttle, r.flush)
+	throttler.Add("wechat/receiver", newPriorityData("debug"), throttle, r.flush)
+
+	// The buffer is flushed as soon as it is full.
+	r.wait(t, time.Second)
+	if flushed := r.get(); len(flushed) != 1 || len(flushed[0].Alerts) != 3 {
+		t.Fatalf("expected 1 notification with 3 alerts, got %v", flushed)
+	}
+
+	// The remaining buffers are flushed on shutdown.
+	throttler.Flush()
+	if flushed := r.get(); len(flushed) != 2 || len(flushed[1].Alerts) != 1 {
+		t.Errorf("expected the other buffer flushed, got %v", flushed)
+	}
+}
+
+func TestMergeData(t *testing.T) {
+
+	old := template.Data{
+		Status:       "firing",
+		CommonLabels: template.KV{"namespace": "default", "severity": "warning"},
+		Alerts:       template.Alerts{{Status: "firing", Fingerprint: "a"}},
+	}
+	newer := template.Data{
+		Status:       "resolved",
+		CommonLabels: template.KV{"namespace": "default", "severity": "info"},
+		Alerts:       template.Alerts{{Status: "resolved", Fingerprint: "a"}, {Status: "resolved", Fingerprint: "b"}},
+	}
+
+	d := mergeData(old, newer)
+	if len(d.Alerts) != 2 || d.Alerts[0].Status != "resolved" {
+		t.Errorf("expected the alert a replaced by the newer one, got %v", d.Alerts)
+	}
+
+	if d.Status != "resolved" {
+		t.Errorf("expected the status resolved, got %s", d.Status)
+	}
+
+	if len(d.CommonLabels) != 1 || d.CommonLabels["namespace"] != "default" {
+		t.Errorf("expected the common labels of both, got %v", d.CommonLabels)
+	}
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"fmt"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultQuietSeverityLabel = "severity"
+	DefaultQuietSeverity      = "critical"
+)
+
+// The severities from low to high, the alerts with unknown severity are the lowest.
+var severities = map[string]int{
+	"info":     1,
+	"warning":  2,
+	"error":    3,
+	"critical": 4,
+}
+
+// ValidateQuietHours checks the time ranges and the time zone of the quiet hours.
+func ValidateQuietHours(q *v1alpha1.QuietHours) error {
+
+	if len(q.Ranges) == 0 {
+		return fmt.Errorf("quiet hours has no time range")
+	}
+
+	for _, r := range q.Ranges {
+		if _, _, err := parseTimeRange(r); err != nil {
+			return err
+		}
+	}
+
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return err
+	}
+
+	if len(q.Severity) > 0 {
+		if _, ok := severities[q.Severity]; !ok {
+			return fmt.Errorf("unknown severity %s", q.Severity)
+		}
+	}
+
+	return nil
+}
+
+// Parse the time range in form of `HH:MM-HH:MM`, return the minutes of the day the range starts and ends.
+func parseTimeRange(r string) (int, int, error) {
+
+	parts := strings.Split(r, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time range %s", r)
+	}
+
+	var minutes []int
+	for _, p := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(p))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid time range %s, %s", r, err)
+		}
+		minutes = append(minutes, t.Hour()*60+t.Minute())
+	}
+
+	return minutes[0], minutes[1], nil
+}
+
+// InQuietHours returns whether the time is within the quiet hours. The range whose start is after
+// the end crosses midnight, and the range whose start equals to the end lasts the whole day.
+func InQuietHours(q *v1alpha1.QuietHours, t time.Time) bool {
+
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return false
+	}
+
+	t = t.In(loc)
+	m := t.Hour()*60 + t.Minute()
+	for _, r := range q.Ranges {
+		start, end, err := parseTimeRange(r)
+		if err != nil {
+			continue
+		}
+
+		if start < end {
+			if m >= start && m < end {
+				return true
+			}
+		} else if m >= start || m < end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SplitByQuietHours splits the alerts into the alerts sent at the time and the alerts suppressed by the quiet hours.
+// No alert is suppressed out of the quiet hours.
+func SplitByQuietHours(data template.Data, q *v1alpha1.QuietHours, t time.Time) (template.Data, template.Data) {
+
+	sent, suppressed := data, data
+	suppressed.Alerts = nil
+	if !InQuietHours(q, t) {
+		return sent, suppressed
+	}
+
+	label := DefaultQuietSeverityLabel
+	if len(q.SeverityLabel) > 0 {
+		label = q.SeverityLabel
+	}
+
+	threshold := severities[DefaultQuietSeverity]
+	if s, ok := severities[q.Severity]; ok {
+		threshold = s
+	}
+
+	sent.Alerts = nil
+	for _, alert := range data.Alerts {
+		if severities[alert.Labels[label]] >= threshold {
+			sent.Alerts = append(sent.Alerts, alert)
+		} else {
+			suppressed.Alerts = append(suppressed.Alerts, alert)
+		}
+	}
+
+	return sent, suppressed
+}
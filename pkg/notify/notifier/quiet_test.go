@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"testing"
+	"time"
+)
+
+func TestValidateQuietHours(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		quiet *v1alpha1.QuietHours
+		valid bool
+	}{
+		{"valid", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00", "12:00 - 13:30"}, TimeZone: "Asia/Shanghai", Severity: "error"}, true},
+		{"default time zone", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}}, true},
+		{"no range", &v1alpha1.QuietHours{}, false},
+		{"invalid range", &v1alpha1.QuietHours{Ranges: []string{"22:00"}}, false},
+		{"invalid time", &v1alpha1.QuietHours{Ranges: []string{"22:00-25:00"}}, false},
+		{"invalid time zone", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}, TimeZone: "Mars/Olympus"}, false},
+		{"unknown severity", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}, Severity: "page"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateQuietHours(tt.quiet); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %t, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+
+	night := &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}, TimeZone: "Asia/Shanghai"}
+	lunch := &v1alpha1.QuietHours{Ranges: []string{"12:00-13:00"}}
+	allDay := &v1alpha1.QuietHours{Ranges: []string{"00:00-00:00"}}
+
+	// The times are in UTC, Asia/Shanghai is 8 hours ahead.
+	at := func(hour, min int) time.Time {
+		return time.Date(2020, 10, 1, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		quiet *v1alpha1.QuietHours
+		t     time.Time
+		want  bool
+	}{
+		{"before midnight", night, at(15, 0), true},
+		{"after midnight", night, at(22, 30), true},
+		{"start of range", night, at(14, 0), true},
+		{"end of range", night, at(23, 0), false},
+		{"daytime", night, at(4, 0), false},
+		{"just before start", night, at(13, 59), false},
+		{"within range", lunch, at(12, 30), true},
+		{"end of range in utc", lunch, at(13, 0), false},
+		{"before range", lunch, at(11, 59), false},
+		{"all day", allDay, at(9, 0), true},
+		{"invalid time zone", &v1alpha1.QuietHours{Ranges: []string{"00:00-00:00"}, TimeZone: "Mars/Olympus"}, at(9, 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := InQuietHours(tt.quiet, tt.t); got != tt.want {
+			t.Errorf("%s: expected %t at %s, got %t", tt.name, tt.want, tt.t.Format("15:04"), got)
+		}
+	}
+}
+
+func TestSplitByQuietHours(t *testing.T) {
+
+	quiet := time.Date(2020, 10, 1, 23, 0, 0, 0, time.UTC)
+	day := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	data := newPriorityData("critical", "error", "warning", "info", "")
+
+	tests := []struct {
+		name       string
+		quiet      *v1alpha1.QuietHours
+		t          time.Time
+		sent       int
+		suppressed int
+	}{
+		// Only the critical alerts are sent by default, the alert without severity is the lowest.
+		{"default severity", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}}, quiet, 1, 4},
+		{"error or higher", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}, Severity: "error"}, quiet, 2, 3},
+		{"info or higher", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}, Severity: "info"}, quiet, 4, 1},
+		{"out of quiet hours", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}}, day, 5, 0},
+		// The severity is read from another label, so none of the alerts has a severity.
+		{"severity label", &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}, SeverityLabel: "level"}, quiet, 0, 5},
+	}
+
+	for _, tt := range tests {
+		sent, suppressed := SplitByQuietHours(data, tt.quiet, tt.t)
+		if len(sent.Alerts) != tt.sent || len(suppressed.Alerts) != tt.suppressed {
+			t.Errorf("%s: expected %d sent and %d suppressed, got %d and %d",
+				tt.name, tt.sent, tt.suppressed, len(sent.Alerts), len(suppressed.Alerts))
+		}
+	}
+
+	// The critical alerts always pass.
+	sent, _ := SplitByQuietHours(data, &v1alpha1.QuietHours{Ranges: []string{"22:00-07:00"}}, quiet)
+	if len(sent.Alerts) != 1 || sent.Alerts[0].Labels["severity"] != "critical" {
+		t.Errorf("expected the critical alert sent, got %v", sent.Alerts)
+	}
+}
@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"golang.org/x/time/rate"
+	"sync"
+	"time"
+)
+
+const (
+	// The maximum number of sends waiting for the global send budget, the sends beyond it will be dropped.
+	DefaultSendBudgetQueueSize = 1000
+)
+
+// RateLimiter limits the rate of the requests sent with the same key, such as `CorpID | AgentID`.
+type RateLimiter struct {
+	mutex    sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// SendBudget limits the total number of notifications sent by all notifiers, such as to control the cost of SMS.
+// The sends exceeding the budget wait in a bounded queue, and will be dropped if the queue is full.
+type SendBudget struct {
+	mutex             sync.Mutex
+	limiter           *rate.Limiter
+	maxSendsPerMinute int
+	queueSize         int
+	waiting           int
+}
+
+var rateLimiter *RateLimiter
+var sendBudget *SendBudget
+
+func init() {
+	rateLimiter = &RateLimiter{
+		limiters: make(map[string]*rate.Limiter),
+	}
+
+	sendBudget = &SendBudget{
+		queueSize: DefaultSendBudgetQueueSize,
+	}
+}
+
+func GetRateLimiter() *RateLimiter {
+	return rateLimiter
+}
+
+// Wait blocks until the request of the key is allowed or the ctx is done, the error returned is a ThrottledError.
+// The limit of the key will be updated if it is changed.
+func (r *RateLimiter) Wait(ctx context.Context, key string, limit, burst int) error {
+
+	if limit <= 0 {
+		return nil
+	}
+
+	if burst <= 0 {
+		burst = limit
+	}
+
+	if err := r.get(key, rate.Limit(limit), burst).Wait(ctx); err != nil {
+		return NewThrottledError(err)
+	}
+
+	return nil
+}
+
+func (r *RateLimiter) get(key string, limit rate.Limit, burst int) *rate.Limiter {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	l, ok := r.limiters[key]
+	if !ok || l.Burst() != burst {
+		l = rate.NewLimiter(limit, burst)
+		r.limiters[key] = l
+		return l
+	}
+
+	if l.Limit() != limit {
+		l.SetLimit(limit)
+	}
+
+	return l
+}
+
+func GetSendBudget() *SendBudget {
+	return sendBudget
+}
+
+// SetLimit sets the maximum number of sends per minute, the budget is disabled if it is 0.
+func (b *SendBudget) SetLimit(maxSendsPerMinute int) {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if maxSendsPerMinute == b.maxSendsPerMinute {
+		return
+	}
+
+	b.maxSendsPerMinute = maxSendsPerMinute
+	if maxSendsPerMinute <= 0 {
+		b.limiter = nil
+		return
+	}
+
+	// The sends waiting for the old limiter are not affected.
+	b.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(maxSendsPerMinute)), maxSendsPerMinute)
+}
+
+// SetQueueSize sets the maximum number of sends waiting for the budget.
+func (b *SendBudget) SetQueueSize(size int) {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if size >= 0 {
+		b.queueSize = size
+	}
+}
+
+// Acquire must be called before sending a notification, it blocks until the send is allowed or the ctx is done.
+// The error returned is a ThrottledError.
+// An error will be returned if the send is dropped because the queue is full.
+func (b *SendBudget) Acquire(ctx context.Context, l log.Logger) error {
+
+	b.mutex.Lock()
+	limiter := b.limiter
+	if limiter == nil || limiter.Allow() {
+		b.mutex.Unlock()
+		return nil
+	}
+
+	if b.waiting >= b.queueSize {
+		b.mutex.Unlock()
+		_ = level.Warn(l).Log("msg", "the global send budget is exhausted and the queue is full, drop the notification", "queueSize", b.queueSize)
+		return NewThrottledError(fmt.Errorf("global send budget exhausted"))
+	}
+	b.waiting++
+	b.mutex.Unlock()
+
+	err := limiter.Wait(ctx)
+
+	b.mutex.Lock()
+	b.waiting--
+	b.mutex.Unlock()
+
+	if err != nil {
+		return NewThrottledError(err)
+	}
+
+	return nil
+}
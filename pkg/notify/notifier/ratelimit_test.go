@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"github.com/go-kit/kit/log"
+	"golang.org/x/time/rate"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+
+	const (
+		limit = 20
+		burst = 2
+		n     = 10
+	)
+
+	r := &RateLimiter{limiters: make(map[string]*rate.Limiter)}
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.Wait(context.Background(), "corp | agent", limit, burst); err != nil {
+				t.Errorf("wait error, %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The requests beyond the burst are allowed one by one at the limit.
+	min := time.Second * (n - burst) / limit
+	if elapsed := time.Since(start); elapsed < min-time.Millisecond*10 {
+		t.Errorf("expected %d requests to take at least %s, got %s", n, min, elapsed)
+	}
+
+	// The keys are limited independently.
+	start = time.Now()
+	if err := r.Wait(context.Background(), "another corp | agent", limit, burst); err != nil {
+		t.Fatalf("wait error, %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*20 {
+		t.Errorf("expected the request of another key not to wait, got %s", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCancel(t *testing.T) {
+
+	r := &RateLimiter{limiters: make(map[string]*rate.Limiter)}
+	if err := r.Wait(context.Background(), "key", 1, 1); err != nil {
+		t.Fatalf("wait error, %s", err)
+	}
+
+	// The next request has to wait for a second, the cancelled wait returns immediately.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	start := time.Now()
+	if err := r.Wait(ctx, "key", 1, 1); err == nil {
+		t.Error("expected the error of the cancelled wait")
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("expected the cancelled wait to return immediately, got %s", elapsed)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+
+	r := &RateLimiter{limiters: make(map[string]*rate.Limiter)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.Wait(ctx, "key", 0, 0); err != nil {
+		t.Errorf("expected no limit if the limit is 0, got %s", err)
+	}
+}
+
+// Wait until the number of sends waiting for the budget reaches n.
+func waitQueued(t *testing.T, b *SendBudget, n int) {
+
+	for i := 0; i < 100; i++ {
+		b.mutex.Lock()
+		waiting := b.waiting
+		b.mutex.Unlock()
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	t.Fatalf("expected %d sends waiting", n)
+}
+
+func TestSendBudgetAcquire(t *testing.T) {
+
+	b := &SendBudget{queueSize: 1}
+	b.SetLimit(2)
+
+	// The sends within the budget are allowed immediately.
+	for i := 0; i < 2; i++ {
+		if err := b.Acquire(context.Background(), log.NewNopLogger()); err != nil {
+			t.Fatalf("send %d: expected no error, got %s", i, err)
+		}
+	}
+
+	// The next send waits in the queue, and the send beyond the queue is dropped.
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- b.Acquire(ctx, log.NewNopLogger())
+	}()
+	waitQueued(t, b, 1)
+
+	if err := b.Acquire(context.Background(), log.NewNopLogger()); err == nil || err.Error() != "global send budget exhausted" || !IsThrottled(err) {
+		t.Errorf("expected the send dropped, got %v", err)
+	}
+
+	cancel()
+	if err := <-errCh; err == nil || !IsThrottled(err) {
+		t.Errorf("expected the error of the cancelled send, got %v", err)
+	}
+	waitQueued(t, b, 0)
+}
+
+func TestSendBudgetSetLimit(t *testing.T) {
+
+	b := &SendBudget{queueSize: 0}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The budget is disabled by default.
+	for i := 0; i < 10; i++ {
+		if err := b.Acquire(ctx, log.NewNopLogger()); err != nil {
+			t.Fatalf("expected no limit, got %s", err)
+		}
+	}
+
+	b.SetLimit(1)
+	if err := b.Acquire(ctx, log.NewNopLogger()); err != nil {
+		t.Fatalf("expected the first send allowed, got %s", err)
+	}
+	if err := b.Acquire(ctx, log.NewNopLogger()); err == nil {
+		t.Error("expected the send beyond the budget dropped")
+	}
+
+	// The same limit keeps the budget used, and the budget is disabled by setting the limit to 0.
+	b.SetLimit(1)
+	if err := b.Acquire(ctx, log.NewNopLogger()); err == nil {
+		t.Error("expected the budget kept")
+	}
+
+	b.SetLimit(0)
+	if err := b.Acquire(ctx, log.NewNopLogger()); err != nil {
+		t.Errorf("expected the budget disabled, got %s", err)
+	}
+}
@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// The requests and the retries within the window decide whether a retry is allowed.
+	RetryBudgetWindow = time.Second * 10
+	// The retries always allowed within the window, so that the notifiers sending a few notifications can retry.
+	RetryBudgetMinRetries = 10
+)
+
+// RetryBudget limits the retries of all notifiers to a ratio of the requests within a sliding window,
+// so that the retries do not multiply the load during an outage. The retries exceeding the budget are suppressed.
+type RetryBudget struct {
+	mutex sync.Mutex
+	// The maximum ratio of the retries to the requests, the budget is disabled if it is 0.
+	ratio      float64
+	minRetries int
+	// The requests and the retries of each second within the window.
+	buckets []retryBucket
+	now     func() time.Time
+}
+
+type retryBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+var retryBudget *RetryBudget
+
+func init() {
+	retryBudget = &RetryBudget{
+		minRetries: RetryBudgetMinRetries,
+		buckets:    make([]retryBucket, int(RetryBudgetWindow/time.Second)),
+		now:        time.Now,
+	}
+}
+
+func GetRetryBudget() *RetryBudget {
+	return retryBudget
+}
+
+// Backoff returns the time to wait before the attempt-th retry.
+// The time doubles with each attempt, and a random jitter of up to half of it is subtracted
+// to avoid the retries of different senders happening at the same time.
+func Backoff(base time.Duration, attempt int) time.Duration {
+
+	if base <= 0 || attempt <= 0 {
+		return 0
+	}
+
+	d := base << uint(attempt-1)
+	// Overflow
+	if d <= 0 {
+		d = base
+	}
+
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// FullJitterBackoff returns the time to wait before the attempt-th retry, it is a random time between 0 and
+// the exponential backoff which is capped by max, so the retries of different senders are spread out.
+func FullJitterBackoff(base, max time.Duration, attempt int) time.Duration {
+
+	if base <= 0 || attempt <= 0 {
+		return 0
+	}
+
+	d := base << uint(attempt-1)
+	// Overflow
+	if d <= 0 || (max > 0 && d > max) {
+		d = max
+	}
+
+	if d <= 0 {
+		d = base
+	}
+
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// SetRatio sets the maximum ratio of the retries to the requests, such as 0.1 means 10% of the requests can be retried.
+// The budget is disabled if the ratio is 0.
+func (b *RetryBudget) SetRatio(ratio float64) {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if ratio >= 0 {
+		b.ratio = ratio
+	}
+}
+
+// Request records a request, it must be called before the first attempt of a send.
+func (b *RetryBudget) Request() {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.ratio <= 0 {
+		return
+	}
+
+	b.bucket().requests++
+}
+
+// AllowRetry returns whether a retry is allowed, and records the retry if it is allowed.
+// The retry is allowed if the retries within the window do not exceed the ratio of the requests.
+func (b *RetryBudget) AllowRetry() bool {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.ratio <= 0 {
+		return true
+	}
+
+	current := b.bucket()
+	requests, retries := 0, 0
+	for _, bucket := range b.buckets {
+		if current.second-bucket.second < int64(len(b.buckets)) {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	if float64(retries) >= float64(b.minRetries)+b.ratio*float64(requests) {
+		return false
+	}
+
+	current.retries++
+	return true
+}
+
+// Return the bucket of the current second, the bucket of the past window is reset.
+func (b *RetryBudget) bucket() *retryBucket {
+
+	second := b.now().Unix()
+	bucket := &b.buckets[second%int64(len(b.buckets))]
+	if bucket.second != second {
+		*bucket = retryBucket{second: second}
+	}
+
+	return bucket
+}
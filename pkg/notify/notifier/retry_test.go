@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFullJitterBackoff(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		base    time.Duration
+		max     time.Duration
+		attempt int
+		// The backoff is between 0 and the limit.
+		limit time.Duration
+	}{
+		{"no backoff", 0, time.Second, 1, 0},
+		{"no attempt", time.Millisecond * 100, time.Second, 0, 0},
+		{"first", time.Millisecond * 100, time.Second, 1, time.Millisecond * 100},
+		{"third", time.Millisecond * 100, time.Second, 3, time.Millisecond * 400},
+		{"capped", time.Millisecond * 100, time.Second, 10, time.Second},
+		{"overflow", time.Second, time.Second * 5, 100, time.Second * 5},
+		{"unlimited", time.Millisecond * 100, 0, 5, time.Millisecond * 1600},
+	}
+
+	for _, test := range tests {
+		var max time.Duration
+		for i := 0; i < 1000; i++ {
+			d := FullJitterBackoff(test.base, test.max, test.attempt)
+			if d < 0 || d > test.limit {
+				t.Fatalf("%s: expected the backoff between 0 and %s, got %s", test.name, test.limit, d)
+			}
+			if d > max {
+				max = d
+			}
+		}
+
+		// The backoff is spread over the whole range rather than around the limit.
+		if test.limit > 0 && max < test.limit/2 {
+			t.Errorf("%s: expected the backoff spread up to %s, got at most %s", test.name, test.limit, max)
+		}
+	}
+}
+
+func newRetryBudget(ratio float64, now *time.Time) *RetryBudget {
+	b := &RetryBudget{
+		minRetries: RetryBudgetMinRetries,
+		buckets:    make([]retryBucket, int(RetryBudgetWindow/time.Second)),
+		now:        func() time.Time { return *now },
+	}
+	b.SetRatio(ratio)
+	return b
+}
+
+// Record the requests and return the retries allowed.
+func drive(b *RetryBudget, requests int) int {
+
+	allowed := 0
+	for i := 0; i < requests; i++ {
+		b.Request()
+		if b.AllowRetry() {
+			allowed++
+		}
+	}
+
+	return allowed
+}
+
+func TestRetryBudget(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		ratio    float64
+		requests int
+		allowed  int
+	}{
+		{"disabled", 0, 100, 100},
+		{"min retries", 0.1, 10, 10},
+		{"ten percent", 0.1, 100, 20},
+		{"half", 0.5, 100, 60},
+	}
+
+	for _, test := range tests {
+		now := time.Unix(1000, 0)
+		b := newRetryBudget(test.ratio, &now)
+
+		if allowed := drive(b, test.requests); allowed != test.allowed {
+			t.Errorf("%s: expected %d retries allowed, got %d", test.name, test.allowed, allowed)
+		}
+	}
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+	b := newRetryBudget(0.1, &now)
+
+	// The retries stop once the budget is spent.
+	if allowed := drive(b, 100); allowed != 20 {
+		t.Fatalf("expected 20 retries allowed, got %d", allowed)
+	}
+	if b.AllowRetry() {
+		t.Fatal("expected the retry suppressed after the budget is spent")
+	}
+
+	// The budget is still spent within the window.
+	now = now.Add(RetryBudgetWindow - time.Second)
+	if b.AllowRetry() {
+		t.Error("expected the retry suppressed within the window")
+	}
+
+	// The requests and retries out of the window are forgotten.
+	now = now.Add(RetryBudgetWindow)
+	if !b.AllowRetry() {
+		t.Error("expected the retry allowed after the window")
+	}
+}
+
+func TestRetryBudgetSetRatio(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+	b := newRetryBudget(0.1, &now)
+
+	// The negative ratio is ignored.
+	b.SetRatio(-1)
+	if allowed := drive(b, 100); allowed != 20 {
+		t.Errorf("expected 20 retries allowed, got %d", allowed)
+	}
+
+	// The budget is disabled with the ratio 0.
+	b.SetRatio(0)
+	if allowed := drive(b, 100); allowed != 100 {
+		t.Errorf("expected 100 retries allowed, got %d", allowed)
+	}
+}
@@ -0,0 +1,388 @@
+package rocketchat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout   = time.Second * 3
+	DefaultTemplate      = `{{ template "nm.default.text" . }}`
+	DefaultTitleTemplate = `{{ template "nm.default.subject" . }}`
+	// The default maximum size of a message of Rocket.Chat is 5000 characters.
+	MessageMaxSize = 5000
+	// The maximum number of attachments in a message, the attachments exceeding it are sent in the following messages.
+	AttachmentsMaxSize = 20
+	TitleMaxSize       = 256
+	ColorResolved      = "#2DC72D"
+	ColorCritical      = "#E6522C"
+	ColorWarning       = "#F5A623"
+	ColorInfo          = "#3498DB"
+	ColorDefault       = "#95A5A6"
+	postMessagePath    = "/api/v1/chat.postMessage"
+	alertNameLabel     = "alertname"
+	severityLabel      = "severity"
+	statusResolved     = "resolved"
+)
+
+// The annotations used as the text of the attachment in order.
+var textAnnotations = []string{"message", "summary", "description"}
+
+type Notifier struct {
+	notifierCfg       *config.Config
+	rocketchat        map[string]*config.RocketChat
+	timeout           time.Duration
+	logger            log.Logger
+	template          *notifier.Template
+	templateName      string
+	titleTemplateName string
+	messageMaxSize    int
+	decoration        *notifier.Decoration
+}
+
+type rocketChatField struct {
+	Short bool   `json:"short"`
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type rocketChatAttachment struct {
+	Title     string             `json:"title,omitempty"`
+	TitleLink string             `json:"title_link,omitempty"`
+	Text      string             `json:"text,omitempty"`
+	Color     string             `json:"color,omitempty"`
+	Timestamp string             `json:"ts,omitempty"`
+	Fields    []*rocketChatField `json:"fields,omitempty"`
+}
+
+type rocketChatMessage struct {
+	Channel     string                  `json:"channel,omitempty"`
+	Text        string                  `json:"text,omitempty"`
+	Attachments []*rocketChatAttachment `json:"attachments,omitempty"`
+}
+
+type rocketChatResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func NewRocketChatNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:       notifierCfg,
+		rocketchat:        make(map[string]*config.RocketChat),
+		timeout:           DefaultSendTimeout,
+		logger:            logger,
+		template:          tmpl,
+		templateName:      DefaultTemplate,
+		titleTemplateName: DefaultTitleTemplate,
+		messageMaxSize:    MessageMaxSize,
+		decoration:        &notifier.Decoration{Header: header, Footer: footer},
+	}
+
+	if opts != nil && opts.RocketChat != nil {
+
+		if opts.RocketChat.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.RocketChat.NotificationTimeout)
+		}
+
+		if len(opts.RocketChat.Template) > 0 {
+			n.templateName = opts.RocketChat.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.RocketChat.TitleTemplate) > 0 {
+			n.titleTemplateName = opts.RocketChat.TitleTemplate
+		}
+
+		if opts.RocketChat.MessageMaxSize > 0 {
+			n.messageMaxSize = opts.RocketChat.MessageMaxSize
+		}
+
+		if len(opts.RocketChat.Header) > 0 {
+			n.decoration.Header = opts.RocketChat.Header
+		}
+
+		if len(opts.RocketChat.Footer) > 0 {
+			n.decoration.Footer = opts.RocketChat.Footer
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.RocketChat)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "RocketChatNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		key, err := notifier.Md5key(receiver)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "RocketChatNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.rocketchat[key] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	send := func(r *config.RocketChat, msg *rocketChatMessage) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "RocketChatNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(msg); err != nil {
+			_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		request, err := n.newRequest(r, &buf)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: create request error", "error", err.Error())
+			return err
+		}
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: do http error", "error", err)
+			return err
+		}
+
+		// The webhook and the REST API both respond whether the message is sent successfully.
+		var resp rocketChatResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: decode response body error", "error", err)
+			return err
+		}
+
+		if !resp.Success {
+			_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: rocketchat response error", "error", resp.Error)
+			return fmt.Errorf("rocketchat response error, %s", resp.Error)
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "RocketChatNotifier: send message", "channel", msg.Channel)
+
+		return nil
+	}
+
+	// Messages of each message type.
+	messages := make(map[string][]*rocketChatMessage)
+	for _, r := range n.rocketchat {
+		if _, ok := messages[r.Type]; ok {
+			continue
+		}
+
+		var msgs []*rocketChatMessage
+		if r.Type == config.RocketChatAttachment {
+			title, err := n.template.TempleText(n.titleTemplateName, data, n.logger)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: generate title error", "error", err.Error())
+				return []error{err}
+			}
+			msgs = n.attachmentMessages(title, data)
+		} else {
+			texts, err := n.template.SplitWithDecoration(data, n.messageMaxSize, n.templateName, n.decoration, n.logger)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "RocketChatNotifier: split message error", "error", err.Error())
+				return []error{err}
+			}
+
+			for _, text := range texts {
+				msgs = append(msgs, &rocketChatMessage{Text: text})
+			}
+		}
+
+		messages[r.Type] = msgs
+	}
+
+	group := async.NewGroup(ctx)
+	for _, rocketchat := range n.rocketchat {
+		r := rocketchat
+
+		// The message is sent to the default channel of the webhook if no channel is set.
+		channels := r.Channels
+		if len(channels) == 0 {
+			channels = []string{""}
+		}
+
+		for _, channel := range channels {
+			for _, m := range messages[r.Type] {
+				msg := &rocketChatMessage{
+					Channel:     channel,
+					Text:        m.Text,
+					Attachments: m.Attachments,
+				}
+				group.Add(func(stopCh chan interface{}) {
+					stopCh <- send(r, msg)
+				})
+			}
+		}
+	}
+
+	return group.Wait()
+}
+
+// Create the request which posts the message to the webhook, or to the chat.postMessage API with the token.
+func (n *Notifier) newRequest(r *config.RocketChat, body *bytes.Buffer) (*http.Request, error) {
+
+	if r.RocketChatConfig.Webhook != nil {
+		webhook, err := n.notifierCfg.GetSecretData(r.GetNamespace(), r.RocketChatConfig.Webhook)
+		if err != nil {
+			return nil, err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, webhook, body)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		return request, nil
+	}
+
+	userID, err := n.notifierCfg.GetSecretData(r.GetNamespace(), r.RocketChatConfig.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := n.notifierCfg.GetSecretData(r.GetNamespace(), r.RocketChatConfig.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := notifier.UrlWithPath(r.RocketChatConfig.APIURL, postMessagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-User-Id", userID)
+	request.Header.Set("X-Auth-Token", token)
+	return request, nil
+}
+
+// Generate an attachment for each alert, the attachments will be split into multiple messages
+// if the number of attachments is greater than the limit.
+func (n *Notifier) attachmentMessages(title string, data template.Data) []*rocketChatMessage {
+
+	var attachments []*rocketChatAttachment
+	for _, alert := range data.Alerts {
+		attachment := &rocketChatAttachment{
+			Title:     truncate(fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Status), alert.Labels[alertNameLabel]), TitleMaxSize),
+			TitleLink: alert.GeneratorURL,
+			Color:     color(alert),
+		}
+
+		for _, name := range textAnnotations {
+			if v := alert.Annotations[name]; len(v) > 0 {
+				attachment.Text = truncate(v, n.messageMaxSize)
+				break
+			}
+		}
+
+		if !alert.StartsAt.IsZero() {
+			attachment.Timestamp = alert.StartsAt.Format(time.RFC3339)
+		}
+
+		for _, pair := range alert.Labels.SortedPairs() {
+			if pair.Name == alertNameLabel {
+				continue
+			}
+
+			attachment.Fields = append(attachment.Fields, &rocketChatField{
+				Short: true,
+				Title: pair.Name,
+				Value: pair.Value,
+			})
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	var messages []*rocketChatMessage
+	for i := 0; i < len(attachments); i += AttachmentsMaxSize {
+		end := i + AttachmentsMaxSize
+		if end > len(attachments) {
+			end = len(attachments)
+		}
+
+		messages = append(messages, &rocketChatMessage{
+			Text:        truncate(title, n.messageMaxSize),
+			Attachments: attachments[i:end],
+		})
+	}
+
+	return messages
+}
+
+// The color of the attachment is decided by the status and severity of the alert.
+func color(alert template.Alert) string {
+
+	if alert.Status == statusResolved {
+		return ColorResolved
+	}
+
+	switch strings.ToLower(alert.Labels[severityLabel]) {
+	case "critical", "error":
+		return ColorCritical
+	case "warning":
+		return ColorWarning
+	case "info":
+		return ColorInfo
+	default:
+		return ColorDefault
+	}
+}
+
+func truncate(s string, size int) string {
+
+	rs := []rune(s)
+	if len(rs) <= size {
+		return s
+	}
+
+	return string(rs[:size-3]) + "..."
+}
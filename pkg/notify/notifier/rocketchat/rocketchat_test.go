@@ -0,0 +1,412 @@
+package rocketchat
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+const testNamespace = testutil.Namespace
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	_ = os.Setenv("ROCKETCHAT_USER_ID", "user-id")
+	_ = os.Setenv("ROCKETCHAT_TOKEN", "auth-token")
+	os.Exit(m.Run())
+}
+
+// A request received by the stub of Rocket.Chat.
+type rocketChatRequest struct {
+	path      string
+	userID    string
+	authToken string
+	message   rocketChatMessage
+}
+
+// A stub of Rocket.Chat, it records the requests and responds with the handler.
+type rocketChatServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []rocketChatRequest
+}
+
+// Create the stub, it responds success if the handler is nil.
+func newRocketChatServer(t *testing.T, handler func(w http.ResponseWriter, n int)) *rocketChatServer {
+
+	s := &rocketChatServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg rocketChatMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, rocketChatRequest{
+			path:      r.URL.Path,
+			userID:    r.Header.Get("X-User-Id"),
+			authToken: r.Header.Get("X-Auth-Token"),
+			message:   msg,
+		})
+		n := len(s.requests)
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(w, n)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+
+	return s
+}
+
+func (s *rocketChatServer) received() []rocketChatRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]rocketChatRequest(nil), s.requests...)
+}
+
+func envSelector(name string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://" + name}}
+}
+
+// Create a receiver posting to the incoming webhook, the url of the webhook is read from the environment variable.
+func newWebhookReceiver(url, msgType string) *config.RocketChat {
+
+	name := "ROCKETCHAT_WEBHOOK_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	_ = os.Setenv(name, url+"/hooks/token")
+
+	r := config.NewRocketChatReceiver().(*config.RocketChat)
+	r.SetNamespace(testNamespace)
+	r.Type = msgType
+	r.RocketChatConfig = &config.RocketChatConfig{Webhook: envSelector(name)}
+
+	return r
+}
+
+// Create a receiver posting to the REST API.
+func newAPIReceiver(url, msgType string, channels ...string) *config.RocketChat {
+
+	r := config.NewRocketChatReceiver().(*config.RocketChat)
+	r.SetNamespace(testNamespace)
+	r.Type = msgType
+	r.Channels = channels
+	r.RocketChatConfig = &config.RocketChatConfig{
+		APIURL: url,
+		UserID: envSelector("ROCKETCHAT_USER_ID"),
+		Token:  envSelector("ROCKETCHAT_TOKEN"),
+	}
+
+	return r
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.RocketChatOptions, receivers ...*config.RocketChat) *Notifier {
+
+	c := testutil.NewConfig(nil, &v1alpha1.Options{RocketChat: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewRocketChatNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(names ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range names {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": name},
+			Annotations: template.KV{},
+			StartsAt:    time.Now(),
+		})
+	}
+
+	return data
+}
+
+func TestNotifyAttachment(t *testing.T) {
+
+	s := newRocketChatServer(t, nil)
+	defer s.Close()
+
+	data := newData("critical", "error", "warning", "info", "none", "resolved")
+	for _, a := range data.Alerts[:4] {
+		a.Labels["severity"] = a.Labels["alertname"]
+	}
+	data.Alerts[0].Annotations["message"] = "pod crash looping"
+	data.Alerts[0].Annotations["summary"] = "ignored"
+	data.Alerts[1].Annotations["description"] = "error rate"
+	data.Alerts[0].GeneratorURL = "https://prometheus.example.com/graph"
+	// The resolved alert is green whatever the severity is.
+	data.Alerts[5].Status = "resolved"
+	data.Alerts[5].Labels["severity"] = "critical"
+
+	n := newNotifier(t, nil, newWebhookReceiver(s.URL, config.RocketChatAttachment))
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	rs := s.received()
+	if len(rs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(rs))
+	}
+
+	r := rs[0]
+	if r.path != "/hooks/token" || r.message.Channel != "" {
+		t.Errorf("expected the message posted to the default channel of the webhook, got %s, channel %q", r.path, r.message.Channel)
+	}
+	if r.message.Text != "6 alerts firing" {
+		t.Errorf("expected the title as the text, got %q", r.message.Text)
+	}
+
+	colors := map[string]string{
+		"critical": ColorCritical,
+		"error":    ColorCritical,
+		"warning":  ColorWarning,
+		"info":     ColorInfo,
+		"none":     ColorDefault,
+		"resolved": ColorResolved,
+	}
+	if len(r.message.Attachments) != len(colors) {
+		t.Fatalf("expected %d attachments, got %d", len(colors), len(r.message.Attachments))
+	}
+
+	for i, a := range r.message.Attachments {
+		name := data.Alerts[i].Labels["alertname"]
+		if want := fmt.Sprintf("[%s] %s", strings.ToUpper(data.Alerts[i].Status), name); a.Title != want {
+			t.Errorf("%s: expected title %q, got %q", name, want, a.Title)
+		}
+		if a.Color != colors[name] {
+			t.Errorf("%s: expected color %s, got %s", name, colors[name], a.Color)
+		}
+		if a.Timestamp != data.Alerts[i].StartsAt.Format(time.RFC3339) {
+			t.Errorf("%s: expected the timestamp of the start time, got %q", name, a.Timestamp)
+		}
+		// The alert name is the title rather than a field.
+		for _, f := range a.Fields {
+			if f.Title == "alertname" {
+				t.Errorf("%s: expected no field of the alert name", name)
+			}
+		}
+	}
+
+	first := r.message.Attachments[0]
+	if first.Text != "pod crash looping" || first.TitleLink != "https://prometheus.example.com/graph" {
+		t.Errorf("expected the message and the generator url, got %q and %q", first.Text, first.TitleLink)
+	}
+	if f := first.Fields; len(f) != 1 || f[0].Title != "severity" || f[0].Value != "critical" || !f[0].Short {
+		t.Errorf("expected the severity field, got %+v", f)
+	}
+	if text := r.message.Attachments[1].Text; text != "error rate" {
+		t.Errorf("expected the description as the text, got %q", text)
+	}
+}
+
+func TestNotifyAttachmentSplit(t *testing.T) {
+
+	s := newRocketChatServer(t, nil)
+	defer s.Close()
+
+	var names []string
+	for i := 0; i < AttachmentsMaxSize+5; i++ {
+		names = append(names, fmt.Sprintf("alert%d", i))
+	}
+
+	n := newNotifier(t, nil, newWebhookReceiver(s.URL, config.RocketChatAttachment))
+	if errs := n.Notify(context.Background(), newData(names...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var sizes []int
+	for _, r := range s.received() {
+		sizes = append(sizes, len(r.message.Attachments))
+	}
+	sort.Ints(sizes)
+
+	if expected := []int{5, AttachmentsMaxSize}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("expected the attachments split into %v, got %v", expected, sizes)
+	}
+}
+
+func TestNotifyText(t *testing.T) {
+
+	s := newRocketChatServer(t, nil)
+	defer s.Close()
+
+	n := newNotifier(t, nil, newAPIReceiver(s.URL, config.RocketChatText, "#alerts", "@admin"))
+	if errs := n.Notify(context.Background(), newData("alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	rs := s.received()
+	var channels []string
+	for _, r := range rs {
+		channels = append(channels, r.message.Channel)
+
+		if r.path != postMessagePath || r.userID != "user-id" || r.authToken != "auth-token" {
+			t.Errorf("expected the message posted to the REST API with the token, got %s, %q, %q", r.path, r.userID, r.authToken)
+		}
+		if r.message.Text != "[firing] alert1\n[firing] alert2" || len(r.message.Attachments) != 0 {
+			t.Errorf("expected the text message, got %+v", r.message)
+		}
+	}
+
+	sort.Strings(channels)
+	if expected := []string{"#alerts", "@admin"}; !reflect.DeepEqual(channels, expected) {
+		t.Errorf("expected the message sent to %q, got %q", expected, channels)
+	}
+}
+
+func TestNotifyTextSplit(t *testing.T) {
+
+	s := newRocketChatServer(t, nil)
+	defer s.Close()
+
+	var names []string
+	for i := 0; i < 10; i++ {
+		names = append(names, fmt.Sprintf("alert%d", i))
+	}
+
+	n := newNotifier(t, &v1alpha1.RocketChatOptions{MessageMaxSize: 50}, newWebhookReceiver(s.URL, config.RocketChatText))
+	if errs := n.Notify(context.Background(), newData(names...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	rs := s.received()
+	if len(rs) < 2 {
+		t.Fatalf("expected the message split, got %d messages", len(rs))
+	}
+
+	var lines []string
+	for _, r := range rs {
+		if size := utf8.RuneCountInString(r.message.Text); size > 50 {
+			t.Errorf("expected each message no longer than 50, got %d", size)
+		}
+		lines = append(lines, strings.Split(strings.TrimSpace(r.message.Text), "\n")...)
+	}
+
+	// All the alerts are sent.
+	if len(lines) != len(names) {
+		t.Errorf("expected %d alerts sent, got %q", len(names), lines)
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, n int)
+		err     string
+	}{
+		{
+			name: "unsuccessful",
+			handler: func(w http.ResponseWriter, n int) {
+				_, _ = w.Write([]byte(`{"success":false,"error":"error-room-not-found"}`))
+			},
+			err: "rocketchat response error, error-room-not-found",
+		},
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, n int) {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"status":"error","message":"You must be logged in to do this."}`))
+			},
+			err: "401",
+		},
+		{
+			// The response which can not be decoded fails the sending.
+			name: "malformed",
+			handler: func(w http.ResponseWriter, n int) {
+				_, _ = w.Write([]byte(`ok`))
+			},
+			err: "",
+		},
+	}
+
+	for _, tt := range tests {
+		s := newRocketChatServer(t, tt.handler)
+
+		n := newNotifier(t, nil, newAPIReceiver(s.URL, config.RocketChatText, "#alerts"))
+		errs := n.Notify(context.Background(), newData("alert1"))
+		s.Close()
+
+		if len(errs) != 1 {
+			t.Errorf("%s: expected 1 error, got %v", tt.name, errs)
+			continue
+		}
+		if !strings.Contains(errs[0].Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %s", tt.name, tt.err, errs[0])
+		}
+	}
+}
+
+func TestNotifyInvalidReceiver(t *testing.T) {
+
+	s := newRocketChatServer(t, nil)
+	defer s.Close()
+
+	valid := newAPIReceiver(s.URL, config.RocketChatText, "#alerts")
+	// The REST API requires the channels.
+	noChannel := newAPIReceiver(s.URL, config.RocketChatText)
+	unknownType := newWebhookReceiver(s.URL, "card")
+
+	n := newNotifier(t, nil, valid, noChannel, unknownType)
+	if len(n.rocketchat) != 1 {
+		t.Fatalf("expected the invalid receivers ignored, got %d receivers", len(n.rocketchat))
+	}
+
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if rs := s.received(); len(rs) != 1 || rs[0].message.Channel != "#alerts" {
+		t.Errorf("expected 1 message sent to #alerts, got %+v", rs)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+
+	tests := []struct {
+		s        string
+		size     int
+		expected string
+	}{
+		{"alert", 10, "alert"},
+		{"alert", 5, "alert"},
+		{"KubePodCrashLooping", 10, "KubePod..."},
+		{"告警告警告警", 5, "告警..."},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.size); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.s, tt.expected, got)
+		}
+	}
+}
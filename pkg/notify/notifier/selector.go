@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"fmt"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"regexp"
+)
+
+const (
+	OperatorIn           = "In"
+	OperatorNotIn        = "NotIn"
+	OperatorExists       = "Exists"
+	OperatorDoesNotExist = "DoesNotExist"
+	OperatorRegex        = "Regex"
+	OperatorNotRegex     = "NotRegex"
+)
+
+// FilterAlerts returns the data which only contains the alerts matching the selector.
+func FilterAlerts(data template.Data, selector *v1alpha1.AlertSelector) (template.Data, error) {
+
+	if selector == nil {
+		return data, nil
+	}
+
+	d := data
+	d.Alerts = nil
+	for _, alert := range data.Alerts {
+		ok, err := MatchAlert(alert.Labels, selector)
+		if err != nil {
+			return template.Data{}, err
+		}
+
+		if ok {
+			d.Alerts = append(d.Alerts, alert)
+		}
+	}
+
+	return d, nil
+}
+
+// MatchAlert checks whether the labels of the alert match all the requirements of the selector.
+func MatchAlert(labels template.KV, selector *v1alpha1.AlertSelector) (bool, error) {
+
+	if selector == nil {
+		return true, nil
+	}
+
+	for k, v := range selector.MatchLabels {
+		if value, ok := labels[k]; !ok || value != v {
+			return false, nil
+		}
+	}
+
+	for _, r := range selector.MatchExpressions {
+		ok, err := matchRequirement(labels, r)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchRequirement(labels template.KV, r v1alpha1.AlertSelectorRequirement) (bool, error) {
+
+	value, exists := labels[r.Key]
+
+	switch r.Operator {
+	case OperatorExists:
+		return exists, nil
+	case OperatorDoesNotExist:
+		return !exists, nil
+	case OperatorIn:
+		return exists && sliceContains(r.Values, value), nil
+	case OperatorNotIn:
+		return !exists || !sliceContains(r.Values, value), nil
+	case OperatorRegex, OperatorNotRegex:
+		matched := false
+		for _, v := range r.Values {
+			// The regular expression is anchored, the same as the matchers of Alertmanager.
+			re, err := regexp.Compile("^(?:" + v + ")$")
+			if err != nil {
+				return false, err
+			}
+
+			if re.MatchString(value) {
+				matched = true
+				break
+			}
+		}
+		return matched == (r.Operator == OperatorRegex), nil
+	default:
+		return false, fmt.Errorf("unknown operator %s", r.Operator)
+	}
+}
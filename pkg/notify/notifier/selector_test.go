@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/prometheus/alertmanager/template"
+	"testing"
+)
+
+func TestMatchAlert(t *testing.T) {
+
+	labels := template.KV{"team": "payments", "severity": "critical", "namespace": "kube-system"}
+
+	requirement := func(key, op string, values ...string) *v1alpha1.AlertSelector {
+		return &v1alpha1.AlertSelector{
+			MatchExpressions: []v1alpha1.AlertSelectorRequirement{{Key: key, Operator: op, Values: values}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		selector *v1alpha1.AlertSelector
+		want     bool
+	}{
+		{"nil", nil, true},
+		{"empty", &v1alpha1.AlertSelector{}, true},
+		{"equal", &v1alpha1.AlertSelector{MatchLabels: map[string]string{"team": "payments"}}, true},
+		{"not equal", &v1alpha1.AlertSelector{MatchLabels: map[string]string{"team": "orders"}}, false},
+		{"missing label", &v1alpha1.AlertSelector{MatchLabels: map[string]string{"cluster": "prod"}}, false},
+		{"in", requirement("severity", OperatorIn, "warning", "critical"), true},
+		{"not in", requirement("severity", OperatorNotIn, "warning", "critical"), false},
+		{"not in missing", requirement("cluster", OperatorNotIn, "prod"), true},
+		{"exists", requirement("team", OperatorExists), true},
+		{"exists missing", requirement("cluster", OperatorExists), false},
+		{"does not exist", requirement("cluster", OperatorDoesNotExist), true},
+		{"regex", requirement("namespace", OperatorRegex, "kube-.*"), true},
+		{"regex alternatives", requirement("team", OperatorRegex, "orders", "pay.*"), true},
+		// The regular expression is anchored.
+		{"regex anchored", requirement("namespace", OperatorRegex, "kube"), false},
+		{"not regex", requirement("namespace", OperatorNotRegex, "kube-.*"), false},
+		{"not regex matched", requirement("namespace", OperatorNotRegex, "default|monitoring"), true},
+		{"all requirements", &v1alpha1.AlertSelector{
+			MatchLabels: map[string]string{"team": "payments"},
+			MatchExpressions: []v1alpha1.AlertSelectorRequirement{
+				{Key: "severity", Operator: OperatorIn, Values: []string{"warning"}},
+			},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := MatchAlert(labels, tt.selector)
+		if err != nil {
+			t.Errorf("%s: unexpected error, %s", tt.name, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.want, got)
+		}
+	}
+
+	for name, s := range map[string]*v1alpha1.AlertSelector{
+		"invalid regex":    requirement("team", OperatorRegex, "("),
+		"unknown operator": requirement("team", "Like", "pay"),
+	} {
+		if _, err := MatchAlert(labels, s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFilterAlerts(t *testing.T) {
+
+	data := template.Data{
+		Receiver: "test",
+		Alerts: template.Alerts{
+			{Labels: template.KV{"team": "payments"}, Fingerprint: "a"},
+			{Labels: template.KV{"team": "orders"}, Fingerprint: "b"},
+			{Labels: template.KV{"team": "payments"}, Fingerprint: "c"},
+		},
+	}
+
+	d, err := FilterAlerts(data, &v1alpha1.AlertSelector{MatchLabels: map[string]string{"team": "payments"}})
+	if err != nil {
+		t.Fatalf("filter alerts error, %s", err)
+	}
+
+	if len(d.Alerts) != 2 || d.Alerts[0].Fingerprint != "a" || d.Alerts[1].Fingerprint != "c" {
+		t.Errorf("expected the alerts a and c, got %v", d.Alerts)
+	}
+
+	if d.Receiver != "test" || len(data.Alerts) != 3 {
+		t.Error("expected the data given unchanged")
+	}
+
+	d, err = FilterAlerts(data, &v1alpha1.AlertSelector{MatchLabels: map[string]string{"team": "search"}})
+	if err != nil {
+		t.Fatalf("filter alerts error, %s", err)
+	}
+
+	if len(d.Alerts) != 0 {
+		t.Errorf("expected no alert, got %d", len(d.Alerts))
+	}
+
+	if d, _ := FilterAlerts(data, nil); len(d.Alerts) != 3 {
+		t.Errorf("expected all alerts without the selector, got %d", len(d.Alerts))
+	}
+}
@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The maximum number of the short urls cached, the cache is cleared when it is full.
+	ShortURLCacheMaxSize = 10000
+)
+
+// URLShortener shortens the long urls, such as the urls of Grafana or Prometheus, by a shortener service.
+// The service receives a POST request with body `{"url": "<long url>"}`, and responds the short url in
+// the field `shortUrl` of a json body, or in a plain text body.
+type URLShortener struct {
+	endpoint string
+	// Get the token which is sent in the Authorization header, no token is sent if it returns empty.
+	getToken func() (string, error)
+	client   *http.Client
+	mutex    sync.Mutex
+	cache    map[string]string
+}
+
+type shortenRequest struct {
+	URL string `json:"url"`
+}
+
+type shortenResponse struct {
+	ShortURL string `json:"shortUrl"`
+}
+
+var shortener *URLShortener
+var shortenerMutex sync.RWMutex
+
+func NewURLShortener(endpoint string, getToken func() (string, error), timeout time.Duration) *URLShortener {
+	return &URLShortener{
+		endpoint: endpoint,
+		getToken: getToken,
+		client:   &http.Client{Timeout: timeout},
+		cache:    make(map[string]string),
+	}
+}
+
+// SetURLShortener sets the shortener used by the template function shortURL, the shortening is disabled if it is nil.
+func SetURLShortener(s *URLShortener) {
+
+	shortenerMutex.Lock()
+	defer shortenerMutex.Unlock()
+
+	shortener = s
+}
+
+// Shorten the url, the result is cached by the original url.
+func (s *URLShortener) Shorten(ctx context.Context, u string) (string, error) {
+
+	s.mutex.Lock()
+	short, ok := s.cache[u]
+	s.mutex.Unlock()
+	if ok {
+		return short, nil
+	}
+
+	bs, err := json.Marshal(&shortenRequest{URL: u})
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(bs))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	if s.getToken != nil {
+		token, err := s.getToken()
+		if err != nil {
+			return "", err
+		}
+		if len(token) > 0 {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	body, err := DoHttpRequest(ctx, s.client, request)
+	if err != nil {
+		return "", err
+	}
+
+	var resp shortenResponse
+	if err := json.Unmarshal(body, &resp); err == nil {
+		short = resp.ShortURL
+	} else {
+		short = strings.TrimSpace(string(body))
+	}
+
+	if len(short) == 0 {
+		return "", fmt.Errorf("the shortener responds an empty url")
+	}
+
+	s.mutex.Lock()
+	if len(s.cache) >= ShortURLCacheMaxSize {
+		s.cache = make(map[string]string)
+	}
+	s.cache[u] = short
+	s.mutex.Unlock()
+
+	return short, nil
+}
+
+// The template function to shorten the url, the original url is returned if the shortening is disabled or failed,
+// so the message can always be generated.
+func shortURL(u string) string {
+
+	shortenerMutex.RLock()
+	s := shortener
+	shortenerMutex.RUnlock()
+
+	if s == nil || len(u) == 0 {
+		return u
+	}
+
+	short, err := s.Shorten(context.Background(), u)
+	if err != nil {
+		mutex.Lock()
+		l := templateLogger
+		mutex.Unlock()
+		_ = level.Warn(l).Log("msg", "shorten url error, use the original url", "url", u, "error", err.Error())
+		return u
+	}
+
+	return short
+}
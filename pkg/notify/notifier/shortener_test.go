@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const longURL = "https://grafana.example.com/d/abc/cluster?orgId=1&var-namespace=kube-system&from=now-1h&to=now"
+
+// A stub of the shortener service, it responds the short url in json or in plain text.
+type shortenerServer struct {
+	*httptest.Server
+	requests int32
+	// The authorization header of the last request.
+	auth atomic.Value
+}
+
+func newShortenerServer(t *testing.T, plain bool) *shortenerServer {
+
+	s := &shortenerServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&s.requests, 1)
+		s.auth.Store(r.Header.Get("Authorization"))
+
+		req := shortenRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request error, %s", err)
+		}
+		if req.URL != longURL {
+			t.Errorf("expected the url %s, got %s", longURL, req.URL)
+		}
+
+		if plain {
+			_, _ = w.Write([]byte("https://s.example.com/plain\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"shortUrl":"https://s.example.com/` + string(rune('a'+n-1)) + `"}`))
+	}))
+
+	return s
+}
+
+func TestShorten(t *testing.T) {
+
+	s := newShortenerServer(t, false)
+	defer s.Close()
+
+	shortener := NewURLShortener(s.URL, func() (string, error) { return "token", nil }, time.Second)
+	short, err := shortener.Shorten(context.Background(), longURL)
+	if err != nil {
+		t.Fatalf("shorten error, %s", err)
+	}
+
+	if short != "https://s.example.com/a" {
+		t.Errorf("expected https://s.example.com/a, got %s", short)
+	}
+
+	if auth := s.auth.Load(); auth != "Bearer token" {
+		t.Errorf("expected the bearer token, got %v", auth)
+	}
+
+	// The short url is cached by the original url.
+	for i := 0; i < 3; i++ {
+		if short, _ := shortener.Shorten(context.Background(), longURL); short != "https://s.example.com/a" {
+			t.Errorf("expected the cached url, got %s", short)
+		}
+	}
+	if n := atomic.LoadInt32(&s.requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestShortenPlainText(t *testing.T) {
+
+	s := newShortenerServer(t, true)
+	defer s.Close()
+
+	// No token is sent if it is empty.
+	shortener := NewURLShortener(s.URL, func() (string, error) { return "", nil }, time.Second)
+	short, err := shortener.Shorten(context.Background(), longURL)
+	if err != nil {
+		t.Fatalf("shorten error, %s", err)
+	}
+
+	if short != "https://s.example.com/plain" {
+		t.Errorf("expected https://s.example.com/plain, got %s", short)
+	}
+
+	if auth := s.auth.Load(); auth != "" {
+		t.Errorf("expected no authorization, got %v", auth)
+	}
+}
+
+func TestShortenError(t *testing.T) {
+
+	failed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failed.Close()
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"shortUrl":""}`))
+	}))
+	defer empty.Close()
+
+	tests := []struct {
+		name      string
+		shortener *URLShortener
+	}{
+		{"server error", NewURLShortener(failed.URL, nil, time.Second)},
+		{"empty url", NewURLShortener(empty.URL, nil, time.Second)},
+		{"token error", NewURLShortener(empty.URL, func() (string, error) { return "", errors.New("secret not found") }, time.Second)},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.shortener.Shorten(context.Background(), longURL); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+		}
+
+		// The failed shortening is not cached.
+		if len(tt.shortener.cache) != 0 {
+			t.Errorf("%s: expected nothing cached, got %v", tt.name, tt.shortener.cache)
+		}
+	}
+}
+
+func TestTemplateShortURL(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	render := func() string {
+		data := template.Data{
+			Alerts: template.Alerts{{Status: "firing", Labels: template.KV{}, Annotations: template.KV{"dashboard": longURL}}},
+		}
+		msg, err := tmpl.TempleText("test.shorturl", data, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("render template error, %s", err)
+		}
+		return msg
+	}
+
+	// The original url is used if the shortening is disabled.
+	SetURLShortener(nil)
+	if msg := render(); msg != longURL {
+		t.Errorf("expected the original url, got %s", msg)
+	}
+
+	s := newShortenerServer(t, false)
+	SetURLShortener(NewURLShortener(s.URL, nil, time.Second))
+	defer SetURLShortener(nil)
+
+	if msg := render(); msg != "https://s.example.com/a" {
+		t.Errorf("expected the short url, got %s", msg)
+	}
+
+	// The cached url is used even if the shortener is down.
+	s.Close()
+	if msg := render(); msg != "https://s.example.com/a" {
+		t.Errorf("expected the cached short url, got %s", msg)
+	}
+
+	// The original url is used if the shortening failed.
+	SetURLShortener(NewURLShortener(s.URL, nil, time.Second))
+	if msg := render(); msg != longURL {
+		t.Errorf("expected the original url, got %s", msg)
+	}
+}
@@ -20,6 +20,9 @@ const (
 	DefaultSendTimeout = time.Second * 3
 	URL                = "https://slack.com/api/chat.postMessage"
 	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The maximum size of the text of a section block.
+	SectionTextMaxSize = 3000
+	ImageAltText       = "alert graph"
 )
 
 type Notifier struct {
@@ -29,11 +32,26 @@ type Notifier struct {
 	logger       log.Logger
 	template     *notifier.Template
 	templateName string
+	// The url of the api posting the messages.
+	url string
 }
 
 type slackRequest struct {
-	Channel string `json:"channel"`
-	Text    string `json:"text"`
+	Channel string        `json:"channel"`
+	Text    string        `json:"text"`
+	Blocks  []*slackBlock `json:"blocks,omitempty"`
+}
+
+type slackBlockText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string          `json:"type"`
+	Text     *slackBlockText `json:"text,omitempty"`
+	ImageURL string          `json:"image_url,omitempty"`
+	AltText  string          `json:"alt_text,omitempty"`
 }
 
 type slackResponse struct {
@@ -41,17 +59,18 @@ type slackResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
-func NewSlackNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) notifier.Notifier {
+func NewSlackNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
 
 	var path []string
+	var externalURL string
 	opts := notifierCfg.ReceiverOpts
 	if opts != nil && opts.Global != nil {
 		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
 	}
-	tmpl, err := notifier.NewTemplate(path)
+	tmpl, err := notifier.NewTemplate(path, externalURL)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "SlackNotifier: get template error", "error", err.Error())
-		return nil
+		return nil, fmt.Errorf("get template error, %s", err.Error())
 	}
 
 	n := &Notifier{
@@ -60,6 +79,7 @@ func NewSlackNotifier(logger log.Logger, receivers []config.Receiver, notifierCf
 		logger:       logger,
 		template:     tmpl,
 		templateName: DefaultTemplate,
+		url:          URL,
 	}
 
 	if opts != nil && opts.Slack != nil {
@@ -81,15 +101,15 @@ func NewSlackNotifier(logger log.Logger, receivers []config.Receiver, notifierCf
 			continue
 		}
 
-		if receiver.SlackConfig == nil {
-			_ = level.Warn(logger).Log("msg", "SlackNotifier: ignore receiver because of empty config")
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "SlackNotifier: ignore invalid receiver", "error", err.Error())
 			continue
 		}
 
 		n.slack = append(n.slack, receiver)
 	}
 
-	return n
+	return n, nil
 }
 
 func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
@@ -112,13 +132,28 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 			Text:    msg,
 		}
 
+		// The text is only used as the fallback when there are blocks, so it is also sent in the section blocks.
+		if imageURL := n.imageURL(c, data); len(imageURL) > 0 {
+			for _, text := range notifier.SplitString(msg, SectionTextMaxSize) {
+				sr.Blocks = append(sr.Blocks, &slackBlock{
+					Type: "section",
+					Text: &slackBlockText{Type: "mrkdwn", Text: text},
+				})
+			}
+			sr.Blocks = append(sr.Blocks, &slackBlock{
+				Type:     "image",
+				ImageURL: imageURL,
+				AltText:  ImageAltText,
+			})
+		}
+
 		var buf bytes.Buffer
 		if err := json.NewEncoder(&buf).Encode(sr); err != nil {
 			_ = level.Error(n.logger).Log("msg", "SlackNotifier: encode message error", "error", err.Error())
 			return err
 		}
 
-		request, err := http.NewRequest(http.MethodPost, URL, &buf)
+		request, err := http.NewRequest(http.MethodPost, n.url, &buf)
 		if err != nil {
 			return err
 		}
@@ -132,6 +167,10 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 
 		request.Header.Set("Authorization", "Bearer "+token)
 
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
 		body, err := notifier.DoHttpRequest(ctx, nil, request.WithContext(ctx))
 		if err != nil {
 			_ = level.Error(n.logger).Log("msg", "SlackNotifier: do http error", "error", err)
@@ -164,3 +203,29 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 
 	return group.Wait()
 }
+
+// Generate the url of the image attached to the message, the message is sent without the image if it failed.
+func (n *Notifier) imageURL(c *config.Slack, data template.Data) string {
+
+	if len(c.ImageURLTemplate) == 0 {
+		return ""
+	}
+
+	var token string
+	if c.SlackConfig.ImageRenderToken != nil {
+		t, err := n.notifierCfg.GetSecretData(c.GetNamespace(), c.SlackConfig.ImageRenderToken)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "SlackNotifier: get image render token secret", "error", err.Error())
+			return ""
+		}
+		token = t
+	}
+
+	imageURL, err := n.template.ImageURL(c.ImageURLTemplate, data, token, c.SlackConfig.ImageURLExpires, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "SlackNotifier: generate image url error", "error", err.Error())
+		return ""
+	}
+
+	return imageURL
+}
@@ -0,0 +1,265 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testNamespace = testutil.Namespace
+	imageTemplate = `{{ with .CommonLabels.panel }}https://grafana.test/render/d-solo/nm?panelId={{ . }}{{ end }}`
+	imageURL      = "https://grafana.test/render/d-solo/nm?panelId=2"
+)
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A stub of the slack api, it records the messages and the authorization headers received.
+type slackServer struct {
+	*httptest.Server
+	mu             sync.Mutex
+	messages       []slackRequest
+	authorizations []string
+}
+
+func newSlackServer(t *testing.T) *slackServer {
+
+	s := &slackServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackRequest
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.authorizations = append(s.authorizations, r.Header.Get("Authorization"))
+		s.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+
+	return s
+}
+
+func (s *slackServer) sent() []slackRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]slackRequest(nil), s.messages...)
+}
+
+func newReceiver(t *testing.T, imageURLTemplate string) *config.Slack {
+
+	s := config.NewSlackReceiver().(*config.Slack)
+	s.SetNamespace(testNamespace)
+	s.Channel = "alerts"
+	s.ImageURLTemplate = imageURLTemplate
+	s.SlackConfig = &config.SlackConfig{Token: secrets.NewSecret(t, "slack-token")}
+
+	return s
+}
+
+// Create the notifier posting to the stub rather than the slack api.
+func newNotifier(t *testing.T, url string, receivers ...*config.Slack) *Notifier {
+
+	c := testutil.NewConfig(secrets, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewSlackNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	sn := n.(*Notifier)
+	sn.url = url
+	return sn
+}
+
+// Create the alerts with the panels given, the alert has no panel label if the panel is empty.
+func newData(status string, panels ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: status}
+	for i, panel := range panels {
+		alert := template.Alert{
+			Status: status,
+			Labels: template.KV{"alertname": fmt.Sprintf("alert%d", i+1)},
+		}
+		if len(panel) > 0 {
+			alert.Labels["panel"] = panel
+		}
+		data.Alerts = append(data.Alerts, alert)
+	}
+
+	return data
+}
+
+// Return the image blocks and the text of the section blocks of the message,
+// the text is split on the newline boundaries so the sections are joined by the newlines.
+func blocks(msg slackRequest) ([]*slackBlock, string) {
+
+	var images []*slackBlock
+	var texts []string
+	for _, b := range msg.Blocks {
+		switch b.Type {
+		case "image":
+			images = append(images, b)
+		case "section":
+			texts = append(texts, b.Text.Text)
+		}
+	}
+
+	return images, strings.Join(texts, "\n")
+}
+
+func TestNotifyImage(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		template string
+		panels   []string
+		image    string
+	}{
+		{"not set", "", []string{"2"}, ""},
+		{"render url", imageTemplate, []string{"2", "2"}, imageURL},
+		// The panel is not a common label, so the template renders empty and no image is attached.
+		{"render empty", imageTemplate, []string{"2", "3"}, ""},
+		// The message is sent without the image if the url is invalid.
+		{"invalid url", "/render/d-solo/nm", []string{"2"}, ""},
+	}
+
+	for _, test := range tests {
+		s := newSlackServer(t)
+
+		n := newNotifier(t, s.URL, newReceiver(t, test.template))
+		if errs := n.Notify(context.Background(), newData("firing", test.panels...)); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", test.name, errs)
+		}
+
+		msgs := s.sent()
+		s.Close()
+		if len(msgs) != 1 {
+			t.Errorf("%s: expected 1 message, got %d", test.name, len(msgs))
+			continue
+		}
+
+		msg := msgs[0]
+		if msg.Channel != "alerts" || !strings.HasPrefix(msg.Text, "[firing] alert1") {
+			t.Errorf("%s: expected the text sent to the channel, got %s %q", test.name, msg.Channel, msg.Text)
+		}
+
+		// There are no blocks without the image, the text is shown.
+		if len(test.image) == 0 {
+			if len(msg.Blocks) != 0 {
+				t.Errorf("%s: expected no blocks, got %d", test.name, len(msg.Blocks))
+			}
+			continue
+		}
+
+		images, text := blocks(msg)
+		if len(images) != 1 || images[0].ImageURL != test.image || images[0].AltText != ImageAltText {
+			t.Errorf("%s: expected the image block of %s, got %v", test.name, test.image, images)
+		}
+
+		// The text is only a fallback when there are blocks, so it is also sent in the section blocks.
+		if text != msg.Text {
+			t.Errorf("%s: expected the text in the section blocks, got %q", test.name, text)
+		}
+
+		if last := msg.Blocks[len(msg.Blocks)-1]; last.Type != "image" {
+			t.Errorf("%s: expected the image block after the text, got %s", test.name, last.Type)
+		}
+	}
+}
+
+func TestNotifyImageSections(t *testing.T) {
+
+	s := newSlackServer(t)
+	defer s.Close()
+
+	// The text longer than the limit of a section block is split into several section blocks.
+	var panels []string
+	for i := 0; i < SectionTextMaxSize/10; i++ {
+		panels = append(panels, "2")
+	}
+
+	n := newNotifier(t, s.URL, newReceiver(t, imageTemplate))
+	if errs := n.Notify(context.Background(), newData("firing", panels...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	sections := 0
+	for _, b := range msgs[0].Blocks {
+		if b.Type != "section" {
+			continue
+		}
+		sections++
+		if len(b.Text.Text) > SectionTextMaxSize {
+			t.Errorf("expected the section text at most %d, got %d", SectionTextMaxSize, len(b.Text.Text))
+		}
+	}
+
+	if sections < 2 {
+		t.Errorf("expected the text split into section blocks, got %d", sections)
+	}
+
+	if images, text := blocks(msgs[0]); len(images) != 1 || text != msgs[0].Text {
+		t.Errorf("expected the text and the image in the blocks, got %d images", len(images))
+	}
+}
+
+func TestNotifyImageSigned(t *testing.T) {
+
+	s := newSlackServer(t)
+	defer s.Close()
+
+	r := newReceiver(t, imageTemplate)
+	r.SlackConfig.ImageRenderToken = secrets.NewSecret(t, "render-token")
+
+	n := newNotifier(t, s.URL, r)
+	if errs := n.Notify(context.Background(), newData("firing", "2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	images, _ := blocks(msgs[0])
+	if len(images) != 1 {
+		t.Fatalf("expected the image block, got %d", len(images))
+	}
+
+	// The url is signed by the render token, the token is not exposed.
+	u := images[0].ImageURL
+	if !strings.Contains(u, "panelId=2") || !strings.Contains(u, "&signature=") || strings.Contains(u, "render-token") {
+		t.Errorf("expected the signed image url, got %s", u)
+	}
+
+	// The slack token authorizes the request, the render token is not used.
+	s.mu.Lock()
+	auth := s.authorizations[0]
+	s.mu.Unlock()
+	if auth != "Bearer slack-token" {
+		t.Errorf("expected the slack token, got %s", auth)
+	}
+}
@@ -0,0 +1,166 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	providerAliyun         = "aliyun"
+	aliyunDefaultEndpoint  = "https://dysmsapi.aliyuncs.com"
+	aliyunDefaultParam     = "content"
+	aliyunAPIVersion       = "2017-05-25"
+	aliyunRegion           = "cn-hangzhou"
+	aliyunPhonesMaxSize    = 1000
+	aliyunMessageMaxLength = 500
+)
+
+func init() {
+	Register(providerAliyun, newAliyunProvider, aliyunPhonesMaxSize, aliyunMessageMaxLength)
+}
+
+type aliyunProvider struct {
+	config          *v1alpha1.AliyunSMS
+	accessKeyID     string
+	accessKeySecret string
+	timeout         time.Duration
+}
+
+type aliyunResponse struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	RequestID string `json:"RequestId"`
+}
+
+func newAliyunProvider(c *config.SMSConfig, getSecret notifier.SecretFunc, timeout time.Duration) (Provider, error) {
+
+	if c.Aliyun == nil {
+		return nil, errEmptyConfig(providerAliyun)
+	}
+
+	id, err := getSecret(c.Aliyun.AccessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := getSecret(c.Aliyun.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aliyunProvider{
+		config:          c.Aliyun,
+		accessKeyID:     id,
+		accessKeySecret: secret,
+		timeout:         timeout,
+	}, nil
+}
+
+func (p *aliyunProvider) Send(ctx context.Context, phones []string, text string) error {
+
+	paramName := p.config.TemplateParam
+	if len(paramName) == 0 {
+		paramName = aliyunDefaultParam
+	}
+
+	param, err := json.Marshal(map[string]string{paramName: text})
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	parameters := map[string]string{
+		"Action":           "SendSms",
+		"Version":          aliyunAPIVersion,
+		"RegionId":         aliyunRegion,
+		"Format":           "JSON",
+		"AccessKeyId":      p.accessKeyID,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   hex.EncodeToString(nonce),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"PhoneNumbers":     strings.Join(phones, ","),
+		"SignName":         p.config.SignName,
+		"TemplateCode":     p.config.TemplateCode,
+		"TemplateParam":    string(param),
+	}
+	parameters["Signature"] = p.sign(http.MethodGet, parameters)
+
+	endpoint := p.config.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = aliyunDefaultEndpoint
+	}
+
+	u, err := notifier.UrlWithParameters(endpoint, parameters)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: p.timeout}, request)
+	if err != nil {
+		return err
+	}
+
+	var resp aliyunResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	if resp.Code != "OK" {
+		return fmt.Errorf("aliyun sms error, code: %s, message: %s, requestId: %s", resp.Code, resp.Message, resp.RequestID)
+	}
+
+	return nil
+}
+
+// Sign the request with the signature version 1.0 of the Aliyun RPC API.
+func (p *aliyunProvider) sign(method string, parameters map[string]string) string {
+
+	var keys []string
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, aliyunEscape(k)+"="+aliyunEscape(parameters[k]))
+	}
+
+	stringToSign := method + "&" + aliyunEscape("/") + "&" + aliyunEscape(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(p.accessKeySecret+"&"))
+	_, _ = mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Escape the string according to RFC 3986 as required by the Aliyun API.
+func aliyunEscape(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
@@ -0,0 +1,240 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+)
+
+// Provider sends the SMS message to the phones.
+type Provider interface {
+	Send(ctx context.Context, phones []string, text string) error
+}
+
+// ProviderFactory creates the provider with the config, the secrets of the provider can be got by getSecret.
+type ProviderFactory func(c *config.SMSConfig, getSecret notifier.SecretFunc, timeout time.Duration) (Provider, error)
+
+type registration struct {
+	factory ProviderFactory
+	// The maximum number of phones in one request.
+	batchSize int
+	// The maximum number of characters of the message.
+	maxLength int
+}
+
+var providers = make(map[string]*registration)
+var mutex sync.Mutex
+
+// Register a provider, the provider is selected by the provider name of the SMS config.
+func Register(name string, factory ProviderFactory, batchSize, maxLength int) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	providers[name] = &registration{
+		factory:   factory,
+		batchSize: batchSize,
+		maxLength: maxLength,
+	}
+}
+
+func getProvider(name string) *registration {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return providers[name]
+}
+
+type Notifier struct {
+	notifierCfg  *config.Config
+	sms          map[string]*config.SMS
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+}
+
+func NewSMSNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		sms:          make(map[string]*config.SMS),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+	}
+
+	if opts != nil && opts.SMS != nil {
+
+		if opts.SMS.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.SMS.NotificationTimeout)
+		}
+
+		if len(opts.SMS.Template) > 0 {
+			n.templateName = opts.SMS.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.SMS)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "SMSNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		if getProvider(receiver.SMSConfig.Provider) == nil {
+			_ = level.Warn(logger).Log("msg", "SMSNotifier: ignore receiver because of unknown provider", "provider", receiver.SMSConfig.Provider)
+			continue
+		}
+
+		// The receivers which use the same provider will be merged.
+		key, err := notifier.Md5key(receiver.SMSConfig)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "SMSNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		s, ok := n.sms[key]
+		if !ok {
+			c := *receiver
+			c.PhoneNumbers = nil
+			s = &c
+		}
+
+		for _, phone := range receiver.PhoneNumbers {
+			if phone = strings.TrimSpace(phone); len(phone) > 0 && !contains(s.PhoneNumbers, phone) {
+				s.PhoneNumbers = append(s.PhoneNumbers, phone)
+			}
+		}
+
+		n.sms[key] = s
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	msg, err := n.template.TempleText(n.templateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "SMSNotifier: generate message error", "error", err.Error())
+		return []error{err}
+	}
+
+	send := func(p Provider, s *config.SMS, phones []string, text string) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "SMSNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		if err := p.Send(ctx, phones, text); err != nil {
+			_ = level.Error(n.logger).Log("msg", "SMSNotifier: send message error", "provider", s.SMSConfig.Provider, "error", err.Error())
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "SMSNotifier: send message", "provider", s.SMSConfig.Provider, "phones", strings.Join(phones, ","))
+
+		return nil
+	}
+
+	// The errors of creating the providers, the messages of the other receivers are still sent.
+	var errs []error
+	group := async.NewGroup(ctx)
+	for _, sms := range n.sms {
+		s := sms
+		if len(s.PhoneNumbers) == 0 {
+			continue
+		}
+
+		r := getProvider(s.SMSConfig.Provider)
+		getSecret := func(selector *v1.SecretKeySelector) (string, error) {
+			return n.notifierCfg.GetSecretData(s.GetNamespace(), selector)
+		}
+
+		p, err := r.factory(s.SMSConfig, getSecret, n.timeout)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "SMSNotifier: create provider error", "provider", s.SMSConfig.Provider, "error", err.Error())
+			errs = append(errs, err)
+			continue
+		}
+
+		text := truncate(msg, r.maxLength)
+		for i := 0; i < len(s.PhoneNumbers); i += r.batchSize {
+			end := i + r.batchSize
+			if end > len(s.PhoneNumbers) {
+				end = len(s.PhoneNumbers)
+			}
+
+			phones := s.PhoneNumbers[i:end]
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(p, s, phones, text)
+			})
+		}
+	}
+
+	return append(group.Wait(), errs...)
+}
+
+// Truncate the message to the max length, the message is cut on the rune boundary.
+func truncate(s string, max int) string {
+
+	rs := []rune(s)
+	if max <= 0 || len(rs) <= max {
+		return s
+	}
+
+	return string(rs[:max])
+}
+
+func contains(src []string, elem string) bool {
+	for _, s := range src {
+		if s == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
+func errEmptyConfig(provider string) error {
+	return fmt.Errorf("the config of sms provider %s is empty", provider)
+}
@@ -0,0 +1,377 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testNamespace = testutil.Namespace
+	providerFake  = "fake"
+	fakeBatchSize = 2
+	fakeMaxLength = 10
+)
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+func TestMain(m *testing.M) {
+
+	Register(providerFake, newFakeProvider, fakeBatchSize, fakeMaxLength)
+	os.Exit(m.Run())
+}
+
+// The messages sent by the fake provider, they are keyed by the access key id of the provider.
+var fakeSent = struct {
+	sync.Mutex
+	messages map[string][]fakeMessage
+}{messages: make(map[string][]fakeMessage)}
+
+type fakeMessage struct {
+	phones []string
+	text   string
+}
+
+type fakeProvider struct {
+	id string
+}
+
+// The fake provider reads the access key id of the aliyun config, so it fails if the secret does not exist.
+func newFakeProvider(c *config.SMSConfig, getSecret notifier.SecretFunc, _ time.Duration) (Provider, error) {
+
+	id, err := getSecret(c.Aliyun.AccessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeProvider{id: id}, nil
+}
+
+func (p *fakeProvider) Send(_ context.Context, phones []string, text string) error {
+
+	fakeSent.Lock()
+	defer fakeSent.Unlock()
+
+	fakeSent.messages[p.id] = append(fakeSent.messages[p.id], fakeMessage{append([]string(nil), phones...), text})
+	return nil
+}
+
+// Return the messages sent by the provider with the id, the phones of the messages are sorted by the order of the batches.
+func sentBy(id string) []fakeMessage {
+
+	fakeSent.Lock()
+	defer fakeSent.Unlock()
+
+	msgs := append([]fakeMessage(nil), fakeSent.messages[id]...)
+	sort.Slice(msgs, func(i, j int) bool {
+		return strings.Join(msgs[i].phones, ",") < strings.Join(msgs[j].phones, ",")
+	})
+	return msgs
+}
+
+// Create a receiver of the fake provider, the id is read from the secret so the messages of the test are separated,
+// the receivers sharing the secret share the provider.
+func newFakeReceiver(secret *v1.SecretKeySelector, phones ...string) *config.SMS {
+
+	s := config.NewSMSReceiver().(*config.SMS)
+	s.SetNamespace(testNamespace)
+	s.PhoneNumbers = phones
+	s.SMSConfig = &config.SMSConfig{
+		Provider: providerFake,
+		Aliyun:   &v1alpha1.AliyunSMS{AccessKeyID: secret},
+	}
+
+	return s
+}
+
+func newNotifier(t *testing.T, receivers ...*config.SMS) *Notifier {
+
+	c := testutil.NewConfig(secrets, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewSMSNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(status string, alerts ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: status}
+	for _, name := range alerts {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status: status,
+			Labels: template.KV{"alertname": name},
+		})
+	}
+
+	return data
+}
+
+func TestTruncate(t *testing.T) {
+
+	tests := []struct {
+		s        string
+		max      int
+		expected string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello"},
+		{"hello", 0, "hello"},
+		// The message is cut on the rune boundary.
+		{"告警恢复了", 2, "告警"},
+		{"a告警", 2, "a告"},
+	}
+
+	for _, test := range tests {
+		if got := truncate(test.s, test.max); got != test.expected {
+			t.Errorf("%q %d: expected %q, got %q", test.s, test.max, test.expected, got)
+		}
+	}
+}
+
+func TestNotifyBatch(t *testing.T) {
+
+	id := fmt.Sprintf("batch%d", time.Now().UnixNano())
+	secret := secrets.NewSecret(t, id)
+
+	// The phones are trimmed and deduplicated, then sent in the batches of the provider.
+	n := newNotifier(t,
+		newFakeReceiver(secret, "1001", " 1002 ", "", "1003"),
+		newFakeReceiver(secret, "1002", "1004", "1005"))
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := sentBy(id)
+	var batches [][]string
+	for _, msg := range msgs {
+		batches = append(batches, msg.phones)
+		if msg.text != "[firing] a" {
+			t.Errorf("expected the text truncated to %d characters, got %q", fakeMaxLength, msg.text)
+		}
+	}
+
+	expected := [][]string{{"1001", "1002"}, {"1003", "1004"}, {"1005"}}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Errorf("expected the batches %v, got %v", expected, batches)
+	}
+}
+
+func TestNotifyProviderError(t *testing.T) {
+
+	id := fmt.Sprintf("healthy%d", time.Now().UnixNano())
+
+	// The provider of the broken receiver can not be created, the messages of the healthy receiver are still sent.
+	broken := newFakeReceiver(&v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "sms-fake-id-not-exist"}, Key: "value"}, "2001")
+
+	n := newNotifier(t, broken, newFakeReceiver(secrets.NewSecret(t, id), "1001", "1002", "1003"))
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 {
+		t.Errorf("expected the error of the broken receiver, got %v", errs)
+	}
+
+	if msgs := sentBy(id); len(msgs) != 2 {
+		t.Errorf("expected 2 batches sent to the healthy receiver, got %d", len(msgs))
+	}
+}
+
+func TestAliyunSign(t *testing.T) {
+
+	// The example of the signature in the document of the Aliyun SMS API.
+	p := &aliyunProvider{accessKeySecret: "testSecret"}
+	parameters := map[string]string{
+		"AccessKeyId":      "testId",
+		"Action":           "SendSms",
+		"Format":           "XML",
+		"OutId":            "123",
+		"PhoneNumbers":     "15300000001",
+		"RegionId":         "cn-hangzhou",
+		"SignName":         "阿里云短信测试专用",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   "45e25e9b-0a6f-4070-8c85-2956eda1b466",
+		"SignatureVersion": "1.0",
+		"TemplateCode":     "SMS_71390007",
+		"TemplateParam":    `{"customer":"test"}`,
+		"Timestamp":        "2017-07-12T02:42:19Z",
+		"Version":          "2017-05-25",
+	}
+
+	if got := p.sign(http.MethodGet, parameters); got != "zJDF+Lrzhj/ThnlvIToysFRq6t4=" {
+		t.Errorf("expected the signature of the example, got %s", got)
+	}
+}
+
+func TestTC3Sign(t *testing.T) {
+
+	// The example of the signature v3 in the document of the Tencent Cloud API, the payload keeps the escaped characters.
+	payload := []byte(`{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`)
+	got := tc3Sign("AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE", "Gu5t9xGARNpq86cd98joQYCN3EXAMPLE",
+		"cvm", "cvm.tencentcloudapi.com", payload, time.Unix(1551113065, 0))
+
+	expected := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/cvm/tc3_request, " +
+		"SignedHeaders=content-type;host, Signature=72e494ea809ad7a8c8f7a4507b9bddcbaa8e581f516e8da2f66e2c5a96525168"
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+// The access keys of the providers, they are read by the key of the selector.
+var testKeys = map[string]string{"id": "testId", "secret": "testSecret"}
+
+func keySelector(key string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "sms"}, Key: key}
+}
+
+func TestAliyunSend(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		response string
+		err      bool
+	}{
+		{"ok", `{"Code":"OK","Message":"OK","RequestId":"1"}`, false},
+		{"error", `{"Code":"isv.MOBILE_NUMBER_ILLEGAL","Message":"illegal","RequestId":"2"}`, true},
+	}
+
+	for _, test := range tests {
+		var query map[string][]string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			_, _ = w.Write([]byte(test.response))
+		}))
+
+		c := &config.SMSConfig{
+			Provider: providerAliyun,
+			Aliyun: &v1alpha1.AliyunSMS{
+				Endpoint:        s.URL,
+				SignName:        "nm",
+				TemplateCode:    "SMS_1",
+				AccessKeyID:     keySelector("id"),
+				AccessKeySecret: keySelector("secret"),
+			},
+		}
+		getSecret := func(selector *v1.SecretKeySelector) (string, error) {
+			return testKeys[selector.Key], nil
+		}
+
+		p, err := newAliyunProvider(c, getSecret, time.Second)
+		if err != nil {
+			t.Fatalf("%s: create provider error, %s", test.name, err)
+		}
+
+		err = p.Send(context.Background(), []string{"1001", "1002"}, "告警")
+		s.Close()
+		if test.err != (err != nil) {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+
+		if got := strings.Join(query["PhoneNumbers"], ""); got != "1001,1002" {
+			t.Errorf("%s: expected the phones sent, got %s", test.name, got)
+		}
+
+		if got := strings.Join(query["TemplateParam"], ""); got != `{"content":"告警"}` {
+			t.Errorf("%s: expected the text in the template param, got %s", test.name, got)
+		}
+
+		// The signature is computed over all the other parameters.
+		signature := strings.Join(query["Signature"], "")
+		parameters := make(map[string]string)
+		for k, v := range query {
+			if k != "Signature" {
+				parameters[k] = v[0]
+			}
+		}
+		if expected := p.(*aliyunProvider).sign(http.MethodGet, parameters); signature != expected {
+			t.Errorf("%s: expected the signature %s, got %s", test.name, expected, signature)
+		}
+	}
+}
+
+func TestTencentSend(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		response string
+		err      string
+	}{
+		{"ok", `{"Response":{"SendStatusSet":[{"PhoneNumber":"+861001","Code":"Ok"},{"PhoneNumber":"+861002","Code":"Ok"}],"RequestId":"1"}}`, ""},
+		// One of the phones fails, the error names the phone.
+		{"phone error", `{"Response":{"SendStatusSet":[{"PhoneNumber":"+861001","Code":"Ok"},{"PhoneNumber":"+861002","Code":"LimitExceeded.PhoneNumberDailyLimit","Message":"limited"}],"RequestId":"2"}}`, "+861002"},
+		{"request error", `{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"signature failure"},"RequestId":"3"}}`, "AuthFailure.SignatureFailure"},
+	}
+
+	for _, test := range tests {
+		var request tencentRequest
+		var header http.Header
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &request); err != nil {
+				t.Errorf("%s: decode request error, %s", test.name, err)
+			}
+			_, _ = w.Write([]byte(test.response))
+		}))
+
+		c := &config.SMSConfig{
+			Provider: providerTencent,
+			Tencent: &v1alpha1.TencentSMS{
+				Endpoint:    s.URL,
+				SmsSdkAppID: "1400000000",
+				SignName:    "nm",
+				TemplateID:  "1",
+				SecretID:    keySelector("id"),
+				SecretKey:   keySelector("secret"),
+			},
+		}
+		getSecret := func(selector *v1.SecretKeySelector) (string, error) {
+			return testKeys[selector.Key], nil
+		}
+
+		p, err := newTencentProvider(c, getSecret, time.Second)
+		if err != nil {
+			t.Fatalf("%s: create provider error, %s", test.name, err)
+		}
+
+		err = p.Send(context.Background(), []string{"+861001", "+861002"}, "告警")
+		s.Close()
+		if len(test.err) == 0 && err != nil {
+			t.Errorf("%s: expected no error, got %s", test.name, err)
+		} else if len(test.err) > 0 && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%s: expected the error of %s, got %v", test.name, test.err, err)
+		}
+
+		if !reflect.DeepEqual(request.PhoneNumberSet, []string{"+861001", "+861002"}) || !reflect.DeepEqual(request.TemplateParamSet, []string{"告警"}) {
+			t.Errorf("%s: expected the phones and the text sent, got %v", test.name, request)
+		}
+
+		if header.Get("X-TC-Region") != tencentDefaultRegion || !strings.HasPrefix(header.Get("Authorization"), "TC3-HMAC-SHA256 Credential=testId/") {
+			t.Errorf("%s: expected the signed request of the default region, got %v", test.name, header)
+		}
+	}
+}
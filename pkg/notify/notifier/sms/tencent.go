@@ -0,0 +1,184 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	providerTencent         = "tencent"
+	tencentDefaultEndpoint  = "https://sms.tencentcloudapi.com"
+	tencentDefaultRegion    = "ap-guangzhou"
+	tencentService          = "sms"
+	tencentAPIVersion       = "2021-01-11"
+	tencentAlgorithm        = "TC3-HMAC-SHA256"
+	tencentContentType      = "application/json; charset=utf-8"
+	tencentPhonesMaxSize    = 200
+	tencentMessageMaxLength = 500
+)
+
+func init() {
+	Register(providerTencent, newTencentProvider, tencentPhonesMaxSize, tencentMessageMaxLength)
+}
+
+type tencentProvider struct {
+	config    *v1alpha1.TencentSMS
+	secretID  string
+	secretKey string
+	timeout   time.Duration
+}
+
+type tencentRequest struct {
+	PhoneNumberSet   []string `json:"PhoneNumberSet"`
+	SmsSdkAppID      string   `json:"SmsSdkAppId"`
+	SignName         string   `json:"SignName"`
+	TemplateID       string   `json:"TemplateId"`
+	TemplateParamSet []string `json:"TemplateParamSet"`
+}
+
+type tencentResponse struct {
+	Response struct {
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error,omitempty"`
+		SendStatusSet []struct {
+			PhoneNumber string `json:"PhoneNumber"`
+			Code        string `json:"Code"`
+			Message     string `json:"Message"`
+		} `json:"SendStatusSet"`
+		RequestID string `json:"RequestId"`
+	} `json:"Response"`
+}
+
+func newTencentProvider(c *config.SMSConfig, getSecret notifier.SecretFunc, timeout time.Duration) (Provider, error) {
+
+	if c.Tencent == nil {
+		return nil, errEmptyConfig(providerTencent)
+	}
+
+	id, err := getSecret(c.Tencent.SecretID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := getSecret(c.Tencent.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tencentProvider{
+		config:    c.Tencent,
+		secretID:  id,
+		secretKey: key,
+		timeout:   timeout,
+	}, nil
+}
+
+func (p *tencentProvider) Send(ctx context.Context, phones []string, text string) error {
+
+	payload, err := json.Marshal(&tencentRequest{
+		PhoneNumberSet:   phones,
+		SmsSdkAppID:      p.config.SmsSdkAppID,
+		SignName:         p.config.SignName,
+		TemplateID:       p.config.TemplateID,
+		TemplateParamSet: []string{text},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := p.config.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = tencentDefaultEndpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+
+	region := p.config.Region
+	if len(region) == 0 {
+		region = tencentDefaultRegion
+	}
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	request.Header.Set("Content-Type", tencentContentType)
+	request.Header.Set("X-TC-Action", "SendSms")
+	request.Header.Set("X-TC-Version", tencentAPIVersion)
+	request.Header.Set("X-TC-Region", region)
+	request.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	request.Header.Set("Authorization", tc3Sign(p.secretID, p.secretKey, tencentService, u.Host, payload, now))
+
+	body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: p.timeout}, request)
+	if err != nil {
+		return err
+	}
+
+	var resp tencentResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	if e := resp.Response.Error; e != nil {
+		return fmt.Errorf("tencent sms error, code: %s, message: %s, requestId: %s", e.Code, e.Message, resp.Response.RequestID)
+	}
+
+	// The sending result of each phone.
+	for _, s := range resp.Response.SendStatusSet {
+		if s.Code != "Ok" {
+			return fmt.Errorf("tencent sms error, phone: %s, code: %s, message: %s, requestId: %s",
+				s.PhoneNumber, s.Code, s.Message, resp.Response.RequestID)
+		}
+	}
+
+	return nil
+}
+
+// Sign the POST request of the service with the signature v3 of the Tencent Cloud API, it returns the authorization header.
+func tc3Sign(secretID, secretKey, service, host string, payload []byte, t time.Time) string {
+
+	date := t.UTC().Format("2006-01-02")
+	canonicalRequest := fmt.Sprintf("POST\n/\n\ncontent-type:%s\nhost:%s\n\ncontent-type;host\n%s",
+		tencentContentType, host, sha256Hex(payload))
+
+	scope := fmt.Sprintf("%s/%s/tc3_request", date, service)
+	stringToSign := fmt.Sprintf("%s\n%d\n%s\n%s", tencentAlgorithm, t.Unix(), scope, sha256Hex([]byte(canonicalRequest)))
+
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		tencentAlgorithm, secretID, scope, signature)
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
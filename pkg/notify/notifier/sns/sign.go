@@ -0,0 +1,173 @@
+package sns
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	service     = "sns"
+	algorithm   = "AWS4-HMAC-SHA256"
+	contentType = "application/x-www-form-urlencoded; charset=utf-8"
+	amzDate     = "20060102T150405Z"
+	// The instance metadata service which provides the credentials of the instance role.
+	metadataEndpoint   = "http://169.254.169.254"
+	metadataTokenPath  = "/latest/api/token"
+	metadataCredsPath  = "/latest/meta-data/iam/security-credentials/"
+	metadataTokenTTL   = "21600"
+	instanceRoleKey    = "sns | instance-role"
+	instanceRoleMargin = time.Minute * 5
+)
+
+type credentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token,omitempty"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// Get the credentials from the secrets, or from the instance role if the secrets are not set.
+func (n *Notifier) credentials(ctx context.Context, s *config.SNS) (*credentials, error) {
+
+	if s.SNSConfig.AccessKeyID != nil {
+		id, err := n.notifierCfg.GetSecretData(s.GetNamespace(), s.SNSConfig.AccessKeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := n.notifierCfg.GetSecretData(s.GetNamespace(), s.SNSConfig.SecretAccessKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return &credentials{AccessKeyID: id, SecretAccessKey: key}, nil
+	}
+
+	// The temporary credentials of the instance role are cached by the access token service.
+	token, err := notifier.GetAccessTokenService().GetToken(ctx, instanceRoleKey, n.instanceRoleCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &credentials{}
+	if err := json.Unmarshal([]byte(token), c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Get the credentials of the instance role from the instance metadata service (IMDSv2).
+func (n *Notifier) instanceRoleCredentials(ctx context.Context) (string, time.Duration, error) {
+
+	client := &http.Client{Timeout: n.timeout}
+
+	request, err := http.NewRequest(http.MethodPut, metadataEndpoint+metadataTokenPath, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	request.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", metadataTokenTTL)
+
+	token, err := notifier.DoHttpRequest(ctx, client, request)
+	if err != nil {
+		return "", 0, fmt.Errorf("get metadata token error, %s", err.Error())
+	}
+
+	get := func(path string) ([]byte, error) {
+		request, err := http.NewRequest(http.MethodGet, metadataEndpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("X-aws-ec2-metadata-token", string(token))
+		return notifier.DoHttpRequest(ctx, client, request)
+	}
+
+	role, err := get(metadataCredsPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("get instance role error, %s", err.Error())
+	}
+
+	name := strings.TrimSpace(strings.SplitN(string(role), "\n", 2)[0])
+	if len(name) == 0 {
+		return "", 0, fmt.Errorf("no instance role is attached")
+	}
+
+	body, err := get(metadataCredsPath + name)
+	if err != nil {
+		return "", 0, fmt.Errorf("get credentials of instance role error, %s", err.Error())
+	}
+
+	c := &credentials{}
+	if err := json.Unmarshal(body, c); err != nil {
+		return "", 0, err
+	}
+
+	// Expire the cached credentials before they actually expire.
+	expires := time.Hour
+	if t, err := time.Parse(time.RFC3339, c.Expiration); err == nil {
+		expires = time.Until(t) - instanceRoleMargin
+	}
+
+	bs, err := json.Marshal(c)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return string(bs), expires, nil
+}
+
+// Sign the request with AWS Signature Version 4.
+func sign(request *http.Request, u *url.URL, region string, payload []byte, c *credentials, t time.Time) {
+
+	now := t.UTC().Format(amzDate)
+	date := now[:8]
+
+	path := u.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	request.Header.Set("X-Amz-Date", now)
+	headers := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\n", contentType, u.Host, now)
+	signedHeaders := "content-type;host;x-amz-date"
+	if len(c.Token) > 0 {
+		request.Header.Set("X-Amz-Security-Token", c.Token)
+		headers += fmt.Sprintf("x-amz-security-token:%s\n", c.Token)
+		signedHeaders += ";x-amz-security-token"
+	}
+
+	canonicalRequest := fmt.Sprintf("POST\n%s\n\n%s\n%s\n%s", path, headers, signedHeaders, sha256Hex(payload))
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s", algorithm, now, scope, sha256Hex([]byte(canonicalRequest)))
+
+	secretDate := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	secretRegion := hmacSHA256(secretDate, region)
+	secretService := hmacSHA256(secretRegion, service)
+	secretSigning := hmacSHA256(secretService, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
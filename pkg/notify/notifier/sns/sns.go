@@ -0,0 +1,264 @@
+package sns
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The size of message is limited to 256 KB, including the message attributes.
+	MessageMaxSize = 256 * 1024
+	// The maximum count of message attributes.
+	AttributesMaxCount = 10
+	apiVersion         = "2010-03-31"
+	truncatedSuffix    = "..."
+)
+
+type Notifier struct {
+	notifierCfg  *config.Config
+	sns          map[string]*config.SNS
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+	decoration   *notifier.Decoration
+}
+
+type publishResponse struct {
+	MessageID string `xml:"PublishResult>MessageId"`
+}
+
+type errorResponse struct {
+	Code      string `xml:"Error>Code"`
+	Message   string `xml:"Error>Message"`
+	RequestID string `xml:"RequestId"`
+}
+
+func NewSNSNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		sns:          make(map[string]*config.SNS),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+		decoration:   &notifier.Decoration{Header: header, Footer: footer},
+	}
+
+	if opts != nil && opts.SNS != nil {
+
+		if opts.SNS.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.SNS.NotificationTimeout)
+		}
+
+		if len(opts.SNS.Template) > 0 {
+			n.templateName = opts.SNS.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.SNS.Header) > 0 {
+			n.decoration.Header = opts.SNS.Header
+		}
+
+		if len(opts.SNS.Footer) > 0 {
+			n.decoration.Footer = opts.SNS.Footer
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.SNS)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "SNSNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which publish to the same topic with the same attributes only need to be sent once.
+		key, err := notifier.Md5key(receiver)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "SNSNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.sns[key] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	msg, err := n.template.TempleText(n.templateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "SNSNotifier: generate message error", "error", err.Error())
+		return []error{err}
+	}
+
+	if msg, err = n.decoration.Decorate(n.template, msg, data, n.logger); err != nil {
+		_ = level.Error(n.logger).Log("msg", "SNSNotifier: decorate message error", "error", err.Error())
+		return []error{err}
+	}
+
+	send := func(s *config.SNS) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "SNSNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		creds, err := n.credentials(ctx, s)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "SNSNotifier: get credentials error", "error", err.Error())
+			return err
+		}
+
+		endpoint := s.SNSConfig.Endpoint
+		if len(endpoint) == 0 {
+			endpoint = fmt.Sprintf("https://sns.%s.amazonaws.com", s.SNSConfig.Region)
+		}
+
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+
+		values, size := attributes(data, s.AttributeLabels)
+		values.Set("Action", "Publish")
+		values.Set("Version", apiVersion)
+		values.Set("TopicArn", s.TopicARN)
+		values.Set("Message", truncate(msg, MessageMaxSize-size))
+		payload := []byte(values.Encode())
+
+		request, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", contentType)
+		sign(request, u, s.SNSConfig.Region, payload, creds, time.Now())
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			if he, ok := err.(*notifier.HttpError); ok {
+				var er errorResponse
+				if xml.Unmarshal([]byte(he.Message), &er) == nil && len(er.Code) > 0 {
+					err = fmt.Errorf("sns error, code: %s, message: %s, requestId: %s", er.Code, er.Message, er.RequestID)
+				}
+			}
+			_ = level.Error(n.logger).Log("msg", "SNSNotifier: publish message error", "topic", s.TopicARN, "error", err.Error())
+			return err
+		}
+
+		var resp publishResponse
+		_ = xml.Unmarshal(body, &resp)
+		_ = level.Debug(n.logger).Log("msg", "SNSNotifier: publish message", "topic", s.TopicARN, "messageId", resp.MessageID)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, sns := range n.sns {
+		s := sns
+		group.Add(func(stopCh chan interface{}) {
+			stopCh <- send(s)
+		})
+	}
+
+	return group.Wait()
+}
+
+// Generate the message attributes from the labels shared by all alerts, the size returned is the size
+// of the attributes which is counted in the message size.
+func attributes(data template.Data, labels []string) (url.Values, int) {
+
+	values := url.Values{}
+	if len(labels) == 0 {
+		return values, 0
+	}
+
+	var names []string
+	for _, name := range labels {
+		if v, ok := data.CommonLabels[name]; ok && len(v) > 0 && !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) > AttributesMaxCount {
+		names = names[:AttributesMaxCount]
+	}
+
+	size := 0
+	for i, name := range names {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i+1)
+		values.Set(prefix+".Name", name)
+		values.Set(prefix+".Value.DataType", "String")
+		values.Set(prefix+".Value.StringValue", data.CommonLabels[name])
+		size += len(name) + len("String") + len(data.CommonLabels[name])
+	}
+
+	return values, size
+}
+
+// Truncate the string to the max bytes, the string is never cut in the middle of a rune.
+func truncate(s string, max int) string {
+
+	if len(s) <= max {
+		return s
+	}
+
+	end := max - len(truncatedSuffix)
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+
+	return s[:end] + truncatedSuffix
+}
+
+func contains(src []string, elem string) bool {
+	for _, s := range src {
+		if s == elem {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,328 @@
+package sns
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-kit/kit/log"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	testNamespace = testutil.Namespace
+	testTopic     = "arn:aws:sns:us-east-1:123456789012:alerts"
+)
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	_ = os.Setenv("SNS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	_ = os.Setenv("SNS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	os.Exit(m.Run())
+}
+
+// A stub of the SNS publish API, it records the requests and the forms received.
+type snsServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	forms    []url.Values
+	// The error responded if it is set.
+	errorCode string
+}
+
+func newSNSServer(t *testing.T) *snsServer {
+
+	s := &snsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body error, %s", err)
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			t.Errorf("parse form error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		s.forms = append(s.forms, form)
+		code := s.errorCode
+		s.mu.Unlock()
+
+		if len(code) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<ErrorResponse><Error><Code>` + code + `</Code><Message>Topic does not exist</Message></Error><RequestId>req-1</RequestId></ErrorResponse>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<PublishResponse><PublishResult><MessageId>msg-1</MessageId></PublishResult></PublishResponse>`))
+	}))
+
+	return s
+}
+
+func (s *snsServer) sent() ([]*http.Request, []url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...), append([]url.Values(nil), s.forms...)
+}
+
+func envSelector(name string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://" + name}}
+}
+
+func newReceiver(endpoint string, labels ...string) *config.SNS {
+
+	s := config.NewSNSReceiver().(*config.SNS)
+	s.SetNamespace(testNamespace)
+	s.TopicARN = testTopic
+	s.AttributeLabels = labels
+	s.SNSConfig = &config.SNSConfig{
+		Region:          "us-east-1",
+		Endpoint:        endpoint,
+		AccessKeyID:     envSelector("SNS_ACCESS_KEY_ID"),
+		SecretAccessKey: envSelector("SNS_SECRET_ACCESS_KEY"),
+	}
+
+	return s
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.SNSOptions, receivers ...*config.SNS) *Notifier {
+
+	c := testutil.NewConfig(nil, &v1alpha1.Options{SNS: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewSNSNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(commonLabels template.KV, alertnames ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing", CommonLabels: commonLabels}
+	for _, name := range alertnames {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:   "firing",
+			Labels:   template.KV{"alertname": name},
+			StartsAt: time.Now(),
+		})
+	}
+
+	return data
+}
+
+func TestSign(t *testing.T) {
+
+	payload := []byte("Action=Publish&Message=hello&TopicArn=arn%3Aaws%3Asns%3Aus-east-1%3A123456789012%3Aalerts&Version=2010-03-31")
+	u, _ := url.Parse("https://sns.us-east-1.amazonaws.com")
+	now := time.Unix(1600000000, 0)
+
+	tests := []struct {
+		name          string
+		token         string
+		signedHeaders string
+		signature     string
+	}{
+		{"access key", "", "content-type;host;x-amz-date", "8a34c1a69e97ed341494bf9ebee700ed342fb45857e52bf96adf8e4d61358ea0"},
+		{"session token", "session-token", "content-type;host;x-amz-date;x-amz-security-token", "204f256139ae68ebabe7100ad443d25d9d6f7a7aaf6eaadefa354922f370e687"},
+	}
+
+	for _, tt := range tests {
+		request, _ := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(payload))
+		c := &credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", Token: tt.token}
+		sign(request, u, "us-east-1", payload, c, now)
+
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20200913/us-east-1/sns/aws4_request, SignedHeaders=" +
+			tt.signedHeaders + ", Signature=" + tt.signature
+		if got := request.Header.Get("Authorization"); got != want {
+			t.Errorf("%s: expected authorization %s, got %s", tt.name, want, got)
+		}
+
+		if got := request.Header.Get("X-Amz-Date"); got != "20200913T122640Z" {
+			t.Errorf("%s: expected date 20200913T122640Z, got %s", tt.name, got)
+		}
+
+		if got := request.Header.Get("X-Amz-Security-Token"); got != tt.token {
+			t.Errorf("%s: expected security token %q, got %q", tt.name, tt.token, got)
+		}
+	}
+}
+
+func TestNotifyPublish(t *testing.T) {
+
+	s := newSNSServer(t)
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "severity", "namespace", "cluster"))
+	data := newData(template.KV{"severity": "critical", "namespace": "kube-system", "alertname": "alert1"}, "alert1")
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	requests, forms := s.sent()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	r := requests[0]
+	if ct := r.Header.Get("Content-Type"); ct != contentType {
+		t.Errorf("expected content type %s, got %s", contentType, ct)
+	}
+
+	date := r.Header.Get("X-Amz-Date")
+	if _, err := time.Parse(amzDate, date); err != nil {
+		t.Errorf("expected the signing date, got %q", date)
+	}
+	prefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/" + date[:8] + "/us-east-1/sns/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature="
+	if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, prefix) || len(auth) != len(prefix)+64 {
+		t.Errorf("expected the signed request, got authorization %s", auth)
+	}
+
+	// The attributes are sorted by the name, and the missing labels are skipped.
+	want := url.Values{
+		"Action":                         {"Publish"},
+		"Version":                        {apiVersion},
+		"TopicArn":                       {testTopic},
+		"Message":                        {"[firing] alert1"},
+		"MessageAttributes.entry.1.Name": {"namespace"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {"kube-system"},
+		"MessageAttributes.entry.2.Name":              {"severity"},
+		"MessageAttributes.entry.2.Value.DataType":    {"String"},
+		"MessageAttributes.entry.2.Value.StringValue": {"critical"},
+	}
+	forms[0].Set("Message", strings.TrimSpace(forms[0].Get("Message")))
+	if !reflect.DeepEqual(forms[0], want) {
+		t.Errorf("expected form %v, got %v", want, forms[0])
+	}
+}
+
+func TestNotifyPublishError(t *testing.T) {
+
+	s := newSNSServer(t)
+	defer s.Close()
+	s.errorCode = "NotFound"
+
+	n := newNotifier(t, nil, newReceiver(s.URL))
+	errs := n.Notify(context.Background(), newData(nil, "alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	// The error of SNS is decoded.
+	if want := "sns error, code: NotFound, message: Topic does not exist, requestId: req-1"; errs[0].Error() != want {
+		t.Errorf("expected %s, got %s", want, errs[0].Error())
+	}
+}
+
+func TestNotifyMessageMaxSize(t *testing.T) {
+
+	s := newSNSServer(t)
+	defer s.Close()
+
+	var names []string
+	for i := 0; i < 20000; i++ {
+		names = append(names, "告警")
+	}
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "severity"))
+	if errs := n.Notify(context.Background(), newData(template.KV{"severity": "critical"}, names...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	_, forms := s.sent()
+	msg := forms[0].Get("Message")
+	if size := len(msg) + len("severity") + len("String") + len("critical"); size > MessageMaxSize {
+		t.Errorf("expected the message no larger than %d bytes with the attributes, got %d", MessageMaxSize, size)
+	}
+	if !strings.HasSuffix(msg, truncatedSuffix) || !utf8.ValidString(msg) {
+		t.Error("expected the message truncated at the rune boundary")
+	}
+}
+
+func TestAttributes(t *testing.T) {
+
+	labels := template.KV{"a": "1", "b": "", "c": "3"}
+	for i := 0; i < 12; i++ {
+		labels[string(rune('k'+i))] = "v"
+	}
+
+	var all []string
+	for name := range labels {
+		all = append(all, name)
+	}
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   []string
+		size   int
+	}{
+		{"no labels", nil, nil, 0},
+		{"missing and empty labels", []string{"c", "b", "x", "a", "c"}, []string{"a", "c"}, 2 * (1 + 6 + 1)},
+		{"max count", all, []string{"a", "c", "k", "l", "m", "n", "o", "p", "q", "r"}, 10 * (1 + 6 + 1)},
+	}
+
+	for _, tt := range tests {
+		values, size := attributes(template.Data{CommonLabels: labels}, tt.labels)
+
+		var names []string
+		for i := 1; ; i++ {
+			name := values.Get("MessageAttributes.entry." + strconv.Itoa(i) + ".Name")
+			if len(name) == 0 {
+				break
+			}
+			names = append(names, name)
+		}
+
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%s: expected attributes %v, got %v", tt.name, tt.want, names)
+		}
+		if size != tt.size {
+			t.Errorf("%s: expected size %d, got %d", tt.name, tt.size, size)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"hello", 5, "hello"},
+		{"hello world", 8, "hello..."},
+		{"告警告警", 8, "告..."},
+		{"告警告警", 9, "告警..."},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
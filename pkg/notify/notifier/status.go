@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReceiverStatus is the result of the last sending of a receiver.
+type ReceiverStatus struct {
+	Type     string `json:"type"`
+	Receiver string `json:"receiver"`
+	// The time of the last sending, whether it succeeded or not.
+	LastSendTime    time.Time  `json:"lastSendTime"`
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+	LastErrorTime   *time.Time `json:"lastErrorTime,omitempty"`
+	LastError       string     `json:"lastError,omitempty"`
+}
+
+// StatusRegistry records the status of the receivers in memory, the receiver is identified by the notifier type
+// and the name of the receiver.
+type StatusRegistry struct {
+	mutex    sync.Mutex
+	statuses map[string]*ReceiverStatus
+}
+
+var statusRegistry *StatusRegistry
+
+func init() {
+	statusRegistry = &StatusRegistry{
+		statuses: make(map[string]*ReceiverStatus),
+	}
+}
+
+func GetStatusRegistry() *StatusRegistry {
+	return statusRegistry
+}
+
+// Record the result of a sending at the time t, the error is nil if the sending succeeded.
+func (r *StatusRegistry) Record(notifierType, receiver string, t time.Time, err error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := notifierType + " | " + receiver
+	s, ok := r.statuses[key]
+	if !ok {
+		s = &ReceiverStatus{
+			Type:     notifierType,
+			Receiver: receiver,
+		}
+		r.statuses[key] = s
+	}
+
+	s.LastSendTime = t
+	if err != nil {
+		s.LastErrorTime = &t
+		s.LastError = err.Error()
+	} else {
+		s.LastSuccessTime = &t
+	}
+}
+
+// List the status of all receivers, sorted by the type and the receiver.
+func (r *StatusRegistry) List() []ReceiverStatus {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	statuses := make([]ReceiverStatus, 0, len(r.statuses))
+	for _, s := range r.statuses {
+		statuses = append(statuses, *s)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Type != statuses[j].Type {
+			return statuses[i].Type < statuses[j].Type
+		}
+		return statuses[i].Receiver < statuses[j].Receiver
+	})
+
+	return statuses
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusRegistry(t *testing.T) {
+
+	r := &StatusRegistry{statuses: make(map[string]*ReceiverStatus)}
+
+	t1 := time.Now()
+	t2 := t1.Add(time.Second)
+	r.Record("wechat", "receiver-b", t1, nil)
+	r.Record("wechat", "receiver-a", t1, errors.New("send error"))
+	r.Record("slack", "receiver-b", t1, nil)
+	// The last success is kept after a failure.
+	r.Record("wechat", "receiver-b", t2, errors.New("invalid userid"))
+
+	statuses := r.List()
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+
+	// The statuses are sorted by the type and the receiver.
+	var keys []string
+	for _, s := range statuses {
+		keys = append(keys, s.Type+"/"+s.Receiver)
+	}
+	if want := "slack/receiver-b wechat/receiver-a wechat/receiver-b"; strings.Join(keys, " ") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(keys, " "))
+	}
+
+	s := statuses[2]
+	if !s.LastSendTime.Equal(t2) {
+		t.Errorf("expected the last send time %s, got %s", t2, s.LastSendTime)
+	}
+	if s.LastSuccessTime == nil || !s.LastSuccessTime.Equal(t1) {
+		t.Errorf("expected the last success time %s, got %v", t1, s.LastSuccessTime)
+	}
+	if s.LastErrorTime == nil || !s.LastErrorTime.Equal(t2) || s.LastError != "invalid userid" {
+		t.Errorf("expected the last error at %s, got %v at %v", t2, s.LastError, s.LastErrorTime)
+	}
+
+	if s := statuses[1]; s.LastSuccessTime != nil || s.LastError != "send error" {
+		t.Errorf("expected only the error of receiver-a, got %+v", s)
+	}
+}
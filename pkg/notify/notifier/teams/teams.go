@@ -0,0 +1,264 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	DefaultTitle       = `{{ template "nm.default.subject" . }}`
+	// The payload of the incoming webhook is limited to 28KB, leave some space for the other fields.
+	MessageMaxSize = 24 * 1024
+	ColorFiring    = "E6522C"
+	ColorResolved  = "2DC72D"
+	ImageTitle     = "alert graph"
+)
+
+type Notifier struct {
+	notifierCfg    *config.Config
+	teams          map[string]*config.Teams
+	timeout        time.Duration
+	logger         log.Logger
+	template       *notifier.Template
+	templateName   string
+	decoration     *notifier.Decoration
+	messageMaxSize int
+}
+
+// The legacy actionable message card supported by the incoming webhook.
+type teamsMessage struct {
+	Type       string          `json:"@type"`
+	Context    string          `json:"@context"`
+	ThemeColor string          `json:"themeColor,omitempty"`
+	Summary    string          `json:"summary"`
+	Title      string          `json:"title,omitempty"`
+	Text       string          `json:"text"`
+	Sections   []*teamsSection `json:"sections,omitempty"`
+}
+
+type teamsImage struct {
+	Image string `json:"image"`
+	Title string `json:"title,omitempty"`
+}
+
+type teamsSection struct {
+	Images []*teamsImage `json:"images"`
+}
+
+func NewTeamsNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:    notifierCfg,
+		teams:          make(map[string]*config.Teams),
+		timeout:        DefaultSendTimeout,
+		logger:         logger,
+		template:       tmpl,
+		templateName:   DefaultTemplate,
+		decoration:     &notifier.Decoration{Header: header, Footer: footer},
+		messageMaxSize: MessageMaxSize,
+	}
+
+	if opts != nil && opts.Teams != nil {
+
+		if opts.Teams.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Teams.NotificationTimeout)
+		}
+
+		if len(opts.Teams.Template) > 0 {
+			n.templateName = opts.Teams.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.Teams.Header) > 0 {
+			n.decoration.Header = opts.Teams.Header
+		}
+
+		if len(opts.Teams.Footer) > 0 {
+			n.decoration.Footer = opts.Teams.Footer
+		}
+
+		if opts.Teams.MessageMaxSize > 0 {
+			n.messageMaxSize = opts.Teams.MessageMaxSize
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Teams)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "TeamsNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receivers which use the same webhook and image url template only need to be sent once.
+		key, err := notifier.Md5key(struct {
+			ImageURLTemplate string
+			Config           *config.TeamsConfig
+		}{receiver.ImageURLTemplate, receiver.TeamsConfig})
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "TeamsNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.teams[key] = receiver
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	title, err := n.template.TempleText(DefaultTitle, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "TeamsNotifier: generate title error", "error", err.Error())
+		return []error{err}
+	}
+
+	messages, err := n.template.SplitWithDecoration(data, n.messageMaxSize, n.templateName, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "TeamsNotifier: split message error", "error", err.Error())
+		return []error{err}
+	}
+
+	color := ColorResolved
+	if len(data.Alerts.Firing()) > 0 {
+		color = ColorFiring
+	}
+
+	send := func(t *config.Teams, msg, imageURL string) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "TeamsNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		tm := &teamsMessage{
+			Type:       "MessageCard",
+			Context:    "http://schema.org/extensions",
+			ThemeColor: color,
+			Summary:    title,
+			Title:      title,
+			Text:       msg,
+		}
+
+		if len(imageURL) > 0 {
+			tm.Sections = []*teamsSection{
+				{Images: []*teamsImage{{Image: imageURL, Title: ImageTitle}}},
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(tm); err != nil {
+			_ = level.Error(n.logger).Log("msg", "TeamsNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		webhook, err := n.notifierCfg.GetSecretData(t.GetNamespace(), t.TeamsConfig.Webhook)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "TeamsNotifier: get webhook secret", "error", err.Error())
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, webhook, &buf)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "TeamsNotifier: do http error", "error", err)
+			return err
+		}
+
+		// The incoming webhook responds `1` when succeed, otherwise it responds the error message.
+		if resp := strings.TrimSpace(string(body)); len(resp) > 0 && resp != "1" {
+			_ = level.Error(n.logger).Log("msg", "TeamsNotifier: teams error", "error", resp)
+			return fmt.Errorf("%s", resp)
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "TeamsNotifier: send message", "webhook", t.TeamsConfig.Webhook.Name)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, teams := range n.teams {
+		t := teams
+		// The image is only attached to the first part of the message.
+		imageURL := n.imageURL(t, data)
+		for i, m := range messages {
+			msg := m
+			u := ""
+			if i == 0 {
+				u = imageURL
+			}
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(t, msg, u)
+			})
+		}
+	}
+
+	return group.Wait()
+}
+
+// Generate the url of the image attached to the message, the message is sent without the image if it failed.
+func (n *Notifier) imageURL(t *config.Teams, data template.Data) string {
+
+	if len(t.ImageURLTemplate) == 0 {
+		return ""
+	}
+
+	var token string
+	if t.TeamsConfig.ImageRenderToken != nil {
+		s, err := n.notifierCfg.GetSecretData(t.GetNamespace(), t.TeamsConfig.ImageRenderToken)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "TeamsNotifier: get image render token secret", "error", err.Error())
+			return ""
+		}
+		token = s
+	}
+
+	imageURL, err := n.template.ImageURL(t.ImageURLTemplate, data, token, t.TeamsConfig.ImageURLExpires, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "TeamsNotifier: generate image url error", "error", err.Error())
+		return ""
+	}
+
+	return imageURL
+}
@@ -0,0 +1,392 @@
+package teams
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A stub of the incoming webhook of teams, it records the messages received.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	messages []teamsMessage
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg teamsMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.mu.Unlock()
+
+		_, _ = w.Write([]byte("1"))
+	}))
+
+	return s
+}
+
+func (s *webhookServer) sent() []teamsMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]teamsMessage(nil), s.messages...)
+}
+
+// Create a receiver sending to the webhook, the webhook is read from the secret.
+func newReceiver(t *testing.T, webhook string) *config.Teams {
+
+	r := config.NewTeamsReceiver().(*config.Teams)
+	r.SetNamespace(testNamespace)
+	r.TeamsConfig = &config.TeamsConfig{
+		Webhook: secrets.NewSecret(t, webhook),
+	}
+
+	return r
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.TeamsOptions, receivers ...*config.Teams) *Notifier {
+
+	c := testutil.NewConfig(secrets, &v1alpha1.Options{Teams: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewTeamsNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(status string, n int) template.Data {
+
+	data := template.Data{Receiver: "test", Status: status}
+	for i := 0; i < n; i++ {
+		alert := template.Alert{
+			Status: status,
+			Labels: template.KV{"alertname": fmt.Sprintf("alert%d", i+1)},
+		}
+		// The status of the alert in the template is decided by the end time.
+		if status == "resolved" {
+			alert.EndsAt = time.Now().Add(-time.Minute)
+		}
+		data.Alerts = append(data.Alerts, alert)
+	}
+
+	return data
+}
+
+func TestNotify(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		status string
+		color  string
+	}{
+		{"firing", "firing", ColorFiring},
+		{"resolved", "resolved", ColorResolved},
+	}
+
+	for _, test := range tests {
+		s := newWebhookServer(t)
+
+		n := newNotifier(t, nil, newReceiver(t, s.URL))
+		if errs := n.Notify(context.Background(), newData(test.status, 2)); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", test.name, errs)
+		}
+
+		msgs := s.sent()
+		s.Close()
+		if len(msgs) != 1 {
+			t.Errorf("%s: expected 1 message, got %d", test.name, len(msgs))
+			continue
+		}
+
+		msg := msgs[0]
+		if msg.Type != "MessageCard" || msg.ThemeColor != test.color {
+			t.Errorf("%s: expected the card of color %s, got %s %s", test.name, test.color, msg.Type, msg.ThemeColor)
+		}
+
+		if title := "2 alerts " + test.status; msg.Title != title || msg.Summary != title {
+			t.Errorf("%s: expected the title %q, got %q %q", test.name, title, msg.Title, msg.Summary)
+		}
+
+		if text := fmt.Sprintf("[%s] alert1\n[%s] alert2", test.status, test.status); strings.TrimSpace(msg.Text) != text {
+			t.Errorf("%s: expected the text %q, got %q", test.name, text, msg.Text)
+		}
+	}
+}
+
+func TestNotifySplit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The text of each part is within the max size, and the alerts are not lost.
+	const maxSize = 64
+	n := newNotifier(t, &v1alpha1.TeamsOptions{MessageMaxSize: maxSize}, newReceiver(t, s.URL))
+	data := newData("firing", 20)
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) < 2 {
+		t.Fatalf("expected the message split, got %d", len(msgs))
+	}
+
+	alerts := make(map[string]bool)
+	for i, msg := range msgs {
+		if len(msg.Text) > maxSize {
+			t.Errorf("part %d: expected the text at most %d, got %d", i, maxSize, len(msg.Text))
+		}
+
+		// Every part is a complete card with the title.
+		if msg.Title != "20 alerts firing" || msg.ThemeColor != ColorFiring {
+			t.Errorf("part %d: expected the title and color, got %q %s", i, msg.Title, msg.ThemeColor)
+		}
+
+		for _, line := range strings.Split(msg.Text, "\n") {
+			if line = strings.TrimSpace(line); len(line) > 0 {
+				alerts[line] = true
+			}
+		}
+	}
+
+	for _, alert := range data.Alerts {
+		if line := "[firing] " + alert.Labels["alertname"]; !alerts[line] {
+			t.Errorf("expected %q sent", line)
+		}
+	}
+}
+
+func TestNotifyReceivers(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The receivers with the same webhook are sent once.
+	r1 := newReceiver(t, s.URL)
+	r2 := newReceiver(t, s.URL)
+	r2.TeamsConfig.Webhook = r1.TeamsConfig.Webhook
+	r3 := newReceiver(t, s.URL)
+
+	n := newNotifier(t, nil, r1, r2, r3)
+	if errs := n.Notify(context.Background(), newData("firing", 1)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if msgs := s.sent(); len(msgs) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(msgs))
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+
+	// The incoming webhook responds the error message with 200.
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Webhook message delivery failed with error: Microsoft Teams endpoint returned HTTP error 429"))
+	}))
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(t, s.URL))
+	errs := n.Notify(context.Background(), newData("firing", 1))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "delivery failed") {
+		t.Errorf("expected the error of teams, got %v", errs)
+	}
+}
+
+const (
+	imageTemplate = `{{ if .CommonLabels.panel }}https://grafana.test/render/d-solo/nm?panelId={{ .CommonLabels.panel }}{{ end }}`
+	imageURL      = "https://grafana.test/render/d-solo/nm?panelId=2"
+)
+
+// Set the panel label of the alerts, the image url template renders empty without it.
+func withPanel(data template.Data, panel string) template.Data {
+
+	for _, alert := range data.Alerts {
+		alert.Labels["panel"] = panel
+	}
+
+	return data
+}
+
+// Return the urls of the images attached to the message.
+func images(msg teamsMessage) []string {
+
+	var urls []string
+	for _, section := range msg.Sections {
+		for _, image := range section.Images {
+			urls = append(urls, image.Image)
+		}
+	}
+
+	return urls
+}
+
+func TestNotifyImage(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		template string
+		panel    string
+		image    string
+	}{
+		{"not set", "", "2", ""},
+		{"render url", imageTemplate, "2", imageURL},
+		// No image is attached if the template renders empty.
+		{"render empty", imageTemplate, "", ""},
+		// The message is sent without the image if the url is invalid.
+		{"invalid url", "/render/d-solo/nm", "2", ""},
+	}
+
+	for _, test := range tests {
+		s := newWebhookServer(t)
+
+		r := newReceiver(t, s.URL)
+		r.ImageURLTemplate = test.template
+
+		n := newNotifier(t, nil, r)
+		if errs := n.Notify(context.Background(), withPanel(newData("firing", 1), test.panel)); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", test.name, errs)
+		}
+
+		msgs := s.sent()
+		s.Close()
+		if len(msgs) != 1 {
+			t.Errorf("%s: expected 1 message, got %d", test.name, len(msgs))
+			continue
+		}
+
+		if strings.TrimSpace(msgs[0].Text) != "[firing] alert1" {
+			t.Errorf("%s: expected the text sent, got %q", test.name, msgs[0].Text)
+		}
+
+		urls := images(msgs[0])
+		if len(test.image) == 0 {
+			if len(urls) != 0 {
+				t.Errorf("%s: expected no image, got %v", test.name, urls)
+			}
+			continue
+		}
+
+		if len(urls) != 1 || urls[0] != test.image {
+			t.Errorf("%s: expected the image %s, got %v", test.name, test.image, urls)
+		}
+	}
+}
+
+func TestNotifyImageSplit(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	// The image is only attached to the first part of the message.
+	r := newReceiver(t, s.URL)
+	r.ImageURLTemplate = imageTemplate
+
+	n := newNotifier(t, &v1alpha1.TeamsOptions{MessageMaxSize: 32}, r)
+	if errs := n.Notify(context.Background(), withPanel(newData("firing", 4), "2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) < 2 {
+		t.Fatalf("expected the message split, got %d", len(msgs))
+	}
+
+	var attached []string
+	for _, msg := range msgs {
+		if urls := images(msg); len(urls) > 0 {
+			attached = append(attached, urls...)
+			if !strings.Contains(msg.Text, "alert1") {
+				t.Errorf("expected the image attached to the first part, got %q", msg.Text)
+			}
+		}
+	}
+
+	if len(attached) != 1 || attached[0] != imageURL {
+		t.Errorf("expected the image attached once, got %v", attached)
+	}
+}
+
+func TestNotifyImageSigned(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	r := newReceiver(t, s.URL)
+	r.ImageURLTemplate = imageTemplate
+	r.TeamsConfig.ImageRenderToken = secrets.NewSecret(t, "render-token")
+
+	n := newNotifier(t, nil, r)
+	if errs := n.Notify(context.Background(), withPanel(newData("firing", 1), "2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	urls := images(msgs[0])
+	if len(urls) != 1 {
+		t.Fatalf("expected the image attached, got %v", urls)
+	}
+
+	// The url is signed by the token, the token is not exposed.
+	if !strings.Contains(urls[0], "&signature=") || strings.Contains(urls[0], "render-token") {
+		t.Errorf("expected the signed image url, got %s", urls[0])
+	}
+}
+
+func TestNotifyImageTokenError(t *testing.T) {
+
+	s := newWebhookServer(t)
+	defer s.Close()
+
+	r := newReceiver(t, s.URL)
+	r.ImageURLTemplate = imageTemplate
+	r.TeamsConfig.ImageRenderToken = &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "not-exist"}, Key: "token"}
+
+	// The message is sent without the image if the token can not be read.
+	n := newNotifier(t, nil, r)
+	if errs := n.Notify(context.Background(), withPanel(newData("firing", 1), "2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	if urls := images(msgs[0]); len(urls) != 0 {
+		t.Errorf("expected no image, got %v", urls)
+	}
+}
@@ -0,0 +1,285 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DefaultSendTimeout = time.Second * 3
+	DefaultApiURL      = "https://api.telegram.org"
+	DefaultTemplate    = `{{ template "nm.default.text" . }}`
+	// The maximum number of characters of a message.
+	MessageMaxSize = 4096
+)
+
+// The characters must be escaped in MarkdownV2.
+var markdownV2Replacer = strings.NewReplacer(
+	"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "~", "\\~", "`", "\\`",
+	">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=", "|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+)
+
+type Notifier struct {
+	notifierCfg  *config.Config
+	telegram     map[string]*config.Telegram
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+	decoration   *notifier.Decoration
+}
+
+type telegramMessage struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func NewTelegramNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:  notifierCfg,
+		telegram:     make(map[string]*config.Telegram),
+		timeout:      DefaultSendTimeout,
+		logger:       logger,
+		template:     tmpl,
+		templateName: DefaultTemplate,
+		decoration:   &notifier.Decoration{Header: header, Footer: footer},
+	}
+
+	if opts != nil && opts.Telegram != nil {
+
+		if opts.Telegram.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Telegram.NotificationTimeout)
+		}
+
+		if len(opts.Telegram.Template) > 0 {
+			n.templateName = opts.Telegram.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.Telegram.Header) > 0 {
+			n.decoration.Header = opts.Telegram.Header
+		}
+
+		if len(opts.Telegram.Footer) > 0 {
+			n.decoration.Footer = opts.Telegram.Footer
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Telegram)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "TelegramNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receiver is shared by the notifications, so the default api url is set in a copy of it.
+		c := *receiver.TelegramConfig
+		if len(c.APIURL) == 0 {
+			c.APIURL = DefaultApiURL
+		}
+
+		// The receivers which use the same bot and parse mode will be merged.
+		key, err := notifier.Md5key(struct {
+			Config    *config.TelegramConfig
+			ParseMode string
+		}{&c, receiver.ParseMode})
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "TelegramNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		t, ok := n.telegram[key]
+		if !ok {
+			r := *receiver
+			r.TelegramConfig = &c
+			r.ChatIDs = nil
+			t = &r
+		}
+
+		for _, id := range receiver.ChatIDs {
+			if !contains(t.ChatIDs, id) {
+				t.ChatIDs = append(t.ChatIDs, id)
+			}
+		}
+
+		n.telegram[key] = t
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	msgs, err := n.template.SplitWithDecoration(data, MessageMaxSize, n.templateName, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "TelegramNotifier: split message error", "error", err.Error())
+		return []error{err}
+	}
+
+	send := func(t *config.Telegram, chatID, msg string) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "TelegramNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		token, err := n.notifierCfg.GetSecretData(t.GetNamespace(), t.TelegramConfig.BotToken)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "TelegramNotifier: get bot token secret", "error", err.Error())
+			return err
+		}
+
+		tm := &telegramMessage{
+			ChatID:    chatID,
+			Text:      msg,
+			ParseMode: t.ParseMode,
+		}
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(tm); err != nil {
+			_ = level.Error(n.logger).Log("msg", "TelegramNotifier: encode message error", "error", err.Error())
+			return err
+		}
+
+		u, err := notifier.UrlWithPath(t.TelegramConfig.APIURL, fmt.Sprintf("/bot%s/sendMessage", token))
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "TelegramNotifier: set path error", "error", err)
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, u, &buf)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			// The error may contain the url which contains the bot token.
+			err = fmt.Errorf("%s", strings.ReplaceAll(err.Error(), token, "<token>"))
+			_ = level.Error(n.logger).Log("msg", "TelegramNotifier: do http error", "error", err)
+			return err
+		}
+
+		var resp telegramResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			_ = level.Error(n.logger).Log("msg", "TelegramNotifier: decode response body error", "error", err)
+			return err
+		}
+
+		if !resp.OK {
+			_ = level.Error(n.logger).Log("msg", "TelegramNotifier: telegram error", "code", resp.ErrorCode, "error", resp.Description)
+			return fmt.Errorf("telegram error, code: %d, description: %s", resp.ErrorCode, resp.Description)
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "TelegramNotifier: send message", "chatID", chatID)
+
+		return nil
+	}
+
+	// The messages of each parse mode.
+	messages := make(map[string][]string)
+	group := async.NewGroup(ctx)
+	for _, telegram := range n.telegram {
+		t := telegram
+		if _, ok := messages[t.ParseMode]; !ok {
+			var ms []string
+			for _, msg := range msgs {
+				ms = append(ms, escape(msg, t.ParseMode, MessageMaxSize)...)
+			}
+			messages[t.ParseMode] = ms
+		}
+
+		for _, id := range t.ChatIDs {
+			chatID := id
+			for _, m := range messages[t.ParseMode] {
+				msg := m
+				group.Add(func(stopCh chan interface{}) {
+					stopCh <- send(t, chatID, msg)
+				})
+			}
+		}
+	}
+
+	return group.Wait()
+}
+
+// Escape the message according to the parse mode. The message will be split again
+// if the escaped message is longer than the limit.
+func escape(msg, parseMode string, maxSize int) []string {
+
+	var s string
+	switch parseMode {
+	case config.TelegramMarkdownV2:
+		s = markdownV2Replacer.Replace(msg)
+	case config.TelegramHTML:
+		s = html.EscapeString(msg)
+	default:
+		s = msg
+	}
+
+	if utf8.RuneCountInString(s) <= maxSize || maxSize <= 1 {
+		return []string{s}
+	}
+
+	var res []string
+	for _, sub := range notifier.SplitString(msg, maxSize/2) {
+		res = append(res, escape(sub, parseMode, maxSize/2)...)
+	}
+
+	return res
+}
+
+func contains(src []string, elem string) bool {
+	for _, s := range src {
+		if s == elem {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,275 @@
+package telegram
+
+import (
+	"context"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache()
+
+// A request received by the stub of the bot API.
+type botRequest struct {
+	path    string
+	message telegramMessage
+}
+
+// A stub of the bot API, it records the requests and responds with the handler.
+type botServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []botRequest
+}
+
+// Create the stub, it responds ok if the handler is nil.
+func newBotServer(t *testing.T, handler func(w http.ResponseWriter)) *botServer {
+
+	s := &botServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := botRequest{path: r.URL.Path}
+		if err := json.NewDecoder(r.Body).Decode(&req.message); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		s.mu.Unlock()
+
+		if handler != nil {
+			handler(w)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+
+	return s
+}
+
+// Return the requests received, they are sorted by the chat id.
+func (s *botServer) received() []botRequest {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := append([]botRequest(nil), s.requests...)
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].message.ChatID < requests[j].message.ChatID
+	})
+	return requests
+}
+
+// Create a receiver sending to the stub, the bot token is read from the secret.
+func newReceiver(t *testing.T, apiURL, token string, chatIDs ...string) *config.Telegram {
+
+	secret := secrets.NewSecret(t, token)
+
+	r := config.NewTelegramReceiver().(*config.Telegram)
+	r.SetNamespace(testNamespace)
+	r.ChatIDs = chatIDs
+	r.TelegramConfig = &config.TelegramConfig{
+		APIURL:   apiURL,
+		BotToken: secret,
+	}
+
+	return r
+}
+
+func newNotifier(t *testing.T, receivers ...*config.Telegram) *Notifier {
+
+	c := testutil.NewConfig(secrets, nil)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewTelegramNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(names ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range names {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status: "firing",
+			Labels: template.KV{"alertname": name},
+		})
+	}
+
+	return data
+}
+
+func TestNotifyChats(t *testing.T) {
+
+	s := newBotServer(t, nil)
+	defer s.Close()
+
+	// The receivers of the same bot and parse mode are merged, the message is sent to each chat once.
+	r1 := newReceiver(t, s.URL, "123:token", "-1001", "-1002")
+	r2 := newReceiver(t, s.URL, "123:token", "-1002", "@channel")
+	r2.TelegramConfig.BotToken = r1.TelegramConfig.BotToken
+	r3 := newReceiver(t, s.URL, "123:token", "-1003")
+	r3.TelegramConfig.BotToken = r1.TelegramConfig.BotToken
+	r3.ParseMode = config.TelegramHTML
+
+	n := newNotifier(t, r1, r2, r3)
+	if len(n.telegram) != 2 {
+		t.Errorf("expected 2 bots, got %d", len(n.telegram))
+	}
+
+	if errs := n.Notify(context.Background(), newData("alert<1>")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var chats []string
+	for _, req := range s.received() {
+		chats = append(chats, req.message.ChatID)
+		if req.path != "/bot123:token/sendMessage" {
+			t.Errorf("%s: expected the path of the bot, got %s", req.message.ChatID, req.path)
+		}
+
+		// The message is escaped in the parse mode of the receiver.
+		text := "[firing] alert<1>"
+		if req.message.ParseMode == config.TelegramHTML {
+			text = "[firing] alert&lt;1&gt;"
+		}
+		if strings.TrimSpace(req.message.Text) != text {
+			t.Errorf("%s: expected the text %q, got %q", req.message.ChatID, text, req.message.Text)
+		}
+	}
+
+	if expected := []string{"-1001", "-1002", "-1003", "@channel"}; !reflect.DeepEqual(chats, expected) {
+		t.Errorf("expected the chats %v, got %v", expected, chats)
+	}
+}
+
+func TestNotifyTokenRedacted(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter)
+		// The server is closed before sending, so the error of the request contains the url.
+		closed bool
+	}{
+		{"request error", nil, true},
+		{"telegram error", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+		}, false},
+	}
+
+	for _, test := range tests {
+		s := newBotServer(t, test.handler)
+		if test.closed {
+			s.Close()
+		}
+
+		n := newNotifier(t, newReceiver(t, s.URL, "123:secret-token", "-1001"))
+		errs := n.Notify(context.Background(), newData("alert1"))
+		s.Close()
+
+		if len(errs) != 1 {
+			t.Errorf("%s: expected 1 error, got %v", test.name, errs)
+			continue
+		}
+
+		if msg := errs[0].Error(); strings.Contains(msg, "secret-token") {
+			t.Errorf("%s: expected the bot token redacted, got %s", test.name, msg)
+		}
+	}
+}
+
+func TestNotifyNotOK(t *testing.T) {
+
+	s := newBotServer(t, func(w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, newReceiver(t, s.URL, "123:token", "-1001"))
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if want := "telegram error, code: 403, description: Forbidden: bot was blocked by the user"; len(errs) != 1 || errs[0].Error() != want {
+		t.Errorf("expected %q, got %v", want, errs)
+	}
+}
+
+func TestNewNotifierDefaultURL(t *testing.T) {
+
+	r := newReceiver(t, "", "123:token", "-1001")
+
+	// The notifiers are created concurrently with the same receiver, the default api url is not written to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newNotifier(t, r)
+			for _, c := range n.telegram {
+				if c.TelegramConfig.APIURL != DefaultApiURL {
+					t.Errorf("expected the api url %s, got %s", DefaultApiURL, c.TelegramConfig.APIURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(r.TelegramConfig.APIURL) != 0 {
+		t.Errorf("expected the receiver not changed, got %s", r.TelegramConfig.APIURL)
+	}
+}
+
+func TestEscape(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		msg       string
+		parseMode string
+		maxSize   int
+		expected  string
+		// The number of parts, it is not checked if it is 0.
+		parts int
+	}{
+		{"plain", "a.b&c", "", 10, "a.b&c", 1},
+		{"markdown", "a.b_c", config.TelegramMarkdownV2, 10, `a\.b\_c`, 1},
+		{"html", "<b>&</b>", config.TelegramHTML, 30, "&lt;b&gt;&amp;&lt;/b&gt;", 1},
+		// The message is split again if the escaped message is longer than the limit.
+		{"markdown split", strings.Repeat("a.", 8), config.TelegramMarkdownV2, 16, strings.Repeat(`a\.`, 8), 0},
+		{"html split", strings.Repeat("&", 20), config.TelegramHTML, 10, strings.Repeat("&amp;", 20), 20},
+	}
+
+	for _, test := range tests {
+		parts := escape(test.msg, test.parseMode, test.maxSize)
+		if test.parts > 0 && len(parts) != test.parts {
+			t.Errorf("%s: expected %d parts, got %q", test.name, test.parts, parts)
+		}
+
+		for _, p := range parts {
+			if n := utf8.RuneCountInString(p); n > test.maxSize {
+				t.Errorf("%s: expected each part no longer than %d, got %d", test.name, test.maxSize, n)
+			}
+		}
+
+		if got := strings.Join(parts, ""); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -2,7 +2,11 @@ package notifier
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	json "github.com/json-iterator/go"
@@ -11,27 +15,102 @@ import (
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 	"net/url"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	tmpltext "text/template"
+	"time"
 )
 
+// The data used to execute the templates, the external labels are accessed by `.ExternalLabels`,
+// so they do not clobber the fields of the alerts.
+type templateData struct {
+	*template.Data
+	ExternalLabels template.KV
+}
+
 type Template struct {
-	Tmpl *template.Template
-	path []string
+	tmpl *template.Template
+	// The mutex protects the template, it is replaced when the template files change.
+	mutex sync.RWMutex
+	path  []string
 }
 
+const (
+	DefaultExternalURL = "http://kubesphere.io"
+	// Split the messages by the size, the alerts are rendered together.
+	SplitModeSize = "size"
+	// Split the messages by the alerts, each alert is rendered independently, and an alert is never split
+	// unless it exceeds the maximum size by itself.
+	SplitModeAlert = "alert"
+	// The marker added to the last chunk of an alert which is too large and split into several chunks.
+	TruncatedMarker = "…truncated"
+	// The default time the signed image url is valid, the image is fetched by the chat service soon after the message is sent.
+	DefaultImageURLExpires = time.Hour
+)
+
 var notifierTemplate *Template
 var templatePaths []string
+var templateExternalURL string
+var templateMaxLabels int
+var templateExternalLabels template.KV
+var templateWatcher *fsnotify.Watcher
+var templateLogger log.Logger = log.NewNopLogger()
 var mutex sync.Mutex
 
-func NewTemplate(paths []string) (*Template, error) {
+// SetTemplateLogger sets the logger used to log the reloading of templates.
+func SetTemplateLogger(l log.Logger) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if l != nil {
+		templateLogger = l
+	}
+}
+
+// SetMaxLabels sets the maximum number of labels and annotations rendered for each alert
+// by the template function limitLabels, it is unlimited if max is not greater than 0.
+func SetMaxLabels(max int) {
 
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	if !reflect.DeepEqual(templatePaths, paths) {
+	templateMaxLabels = max
+}
+
+// SetExternalLabels sets the static labels which can be accessed by the templates as `{{ .ExternalLabels.cluster }}`,
+// such as the cluster or the region the notification manager runs in.
+func SetExternalLabels(labels map[string]string) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	templateExternalLabels = template.KV(labels)
+}
+
+// Return the external labels used by the templates.
+func getExternalLabels() template.KV {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return templateExternalLabels
+}
+
+func NewTemplate(paths []string, externalURL string) (*Template, error) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(externalURL) == 0 {
+		externalURL = DefaultExternalURL
+	}
+
+	if !reflect.DeepEqual(templatePaths, paths) || templateExternalURL != externalURL {
 		templatePaths = paths
 		notifierTemplate = nil
 	}
@@ -40,23 +119,177 @@ func NewTemplate(paths []string) (*Template, error) {
 		return notifierTemplate, nil
 	}
 
-	t := &Template{}
+	t := &Template{
+		path: paths,
+	}
 
-	tmpl, err := template.FromGlobs(paths...)
+	tmpl, err := parseTemplate(paths, externalURL)
 	if err != nil {
 		return nil, err
 	}
-	tmpl.ExternalURL, _ = url.Parse("http://kubesphere.io")
 
-	t.Tmpl = tmpl
+	t.tmpl = tmpl
 	notifierTemplate = t
+	templateExternalURL = externalURL
+	watchTemplate(t, externalURL)
 
 	return notifierTemplate, nil
 }
 
+func parseTemplate(paths []string, externalURL string) (*template.Template, error) {
+
+	tmpl, err := template.FromGlobs(paths...)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.ExternalURL, err = url.Parse(externalURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// Watch the directories of the template files, and reload the template when the files change.
+// The previous template is retained if the new template files can not be parsed.
+// It must be called with the mutex held.
+func watchTemplate(t *Template, externalURL string) {
+
+	if templateWatcher != nil {
+		_ = templateWatcher.Close()
+		templateWatcher = nil
+	}
+
+	if len(t.path) == 0 {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		_ = level.Error(templateLogger).Log("msg", "create template watcher error", "error", err.Error())
+		return
+	}
+
+	for _, p := range t.path {
+		if err := watcher.Add(filepath.Dir(p)); err != nil {
+			_ = level.Error(templateLogger).Log("msg", "watch template error", "path", p, "error", err.Error())
+		}
+	}
+
+	l := templateLogger
+	go func() {
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				tmpl, err := parseTemplate(t.path, externalURL)
+				if err != nil {
+					_ = level.Error(l).Log("msg", "reload template error, use the previous template", "error", err.Error())
+					continue
+				}
+
+				t.mutex.Lock()
+				t.tmpl = tmpl
+				t.mutex.Unlock()
+				_ = level.Info(l).Log("msg", "template reloaded")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				_ = level.Error(l).Log("msg", "watch template error", "error", err.Error())
+			}
+		}
+	}()
+
+	templateWatcher = watcher
+}
+
+// Get the current template.
+func (t *Template) Get() *template.Template {
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.tmpl
+}
+
 func (t *Template) TempleText(name string, data template.Data, l log.Logger) (string, error) {
+	return t.Text(t.transform(name), data, l)
+}
+
+// Text executes the text as a template, the text can use the templates defined in the template files.
+func (t *Template) Text(text string, data template.Data, l log.Logger) (string, error) {
+
+	current := t.Get()
+	return execute(current, text, newTemplateData(current, data, l))
+}
+
+// ImageURL executes the text to generate the url of the image attached to the message, such as the url of a graph
+// rendered by the Grafana render API. It returns empty if the text renders nothing, so that no image is attached.
+// The url is signed by the token if it is set, so the token is not exposed in the chat history. The url expires after
+// the duration given, or DefaultImageURLExpires if it is not greater than 0.
+func (t *Template) ImageURL(text string, data template.Data, token string, expires time.Duration, l log.Logger) (string, error) {
+
+	if len(text) == 0 {
+		return "", nil
+	}
+
+	current := t.Get()
+	s, err := execute(current, text, newTemplateData(current, data, l))
+	if err != nil {
+		return "", err
+	}
+
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return "", nil
+	}
+
+	// The image is fetched by the chat service, so it must be an absolute http url.
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid image url %s", s)
+	}
+
+	if len(token) == 0 {
+		return s, nil
+	}
+
+	if expires <= 0 {
+		expires = DefaultImageURLExpires
+	}
+
+	return SignURL(u, token, time.Now().Add(expires)), nil
+}
 
-	name = t.transform(name)
+// SignURL adds the `expires` parameter, the unix time the url expires, and the `signature` parameter to the url.
+// The signature is the hex encoded HMAC-SHA256 of the url before the `signature` parameter, which is the last
+// parameter, so the server sharing the key can verify the url and the expiration. The render services such as Grafana
+// do not verify the signature, it is verified by a proxy in front of the render service, which forwards the request
+// with the credentials of the render service, such as the api key of Grafana.
+func SignURL(u *url.URL, key string, expires time.Time) string {
+
+	// The fragment is not sent to the server, so it is dropped.
+	signed := *u
+	signed.Fragment = ""
+	query := signed.Query()
+	query.Del("signature")
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	signed.RawQuery = query.Encode()
+
+	s := signed.String()
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(s))
+	return s + "&signature=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTemplateData(current *template.Template, data template.Data, l log.Logger) *templateData {
 
 	ctx := context.Background()
 	ctx = notify.WithGroupLabels(ctx, KvToLabelSet(data.GroupLabels))
@@ -75,19 +308,64 @@ func (t *Template) TempleText(name string, data template.Data, l log.Logger) (st
 		})
 	}
 
-	d := notify.GetTemplateData(ctx, t.Tmpl, as, l)
-
-	var e error
-	text := notify.TmplText(t.Tmpl, d, &e)
-	if e != nil {
-		return "", e
+	return &templateData{
+		Data:           notify.GetTemplateData(ctx, current, as, l),
+		ExternalLabels: getExternalLabels(),
 	}
+}
 
-	s := text(name)
+func execute(current *template.Template, text string, data interface{}) (string, error) {
+
+	s, err := current.ExecuteTextString(text, data)
+	if err != nil {
+		return "", err
+	}
 
 	return strings.TrimRight(s, "\n"), nil
 }
 
+// ValidateText checks whether the text can be parsed as a template, the templates defined in the
+// template files are not checked.
+func ValidateText(text string) error {
+
+	_, err := tmpltext.New("").Option("missingkey=zero").Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(text)
+	return err
+}
+
+// RenderList executes each value as a template, the result is split by comma, and the empty elements are dropped.
+// It is used to generate the value list from the alerts, such as the users to be mentioned.
+func (t *Template) RenderList(values []string, data template.Data, l log.Logger) ([]string, error) {
+
+	var res []string
+	for _, v := range values {
+		if strings.Contains(v, "{{") {
+			s, err := t.Text(v, data, l)
+			if err != nil {
+				return nil, err
+			}
+			v = s
+		}
+
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.TrimSpace(e); len(e) > 0 && !sliceContains(res, e) {
+				res = append(res, e)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func sliceContains(src []string, elem string) bool {
+	for _, s := range src {
+		if s == elem {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (t *Template) transform(name string) string {
 
 	n := strings.ReplaceAll(name, " ", "")
@@ -100,19 +378,22 @@ func (t *Template) transform(name string) string {
 	return fmt.Sprintf("{{ template \"%s\" . }}", name)
 }
 
-func (t *Template) Split(data template.Data, maxSize int, templateName string, l log.Logger) ([]string, error) {
+// Split renders the alerts into messages up to maxSize, the count returned is the number of alerts which are
+// too large and split into several chunks.
+func (t *Template) Split(data template.Data, maxSize int, templateName string, l log.Logger) ([]string, int, error) {
 	d := template.Data{
 		Receiver:    data.Receiver,
 		GroupLabels: data.GroupLabels,
 	}
 	var messages []string
+	truncated := 0
 	lastMsg := ""
 	for i := 0; i < len(data.Alerts); i++ {
 
 		d.Alerts = append(d.Alerts, data.Alerts[i])
 		msg, err := t.TempleText(templateName, d, l)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		if Len(msg) < maxSize {
@@ -120,9 +401,10 @@ func (t *Template) Split(data template.Data, maxSize int, templateName string, l
 			continue
 		}
 
-		// If there is only alert, and the message length is greater than MaxMessageSize, drop this alert.
+		// If there is only alert, and the message length is greater than MaxMessageSize, split the message of this alert.
 		if len(d.Alerts) == 1 {
-			_ = level.Error(l).Log("msg", "alert is too large, drop it")
+			messages = append(messages, splitAlert(msg, maxSize)...)
+			truncated++
 			d.Alerts = nil
 			lastMsg = ""
 			continue
@@ -139,7 +421,75 @@ func (t *Template) Split(data template.Data, maxSize int, templateName string, l
 		messages = append(messages, lastMsg)
 	}
 
-	return messages, nil
+	return messages, truncated, nil
+}
+
+// SplitByAlert renders each alert independently, and packs the whole alerts into messages up to maxSize.
+// An alert is split by size only if it exceeds maxSize by itself.
+func (t *Template) SplitByAlert(data template.Data, maxSize int, templateName string, l log.Logger) ([]string, int, error) {
+
+	d := template.Data{
+		Receiver:    data.Receiver,
+		GroupLabels: data.GroupLabels,
+	}
+
+	var messages []string
+	truncated := 0
+	lastMsg := ""
+	for _, alert := range data.Alerts {
+
+		d.Alerts = []template.Alert{alert}
+		msg, err := t.TempleText(templateName, d, l)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if Len(msg) >= maxSize {
+			if len(lastMsg) > 0 {
+				messages = append(messages, lastMsg)
+				lastMsg = ""
+			}
+			messages = append(messages, splitAlert(msg, maxSize)...)
+			truncated++
+			continue
+		}
+
+		if len(lastMsg) == 0 {
+			lastMsg = msg
+			continue
+		}
+
+		if Len(lastMsg+"\n"+msg) < maxSize {
+			lastMsg = lastMsg + "\n" + msg
+			continue
+		}
+
+		messages = append(messages, lastMsg)
+		lastMsg = msg
+	}
+
+	if len(lastMsg) > 0 {
+		messages = append(messages, lastMsg)
+	}
+
+	return messages, truncated, nil
+}
+
+// Split the message of an alert which exceeds maxSize, the marker is added to the last chunk
+// so that the receiver knows the alert is not complete in one message.
+func splitAlert(msg string, maxSize int) []string {
+
+	marker := "\n" + TruncatedMarker
+	if Len(marker) >= maxSize {
+		return SplitString(msg, maxSize)
+	}
+
+	chunks := SplitString(msg, maxSize-Len(marker))
+	if len(chunks) > 0 {
+		chunks[len(chunks)-1] += marker
+	}
+
+	return chunks
 }
 
 // When a string is serialized, the escape character in the string will occupy two bytes because of `\`.
@@ -153,3 +503,51 @@ func Len(s string) int {
 	// Remove the '"' at the begin and end.
 	return len(string(bs)) - 2
 }
+
+// SplitString splits the string into chunks which the length is less than maxSize. The string is split on
+// the newline boundaries when possible, and never in the middle of a rune.
+func SplitString(s string, maxSize int) []string {
+
+	var chunks []string
+	var chunk strings.Builder
+	size := 0
+
+	flush := func() {
+		if c := strings.TrimRight(chunk.String(), "\n"); len(c) > 0 {
+			chunks = append(chunks, c)
+		}
+		chunk.Reset()
+		size = 0
+	}
+
+	for _, line := range strings.SplitAfter(s, "\n") {
+
+		l := Len(line)
+		if size+l < maxSize {
+			chunk.WriteString(line)
+			size += l
+			continue
+		}
+
+		flush()
+		if l < maxSize {
+			chunk.WriteString(line)
+			size = l
+			continue
+		}
+
+		// The line is too long, split it on the rune boundaries.
+		for _, r := range line {
+			rl := Len(string(r))
+			if size+rl >= maxSize {
+				flush()
+			}
+			chunk.WriteRune(r)
+			size += rl
+		}
+	}
+
+	flush()
+
+	return chunks
+}
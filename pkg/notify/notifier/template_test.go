@@ -0,0 +1,632 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/template"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestSplitStringRunes(t *testing.T) {
+
+	const maxSize = 2048
+
+	tests := map[string]string{
+		"without newline": strings.Repeat("告警内容", 1500),
+		"with newlines":   strings.Repeat("命名空间 kube-system 的容器 CPU 使用率过高\n", 200),
+		"mixed":           strings.Repeat("a告", 2000) + "\n" + strings.Repeat("警b", 2000),
+	}
+
+	for name, s := range tests {
+		chunks := SplitString(s, maxSize)
+		if len(chunks) < 2 {
+			t.Errorf("%s: expected the string to be split, got %d chunk", name, len(chunks))
+		}
+
+		for i, c := range chunks {
+			if !utf8.ValidString(c) {
+				t.Errorf("%s: chunk %d is not valid utf-8", name, i)
+			}
+
+			if len(c) >= maxSize {
+				t.Errorf("%s: chunk %d has %d bytes, exceeds %d", name, i, len(c), maxSize)
+			}
+		}
+
+		// Nothing is lost except the newlines at the boundaries.
+		if got, want := strings.ReplaceAll(strings.Join(chunks, ""), "\n", ""), strings.ReplaceAll(s, "\n", ""); got != want {
+			t.Errorf("%s: the chunks do not make up the string", name)
+		}
+	}
+}
+
+func TestSplitStringNewline(t *testing.T) {
+
+	line := strings.Repeat("告", 10) + "\n"
+	chunks := SplitString(strings.Repeat(line, 10), Len(line)*3+1)
+
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks, got %d", len(chunks))
+	}
+
+	// Each chunk consists of the whole lines.
+	for i, c := range chunks {
+		for _, l := range strings.Split(c, "\n") {
+			if l+"\n" != line {
+				t.Errorf("chunk %d is not split on the newline boundary, %q", i, c)
+			}
+		}
+	}
+}
+
+func TestSplitCJKAlerts(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+
+	data := template.Data{}
+	for i := 0; i < 20; i++ {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": "容器内存使用率过高"},
+			Annotations: template.KV{"message": strings.Repeat("命名空间中的容器内存使用率超过阈值", 10)},
+		})
+	}
+
+	messages, _, err := tmpl.Split(data, 2048, "test.text", log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	if len(messages) < 2 {
+		t.Fatalf("expected the alerts to be split, got %d message", len(messages))
+	}
+
+	for i, m := range messages {
+		if !utf8.ValidString(m) {
+			t.Errorf("message %d is not valid utf-8", i)
+		}
+
+		if len(m) >= 2048 {
+			t.Errorf("message %d has %d bytes, exceeds 2048", i, len(m))
+		}
+	}
+}
+
+func TestSplitLargeCJKAlert(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := template.Data{
+		Alerts: template.Alerts{{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": "日志错误"},
+			Annotations: template.KV{"message": strings.Repeat("错误日志", 1000)},
+		}},
+	}
+
+	messages, truncated, err := tmpl.Split(data, 2048, "test.text", log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	if truncated != 1 || len(messages) < 2 {
+		t.Fatalf("expected the alert to be split, got %d truncated and %d messages", truncated, len(messages))
+	}
+
+	for i, m := range messages {
+		if !utf8.ValidString(m) || len(m) >= 2048 {
+			t.Errorf("message %d is invalid, %d bytes", i, len(m))
+		}
+	}
+
+	if !strings.HasSuffix(messages[len(messages)-1], TruncatedMarker) {
+		t.Error("expected the marker at the end of the last chunk")
+	}
+}
+
+func TestTemplateReload(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatalf("create dir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "template.tmpl")
+	// The template is replaced rather than written in place, so that the watcher never reads a partial file.
+	write := func(text string) {
+		if err := ioutil.WriteFile(file+".tmp", []byte(text), 0644); err != nil {
+			t.Fatalf("write template error, %s", err)
+		}
+		if err := os.Rename(file+".tmp", file); err != nil {
+			t.Fatalf("replace template error, %s", err)
+		}
+	}
+
+	write(`{{ define "test.text" }}v1{{ end }}`)
+	tmpl, err := NewTemplate([]string{filepath.Join(dir, "*.tmpl")}, "")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	render := func() string {
+		msg, err := tmpl.TempleText("test.text", template.Data{}, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("render template error, %s", err)
+		}
+		return msg
+	}
+
+	// Wait until the template rendered is the one expected.
+	waitFor := func(want string) {
+		deadline := time.Now().Add(time.Second * 5)
+		for render() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the template reloaded to render %q, got %q", want, render())
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+
+	if msg := render(); msg != "v1" {
+		t.Fatalf("expected v1, got %q", msg)
+	}
+
+	write(`{{ define "test.text" }}v2{{ end }}`)
+	waitFor("v2")
+
+	// The previous template is retained if the new one is invalid.
+	write(`{{ define "test.text" }}{{ .Unknown`)
+	time.Sleep(time.Millisecond * 200)
+	if msg := render(); msg != "v2" {
+		t.Errorf("expected the previous template retained, got %q", msg)
+	}
+
+	write(`{{ define "test.text" }}v3{{ end }}`)
+	waitFor("v3")
+}
+
+func TestSplitModes(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+
+	data := template.Data{}
+	var alerts []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("alert%d", i)
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": name},
+			Annotations: template.KV{"message": strings.Repeat("the detail of "+name+"\n", 3)},
+		})
+		alerts = append(alerts, name)
+	}
+
+	const maxSize = 200
+	for _, mode := range []string{SplitModeSize, SplitModeAlert} {
+		messages, err := tmpl.SplitMessages(data, maxSize, "test.text", mode, nil, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("%s: split error, %s", mode, err)
+		}
+
+		if len(messages) < 2 {
+			t.Errorf("%s: expected the alerts to be split, got %d message", mode, len(messages))
+		}
+
+		for i, msg := range messages {
+			if Len(msg) >= maxSize {
+				t.Errorf("%s: message %d has %d bytes, exceeds %d", mode, i, Len(msg), maxSize)
+			}
+		}
+
+		// Each alert is in exactly one message, and it is complete.
+		for _, name := range alerts {
+			found := 0
+			for _, msg := range messages {
+				if strings.Contains(msg, name+" ") {
+					found++
+					if strings.Count(msg, "the detail of "+name) != 3 {
+						t.Errorf("%s: %s is split across messages", mode, name)
+					}
+				}
+			}
+
+			if found != 1 {
+				t.Errorf("%s: expected %s in 1 message, got %d", mode, name, found)
+			}
+		}
+	}
+}
+
+func TestSplitByAlertLargeAlert(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+
+	data := template.Data{Alerts: template.Alerts{
+		{Status: "firing", Labels: template.KV{"alertname": "small1"}, Annotations: template.KV{"message": "ok"}},
+		{Status: "firing", Labels: template.KV{"alertname": "large"}, Annotations: template.KV{"message": strings.Repeat("x", 500)}},
+		{Status: "firing", Labels: template.KV{"alertname": "small2"}, Annotations: template.KV{"message": "ok"}},
+	}}
+
+	messages, truncated, err := tmpl.SplitByAlert(data, 200, "test.text", log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	if truncated != 1 {
+		t.Errorf("expected 1 alert split, got %d", truncated)
+	}
+
+	// The small alerts are not packed with the chunks of the large alert.
+	if len(messages) < 4 || !strings.HasPrefix(messages[0], "small1") || !strings.HasPrefix(messages[len(messages)-1], "small2") {
+		t.Fatalf("unexpected messages %q", messages)
+	}
+
+	large := messages[1 : len(messages)-1]
+	for i, msg := range large {
+		if Len(msg) >= 200 {
+			t.Errorf("chunk %d has %d bytes, exceeds 200", i, Len(msg))
+		}
+	}
+
+	// The size split falls back for the large alert, its last chunk is marked.
+	if !strings.HasSuffix(large[len(large)-1], TruncatedMarker) {
+		t.Errorf("expected the last chunk of the large alert marked, got %q", large[len(large)-1])
+	}
+}
+
+func TestSplitTruncation(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "small"}, Annotations: template.KV{"message": "ok"}},
+			// A single line which can not be split by the newlines.
+			{Status: "firing", Labels: template.KV{"alertname": "large"}, Annotations: template.KV{"message": strings.Repeat("x", 5000)}},
+		},
+	}
+
+	for _, mode := range []string{SplitModeSize, SplitModeAlert} {
+		split := tmpl.Split
+		if mode == SplitModeAlert {
+			split = tmpl.SplitByAlert
+		}
+
+		messages, truncated, err := split(data, 1024, "test.text", log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("%s: split error, %s", mode, err)
+		}
+
+		if truncated != 1 {
+			t.Errorf("%s: expected 1 alert truncated, got %d", mode, truncated)
+		}
+
+		// The marker is only in the last chunk of the large alert.
+		markers := 0
+		for i, m := range messages {
+			if len(m) >= 1024 {
+				t.Errorf("%s: message %d has %d bytes, exceeds 1024", mode, i, len(m))
+			}
+			markers += strings.Count(m, TruncatedMarker)
+		}
+		if markers != 1 || !strings.HasSuffix(messages[len(messages)-1], "\n"+TruncatedMarker) {
+			t.Errorf("%s: expected the marker at the end of the last chunk, got %d markers", mode, markers)
+		}
+
+		// The alerts which are not too large are not truncated.
+		_, truncated, err = split(template.Data{Alerts: data.Alerts[:1]}, 1024, "test.text", log.NewNopLogger())
+		if err != nil || truncated != 0 {
+			t.Errorf("%s: expected no alert truncated, got %d, %v", mode, truncated, err)
+		}
+	}
+}
+
+func TestSplitTruncationWarning(t *testing.T) {
+
+	tmpl := newTestTemplate(t)
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "large"}, Annotations: template.KV{"message": strings.Repeat("x", 5000)}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if _, err := tmpl.SplitMessages(data, 1024, "test.text", SplitModeSize, nil, log.NewLogfmtLogger(&buf)); err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	if !strings.Contains(buf.String(), "alerts are too large") || !strings.Contains(buf.String(), "alerts=1") {
+		t.Errorf("expected the warning of the truncated alerts, got %s", buf.String())
+	}
+}
+
+func TestSplitAlertSmallMaxSize(t *testing.T) {
+
+	// The marker is not added if it does not fit in a chunk.
+	chunks := splitAlert(strings.Repeat("x", 20), 8)
+	for _, c := range chunks {
+		if strings.Contains(c, TruncatedMarker) {
+			t.Errorf("expected no marker, got %q", c)
+		}
+	}
+
+	if got := strings.Join(chunks, ""); got != strings.Repeat("x", 20) {
+		t.Errorf("expected the whole message kept, got %q", got)
+	}
+}
+
+func TestNewTemplateNoFiles(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatalf("create dir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "template.tmpl"), []byte(`{{ define "test.text" }}v1{{ end }}`), 0644); err != nil {
+		t.Fatalf("write template error, %s", err)
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		err   string
+	}{
+		{"file", []string{filepath.Join(dir, "template.tmpl")}, ""},
+		{"glob", []string{filepath.Join(dir, "*.tmpl")}, ""},
+		// The paths matching no files are skipped, the files may be created later.
+		{"not exist", []string{filepath.Join(dir, "not-exist.tmpl")}, ""},
+		{"one not exist", []string{filepath.Join(dir, "*.tmpl"), filepath.Join(dir, "*.txt")}, ""},
+		{"bad pattern", []string{filepath.Join(dir, "[")}, filepath.ErrBadPattern.Error()},
+	}
+
+	for _, test := range tests {
+		_, err := NewTemplate(test.paths, "")
+		if test.err == "" && err != nil {
+			t.Errorf("%s: expected no error, got %s", test.name, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%s: expected error %q, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestTemplateExternalLabels(t *testing.T) {
+
+	defer SetExternalLabels(nil)
+
+	tmpl, err := NewTemplate([]string{"testdata/template.tmpl"}, "")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	data := template.Data{
+		Status:       "firing",
+		CommonLabels: template.KV{"cluster": "alert-cluster"},
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "alert1", "cluster": "alert-cluster"}, Annotations: template.KV{"message": "down"}},
+		},
+	}
+
+	text := `[{{ .ExternalLabels.cluster }}/{{ .ExternalLabels.region }}] {{ .Status }} {{ .CommonLabels.cluster }} {{ template "test.text" . }}`
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{"not set", nil, "[/] firing alert-cluster alert1 down"},
+		// The external labels do not clobber the fields of the alerts.
+		{"set", map[string]string{"cluster": "host", "region": "eu-west-1"}, "[host/eu-west-1] firing alert-cluster alert1 down"},
+		{"missing label", map[string]string{"cluster": "host"}, "[host/] firing alert-cluster alert1 down"},
+	}
+
+	for _, test := range tests {
+		SetExternalLabels(test.labels)
+		got, err := tmpl.Text(text, data, log.NewNopLogger())
+		if err != nil {
+			t.Errorf("%s: render template error, %s", test.name, err)
+			continue
+		}
+
+		if got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestSplitExternalLabels(t *testing.T) {
+
+	SetExternalLabels(map[string]string{"cluster": "host"})
+	defer SetExternalLabels(nil)
+
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatalf("create dir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "template.tmpl")
+	text := `{{ define "test.external" }}{{ range .Alerts }}[{{ $.ExternalLabels.cluster }}] {{ .Labels.alertname }}
+{{ end }}{{ end }}`
+	if err := ioutil.WriteFile(file, []byte(text), 0644); err != nil {
+		t.Fatalf("write template error, %s", err)
+	}
+
+	tmpl, err := NewTemplate([]string{file}, "")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "alert1"}},
+			{Status: "firing", Labels: template.KV{"alertname": "alert2"}},
+		},
+	}
+
+	// The external labels are accessible when the alerts are rendered into messages.
+	msgs, _, err := tmpl.Split(data, 4096, "test.external", log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("split error, %s", err)
+	}
+
+	if len(msgs) != 1 || msgs[0] != "[host] alert1\n[host] alert2" {
+		t.Errorf("expected the external labels rendered, got %q", msgs)
+	}
+}
+
+func TestImageURL(t *testing.T) {
+
+	tmpl, err := NewTemplate([]string{"testdata/template.tmpl"}, "")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	data := template.Data{
+		Alerts: template.Alerts{
+			{Status: "firing", Labels: template.KV{"alertname": "alert1", "panel": "2"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		err      bool
+	}{
+		{"not set", "", "", false},
+		{"render empty", `{{ if .CommonLabels.dashboard }}https://grafana.test/render{{ end }}`, "", false},
+		{"render blank", "  \n", "", false},
+		{"render url", `https://grafana.test/render/d-solo/nm?panelId={{ .CommonLabels.panel }}`, "https://grafana.test/render/d-solo/nm?panelId=2", false},
+		{"relative url", `/render/d-solo/nm`, "", true},
+		{"not http", `ftp://grafana.test/render`, "", true},
+		{"template error", `{{ template "nm.not.exist" . }}`, "", true},
+	}
+
+	for _, test := range tests {
+		u, err := tmpl.ImageURL(test.text, data, "", 0, log.NewNopLogger())
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", test.name, u)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: image url error, %s", test.name, err)
+			continue
+		}
+
+		if u != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, u)
+		}
+	}
+}
+
+func TestImageURLSigned(t *testing.T) {
+
+	tmpl, err := NewTemplate([]string{"testdata/template.tmpl"}, "")
+	if err != nil {
+		t.Fatalf("create template error, %s", err)
+	}
+
+	data := template.Data{Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": "alert1"}}}}
+
+	tests := []struct {
+		name     string
+		expires  time.Duration
+		expected time.Duration
+	}{
+		{"default", 0, DefaultImageURLExpires},
+		{"custom", 10 * time.Minute, 10 * time.Minute},
+	}
+
+	for _, test := range tests {
+		s, err := tmpl.ImageURL(`https://grafana.test/render?panelId=2`, data, "render-token", test.expires, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("%s: image url error, %s", test.name, err)
+		}
+
+		// The token is not exposed, the url is signed instead.
+		if strings.Contains(s, "render-token") {
+			t.Errorf("%s: expected the token not exposed, got %s", test.name, s)
+		}
+
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatalf("%s: parse url error, %s", test.name, err)
+		}
+
+		expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+		if err != nil {
+			t.Fatalf("%s: parse expires error, %s", test.name, err)
+		}
+		if d := time.Until(time.Unix(expires, 0)); d <= test.expected-time.Minute || d > test.expected {
+			t.Errorf("%s: expected the url expires in %s, got %s", test.name, test.expected, d)
+		}
+
+		if !verifySignature(s, "render-token") {
+			t.Errorf("%s: expected the signature verified, got %s", test.name, s)
+		}
+	}
+}
+
+// Verify the signature of the url as the server sharing the key does.
+func verifySignature(s, key string) bool {
+
+	i := strings.LastIndex(s, "&signature=")
+	if i < 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(s[:i]))
+	return hmac.Equal([]byte(s[i+len("&signature="):]), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
+
+func TestSignURL(t *testing.T) {
+
+	expires := time.Unix(1600000000, 0)
+	tests := []struct {
+		name   string
+		url    string
+		signed string
+	}{
+		{"no query", "https://grafana.test/render", "https://grafana.test/render?expires=1600000000"},
+		{"query", "https://grafana.test/render?panelId=2", "https://grafana.test/render?expires=1600000000&panelId=2"},
+		{"fragment", "https://grafana.test/render?panelId=2#top", "https://grafana.test/render?expires=1600000000&panelId=2"},
+		// The signature given is replaced.
+		{"signed", "https://grafana.test/render?panelId=2&signature=forged&expires=1", "https://grafana.test/render?expires=1600000000&panelId=2"},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatalf("%s: parse url error, %s", test.name, err)
+		}
+
+		s := SignURL(u, "key", expires)
+		if !strings.HasPrefix(s, test.signed+"&signature=") {
+			t.Errorf("%s: expected %s signed, got %s", test.name, test.signed, s)
+		}
+
+		if !verifySignature(s, "key") {
+			t.Errorf("%s: expected the signature verified, got %s", test.name, s)
+		}
+
+		if verifySignature(s, "other") {
+			t.Errorf("%s: expected the signature not verified by the other key, got %s", test.name, s)
+		}
+	}
+}
@@ -5,27 +5,65 @@ import (
 	"fmt"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"golang.org/x/sync/singleflight"
+	"math/rand"
 	"sync"
 	"time"
 )
 
-type token struct {
-	accessToken   string
-	accessTokenAt time.Time
-	expires       time.Duration
-	mutex         sync.Mutex
-}
+const (
+	// The tokens not used within this time will not be refreshed in background.
+	TokenActiveWindow = time.Hour * 2
+	// The interval of checking whether the tokens need to be refreshed.
+	TokenRefreshInterval = time.Minute
+	// The timeout of refreshing a token in background.
+	TokenRefreshTimeout = time.Second * 10
+	// The timeout of fetching a token, the fetching is shared by all callers waiting for the same token,
+	// so it is not canceled when one of the callers is canceled.
+	TokenFetchTimeout = time.Second * 10
+	// The fetching failed with a retryable error is retried with the backoff until the fetch timeout.
+	TokenFetchBackoff    = time.Millisecond * 200
+	TokenFetchMaxBackoff = time.Second * 2
+)
 
 type AccessTokenService struct {
-	mutex  sync.Mutex
-	tokens map[string]token
+	mutex sync.Mutex
+	store TokenStore
+	// The token is refreshed in background at this time before it expires, it is disabled if it is 0.
+	refreshBefore time.Duration
+	refreshing    bool
+	// The functions to get the tokens used recently, the key is the key of token.
+	sources map[string]*tokenSource
+	logger  log.Logger
+	now     func() time.Time
+	// The refreshing of each token is advanced by a random time within the jitter, and the background
+	// refreshing starts after a random delay within the jitter, so the tokens are not refreshed at the same time.
+	// The random times are decided by the first refreshing check, so the jitter can be set at any time.
+	jitter time.Duration
+	random func(n int64) int64
+	// The time the background refreshing starts, it is zero before the first refreshing check.
+	startAt time.Time
+	// The concurrent fetching of the same token, including the refreshing, is merged into one request.
+	fetching singleflight.Group
+}
+
+type tokenSource struct {
+	getToken func(ctx context.Context) (string, time.Duration, error)
+	lastUsed time.Time
+	// The time the refreshing of the token is advanced, it is valid if jitterSet is true.
+	jitter    time.Duration
+	jitterSet bool
 }
 
 var ats *AccessTokenService
 
 func init() {
 	ats = &AccessTokenService{
-		tokens: make(map[string]token),
+		store:   NewMemoryTokenStore(),
+		sources: make(map[string]*tokenSource),
+		logger:  log.NewNopLogger(),
+		now:     time.Now,
+		random:  rand.Int63n,
 	}
 }
 
@@ -33,6 +71,141 @@ func GetAccessTokenService() *AccessTokenService {
 	return ats
 }
 
+// Set the store used to save access tokens, the default store saves tokens in memory.
+func (ats *AccessTokenService) SetStore(store TokenStore) {
+
+	ats.mutex.Lock()
+	defer ats.mutex.Unlock()
+
+	if store != nil {
+		ats.store = store
+	}
+}
+
+// SetRefreshJitter sets the jitter of the background refreshing.
+func (ats *AccessTokenService) SetRefreshJitter(jitter time.Duration) {
+
+	ats.mutex.Lock()
+	defer ats.mutex.Unlock()
+
+	if jitter >= 0 {
+		ats.jitter = jitter
+	}
+}
+
+// Get a random time within the jitter.
+func (ats *AccessTokenService) randomJitter() time.Duration {
+
+	if ats.jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(ats.random(int64(ats.jitter)))
+}
+
+// SetRefreshBefore sets the time before the token expires to refresh it in background, so that the sending
+// will not fail because of the expired token. Only the tokens used recently are refreshed.
+func (ats *AccessTokenService) SetRefreshBefore(d time.Duration, l log.Logger) {
+
+	ats.mutex.Lock()
+	defer ats.mutex.Unlock()
+
+	ats.refreshBefore = d
+	if l != nil {
+		ats.logger = l
+	}
+
+	if d > 0 && !ats.refreshing {
+		ats.refreshing = true
+		go func() {
+			ticker := time.NewTicker(TokenRefreshInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				ats.refresh()
+			}
+		}()
+	}
+}
+
+// Refresh the tokens which will expire within the refresh margin, the old token is kept if the refreshing failed.
+// The tokens are fetched out of the lock and the fetching is shared with GetToken, so GetToken is not blocked
+// by the refreshing.
+func (ats *AccessTokenService) refresh() {
+
+	type candidate struct {
+		key      string
+		margin   time.Duration
+		getToken func(ctx context.Context) (string, time.Duration, error)
+	}
+
+	ats.mutex.Lock()
+
+	if ats.refreshBefore <= 0 {
+		ats.mutex.Unlock()
+		return
+	}
+
+	now := ats.now()
+	if ats.startAt.IsZero() {
+		ats.startAt = now.Add(ats.randomJitter())
+	}
+	if now.Before(ats.startAt) {
+		ats.mutex.Unlock()
+		return
+	}
+
+	var candidates []candidate
+	for key, s := range ats.sources {
+		if now.Sub(s.lastUsed) > TokenActiveWindow {
+			delete(ats.sources, key)
+			continue
+		}
+
+		if !s.jitterSet {
+			s.jitter = ats.randomJitter()
+			s.jitterSet = true
+		}
+
+		candidates = append(candidates, candidate{
+			key:      key,
+			margin:   ats.refreshBefore + s.jitter,
+			getToken: s.getToken,
+		})
+	}
+
+	store := ats.store
+	logger := ats.logger
+	ats.mutex.Unlock()
+
+	for _, c := range candidates {
+		t, err := store.Get(c.key)
+		if err != nil || t == nil || t.ExpireAt.Sub(now) > c.margin {
+			continue
+		}
+
+		_, err, _ = ats.fetching.Do(c.key, func() (interface{}, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), TokenRefreshTimeout)
+			defer cancel()
+			accessToken, expires, err := fetch(ctx, logger, c.getToken)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := store.Set(c.key, &Token{
+				AccessToken: accessToken,
+				ExpireAt:    ats.now().Add(expires),
+			}); err != nil {
+				_ = level.Error(logger).Log("msg", "save token error", "error", err.Error())
+			}
+			_ = level.Debug(logger).Log("msg", "refresh token", "expires", expires.String())
+			return accessToken, nil
+		})
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "refresh token error, the old token is kept", "expireAt", t.ExpireAt.String(), "error", err.Error())
+		}
+	}
+}
+
 func (ats *AccessTokenService) InvalidToken(ctx context.Context, key string, l log.Logger) {
 
 	ats.mutex.Lock()
@@ -41,9 +214,8 @@ func (ats *AccessTokenService) InvalidToken(ctx context.Context, key string, l l
 	ch := make(chan interface{})
 
 	go func() {
-		t, ok := ats.tokens[key]
-		if ok {
-			t.accessTokenAt = time.Time{}
+		if err := ats.store.Delete(key); err != nil {
+			_ = level.Error(l).Log("msg", "delete token error", "error", err.Error())
 		}
 		ch <- struct{}{}
 	}()
@@ -57,46 +229,82 @@ func (ats *AccessTokenService) InvalidToken(ctx context.Context, key string, l l
 	}
 }
 
+// GetToken returns the token of the key, the token is fetched by getToken if it does not exist or is expired.
+// The concurrent calls of the same key share one fetching.
 func (ats *AccessTokenService) GetToken(ctx context.Context, key string, getToken func(ctx context.Context) (string, time.Duration, error)) (string, error) {
 
 	ats.mutex.Lock()
-	defer ats.mutex.Unlock()
 
-	ch := make(chan interface{})
+	// Record the key used, so that the token can be refreshed in background.
+	if ats.refreshBefore > 0 {
+		if s, ok := ats.sources[key]; ok {
+			s.getToken = getToken
+			s.lastUsed = ats.now()
+		} else {
+			ats.sources[key] = &tokenSource{
+				getToken: getToken,
+				lastUsed: ats.now(),
+			}
+		}
+	}
 
-	go func() {
-		t, ok := ats.tokens[key]
-		if ok && time.Since(t.accessTokenAt) < t.expires {
-			ch <- t.accessToken
-			return
+	store := ats.store
+	logger := ats.logger
+	ats.mutex.Unlock()
+
+	ch := ats.fetching.DoChan(key, func() (interface{}, error) {
+		t, err := store.Get(key)
+		if err == nil && t != nil && ats.now().Before(t.ExpireAt) {
+			return t.AccessToken, nil
 		}
 
-		accessToken, expires, err := getToken(ctx)
+		fetchCtx, cancel := context.WithTimeout(context.Background(), TokenFetchTimeout)
+		defer cancel()
+		accessToken, expires, err := fetch(fetchCtx, logger, getToken)
 		if err != nil {
-			ch <- err
-			return
-		} else {
-			ats.tokens[key] = token{
-				accessToken:   accessToken,
-				accessTokenAt: time.Now(),
-				expires:       expires,
-			}
-			ch <- accessToken
-			return
+			return nil, err
 		}
-	}()
+
+		t = &Token{
+			AccessToken: accessToken,
+			ExpireAt:    ats.now().Add(expires),
+		}
+		// The token is still usable even if it is not saved.
+		_ = store.Set(key, t)
+		return accessToken, nil
+	})
 
 	select {
 	case <-ctx.Done():
-		return "", fmt.Errorf("get token timeout")
-	case val := <-ch:
-		switch val.(type) {
-		case error:
-			return "", val.(error)
-		case string:
-			return val.(string), nil
-		default:
+		// The fetching may succeed later, so the timeout can be retried.
+		return "", NewRetryableError(fmt.Errorf("get token timeout"))
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+
+		accessToken, ok := res.Val.(string)
+		if !ok {
 			return "", fmt.Errorf("wrong token type")
 		}
+		return accessToken, nil
+	}
+}
+
+// Fetch the token. The fetching failed with a retryable error, such as the network error or the server error,
+// is retried with the exponential backoff and full jitter until the context is done.
+func fetch(ctx context.Context, l log.Logger, getToken func(ctx context.Context) (string, time.Duration, error)) (string, time.Duration, error) {
+
+	for attempt := 1; ; attempt++ {
+		accessToken, expires, err := getToken(ctx)
+		if err == nil || !IsRetryable(err) {
+			return accessToken, expires, err
+		}
+
+		wait := FullJitterBackoff(TokenFetchBackoff, TokenFetchMaxBackoff, attempt)
+		_ = level.Debug(l).Log("msg", "fetch token error, retry", "attempt", attempt, "wait", wait.String(), "error", err.Error())
+		if e := Sleep(ctx, wait); e != nil {
+			return "", 0, err
+		}
 	}
 }
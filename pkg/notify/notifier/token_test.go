@@ -0,0 +1,440 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A clock moved by the tests.
+type fakeClock struct {
+	mutex sync.Mutex
+	t     time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// A stub of the token API, it issues a new token for each request.
+type tokenServer struct {
+	*httptest.Server
+	fetches int32
+	// The requests are rejected if it is not 0.
+	reject int32
+}
+
+func newTokenServer() *tokenServer {
+
+	s := &tokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&s.reject) != 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		n := atomic.AddInt32(&s.fetches, 1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":7200}`, n)
+	}))
+
+	return s
+}
+
+func (s *tokenServer) getToken(ctx context.Context) (string, time.Duration, error) {
+
+	request, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	resp, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("get token error, status %d", resp.StatusCode)
+	}
+
+	res := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", 0, err
+	}
+
+	return res.AccessToken, time.Duration(res.ExpiresIn) * time.Second, nil
+}
+
+// Create a token service using the fake clock, the refreshing is triggered by the tests rather than the ticker.
+func newTestTokenService(clock *fakeClock, refreshBefore time.Duration) *AccessTokenService {
+	return &AccessTokenService{
+		store:         NewMemoryTokenStore(),
+		sources:       make(map[string]*tokenSource),
+		logger:        log.NewNopLogger(),
+		now:           clock.Now,
+		random:        func(n int64) int64 { return n / 2 },
+		refreshBefore: refreshBefore,
+	}
+}
+
+func getToken(t *testing.T, ats *AccessTokenService, s *tokenServer, want string) {
+
+	token, err := ats.GetToken(context.Background(), "key", s.getToken)
+	if err != nil {
+		t.Fatalf("get token error, %s", err)
+	}
+	if token != want {
+		t.Fatalf("expected %s, got %s", want, token)
+	}
+}
+
+func TestTokenRefreshBeforeExpiry(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	clock := &fakeClock{t: time.Now()}
+	ats := newTestTokenService(clock, time.Minute*10)
+
+	getToken(t, ats, s, "token-1")
+
+	// The token is not refreshed until it is within the margin.
+	clock.Advance(time.Hour)
+	ats.refresh()
+	if n := atomic.LoadInt32(&s.fetches); n != 1 {
+		t.Fatalf("expected 1 fetch, got %d", n)
+	}
+
+	clock.Advance(time.Minute * 55)
+	ats.refresh()
+	if n := atomic.LoadInt32(&s.fetches); n != 2 {
+		t.Fatalf("expected the token refreshed, got %d fetches", n)
+	}
+
+	// The refreshed token is used without fetching, even after the old token expires.
+	getToken(t, ats, s, "token-2")
+	clock.Advance(time.Minute * 10)
+	getToken(t, ats, s, "token-2")
+	if n := atomic.LoadInt32(&s.fetches); n != 2 {
+		t.Errorf("expected 2 fetches, got %d", n)
+	}
+}
+
+func TestTokenRefreshFailure(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	clock := &fakeClock{t: time.Now()}
+	ats := newTestTokenService(clock, time.Minute*10)
+
+	getToken(t, ats, s, "token-1")
+
+	// The old token is kept if the refreshing failed.
+	atomic.StoreInt32(&s.reject, 1)
+	clock.Advance(time.Hour*2 - time.Minute*5)
+	ats.refresh()
+	getToken(t, ats, s, "token-1")
+
+	// The token is fetched once it truly expires.
+	atomic.StoreInt32(&s.reject, 0)
+	clock.Advance(time.Minute * 5)
+	getToken(t, ats, s, "token-2")
+}
+
+func TestTokenRefreshInactive(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	clock := &fakeClock{t: time.Now()}
+	ats := newTestTokenService(clock, time.Minute*10)
+
+	getToken(t, ats, s, "token-1")
+
+	// The token not used recently is not refreshed.
+	clock.Advance(TokenActiveWindow + time.Minute)
+	ats.refresh()
+	if n := atomic.LoadInt32(&s.fetches); n != 1 {
+		t.Errorf("expected 1 fetch, got %d", n)
+	}
+	if len(ats.sources) != 0 {
+		t.Errorf("expected the inactive token removed, got %d", len(ats.sources))
+	}
+}
+
+func TestTokenRefreshDisabled(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	clock := &fakeClock{t: time.Now()}
+	ats := newTestTokenService(clock, 0)
+
+	getToken(t, ats, s, "token-1")
+	clock.Advance(time.Hour*2 - time.Minute)
+	ats.refresh()
+	if n := atomic.LoadInt32(&s.fetches); n != 1 {
+		t.Errorf("expected 1 fetch, got %d", n)
+	}
+}
+
+func TestTokenRefreshJitter(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	clock := &fakeClock{t: time.Now()}
+	ats := newTestTokenService(clock, time.Minute*10)
+	// The background refreshing starts after 5 minutes, and the refreshing is advanced by 5 minutes.
+	ats.SetRefreshJitter(time.Minute * 10)
+
+	getToken(t, ats, s, "token-1")
+
+	ats.refresh()
+	clock.Advance(time.Minute * 4)
+	ats.refresh()
+	if ats.sources["key"].jitterSet {
+		t.Error("expected the refreshing not started within the start delay")
+	}
+
+	// The token is refreshed 15 minutes before it expires.
+	clock.Advance(time.Hour*2 - time.Minute*20)
+	ats.refresh()
+	if n := atomic.LoadInt32(&s.fetches); n != 1 {
+		t.Fatalf("expected 1 fetch, got %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	ats.refresh()
+	if n := atomic.LoadInt32(&s.fetches); n != 2 {
+		t.Errorf("expected the token refreshed, got %d fetches", n)
+	}
+}
+
+func TestTokenRefreshJitterSpread(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	clock := &fakeClock{t: time.Now()}
+	ats := newTestTokenService(clock, time.Minute*10)
+	ats.random = rand.New(rand.NewSource(1)).Int63n
+	ats.SetRefreshJitter(time.Minute * 30)
+
+	// The tokens of all keys are fetched at the same time, and the tick refreshing each key is recorded.
+	const keys = 10
+	tick := 0
+	refreshed := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		getToken := func(ctx context.Context) (string, time.Duration, error) {
+			if _, ok := refreshed[key]; !ok && tick > 0 {
+				refreshed[key] = tick
+			}
+			return s.getToken(ctx)
+		}
+		if _, err := ats.GetToken(context.Background(), key, getToken); err != nil {
+			t.Fatalf("get token error, %s", err)
+		}
+	}
+
+	for tick = 1; time.Duration(tick)*TokenRefreshInterval < time.Hour*2; tick++ {
+		clock.Advance(TokenRefreshInterval)
+		ats.refresh()
+	}
+
+	if len(refreshed) != keys {
+		t.Fatalf("expected %d keys refreshed before expiry, got %d", keys, len(refreshed))
+	}
+
+	ticks := make(map[int]bool)
+	for _, n := range refreshed {
+		ticks[n] = true
+	}
+	if len(ticks) < 2 {
+		t.Errorf("expected the refreshing spread over several ticks, got %v", refreshed)
+	}
+}
+
+func TestGetTokenConcurrent(t *testing.T) {
+
+	s := newTokenServer()
+	defer s.Close()
+
+	ats := newTestTokenService(&fakeClock{t: time.Now()}, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ats.GetToken(context.Background(), "key", s.getToken); err != nil {
+				t.Errorf("get token error, %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The concurrent fetching is merged and the token is cached.
+	if n := atomic.LoadInt32(&s.fetches); n != 1 {
+		t.Errorf("expected 1 fetch, got %d", n)
+	}
+}
+
+func TestGetTokenMergeFetching(t *testing.T) {
+
+	// The token API blocks until all callers are waiting, so the token is not cached before they call.
+	release := make(chan struct{})
+	var fetches int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		_, _ = w.Write([]byte(`{"access_token":"token-1","expires_in":7200}`))
+	}))
+	defer s.Close()
+
+	ats := newTestTokenService(&fakeClock{t: time.Now()}, 0)
+	getToken := (&tokenServer{Server: s}).getToken
+
+	const callers = 50
+	var wg sync.WaitGroup
+	tokens := make(chan string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := ats.GetToken(context.Background(), "key", getToken)
+			if err != nil {
+				t.Errorf("get token error, %s", err)
+			}
+			tokens <- token
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	close(release)
+	wg.Wait()
+	close(tokens)
+
+	// All callers share the single in-flight fetching.
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected 1 fetch, got %d", n)
+	}
+	for token := range tokens {
+		if token != "token-1" {
+			t.Errorf("expected token-1, got %s", token)
+		}
+	}
+}
+
+func TestGetTokenRetry(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		status  int
+		fetches int32
+		err     bool
+	}{
+		// The server errors are retried until the fetching succeeds.
+		{"server error", http.StatusServiceUnavailable, 3, false},
+		// The invalid credentials are not retried.
+		{"client error", http.StatusForbidden, 1, true},
+	}
+
+	for _, test := range tests {
+		var fetches int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&fetches, 1) <= 2 {
+				w.WriteHeader(test.status)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"token-1","expires_in":7200}`))
+		}))
+
+		getToken := func(ctx context.Context) (string, time.Duration, error) {
+			request, err := http.NewRequest(http.MethodGet, s.URL, nil)
+			if err != nil {
+				return "", 0, err
+			}
+
+			body, err := DoHttpRequest(ctx, http.DefaultClient, request)
+			if err != nil {
+				return "", 0, err
+			}
+
+			res := struct {
+				AccessToken string `json:"access_token"`
+			}{}
+			if err := json.Unmarshal(body, &res); err != nil {
+				return "", 0, err
+			}
+			return res.AccessToken, time.Hour, nil
+		}
+
+		ats := newTestTokenService(&fakeClock{t: time.Now()}, 0)
+		token, err := ats.GetToken(context.Background(), "key", getToken)
+		s.Close()
+
+		if test.err && err == nil {
+			t.Errorf("%s: expected error, got token %s", test.name, token)
+		}
+		if !test.err && (err != nil || token != "token-1") {
+			t.Errorf("%s: expected token-1, got %s, %v", test.name, token, err)
+		}
+		if n := atomic.LoadInt32(&fetches); n != test.fetches {
+			t.Errorf("%s: expected %d fetches, got %d", test.name, test.fetches, n)
+		}
+	}
+}
+
+func TestGetTokenRetryTimeout(t *testing.T) {
+
+	var fetches int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer s.Close()
+
+	getToken := func(ctx context.Context) (string, time.Duration, error) {
+		request, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		if err != nil {
+			return "", 0, err
+		}
+		_, err = DoHttpRequest(ctx, http.DefaultClient, request)
+		return "", 0, err
+	}
+
+	// The caller stops waiting when its context is done, the timeout can be retried by the caller.
+	ats := newTestTokenService(&fakeClock{t: time.Now()}, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+
+	_, err := ats.GetToken(ctx, "key", getToken)
+	if err == nil || !IsRetryable(err) {
+		t.Fatalf("expected a retryable timeout, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&fetches); n < 2 {
+		t.Errorf("expected the fetching retried, got %d fetches", n)
+	}
+}
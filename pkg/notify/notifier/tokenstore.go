@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	json "github.com/json-iterator/go"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+const (
+	tokenSecretKey = "tokens"
+)
+
+type Token struct {
+	AccessToken string    `json:"accessToken"`
+	ExpireAt    time.Time `json:"expireAt"`
+}
+
+// TokenStore saves the access tokens, the key is in form of `CorpID | AgentID` or `AppKey | AppSecret`.
+type TokenStore interface {
+	// Get the token of the key, return nil if the token does not exist.
+	Get(key string) (*Token, error)
+	Set(key string, t *Token) error
+	Delete(key string) error
+}
+
+type memoryTokenStore struct {
+	mutex  sync.Mutex
+	tokens map[string]*Token
+}
+
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{
+		tokens: make(map[string]*Token),
+	}
+}
+
+func (s *memoryTokenStore) Get(key string) (*Token, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.tokens[key], nil
+}
+
+func (s *memoryTokenStore) Set(key string, t *Token) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tokens[key] = t
+	return nil
+}
+
+func (s *memoryTokenStore) Delete(key string) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.tokens, key)
+	return nil
+}
+
+// secretTokenStore saves the access tokens to a secret, so the tokens can be reused after restarting.
+// The tokens are cached in memory, the secret is only read when the token is not in the memory.
+type secretTokenStore struct {
+	ctx       context.Context
+	client    client.Client
+	namespace string
+	name      string
+	cache     TokenStore
+	mutex     sync.Mutex
+}
+
+func NewSecretTokenStore(ctx context.Context, c client.Client, namespace, name string) TokenStore {
+	return &secretTokenStore{
+		ctx:       ctx,
+		client:    c,
+		namespace: namespace,
+		name:      name,
+		cache:     NewMemoryTokenStore(),
+	}
+}
+
+func (s *secretTokenStore) Get(key string) (*Token, error) {
+
+	if t, _ := s.cache.Get(key); t != nil {
+		return t, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, tokens, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := tokens[key]
+	if !ok {
+		return nil, nil
+	}
+
+	_ = s.cache.Set(key, t)
+	return t, nil
+}
+
+func (s *secretTokenStore) Set(key string, t *Token) error {
+
+	_ = s.cache.Set(key, t)
+
+	return s.update(func(tokens map[string]*Token) {
+		tokens[key] = t
+	})
+}
+
+func (s *secretTokenStore) Delete(key string) error {
+
+	_ = s.cache.Delete(key)
+
+	return s.update(func(tokens map[string]*Token) {
+		delete(tokens, key)
+	})
+}
+
+func (s *secretTokenStore) update(f func(tokens map[string]*Token)) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	secret, tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	// Drop the expired tokens.
+	for k, t := range tokens {
+		if time.Now().After(t.ExpireAt) {
+			delete(tokens, k)
+		}
+	}
+
+	f(tokens)
+
+	bs, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	if secret == nil {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+			},
+			Data: map[string][]byte{
+				tokenSecretKey: bs,
+			},
+		}
+		return s.client.Create(s.ctx, secret)
+	}
+
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[tokenSecretKey] = bs
+	return s.client.Update(s.ctx, secret)
+}
+
+// Load the secret and the tokens saved in it, the secret will be nil if it does not exist.
+func (s *secretTokenStore) load() (*v1.Secret, map[string]*Token, error) {
+
+	tokens := make(map[string]*Token)
+
+	secret := &v1.Secret{}
+	if err := s.client.Get(s.ctx, types.NamespacedName{Namespace: s.namespace, Name: s.name}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, tokens, nil
+		}
+		return nil, nil, err
+	}
+
+	if bs, ok := secret.Data[tokenSecretKey]; ok && len(bs) > 0 {
+		if err := json.Unmarshal(bs, &tokens); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return secret, tokens, nil
+}
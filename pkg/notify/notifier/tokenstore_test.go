@@ -0,0 +1,153 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	json "github.com/json-iterator/go"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Create the secret saving the tokens, as the one left by the notification manager before restarting.
+func newTokenSecret(t *testing.T, tokens map[string]*Token) *v1.Secret {
+
+	bs, err := json.Marshal(tokens)
+	if err != nil {
+		t.Fatalf("marshal tokens error, %s", err)
+	}
+
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tokens", Namespace: "default"},
+		Data:       map[string][]byte{tokenSecretKey: bs},
+	}
+}
+
+// Read the tokens saved in the secret.
+func savedTokens(t *testing.T, c client.Client) map[string]*Token {
+
+	secret := &v1.Secret{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "tokens"}, secret); err != nil {
+		t.Fatalf("get secret error, %s", err)
+	}
+
+	tokens := make(map[string]*Token)
+	if err := json.Unmarshal(secret.Data[tokenSecretKey], &tokens); err != nil {
+		t.Fatalf("unmarshal tokens error, %s", err)
+	}
+
+	return tokens
+}
+
+func TestSecretTokenStore(t *testing.T) {
+
+	c := fake.NewFakeClient()
+	store := NewSecretTokenStore(context.Background(), c, "default", "tokens")
+
+	if token, err := store.Get("key1"); err != nil || token != nil {
+		t.Fatalf("expected no token without the secret, got %v, %v", token, err)
+	}
+
+	// The secret is created when the first token is saved.
+	expireAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := store.Set("key1", &Token{AccessToken: "token-1", ExpireAt: expireAt}); err != nil {
+		t.Fatalf("set token error, %s", err)
+	}
+	if err := store.Set("key2", &Token{AccessToken: "token-2", ExpireAt: expireAt}); err != nil {
+		t.Fatalf("set token error, %s", err)
+	}
+
+	tokens := savedTokens(t, c)
+	if len(tokens) != 2 || tokens["key1"].AccessToken != "token-1" || !tokens["key1"].ExpireAt.Equal(expireAt) {
+		t.Errorf("expected 2 tokens saved, got %v", tokens)
+	}
+
+	// The tokens are read from the secret by a new store.
+	token, err := NewSecretTokenStore(context.Background(), c, "default", "tokens").Get("key2")
+	if err != nil || token == nil || token.AccessToken != "token-2" {
+		t.Errorf("expected token-2 loaded, got %v, %v", token, err)
+	}
+
+	if err := store.Delete("key1"); err != nil {
+		t.Fatalf("delete token error, %s", err)
+	}
+	if token, _ := store.Get("key1"); token != nil {
+		t.Errorf("expected the token deleted, got %v", token)
+	}
+	if tokens := savedTokens(t, c); len(tokens) != 1 || tokens["key2"] == nil {
+		t.Errorf("expected only key2 saved, got %v", tokens)
+	}
+}
+
+func TestSecretTokenStoreDropExpired(t *testing.T) {
+
+	c := fake.NewFakeClient(newTokenSecret(t, map[string]*Token{
+		"expired": {AccessToken: "token-0", ExpireAt: time.Now().Add(-time.Minute)},
+	}))
+	store := NewSecretTokenStore(context.Background(), c, "default", "tokens")
+
+	// The expired tokens are dropped when the secret is updated.
+	if err := store.Set("key", &Token{AccessToken: "token-1", ExpireAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("set token error, %s", err)
+	}
+
+	if tokens := savedTokens(t, c); len(tokens) != 1 || tokens["key"] == nil {
+		t.Errorf("expected the expired token dropped, got %v", tokens)
+	}
+}
+
+func TestGetTokenAfterRestart(t *testing.T) {
+
+	var fetches int32
+	getToken := func(ctx context.Context) (string, time.Duration, error) {
+		return fmt.Sprintf("token-%d", atomic.AddInt32(&fetches, 1)), time.Hour, nil
+	}
+
+	c := fake.NewFakeClient(newTokenSecret(t, map[string]*Token{
+		"restart-valid":   {AccessToken: "token-0", ExpireAt: time.Now().Add(time.Hour)},
+		"restart-expired": {AccessToken: "token-0", ExpireAt: time.Now().Add(-time.Minute)},
+	}))
+
+	ats := GetAccessTokenService()
+	defer ats.SetStore(NewMemoryTokenStore())
+
+	// Each store is created as the notification manager restarts, it loads the tokens saved in the secret.
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		fetches int32
+	}{
+		// The token saved before restarting is used until it expires, the token API is not called.
+		{"valid", "restart-valid", "token-0", 0},
+		// The token is fetched once it expires, and the new token is saved for the next restarting.
+		{"expired", "restart-expired", "token-1", 1},
+		{"saved", "restart-expired", "token-1", 1},
+	}
+
+	for _, tt := range tests {
+		ats.SetStore(NewSecretTokenStore(context.Background(), c, "default", "tokens"))
+
+		token, err := ats.GetToken(context.Background(), tt.key, getToken)
+		if err != nil {
+			t.Fatalf("%s: get token error, %s", tt.name, err)
+		}
+
+		if token != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, token)
+		}
+
+		if n := atomic.LoadInt32(&fetches); n != tt.fetches {
+			t.Errorf("%s: expected %d fetches, got %d", tt.name, tt.fetches, n)
+		}
+	}
+
+	if tokens := savedTokens(t, c); tokens["restart-expired"] == nil || tokens["restart-expired"].AccessToken != "token-1" {
+		t.Errorf("expected token-1 saved, got %v", tokens)
+	}
+}
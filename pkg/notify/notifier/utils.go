@@ -1,6 +1,8 @@
 package notifier
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/md5"
 	"fmt"
@@ -11,6 +13,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func Md5key(val interface{}) (string, error) {
@@ -66,10 +71,47 @@ func UrlWithParameters(u string, parameters map[string]string) (string, error) {
 	return postMessageURL.String(), nil
 }
 
+// HTTPDoer sends the request and returns the body of the response, the error is returned if the request failed
+// or the response is not successful. The notifiers send requests through it, so a stub can be used in the tests.
+type HTTPDoer interface {
+	Do(ctx context.Context, client *http.Client, request *http.Request) ([]byte, error)
+}
+
+// HTTPDoerFunc allows a function to be used as a HTTPDoer.
+type HTTPDoerFunc func(ctx context.Context, client *http.Client, request *http.Request) ([]byte, error)
+
+func (f HTTPDoerFunc) Do(ctx context.Context, client *http.Client, request *http.Request) ([]byte, error) {
+	return f(ctx, client, request)
+}
+
+// DefaultHTTPDoer sends the requests by DoHttpRequest.
+var DefaultHTTPDoer HTTPDoer = HTTPDoerFunc(DoHttpRequest)
+
+// Gzip compresses the request body, the request is sent with the `Content-Encoding: gzip` header.
+func Gzip(body []byte) ([]byte, error) {
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 func DoHttpRequest(ctx context.Context, client *http.Client, request *http.Request) ([]byte, error) {
 
+	// Use the shared transport so that the idle connections can be reused.
 	if client == nil {
-		client = &http.Client{}
+		client = &http.Client{Transport: SharedTransport()}
+	} else if client.Transport == nil {
+		c := *client
+		c.Transport = SharedTransport()
+		client = &c
 	}
 
 	resp, err := client.Do(request.WithContext(ctx))
@@ -87,13 +129,102 @@ func DoHttpRequest(ctx context.Context, client *http.Client, request *http.Reque
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	// Some APIs respond 202 or 204 when succeed.
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		msg := ""
 		if body != nil && len(body) > 0 {
 			msg = string(body)
 		}
-		return nil, fmt.Errorf("http error, code: %d, message: %s", resp.StatusCode, msg)
+		he := &HttpError{
+			StatusCode: resp.StatusCode,
+			Message:    msg,
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			he.RetryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return nil, he
 	}
 
 	return body, nil
 }
+
+// ParseRetryAfter parses the value of the `Retry-After` header, which is either the seconds to wait or a HTTP-date.
+// It returns 0 if the value is invalid or the date has passed.
+func ParseRetryAfter(v string, now time.Time) time.Duration {
+
+	v = strings.TrimSpace(v)
+	if len(v) == 0 {
+		return 0
+	}
+
+	if s, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if s <= 0 {
+			return 0
+		}
+		return time.Duration(s) * time.Second
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0
+	}
+
+	if d := t.Sub(now); d > 0 {
+		return d
+	}
+
+	return 0
+}
+
+// Sleep waits for the duration, it returns the error of the context if the context is done before that.
+// An error is returned immediately if the deadline of the context is earlier than the end of the waiting.
+func Sleep(ctx context.Context, d time.Duration) error {
+
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return fmt.Errorf("wait %s exceeds the deadline of context", d.String())
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// FilterResolved drops the resolved alerts, only the firing alerts are retained.
+func FilterResolved(data template.Data) template.Data {
+
+	d := data
+	d.Alerts = nil
+	for _, alert := range data.Alerts {
+		if alert.Status == string(model.AlertFiring) {
+			d.Alerts = append(d.Alerts, alert)
+		}
+	}
+
+	if len(d.Alerts) > 0 {
+		d.Status = string(model.AlertFiring)
+	}
+
+	return d
+}
+
+// Fingerprints returns the fingerprints of the alerts separated by comma, it is used to trace the alerts in logs.
+func Fingerprints(data template.Data) string {
+
+	var fps []string
+	for _, alert := range data.Alerts {
+		fp := alert.Fingerprint
+		if len(fp) == 0 {
+			fp = KvToLabelSet(alert.Labels).Fingerprint().String()
+		}
+		fps = append(fps, fp)
+	}
+
+	return strings.Join(fps, ",")
+}
@@ -0,0 +1,177 @@
+package notifier
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/prometheus/alertmanager/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", time.Second * 3},
+		{" 120 ", time.Minute * 2},
+		{"0", 0},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(time.Second * 30).Format(http.TimeFormat), time.Second * 30},
+		{now.Add(-time.Second * 30).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDoHttpRequestRetryAfter(t *testing.T) {
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("slow down"))
+	}))
+	defer s.Close()
+
+	request, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Fatalf("create request error, %s", err)
+	}
+
+	_, err = DoHttpRequest(context.Background(), nil, request)
+
+	var he *HttpError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected the http error, got %v", err)
+	}
+
+	if he.StatusCode != http.StatusTooManyRequests || he.Message != "slow down" {
+		t.Errorf("unexpected http error %+v", he)
+	}
+
+	if d := RetryAfter(err); d != time.Second*2 {
+		t.Errorf("expected retry after 2s, got %s", d)
+	}
+
+	if !IsRetryable(err) {
+		t.Error("expected the rate limit retryable")
+	}
+}
+
+func TestSleep(t *testing.T) {
+
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+
+	// The waiting exceeding the deadline returns immediately.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	start := time.Now()
+	if err := Sleep(ctx, time.Second); err == nil {
+		t.Error("expected the error of the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*20 {
+		t.Errorf("expected to return immediately, took %s", elapsed)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := Sleep(ctx, time.Second); err == nil {
+		t.Error("expected the error of the cancelled context")
+	}
+}
+
+func TestFilterResolved(t *testing.T) {
+
+	data := template.Data{
+		Status: "resolved",
+		Alerts: template.Alerts{
+			{Status: "firing", Fingerprint: "a"},
+			{Status: "resolved", Fingerprint: "b"},
+			{Status: "firing", Fingerprint: "c"},
+		},
+	}
+
+	d := FilterResolved(data)
+	if len(d.Alerts) != 2 || d.Alerts[0].Fingerprint != "a" || d.Alerts[1].Fingerprint != "c" {
+		t.Errorf("expected the firing alerts a and c, got %v", d.Alerts)
+	}
+
+	if d.Status != "firing" {
+		t.Errorf("expected the status firing, got %s", d.Status)
+	}
+
+	// The data given is not modified.
+	if len(data.Alerts) != 3 {
+		t.Errorf("expected the alerts given unchanged, got %d", len(data.Alerts))
+	}
+
+	if d := FilterResolved(template.Data{Alerts: template.Alerts{{Status: "resolved"}}}); len(d.Alerts) != 0 {
+		t.Errorf("expected no alert, got %d", len(d.Alerts))
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+
+	labels := template.KV{"alertname": "alert2"}
+	data := template.Data{Alerts: template.Alerts{
+		{Fingerprint: "fp1"},
+		// The fingerprint is calculated from the labels if it is not set.
+		{Labels: labels},
+	}}
+
+	want := "fp1," + KvToLabelSet(labels).Fingerprint().String()
+	if got := Fingerprints(data); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if got := Fingerprints(template.Data{}); got != "" {
+		t.Errorf("expected no fingerprint, got %q", got)
+	}
+}
+
+func TestGzip(t *testing.T) {
+
+	for _, body := range [][]byte{nil, []byte("{}"), bytes.Repeat([]byte(`{"alertname":"alert1"}`), 1000)} {
+		content, err := Gzip(body)
+		if err != nil {
+			t.Fatalf("compress error, %s", err)
+		}
+
+		zr, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("read compressed body error, %s", err)
+		}
+		got, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("decompress error, %s", err)
+		}
+
+		if !bytes.Equal(got, body) {
+			t.Errorf("expected %d bytes round-tripped, got %d", len(body), len(got))
+		}
+	}
+
+	// The repeated alerts are compressed to a much smaller body.
+	body := bytes.Repeat([]byte(`{"alertname":"alert1"}`), 1000)
+	content, _ := Gzip(body)
+	if len(content) > len(body)/10 {
+		t.Errorf("expected the body compressed to less than %d bytes, got %d", len(body)/10, len(content))
+	}
+}
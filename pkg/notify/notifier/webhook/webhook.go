@@ -3,25 +3,45 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
 	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/metrics"
 	"github.com/kubesphere/notification-manager/pkg/notify/config"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	"github.com/mwitkow/go-conntrack"
 	"github.com/prometheus/alertmanager/template"
+	"hash"
+	"io"
+	"k8s.io/api/core/v1"
 	"net/http"
-	"net/url"
+	"sort"
 	"time"
 )
 
 const (
 	DefaultSendTimeout = time.Second * 5
 	DefaultTemplate    = `{{ template "webhook.default.message" . }}`
+	// The template to generate the message field of the request body if the template of the options is not set.
+	DefaultMessageTemplate = `{{ template "nm.default.text" . }}`
+
+	DefaultSignatureHeader    = "X-NM-Signature"
+	DefaultSignatureAlgorithm = "sha256"
+
+	DefaultBackoff = time.Millisecond * 500
+	// The header carrying the key which is the same in all attempts of a delivery, so the receiver can
+	// drop the duplicate deliveries caused by retrying.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	notifierType = "webhook"
 )
 
 type Notifier struct {
@@ -31,19 +51,23 @@ type Notifier struct {
 	logger       log.Logger
 	template     *notifier.Template
 	templateName string
+	// The maximum times to retry and the waiting time before the first retry.
+	maxRetries int
+	backoff    time.Duration
 }
 
-func NewWebhookNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) notifier.Notifier {
+func NewWebhookNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
 
 	var path []string
+	var externalURL string
 	opts := notifierCfg.ReceiverOpts
 	if opts != nil && opts.Global != nil {
 		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
 	}
-	tmpl, err := notifier.NewTemplate(path)
+	tmpl, err := notifier.NewTemplate(path, externalURL)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "WebhookNotifier: get template error", "error", err.Error())
-		return nil
+		return nil, fmt.Errorf("get template error, %s", err.Error())
 	}
 
 	n := &Notifier{
@@ -52,6 +76,7 @@ func NewWebhookNotifier(logger log.Logger, receivers []config.Receiver, notifier
 		logger:       logger,
 		template:     tmpl,
 		templateName: DefaultTemplate,
+		backoff:      DefaultBackoff,
 	}
 
 	if opts != nil && opts.Webhook != nil {
@@ -65,6 +90,16 @@ func NewWebhookNotifier(logger log.Logger, receivers []config.Receiver, notifier
 		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
 			n.templateName = opts.Global.Template
 		}
+
+		if r := opts.Webhook.Retry; r != nil {
+			if r.MaxRetries > 0 {
+				n.maxRetries = r.MaxRetries
+			}
+
+			if r.Backoff > 0 {
+				n.backoff = r.Backoff
+			}
+		}
 	}
 
 	for _, r := range receivers {
@@ -73,15 +108,39 @@ func NewWebhookNotifier(logger log.Logger, receivers []config.Receiver, notifier
 			continue
 		}
 
-		if receiver.WebhookConfig == nil {
-			_ = level.Warn(logger).Log("msg", "WebhookNotifier: ignore receiver because of empty config")
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "WebhookNotifier: ignore invalid receiver", "error", err.Error())
 			continue
 		}
 
-		n.webhooks = append(n.webhooks, receiver)
+		// The receiver is shared by the notifications, so the defaults are set in a copy of it.
+		c := *receiver.WebhookConfig
+		if c.HttpConfig == nil {
+			c.HttpConfig = &v1alpha1.HTTPClientConfig{}
+		}
+
+		if c.Signature != nil {
+			s := *c.Signature
+			if len(s.Header) == 0 {
+				s.Header = DefaultSignatureHeader
+			}
+
+			if len(s.Algorithm) == 0 {
+				s.Algorithm = DefaultSignatureAlgorithm
+			}
+			c.Signature = &s
+		}
+
+		if len(c.Method) == 0 {
+			c.Method = http.MethodPost
+		}
+
+		w := *receiver
+		w.WebhookConfig = &c
+		n.webhooks = append(n.webhooks, &w)
 	}
 
-	return n
+	return n, nil
 }
 
 func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
@@ -104,17 +163,63 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 			_ = level.Debug(n.logger).Log("msg", "WebhookNotifier: send message", "used", time.Since(start).String())
 		}()
 
-		var buf bytes.Buffer
-		if err := json.NewEncoder(&buf).Encode(value); err != nil {
-			_ = level.Error(n.logger).Log("msg", "WebhookNotifier: encode message error", "error", err.Error())
+		buf, err := n.body(w, data, value)
+		if err != nil {
 			return err
 		}
 
-		request, err := http.NewRequest(http.MethodPost, w.WebhookConfig.URL, &buf)
+		// The signature is computed over the body which is actually sent, before it is compressed.
+		payload := buf.Bytes()
+		if w.WebhookConfig.Method == http.MethodGet {
+			payload = nil
+		}
+
+		content := payload
+		if w.WebhookConfig.Compress && payload != nil {
+			content, err = notifier.Gzip(payload)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "WebhookNotifier: compress body error", "error", err.Error())
+				return err
+			}
+		}
+
+		key, err := idempotencyKey(w, data)
 		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "WebhookNotifier: generate idempotency key error", "error", err.Error())
 			return err
 		}
-		request.Header.Set("Content-Type", "application/json")
+
+		header := make(http.Header)
+		header.Set("Content-Type", "application/json")
+		header.Set(IdempotencyKeyHeader, key)
+		if w.WebhookConfig.Compress && payload != nil {
+			header.Set("Content-Encoding", "gzip")
+		}
+
+		for k, v := range w.WebhookConfig.Headers {
+			header.Set(k, v)
+		}
+
+		for k, v := range w.WebhookConfig.HeaderSecrets {
+			selector := v
+			value, err := n.notifierCfg.GetSecretData(w.GetNamespace(), &selector)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "WebhookNotifier: get header secret error", "header", k, "error", err.Error())
+				return err
+			}
+
+			header.Set(k, value)
+		}
+
+		if w.WebhookConfig.Signature != nil {
+			signature, err := n.sign(w, payload)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "WebhookNotifier: sign request error", "error", err.Error())
+				return err
+			}
+
+			header.Set(w.WebhookConfig.Signature.Header, signature)
+		}
 
 		if w.WebhookConfig.HttpConfig.BearerToken != nil {
 			bearer, err := n.notifierCfg.GetSecretData(w.GetNamespace(), w.WebhookConfig.HttpConfig.BearerToken)
@@ -123,7 +228,7 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 				return err
 			}
 
-			request.Header.Set("Authorization", bearer)
+			header.Set("Authorization", bearer)
 		} else if w.WebhookConfig.HttpConfig.BasicAuth != nil {
 			pass := ""
 			if w.WebhookConfig.HttpConfig.BasicAuth.Password != nil {
@@ -135,7 +240,8 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 
 				pass = p
 			}
-			request.SetBasicAuth(w.WebhookConfig.HttpConfig.BasicAuth.Username, pass)
+			auth := w.WebhookConfig.HttpConfig.BasicAuth.Username + ":" + pass
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
 		}
 
 		transport, err := n.getTransport(w)
@@ -149,13 +255,60 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 			Timeout:   n.timeout,
 		}
 
-		_, err = notifier.DoHttpRequest(ctx, client, request)
+		if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+			return err
+		}
+
+		notifier.GetRetryBudget().Request()
+		for attempt := 0; attempt <= n.maxRetries; attempt++ {
+			if attempt > 0 {
+				// The failure is returned immediately if the retry budget shared by all notifiers is exhausted.
+				if !notifier.GetRetryBudget().AllowRetry() {
+					_ = level.Warn(n.logger).Log("msg", "WebhookNotifier: retry budget exhausted, stop retrying", "attempt", attempt)
+					return err
+				}
+				metrics.ObserveRetry(notifierType)
+				wait := notifier.Backoff(n.backoff, attempt)
+				// Respect the waiting time required by the server if the request is rate limited.
+				if d := notifier.RetryAfter(err); d > 0 {
+					wait = d
+				}
+				_ = level.Debug(n.logger).Log("msg", "WebhookNotifier: retry to send message", "attempt", attempt, "wait", wait.String())
+				if e := notifier.Sleep(ctx, wait); e != nil {
+					_ = level.Error(n.logger).Log("msg", "WebhookNotifier: stop retrying", "error", e.Error())
+					return err
+				}
+			}
+
+			// The body is consumed by each request, so the request is recreated in each attempt.
+			var body io.Reader
+			if content != nil {
+				body = bytes.NewReader(content)
+			}
+
+			var request *http.Request
+			request, err = http.NewRequest(w.WebhookConfig.Method, w.WebhookConfig.URL, body)
+			if err != nil {
+				return err
+			}
+			request.Header = header.Clone()
+
+			_, err = notifier.DoHttpRequest(ctx, client, request)
+			if err == nil {
+				break
+			}
+
+			_ = level.Error(n.logger).Log("msg", "WebhookNotifier: do http request error", "attempt", attempt, "error", err.Error())
+			if !notifier.IsRetryable(err) {
+				break
+			}
+		}
+
 		if err != nil {
-			_ = level.Error(n.logger).Log("msg", "WebhookNotifier: do http request error", "error", err.Error())
 			return err
 		}
 
-		_ = level.Debug(n.logger).Log("msg", "WebhookNotifier: send message", "to", w.WebhookConfig.URL)
+		_ = level.Debug(n.logger).Log("msg", "WebhookNotifier: send message", "to", w.WebhookConfig.URL, "idempotencyKey", key)
 
 		return nil
 	}
@@ -171,79 +324,179 @@ func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
 	return group.Wait()
 }
 
+// Generate the request body, it is rendered by the body template of the webhook if it is set,
+// otherwise the value or the fields renamed by the field mapping are encoded as JSON.
+func (n *Notifier) body(w *config.Webhook, data template.Data, value interface{}) (*bytes.Buffer, error) {
+
+	var buf bytes.Buffer
+	if len(w.WebhookConfig.BodyTemplate) == 0 {
+		if len(w.WebhookConfig.FieldMapping) > 0 {
+			v, err := n.mappedBody(w, data)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		}
+
+		if err := json.NewEncoder(&buf).Encode(value); err != nil {
+			_ = level.Error(n.logger).Log("msg", "WebhookNotifier: encode message error", "error", err.Error())
+			return nil, err
+		}
+
+		return &buf, nil
+	}
+
+	msg, err := n.template.Text(w.WebhookConfig.BodyTemplate, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "WebhookNotifier: generate body error", "error", err.Error())
+		return nil, err
+	}
+
+	if !json.Valid([]byte(msg)) {
+		_ = level.Error(n.logger).Log("msg", "WebhookNotifier: body is not a valid JSON", "body", msg)
+		return nil, fmt.Errorf("the body generated by the body template is not a valid JSON")
+	}
+
+	buf.WriteString(msg)
+	return &buf, nil
+}
+
+// Generate the standard fields of the request body with the names in the field mapping.
+func (n *Notifier) mappedBody(w *config.Webhook, data template.Data) (map[string]interface{}, error) {
+
+	body := make(map[string]interface{})
+	for _, field := range config.WebhookFields {
+		name, ok := w.WebhookConfig.FieldMapping[field]
+		if !ok {
+			name = field
+		}
+
+		// The field is omitted.
+		if len(name) == 0 {
+			continue
+		}
+
+		switch field {
+		case config.WebhookFieldStatus:
+			body[name] = data.Status
+		case config.WebhookFieldReceiver:
+			body[name] = data.Receiver
+		case config.WebhookFieldLabels:
+			body[name] = data.CommonLabels
+		case config.WebhookFieldAnnotations:
+			body[name] = data.CommonAnnotations
+		case config.WebhookFieldAlerts:
+			body[name] = data.Alerts
+		case config.WebhookFieldMessage:
+			templateName := n.templateName
+			if templateName == DefaultTemplate {
+				templateName = DefaultMessageTemplate
+			}
+
+			msg, err := n.template.TempleText(templateName, data, n.logger)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "WebhookNotifier: generate message error", "error", err.Error())
+				return nil, err
+			}
+			body[name] = msg
+		}
+	}
+
+	return body, nil
+}
+
+// Generate the idempotency key of a delivery from the receiver name and the sorted fingerprints of the alerts,
+// so the key is the same in all attempts and redeliveries of the same alerts to the receiver.
+// The status of the alert is kept with the fingerprint, so the resolved alert is not deduplicated with the firing one.
+func idempotencyKey(w *config.Webhook, data template.Data) (string, error) {
+
+	var alerts []string
+	for _, a := range data.Alerts {
+		alerts = append(alerts, a.Fingerprint+"/"+a.Status)
+	}
+	sort.Strings(alerts)
+
+	return notifier.Md5key(struct {
+		Receiver string
+		Alerts   []string
+	}{
+		Receiver: w.GetName(),
+		Alerts:   alerts,
+	})
+}
+
 func (n *Notifier) getTransport(w *config.Webhook) (http.RoundTripper, error) {
 
-	transport := &http.Transport{
-		DisableKeepAlives:  false,
-		DisableCompression: true,
-		DialContext: conntrack.NewDialContextFunc(
-			conntrack.DialWithTracing(),
-			conntrack.DialWithName(w.WebhookConfig.URL),
-		),
+	getSecret := func(selector *v1.SecretKeySelector) (string, error) {
+		return n.notifierCfg.GetSecretData(w.GetNamespace(), selector)
 	}
 
+	proxyURL, proxyAuth, tlsConfig := "", (*v1alpha1.BasicAuth)(nil), (*v1alpha1.TLSConfig)(nil)
 	if c := w.WebhookConfig.HttpConfig; c != nil {
+		proxyURL, proxyAuth, tlsConfig = c.ProxyURL, c.ProxyAuth, c.TLSConfig
+	}
 
-		if c.TLSConfig != nil {
-			tlsConfig := &tls.Config{InsecureSkipVerify: c.TLSConfig.InsecureSkipVerify}
-
-			// If a CA cert is provided then let's read it in so we can validate the
-			// scrape target's certificate properly.
-			if c.TLSConfig.RootCA != nil {
-				if ca, err := n.notifierCfg.GetSecretData(w.GetNamespace(), c.TLSConfig.RootCA); err != nil {
-					return nil, err
-				} else {
-					caCertPool := x509.NewCertPool()
-					if !caCertPool.AppendCertsFromPEM([]byte(ca)) {
-						return nil, err
-					}
-					tlsConfig.RootCAs = caCertPool
-				}
-			}
+	// The connections are traced by the url, so the transport is cached for each url.
+	key, err := notifier.TransportKey(w.WebhookConfig.URL, proxyURL, proxyAuth, tlsConfig, getSecret)
+	if err != nil {
+		return nil, err
+	}
 
-			if len(c.TLSConfig.ServerName) > 0 {
-				tlsConfig.ServerName = c.TLSConfig.ServerName
-			}
-
-			// If a client cert & key is provided then configure TLS config accordingly.
-			if c.TLSConfig.ClientCertificate != nil {
-				if c.TLSConfig.Cert != nil && c.TLSConfig.Key == nil {
-					return nil, fmt.Errorf("client cert file specified without client key file")
-				} else if c.TLSConfig.Cert == nil && c.TLSConfig.Key != nil {
-					return nil, fmt.Errorf("client key file specified without client cert file")
-				} else if c.TLSConfig.Cert != nil && c.TLSConfig.Key != nil {
-					key, err := n.notifierCfg.GetSecretData(w.GetNamespace(), c.TLSConfig.Key)
-					if err != nil {
-						return nil, err
-					}
-
-					cert, err := n.notifierCfg.GetSecretData(w.GetNamespace(), c.TLSConfig.Cert)
-					if err != nil {
-						return nil, err
-					}
-
-					tlsCert, err := tls.X509KeyPair([]byte(cert), []byte(key))
-					if err != nil {
-						return nil, err
-					}
-					tlsConfig.Certificates = []tls.Certificate{tlsCert}
-				}
+	return notifier.CachedTransport(key, func() (*http.Transport, error) {
+
+		// The proxy of the environment is not used, the proxy is set by the http config.
+		transport := notifier.PooledTransport()
+		transport.Proxy = nil
+		transport.DisableKeepAlives = false
+		transport.DisableCompression = true
+		transport.DialContext = conntrack.NewDialContextFunc(
+			conntrack.DialWithTracing(),
+			conntrack.DialWithName(w.WebhookConfig.URL),
+		)
+
+		if tlsConfig != nil {
+			c, err := notifier.NewTLSConfig(tlsConfig, getSecret)
+			if err != nil {
+				return nil, err
 			}
 
-			transport.TLSClientConfig = tlsConfig
+			transport.TLSClientConfig = c
 		}
 
-		if len(c.ProxyURL) > 0 {
-			var proxy func(*http.Request) (*url.URL, error)
-			if u, err := url.Parse(c.ProxyURL); err != nil {
+		if len(proxyURL) > 0 {
+			proxy, err := notifier.ProxyFunc(proxyURL, proxyAuth, getSecret)
+			if err != nil {
 				return nil, err
-			} else {
-				proxy = http.ProxyURL(u)
 			}
 
 			transport.Proxy = proxy
 		}
+
+		return transport, nil
+	})
+}
+
+// Sign the request body, the signature is in form of `<algorithm>=<hex digest>`.
+func (n *Notifier) sign(w *config.Webhook, body []byte) (string, error) {
+
+	s := w.WebhookConfig.Signature
+
+	var h func() hash.Hash
+	switch s.Algorithm {
+	case "sha1":
+		h = sha1.New
+	case "sha256":
+		h = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported signature algorithm %s", s.Algorithm)
+	}
+
+	key, err := n.notifierCfg.GetSecretData(w.GetNamespace(), s.Secret)
+	if err != nil {
+		return "", err
 	}
 
-	return transport, nil
+	mac := hmac.New(h, []byte(key))
+	_, _ = mac.Write(body)
+	return s.Algorithm + "=" + hex.EncodeToString(mac.Sum(nil)), nil
 }
@@ -0,0 +1,635 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"hash"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNamespace = testutil.Namespace
+
+// The secrets used by the tests.
+var secrets = testutil.NewSecretCache(&v1.Secret{
+	ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: testNamespace},
+	Data: map[string][]byte{
+		"token":         []byte("Bearer secret-token"),
+		"signature-key": []byte("signature-key"),
+	},
+})
+
+// The request received by the recorder.
+type record struct {
+	method string
+	header http.Header
+	body   []byte
+	at     time.Time
+}
+
+// A server recording the requests, the response of each request is written by the handler if it is set.
+type recorder struct {
+	*httptest.Server
+	mu      sync.Mutex
+	records []record
+}
+
+func newRecorder(t *testing.T, handler func(w http.ResponseWriter, index int)) *recorder {
+
+	rec := &recorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body error, %s", err)
+		}
+
+		rec.mu.Lock()
+		rec.records = append(rec.records, record{method: r.Method, header: r.Header.Clone(), body: body, at: time.Now()})
+		index := len(rec.records) - 1
+		rec.mu.Unlock()
+
+		if handler != nil {
+			handler(w, index)
+		}
+	}))
+
+	return rec
+}
+
+func (rec *recorder) received() []record {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]record(nil), rec.records...)
+}
+
+func secretSelector(name, key string) *v1.SecretKeySelector {
+	return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: key}
+}
+
+func newReceiver(url string) *config.Webhook {
+
+	w := config.NewWebhookReceiver().(*config.Webhook)
+	w.SetNamespace(testNamespace)
+	w.SetName("webhook")
+	w.WebhookConfig = &config.WebhookConfig{URL: url}
+
+	return w
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.Options, receivers ...*config.Webhook) *Notifier {
+
+	c := testutil.NewConfig(secrets, opts)
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewWebhookNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(alertnames ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range alertnames {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      "firing",
+			Labels:      template.KV{"alertname": name},
+			Fingerprint: name,
+		})
+	}
+
+	return data
+}
+
+func TestNotifyMethodAndHeaders(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"", http.MethodPost},
+		{http.MethodPut, http.MethodPut},
+		{http.MethodPatch, http.MethodPatch},
+		{http.MethodGet, http.MethodGet},
+	}
+
+	for _, tt := range tests {
+		w := newReceiver(rec.URL)
+		w.WebhookConfig.Method = tt.method
+		w.WebhookConfig.Headers = map[string]string{"X-Cluster": "host"}
+		w.WebhookConfig.HeaderSecrets = map[string]v1.SecretKeySelector{"Authorization": *secretSelector("webhook", "token")}
+
+		n := newNotifier(t, nil, w)
+		if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+			t.Fatalf("%s: expected no error, got %v", tt.want, errs)
+		}
+
+		records := rec.received()
+		r := records[len(records)-1]
+		if r.method != tt.want {
+			t.Errorf("expected method %s, got %s", tt.want, r.method)
+		}
+
+		if v := r.header.Get("X-Cluster"); v != "host" {
+			t.Errorf("%s: expected the custom header, got %q", tt.want, v)
+		}
+
+		if v := r.header.Get("Authorization"); v != "Bearer secret-token" {
+			t.Errorf("%s: expected the header from the secret, got %q", tt.want, v)
+		}
+
+		if tt.want == http.MethodGet && len(r.body) != 0 {
+			t.Errorf("expected no body of the get request, got %s", r.body)
+		}
+
+		if tt.want != http.MethodGet && len(r.body) == 0 {
+			t.Errorf("%s: expected the body", tt.want)
+		}
+	}
+
+	if len(rec.received()) != len(tests) {
+		t.Errorf("expected %d requests, got %d", len(tests), len(rec.received()))
+	}
+}
+
+func TestNotifyInvalidMethod(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	w := newReceiver(rec.URL)
+	w.WebhookConfig.Method = http.MethodDelete
+	if err := w.Validate(); err == nil {
+		t.Error("expected the unsupported method invalid")
+	}
+
+	// The invalid receiver is ignored.
+	n := newNotifier(t, nil, w)
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Errorf("expected no error, got %v", errs)
+	}
+
+	if len(rec.received()) != 0 {
+		t.Errorf("expected no request, got %d", len(rec.received()))
+	}
+}
+
+func TestNotifyHeaderSecretError(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	w := newReceiver(rec.URL)
+	w.WebhookConfig.HeaderSecrets = map[string]v1.SecretKeySelector{"Authorization": *secretSelector("webhook-not-exist", "token")}
+
+	n := newNotifier(t, nil, w)
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 1 {
+		t.Errorf("expected the error of the header secret, got %v", errs)
+	}
+
+	if len(rec.received()) != 0 {
+		t.Errorf("expected no request, got %d", len(rec.received()))
+	}
+}
+
+func TestNotifySignature(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	tests := []struct {
+		name      string
+		method    string
+		header    string
+		algorithm string
+		hash      func() hash.Hash
+	}{
+		{name: "default", hash: sha256.New},
+		{name: "sha1", header: "X-Hub-Signature", algorithm: "sha1", hash: sha1.New},
+		{name: "empty body", method: http.MethodGet, hash: sha256.New},
+	}
+
+	for _, tt := range tests {
+		w := newReceiver(rec.URL)
+		w.WebhookConfig.Method = tt.method
+		w.WebhookConfig.Signature = &v1alpha1.WebhookSignature{
+			Secret:    secretSelector("webhook", "signature-key"),
+			Header:    tt.header,
+			Algorithm: tt.algorithm,
+		}
+
+		n := newNotifier(t, nil, w)
+		if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+			t.Fatalf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		records := rec.received()
+		r := records[len(records)-1]
+
+		header, algorithm := tt.header, tt.algorithm
+		if len(header) == 0 {
+			header = DefaultSignatureHeader
+		}
+		if len(algorithm) == 0 {
+			algorithm = DefaultSignatureAlgorithm
+		}
+
+		// The receiver recomputes the signature over the body received.
+		mac := hmac.New(tt.hash, []byte("signature-key"))
+		_, _ = mac.Write(r.body)
+		want := algorithm + "=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.header.Get(header); got != want {
+			t.Errorf("%s: expected signature %s, got %s", tt.name, want, got)
+		}
+	}
+}
+
+func TestNotifySignatureCompressed(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	w := newReceiver(rec.URL)
+	w.WebhookConfig.Compress = true
+	w.WebhookConfig.Signature = &v1alpha1.WebhookSignature{Secret: secretSelector("webhook", "signature-key")}
+
+	n := newNotifier(t, nil, w)
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	r := rec.received()[0]
+	zr, err := gzip.NewReader(bytes.NewReader(r.body))
+	if err != nil {
+		t.Fatalf("read compressed body error, %s", err)
+	}
+	body, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("decompress body error, %s", err)
+	}
+
+	// The signature is computed over the body before it is compressed.
+	mac := hmac.New(sha256.New, []byte("signature-key"))
+	_, _ = mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); r.header.Get(DefaultSignatureHeader) != want {
+		t.Errorf("expected signature %s, got %s", want, r.header.Get(DefaultSignatureHeader))
+	}
+}
+
+func TestNotifyBodyTemplate(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	w := newReceiver(rec.URL)
+	w.WebhookConfig.BodyTemplate = `{
+  "receiver": {{ toJson .Receiver }},
+  "count": {{ len .Alerts }},
+  "alerts": [{{ range $i, $a := .Alerts }}{{ if $i }},{{ end }}
+    {"name": {{ toJson $a.Labels.alertname }}, "labels": {"namespace": {{ toJson $a.Labels.namespace }}}, "summary": {{ toJson $a.Annotations.summary }}}{{ end }}
+  ]
+}`
+	n := newNotifier(t, nil, w)
+
+	data := newData("alert1", "alert2")
+	data.Alerts[0].Labels["namespace"] = "kube-system"
+	data.Alerts[0].Annotations = template.KV{"summary": `The "pod" is down`}
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	records := rec.received()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(records))
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(records[0].body, &body); err != nil {
+		t.Fatalf("expected the body is a valid JSON, got %s", err)
+	}
+
+	want := map[string]interface{}{
+		"receiver": "test",
+		"count":    float64(2),
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"name":    "alert1",
+				"labels":  map[string]interface{}{"namespace": "kube-system"},
+				"summary": `The "pod" is down`,
+			},
+			map[string]interface{}{
+				"name":    "alert2",
+				"labels":  map[string]interface{}{"namespace": ""},
+				"summary": "",
+			},
+		},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("expected the body %v, got %v", want, body)
+	}
+}
+
+func TestNotifyBodyTemplateInvalid(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	// The quotes are missing, so the body is not a valid JSON.
+	w := newReceiver(rec.URL)
+	w.WebhookConfig.BodyTemplate = `{"alerts": [{{ range .Alerts }}{{ .Labels.alertname }}{{ end }}]}`
+	n := newNotifier(t, nil, w)
+
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "not a valid JSON") {
+		t.Fatalf("expected the invalid JSON error, got %v", errs)
+	}
+
+	if len(rec.received()) != 0 {
+		t.Errorf("expected nothing sent, got %d requests", len(rec.received()))
+	}
+}
+
+func TestNotifyIdempotencyKey(t *testing.T) {
+
+	// The first attempt of each delivery fails.
+	rec := newRecorder(t, func(w http.ResponseWriter, index int) {
+		if index%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer rec.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Webhook: &v1alpha1.WebhookOptions{Retry: &v1alpha1.Retry{MaxRetries: 2, Backoff: time.Millisecond * 10}},
+	}, newReceiver(rec.URL))
+
+	for _, data := range []template.Data{newData("alert1", "alert2"), newData("alert3")} {
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	records := rec.received()
+	if len(records) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(records))
+	}
+
+	var keys []string
+	for _, r := range records {
+		keys = append(keys, r.header.Get(IdempotencyKeyHeader))
+	}
+
+	// The key is the same on both attempts of a delivery, and differs across the deliveries.
+	if len(keys[0]) == 0 || keys[0] != keys[1] {
+		t.Errorf("expected the same key on the retry, got %q and %q", keys[0], keys[1])
+	}
+	if keys[2] != keys[3] {
+		t.Errorf("expected the same key on the retry, got %q and %q", keys[2], keys[3])
+	}
+	if keys[0] == keys[2] {
+		t.Errorf("expected different keys for different notifications, got %q", keys[0])
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+
+	key := func(w *config.Webhook, data template.Data) string {
+		k, err := idempotencyKey(w, data)
+		if err != nil {
+			t.Fatalf("generate key error, %s", err)
+		}
+		return k
+	}
+
+	w := newReceiver("http://localhost")
+	other := newReceiver("http://localhost")
+	other.SetName("other")
+
+	resolved := newData("alert1", "alert2")
+	for i := range resolved.Alerts {
+		resolved.Alerts[i].Status = "resolved"
+	}
+
+	base := key(w, newData("alert1", "alert2"))
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{"same alerts", key(w, newData("alert1", "alert2")), true},
+		{"order of alerts", key(w, newData("alert2", "alert1")), true},
+		{"different alerts", key(w, newData("alert1")), false},
+		{"resolved alerts", key(w, resolved), false},
+		{"different receiver", key(other, newData("alert1", "alert2")), false},
+	}
+
+	for _, tt := range tests {
+		if (tt.key == base) != tt.same {
+			t.Errorf("%s: expected the same key %t, got %s and %s", tt.name, tt.same, base, tt.key)
+		}
+	}
+}
+
+func TestNotifyCompress(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		method   string
+		compress bool
+		encoding string
+	}{
+		{"compressed", http.MethodPost, true, "gzip"},
+		{"plain", http.MethodPost, false, ""},
+		// The request without body is not compressed.
+		{"get", http.MethodGet, true, ""},
+	}
+
+	for _, tt := range tests {
+		rec := newRecorder(t, nil)
+
+		w := newReceiver(rec.URL)
+		w.WebhookConfig.Method = tt.method
+		w.WebhookConfig.Compress = tt.compress
+
+		n := newNotifier(t, nil, w)
+		if errs := n.Notify(context.Background(), newData("alert1", "alert2")); len(errs) != 0 {
+			t.Fatalf("%s: expected no error, got %v", tt.name, errs)
+		}
+		rec.Close()
+
+		r := rec.received()[0]
+		if got := r.header.Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%s: expected encoding %q, got %q", tt.name, tt.encoding, got)
+		}
+
+		body := r.body
+		if tt.encoding == "gzip" {
+			zr, err := gzip.NewReader(bytes.NewReader(r.body))
+			if err != nil {
+				t.Fatalf("%s: read compressed body error, %s", tt.name, err)
+			}
+			if body, err = ioutil.ReadAll(zr); err != nil {
+				t.Fatalf("%s: decompress body error, %s", tt.name, err)
+			}
+		}
+
+		if tt.method == http.MethodGet {
+			if len(body) != 0 {
+				t.Errorf("%s: expected no body, got %q", tt.name, body)
+			}
+			continue
+		}
+
+		// The body decompressed is the alerts sent.
+		var got template.Data
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("%s: decode body error, %s", tt.name, err)
+		}
+		if len(got.Alerts) != 2 || got.Alerts[0].Labels["alertname"] != "alert1" || got.Alerts[1].Labels["alertname"] != "alert2" {
+			t.Errorf("%s: expected alert1 and alert2, got %+v", tt.name, got.Alerts)
+		}
+	}
+}
+
+func TestNotifyFieldMapping(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		mapping  map[string]string
+		expected map[string]interface{}
+	}{
+		{
+			name:    "text",
+			mapping: map[string]string{"message": "text", "labels": "commonLabels", "alerts": "", "annotations": ""},
+			expected: map[string]interface{}{
+				"status":       "firing",
+				"receiver":     "test",
+				"commonLabels": map[string]interface{}{"namespace": "default"},
+				"text":         "[firing] alert1\n[firing] alert2",
+			},
+		},
+		{
+			name:    "content",
+			mapping: map[string]string{"message": "content", "status": "state", "receiver": "", "labels": "", "annotations": "", "alerts": ""},
+			expected: map[string]interface{}{
+				"state":   "firing",
+				"content": "[firing] alert1\n[firing] alert2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		rec := newRecorder(t, nil)
+
+		w := newReceiver(rec.URL)
+		w.WebhookConfig.FieldMapping = tt.mapping
+
+		data := newData("alert1", "alert2")
+		data.CommonLabels = template.KV{"namespace": "default"}
+		n := newNotifier(t, nil, w)
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Fatalf("%s: expected no error, got %v", tt.name, errs)
+		}
+		rec.Close()
+
+		var got map[string]interface{}
+		if err := json.Unmarshal(rec.received()[0].body, &got); err != nil {
+			t.Fatalf("%s: decode body error, %s", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestNotifyFieldMappingAlerts(t *testing.T) {
+
+	rec := newRecorder(t, nil)
+	defer rec.Close()
+
+	// The fields not in the mapping keep their names.
+	w := newReceiver(rec.URL)
+	w.WebhookConfig.FieldMapping = map[string]string{"alerts": "events"}
+
+	n := newNotifier(t, nil, w)
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var got struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Events  template.Alerts `json:"events"`
+		Alerts  template.Alerts `json:"alerts"`
+	}
+	if err := json.Unmarshal(rec.received()[0].body, &got); err != nil {
+		t.Fatalf("decode body error, %s", err)
+	}
+
+	if got.Status != "firing" || got.Message != "[firing] alert1" {
+		t.Errorf("expected the status and message kept, got %+v", got)
+	}
+	if len(got.Alerts) != 0 || len(got.Events) != 1 || got.Events[0].Labels["alertname"] != "alert1" {
+		t.Errorf("expected the alerts renamed to events, got %+v", got)
+	}
+}
+
+func TestNewNotifierDefaults(t *testing.T) {
+
+	w := newReceiver("http://localhost")
+	w.WebhookConfig.Signature = &v1alpha1.WebhookSignature{Secret: secretSelector("webhook", "signature-key")}
+
+	// The notifiers are created concurrently with the same receiver, the defaults are not written to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newNotifier(t, nil, w)
+			if len(n.webhooks) != 1 {
+				t.Errorf("expected 1 webhook, got %d", len(n.webhooks))
+				return
+			}
+
+			c := n.webhooks[0].WebhookConfig
+			if c.HttpConfig == nil || c.Method != http.MethodPost ||
+				c.Signature.Header != DefaultSignatureHeader || c.Signature.Algorithm != DefaultSignatureAlgorithm {
+				t.Errorf("expected the defaults set, got %+v", c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := w.WebhookConfig
+	if c.HttpConfig != nil || len(c.Method) != 0 || len(c.Signature.Header) != 0 || len(c.Signature.Algorithm) != 0 {
+		t.Errorf("expected the receiver not changed, got %+v", c)
+	}
+}
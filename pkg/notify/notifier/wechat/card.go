@@ -0,0 +1,169 @@
+package wechat
+
+import (
+	"fmt"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/prometheus/alertmanager/template"
+)
+
+const (
+	CardTypeTextNotice = "text_notice"
+	CardTypeNewsNotice = "news_notice"
+	// The type of card action which opens the url.
+	CardActionURL = 1
+	// The type of card action which opens the mini program.
+	CardActionApp = 2
+	// The maximum number of the horizontal contents in a card.
+	HorizontalContentMaxSize = 6
+	// The maximum number of the vertical contents in a card.
+	VerticalContentMaxSize = 4
+	// The maximum number of the jumps in a card.
+	JumpMaxSize = 3
+)
+
+type weChatTemplateCard struct {
+	CardType              string               `json:"card_type"`
+	Source                *weChatCardSource    `json:"source,omitempty"`
+	MainTitle             *weChatCardTitle     `json:"main_title,omitempty"`
+	EmphasisContent       *weChatCardTitle     `json:"emphasis_content,omitempty"`
+	SubTitleText          string               `json:"sub_title_text,omitempty"`
+	CardImage             *weChatCardImage     `json:"card_image,omitempty"`
+	ImageTextArea         *weChatCardImageText `json:"image_text_area,omitempty"`
+	VerticalContentList   []*weChatCardTitle   `json:"vertical_content_list,omitempty"`
+	HorizontalContentList []*weChatCardContent `json:"horizontal_content_list,omitempty"`
+	JumpList              []*weChatCardJump    `json:"jump_list,omitempty"`
+	CardAction            *weChatCardAction    `json:"card_action,omitempty"`
+}
+
+type weChatCardSource struct {
+	IconURL   string `json:"icon_url,omitempty"`
+	Desc      string `json:"desc,omitempty"`
+	DescColor int    `json:"desc_color,omitempty"`
+}
+
+type weChatCardTitle struct {
+	Title string `json:"title,omitempty"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+type weChatCardImage struct {
+	URL         string  `json:"url"`
+	AspectRatio float64 `json:"aspect_ratio,omitempty"`
+}
+
+type weChatCardImageText struct {
+	Type     int    `json:"type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Desc     string `json:"desc,omitempty"`
+	ImageURL string `json:"image_url"`
+}
+
+type weChatCardContent struct {
+	KeyName string `json:"keyname"`
+	Value   string `json:"value,omitempty"`
+	// 1 means the value is an url, 0 means the value is a text.
+	Type int    `json:"type,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type weChatCardJump struct {
+	Type     int    `json:"type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Title    string `json:"title"`
+	AppID    string `json:"appid,omitempty"`
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+type weChatCardAction struct {
+	Type     int    `json:"type"`
+	URL      string `json:"url,omitempty"`
+	AppID    string `json:"appid,omitempty"`
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+// Check whether the card has the fields required by WeChat.
+func (c *weChatTemplateCard) validate() error {
+
+	if c.CardType != CardTypeTextNotice && c.CardType != CardTypeNewsNotice {
+		return fmt.Errorf("unknown card type %s", c.CardType)
+	}
+
+	if c.MainTitle == nil || len(c.MainTitle.Title) == 0 {
+		return fmt.Errorf("the main title of card is empty")
+	}
+
+	if c.CardAction == nil {
+		return fmt.Errorf("the action of card is empty")
+	}
+
+	switch c.CardAction.Type {
+	case CardActionURL:
+		if len(c.CardAction.URL) == 0 {
+			return fmt.Errorf("the url of card action is empty")
+		}
+	case CardActionApp:
+		if len(c.CardAction.AppID) == 0 {
+			return fmt.Errorf("the appid of card action is empty")
+		}
+	default:
+		return fmt.Errorf("unknown card action type %d", c.CardAction.Type)
+	}
+
+	if c.CardType == CardTypeNewsNotice && c.CardImage == nil && c.ImageTextArea == nil {
+		return fmt.Errorf("the image of news notice card is empty")
+	}
+
+	return nil
+}
+
+// Generate the template card messages, the template should generate a json array of cards,
+// and each card is sent in a message.
+func (n *Notifier) cardMessages(data template.Data, templateName string) ([]*weChatMessage, error) {
+
+	msg, err := n.template.TempleText(templateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: generate template card message error", "error", err.Error())
+		return nil, err
+	}
+
+	var cards []*weChatTemplateCard
+	if err := json.Unmarshal([]byte(msg), &cards); err != nil {
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: decode template cards error", "error", err.Error())
+		return nil, err
+	}
+
+	if len(cards) == 0 {
+		err := fmt.Errorf("no card generated by the template card template")
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: generate template card message error", "error", err.Error())
+		return nil, err
+	}
+
+	var messages []*weChatMessage
+	for _, card := range cards {
+		if err := card.validate(); err != nil {
+			_ = level.Error(n.logger).Log("msg", "WechatNotifier: invalid template card", "error", err.Error())
+			return nil, err
+		}
+
+		// Drop the contents exceeding the limits, rather than rejecting the whole card.
+		if len(card.HorizontalContentList) > HorizontalContentMaxSize {
+			card.HorizontalContentList = card.HorizontalContentList[:HorizontalContentMaxSize]
+		}
+
+		if len(card.VerticalContentList) > VerticalContentMaxSize {
+			card.VerticalContentList = card.VerticalContentList[:VerticalContentMaxSize]
+		}
+
+		if len(card.JumpList) > JumpMaxSize {
+			card.JumpList = card.JumpList[:JumpMaxSize]
+		}
+
+		messages = append(messages, &weChatMessage{
+			TemplateCard: card,
+		})
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,244 @@
+package wechat
+
+import (
+	"context"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newValidCard() *weChatTemplateCard {
+	return &weChatTemplateCard{
+		CardType:   CardTypeTextNotice,
+		MainTitle:  &weChatCardTitle{Title: "alert1"},
+		CardAction: &weChatCardAction{Type: CardActionURL, URL: "https://example.com/alerts"},
+	}
+}
+
+func TestCardValidate(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		modify func(c *weChatTemplateCard)
+		// The error expected, the card is valid if it is empty.
+		err string
+	}{
+		{"valid", func(c *weChatTemplateCard) {}, ""},
+		{"app action", func(c *weChatTemplateCard) { c.CardAction = &weChatCardAction{Type: CardActionApp, AppID: "app1"} }, ""},
+		{"news notice", func(c *weChatTemplateCard) {
+			c.CardType = CardTypeNewsNotice
+			c.CardImage = &weChatCardImage{URL: "https://example.com/alerts.png"}
+		}, ""},
+		{"unknown type", func(c *weChatTemplateCard) { c.CardType = "vote_interaction" }, "unknown card type"},
+		{"empty type", func(c *weChatTemplateCard) { c.CardType = "" }, "unknown card type"},
+		{"no main title", func(c *weChatTemplateCard) { c.MainTitle = nil }, "main title of card is empty"},
+		{"empty main title", func(c *weChatTemplateCard) { c.MainTitle.Title = "" }, "main title of card is empty"},
+		{"no action", func(c *weChatTemplateCard) { c.CardAction = nil }, "action of card is empty"},
+		{"action without url", func(c *weChatTemplateCard) { c.CardAction.URL = "" }, "url of card action is empty"},
+		{"action without appid", func(c *weChatTemplateCard) { c.CardAction = &weChatCardAction{Type: CardActionApp} }, "appid of card action is empty"},
+		{"unknown action", func(c *weChatTemplateCard) { c.CardAction.Type = 3 }, "unknown card action type 3"},
+		{"news without image", func(c *weChatTemplateCard) { c.CardType = CardTypeNewsNotice }, "image of news notice card is empty"},
+	}
+
+	for _, tt := range tests {
+		c := newValidCard()
+		tt.modify(c)
+
+		err := c.validate()
+		if len(tt.err) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected valid, got %s", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+// Render the cards and encode the messages as they are sent.
+func renderCards(t *testing.T, templateName string, alertnames ...string) []map[string]interface{} {
+
+	n := newNotifier(t, nil)
+	data := newData("firing", alertnames...)
+	for i := range data.Alerts {
+		data.Alerts[i].Labels["severity"] = "critical"
+	}
+
+	msgs, err := n.cardMessages(data, templateName)
+	if err != nil {
+		t.Fatalf("render cards error, %s", err)
+	}
+
+	var cards []map[string]interface{}
+	for _, msg := range msgs {
+		msg.Type = config.WechatTemplateCard
+		bs, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("encode message error, %s", err)
+		}
+
+		m := make(map[string]interface{})
+		if err := json.Unmarshal(bs, &m); err != nil {
+			t.Fatalf("decode message error, %s", err)
+		}
+		if m["msgtype"] != config.WechatTemplateCard {
+			t.Errorf("expected msgtype %s, got %v", config.WechatTemplateCard, m["msgtype"])
+		}
+
+		card, ok := m["template_card"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected the template card in the message, got %s", string(bs))
+		}
+		cards = append(cards, card)
+	}
+
+	return cards
+}
+
+func TestCardMessagesTextNotice(t *testing.T) {
+
+	cards := renderCards(t, DefaultCard, "alert1", "alert2")
+	if len(cards) != 2 {
+		t.Fatalf("expected 2 cards, got %d", len(cards))
+	}
+
+	card := cards[0]
+	if card["card_type"] != CardTypeTextNotice {
+		t.Errorf("expected card type %s, got %v", CardTypeTextNotice, card["card_type"])
+	}
+
+	want := map[string]interface{}{"title": "[firing] alert1"}
+	if !reflect.DeepEqual(card["main_title"], want) {
+		t.Errorf("expected main title %v, got %v", want, card["main_title"])
+	}
+
+	// The labels are the key-value contents.
+	contents := []interface{}{
+		map[string]interface{}{"keyname": "alertname", "value": "alert1"},
+		map[string]interface{}{"keyname": "severity", "value": "critical"},
+	}
+	if !reflect.DeepEqual(card["horizontal_content_list"], contents) {
+		t.Errorf("expected horizontal contents %v, got %v", contents, card["horizontal_content_list"])
+	}
+
+	action := map[string]interface{}{"type": float64(CardActionURL), "url": "https://example.com/alerts"}
+	if !reflect.DeepEqual(card["card_action"], action) {
+		t.Errorf("expected card action %v, got %v", action, card["card_action"])
+	}
+
+	// The empty fields are omitted.
+	for _, k := range []string{"card_image", "image_text_area", "jump_list", "emphasis_content"} {
+		if _, ok := card[k]; ok {
+			t.Errorf("expected %s omitted, got %v", k, card[k])
+		}
+	}
+}
+
+func TestCardMessagesNewsNotice(t *testing.T) {
+
+	cards := renderCards(t, "test.card.news", "alert1", "alert2", "alert3", "alert4", "alert5")
+	if len(cards) != 1 {
+		t.Fatalf("expected 1 card, got %d", len(cards))
+	}
+
+	card := cards[0]
+	if card["card_type"] != CardTypeNewsNotice {
+		t.Errorf("expected card type %s, got %v", CardTypeNewsNotice, card["card_type"])
+	}
+
+	image := map[string]interface{}{"url": "https://example.com/alerts.png", "aspect_ratio": 1.3}
+	if !reflect.DeepEqual(card["card_image"], image) {
+		t.Errorf("expected card image %v, got %v", image, card["card_image"])
+	}
+
+	// The contents exceeding the limits are dropped.
+	if l := card["vertical_content_list"].([]interface{}); len(l) != VerticalContentMaxSize {
+		t.Errorf("expected %d vertical contents, got %d", VerticalContentMaxSize, len(l))
+	}
+	if l := card["jump_list"].([]interface{}); len(l) != JumpMaxSize {
+		t.Errorf("expected %d jumps, got %d", JumpMaxSize, len(l))
+	}
+
+	action := map[string]interface{}{"type": float64(CardActionApp), "appid": "app1", "pagepath": "/alerts"}
+	if !reflect.DeepEqual(card["card_action"], action) {
+		t.Errorf("expected card action %v, got %v", action, card["card_action"])
+	}
+}
+
+func TestCardMessagesError(t *testing.T) {
+
+	n := newNotifier(t, nil)
+	data := newData("firing", "alert1")
+
+	tests := []struct {
+		name         string
+		templateName string
+		err          string
+	}{
+		{"invalid card", `{{ template "test.card.invalid" . }}`, "main title of card is empty"},
+		{"no card", `{{ template "test.card.empty" . }}`, "no card generated"},
+		{"not json", `{{ template "nm.default.text" . }}`, ""},
+	}
+
+	for _, tt := range tests {
+		_, err := n.cardMessages(data, tt.templateName)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected the error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestNotifyTemplateCard(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "template-card")
+	r.MsgType = config.WechatTemplateCard
+	n := newNotifier(t, nil, r)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	for _, msg := range msgs {
+		if msg.Type != config.WechatTemplateCard || msg.ToUser != "user1" {
+			t.Errorf("expected the template card sent to user1, got %s to %s", msg.Type, msg.ToUser)
+		}
+		if msg.TemplateCard == nil || msg.TemplateCard.CardType != CardTypeTextNotice {
+			t.Fatalf("expected the text notice card, got %+v", msg.TemplateCard)
+		}
+	}
+}
+
+func TestNotifyInvalidTemplateCard(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "invalid-template-card")
+	r.MsgType = config.WechatTemplateCard
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{TemplateCardTemplate: `{{ template "test.card.invalid" . }}`},
+	}, r)
+
+	// The malformed card is rejected before sending.
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if len(s.sent()) != 0 {
+		t.Errorf("expected no message sent, got %d", len(s.sent()))
+	}
+}
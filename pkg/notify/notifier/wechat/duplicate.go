@@ -0,0 +1,80 @@
+package wechat
+
+import (
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"sort"
+	"sync"
+	"time"
+)
+
+type sentContent struct {
+	msgType      string
+	text         *weChatMessageContent
+	markdown     *weChatMessageContent
+	news         *weChatNews
+	templateCard *weChatTemplateCard
+	expireAt     time.Time
+}
+
+// The content sent first within the duplicate check interval, the key is in form of `CorpID | AgentID | target | hash`.
+var sentCache = struct {
+	sync.Mutex
+	contents map[string]*sentContent
+}{
+	contents: make(map[string]*sentContent),
+}
+
+// Get the stable hash of the alerts, it only depends on the fingerprints and the status of the alerts,
+// so the alerts re-fired have the same hash even if their start time or annotations change.
+func alertsHash(data template.Data) (string, error) {
+
+	var alerts []string
+	for _, alert := range data.Alerts {
+		fp := alert.Fingerprint
+		if len(fp) == 0 {
+			fp = notifier.KvToLabelSet(alert.Labels).Fingerprint().String()
+		}
+		alerts = append(alerts, fp+"/"+alert.Status)
+	}
+	sort.Strings(alerts)
+
+	return notifier.Md5key(alerts)
+}
+
+// Replace the content of the message with the content sent first with the same hash within the duplicate check
+// interval, so that WeChat treats the message as a duplicate. The content is recorded if it is not sent before.
+func reuseContent(w *config.Wechat, hash string, msg *weChatMessage) {
+
+	interval := w.DuplicateCheckInterval
+	if interval <= 0 {
+		interval = DefaultDuplicateCheckInterval
+	}
+
+	key := tokenKey(w) + " | " + target(w) + " | " + hash
+	now := time.Now()
+
+	sentCache.Lock()
+	defer sentCache.Unlock()
+
+	for k, c := range sentCache.contents {
+		if now.After(c.expireAt) {
+			delete(sentCache.contents, k)
+		}
+	}
+
+	if c, ok := sentCache.contents[key]; ok && c.msgType == msg.Type {
+		msg.Text, msg.Markdown, msg.News, msg.TemplateCard = c.text, c.markdown, c.news, c.templateCard
+		return
+	}
+
+	sentCache.contents[key] = &sentContent{
+		msgType:      msg.Type,
+		text:         msg.Text,
+		markdown:     msg.Markdown,
+		news:         msg.News,
+		templateCard: msg.TemplateCard,
+		expireAt:     now.Add(time.Duration(interval) * time.Second),
+	}
+}
@@ -0,0 +1,98 @@
+package wechat
+
+import (
+	"context"
+	"github.com/prometheus/alertmanager/template"
+	"testing"
+	"time"
+)
+
+func TestAlertsHash(t *testing.T) {
+
+	hash := func(data template.Data) string {
+		h, err := alertsHash(data)
+		if err != nil {
+			t.Fatalf("get alerts hash error, %s", err)
+		}
+		return h
+	}
+
+	base := hash(newData("firing", "alert1", "alert2"))
+
+	// The alert re-fired has the same hash even if the order, the start time or the annotations change.
+	refired := newData("firing", "alert2", "alert1")
+	for i := range refired.Alerts {
+		refired.Alerts[i].StartsAt = time.Now().Add(time.Minute)
+		refired.Alerts[i].Annotations["message"] = "changed"
+	}
+	if h := hash(refired); h != base {
+		t.Errorf("expected the same hash of the alerts re-fired, got %s and %s", base, h)
+	}
+
+	if h := hash(newData("resolved", "alert1", "alert2")); h == base {
+		t.Error("expected the hash differs by the status")
+	}
+
+	if h := hash(newData("firing", "alert1", "alert3")); h == base {
+		t.Error("expected the hash differs by the alerts")
+	}
+
+	// The fingerprint is computed from the labels if it is not set.
+	unset := newData("firing", "alert1")
+	unset.Alerts[0].Fingerprint = ""
+	if h := hash(unset); h == hash(newData("firing", "alert1")) || h != hash(unset) {
+		t.Errorf("expected the stable hash of the labels, got %s", h)
+	}
+}
+
+func TestNotifyDuplicateCheckByFingerprint(t *testing.T) {
+
+	for _, byFingerprint := range []bool{true, false} {
+		s := newWechatServer(t, nil)
+
+		w := newReceiver(s.URL, "duplicate-fingerprint")
+		w.Template = "test.annotated"
+		w.EnableDuplicateCheck = true
+		w.DuplicateCheckInterval = 600
+		w.DuplicateCheckByFingerprint = byFingerprint
+		n := newNotifier(t, nil, w)
+
+		// The alert re-fired with a different annotation, and another alert.
+		for _, msg := range []string{"cpu 91%", "cpu 92%"} {
+			data := newData("firing", "alert1")
+			data.Alerts[0].Annotations["message"] = msg
+			if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+				t.Fatalf("by fingerprint %v: expected no error, got %v", byFingerprint, errs)
+			}
+		}
+		data := newData("firing", "alert2")
+		data.Alerts[0].Annotations["message"] = "cpu 93%"
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Fatalf("by fingerprint %v: expected no error, got %v", byFingerprint, errs)
+		}
+		s.Close()
+
+		msgs := s.sent()
+		if len(msgs) != 3 {
+			t.Fatalf("by fingerprint %v: expected 3 messages, got %d", byFingerprint, len(msgs))
+		}
+
+		for _, m := range msgs {
+			if m.EnableDuplicateCheck != 1 || m.DuplicateCheckInterval != 600 {
+				t.Errorf("by fingerprint %v: expected the duplicate check 1/600, got %d/%d", byFingerprint, m.EnableDuplicateCheck, m.DuplicateCheckInterval)
+			}
+		}
+
+		// The content sent first is reused, so WeChat treats the alert re-fired as a duplicate.
+		first, second, other := msgs[0].Text.Content, msgs[1].Text.Content, msgs[2].Text.Content
+		if first != "alert1: cpu 91%" || other != "alert2: cpu 93%" {
+			t.Errorf("by fingerprint %v: unexpected messages %q and %q", byFingerprint, first, other)
+		}
+		if byFingerprint && second != first {
+			t.Errorf("by fingerprint %v: expected the content %q reused, got %q", byFingerprint, first, second)
+		}
+		if !byFingerprint && second != "alert1: cpu 92%" {
+			t.Errorf("by fingerprint %v: expected the content rendered, got %q", byFingerprint, second)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package wechat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"golang.org/x/sync/singleflight"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	// The media uploaded expires in 3 days, it is uploaded again before that.
+	MediaExpires = time.Hour * 48
+	// The timeout of uploading a media, the uploading is shared by all callers waiting for the same media,
+	// so it is not canceled when one of the callers is canceled.
+	MediaUploadTimeout = time.Second * 30
+)
+
+type media struct {
+	id       string
+	expireAt time.Time
+}
+
+// The media ids uploaded, the key is in form of `CorpID | AgentID | type | source`, the CorpID is resolved
+// from the secret reference. The expired media are evicted when a media is uploaded.
+var mediaCache = struct {
+	sync.Mutex
+	media map[string]*media
+	// The concurrent uploading of the same media is merged into one.
+	uploading singleflight.Group
+}{
+	media: make(map[string]*media),
+}
+
+// Get the id of the media of the receiver, the media is uploaded if it is not uploaded or expired.
+func (n *Notifier) getMedia(ctx context.Context, w *config.Wechat, accessToken string) (string, error) {
+
+	source := w.Media.URL
+	if w.Media.Secret != nil {
+		source = w.GetNamespace() + "/" + w.Media.Secret.Name + "/" + w.Media.Secret.Key
+	}
+	corpID, err := n.notifierCfg.GetSecretValue(w.GetNamespace(), w.WechatConfig.CorpID)
+	if err != nil {
+		return "", err
+	}
+	key := corpID + " | " + w.WechatConfig.AgentID + " | " + w.MsgType + " | " + source
+
+	mediaCache.Lock()
+	m, ok := mediaCache.media[key]
+	mediaCache.Unlock()
+	if ok && time.Now().Before(m.expireAt) {
+		return m.id, nil
+	}
+
+	ch := mediaCache.uploading.DoChan(key, func() (interface{}, error) {
+
+		uploadCtx, cancel := context.WithTimeout(context.Background(), MediaUploadTimeout)
+		defer cancel()
+
+		content, fileName, err := n.getMediaContent(uploadCtx, w)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := n.uploadMedia(uploadCtx, w, accessToken, content, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		mediaCache.Lock()
+		for k, m := range mediaCache.media {
+			if !now.Before(m.expireAt) {
+				delete(mediaCache.media, k)
+			}
+		}
+		mediaCache.media[key] = &media{
+			id:       id,
+			expireAt: now.Add(MediaExpires),
+		}
+		mediaCache.Unlock()
+
+		return id, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// Get the content and the file name of the media.
+func (n *Notifier) getMediaContent(ctx context.Context, w *config.Wechat) ([]byte, string, error) {
+
+	fileName := w.Media.FileName
+
+	if w.Media.Secret != nil {
+		content, err := n.notifierCfg.GetSecretData(w.GetNamespace(), w.Media.Secret)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if len(fileName) == 0 {
+			fileName = w.Media.Secret.Key
+		}
+
+		return []byte(content), fileName, nil
+	}
+
+	if len(fileName) == 0 {
+		u, err := url.Parse(w.Media.URL)
+		if err != nil {
+			return nil, "", err
+		}
+		fileName = path.Base(u.Path)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, w.Media.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	request.Header.Set("User-Agent", n.userAgent)
+
+	client, err := n.getClient(w)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := n.doer.Do(ctx, client, request)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return content, fileName, nil
+}
+
+// Upload the media to wechat, and return the media id.
+func (n *Notifier) uploadMedia(ctx context.Context, w *config.Wechat, accessToken string, content []byte, fileName string) (string, error) {
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("media", fileName)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	u, err := notifier.UrlWithPath(w.WechatConfig.APIURL, "media/upload")
+	if err != nil {
+		return "", err
+	}
+
+	parameters := make(map[string]string)
+	parameters["access_token"] = accessToken
+	parameters["type"] = w.MsgType
+	u, err = notifier.UrlWithParameters(u, parameters)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u, &buf)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("User-Agent", n.userAgent)
+
+	client, err := n.getClient(w)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := n.doer.Do(ctx, client, request)
+	if err != nil {
+		return "", err
+	}
+
+	var resp weChatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+
+	if resp.Code == AccessTokenInvalid {
+		n.invalidToken(ctx, w)
+	}
+
+	if resp.Code != 0 {
+		err := fmt.Errorf("wechat upload media error, errcode: %d, errmsg: %s", resp.Code, resp.Error)
+		// The uploading can be retried with the new token if the token is expired.
+		if resp.Code == AccessTokenInvalid || resp.Code == SystemBusy {
+			return "", notifier.NewRetryableError(err)
+		}
+		return "", err
+	}
+
+	return resp.MediaID, nil
+}
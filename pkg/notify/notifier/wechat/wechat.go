@@ -3,87 +3,207 @@ package wechat
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
 	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/metrics"
 	"github.com/kubesphere/notification-manager/pkg/notify/config"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	DefaultApiURL      = "https://qyapi.weixin.qq.com/cgi-bin/"
+	DefaultSendPath    = "message/send"
+	DefaultTokenPath   = "gettoken"
 	DefaultSendTimeout = time.Second * 3
 	ToUserBatchSize    = 1000
 	ToPartyBatchSize   = 100
 	ToTagBatchSize     = 100
 	AccessTokenInvalid = 42001
+	SystemBusy         = -1
+	DefaultMaxRetries  = 3
+	DefaultBackoff     = time.Millisecond * 500
 	DefaultTemplate    = `{{ template "nm.default.text" . }}`
-	MessageMaxSize     = 2048
-	DefaultExpires     = time.Hour * 2
+	DefaultMarkdown    = `{{ template "nm.default.markdown" . }}`
+	DefaultNews        = `{{ template "nm.default.news" . }}`
+	DefaultCard        = `{{ template "nm.default.card" . }}`
+	notifierType       = "wechat"
+	// The maximum number of articles in a news message.
+	ArticlesMaxSize = 8
+	MessageMaxSize  = 2048
+	DefaultExpires  = time.Hour * 2
+	// The token will be refreshed at this time before it expires.
+	ExpiresMargin = time.Minute * 5
+	// The maximum and the default interval of the duplicate check of WeChat in seconds.
+	DuplicateCheckIntervalMax     = 14400
+	DefaultDuplicateCheckInterval = 1800
+	// The default cooldown of the circuit breaker.
+	DefaultCooldown = time.Minute
+	// The label used to route the alerts.
+	SeverityLabel = "severity"
 )
 
 type Notifier struct {
-	notifierCfg    *config.Config
-	wechat         map[string]*config.Wechat
-	accessToken    string
-	timeout        time.Duration
-	logger         log.Logger
-	template       *notifier.Template
-	templateName   string
-	ats            *notifier.AccessTokenService
-	messageMaxSize int
-	tokenExpires   time.Duration
+	notifierCfg  *config.Config
+	wechat       map[string]*config.Wechat
+	accessToken  string
+	timeout      time.Duration
+	logger       log.Logger
+	template     *notifier.Template
+	templateName string
+	decoration   *notifier.Decoration
+	// The name of template to generate markdown message.
+	markdownTemplateName string
+	// The name of template to generate news message.
+	newsTemplateName string
+	// The name of template to generate template card message.
+	cardTemplateName string
+	ats              *notifier.AccessTokenService
+	messageMaxSize   int
+	tokenExpires     time.Duration
+	// The maximum times to retry after the first sending failed.
+	maxRetries int
+	// The waiting time before the first retry.
+	backoff time.Duration
+	// The identical message sent within the window will be dropped.
+	dedupWindow time.Duration
+	// The rate limit of sending message of each wechat application.
+	rateLimit *v1alpha1.RateLimit
+	// The circuit breaker of each wechat application, it is disabled if the failure threshold is 0.
+	failureThreshold int
+	cooldown         time.Duration
+	// The maximum number of messages sent at the same time.
+	maxConcurrency int
+	// Whether to send the messages of each receiver one by one in order.
+	serialDelivery bool
+	sendResolved   bool
+	splitMode      string
+	userAgent      string
+	dryRun         bool
+	// The labels used to regroup the alerts, each group is sent in its own messages.
+	groupBy []string
+	// The requests to WeChat are sent by the doer.
+	doer notifier.HTTPDoer
+	// The names of the receivers merged into each receiver, the results of the buffered alerts are recorded for them.
+	names map[string][]string
+	// The names of the receivers which nothing was sent to in the notification.
+	deferred      []string
+	deferredMutex sync.Mutex
 }
 
 type weChatMessageContent struct {
 	Content string `json:"content"`
 }
 
+type weChatArticle struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	PicURL      string `json:"picurl,omitempty"`
+}
+
+type weChatNews struct {
+	Articles []*weChatArticle `json:"articles"`
+}
+
+type weChatMedia struct {
+	MediaID string `json:"media_id"`
+}
+
 type weChatMessage struct {
-	Text    weChatMessageContent `yaml:"text,omitempty" json:"text,omitempty"`
-	ToUser  string               `yaml:"touser,omitempty" json:"touser,omitempty"`
-	ToParty string               `yaml:"toparty,omitempty" json:"toparty,omitempty"`
-	Totag   string               `yaml:"totag,omitempty" json:"totag,omitempty"`
-	AgentID string               `yaml:"agentid,omitempty" json:"agentid,omitempty"`
-	Safe    string               `yaml:"safe,omitempty" json:"safe,omitempty"`
-	Type    string               `yaml:"msgtype,omitempty" json:"msgtype,omitempty"`
+	Text     *weChatMessageContent `yaml:"text,omitempty" json:"text,omitempty"`
+	Markdown *weChatMessageContent `yaml:"markdown,omitempty" json:"markdown,omitempty"`
+	News     *weChatNews           `yaml:"news,omitempty" json:"news,omitempty"`
+	Image    *weChatMedia          `yaml:"image,omitempty" json:"image,omitempty"`
+	File     *weChatMedia          `yaml:"file,omitempty" json:"file,omitempty"`
+	ToUser   string                `yaml:"touser,omitempty" json:"touser,omitempty"`
+	ToParty  string                `yaml:"toparty,omitempty" json:"toparty,omitempty"`
+	Totag    string                `yaml:"totag,omitempty" json:"totag,omitempty"`
+	ChatID   string                `yaml:"chatid,omitempty" json:"chatid,omitempty"`
+	AgentID  string                `yaml:"agentid,omitempty" json:"agentid,omitempty"`
+	Safe     string                `yaml:"safe,omitempty" json:"safe,omitempty"`
+	// Whether to enable the duplicate check, 0 means disable, 1 means enable.
+	EnableDuplicateCheck   int    `yaml:"enable_duplicate_check,omitempty" json:"enable_duplicate_check,omitempty"`
+	DuplicateCheckInterval int    `yaml:"duplicate_check_interval,omitempty" json:"duplicate_check_interval,omitempty"`
+	Type                   string `yaml:"msgtype,omitempty" json:"msgtype,omitempty"`
+	// The interactive card message.
+	TemplateCard *weChatTemplateCard `yaml:"template_card,omitempty" json:"template_card,omitempty"`
+	// The stable hash of the alerts in the message, it is used as the key of the duplicate check.
+	alertsHash string
 }
 
 type weChatResponse struct {
-	Code        int    `json:"code"`
-	Error       string `json:"error"`
+	Code        int    `json:"errcode"`
+	Error       string `json:"errmsg"`
 	AccessToken string `json:"access_token,omitempty"`
+	// The lifetime of the access token in seconds.
+	ExpiresIn int `json:"expires_in,omitempty"`
+	// The id of the media uploaded.
+	MediaID string `json:"media_id,omitempty"`
+	// The id of the message sent.
+	MsgID string `json:"msgid,omitempty"`
+	// The recipients which the message can not be sent to, separated by '|'.
+	InvalidUser  string `json:"invaliduser,omitempty"`
+	InvalidParty string `json:"invalidparty,omitempty"`
+	InvalidTag   string `json:"invalidtag,omitempty"`
 }
 
-func NewWechatNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) notifier.Notifier {
+func NewWechatNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
 
 	var path []string
+	var externalURL string
+	var header, footer string
+	var dedupWindow time.Duration
+	userAgent := notifier.DefaultUserAgent()
 	opts := notifierCfg.ReceiverOpts
 	if opts != nil && opts.Global != nil {
 		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+		dedupWindow = opts.Global.DedupWindow
+		if len(opts.Global.UserAgent) > 0 {
+			userAgent = opts.Global.UserAgent
+		}
 	}
-	tmpl, err := notifier.NewTemplate(path)
+	tmpl, err := notifier.NewTemplate(path, externalURL)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "WechatNotifier: get template error", "error", err.Error())
-		return nil
+		return nil, fmt.Errorf("get template error, %s", err.Error())
 	}
 
 	n := &Notifier{
-		notifierCfg:    notifierCfg,
-		wechat:         make(map[string]*config.Wechat),
-		logger:         logger,
-		timeout:        DefaultSendTimeout,
-		template:       tmpl,
-		templateName:   DefaultTemplate,
-		ats:            notifier.GetAccessTokenService(),
-		messageMaxSize: MessageMaxSize,
-		tokenExpires:   DefaultExpires,
+		notifierCfg:          notifierCfg,
+		wechat:               make(map[string]*config.Wechat),
+		names:                make(map[string][]string),
+		logger:               logger,
+		timeout:              DefaultSendTimeout,
+		template:             tmpl,
+		templateName:         DefaultTemplate,
+		decoration:           &notifier.Decoration{Header: header, Footer: footer},
+		markdownTemplateName: DefaultMarkdown,
+		newsTemplateName:     DefaultNews,
+		cardTemplateName:     DefaultCard,
+		ats:                  notifier.GetAccessTokenService(),
+		messageMaxSize:       MessageMaxSize,
+		tokenExpires:         DefaultExpires,
+		maxRetries:           DefaultMaxRetries,
+		backoff:              DefaultBackoff,
+		dedupWindow:          dedupWindow,
+		cooldown:             DefaultCooldown,
+		sendResolved:         true,
+		splitMode:            notifier.SplitModeSize,
+		userAgent:            userAgent,
+		doer:                 notifier.DefaultHTTPDoer,
 	}
 
 	if opts != nil && opts.Wechat != nil {
@@ -98,6 +218,26 @@ func NewWechatNotifier(logger log.Logger, receivers []config.Receiver, notifierC
 			n.templateName = opts.Global.Template
 		}
 
+		if len(opts.Wechat.Header) > 0 {
+			n.decoration.Header = opts.Wechat.Header
+		}
+
+		if len(opts.Wechat.Footer) > 0 {
+			n.decoration.Footer = opts.Wechat.Footer
+		}
+
+		if len(opts.Wechat.MarkdownTemplate) > 0 {
+			n.markdownTemplateName = opts.Wechat.MarkdownTemplate
+		}
+
+		if len(opts.Wechat.NewsTemplate) > 0 {
+			n.newsTemplateName = opts.Wechat.NewsTemplate
+		}
+
+		if len(opts.Wechat.TemplateCardTemplate) > 0 {
+			n.cardTemplateName = opts.Wechat.TemplateCardTemplate
+		}
+
 		if opts.Wechat.MessageMaxSize > 0 {
 			n.messageMaxSize = opts.Wechat.MessageMaxSize
 		}
@@ -105,6 +245,43 @@ func NewWechatNotifier(logger log.Logger, receivers []config.Receiver, notifierC
 		if opts.Wechat.TokenExpires != 0 {
 			n.tokenExpires = opts.Wechat.TokenExpires
 		}
+
+		if r := opts.Wechat.Retry; r != nil {
+			if r.MaxRetries > 0 {
+				n.maxRetries = r.MaxRetries
+			}
+
+			if r.Backoff > 0 {
+				n.backoff = r.Backoff
+			}
+		}
+
+		n.rateLimit = opts.Wechat.RateLimit
+
+		if opts.Wechat.MaxConcurrency > 0 {
+			n.maxConcurrency = opts.Wechat.MaxConcurrency
+		}
+
+		n.serialDelivery = opts.Wechat.SerialDelivery
+
+		if len(opts.Wechat.SplitMode) > 0 {
+			n.splitMode = opts.Wechat.SplitMode
+		}
+
+		n.groupBy = opts.Wechat.GroupBy
+
+		n.dryRun = opts.Wechat.DryRun
+
+		if opts.Wechat.SendResolved != nil {
+			n.sendResolved = *opts.Wechat.SendResolved
+		}
+
+		if cb := opts.Wechat.CircuitBreaker; cb != nil {
+			n.failureThreshold = cb.FailureThreshold
+			if cb.Cooldown > 0 {
+				n.cooldown = cb.Cooldown
+			}
+		}
 	}
 
 	for _, r := range receivers {
@@ -114,16 +291,57 @@ func NewWechatNotifier(logger log.Logger, receivers []config.Receiver, notifierC
 			continue
 		}
 
-		if receiver.WechatConfig == nil {
-			_ = level.Warn(logger).Log("msg", "WechatNotifier: ignore receiver because of empty config")
+		// The disabled receiver is skipped, so the token of it is not fetched.
+		if !receiver.Enabled() {
+			_ = level.Debug(logger).Log("msg", "WechatNotifier: ignore disabled receiver")
+			continue
+		}
+
+		// The api url can contain the environment variables in form of ${ENV_VAR}, they are expanded before validating.
+		// The receiver is shared by the notifications, so the urls are expanded in a copy of it.
+		if receiver.WechatConfig != nil {
+			receiver = receiver.Clone()
+			receiver.WechatConfig.APIURL = notifierCfg.ExpandEnv(receiver.GetNamespace(), receiver.WechatConfig.APIURL)
+			for i := range receiver.WechatConfig.APIURLs {
+				receiver.WechatConfig.APIURLs[i].URL = notifierCfg.ExpandEnv(receiver.GetNamespace(), receiver.WechatConfig.APIURLs[i].URL)
+			}
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "WechatNotifier: ignore invalid receiver", "error", err.Error())
 			continue
 		}
 
 		if len(receiver.WechatConfig.APIURL) == 0 {
 			receiver.WechatConfig.APIURL = DefaultApiURL
+			if len(receiver.WechatConfig.APIURLs) > 0 {
+				receiver.WechatConfig.APIURL = receiver.WechatConfig.APIURLs[0].URL
+			}
+		}
+
+		if receiver.DuplicateCheckInterval < 0 || receiver.DuplicateCheckInterval > DuplicateCheckIntervalMax {
+			_ = level.Warn(logger).Log("msg", "WechatNotifier: ignore invalid duplicate check interval, use the default", "interval", receiver.DuplicateCheckInterval)
+			receiver.DuplicateCheckInterval = 0
+		}
+
+		// The message is sent to the chat, the users, parties and tags are not needed.
+		if len(receiver.ChatID) > 0 {
+			c := receiver.Clone()
+			c.ToUser, c.ToParty, c.ToTag, c.ToTagTemplate = "", "", "", ""
+			key, err := notifier.Md5key(c)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "WechatNotifier: get notifier error", "error", err.Error())
+				continue
+			}
+
+			n.wechat[key] = c
+			n.names[key] = appendName(n.names[key], receiver.GetName())
+			continue
 		}
 
+		// The receivers which differ only in the recipients are merged, so the recipients are not part of the key.
 		c := receiver.Clone()
+		c.ToUser, c.ToParty, c.ToTag = "", "", ""
 		key, err := notifier.Md5key(c)
 		if err != nil {
 			_ = level.Error(logger).Log("msg", "WechatNotifier: get notifier error", "error", err.Error())
@@ -135,159 +353,1021 @@ func NewWechatNotifier(logger log.Logger, receivers []config.Receiver, notifierC
 			w = c
 		}
 
-		if len(receiver.ToUser) > 0 {
-			w.ToUser += "|" + receiver.ToUser
+		w.ToUser = mergeRecipients(w.ToUser, receiver.ToUser)
+		w.ToParty = mergeRecipients(w.ToParty, receiver.ToParty)
+		w.ToTag = mergeRecipients(w.ToTag, receiver.ToTag)
+
+		n.wechat[key] = w
+		n.names[key] = appendName(n.names[key], receiver.GetName())
+	}
+
+	return n, nil
+}
+
+func appendName(names []string, name string) []string {
+
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+
+	return append(names, name)
+}
+
+// Deferred returns the names of the receivers which all alerts were buffered or suppressed in the notification.
+func (n *Notifier) Deferred() []string {
+
+	n.deferredMutex.Lock()
+	defer n.deferredMutex.Unlock()
+
+	return n.deferred
+}
+
+// SetHTTPDoer sets the doer which sends the requests to WeChat, such as a stub returning canned responses.
+func (n *Notifier) SetHTTPDoer(doer notifier.HTTPDoer) {
+
+	if doer != nil {
+		n.doer = doer
+	}
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	// The logs of the sending are traceable by the fingerprints of alerts.
+	logger := log.With(n.logger, "receiver", data.Receiver, "fingerprints", notifier.Fingerprints(data))
+
+	if !n.sendResolved {
+		data = notifier.FilterResolved(data)
+		if len(data.Alerts) == 0 {
+			_ = level.Debug(logger).Log("msg", "WechatNotifier: no firing alert, skip sending")
+			return nil
+		}
+	}
+
+	// The alerts sent to each receiver now, the low priority alerts of the throttled receiver are buffered,
+	// and the low severity alerts are suppressed during the quiet hours.
+	targets := make(map[*config.Wechat]template.Data)
+	for key, w := range n.wechat {
+		d := data
+		if w.QuietHours != nil {
+			var suppressed template.Data
+			d, suppressed = notifier.SplitByQuietHours(d, w.QuietHours, time.Now())
+			if len(suppressed.Alerts) > 0 {
+				_ = level.Debug(logger).Log("msg", "WechatNotifier: suppress alerts during quiet hours", "alerts", len(suppressed.Alerts))
+				if w.Digest != nil {
+					n.digest(key, w, suppressed)
+				}
+			}
+
+			if len(d.Alerts) == 0 {
+				continue
+			}
 		}
-		w.ToUser = strings.TrimPrefix(w.ToUser, "|")
 
-		if len(receiver.ToTag) > 0 {
-			w.ToTag += "|" + receiver.ToTag
+		if w.Throttle != nil {
+			var low template.Data
+			d, low = notifier.SplitByPriority(d, w.Throttle)
+			n.throttle(key, w, low)
+			if len(d.Alerts) == 0 {
+				continue
+			}
 		}
-		w.ToTag = strings.TrimPrefix(w.ToTag, "|")
 
-		if len(receiver.ToParty) > 0 {
-			w.ToParty += "|" + receiver.ToParty
+		if w.CoalesceDelay > 0 {
+			n.coalesce(key, w, d)
+			continue
 		}
-		w.ToParty = strings.TrimPrefix(w.ToParty, "|")
 
-		n.wechat[key] = w
+		targets[w] = d
+	}
+
+	var deferred []string
+	for key, w := range n.wechat {
+		if _, ok := targets[w]; !ok {
+			deferred = append(deferred, n.names[key]...)
+		}
 	}
+	n.deferredMutex.Lock()
+	n.deferred = deferred
+	n.deferredMutex.Unlock()
 
-	return n
+	return n.notify(ctx, logger, targets)
 }
 
-func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+// Buffer the low priority alerts, they are sent to the receiver in one notification when the buffer is flushed.
+func (n *Notifier) throttle(key string, w *config.Wechat, data template.Data) {
+
+	notifier.GetPriorityThrottler().Add(notifierType+"/"+key, data, w.Throttle, func(d template.Data) {
+		n.sendBuffered(key, w, d, "buffered alerts", notifier.DefaultThrottleFlushTimeout)
+	})
+}
+
+// Buffer the alerts for the coalesce delay, the notifications received within the delay are sent in one message.
+func (n *Notifier) coalesce(key string, w *config.Wechat, data template.Data) {
+
+	throttle := &v1alpha1.PriorityThrottle{
+		Window:    w.CoalesceDelay,
+		MaxAlerts: w.CoalesceMaxAlerts,
+	}
+
+	notifier.GetPriorityThrottler().Add(notifierType+"/coalesce/"+key, data, throttle, func(d template.Data) {
+		n.sendBuffered(key, w, d, "coalesced alerts", notifier.DefaultThrottleFlushTimeout)
+	})
+}
+
+// Accumulate the suppressed alerts, they are sent to the receiver in one digest message on the schedule of the digest.
+func (n *Notifier) digest(key string, w *config.Wechat, data template.Data) {
+
+	notifier.GetDigester().Add(notifierType+"/"+key, data, w.Digest, func(d template.Data) {
+
+		// The digest is a text or markdown message generated by the digest template.
+		c := w.Clone()
+		c.Throttle, c.QuietHours, c.Digest = nil, nil, nil
+		c.Template = notifier.DefaultDigestTemplate
+		if len(w.Digest.Template) > 0 {
+			c.Template = w.Digest.Template
+		}
+		if c.MsgType != config.WechatMarkdown {
+			c.MsgType = config.WechatText
+		}
+
+		n.sendBuffered(key, c, d, "digest", notifier.DefaultDigestSendTimeout)
+	})
+}
+
+// Send the alerts flushed from a buffer to the receiver. The buffer is flushed by a timer rather than a notification,
+// so the pausing is checked here, and the alerts are dropped like the other notifications while paused.
+func (n *Notifier) sendBuffered(key string, w *config.Wechat, data template.Data, kind string, timeout time.Duration) {
+
+	logger := log.With(n.logger, "receiver", data.Receiver, "fingerprints", notifier.Fingerprints(data))
+	if notifier.Paused() {
+		_ = level.Info(logger).Log("msg", "WechatNotifier: notifications are paused, drop the "+kind, "alerts", len(data.Alerts))
+		return
+	}
+
+	_ = level.Debug(logger).Log("msg", "WechatNotifier: send "+kind, "alerts", len(data.Alerts))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// The buffered alerts are not sent through the notification, so the result is recorded here.
+	start := time.Now()
+	var result error
+	for _, err := range n.notify(ctx, logger, map[*config.Wechat]template.Data{w: data}) {
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "WechatNotifier: send "+kind+" error", "error", err.Error())
+			if result == nil {
+				result = err
+			}
+		}
+	}
+	// The result is recorded for the receivers merged into the receiver.
+	for _, name := range n.names[key] {
+		metrics.ObserveSend(notifierType, name, start, result)
+		notifier.GetStatusRegistry().Record(notifierType, name, start, result)
+	}
+}
+
+// Send the alerts to the receivers, the key of targets is the receiver and the value is the alerts it receives.
+func (n *Notifier) notify(ctx context.Context, logger log.Logger, targets map[*config.Wechat]template.Data) []error {
 
-	send := func(w *config.Wechat, msg string) error {
+	send := func(w *config.Wechat, msg *weChatMessage) (err error) {
 
 		start := time.Now()
 		defer func() {
-			_ = level.Debug(n.logger).Log("msg", "WechatNotifier: send message", "used", time.Since(start).String())
+			_ = level.Debug(logger).Log("msg", "WechatNotifier: send message", "used", time.Since(start).String())
 		}()
 
 		wechatMsg := &weChatMessage{
-			Text: weChatMessageContent{
-				Content: msg,
-			},
-			ToUser:  w.ToUser,
-			ToParty: w.ToParty,
-			Totag:   w.ToTag,
-			AgentID: w.WechatConfig.AgentID,
-			Type:    "text",
-			Safe:    "0",
+			Type:         w.MsgType,
+			Safe:         "0",
+			Text:         msg.Text,
+			Markdown:     msg.Markdown,
+			News:         msg.News,
+			TemplateCard: msg.TemplateCard,
+		}
+
+		// The message type differs from the receiver, such as the summary message.
+		if len(msg.Type) > 0 {
+			wechatMsg.Type = msg.Type
+		}
+
+		if w.Confidential {
+			wechatMsg.Safe = "1"
+		}
+
+		if w.EnableDuplicateCheck {
+			wechatMsg.EnableDuplicateCheck = 1
+			wechatMsg.DuplicateCheckInterval = w.DuplicateCheckInterval
+			if w.DuplicateCheckByFingerprint && len(msg.alertsHash) > 0 {
+				reuseContent(w, msg.alertsHash, wechatMsg)
+			}
+		}
+
+		// The message sent to the application chat does not need the agent id.
+		path := DefaultSendPath
+		if len(w.WechatConfig.SendPath) > 0 {
+			path = w.WechatConfig.SendPath
+		}
+		if len(w.ChatID) > 0 {
+			path = "appchat/send"
+			wechatMsg.ChatID = w.ChatID
+		} else {
+			wechatMsg.ToUser = w.ToUser
+			wechatMsg.ToParty = w.ToParty
+			wechatMsg.Totag = w.ToTag
+			wechatMsg.AgentID, err = n.notifierCfg.GetSecretValue(w.GetNamespace(), w.WechatConfig.AgentID)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "WechatNotifier: get agent id error", "error", err.Error())
+				return err
+			}
+		}
+
+		if n.dryRun {
+			bs, err := json.Marshal(wechatMsg)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "WechatNotifier: encode message error", "error", err.Error())
+				return err
+			}
+
+			u, _ := notifier.UrlWithPath(w.WechatConfig.APIURL, path)
+			_ = level.Info(logger).Log("msg", "WechatNotifier: dry run, the message is not sent", "url", u, "message", string(bs))
+			return nil
+		}
+
+		var key string
+		key, err = notifier.DedupKey(w, wechatMsg)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "WechatNotifier: get dedup key error", "error", err.Error())
+			return err
+		}
+
+		dedup := notifier.GetDeduplicator()
+		if !dedup.Allow(key, n.dedupWindow) {
+			_ = level.Debug(logger).Log("msg", "WechatNotifier: drop duplicate message", "toUser", w.ToUser, "toParty", w.ToParty, "toTag", w.ToTag, "chatID", w.ChatID)
+			return nil
+		}
+
+		// The message failed to send after retries is written to the dead letter sink, so that it can be replayed.
+		deadLetter := func(err error) error {
+			dedup.Forget(key)
+			se := notifier.NewSendError(notifierType, tokenKey(w), target(w), err)
+			if bs, e := json.Marshal(wechatMsg); e == nil {
+				notifier.WriteDeadLetter(logger, se, bs)
+			}
+			return se
 		}
 
+		breaker := notifier.GetCircuitBreaker()
+		if n.failureThreshold > 0 {
+			if !breaker.Allow(tokenKey(w), n.cooldown) {
+				_ = level.Debug(logger).Log("msg", "WechatNotifier: drop message because the circuit breaker is open", "key", tokenKey(w))
+				dedup.Forget(key)
+				return notifier.NewSendError(notifierType, tokenKey(w), target(w), notifier.ErrCircuitOpen)
+			}
+
+			defer func() {
+				// The message is delivered to the valid recipients, it is not a failure of the application.
+				// The message rejected by the local rate limiter or send budget is not sent, so it is not reported.
+				var pe *notifier.PartialError
+				if err == nil || errors.As(err, &pe) {
+					breaker.Success(tokenKey(w))
+				} else if !notifier.IsThrottled(err) && breaker.Failure(tokenKey(w), n.failureThreshold) {
+					_ = level.Error(logger).Log("msg", "WechatNotifier: circuit breaker is open, the sending will be rejected during the cooldown",
+						"key", tokenKey(w), "cooldown", n.cooldown.String())
+				}
+			}()
+		}
+
+		// The recipients which the message is not delivered to.
+		var partial *notifier.PartialError
+
+		timeout := n.timeoutOf(w)
+
+		// Send the message, the bool returned means whether the sending can be retried.
 		sendMessage := func() (bool, error) {
 
-			accessToken, err := n.getToken(ctx, w)
+			// The token fetching and the message sending have their own timeout,
+			// so that a hung request will not block the other sending until the context is done.
+			tokenCtx, cancel := context.WithTimeout(ctx, timeout)
+			accessToken, err := n.getToken(tokenCtx, w)
+			cancel()
 			if err != nil {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: get access token error", "error", err.Error())
-				return false, err
+				_ = level.Error(logger).Log("msg", "WechatNotifier: get access token error", "error", err.Error())
+				// The invalid credentials can not be fixed by retrying.
+				return notifier.IsRetryable(err), err
+			}
+
+			// The media message refers to the media uploaded.
+			if w.MsgType == config.WechatImage || w.MsgType == config.WechatFile {
+				mediaCtx, cancel := context.WithTimeout(ctx, timeout)
+				mediaID, err := n.getMedia(mediaCtx, w, accessToken)
+				cancel()
+				if err != nil {
+					_ = level.Error(logger).Log("msg", "WechatNotifier: upload media error", "error", err.Error())
+					return notifier.IsRetryable(err), err
+				}
+
+				if w.MsgType == config.WechatImage {
+					wechatMsg.Image = &weChatMedia{MediaID: mediaID}
+				} else {
+					wechatMsg.File = &weChatMedia{MediaID: mediaID}
+				}
 			}
 
 			var buf bytes.Buffer
 			if err := json.NewEncoder(&buf).Encode(wechatMsg); err != nil {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: encode message error", "error", err.Error())
+				_ = level.Error(logger).Log("msg", "WechatNotifier: encode message error", "error", err.Error())
 				return false, err
 			}
 
-			u, err := notifier.UrlWithPath(w.WechatConfig.APIURL, "message/send")
+			client, err := n.getClient(w)
 			if err != nil {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: set path error", "error", err)
+				_ = level.Error(logger).Log("msg", "WechatNotifier: get http client error", "error", err.Error())
 				return false, err
 			}
 
-			parameters := make(map[string]string)
-			parameters["access_token"] = accessToken
-			u, err = notifier.UrlWithParameters(u, parameters)
-			if err != nil {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: set parameters error", "error", err)
-				return false, err
+			if n.rateLimit != nil {
+				if err := notifier.GetRateLimiter().Wait(ctx, tokenKey(w), n.rateLimit.RequestsPerSecond, n.rateLimit.Burst); err != nil {
+					_ = level.Error(logger).Log("msg", "WechatNotifier: wait for rate limit error", "error", err.Error())
+					return false, err
+				}
 			}
 
-			request, err := http.NewRequest(http.MethodPost, u, &buf)
-			if err != nil {
+			if err := notifier.GetSendBudget().Acquire(ctx, logger); err != nil {
 				return false, err
 			}
-			request.Header.Set("Content-Type", "application/json")
 
-			body, err := notifier.DoHttpRequest(ctx, nil, request)
+			sendCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			body, err := n.post(sendCtx, logger, w, client, path, accessToken, buf.Bytes())
 			if err != nil {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: do http error", "error", err)
-				return false, err
+				// Only the network errors, the server errors and the rate limit are retried.
+				return notifier.IsRetryable(err), err
 			}
 
 			var weResp weChatResponse
 			if err := json.Unmarshal(body, &weResp); err != nil {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: decode response body error", "error", err)
+				_ = level.Error(logger).Log("msg", "WechatNotifier: decode response body error", "error", err)
 				return false, err
 			}
 
-			if weResp.Code == 0 {
-				_ = level.Debug(n.logger).Log("msg", "WechatNotifier: send message", "from", w.WechatConfig.AgentID, "toUser", w.ToUser, "toParty", w.ToParty, "toTag", w.ToTag)
+			if isSuccess(weResp.Code, w.WechatConfig.SuccessCodes) {
+				if len(weResp.InvalidUser) > 0 || len(weResp.InvalidParty) > 0 || len(weResp.InvalidTag) > 0 {
+					_ = level.Warn(logger).Log("msg", "WechatNotifier: message is not sent to the invalid recipients", "from", w.WechatConfig.AgentID,
+						"invalidUser", weResp.InvalidUser, "invalidParty", weResp.InvalidParty, "invalidTag", weResp.InvalidTag)
+					total := len(splitRecipients(w.ToUser)) + len(splitRecipients(w.ToParty)) + len(splitRecipients(w.ToTag))
+					invalid := len(splitRecipients(weResp.InvalidUser)) + len(splitRecipients(weResp.InvalidParty)) + len(splitRecipients(weResp.InvalidTag))
+					partial = notifier.NewPartialError(total-invalid, total,
+						fmt.Sprintf("user: %s, party: %s, tag: %s", weResp.InvalidUser, weResp.InvalidParty, weResp.InvalidTag))
+				}
+				_ = level.Debug(logger).Log("msg", "WechatNotifier: send message", "from", w.WechatConfig.AgentID, "toUser", w.ToUser, "toParty", w.ToParty, "toTag", w.ToTag, "chatID", w.ChatID, "msgID", weResp.MsgID)
 				return false, nil
 			}
 
+			err = fmt.Errorf("wechat response error, errcode: %d, errmsg: %s", weResp.Code, weResp.Error)
+
 			// AccessToken is expired
 			if weResp.Code == AccessTokenInvalid {
-				_ = level.Error(n.logger).Log("msg", "WechatNotifier: token expired", "error", err)
-				go n.invalidToken(ctx, w)
-				return true, fmt.Errorf("%s", weResp.Error)
+				_ = level.Error(logger).Log("msg", "WechatNotifier: token expired", "error", err)
+				n.invalidToken(sendCtx, w)
+				return true, err
 			}
 
-			_ = level.Error(n.logger).Log("msg", "WechatNotifier: wechat response error", "error", weResp.Code, "message", weResp.Error)
-			return false, nil
+			_ = level.Error(logger).Log("msg", "WechatNotifier: wechat response error", "error", weResp.Code, "message", weResp.Error)
+			return weResp.Code == SystemBusy, err
 		}
 
-		retry, err := sendMessage()
-		if retry {
-			_, err = sendMessage()
+		notifier.GetRetryBudget().Request()
+		for attempt := 0; attempt <= n.maxRetries; attempt++ {
+			if attempt > 0 {
+				// The failure is returned immediately if the retry budget shared by all notifiers is exhausted.
+				if !notifier.GetRetryBudget().AllowRetry() {
+					_ = level.Warn(logger).Log("msg", "WechatNotifier: retry budget exhausted, stop retrying", "attempt", attempt)
+					return deadLetter(err)
+				}
+				metrics.ObserveRetry(notifierType)
+				wait := notifier.Backoff(n.backoff, attempt)
+				// Respect the waiting time required by the server if the request is rate limited.
+				if d := notifier.RetryAfter(err); d > 0 {
+					wait = d
+				}
+				_ = level.Debug(logger).Log("msg", "WechatNotifier: retry to send message", "attempt", attempt, "wait", wait.String())
+				if e := notifier.Sleep(ctx, wait); e != nil {
+					_ = level.Error(logger).Log("msg", "WechatNotifier: stop retrying", "error", e.Error())
+					return deadLetter(err)
+				}
+			}
+
+			var retry bool
+			retry, err = sendMessage()
+			if err == nil || !retry {
+				break
+			}
 		}
 
-		return err
-	}
+		// The message rejected locally is not written to the dead letter sink, it is not a failure of sending.
+		if notifier.IsThrottled(err) {
+			dedup.Forget(key)
+			return notifier.NewSendError(notifierType, tokenKey(w), target(w), err)
+		}
+
+		if err != nil {
+			return deadLetter(err)
+		}
+
+		if partial != nil {
+			return notifier.NewSendError(notifierType, tokenKey(w), target(w), partial)
+		}
 
-	messages, err := n.template.Split(data, MessageMaxSize, n.templateName, n.logger)
-	if err != nil {
-		_ = level.Error(n.logger).Log("msg", "WechatNotifier: split message error", "error", err.Error())
 		return nil
 	}
 
-	group := async.NewGroup(ctx)
-	for _, w := range n.wechat {
+	// Messages of each alerts, message type and mentions, the key of the receiver's messages is saved in keys.
+	messages := make(map[string][]*weChatMessage)
+	keys := make(map[*config.Wechat]string)
+	var receivers []*config.Wechat
+	for wechat, data := range targets {
+		routes := make(map[*config.Wechat]template.Data)
+		for rw, rd := range n.route(wechat, data) {
+			tags, err := n.routeByTag(rw, rd)
+			if err != nil {
+				return []error{err}
+			}
+
+			for w, d := range tags {
+				routes[w] = d
+			}
+		}
+
+		for w, d := range routes {
+
+			// The alerts routed to the receiver are identified by their fingerprints.
+			alertsKey := notifier.Fingerprints(d)
+
+			receivers = append(receivers, w)
+			key := alertsKey + w.MsgType + w.Template + w.TitleAnnotation + strings.Join(w.MentionedUsers, ",") +
+				fmt.Sprintf("%d/%d/%t", w.MaxAlertsPerMessage, w.SummaryThreshold, w.EnableDuplicateCheck && w.DuplicateCheckByFingerprint)
+			keys[w] = key
+			if _, ok := messages[key]; ok {
+				continue
+			}
+
+			var msgs []*weChatMessage
+			if w.MsgType == config.WechatImage || w.MsgType == config.WechatFile {
+				// The media is uploaded when sending, because the media id belongs to the application.
+				msgs = []*weChatMessage{{}}
+			} else {
+				// Each group of alerts is generated into its own messages.
+				for _, gd := range notifier.GroupAlerts(d, n.groupBy) {
+					ms, err := n.groupMessages(w, gd)
+					if err != nil {
+						return []error{err}
+					}
+
+					if w.EnableDuplicateCheck && w.DuplicateCheckByFingerprint {
+						hash, err := alertsHash(gd)
+						if err != nil {
+							_ = level.Error(logger).Log("msg", "WechatNotifier: get alerts hash error", "error", err.Error())
+							return []error{err}
+						}
+						for i, m := range ms {
+							m.alertsHash = fmt.Sprintf("%s/%d", hash, i)
+						}
+					}
+					msgs = append(msgs, ms...)
+				}
+			}
+
+			messages[key] = msgs
+		}
+	}
+
+	group := async.NewGroupWithLimit(ctx, n.maxConcurrency)
+	for _, w := range receivers {
+
+		// The deliveries of the receiver, each of them sends a message to a batch of recipients.
+		var deliveries []func() error
+
+		// The chat is a group, it does not need to be sent in batches.
+		if len(w.ChatID) > 0 {
+			for _, m := range messages[keys[w]] {
+				cw, msg := w, m
+				deliveries = append(deliveries, func() error {
+					return send(cw, msg)
+				})
+			}
+			n.deliver(group, deliveries)
+			continue
+		}
 
 		us, ps, ts := 0, 0, 0
-		toUser := strings.Split(w.ToUser, "|")
-		toParty := strings.Split(w.ToParty, "|")
-		toTag := strings.Split(w.ToTag, "|")
+		toUser := splitRecipients(w.ToUser)
+		toParty := splitRecipients(w.ToParty)
+		toTag := splitRecipients(w.ToTag)
 
-		nw := w.Clone()
 		for {
 			if us >= len(toUser) && ps >= len(toParty) && ts >= len(toTag) {
 				break
 			}
 
+			// Each batch uses its own receiver, because the receiver is used by the sending goroutines.
+			nw := w.Clone()
 			nw.ToUser = batch(toUser, &us, ToUserBatchSize)
 			nw.ToParty = batch(toParty, &ps, ToPartyBatchSize)
 			nw.ToTag = batch(toTag, &ts, ToTagBatchSize)
 
-			for _, m := range messages {
+			// Skip the batch without any recipient.
+			if len(nw.ToUser) == 0 && len(nw.ToParty) == 0 && len(nw.ToTag) == 0 {
+				continue
+			}
+
+			for _, m := range messages[keys[w]] {
 				msg := m
-				group.Add(func(stopCh chan interface{}) {
-					stopCh <- send(nw, msg)
+				deliveries = append(deliveries, func() error {
+					return send(nw, msg)
 				})
 			}
 		}
+
+		n.deliver(group, deliveries)
 	}
 
 	return group.Wait()
 }
 
+// Add the deliveries of a receiver to the group. They are sent one by one in order if the serial delivery is enabled,
+// and the failure of a delivery does not stop the following ones, otherwise they are sent concurrently.
+func (n *Notifier) deliver(group *async.Group, deliveries []func() error) {
+
+	if !n.serialDelivery {
+		for _, delivery := range deliveries {
+			d := delivery
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- d()
+			})
+		}
+		return
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	group.Add(func(stopCh chan interface{}) {
+		var errs []error
+		for _, d := range deliveries {
+			if err := d(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		stopCh <- errs
+	})
+}
+
+// Route the alerts by the severity, the alerts not matching any route are sent to the receiver itself.
+// It returns the receivers and the alerts they should receive.
+func (n *Notifier) route(w *config.Wechat, data template.Data) map[*config.Wechat]template.Data {
+
+	if len(w.SeverityRouting) == 0 {
+		return map[*config.Wechat]template.Data{w: data}
+	}
+
+	res := make(map[*config.Wechat]template.Data)
+	routes := make(map[string]*config.Wechat)
+	for _, alert := range data.Alerts {
+
+		rw := w
+		severity := alert.Labels[SeverityLabel]
+		if route, ok := w.SeverityRouting[severity]; ok {
+			if routes[severity] == nil {
+				routes[severity] = routeReceiver(w, route)
+			}
+			rw = routes[severity]
+		}
+
+		d, ok := res[rw]
+		if !ok {
+			d = template.Data{
+				Receiver:          data.Receiver,
+				Status:            data.Status,
+				GroupLabels:       data.GroupLabels,
+				CommonLabels:      data.CommonLabels,
+				CommonAnnotations: data.CommonAnnotations,
+				ExternalURL:       data.ExternalURL,
+			}
+		}
+		d.Alerts = append(d.Alerts, alert)
+		res[rw] = d
+	}
+
+	return res
+}
+
+// Route the alerts to the tags generated by the totag template, the template is rendered with each alert, and the
+// alert is sent to the tags of the receiver if the result is empty. It returns the receivers and the alerts they should receive.
+func (n *Notifier) routeByTag(w *config.Wechat, data template.Data) (map[*config.Wechat]template.Data, error) {
+
+	if len(w.ToTagTemplate) == 0 || len(w.ChatID) > 0 {
+		return map[*config.Wechat]template.Data{w: data}, nil
+	}
+
+	res := make(map[*config.Wechat]template.Data)
+	receivers := make(map[string]*config.Wechat)
+	for _, alert := range data.Alerts {
+		ad := data
+		ad.Alerts = template.Alerts{alert}
+		tag, err := n.template.Text(w.ToTagTemplate, ad, n.logger)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "WechatNotifier: generate totag error", "error", err.Error())
+			return nil, err
+		}
+
+		tag = strings.Join(splitRecipients(tag), "|")
+		if len(tag) == 0 {
+			tag = w.ToTag
+		}
+
+		rw, ok := receivers[tag]
+		if !ok {
+			rw = w.Clone()
+			rw.ToTag = tag
+			rw.ToTagTemplate = ""
+			receivers[tag] = rw
+		}
+
+		d, ok := res[rw]
+		if !ok {
+			d = template.Data{
+				Receiver:          data.Receiver,
+				Status:            data.Status,
+				GroupLabels:       data.GroupLabels,
+				CommonLabels:      data.CommonLabels,
+				CommonAnnotations: data.CommonAnnotations,
+				ExternalURL:       data.ExternalURL,
+			}
+		}
+		d.Alerts = append(d.Alerts, alert)
+		res[rw] = d
+	}
+
+	return res, nil
+}
+
+// Generate the receiver of the route.
+func routeReceiver(w *config.Wechat, route v1alpha1.WechatRoute) *config.Wechat {
+
+	c := w.Clone()
+	c.SeverityRouting = nil
+
+	if len(route.AgentID) > 0 {
+		c.WechatConfig.AgentID = route.AgentID
+	}
+
+	if route.APISecret != nil {
+		c.WechatConfig.APISecret = route.APISecret
+	}
+
+	if len(route.ToUser) > 0 || len(route.ToParty) > 0 || len(route.ToTag) > 0 || len(route.ChatID) > 0 {
+		c.ToUser = route.ToUser
+		c.ToParty = route.ToParty
+		c.ToTag = route.ToTag
+		c.ChatID = route.ChatID
+	}
+
+	return c
+}
+
+// Generate the messages of a group of alerts. A summary message is generated rather than the alerts if the number
+// of alerts exceeds the summary threshold, otherwise each message contains at most MaxAlertsPerMessage alerts.
+func (n *Notifier) groupMessages(w *config.Wechat, data template.Data) ([]*weChatMessage, error) {
+
+	if w.SummaryThreshold > 0 && len(data.Alerts) > w.SummaryThreshold {
+		// The summary is a text or markdown message.
+		msgType := config.WechatText
+		if w.MsgType == config.WechatMarkdown {
+			msgType = config.WechatMarkdown
+		}
+
+		msgs, err := n.textMessages(data, msgType, notifier.DefaultSummaryTemplate, "")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range msgs {
+			m.Type = msgType
+		}
+		return msgs, nil
+	}
+
+	var msgs []*weChatMessage
+	for _, d := range notifier.ChunkAlerts(data, w.MaxAlertsPerMessage) {
+		ms, err := n.messages(w, d)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, ms...)
+	}
+
+	return msgs, nil
+}
+
+// Generate the messages of the alerts according to the message type of the receiver.
+func (n *Notifier) messages(w *config.Wechat, data template.Data) ([]*weChatMessage, error) {
+
+	switch w.MsgType {
+	case config.WechatNews:
+		return n.newsMessages(data, n.templateOf(w))
+	case config.WechatTemplateCard:
+		return n.cardMessages(data, n.templateOf(w))
+	default:
+		mention, err := n.mention(w, data)
+		if err != nil {
+			return nil, err
+		}
+		return n.textMessages(data, w.MsgType, n.templateOf(w), title(w, data)+mention)
+	}
+}
+
+// Post the message to the WeChat API. If the relays are set, the message is sent through them in the order
+// chosen by the balancer, and the next relay is tried if the request fails. The relays whose circuit breaker is open are skipped.
+func (n *Notifier) post(ctx context.Context, logger log.Logger, w *config.Wechat, client *http.Client, path, accessToken string, payload []byte) ([]byte, error) {
+
+	apiURLs := []string{w.WechatConfig.APIURL}
+	if len(w.WechatConfig.APIURLs) > 0 {
+		var urls []string
+		var weights []int
+		for _, r := range w.WechatConfig.APIURLs {
+			urls = append(urls, r.URL)
+			weights = append(weights, r.Weight)
+		}
+		apiURLs = notifier.GetBalancer().Order(tokenKey(w), urls, weights)
+	}
+
+	relay := len(w.WechatConfig.APIURLs) > 0
+	breaker := notifier.GetCircuitBreaker()
+
+	send := func(apiURL string) ([]byte, error) {
+
+		u, err := notifier.UrlWithPath(apiURL, path)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "WechatNotifier: set path error", "error", err)
+			return nil, err
+		}
+
+		parameters := make(map[string]string)
+		parameters["access_token"] = accessToken
+		u, err = notifier.UrlWithParameters(u, parameters)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "WechatNotifier: set parameters error", "error", err)
+			return nil, err
+		}
+
+		// The official WeChat API does not accept the compressed body.
+		content := payload
+		compress := w.WechatConfig.Compress && !isOfficialAPI(apiURL)
+		if compress {
+			content, err = notifier.Gzip(payload)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "WechatNotifier: compress message error", "error", err)
+				return nil, err
+			}
+		}
+
+		request, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("User-Agent", n.userAgent)
+		if compress {
+			request.Header.Set("Content-Encoding", "gzip")
+		}
+
+		body, err := n.doer.Do(ctx, client, request)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "WechatNotifier: do http error", "error", err)
+			return nil, err
+		}
+
+		return body, nil
+	}
+
+	err := notifier.ErrCircuitOpen
+	for _, apiURL := range apiURLs {
+		if relay && n.failureThreshold > 0 && !breaker.Allow(apiURL, n.cooldown) {
+			_ = level.Debug(logger).Log("msg", "WechatNotifier: skip the relay because the circuit breaker is open", "relay", apiURL)
+			continue
+		}
+
+		// The result of every relay allowed is reported to the circuit breaker, otherwise the probe of the
+		// half open circuit will never finish.
+		var body []byte
+		body, err = send(apiURL)
+		if err == nil {
+			if relay {
+				breaker.Success(apiURL)
+			}
+			return body, nil
+		}
+
+		if !relay {
+			break
+		}
+
+		if n.failureThreshold > 0 && breaker.Failure(apiURL, n.failureThreshold) {
+			_ = level.Error(logger).Log("msg", "WechatNotifier: circuit breaker of the relay is open", "relay", apiURL, "cooldown", n.cooldown.String())
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+		_ = level.Debug(logger).Log("msg", "WechatNotifier: try the next relay", "relay", apiURL)
+	}
+
+	return nil, err
+}
+
+// Check whether the API URL is the official WeChat API rather than a relay.
+func isOfficialAPI(apiURL string) bool {
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return false
+	}
+
+	official, _ := url.Parse(DefaultApiURL)
+	return strings.EqualFold(u.Hostname(), official.Hostname())
+}
+
+// Get the timeout of each request sent to the receiver, the timeout of the receiver takes precedence over the timeout of the notifier.
+func (n *Notifier) timeoutOf(w *config.Wechat) time.Duration {
+
+	if w.Timeout > 0 {
+		return w.Timeout
+	}
+
+	return n.timeout
+}
+
+// Get the name of template used to generate the message of the receiver,
+// the template of the receiver takes precedence over the template of the message type.
+func (n *Notifier) templateOf(w *config.Wechat) string {
+
+	if len(w.Template) > 0 {
+		return w.Template
+	}
+
+	switch w.MsgType {
+	case config.WechatMarkdown:
+		return n.markdownTemplateName
+	case config.WechatNews:
+		return n.newsTemplateName
+	case config.WechatTemplateCard:
+		return n.cardTemplateName
+	default:
+		return n.templateName
+	}
+}
+
+// Generate the title of the text or markdown message from the title annotation of the receiver,
+// the alertname is used if the alerts do not have the annotation.
+func title(w *config.Wechat, data template.Data) string {
+
+	if len(w.TitleAnnotation) == 0 {
+		return ""
+	}
+
+	t := data.CommonAnnotations[w.TitleAnnotation]
+	for i := 0; len(t) == 0 && i < len(data.Alerts); i++ {
+		t = data.Alerts[i].Annotations[w.TitleAnnotation]
+	}
+
+	if len(t) == 0 {
+		t = data.CommonLabels["alertname"]
+	}
+	for i := 0; len(t) == 0 && i < len(data.Alerts); i++ {
+		t = data.Alerts[i].Labels["alertname"]
+	}
+
+	// The title must be a single line.
+	t = strings.TrimSpace(strings.ReplaceAll(t, "\n", " "))
+	if len(t) == 0 {
+		return ""
+	}
+
+	if w.MsgType == config.WechatMarkdown {
+		return fmt.Sprintf("**%s**\n", t)
+	}
+
+	return t + "\n"
+}
+
+// Generate the mentions of the markdown message, such as `<@user1><@user2>`.
+func (n *Notifier) mention(w *config.Wechat, data template.Data) (string, error) {
+
+	if w.MsgType != config.WechatMarkdown || len(w.MentionedUsers) == 0 {
+		return "", nil
+	}
+
+	users, err := n.template.RenderList(w.MentionedUsers, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: generate mentioned users error", "error", err.Error())
+		return "", err
+	}
+
+	mention := ""
+	for _, u := range users {
+		mention += fmt.Sprintf("<@%s>", u)
+	}
+
+	if len(mention) > 0 {
+		mention += "\n"
+	}
+
+	return mention, nil
+}
+
+// Generate the text or markdown messages, the alerts will be split into multiple messages
+// if the message size is greater than the limit. The prefix, such as the title and mention, will be added at the beginning of each message.
+func (n *Notifier) textMessages(data template.Data, msgType, templateName, prefix string) ([]*weChatMessage, error) {
+
+	maxSize := n.messageMaxSize
+	if maxSize <= 0 {
+		maxSize = MessageMaxSize
+	}
+
+	msgs, err := n.template.SplitMessages(data, maxSize-notifier.Len(prefix), templateName, n.splitMode, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: split message error", "error", err.Error())
+		return nil, err
+	}
+
+	var messages []*weChatMessage
+	for _, msg := range msgs {
+		content := &weChatMessageContent{
+			Content: prefix + msg,
+		}
+
+		if msgType == config.WechatMarkdown {
+			messages = append(messages, &weChatMessage{Markdown: content})
+		} else {
+			messages = append(messages, &weChatMessage{Text: content})
+		}
+	}
+
+	return messages, nil
+}
+
+// Generate the news messages, the articles will be split into multiple messages
+// if the number of articles is greater than the limit.
+func (n *Notifier) newsMessages(data template.Data, templateName string) ([]*weChatMessage, error) {
+
+	msg, err := n.template.TempleText(templateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: generate news message error", "error", err.Error())
+		return nil, err
+	}
+
+	var articles []*weChatArticle
+	if err := json.Unmarshal([]byte(msg), &articles); err != nil {
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: decode news articles error", "error", err.Error())
+		return nil, err
+	}
+
+	if len(articles) == 0 {
+		err := fmt.Errorf("no article generated by the news template")
+		_ = level.Error(n.logger).Log("msg", "WechatNotifier: generate news message error", "error", err.Error())
+		return nil, err
+	}
+
+	var messages []*weChatMessage
+	for i := 0; i < len(articles); i += ArticlesMaxSize {
+		end := i + ArticlesMaxSize
+		if end > len(articles) {
+			end = len(articles)
+		}
+
+		messages = append(messages, &weChatMessage{
+			News: &weChatNews{
+				Articles: articles[i:end],
+			},
+		})
+	}
+
+	return messages, nil
+}
+
 func (n *Notifier) getToken(ctx context.Context, w *config.Wechat) (string, error) {
 
 	get := func(ctx context.Context) (string, time.Duration, error) {
-		u := w.WechatConfig.APIURL
-		u, err := notifier.UrlWithPath(u, "gettoken")
+		path := DefaultTokenPath
+		if len(w.WechatConfig.TokenPath) > 0 {
+			path = w.WechatConfig.TokenPath
+		}
+
+		u, err := notifier.UrlWithPath(w.WechatConfig.APIURL, path)
 		if err != nil {
 			return "", 0, err
 		}
@@ -297,9 +1377,14 @@ func (n *Notifier) getToken(ctx context.Context, w *config.Wechat) (string, erro
 			return "", 0, err
 		}
 
+		corpID, err := n.notifierCfg.GetSecretValue(w.GetNamespace(), w.WechatConfig.CorpID)
+		if err != nil {
+			return "", 0, err
+		}
+
 		parameters := make(map[string]string)
 		parameters["corpsecret"] = apiSecret
-		parameters["corpid"] = w.WechatConfig.CorpID
+		parameters["corpid"] = corpID
 		u, err = notifier.UrlWithParameters(u, parameters)
 		if err != nil {
 			return "", 0, err
@@ -311,8 +1396,14 @@ func (n *Notifier) getToken(ctx context.Context, w *config.Wechat) (string, erro
 			return "", 0, err
 		}
 		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("User-Agent", n.userAgent)
 
-		body, err := notifier.DoHttpRequest(ctx, nil, request)
+		client, err := n.getClient(w)
+		if err != nil {
+			return "", 0, err
+		}
+
+		body, err := n.doer.Do(ctx, client, request)
 		if err != nil {
 			return "", 0, err
 		}
@@ -323,20 +1414,119 @@ func (n *Notifier) getToken(ctx context.Context, w *config.Wechat) (string, erro
 			return "", 0, err
 		}
 
-		_ = level.Debug(n.logger).Log("msg", "WechatNotifier: get token", "key", w.WechatConfig.CorpID+" | "+w.WechatConfig.AgentID)
-		return resp.AccessToken, DefaultExpires, nil
+		if resp.Code != 0 {
+			err = fmt.Errorf("get token error, errcode: %d, errmsg: %s", resp.Code, resp.Error)
+			// The other errors, such as the invalid secret (40001) and the invalid corp id (40013), can not be fixed by retrying.
+			if resp.Code == SystemBusy {
+				return "", 0, notifier.NewRetryableError(err)
+			}
+			return "", 0, err
+		}
+
+		expires := n.tokenExpires
+		if resp.ExpiresIn > 0 {
+			expires = time.Duration(resp.ExpiresIn) * time.Second
+			if expires > ExpiresMargin {
+				expires = expires - ExpiresMargin
+			}
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "WechatNotifier: get token", "key", tokenKey(w), "expires", expires.String())
+		return resp.AccessToken, expires, nil
 	}
 
-	return n.ats.GetToken(ctx, w.WechatConfig.CorpID+" | "+w.WechatConfig.AgentID, get)
+	return n.ats.GetToken(ctx, tokenKey(w), get)
 }
 
 func (n *Notifier) invalidToken(ctx context.Context, w *config.Wechat) {
-	key := w.WechatConfig.CorpID + " | " + w.WechatConfig.AgentID
-	n.ats.InvalidToken(ctx, key, n.logger)
+	n.ats.InvalidToken(ctx, tokenKey(w), n.logger)
 }
 
+// Get the http client used to access the WeChat API, nil means using the default client.
+func (n *Notifier) getClient(w *config.Wechat) (*http.Client, error) {
+
+	c := w.WechatConfig
+	if len(c.ProxyURL) == 0 && c.TLSConfig == nil {
+		return nil, nil
+	}
+
+	getSecret := func(selector *v1.SecretKeySelector) (string, error) {
+		return n.notifierCfg.GetSecretData(w.GetNamespace(), selector)
+	}
+
+	key, err := notifier.TransportKey(notifierType, c.ProxyURL, c.ProxyAuth, c.TLSConfig, getSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transport is cached, so the connections are reused by the following sends.
+	transport, err := notifier.CachedTransport(key, func() (*http.Transport, error) {
+
+		transport := notifier.PooledTransport()
+
+		if len(c.ProxyURL) > 0 {
+			proxy, err := notifier.ProxyFunc(c.ProxyURL, c.ProxyAuth, getSecret)
+			if err != nil {
+				return nil, err
+			}
+
+			transport.Proxy = proxy
+		}
+
+		if c.TLSConfig != nil {
+			tlsConfig, err := notifier.NewTLSConfig(c.TLSConfig, getSecret)
+			if err != nil {
+				return nil, err
+			}
+
+			transport.TLSClientConfig = tlsConfig
+		}
+
+		return transport, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: transport,
+	}, nil
+}
+
+// The key of the access token, it is also used to identify the wechat app in metrics.
+func tokenKey(w *config.Wechat) string {
+	return w.WechatConfig.CorpID + " | " + w.WechatConfig.AgentID
+}
+
+// Whether the errcode means the sending succeeded, the errcode 0 means success if the success codes are not set.
+func isSuccess(code int, successCodes []int) bool {
+
+	if len(successCodes) == 0 {
+		return code == 0
+	}
+
+	for _, c := range successCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The target of the message, it is used to identify the recipients in the error.
+func target(w *config.Wechat) string {
+
+	if len(w.ChatID) > 0 {
+		return "chatID: " + w.ChatID
+	}
+
+	return fmt.Sprintf("toUser: %s, toParty: %s, toTag: %s", w.ToUser, w.ToParty, w.ToTag)
+}
+
+// Get the next batch of the recipients, an empty string will be returned once the recipients are exhausted.
 func batch(src []string, index *int, size int) string {
-	if *index > len(src) {
+	if *index >= len(src) {
 		return ""
 	}
 
@@ -349,10 +1539,34 @@ func batch(src []string, index *int, size int) string {
 
 	*index += size
 
-	to := ""
-	for _, t := range sub {
-		to += fmt.Sprintf("%s|", t)
+	return strings.Join(sub, "|")
+}
+
+// Merge two lists of the recipients joined by `|` into one list, the duplicate and empty recipients are dropped,
+// and the order of the recipients is kept.
+func mergeRecipients(a, b string) string {
+
+	var recipients []string
+	seen := make(map[string]bool)
+	for _, r := range append(splitRecipients(a), splitRecipients(b)...) {
+		if !seen[r] {
+			seen[r] = true
+			recipients = append(recipients, r)
+		}
+	}
+
+	return strings.Join(recipients, "|")
+}
+
+// Split the recipients joined by `|`, the empty recipients are dropped.
+func splitRecipients(s string) []string {
+
+	var recipients []string
+	for _, r := range strings.Split(s, "|") {
+		if r = strings.TrimSpace(r); len(r) > 0 {
+			recipients = append(recipients, r)
+		}
 	}
 
-	return to
+	return recipients
 }
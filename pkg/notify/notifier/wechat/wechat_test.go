@@ -0,0 +1,3553 @@
+package wechat
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/metrics"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"io"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	stdlog "log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testNamespace = testutil.Namespace
+	testToken     = "test-token"
+)
+
+func TestMain(m *testing.M) {
+
+	// The environment variables are expanded in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	_ = os.Setenv("WECHAT_SECRET", "secret")
+	os.Exit(m.Run())
+}
+
+// The secrets used by the tests, the api secret of the receivers and the file sent are created at the beginning.
+var secrets = testutil.NewSecretCache(&v1.Secret{
+	ObjectMeta: metav1.ObjectMeta{Name: "wechat", Namespace: testNamespace},
+	Data:       map[string][]byte{"secret": []byte("secret"), "app.log": []byte("log")},
+})
+
+// A stub of the WeChat API, it issues the test token and records the messages sent.
+type wechatServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	tokens int
+	// The parameters of each token request.
+	tokenQueries []url.Values
+	messages     []weChatMessage
+	// The time each message is received.
+	sentAt []time.Time
+	// The User-Agent of each request, the key is the path.
+	userAgents map[string][]string
+	// Handle the sending, it responds success if it is not set.
+	send func(w http.ResponseWriter, r *http.Request, msg weChatMessage)
+	// The other APIs can be added to the mux.
+	mux *http.ServeMux
+}
+
+func newWechatServer(t *testing.T, send func(w http.ResponseWriter, r *http.Request, msg weChatMessage)) *wechatServer {
+	s := newUnstartedWechatServer(t, send)
+	s.Start()
+	return s
+}
+
+func newWechatTLSServer(t *testing.T, send func(w http.ResponseWriter, r *http.Request, msg weChatMessage)) *wechatServer {
+	s := newUnstartedWechatServer(t, send)
+	// The handshake errors of the clients which do not trust the server are expected.
+	s.Config.ErrorLog = stdlog.New(ioutil.Discard, "", 0)
+	s.StartTLS()
+	return s
+}
+
+func newUnstartedWechatServer(t *testing.T, send func(w http.ResponseWriter, r *http.Request, msg weChatMessage)) *wechatServer {
+
+	mux := http.NewServeMux()
+	s := &wechatServer{send: send, mux: mux, userAgents: make(map[string][]string)}
+	mux.HandleFunc("/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.tokens++
+		s.tokenQueries = append(s.tokenQueries, r.URL.Query())
+		s.userAgents[r.URL.Path] = append(s.userAgents[r.URL.Path], r.UserAgent())
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"` + testToken + `","expires_in":7200}`))
+	})
+	mux.HandleFunc("/message/send", func(w http.ResponseWriter, r *http.Request) {
+		// The relays may accept the compressed body.
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("read compressed body error, %s", err)
+				return
+			}
+			body = zr
+		}
+
+		var msg weChatMessage
+		if err := json.NewDecoder(body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.sentAt = append(s.sentAt, time.Now())
+		s.userAgents[r.URL.Path] = append(s.userAgents[r.URL.Path], r.UserAgent())
+		s.mu.Unlock()
+
+		if s.send != nil {
+			s.send(w, r, msg)
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s.Server = httptest.NewUnstartedServer(mux)
+
+	return s
+}
+
+func (s *wechatServer) sent() []weChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]weChatMessage(nil), s.messages...)
+}
+
+// The sequence of the receivers created, it makes the corp id unique even if the tests run several times.
+var receiverSeq int32
+
+// Create a receiver sending to the stub, the corp id identifies the access token, the rate limiter and the circuit breaker,
+// so that each receiver uses its own.
+func newReceiver(apiURL, corpID string) *config.Wechat {
+
+	w := config.NewWechatReceiver().(*config.Wechat)
+	w.SetNamespace(testNamespace)
+	w.ToUser = "user1"
+	w.MsgType = config.WechatText
+	w.WechatConfig = &config.WechatConfig{
+		APIURL:  apiURL + "/",
+		CorpID:  fmt.Sprintf("%s-%d", corpID, atomic.AddInt32(&receiverSeq, 1)),
+		AgentID: "1000002",
+		APISecret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "wechat"},
+			Key:                  "secret",
+		},
+	}
+
+	return w
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.Options, receivers ...*config.Wechat) *Notifier {
+	return newLoggedNotifier(t, log.NewNopLogger(), opts, receivers...)
+}
+
+func newLoggedNotifier(t *testing.T, l log.Logger, opts *v1alpha1.Options, receivers ...*config.Wechat) *Notifier {
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewWechatNotifier(l, rs, testutil.NewConfig(secrets, opts))
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(status string, alertnames ...string) template.Data {
+
+	data := template.Data{
+		Receiver: "test",
+		Status:   status,
+	}
+	for _, name := range alertnames {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:      status,
+			Labels:      template.KV{"alertname": name},
+			Annotations: template.KV{},
+			StartsAt:    time.Now(),
+			Fingerprint: name,
+		})
+	}
+
+	return data
+}
+
+func TestNotifyTemplateError(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Template: "nm.not.exist"},
+	}, newReceiver(s.URL, "template-error"))
+
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) == 0 || errs[0] == nil {
+		t.Fatalf("expected the template error, got %v", errs)
+	}
+
+	if len(s.sent()) != 0 {
+		t.Errorf("expected no message sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyRetryMetrics(t *testing.T) {
+
+	var s *wechatServer
+	s = newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		// The first request fails with the system busy which can be retried.
+		if len(s.sent()) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Retry: &v1alpha1.Retry{MaxRetries: 2, Backoff: time.Millisecond}},
+	}, newReceiver(s.URL, "retry-metrics"))
+
+	retries := metrics.RetryTotal.WithLabelValues(notifierType)
+	r0 := promtestutil.ToFloat64(retries)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.sent()) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(s.sent()))
+	}
+
+	if v := promtestutil.ToFloat64(retries) - r0; v != 1 {
+		t.Errorf("expected 1 retry, got %v", v)
+	}
+}
+
+func TestNotifyCustomCA(t *testing.T) {
+
+	s := newWechatTLSServer(t, nil)
+	defer s.Close()
+
+	ca := secrets.NewSecret(t, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})))
+
+	// The server certificate is verified by the custom CA.
+	w := newReceiver(s.URL, "custom-ca")
+	w.WechatConfig.TLSConfig = &v1alpha1.TLSConfig{RootCA: ca}
+	n := newNotifier(t, nil, w)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.sent()) != 1 {
+		t.Fatalf("expected 1 message sent, got %d", len(s.sent()))
+	}
+
+	// The server certificate can not be verified without the CA.
+	w = newReceiver(s.URL, "unknown-ca")
+	n = newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Retry: &v1alpha1.Retry{MaxRetries: 1, Backoff: time.Millisecond}},
+	}, w)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) == 0 {
+		t.Fatal("expected the certificate error")
+	}
+
+	if len(s.sent()) != 1 {
+		t.Errorf("expected no more message sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyRateLimit(t *testing.T) {
+
+	const (
+		rps    = 20
+		burst  = 2
+		alerts = 10
+	)
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// Each alert is sent in its own message, and the messages are sent concurrently.
+	w := newReceiver(s.URL, "rate-limit")
+	w.MaxAlertsPerMessage = 1
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{RateLimit: &v1alpha1.RateLimit{RequestsPerSecond: rps, Burst: burst}},
+	}, w)
+
+	var names []string
+	for i := 0; i < alerts; i++ {
+		names = append(names, fmt.Sprintf("alert%d", i))
+	}
+
+	if errs := n.Notify(context.Background(), newData("firing", names...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	s.mu.Lock()
+	sentAt := append([]time.Time(nil), s.sentAt...)
+	s.mu.Unlock()
+
+	if len(sentAt) != alerts {
+		t.Fatalf("expected %d messages sent, got %d", alerts, len(sentAt))
+	}
+
+	first, last := sentAt[0], sentAt[0]
+	for _, at := range sentAt {
+		if at.Before(first) {
+			first = at
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+
+	// The messages beyond the burst are sent one by one at the rate limit.
+	min := time.Second * (alerts - burst) / rps
+	if span := last.Sub(first); span < min-time.Millisecond*10 {
+		t.Errorf("expected the messages to be sent in at least %s, got %s", min, span)
+	}
+}
+
+func TestNotifyRateLimitCancel(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "rate-limit-cancel")
+	w.MaxAlertsPerMessage = 1
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{RateLimit: &v1alpha1.RateLimit{RequestsPerSecond: 1, Burst: 1}},
+	}, w)
+
+	// Only the first message can be sent before the context is done, the others do not wait for the limiter.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	start := time.Now()
+	errs := n.Notify(ctx, newData("firing", "alert1", "alert2", "alert3"))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the cancelled sends not to block, took %s", elapsed)
+	}
+
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+
+	if len(s.sent()) != 1 {
+		t.Errorf("expected 1 message sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyMessageMaxSize(t *testing.T) {
+
+	names := []string{"alert1", "alert2", "alert3", "alert4", "alert5"}
+	tests := []struct {
+		name        string
+		maxSize     int
+		wantChunks  int
+		resetToZero bool
+	}{
+		{name: "default", wantChunks: 1},
+		// Each alert is rendered into 16 bytes, so a message holds 2 alerts.
+		{name: "small", maxSize: 40, wantChunks: 3},
+		{name: "misconfigured", resetToZero: true, wantChunks: 1},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		n := newNotifier(t, &v1alpha1.Options{
+			Wechat: &v1alpha1.WechatOptions{MessageMaxSize: tt.maxSize},
+		}, newReceiver(s.URL, "max-size-"+tt.name))
+		if tt.resetToZero {
+			n.messageMaxSize = 0
+		}
+
+		if errs := n.Notify(context.Background(), newData("firing", names...)); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		msgs := s.sent()
+		if len(msgs) != tt.wantChunks {
+			t.Errorf("%s: expected %d messages, got %d", tt.name, tt.wantChunks, len(msgs))
+		}
+
+		for _, m := range msgs {
+			if max := tt.maxSize; max > 0 && len(m.Text.Content) >= max {
+				t.Errorf("%s: message %q exceeds %d", tt.name, m.Text.Content, max)
+			}
+		}
+
+		s.Close()
+	}
+}
+
+func TestNotifyRecipients(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		toUser   string
+		toParty  string
+		wantSent int
+		wantUser string
+	}{
+		{name: "all empty", toUser: " | ", wantSent: 0},
+		{name: "single recipient", toUser: "user1", wantSent: 1, wantUser: "user1"},
+		{name: "separators trimmed", toUser: "|user1||user2|", wantSent: 1, wantUser: "user1|user2"},
+		{name: "party only", toUser: "|", toParty: "2", wantSent: 1},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		w := newReceiver(s.URL, "recipients")
+		w.ToUser, w.ToParty = tt.toUser, tt.toParty
+		n := newNotifier(t, nil, w)
+		if len(n.wechat) != 1 {
+			t.Fatalf("%s: expected the receiver to be valid", tt.name)
+		}
+
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		msgs := s.sent()
+		if len(msgs) != tt.wantSent {
+			t.Errorf("%s: expected %d messages, got %d", tt.name, tt.wantSent, len(msgs))
+		}
+
+		for _, m := range msgs {
+			if m.ToUser != tt.wantUser {
+				t.Errorf("%s: expected touser %q, got %q", tt.name, tt.wantUser, m.ToUser)
+			}
+		}
+
+		s.Close()
+	}
+}
+
+func TestSplitRecipients(t *testing.T) {
+
+	tests := map[string][]string{
+		"":               nil,
+		"|":              nil,
+		" a ":            {"a"},
+		"a|b":            {"a", "b"},
+		"|a| |b|":        {"a", "b"},
+		"a||b|":          {"a", "b"},
+		"user1 | user2 ": {"user1", "user2"},
+	}
+
+	for s, want := range tests {
+		if got := splitRecipients(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitRecipients(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestMergeRecipients(t *testing.T) {
+
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", ""},
+		{"user1", "", "user1"},
+		{"", "user1", "user1"},
+		{"user1|user2", "user2|user3", "user1|user2|user3"},
+		{"user1|", "|user2|", "user1|user2"},
+		{"user1||user1", "user1", "user1"},
+		{" | ", " ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := mergeRecipients(tt.a, tt.b); got != tt.want {
+			t.Errorf("mergeRecipients(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNotifyMergeReceivers(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// The receivers differ only in the recipients, so they produce the same key.
+	r1 := newReceiver(s.URL, "merge-receivers")
+	r1.ToUser, r1.ToParty = "user1|user2|", "party1"
+	r2 := r1.Clone()
+	r2.ToUser, r2.ToParty, r2.ToTag = "|user2|user3", "", "tag1"
+
+	// The same receiver processed twice does not duplicate the recipients.
+	n := newNotifier(t, nil, r1, r2, r1)
+	if len(n.wechat) != 1 {
+		t.Fatalf("expected the receivers merged, got %d", len(n.wechat))
+	}
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+
+	msg := msgs[0]
+	if msg.ToUser != "user1|user2|user3" || msg.ToParty != "party1" || msg.Totag != "tag1" {
+		t.Errorf("expected the recipients merged, got touser %q, toparty %q, totag %q", msg.ToUser, msg.ToParty, msg.Totag)
+	}
+
+	// The receivers are not mutated by the merging.
+	if r1.ToUser != "user1|user2|" || r2.ToUser != "|user2|user3" {
+		t.Errorf("expected the receivers unchanged, got %q and %q", r1.ToUser, r2.ToUser)
+	}
+}
+
+func TestBatch(t *testing.T) {
+
+	recipients := func(n int) []string {
+		var rs []string
+		for i := 0; i < n; i++ {
+			rs = append(rs, fmt.Sprintf("u%d", i))
+		}
+		return rs
+	}
+
+	const size = 3
+	tests := []struct {
+		name string
+		len  int
+		want []int
+	}{
+		{"empty", 0, nil},
+		{"one under", size - 1, []int{2}},
+		{"exact", size, []int{3}},
+		{"one over", size + 1, []int{3, 1}},
+		{"exact multiple", size * 2, []int{3, 3}},
+		{"multiple and one under", size*3 - 1, []int{3, 3, 2}},
+	}
+
+	for _, tt := range tests {
+		src := recipients(tt.len)
+		index := 0
+		var got []int
+		for b := batch(src, &index, size); len(b) > 0; b = batch(src, &index, size) {
+			got = append(got, len(strings.Split(b, "|")))
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected batches %v, got %v", tt.name, tt.want, got)
+		}
+
+		// The exhausted recipients contribute no further batches.
+		if b := batch(src, &index, size); len(b) != 0 {
+			t.Errorf("%s: expected no batch after exhausted, got %q", tt.name, b)
+		}
+	}
+}
+
+func TestNotifyBatches(t *testing.T) {
+
+	recipients := func(prefix string, n int) string {
+		var rs []string
+		for i := 0; i < n; i++ {
+			rs = append(rs, fmt.Sprintf("%s%d", prefix, i))
+		}
+		return strings.Join(rs, "|")
+	}
+
+	tests := []struct {
+		name     string
+		users    int
+		parties  int
+		wantSent int
+	}{
+		{"users one under", ToUserBatchSize - 1, 0, 1},
+		{"users exact", ToUserBatchSize, 0, 1},
+		{"users one over", ToUserBatchSize + 1, 0, 2},
+		{"users exact multiple", ToUserBatchSize * 2, 0, 2},
+		// The parties are exhausted after the first batch, the users continue.
+		{"parties exhausted", ToUserBatchSize * 2, ToPartyBatchSize, 2},
+		{"parties continue", 1, ToPartyBatchSize*2 + 1, 3},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		w := newReceiver(s.URL, "batches")
+		w.ToUser, w.ToParty = recipients("u", tt.users), recipients("p", tt.parties)
+		n := newNotifier(t, nil, w)
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		users, parties := 0, 0
+		for _, m := range s.sent() {
+			for _, rs := range []string{m.ToUser, m.ToParty} {
+				if strings.HasPrefix(rs, "|") || strings.HasSuffix(rs, "|") || strings.Contains(rs, "||") {
+					t.Errorf("%s: unexpected empty recipient in %q", tt.name, rs)
+				}
+			}
+			users += len(splitRecipients(m.ToUser))
+			parties += len(splitRecipients(m.ToParty))
+		}
+
+		if len(s.sent()) != tt.wantSent {
+			t.Errorf("%s: expected %d messages, got %d", tt.name, tt.wantSent, len(s.sent()))
+		}
+
+		if users != tt.users || parties != tt.parties {
+			t.Errorf("%s: expected %d users and %d parties, got %d and %d", tt.name, tt.users, tt.parties, users, parties)
+		}
+
+		s.Close()
+	}
+}
+
+func TestNotifySendError(t *testing.T) {
+
+	// The second batch of users is rejected.
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		if strings.Contains(msg.ToUser, "u1000") {
+			_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid userid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	var users []string
+	for i := 0; i <= ToUserBatchSize; i++ {
+		users = append(users, fmt.Sprintf("u%d", i))
+	}
+
+	failed := newReceiver(s.URL, "send-error")
+	failed.ToUser = strings.Join(users, "|")
+	ok := newReceiver(s.URL, "send-ok")
+	n := newNotifier(t, nil, failed, ok)
+
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	var se *notifier.SendError
+	if !errors.As(errs[0], &se) {
+		t.Fatalf("expected the send error, got %T", errs[0])
+	}
+
+	if se.NotifierType != notifierType {
+		t.Errorf("expected notifier type %s, got %s", notifierType, se.NotifierType)
+	}
+
+	if want := tokenKey(failed); se.Receiver != want {
+		t.Errorf("expected receiver %s, got %s", want, se.Receiver)
+	}
+
+	if want := "toUser: u1000, toParty: , toTag: "; se.Target != want {
+		t.Errorf("expected target %q, got %q", want, se.Target)
+	}
+
+	if !strings.Contains(se.Error(), "40003") {
+		t.Errorf("expected the error of wechat, got %s", se.Error())
+	}
+
+	if len(s.sent()) != 3 {
+		t.Errorf("expected 3 messages, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyCircuitBreaker(t *testing.T) {
+
+	const cooldown = time.Millisecond * 100
+
+	var failing int32 = 1
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		if atomic.LoadInt32(&failing) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid userid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{CircuitBreaker: &v1alpha1.CircuitBreaker{FailureThreshold: 2, Cooldown: cooldown}},
+	}, newReceiver(s.URL, "circuit-breaker"))
+
+	notify := func() []error {
+		return n.Notify(context.Background(), newData("firing", "alert1"))
+	}
+
+	// Closed, the failures are sent to the server until the threshold is reached.
+	for i := 0; i < 2; i++ {
+		if errs := notify(); len(errs) != 1 || errors.Is(errs[0], notifier.ErrCircuitOpen) {
+			t.Fatalf("expected the error of wechat, got %v", errs)
+		}
+	}
+
+	// Open, the sending is rejected without requesting the server.
+	errs := notify()
+	if len(errs) != 1 || !errors.Is(errs[0], notifier.ErrCircuitOpen) {
+		t.Fatalf("expected the circuit open error, got %v", errs)
+	}
+	if len(s.sent()) != 2 {
+		t.Fatalf("expected 2 messages sent, got %d", len(s.sent()))
+	}
+
+	// Half open, the probe is sent after the cooldown, and the failed probe opens the circuit again.
+	time.Sleep(cooldown)
+	if errs := notify(); len(errs) != 1 || errors.Is(errs[0], notifier.ErrCircuitOpen) {
+		t.Fatalf("expected the probe to be sent, got %v", errs)
+	}
+	if errs := notify(); len(errs) != 1 || !errors.Is(errs[0], notifier.ErrCircuitOpen) {
+		t.Fatalf("expected the circuit open error after the failed probe, got %v", errs)
+	}
+
+	// The successful probe closes the circuit.
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(cooldown)
+	for i := 0; i < 2; i++ {
+		if errs := notify(); len(errs) != 0 {
+			t.Fatalf("expected no error after the circuit closed, got %v", errs)
+		}
+	}
+
+	if len(s.sent()) != 5 {
+		t.Errorf("expected 5 messages sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyConfidentialAndDuplicateCheck(t *testing.T) {
+
+	tests := []struct {
+		name                  string
+		confidential          bool
+		duplicateCheck        bool
+		interval              int
+		wantSafe              string
+		wantDuplicateCheck    int
+		wantDuplicateInterval int
+	}{
+		{name: "default", wantSafe: "0"},
+		{name: "confidential", confidential: true, wantSafe: "1"},
+		{name: "duplicate check", duplicateCheck: true, interval: 600, wantSafe: "0", wantDuplicateCheck: 1, wantDuplicateInterval: 600},
+		// The interval out of range is ignored, so WeChat uses the default.
+		{name: "invalid interval", duplicateCheck: true, interval: DuplicateCheckIntervalMax + 1, wantSafe: "0", wantDuplicateCheck: 1},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		w := newReceiver(s.URL, "flags")
+		w.Confidential = tt.confidential
+		w.EnableDuplicateCheck = tt.duplicateCheck
+		w.DuplicateCheckInterval = tt.interval
+		n := newNotifier(t, nil, w)
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		msgs := s.sent()
+		if len(msgs) != 1 {
+			t.Fatalf("%s: expected 1 message, got %d", tt.name, len(msgs))
+		}
+
+		m := msgs[0]
+		if m.Safe != tt.wantSafe {
+			t.Errorf("%s: expected safe %q, got %q", tt.name, tt.wantSafe, m.Safe)
+		}
+
+		if m.EnableDuplicateCheck != tt.wantDuplicateCheck || m.DuplicateCheckInterval != tt.wantDuplicateInterval {
+			t.Errorf("%s: expected duplicate check %d/%d, got %d/%d", tt.name, tt.wantDuplicateCheck, tt.wantDuplicateInterval,
+				m.EnableDuplicateCheck, m.DuplicateCheckInterval)
+		}
+
+		s.Close()
+	}
+}
+
+func TestNotifyMarkdownMention(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "mention")
+	w.MsgType = config.WechatMarkdown
+	w.MentionedUsers = []string{`{{ .CommonLabels.owner }}`, "admin"}
+	n := newNotifier(t, nil, w)
+
+	// The common labels are generated from the labels of the alerts.
+	data := newData("firing", "alert1")
+	data.Alerts[0].Labels["owner"] = "alice"
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 || msgs[0].Markdown == nil {
+		t.Fatalf("expected 1 markdown message, got %+v", msgs)
+	}
+
+	if want := "<@alice><@admin>\n"; !strings.HasPrefix(msgs[0].Markdown.Content, want) {
+		t.Errorf("expected the content to start with %q, got %q", want, msgs[0].Markdown.Content)
+	}
+
+	// The text message does not support the mentions.
+	w = newReceiver(s.URL, "mention-text")
+	w.MentionedUsers = []string{"admin"}
+	n = newNotifier(t, nil, w)
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if msgs := s.sent(); len(msgs) != 2 || strings.Contains(msgs[1].Text.Content, "<@admin>") {
+		t.Errorf("expected the text message without mention, got %+v", msgs)
+	}
+}
+
+func TestNotifyRetryAfter(t *testing.T) {
+
+	var s *wechatServer
+	s = newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		// The first request is rate limited.
+		if len(s.sent()) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Retry: &v1alpha1.Retry{MaxRetries: 3, Backoff: time.Millisecond}},
+	}, newReceiver(s.URL, "retry-after"))
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	s.mu.Lock()
+	sentAt := append([]time.Time(nil), s.sentAt...)
+	s.mu.Unlock()
+
+	if len(sentAt) != 2 {
+		t.Fatalf("expected a single retry, got %d requests", len(sentAt))
+	}
+
+	// The retry waits for the time required by the server rather than the backoff.
+	if d := sentAt[1].Sub(sentAt[0]); d < time.Second {
+		t.Errorf("expected the retry after 1s, got %s", d)
+	}
+}
+
+func TestNotifyRetryAfterDeadline(t *testing.T) {
+
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer s.Close()
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "retry-after-deadline"))
+
+	// The waiting is bounded by the deadline of the context.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if errs := n.Notify(ctx, newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("expected to stop retrying immediately, took %s", elapsed)
+	}
+
+	if len(s.sent()) != 1 {
+		t.Errorf("expected no retry, got %d requests", len(s.sent()))
+	}
+}
+
+func TestNotifyDecoration(t *testing.T) {
+
+	names := []string{"alert1", "alert2", "alert3", "alert4", "alert5"}
+	tests := []struct {
+		name   string
+		global *v1alpha1.GlobalOptions
+		wechat *v1alpha1.WechatOptions
+		header string
+		footer string
+	}{
+		{
+			name:   "global",
+			global: &v1alpha1.GlobalOptions{Header: "[prod-1]", Footer: "{{ len .Alerts }} alerts"},
+			wechat: &v1alpha1.WechatOptions{MessageMaxSize: 60},
+			header: "[prod-1]",
+			footer: "5 alerts",
+		},
+		{
+			name:   "override",
+			global: &v1alpha1.GlobalOptions{Header: "[prod-1]", Footer: "global"},
+			wechat: &v1alpha1.WechatOptions{MessageMaxSize: 60, Footer: "wechat"},
+			header: "[prod-1]",
+			footer: "wechat",
+		},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		n := newNotifier(t, &v1alpha1.Options{Global: tt.global, Wechat: tt.wechat}, newReceiver(s.URL, "decoration-"+tt.name))
+		if errs := n.Notify(context.Background(), newData("firing", names...)); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		msgs := s.sent()
+		if len(msgs) < 2 {
+			t.Errorf("%s: expected the alerts to be split, got %d message", tt.name, len(msgs))
+		}
+
+		for _, m := range msgs {
+			content := m.Text.Content
+			if !strings.HasPrefix(content, tt.header+"\n") || !strings.HasSuffix(content, "\n"+tt.footer) {
+				t.Errorf("%s: message %q is not decorated", tt.name, content)
+			}
+
+			// The header and footer do not push the message over the max size.
+			if len(content) > tt.wechat.MessageMaxSize {
+				t.Errorf("%s: message %q exceeds %d", tt.name, content, tt.wechat.MessageMaxSize)
+			}
+		}
+
+		s.Close()
+	}
+}
+
+func TestNotifyMaxConcurrency(t *testing.T) {
+
+	var running, peak int32
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if cur <= p || atomic.CompareAndSwapInt32(&peak, p, cur) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond * 20)
+		atomic.AddInt32(&running, -1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	var receivers []*config.Wechat
+	for i := 0; i < 10; i++ {
+		w := newReceiver(s.URL, "max-concurrency")
+		w.ToUser = fmt.Sprintf("user%d", i)
+		receivers = append(receivers, w)
+	}
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{MaxConcurrency: 2},
+	}, receivers...)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.sent()) != 10 {
+		t.Errorf("expected 10 messages, got %d", len(s.sent()))
+	}
+
+	if p := atomic.LoadInt32(&peak); p > 2 {
+		t.Errorf("expected at most 2 messages sent at the same time, got %d", p)
+	}
+}
+
+func TestNotifySendTimeout(t *testing.T) {
+
+	release := make(chan struct{})
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		select {
+		case <-release:
+		case <-time.After(time.Second * 5):
+		}
+	})
+	defer s.Close()
+	defer close(release)
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "send-timeout"))
+	n.timeout = time.Millisecond * 200
+	n.maxRetries = 0
+
+	// The outer context has a far deadline, the hung sending fails after the timeout of the notifier.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	start := time.Now()
+	if errs := n.Notify(ctx, newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if elapsed := time.Since(start); elapsed < n.timeout || elapsed > n.timeout*4 {
+		t.Errorf("expected the sending returned around %s, took %s", n.timeout, elapsed)
+	}
+}
+
+func TestNotifyTokenTimeout(t *testing.T) {
+
+	release := make(chan struct{})
+	s := newUnstartedWechatServer(t, nil)
+
+	// Replace the token handler with a hung one.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	s.Config.Handler = mux
+	s.Start()
+	defer s.Close()
+	defer close(release)
+
+	n := newNotifier(t, nil, newReceiver(s.URL, "token-timeout"))
+	n.timeout = time.Millisecond * 200
+	n.maxRetries = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	start := time.Now()
+	if errs := n.Notify(ctx, newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second*2 {
+		t.Errorf("expected the token fetching bounded by %s, took %s", n.timeout, elapsed)
+	}
+
+	if len(s.sent()) != 0 {
+		t.Errorf("expected no message sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifySeverityRouting(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "severity-routing")
+	w.SeverityRouting = map[string]v1alpha1.WechatRoute{
+		"critical": {AgentID: "1000003", ToUser: "oncall"},
+		// Only the recipients are changed.
+		"warning": {ToParty: "ops"},
+	}
+	n := newNotifier(t, nil, w)
+
+	data := newData("firing", "critical1", "warning1", "info1", "critical2", "none1")
+	for i, severity := range []string{"critical", "warning", "info", "critical", ""} {
+		if len(severity) > 0 {
+			data.Alerts[i].Labels[SeverityLabel] = severity
+		}
+	}
+
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	type route struct {
+		agentID, toUser, toParty string
+	}
+	expected := map[route]string{
+		{"1000003", "oncall", ""}: "[firing] critical1\n[firing] critical2",
+		{"1000002", "", "ops"}:    "[firing] warning1",
+		// The alerts without matching severity fall back to the receiver.
+		{"1000002", "user1", ""}: "[firing] info1\n[firing] none1",
+	}
+
+	got := make(map[route]string)
+	for _, m := range s.sent() {
+		got[route{m.AgentID, m.ToUser, m.ToParty}] += m.Text.Content
+	}
+
+	if len(got) != len(expected) {
+		t.Errorf("expected %d routes, got %v", len(expected), got)
+	}
+
+	for r, content := range expected {
+		if got[r] != content {
+			t.Errorf("route %+v: expected %q, got %q", r, content, got[r])
+		}
+	}
+
+	// The routing does not modify the receiver.
+	if w.WechatConfig.AgentID != "1000002" || w.ToUser != "user1" {
+		t.Errorf("expected the receiver unchanged, got agent %s, user %s", w.WechatConfig.AgentID, w.ToUser)
+	}
+}
+
+func TestNotifySendResolved(t *testing.T) {
+
+	mixed := func() template.Data {
+		data := newData("firing", "firing1", "resolved1", "firing2")
+		data.Alerts[1].Status = "resolved"
+		// The status rendered is given by the end time.
+		data.Alerts[1].EndsAt = time.Now().Add(-time.Minute)
+		return data
+	}
+
+	tests := []struct {
+		name         string
+		sendResolved *bool
+		data         template.Data
+		want         []string
+	}{
+		{"default", nil, mixed(), []string{"[firing] firing1\n[resolved] resolved1\n[firing] firing2"}},
+		{"drop resolved", boolPtr(false), mixed(), []string{"[firing] firing1\n[firing] firing2"}},
+		{"all resolved", boolPtr(false), newData("resolved", "resolved1"), nil},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		n := newNotifier(t, &v1alpha1.Options{
+			Wechat: &v1alpha1.WechatOptions{SendResolved: tt.sendResolved},
+		}, newReceiver(s.URL, "send-resolved"))
+
+		if errs := n.Notify(context.Background(), tt.data); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		var got []string
+		for _, m := range s.sent() {
+			got = append(got, m.Text.Content)
+		}
+
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+
+		s.Close()
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestNotifyTemplateReload(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "wechat")
+	if err != nil {
+		t.Fatalf("create dir error, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "template.tmpl")
+	// The template is replaced rather than written in place, so that the watcher never reads a partial file.
+	write := func(text string) {
+		if err := ioutil.WriteFile(file+".tmp", []byte(text), 0644); err != nil {
+			t.Fatalf("write template error, %s", err)
+		}
+		if err := os.Rename(file+".tmp", file); err != nil {
+			t.Fatalf("replace template error, %s", err)
+		}
+	}
+	write(`{{ define "nm.default.text" }}v1 {{ range .Alerts }}{{ .Labels.alertname }}{{ end }}{{ end }}`)
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	c := testutil.NewConfig(secrets, nil)
+	c.ReceiverOpts.Global.TemplateFiles = []string{file}
+	nf, err := NewWechatNotifier(log.NewNopLogger(), []config.Receiver{newReceiver(s.URL, "template-reload")}, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+	n := nf.(*Notifier)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	write(`{{ define "nm.default.text" }}v2 {{ range .Alerts }}{{ .Labels.alertname }}{{ end }}{{ end }}`)
+
+	// The notifier renders the new template once it is reloaded.
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+
+		msgs := s.sent()
+		if msgs[0].Text.Content != "v1 alert1" {
+			t.Fatalf("expected the message rendered by the first template, got %q", msgs[0].Text.Content)
+		}
+
+		if content := msgs[len(msgs)-1].Text.Content; content == "v2 alert1" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the template reloaded, got %q", msgs[len(msgs)-1].Text.Content)
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+}
+
+func TestNotifySplitByAlert(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// Each alert is rendered into 16 bytes, so a message holds 2 alerts.
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{MessageMaxSize: 40, SplitMode: notifier.SplitModeAlert},
+	}, newReceiver(s.URL, "split-by-alert"))
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2", "alert3")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var got []string
+	for _, m := range s.sent() {
+		got = append(got, m.Text.Content)
+	}
+	// The messages are sent concurrently.
+	sort.Strings(got)
+
+	want := []string{"[firing] alert1\n[firing] alert2", "[firing] alert3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// A buffer of logs which can be written concurrently.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestNotifyInvalidRecipients(t *testing.T) {
+
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","msgid":"msg-1","invaliduser":"u2","invalidparty":"p1"}`))
+	})
+	defer s.Close()
+
+	logs := &logBuffer{}
+	w := newReceiver(s.URL, "invalid-recipients")
+	w.ToUser, w.ToParty = "u1|u2", "p1"
+	n := newLoggedNotifier(t, log.NewLogfmtLogger(logs), nil, w)
+
+	// The invalid recipients are returned as a non-fatal error.
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	var pe *notifier.PartialError
+	if !errors.As(errs[0], &pe) {
+		t.Fatalf("expected the partial error, got %s", errs[0])
+	}
+
+	for _, want := range []string{"level=warn", "invalidUser=u2", "invalidParty=p1", "level=debug", "msgID=msg-1"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected %q in the logs, got %s", want, logs.String())
+		}
+	}
+}
+
+func TestNotifyPartialDelivery(t *testing.T) {
+
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","invaliduser":"u2|u4"}`))
+	})
+	defer s.Close()
+
+	w := newReceiver(s.URL, "partial-delivery")
+	w.ToUser = "u1|u2|u3|u4|u5"
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{CircuitBreaker: &v1alpha1.CircuitBreaker{FailureThreshold: 1}},
+	}, w)
+
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	var pe *notifier.PartialError
+	if !errors.As(errs[0], &pe) {
+		t.Fatalf("expected the partial error, got %s", errs[0])
+	}
+
+	if pe.Delivered != 3 || pe.Total != 5 || !strings.Contains(pe.Invalid, "user: u2|u4") {
+		t.Errorf("expected 3 of 5 recipients delivered, got %+v", pe)
+	}
+
+	if !strings.Contains(errs[0].Error(), "3 of 5 recipients delivered") {
+		t.Errorf("expected the summary in the error, got %s", errs[0])
+	}
+
+	var se *notifier.SendError
+	if !errors.As(errs[0], &se) || se.Target != "toUser: u1|u2|u3|u4|u5, toParty: , toTag: " {
+		t.Errorf("expected the send error of the batch, got %s", errs[0])
+	}
+
+	// The partial delivery is neither retried nor a failure of the application.
+	if len(s.sent()) != 1 {
+		t.Errorf("expected 1 message, got %d", len(s.sent()))
+	}
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert2")); len(errs) != 1 || !errors.As(errs[0], &pe) {
+		t.Errorf("expected the circuit breaker closed, got %v", errs)
+	}
+}
+
+func TestNotifyLogFingerprints(t *testing.T) {
+
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+	})
+	defer s.Close()
+
+	logs := &logBuffer{}
+	n := newLoggedNotifier(t, log.NewJSONLogger(logs), &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Retry: &v1alpha1.Retry{MaxRetries: 1, Backoff: time.Millisecond}},
+	}, newReceiver(s.URL, "log-fingerprints"))
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) == 0 {
+		t.Fatal("expected the logs of the sending")
+	}
+
+	// Each log line of the sending is traceable by the fingerprints and the receiver.
+	failures := 0
+	for _, line := range lines {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("expected a json log line, got %s", line)
+		}
+
+		// The token is shared by the alerts.
+		if fields["msg"] == "WechatNotifier: get token" {
+			continue
+		}
+
+		if fields["level"] == "error" {
+			failures++
+		}
+
+		if fields["fingerprints"] != "alert1,alert2" || fields["receiver"] != "test" {
+			t.Errorf("expected the fingerprints and the receiver in the log, got %s", line)
+		}
+	}
+
+	if failures != 2 {
+		t.Errorf("expected the errors of 2 attempts logged, got %d", failures)
+	}
+}
+
+// Add the media API to the server, it returns the media id of the uploaded media in form of `type/filename/content`.
+func (s *wechatServer) handleMedia(t *testing.T) *int32 {
+
+	var uploads int32
+	s.mux.HandleFunc("/media/upload", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&uploads, 1)
+
+		if token := r.URL.Query().Get("access_token"); token != testToken {
+			t.Errorf("expected the access token %s, got %s", testToken, token)
+		}
+
+		file, header, err := r.FormFile("media")
+		if err != nil {
+			t.Errorf("read media error, %s", err)
+			return
+		}
+		defer file.Close()
+
+		content, _ := ioutil.ReadAll(file)
+		id := r.URL.Query().Get("type") + "/" + header.Filename + "/" + string(content)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"image","media_id":"` + id + `"}`))
+	})
+
+	return &uploads
+}
+
+func TestNotifyMedia(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+	uploads := s.handleMedia(t)
+	s.mux.HandleFunc("/files/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("png"))
+	})
+
+	image := newReceiver(s.URL, "media-image")
+	image.MsgType = config.WechatImage
+	image.Media = &v1alpha1.WechatMedia{URL: s.URL + "/files/logo.png"}
+
+	file := newReceiver(s.URL, "media-file")
+	file.MsgType = config.WechatFile
+	file.Media = &v1alpha1.WechatMedia{
+		Secret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "wechat"},
+			Key:                  "app.log",
+		},
+	}
+
+	n := newNotifier(t, nil, image, file)
+
+	// The media is uploaded once, and the media id is reused.
+	for i := 0; i < 2; i++ {
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	if n := atomic.LoadInt32(uploads); n != 2 {
+		t.Errorf("expected 2 media uploaded, got %d", n)
+	}
+
+	images, files := 0, 0
+	for _, m := range s.sent() {
+		switch {
+		case m.Image != nil && m.Image.MediaID == "image/logo.png/png" && m.Type == config.WechatImage:
+			images++
+		case m.File != nil && m.File.MediaID == "file/app.log/log" && m.Type == config.WechatFile:
+			files++
+		default:
+			t.Errorf("unexpected message %+v", m)
+		}
+	}
+
+	if images != 2 || files != 2 {
+		t.Errorf("expected 2 images and 2 files, got %d and %d", images, files)
+	}
+}
+
+func TestNotifyMediaUploadError(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+	s.mux.HandleFunc("/media/upload", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":40004,"errmsg":"invalid media type"}`))
+	})
+
+	w := newReceiver(s.URL, "media-error")
+	w.MsgType = config.WechatFile
+	w.Media = &v1alpha1.WechatMedia{
+		Secret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "wechat"},
+			Key:                  "app.log",
+		},
+	}
+
+	errs := newNotifier(t, nil, w).Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "40004") {
+		t.Fatalf("expected the error of uploading, got %v", errs)
+	}
+
+	// The message is not sent without the media.
+	if len(s.sent()) != 0 {
+		t.Errorf("expected no message, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyMediaConcurrentUpload(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+	uploads := s.handleMedia(t)
+	s.mux.HandleFunc("/files/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		// Make the batches upload at the same time.
+		time.Sleep(time.Millisecond * 50)
+		_, _ = w.Write([]byte("png"))
+	})
+
+	// The batches of the receiver are sent concurrently, all of them send the same image.
+	var users []string
+	for i := 0; i < ToUserBatchSize*3; i++ {
+		users = append(users, fmt.Sprintf("user%d", i))
+	}
+	w := newReceiver(s.URL, "media-concurrent")
+	w.ToUser = strings.Join(users, "|")
+	w.MsgType = config.WechatImage
+	w.Media = &v1alpha1.WechatMedia{URL: s.URL + "/files/logo.png"}
+
+	if errs := newNotifier(t, nil, w).Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if n := atomic.LoadInt32(uploads); n != 1 {
+		t.Errorf("expected the concurrent uploads merged, got %d uploads", n)
+	}
+
+	if len(s.sent()) != 3 {
+		t.Errorf("expected 3 messages, got %d", len(s.sent()))
+	}
+}
+
+func TestGetMediaCallerCanceled(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+	uploads := s.handleMedia(t)
+	s.mux.HandleFunc("/files/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 100)
+		_, _ = w.Write([]byte("png"))
+	})
+
+	w := newReceiver(s.URL, "media-canceled")
+	w.MsgType = config.WechatImage
+	w.Media = &v1alpha1.WechatMedia{URL: s.URL + "/files/logo.png"}
+	n := newNotifier(t, nil, w)
+
+	// The first caller starts the uploading and gives up before it is done,
+	// the uploading is not canceled, so the other caller waiting for it still gets the media.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var canceledErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, canceledErr = n.getMedia(ctx, w, testToken)
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+	id, err := n.getMedia(context.Background(), w, testToken)
+	wg.Wait()
+
+	if canceledErr != context.DeadlineExceeded {
+		t.Errorf("expected the first caller canceled, got %v", canceledErr)
+	}
+
+	if err != nil || id != "image/logo.png/png" {
+		t.Errorf("expected the media uploaded, got %q, %v", id, err)
+	}
+
+	if n := atomic.LoadInt32(uploads); n != 1 {
+		t.Errorf("expected 1 media uploaded, got %d", n)
+	}
+}
+
+func TestNotifyUserAgent(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		global *v1alpha1.GlobalOptions
+		want   string
+	}{
+		{"default", nil, notifier.DefaultUserAgent()},
+		{"custom", &v1alpha1.GlobalOptions{UserAgent: "gateway-client/1.0"}, "gateway-client/1.0"},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		n := newNotifier(t, &v1alpha1.Options{Global: tt.global}, newReceiver(s.URL, "user-agent-"+tt.name))
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		s.mu.Lock()
+		// Both the token fetching and the message sending have the User-Agent.
+		for _, path := range []string{"/gettoken", "/message/send"} {
+			if agents := s.userAgents[path]; len(agents) != 1 || agents[0] != tt.want {
+				t.Errorf("%s: expected the User-Agent %q of %s, got %q", tt.name, tt.want, path, agents)
+			}
+		}
+		s.mu.Unlock()
+
+		s.Close()
+	}
+}
+
+func TestNotifyDryRun(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	logs := &logBuffer{}
+	n := newLoggedNotifier(t, log.NewJSONLogger(logs), &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{DryRun: true},
+	}, newReceiver(s.URL, "dry-run"))
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// Neither the token is fetched nor the message is sent.
+	s.mu.Lock()
+	tokens := s.tokens
+	s.mu.Unlock()
+	if tokens != 0 || len(s.sent()) != 0 {
+		t.Errorf("expected no request, got %d token requests and %d messages", tokens, len(s.sent()))
+	}
+
+	var logged map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &fields); err == nil && fields["level"] == "info" {
+			logged = fields
+		}
+	}
+
+	if logged == nil {
+		t.Fatalf("expected the message logged, got %s", logs.String())
+	}
+
+	if u := logged["url"]; u != s.URL+"/message/send" {
+		t.Errorf("expected the url %s/message/send, got %v", s.URL, u)
+	}
+
+	var msg weChatMessage
+	if err := json.Unmarshal([]byte(fmt.Sprint(logged["message"])), &msg); err != nil {
+		t.Fatalf("decode the message logged error, %s", err)
+	}
+
+	if msg.Text == nil || msg.Text.Content != "[firing] alert1" || msg.ToUser != "user1" {
+		t.Errorf("expected the rendered message logged, got %v", logged["message"])
+	}
+}
+
+func TestNotifySkipInvalidReceivers(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	valid := newReceiver(s.URL, "invalid-receivers")
+	noRecipient := newReceiver(s.URL, "invalid-receivers")
+	noRecipient.ToUser = ""
+	badURL := newReceiver("qyapi.weixin.qq.com", "invalid-receivers")
+
+	logs := &logBuffer{}
+	n := newLoggedNotifier(t, log.NewLogfmtLogger(logs), nil, valid, noRecipient, badURL)
+	if len(n.wechat) != 1 {
+		t.Fatalf("expected only the valid receiver retained, got %d", len(n.wechat))
+	}
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if msgs := s.sent(); len(msgs) != 1 || msgs[0].ToUser != "user1" {
+		t.Errorf("expected the message sent to the valid receiver only, got %+v", msgs)
+	}
+
+	for _, want := range []string{"are all empty", "not an absolute url"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected the invalid receiver logged with %q, got %s", want, logs.String())
+		}
+	}
+}
+
+func TestNotifyPriorityThrottle(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	})
+	defer s.Close()
+
+	w := newReceiver(s.URL, "priority-throttle")
+	w.Throttle = &v1alpha1.PriorityThrottle{Window: time.Millisecond * 200}
+	n := newNotifier(t, nil, w)
+
+	data := newData("firing", "critical1", "warning1")
+	data.Alerts[0].Labels["severity"] = "critical"
+	data.Alerts[1].Labels["severity"] = "warning"
+
+	// The critical alert is sent immediately.
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if msgs := s.sent(); len(msgs) != 1 || msgs[0].Text.Content != "[firing] critical1" {
+		t.Fatalf("expected the critical alert sent immediately, got %+v", msgs)
+	}
+
+	more := newData("firing", "warning2")
+	more.Alerts[0].Labels["severity"] = "warning"
+	if errs := n.Notify(context.Background(), more); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.sent()) != 1 {
+		t.Fatalf("expected the low priority alerts buffered, got %d messages", len(s.sent()))
+	}
+
+	<-received
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the buffered alerts sent after the window")
+	}
+
+	// The low priority alerts are coalesced into one message.
+	msgs := s.sent()
+	if len(msgs) != 2 || msgs[1].Text.Content != "[firing] warning1\n[firing] warning2" {
+		t.Errorf("expected the buffered alerts sent in one message, got %+v", msgs)
+	}
+}
+
+func TestNotifyReceiverTemplate(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	defaults := newReceiver(s.URL, "receiver-template")
+	compact := newReceiver(s.URL, "receiver-template")
+	compact.ToUser = "user2"
+	compact.Template = "test.compact"
+
+	n := newNotifier(t, nil, defaults, compact)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	got := make(map[string]string)
+	for _, m := range s.sent() {
+		got[m.ToUser] = m.Text.Content
+	}
+
+	want := map[string]string{
+		"user1": "[firing] alert1\n[firing] alert2",
+		"user2": "2 alerts: alert1 alert2 ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// A sink records the dead letters in memory.
+type deadLetterSink struct {
+	mu      sync.Mutex
+	letters []*notifier.DeadLetter
+}
+
+func (s *deadLetterSink) Write(_ context.Context, dl *notifier.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, dl)
+	return nil
+}
+
+func TestNotifyDeadLetter(t *testing.T) {
+
+	// The server is always busy.
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+	})
+	defer s.Close()
+
+	sink := &deadLetterSink{}
+	notifier.SetDeadLetterSink(sink)
+	defer notifier.SetDeadLetterSink(nil)
+
+	r := newReceiver(s.URL, "dead-letter")
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Retry: &v1alpha1.Retry{MaxRetries: 2, Backoff: time.Millisecond}},
+	}, r)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if len(s.sent()) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(s.sent()))
+	}
+
+	if len(sink.letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(sink.letters))
+	}
+
+	dl := sink.letters[0]
+	if dl.NotifierType != notifierType {
+		t.Errorf("expected notifier type %s, got %s", notifierType, dl.NotifierType)
+	}
+	if want := tokenKey(r); dl.Receiver != want {
+		t.Errorf("expected receiver %s, got %s", want, dl.Receiver)
+	}
+	if !strings.Contains(dl.Error, "system busy") {
+		t.Errorf("expected the error of wechat, got %s", dl.Error)
+	}
+
+	// The payload is the message sent, so that it can be replayed.
+	msg := weChatMessage{}
+	if err := json.Unmarshal([]byte(dl.Payload), &msg); err != nil {
+		t.Fatalf("decode payload error, %s", err)
+	}
+	if msg.ToUser != "user1" || !strings.Contains(msg.Text.Content, "alert1") {
+		t.Errorf("expected the message sent, got %s", dl.Payload)
+	}
+}
+
+func TestNotifyThrottled(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	sink := &deadLetterSink{}
+	notifier.SetDeadLetterSink(sink)
+	defer notifier.SetDeadLetterSink(nil)
+
+	// Only one message is allowed by the send budget, the others are dropped without waiting.
+	notifier.GetSendBudget().SetLimit(1)
+	notifier.GetSendBudget().SetQueueSize(0)
+	defer notifier.GetSendBudget().SetQueueSize(notifier.DefaultSendBudgetQueueSize)
+	defer notifier.GetSendBudget().SetLimit(0)
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{CircuitBreaker: &v1alpha1.CircuitBreaker{FailureThreshold: 1, Cooldown: time.Minute}},
+	}, newReceiver(s.URL, "throttled"))
+
+	notify := func() []error {
+		return n.Notify(context.Background(), newData("firing", "alert1"))
+	}
+
+	if errs := notify(); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The rejections of the budget are not failures of wechat, they neither open the circuit nor write the dead letters.
+	for i := 0; i < 3; i++ {
+		errs := notify()
+		if len(errs) != 1 || !notifier.IsThrottled(errs[0]) {
+			t.Fatalf("expected the throttled error, got %v", errs)
+		}
+	}
+
+	if len(sink.letters) != 0 {
+		t.Errorf("expected no dead letter, got %d", len(sink.letters))
+	}
+
+	notifier.GetSendBudget().SetLimit(0)
+	if errs := notify(); len(errs) != 0 {
+		t.Errorf("expected the circuit closed, got %v", errs)
+	}
+
+	if len(s.sent()) != 2 {
+		t.Errorf("expected 2 messages sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyGroupBy(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		groupBy []string
+		want    []string
+	}{
+		{"no group", nil, []string{"4 alerts: alert1 alert2 alert3 alert4"}},
+		{"single label", []string{"service"}, []string{"1 alerts: alert2", "1 alerts: alert3", "2 alerts: alert1 alert4"}},
+		{"composite key", []string{"service", "cluster"}, []string{"1 alerts: alert1", "1 alerts: alert2", "1 alerts: alert3", "1 alerts: alert4"}},
+	}
+
+	for _, tt := range tests {
+		s := newWechatServer(t, nil)
+
+		n := newNotifier(t, &v1alpha1.Options{
+			Wechat: &v1alpha1.WechatOptions{Template: `{{ template "test.compact" . }}`, GroupBy: tt.groupBy},
+		}, newReceiver(s.URL, "group-by"))
+
+		data := newData("firing", "alert1", "alert2", "alert3", "alert4")
+		data.Alerts[0].Labels["service"], data.Alerts[0].Labels["cluster"] = "api", "c1"
+		data.Alerts[1].Labels["service"], data.Alerts[1].Labels["cluster"] = "db", "c1"
+		// The alert missing the label is in the default group.
+		data.Alerts[2].Labels["cluster"] = "c1"
+		data.Alerts[3].Labels["service"], data.Alerts[3].Labels["cluster"] = "api", "c2"
+
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Errorf("%s: expected no error, got %v", tt.name, errs)
+		}
+
+		var got []string
+		for _, msg := range s.sent() {
+			got = append(got, strings.TrimSpace(msg.Text.Content))
+		}
+		// The messages are sent concurrently.
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected messages %q, got %q", tt.name, tt.want, got)
+		}
+
+		s.Close()
+	}
+}
+
+func TestNotifyAPIURLEnv(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatalf("parse url error, %s", err)
+	}
+	_ = os.Setenv("WECHAT_PROXY_HOST", u.Host)
+	defer os.Unsetenv("WECHAT_PROXY_HOST")
+
+	// The message is sent through the proxy expanded from the environment variable.
+	r := newReceiver(s.URL, "api-url-env")
+	r.WechatConfig.APIURL = "http://${WECHAT_PROXY_HOST}/"
+	n := newNotifier(t, nil, r)
+
+	for _, w := range n.wechat {
+		if want := s.URL + "/"; w.WechatConfig.APIURL != want {
+			t.Errorf("expected api url %s, got %s", want, w.WechatConfig.APIURL)
+		}
+	}
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.sent()) != 1 || len(s.userAgents["/gettoken"]) != 1 {
+		t.Errorf("expected the token and the message requested through the proxy, got %d tokens and %d messages",
+			len(s.userAgents["/gettoken"]), len(s.sent()))
+	}
+}
+
+func TestAPIURLEnvFallback(t *testing.T) {
+
+	_ = os.Setenv("WECHAT_INVALID_URL", "http://[::1")
+	defer os.Unsetenv("WECHAT_INVALID_URL")
+
+	// The default api url is used if the expanded url is empty.
+	empty := newReceiver("", "api-url-empty")
+	empty.WechatConfig.APIURL = "${WECHAT_UNSET_URL}"
+	n := newNotifier(t, nil, empty)
+	if len(n.wechat) != 1 {
+		t.Fatalf("expected the receiver to be valid, got %d", len(n.wechat))
+	}
+	for _, w := range n.wechat {
+		if w.WechatConfig.APIURL != DefaultApiURL {
+			t.Errorf("expected the default api url, got %s", w.WechatConfig.APIURL)
+		}
+	}
+
+	// The receiver is ignored if the expanded url is invalid.
+	invalid := newReceiver("", "api-url-invalid")
+	invalid.WechatConfig.APIURL = "${WECHAT_INVALID_URL}"
+	if n := newNotifier(t, nil, invalid); len(n.wechat) != 0 {
+		t.Errorf("expected the invalid receiver ignored, got %d", len(n.wechat))
+	}
+}
+
+func TestAPIURLEnvNotCached(t *testing.T) {
+
+	defer os.Unsetenv("WECHAT_CACHED_HOST")
+
+	// The receiver is shared by the notifications, it keeps the urls with the environment variables.
+	r := newReceiver("", "api-url-cached")
+	r.WechatConfig.APIURL = "http://${WECHAT_CACHED_HOST}/"
+	r.WechatConfig.APIURLs = []v1alpha1.WechatRelay{{URL: "http://${WECHAT_CACHED_HOST}/relay/"}}
+
+	// The host is empty while the variable is not set.
+	n := newNotifier(t, nil, r)
+	for _, w := range n.wechat {
+		if w.WechatConfig.APIURL != "http:///" {
+			t.Errorf("expected the empty host expanded, got %s", w.WechatConfig.APIURL)
+		}
+	}
+
+	if r.WechatConfig.APIURL != "http://${WECHAT_CACHED_HOST}/" || r.WechatConfig.APIURLs[0].URL != "http://${WECHAT_CACHED_HOST}/relay/" {
+		t.Fatalf("expected the receiver unchanged, got %s and %s", r.WechatConfig.APIURL, r.WechatConfig.APIURLs[0].URL)
+	}
+
+	// The variable set later takes effect in the next notification.
+	_ = os.Setenv("WECHAT_CACHED_HOST", "wechat.test")
+	n = newNotifier(t, nil, r)
+	for _, w := range n.wechat {
+		if w.WechatConfig.APIURL != "http://wechat.test/" || w.WechatConfig.APIURLs[0].URL != "http://wechat.test/relay/" {
+			t.Errorf("expected the urls expanded, got %s and %s", w.WechatConfig.APIURL, w.WechatConfig.APIURLs[0].URL)
+		}
+	}
+}
+
+func TestNewNotifierConcurrent(t *testing.T) {
+
+	_ = os.Setenv("WECHAT_CONCURRENT_HOST", "wechat.test")
+	defer os.Unsetenv("WECHAT_CONCURRENT_HOST")
+
+	// The receivers are shared by the notifications created concurrently, it races if they are changed.
+	r := newReceiver("", "concurrent")
+	r.WechatConfig.APIURL = "${WECHAT_UNSET_URL}"
+	r.WechatConfig.APIURLs = []v1alpha1.WechatRelay{{URL: "http://${WECHAT_CONCURRENT_HOST}/"}}
+	r.DuplicateCheckInterval = -1
+	c := testutil.NewConfig(secrets, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := NewWechatNotifier(log.NewNopLogger(), []config.Receiver{r}, c)
+			if err != nil {
+				t.Errorf("create notifier error, %s", err)
+				return
+			}
+			for _, w := range n.(*Notifier).wechat {
+				if w.WechatConfig.APIURL != "http://wechat.test/" {
+					t.Errorf("expected the api url of the relay, got %s", w.WechatConfig.APIURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if r.WechatConfig.APIURL != "${WECHAT_UNSET_URL}" || r.DuplicateCheckInterval != -1 {
+		t.Errorf("expected the receiver unchanged, got %s and %d", r.WechatConfig.APIURL, r.DuplicateCheckInterval)
+	}
+}
+
+func TestNotifyCustomPaths(t *testing.T) {
+
+	var mu sync.Mutex
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/relay/wechat/token":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"` + testToken + `","expires_in":7200}`))
+		case "/relay/wechat/send":
+			if token := r.URL.Query().Get("access_token"); token != testToken {
+				t.Errorf("expected the access token %s, got %s", testToken, token)
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	// The relay exposes the paths other than the default ones.
+	r := newReceiver(s.URL, "custom-paths")
+	r.WechatConfig.SendPath = "relay/wechat/send"
+	r.WechatConfig.TokenPath = "relay/wechat/token"
+	n := newNotifier(t, nil, r)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if want := []string{"/relay/wechat/token", "/relay/wechat/send"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected the paths %v requested, got %v", want, paths)
+	}
+}
+
+func TestNotifyDefaultPaths(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// The default paths are used if they are not overridden.
+	n := newNotifier(t, nil, newReceiver(s.URL, "default-paths"))
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if len(s.userAgents["/"+DefaultTokenPath]) != 1 || len(s.userAgents["/"+DefaultSendPath]) != 1 {
+		t.Errorf("expected the default paths requested, got %v", s.userAgents)
+	}
+}
+
+func TestIsSuccess(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		code         int
+		successCodes []int
+		want         bool
+	}{
+		{"default success", 0, nil, true},
+		{"default failure", 1, nil, false},
+		{"configured success", 200, []int{0, 200}, true},
+		{"zero not configured", 0, []int{200}, false},
+		{"not configured", 500, []int{0, 200}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isSuccess(tt.code, tt.successCodes); got != tt.want {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNotifySuccessCodes(t *testing.T) {
+
+	// The relay responds errcode 200 if the message is sent.
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":200,"errmsg":"relayed"}`))
+	})
+	defer s.Close()
+
+	relay := newReceiver(s.URL, "success-codes")
+	relay.WechatConfig.SuccessCodes = []int{0, 200}
+	if errs := newNotifier(t, nil, relay).Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Errorf("expected no error, got %v", errs)
+	}
+
+	// The errcode is an error if the success codes are not configured.
+	errs := newNotifier(t, nil, newReceiver(s.URL, "default-success-codes")).Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "errcode: 200") {
+		t.Errorf("expected the errcode 200 returned, got %v", errs)
+	}
+}
+
+func TestNotifySharedToken(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// The notifiers created for the same app share the access token.
+	r := newReceiver(s.URL, "shared-token")
+	var notifiers []*Notifier
+	for i := 0; i < 5; i++ {
+		w := r.Clone()
+		w.ToUser = fmt.Sprintf("user%d", i)
+		notifiers = append(notifiers, newNotifier(t, nil, w))
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range notifiers {
+		wg.Add(1)
+		go func(n *Notifier) {
+			defer wg.Done()
+			if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+				t.Errorf("expected no error, got %v", errs)
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	tokens := s.tokens
+	s.mu.Unlock()
+	if tokens != 1 {
+		t.Errorf("expected 1 token request, got %d", tokens)
+	}
+	if len(s.sent()) != len(notifiers) {
+		t.Errorf("expected %d messages, got %d", len(notifiers), len(s.sent()))
+	}
+}
+
+func TestTitle(t *testing.T) {
+
+	withSummary := newData("firing", "alert1", "alert2")
+	withSummary.Alerts[1].Annotations["summary"] = "Pod is\ncrash looping"
+	common := newData("firing", "alert1")
+	common.CommonAnnotations = template.KV{"summary": "Disk is full"}
+	common.Alerts[0].Annotations["summary"] = "ignored"
+	withoutSummary := newData("firing", "alert1", "alert2")
+
+	tests := []struct {
+		name       string
+		annotation string
+		msgType    string
+		data       template.Data
+		want       string
+	}{
+		{"not configured", "", config.WechatText, withSummary, ""},
+		{"annotation of alert", "summary", config.WechatText, withSummary, "Pod is crash looping\n"},
+		{"common annotation", "summary", config.WechatText, common, "Disk is full\n"},
+		{"markdown", "summary", config.WechatMarkdown, common, "**Disk is full**\n"},
+		{"missing annotation", "summary", config.WechatText, withoutSummary, "alert1\n"},
+		{"missing annotation markdown", "summary", config.WechatMarkdown, withoutSummary, "**alert1**\n"},
+		{"no alertname", "summary", config.WechatText, template.Data{Alerts: template.Alerts{{}}}, ""},
+	}
+
+	for _, tt := range tests {
+		w := newReceiver("", "title")
+		w.TitleAnnotation = tt.annotation
+		w.MsgType = tt.msgType
+		if got := title(w, tt.data); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNotifyTitle(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	text := newReceiver(s.URL, "title-text")
+	text.TitleAnnotation = "summary"
+	markdown := newReceiver(s.URL, "title-markdown")
+	markdown.TitleAnnotation = "summary"
+	markdown.MsgType = config.WechatMarkdown
+	n := newNotifier(t, nil, text, markdown)
+
+	data := newData("firing", "alert1")
+	data.Alerts[0].Annotations["summary"] = "High CPU usage"
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	contents := make(map[string]string)
+	for _, msg := range s.sent() {
+		switch msg.Type {
+		case config.WechatText:
+			contents[msg.Type] = msg.Text.Content
+		case config.WechatMarkdown:
+			contents[msg.Type] = msg.Markdown.Content
+		}
+	}
+
+	// The title is the first line of the message.
+	if c := contents[config.WechatText]; !strings.HasPrefix(c, "High CPU usage\n[firing] alert1") {
+		t.Errorf("expected the title of the text message, got %q", c)
+	}
+	if c := contents[config.WechatMarkdown]; !strings.HasPrefix(c, "**High CPU usage**\n") {
+		t.Errorf("expected the bold title of the markdown message, got %q", c)
+	}
+}
+
+func TestNotifyPausedBuffered(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "paused-buffered")
+	w.Throttle = &v1alpha1.PriorityThrottle{Window: time.Millisecond * 100}
+	n := newNotifier(t, nil, w)
+
+	warning := func(name string) template.Data {
+		data := newData("firing", name)
+		data.Alerts[0].Labels["severity"] = "warning"
+		return data
+	}
+
+	// The buffered alerts flushed while paused are dropped.
+	if errs := n.Notify(context.Background(), warning("warning1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	notifier.SetPaused(true)
+	defer notifier.SetPaused(false)
+
+	time.Sleep(time.Millisecond * 400)
+	if len(s.sent()) != 0 {
+		t.Fatalf("expected no message sent while paused, got %d", len(s.sent()))
+	}
+
+	// The alerts buffered after resumed are sent.
+	notifier.SetPaused(false)
+	if errs := n.Notify(context.Background(), warning("warning2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	for i := 0; i < 100 && len(s.sent()) == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if msgs := s.sent(); len(msgs) != 1 || msgs[0].Text.Content != "[firing] warning2" {
+		t.Errorf("expected the alerts buffered after resumed sent, got %+v", msgs)
+	}
+}
+
+func TestNotifyQuietHours(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// The quiet hours last the whole day, so the test does not depend on the time it runs.
+	w := newReceiver(s.URL, "quiet-hours")
+	w.QuietHours = &v1alpha1.QuietHours{Ranges: []string{"00:00-00:00"}, Severity: "error"}
+	n := newNotifier(t, nil, w)
+
+	data := newData("firing", "critical1", "error1", "warning1")
+	data.Alerts[0].Labels["severity"] = "critical"
+	data.Alerts[1].Labels["severity"] = "error"
+	data.Alerts[2].Labels["severity"] = "warning"
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if msgs := s.sent(); len(msgs) != 1 || msgs[0].Text.Content != "[firing] critical1\n[firing] error1" {
+		t.Fatalf("expected the alerts of error or higher sent, got %+v", msgs)
+	}
+
+	// Nothing is sent if all alerts are suppressed.
+	if errs := n.Notify(context.Background(), newData("firing", "info1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	if len(s.sent()) != 1 {
+		t.Errorf("expected the alerts suppressed, got %d messages", len(s.sent()))
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	})
+	defer s.Close()
+
+	w := newReceiver(s.URL, "digest")
+	w.QuietHours = &v1alpha1.QuietHours{Ranges: []string{"00:00-00:00"}}
+	w.Digest = &v1alpha1.Digest{Interval: time.Millisecond * 300}
+	n := newNotifier(t, nil, w)
+
+	// The suppressed alerts are fed over time, the alert with the same fingerprint is counted once.
+	// The fingerprint is the name given, and the alertname is the name without the trailing digits.
+	for _, names := range [][]string{{"disk", "cpu"}, {"disk"}, {"cpu2"}} {
+		data := newData("firing", names...)
+		for i := range data.Alerts {
+			data.Alerts[i].Labels["alertname"] = strings.TrimRight(names[i], "0123456789")
+		}
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	if len(s.sent()) != 0 {
+		t.Fatalf("expected the alerts suppressed, got %d messages", len(s.sent()))
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the digest sent")
+	}
+
+	// A single digest is sent at the scheduled time.
+	time.Sleep(time.Millisecond * 400)
+	msgs := s.sent()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 digest, got %d", len(msgs))
+	}
+
+	if want := "Digest of 3 alerts\n- cpu: 2\n- disk: 1"; msgs[0].Type != config.WechatText || msgs[0].Text.Content != want {
+		t.Errorf("expected the digest %q, got %s %q", want, msgs[0].Type, msgs[0].Text.Content)
+	}
+}
+
+func TestNotifyReceiverTimeout(t *testing.T) {
+
+	// The relay takes 300ms to send each message.
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		select {
+		case <-time.After(time.Millisecond * 300):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	// The receiver of the slow relay has a larger timeout, and the other receiver uses the timeout of the notifier.
+	slow := newReceiver(s.URL, "receiver-timeout-slow")
+	slow.ToUser = "slow"
+	slow.Timeout = time.Second * 2
+	fast := newReceiver(s.URL, "receiver-timeout-fast")
+	fast.ToUser = "fast"
+	n := newNotifier(t, nil, slow, fast)
+	n.timeout = time.Millisecond * 100
+	n.maxRetries = 0
+
+	if got := n.timeoutOf(slow); got != slow.Timeout {
+		t.Errorf("expected the timeout %s of the receiver, got %s", slow.Timeout, got)
+	}
+	if got := n.timeoutOf(fast); got != n.timeout {
+		t.Errorf("expected the timeout %s of the notifier, got %s", n.timeout, got)
+	}
+
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	var se *notifier.SendError
+	if !errors.As(errs[0], &se) || se.Receiver != tokenKey(fast) {
+		t.Errorf("expected the sending to the receiver without timeout failed, got %v", errs[0])
+	}
+}
+
+func TestNotifyRelays(t *testing.T) {
+
+	s1 := newWechatServer(t, nil)
+	defer s1.Close()
+	s2 := newWechatServer(t, nil)
+	defer s2.Close()
+
+	// The messages are distributed across the relays by the weights.
+	w := newReceiver(s1.URL, "relays")
+	w.WechatConfig.APIURLs = []v1alpha1.WechatRelay{{URL: s1.URL + "/", Weight: 3}, {URL: s2.URL + "/", Weight: 1}}
+	n := newNotifier(t, nil, w)
+
+	for i := 0; i < 8; i++ {
+		if errs := n.Notify(context.Background(), newData("firing", fmt.Sprintf("alert%d", i))); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	if len(s1.sent()) != 6 || len(s2.sent()) != 2 {
+		t.Errorf("expected 6 and 2 messages sent through the relays, got %d and %d", len(s1.sent()), len(s2.sent()))
+	}
+}
+
+func TestNotifyRelayFailover(t *testing.T) {
+
+	good := newWechatServer(t, nil)
+	defer good.Close()
+	bad := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer bad.Close()
+
+	// The relay failed is skipped once its circuit breaker is open.
+	w := newReceiver(good.URL, "relay-failover")
+	w.WechatConfig.APIURLs = []v1alpha1.WechatRelay{{URL: bad.URL + "/"}, {URL: good.URL + "/"}}
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{CircuitBreaker: &v1alpha1.CircuitBreaker{FailureThreshold: 1, Cooldown: time.Minute}},
+	}, w)
+
+	for i := 0; i < 4; i++ {
+		if errs := n.Notify(context.Background(), newData("firing", fmt.Sprintf("alert%d", i))); len(errs) != 0 {
+			t.Fatalf("expected the message sent through the other relay, got %v", errs)
+		}
+	}
+
+	if len(good.sent()) != 4 {
+		t.Errorf("expected 4 messages sent through the good relay, got %d", len(good.sent()))
+	}
+	if len(bad.sent()) != 1 {
+		t.Errorf("expected the bad relay requested once, got %d", len(bad.sent()))
+	}
+}
+
+func TestNotifyTagTemplate(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "tag-template")
+	w.ToUser = ""
+	w.ToTag = "ops"
+	w.ToTagTemplate = `{{ .CommonLabels.team }}`
+	n := newNotifier(t, nil, w)
+
+	data := newData("firing", "alert1", "alert2", "alert3", "alert4")
+	data.CommonLabels = template.KV{"team": "common"}
+	for i, team := range []string{"frontend", "backend", "frontend", ""} {
+		if len(team) > 0 {
+			data.Alerts[i].Labels["team"] = team
+		}
+	}
+
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	got := make(map[string]string)
+	for _, m := range s.sent() {
+		if len(m.ToUser) > 0 || len(m.ToParty) > 0 {
+			t.Errorf("expected only the tags targeted, got user %q, party %q", m.ToUser, m.ToParty)
+		}
+		got[m.Totag] += m.Text.Content
+	}
+
+	want := map[string]string{
+		"frontend": "[firing] alert1\n[firing] alert3",
+		"backend":  "[firing] alert2",
+		// The alert without the label falls back to the static tags.
+		"ops": "[firing] alert4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// The routing does not modify the receiver.
+	if w.ToTag != "ops" || w.ToTagTemplate != `{{ .CommonLabels.team }}` {
+		t.Errorf("expected the receiver unchanged, got tag %q, template %q", w.ToTag, w.ToTagTemplate)
+	}
+}
+
+func TestNotifyTagTemplateMultiple(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "tag-template-multiple")
+	w.ToTagTemplate = `{{ range .Alerts }}{{ .Labels.team }}|oncall{{ end }}`
+	n := newNotifier(t, nil, w)
+
+	data := newData("firing", "alert1")
+	data.Alerts[0].Labels["team"] = "frontend"
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The users are still sent to along with the tags generated.
+	ms := s.sent()
+	if len(ms) != 1 || ms[0].Totag != "frontend|oncall" || ms[0].ToUser != "user1" {
+		t.Fatalf("expected 1 message to user1 and tags frontend|oncall, got %+v", ms)
+	}
+}
+
+func TestNotifyTagTemplateError(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "tag-template-error")
+	w.ToTagTemplate = `{{ template "not.exist" . }}`
+	n := newNotifier(t, nil, w)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if ms := s.sent(); len(ms) != 0 {
+		t.Errorf("expected no message sent, got %d", len(ms))
+	}
+}
+
+// A doer returning the canned responses rather than sending the requests, the paths of the requests are recorded.
+type stubDoer struct {
+	mu    sync.Mutex
+	paths []string
+	token func(n int) ([]byte, error)
+	send  func(ctx context.Context, n int) ([]byte, error)
+}
+
+func (d *stubDoer) Do(ctx context.Context, _ *http.Client, request *http.Request) ([]byte, error) {
+
+	d.mu.Lock()
+	d.paths = append(d.paths, request.URL.Path)
+	tokens, sends := d.count("/"+DefaultTokenPath), d.count("/"+DefaultSendPath)
+	d.mu.Unlock()
+
+	if strings.HasSuffix(request.URL.Path, DefaultTokenPath) {
+		if d.token != nil {
+			return d.token(tokens)
+		}
+		return []byte(`{"errcode":0,"access_token":"token","expires_in":7200}`), nil
+	}
+
+	return d.send(ctx, sends)
+}
+
+func (d *stubDoer) count(path string) int {
+
+	n := 0
+	for _, p := range d.paths {
+		if p == path {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (d *stubDoer) requests(path string) int {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.count(path)
+}
+
+func newStubNotifier(t *testing.T, corpID string, doer notifier.HTTPDoer) *Notifier {
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{Retry: &v1alpha1.Retry{MaxRetries: 2, Backoff: time.Millisecond}},
+	}, newReceiver("http://wechat.test", corpID))
+	n.SetHTTPDoer(doer)
+
+	return n
+}
+
+func TestNotifyDoerTimeout(t *testing.T) {
+
+	doer := &stubDoer{
+		send: func(ctx context.Context, _ int) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	n := newStubNotifier(t, "doer-timeout", doer)
+	n.timeout = time.Millisecond * 100
+
+	start := time.Now()
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second*2 {
+		t.Errorf("expected the sending bounded by %s, took %s", n.timeout, elapsed)
+	}
+
+	if c := doer.requests("/" + DefaultSendPath); c == 0 {
+		t.Errorf("expected the message sent, got no request")
+	}
+}
+
+func TestNotifyDoerTokenInvalid(t *testing.T) {
+
+	doer := &stubDoer{
+		token: func(n int) ([]byte, error) {
+			return []byte(fmt.Sprintf(`{"errcode":0,"access_token":"token%d","expires_in":7200}`, n)), nil
+		},
+		// The token is expired for the first message.
+		send: func(_ context.Context, n int) ([]byte, error) {
+			if n == 1 {
+				return []byte(`{"errcode":42001,"errmsg":"access_token expired"}`), nil
+			}
+			return []byte(`{"errcode":0,"errmsg":"ok"}`), nil
+		},
+	}
+	n := newStubNotifier(t, "doer-token-invalid", doer)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The token is fetched again and the message is resent.
+	if c := doer.requests("/" + DefaultTokenPath); c != 2 {
+		t.Errorf("expected the token fetched twice, got %d", c)
+	}
+	if c := doer.requests("/" + DefaultSendPath); c != 2 {
+		t.Errorf("expected the message sent twice, got %d", c)
+	}
+}
+
+func TestNotifyDoerMalformedBody(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		token  string
+		send   string
+		tokens int
+		sends  int
+	}{
+		// The malformed response is not retried.
+		{"send", `{"errcode":0,"access_token":"token","expires_in":7200}`, `<html>bad gateway</html>`, 1, 1},
+		{"token", `{"errcode":0,`, `{"errcode":0,"errmsg":"ok"}`, 1, 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		doer := &stubDoer{
+			token: func(int) ([]byte, error) { return []byte(test.token), nil },
+			send:  func(context.Context, int) ([]byte, error) { return []byte(test.send), nil },
+		}
+		n := newStubNotifier(t, "doer-malformed-"+test.name, doer)
+
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+			t.Errorf("%s: expected 1 error, got %v", test.name, errs)
+		}
+
+		if c := doer.requests("/" + DefaultTokenPath); c != test.tokens {
+			t.Errorf("%s: expected %d token requests, got %d", test.name, test.tokens, c)
+		}
+		if c := doer.requests("/" + DefaultSendPath); c != test.sends {
+			t.Errorf("%s: expected %d send requests, got %d", test.name, test.sends, c)
+		}
+	}
+}
+
+func TestSetHTTPDoer(t *testing.T) {
+
+	n := newNotifier(t, nil, newReceiver("http://wechat.test", "set-doer"))
+	if n.doer == nil {
+		t.Fatal("expected the default doer used")
+	}
+
+	// The doer is not replaced by nil.
+	doer := &stubDoer{}
+	n.SetHTTPDoer(doer)
+	n.SetHTTPDoer(nil)
+	if n.doer != doer {
+		t.Errorf("expected the stub doer kept, got %T", n.doer)
+	}
+}
+
+func TestNotifyCompress(t *testing.T) {
+
+	var mu sync.Mutex
+	var encodings []string
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		mu.Lock()
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	compressed := newReceiver(s.URL, "compress")
+	compressed.WechatConfig.Compress = true
+	plain := newReceiver(s.URL, "compress-plain")
+	plain.ToUser = "user2"
+
+	n := newNotifier(t, nil, compressed, plain)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The compressed body is decompressed by the relay to the same message.
+	got := make(map[string]string)
+	for _, m := range s.sent() {
+		got[m.ToUser] = m.Text.Content
+	}
+	if got["user1"] != "[firing] alert1\n[firing] alert2" || got["user1"] != got["user2"] {
+		t.Errorf("expected the same message received by both users, got %q", got)
+	}
+
+	sort.Strings(encodings)
+	if !reflect.DeepEqual(encodings, []string{"", "gzip"}) {
+		t.Errorf("expected only the message of the relay compressed, got encodings %q", encodings)
+	}
+}
+
+func TestIsOfficialAPI(t *testing.T) {
+
+	tests := []struct {
+		apiURL   string
+		expected bool
+	}{
+		{DefaultApiURL, true},
+		{"https://QYAPI.weixin.qq.com/cgi-bin/", true},
+		{"http://qyapi.weixin.qq.com:80/", true},
+		{"https://relay.example.com/cgi-bin/", false},
+		{"https://qyapi.weixin.qq.com.example.com/", false},
+		{"://invalid", false},
+	}
+
+	for _, test := range tests {
+		if got := isOfficialAPI(test.apiURL); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.apiURL, test.expected, got)
+		}
+	}
+}
+
+func TestNotifySummaryThreshold(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		msgType  string
+		alerts   int
+		expected []string
+	}{
+		{"below", config.WechatText, 2, []string{"[firing] alert0\n[firing] alert1"}},
+		{"at", config.WechatText, 3, []string{"[firing] alert0\n[firing] alert1\n[firing] alert2"}},
+		{"above", config.WechatText, 4, []string{"4 alerts firing in namespace kube-system"}},
+		// The summary of the markdown receiver is a markdown message.
+		{"above markdown", config.WechatMarkdown, 4, []string{"4 alerts firing in namespace kube-system"}},
+	}
+
+	for _, test := range tests {
+		s := newWechatServer(t, nil)
+
+		w := newReceiver(s.URL, "summary-"+test.name)
+		w.MsgType = test.msgType
+		w.SummaryThreshold = 3
+		n := newNotifier(t, nil, w)
+
+		var names []string
+		for i := 0; i < test.alerts; i++ {
+			names = append(names, fmt.Sprintf("alert%d", i))
+		}
+		data := newData("firing", names...)
+		for _, a := range data.Alerts {
+			a.Labels["namespace"] = "kube-system"
+		}
+
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Fatalf("%s: expected no error, got %v", test.name, errs)
+		}
+
+		var got []string
+		for _, m := range s.sent() {
+			if m.Type != test.msgType {
+				t.Errorf("%s: expected a %s message, got %s", test.name, test.msgType, m.Type)
+			}
+			if m.Type == config.WechatMarkdown {
+				got = append(got, m.Markdown.Content)
+			} else {
+				got = append(got, m.Text.Content)
+			}
+		}
+		s.Close()
+
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestNotifyMaxAlertsPerMessage(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "max-alerts")
+	w.MaxAlertsPerMessage = 2
+	// The alerts are chunked below the summary threshold.
+	w.SummaryThreshold = 10
+	n := newNotifier(t, nil, w)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2", "alert3", "alert4", "alert5")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	var got []string
+	for _, m := range s.sent() {
+		got = append(got, m.Text.Content)
+	}
+
+	// The messages are sent concurrently.
+	sort.Strings(got)
+	expected := []string{"[firing] alert1\n[firing] alert2", "[firing] alert3\n[firing] alert4", "[firing] alert5"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestNotifySerialDelivery(t *testing.T) {
+
+	for _, serial := range []bool{true, false} {
+		// The earlier messages are answered later, so the concurrent messages arrive in the reverse order.
+		var mu sync.Mutex
+		var arrived []string
+		s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+			var i int
+			_, _ = fmt.Sscanf(msg.Text.Content, "[firing] alert%d", &i)
+			time.Sleep(time.Duration(5-i) * time.Millisecond * 30)
+
+			mu.Lock()
+			arrived = append(arrived, msg.Text.Content)
+			mu.Unlock()
+
+			// The failure of a message does not stop the following ones.
+			if i == 2 {
+				_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid userid"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		})
+
+		w := newReceiver(s.URL, fmt.Sprintf("serial-delivery-%v", serial))
+		w.MaxAlertsPerMessage = 1
+		n := newNotifier(t, &v1alpha1.Options{
+			Wechat: &v1alpha1.WechatOptions{SerialDelivery: serial},
+		}, w)
+
+		if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2", "alert3", "alert4")); len(errs) != 1 {
+			t.Errorf("serial %v: expected 1 error, got %v", serial, errs)
+		}
+		s.Close()
+
+		expected := []string{"[firing] alert1", "[firing] alert2", "[firing] alert3", "[firing] alert4"}
+		if serial && !reflect.DeepEqual(arrived, expected) {
+			t.Errorf("serial %v: expected the messages in order %q, got %q", serial, expected, arrived)
+		}
+		if !serial && reflect.DeepEqual(arrived, expected) {
+			t.Errorf("serial %v: expected the messages sent concurrently, got %q", serial, arrived)
+		}
+	}
+}
+
+func TestNotifyTokenRetry(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		code   int
+		tokens int
+		sends  int
+		errs   int
+	}{
+		// The system busy is retried when the token is fetched.
+		{"system busy", SystemBusy, 3, 1, 0},
+		// The invalid secret is neither retried by the fetching nor by the sending.
+		{"invalid secret", 40001, 1, 0, 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		doer := &stubDoer{
+			token: func(n int) ([]byte, error) {
+				if n <= 2 {
+					return []byte(fmt.Sprintf(`{"errcode":%d,"errmsg":"error"}`, test.code)), nil
+				}
+				return []byte(`{"errcode":0,"access_token":"token","expires_in":7200}`), nil
+			},
+			send: func(context.Context, int) ([]byte, error) {
+				return []byte(`{"errcode":0,"errmsg":"ok"}`), nil
+			},
+		}
+		n := newStubNotifier(t, "token-retry-"+test.name, doer)
+
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != test.errs {
+			t.Errorf("%s: expected %d errors, got %v", test.name, test.errs, errs)
+		}
+
+		if c := doer.requests("/" + DefaultTokenPath); c != test.tokens {
+			t.Errorf("%s: expected %d token requests, got %d", test.name, test.tokens, c)
+		}
+		if c := doer.requests("/" + DefaultSendPath); c != test.sends {
+			t.Errorf("%s: expected %d send requests, got %d", test.name, test.sends, c)
+		}
+	}
+}
+
+func TestNotifyRetryTransient(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		err   error
+		sends int
+	}{
+		{"server error", &notifier.HttpError{StatusCode: http.StatusBadGateway}, 3},
+		{"network error", &url.Error{Op: "Post", URL: "http://wechat.test", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}, 3},
+		// The client errors can not be fixed by retrying.
+		{"client error", &notifier.HttpError{StatusCode: http.StatusBadRequest}, 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		doer := &stubDoer{
+			send: func(context.Context, int) ([]byte, error) {
+				return nil, test.err
+			},
+		}
+		n := newStubNotifier(t, "retry-transient-"+test.name, doer)
+
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+			t.Errorf("%s: expected 1 error, got %v", test.name, errs)
+		}
+
+		if c := doer.requests("/" + DefaultSendPath); c != test.sends {
+			t.Errorf("%s: expected %d send requests, got %d", test.name, test.sends, c)
+		}
+	}
+}
+
+func TestNotifySecretCorpID(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// The corp id is a part of the token key, so the references differ in each run.
+	seq := atomic.AddInt32(&receiverSeq, 1)
+	corpEnv, agentEnv := fmt.Sprintf("WECHAT_CORP_ID_%d", seq), fmt.Sprintf("WECHAT_AGENT_ID_%d", seq)
+	_ = os.Setenv(corpEnv, "secret-corp")
+	_ = os.Setenv(agentEnv, "1000005")
+	defer os.Unsetenv(corpEnv)
+	defer os.Unsetenv(agentEnv)
+
+	plain := newReceiver(s.URL, "plain-corp")
+	secret := newReceiver(s.URL, "")
+	secret.ToUser = "user2"
+	secret.WechatConfig.CorpID = "env://" + corpEnv
+	secret.WechatConfig.AgentID = "env://" + agentEnv
+
+	n := newNotifier(t, nil, plain, secret)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	s.mu.Lock()
+	var corpIDs []string
+	for _, q := range s.tokenQueries {
+		corpIDs = append(corpIDs, q.Get("corpid"))
+	}
+	s.mu.Unlock()
+
+	sort.Strings(corpIDs)
+	if expected := []string{plain.WechatConfig.CorpID, "secret-corp"}; !reflect.DeepEqual(corpIDs, expected) {
+		t.Errorf("expected the tokens of corp %q, got %q", expected, corpIDs)
+	}
+
+	agents := make(map[string]string)
+	for _, m := range s.sent() {
+		agents[m.ToUser] = m.AgentID
+	}
+	if expected := map[string]string{"user1": "1000002", "user2": "1000005"}; !reflect.DeepEqual(agents, expected) {
+		t.Errorf("expected the agents %v, got %v", expected, agents)
+	}
+}
+
+func TestNotifySecretCorpIDError(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	w := newReceiver(s.URL, "")
+	w.WechatConfig.CorpID = fmt.Sprintf("env://WECHAT_CORP_ID_NOT_EXIST_%d", atomic.AddInt32(&receiverSeq, 1))
+	n := newNotifier(t, nil, w)
+	n.maxRetries = 0
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	s.mu.Lock()
+	tokens := s.tokens
+	s.mu.Unlock()
+	if tokens != 0 || len(s.sent()) != 0 {
+		t.Errorf("expected nothing sent, got %d token requests and %d messages", tokens, len(s.sent()))
+	}
+}
+
+func TestNotifyCoalesce(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	})
+	defer s.Close()
+
+	w := newReceiver(s.URL, "coalesce")
+	w.CoalesceDelay = time.Millisecond * 200
+	n := newNotifier(t, nil, w)
+
+	// Two rapid notifications of the receiver.
+	for _, name := range []string{"alert1", "alert2"} {
+		if errs := n.Notify(context.Background(), newData("firing", name)); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+
+	if len(s.sent()) != 0 {
+		t.Fatalf("expected the alerts buffered, got %d messages", len(s.sent()))
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the buffered alerts sent after the delay")
+	}
+
+	// No more message is sent after the merged one.
+	select {
+	case <-received:
+		t.Errorf("expected one merged message, got %+v", s.sent())
+	case <-time.After(time.Millisecond * 300):
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 || msgs[0].Text.Content != "[firing] alert1\n[firing] alert2" {
+		t.Errorf("expected the alerts merged into one message, got %+v", msgs)
+	}
+}
+
+func statusOf(receiver string) *notifier.ReceiverStatus {
+
+	for _, s := range notifier.GetStatusRegistry().List() {
+		if s.Type == notifierType && s.Receiver == receiver {
+			return &s
+		}
+	}
+
+	return nil
+}
+
+func TestNotifyCoalesceStatus(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	})
+	defer s.Close()
+
+	// The receivers differing only in the recipients are merged, the registry is global so the names are unique.
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	r1 := newReceiver(s.URL, "coalesce-status")
+	r1.SetName("coalesce-status-1-" + suffix)
+	r1.CoalesceDelay = time.Millisecond * 200
+	r2 := r1.Clone()
+	r2.SetName("coalesce-status-2-" + suffix)
+	r2.ToUser = "user2"
+
+	n := newNotifier(t, nil, r1, r2)
+	if len(n.wechat) != 1 {
+		t.Fatalf("expected the receivers merged, got %d", len(n.wechat))
+	}
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// Nothing is sent to the receivers in the notification, so no result is recorded by it.
+	deferred := n.Deferred()
+	sort.Strings(deferred)
+	if !reflect.DeepEqual(deferred, []string{r1.GetName(), r2.GetName()}) {
+		t.Errorf("expected the receivers deferred, got %v", deferred)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the buffered alerts sent after the delay")
+	}
+
+	// The result of the flushed alerts is recorded for each receiver merged.
+	deadline := time.Now().Add(time.Second)
+	for _, name := range []string{r1.GetName(), r2.GetName()} {
+		status := statusOf(name)
+		for status == nil && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond * 10)
+			status = statusOf(name)
+		}
+
+		if status == nil || status.LastSuccessTime == nil || status.LastErrorTime != nil {
+			t.Errorf("expected the success of %s recorded, got %+v", name, status)
+		}
+	}
+}
+
+func TestNotifyDeferred(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	sent := newReceiver(s.URL, "deferred-sent")
+	sent.SetName("sent")
+	buffered := newReceiver(s.URL, "deferred-buffered")
+	buffered.SetName("buffered")
+	buffered.CoalesceDelay = time.Hour
+
+	n := newNotifier(t, nil, sent, buffered)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// Only the receiver which all alerts are buffered is deferred.
+	if deferred := n.Deferred(); !reflect.DeepEqual(deferred, []string{"buffered"}) {
+		t.Errorf("expected the buffered receiver deferred, got %v", deferred)
+	}
+}
+
+func TestNotifyCoalesceMaxAlerts(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	})
+	defer s.Close()
+
+	w := newReceiver(s.URL, "coalesce-max")
+	// The delay is long enough that only the maximum flushes the buffer.
+	w.CoalesceDelay = time.Hour
+	w.CoalesceMaxAlerts = 3
+	n := newNotifier(t, nil, w)
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	if errs := n.Notify(context.Background(), newData("firing", "alert3")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the buffered alerts sent when the maximum is reached")
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 || msgs[0].Text.Content != "[firing] alert1\n[firing] alert2\n[firing] alert3" {
+		t.Errorf("expected the alerts merged into one message, got %+v", msgs)
+	}
+}
+
+func TestNotifyCoalesceThrottle(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	})
+	defer s.Close()
+
+	// The critical alerts are not throttled, but still coalesced.
+	w := newReceiver(s.URL, "coalesce-throttle")
+	w.CoalesceDelay = time.Millisecond * 100
+	w.Throttle = &v1alpha1.PriorityThrottle{Window: time.Hour}
+	n := newNotifier(t, nil, w)
+
+	for _, name := range []string{"critical1", "critical2"} {
+		data := newData("firing", name)
+		data.Alerts[0].Labels["severity"] = "critical"
+		if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+			t.Fatalf("expected no error, got %v", errs)
+		}
+	}
+	low := newData("firing", "warning1")
+	low.Alerts[0].Labels["severity"] = "warning"
+	if errs := n.Notify(context.Background(), low); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the critical alerts sent after the delay")
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 1 || msgs[0].Text.Content != "[firing] critical1\n[firing] critical2" {
+		t.Errorf("expected the critical alerts merged into one message, got %+v", msgs)
+	}
+}
+
+// A cache reading the objects from the fake client.
+type fakeCache struct {
+	*informertest.FakeInformers
+	reader client.Reader
+}
+
+func (c *fakeCache) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+// Generate the receiver from the WechatReceiver, as the receivers are generated from the custom resources.
+func newGeneratedReceiver(t *testing.T, apiURL, corpID, toUser string, enabled *bool) *config.Wechat {
+
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+
+	wc := &v1alpha1.WechatConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "wechat-config", Namespace: testNamespace, Labels: map[string]string{"type": "default"}},
+		Spec: v1alpha1.WechatConfigSpec{
+			WechatApiUrl:     apiURL + "/",
+			WechatApiCorpId:  fmt.Sprintf("%s-%d", corpID, atomic.AddInt32(&receiverSeq, 1)),
+			WechatApiAgentId: "1000002",
+			WechatApiSecret: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: "env://WECHAT_SECRET"},
+			},
+		},
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme, wc)
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), &fakeCache{FakeInformers: &informertest.FakeInformers{Scheme: scheme}, reader: cl}, cl, nil)
+
+	w := config.NewWechatReceiver().(*config.Wechat)
+	w.GenerateReceiver(c, &v1alpha1.WechatReceiver{
+		ObjectMeta: metav1.ObjectMeta{Name: toUser, Namespace: testNamespace},
+		Spec: v1alpha1.WechatReceiverSpec{
+			WechatConfigSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"type": "default"}},
+			ToUser:               toUser,
+			Enabled:              enabled,
+		},
+	})
+	w.SetNamespace(testNamespace)
+
+	if w.WechatConfig == nil {
+		t.Fatal("expected the wechat config selected")
+	}
+
+	return w
+}
+
+func TestNotifyDisabledReceiver(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	enabled, disabled := true, false
+	n := newNotifier(t, nil,
+		newGeneratedReceiver(t, s.URL, "disabled", "user1", &disabled),
+		newGeneratedReceiver(t, s.URL, "enabled", "user2", &enabled),
+		newGeneratedReceiver(t, s.URL, "default", "user3", nil))
+
+	// The disabled receiver is excluded, the receiver is enabled by default.
+	if len(n.wechat) != 2 {
+		t.Fatalf("expected 2 receivers, got %d", len(n.wechat))
+	}
+	for _, w := range n.wechat {
+		if strings.HasPrefix(w.WechatConfig.CorpID, "disabled-") {
+			t.Errorf("expected the disabled receiver excluded, got %s", w.WechatConfig.CorpID)
+		}
+	}
+
+	for _, err := range n.Notify(context.Background(), newData("firing", "alert1")) {
+		if err != nil {
+			t.Fatalf("notify error, %s", err)
+		}
+	}
+
+	// No token is fetched and no message is sent for the disabled receiver.
+	s.mu.Lock()
+	tokens := s.tokens
+	s.mu.Unlock()
+	if tokens != 2 {
+		t.Errorf("expected 2 tokens fetched, got %d", tokens)
+	}
+
+	var users []string
+	for _, msg := range s.sent() {
+		users = append(users, msg.ToUser)
+	}
+	sort.Strings(users)
+	if !reflect.DeepEqual(users, []string{"user2", "user3"}) {
+		t.Errorf("expected the messages sent to user2 and user3, got %v", users)
+	}
+}
+
+func TestNotifyRetryBudget(t *testing.T) {
+
+	// 10% of the requests can be retried.
+	notifier.GetRetryBudget().SetRatio(0.1)
+	defer notifier.GetRetryBudget().SetRatio(0)
+
+	doer := &stubDoer{
+		send: func(context.Context, int) ([]byte, error) {
+			return nil, &notifier.HttpError{StatusCode: http.StatusBadGateway}
+		},
+	}
+	n := newStubNotifier(t, "retry-budget", doer)
+
+	const requests = 100
+	for i := 0; i < requests; i++ {
+		if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %v", errs)
+		}
+	}
+
+	// Without the budget, each request is retried twice. The retries stop once the budget is spent,
+	// the retries recorded by the former runs within the window only reduce the retries allowed.
+	sends := doer.requests("/" + DefaultSendPath)
+	if max := requests + notifier.RetryBudgetMinRetries + requests/10; sends > max {
+		t.Errorf("expected at most %d send requests, got %d", max, sends)
+	}
+	if sends < requests {
+		t.Errorf("expected at least %d send requests, got %d", requests, sends)
+	}
+}
+
+func newNewsReceiver(apiURL, corpID string) *config.Wechat {
+
+	w := newReceiver(apiURL, corpID)
+	w.MsgType = config.WechatNews
+
+	return w
+}
+
+func TestNotifyNewsNoArticle(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Wechat: &v1alpha1.WechatOptions{NewsTemplate: "nm.test.news.empty"},
+	}, newNewsReceiver(s.URL, "news-no-article"))
+
+	errs := n.Notify(context.Background(), newData("firing", "alert1"))
+	if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), "no article") {
+		t.Fatalf("expected the error of no article, got %v", errs)
+	}
+
+	if len(s.sent()) != 0 {
+		t.Errorf("expected no message sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyNewsSplit(t *testing.T) {
+
+	s := newWechatServer(t, nil)
+	defer s.Close()
+
+	// The articles are much longer than the max size of the text message in total.
+	description := strings.Repeat("d", 500)
+	data := newData("firing", "a1", "a2", "a3", "a4", "a5", "a6", "a7", "a8", "a9", "a10")
+	for _, alert := range data.Alerts {
+		alert.Annotations["message"] = description
+	}
+
+	n := newNotifier(t, nil, newNewsReceiver(s.URL, "news-split"))
+	if errs := n.Notify(context.Background(), data); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The articles are split by the max number of articles rather than the max size of the text message.
+	msgs := s.sent()
+	sort.Slice(msgs, func(i, j int) bool {
+		return len(msgs[i].News.Articles) > len(msgs[j].News.Articles)
+	})
+	if len(msgs) != 2 || len(msgs[0].News.Articles) != ArticlesMaxSize || len(msgs[1].News.Articles) != 2 {
+		t.Fatalf("expected the articles split into the messages of %d and 2 articles, got %d messages", ArticlesMaxSize, len(msgs))
+	}
+
+	for _, msg := range msgs {
+		if msg.Text != nil || msg.Markdown != nil {
+			t.Errorf("expected the news message only, got %+v", msg)
+		}
+		for _, article := range msg.News.Articles {
+			if article.Description != description {
+				t.Errorf("expected the description of %s kept, got %d bytes", article.Title, len(article.Description))
+			}
+		}
+	}
+}
+
+func TestNotifyDedup(t *testing.T) {
+
+	var s *wechatServer
+	s = newWechatServer(t, func(w http.ResponseWriter, r *http.Request, msg weChatMessage) {
+		// The first request fails with the error which can not be retried.
+		if len(s.sent()) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":60020,"errmsg":"not allow to access from your ip"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{DedupWindow: time.Hour},
+	}, newReceiver(s.URL, "dedup"))
+
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 1 {
+		t.Fatalf("expected the send error, got %v", errs)
+	}
+
+	// The message failed to send is not suppressed, so it can be sent again.
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	if len(s.sent()) != 2 {
+		t.Fatalf("expected the failed message sent again, got %d requests", len(s.sent()))
+	}
+
+	// The message sent within the window is suppressed.
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	if len(s.sent()) != 2 {
+		t.Errorf("expected the duplicate message suppressed, got %d requests", len(s.sent()))
+	}
+}
+
+func TestNotifyAppChat(t *testing.T) {
+
+	// A stub of the WeChat API recording the paths of the messages sent.
+	var mu sync.Mutex
+	var paths []string
+	var msgs []weChatMessage
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"` + testToken + `","expires_in":7200}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var msg weChatMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		msgs = append(msgs, msg)
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	// The users, parties and tags are more than a batch, they are not used by the chat.
+	r := newReceiver(s.URL, "appchat")
+	r.ChatID = "chat1"
+	var users, parties []string
+	for i := 0; i < ToUserBatchSize+1; i++ {
+		users = append(users, fmt.Sprintf("user%d", i))
+	}
+	for i := 0; i < ToPartyBatchSize+1; i++ {
+		parties = append(parties, fmt.Sprintf("party%d", i))
+	}
+	r.ToUser = strings.Join(users, "|")
+	r.ToParty = strings.Join(parties, "|")
+	r.ToTag = "tag1"
+
+	n := newNotifier(t, nil, r)
+	if errs := n.Notify(context.Background(), newData("firing", "alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The message is sent to the chat once rather than in the batches of the users, parties and tags.
+	if len(paths) != 1 || paths[0] != "/appchat/send" {
+		t.Fatalf("expected 1 message sent to /appchat/send, got %v", paths)
+	}
+
+	msg := msgs[0]
+	if msg.ChatID != "chat1" {
+		t.Errorf("expected the chat id chat1, got %q", msg.ChatID)
+	}
+	if msg.ToUser != "" || msg.ToParty != "" || msg.Totag != "" || msg.AgentID != "" {
+		t.Errorf("expected no user, party, tag and agent id, got %+v", msg)
+	}
+}
@@ -0,0 +1,334 @@
+package zoom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultSendTimeout   = time.Second * 3
+	DefaultApiURL        = "https://api.zoom.us"
+	DefaultOAuthURL      = "https://zoom.us"
+	DefaultTemplate      = `{{ template "nm.default.text" . }}`
+	DefaultTitleTemplate = `{{ template "nm.default.subject" . }}`
+	// The chatbot message is limited to 4096 characters, the space is reserved for the head.
+	MessageMaxSize = 4000
+	// The access token is expired a bit earlier than the expires_in in the response.
+	ExpiresMargin = time.Minute
+	messagePath   = "/v2/im/chat/messages"
+	tokenPath     = "/oauth/token"
+)
+
+type Notifier struct {
+	notifierCfg       *config.Config
+	zoom              map[string]*config.Zoom
+	timeout           time.Duration
+	logger            log.Logger
+	template          *notifier.Template
+	templateName      string
+	titleTemplateName string
+	messageMaxSize    int
+	decoration        *notifier.Decoration
+	ats               *notifier.AccessTokenService
+}
+
+type zoomMessage struct {
+	RobotJID          string      `json:"robot_jid"`
+	ToJID             string      `json:"to_jid"`
+	AccountID         string      `json:"account_id"`
+	Content           zoomContent `json:"content"`
+	IsMarkdownSupport bool        `json:"is_markdown_support"`
+}
+
+type zoomContent struct {
+	Head zoomHead   `json:"head"`
+	Body []zoomBody `json:"body"`
+}
+
+type zoomHead struct {
+	Text string `json:"text"`
+}
+
+type zoomBody struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func NewZoomNotifier(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error) {
+
+	var path []string
+	var externalURL string
+	var header, footer string
+	opts := notifierCfg.ReceiverOpts
+	if opts != nil && opts.Global != nil {
+		path = opts.Global.TemplateFiles
+		externalURL = opts.Global.ExternalURL
+		header, footer = opts.Global.Header, opts.Global.Footer
+	}
+	tmpl, err := notifier.NewTemplate(path, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get template error, %s", err.Error())
+	}
+
+	n := &Notifier{
+		notifierCfg:       notifierCfg,
+		zoom:              make(map[string]*config.Zoom),
+		timeout:           DefaultSendTimeout,
+		logger:            logger,
+		template:          tmpl,
+		templateName:      DefaultTemplate,
+		titleTemplateName: DefaultTitleTemplate,
+		messageMaxSize:    MessageMaxSize,
+		decoration:        &notifier.Decoration{Header: header, Footer: footer},
+		ats:               notifier.GetAccessTokenService(),
+	}
+
+	if opts != nil && opts.Zoom != nil {
+
+		if opts.Zoom.NotificationTimeout != nil {
+			n.timeout = time.Second * time.Duration(*opts.Zoom.NotificationTimeout)
+		}
+
+		if len(opts.Zoom.Template) > 0 {
+			n.templateName = opts.Zoom.Template
+		} else if opts.Global != nil && len(opts.Global.Template) > 0 {
+			n.templateName = opts.Global.Template
+		}
+
+		if len(opts.Zoom.TitleTemplate) > 0 {
+			n.titleTemplateName = opts.Zoom.TitleTemplate
+		}
+
+		if opts.Zoom.MessageMaxSize > 0 && opts.Zoom.MessageMaxSize < MessageMaxSize {
+			n.messageMaxSize = opts.Zoom.MessageMaxSize
+		}
+
+		if len(opts.Zoom.Header) > 0 {
+			n.decoration.Header = opts.Zoom.Header
+		}
+
+		if len(opts.Zoom.Footer) > 0 {
+			n.decoration.Footer = opts.Zoom.Footer
+		}
+	}
+
+	for _, r := range receivers {
+		receiver, ok := r.(*config.Zoom)
+		if !ok || receiver == nil {
+			continue
+		}
+
+		if err := receiver.Validate(); err != nil {
+			_ = level.Warn(logger).Log("msg", "ZoomNotifier: ignore invalid receiver", "error", err.Error())
+			continue
+		}
+
+		// The receiver is shared by the notifications, so the default urls are set in a copy of it.
+		c := *receiver.ZoomConfig
+		if len(c.APIURL) == 0 {
+			c.APIURL = DefaultApiURL
+		}
+
+		if len(c.OAuthURL) == 0 {
+			c.OAuthURL = DefaultOAuthURL
+		}
+		z := *receiver
+		z.ZoomConfig = &c
+
+		key, err := notifier.Md5key(&z)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "ZoomNotifier: get notifier error", "error", err.Error())
+			continue
+		}
+
+		n.zoom[key] = &z
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(ctx context.Context, data template.Data) []error {
+
+	title, err := n.template.TempleText(n.titleTemplateName, data, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "ZoomNotifier: generate title error", "error", err.Error())
+		return []error{err}
+	}
+
+	messages, err := n.template.SplitWithDecoration(data, n.messageMaxSize, n.templateName, n.decoration, n.logger)
+	if err != nil {
+		_ = level.Error(n.logger).Log("msg", "ZoomNotifier: split message error", "error", err.Error())
+		return []error{err}
+	}
+
+	send := func(z *config.Zoom, toJID string) error {
+
+		start := time.Now()
+		defer func() {
+			_ = level.Debug(n.logger).Log("msg", "ZoomNotifier: send message", "used", time.Since(start).String())
+		}()
+
+		u, err := notifier.UrlWithPath(z.ZoomConfig.APIURL, messagePath)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "ZoomNotifier: set path error", "error", err)
+			return err
+		}
+
+		for _, msg := range messages {
+			zm := &zoomMessage{
+				RobotJID:  z.ZoomConfig.RobotJID,
+				ToJID:     toJID,
+				AccountID: z.ZoomConfig.AccountID,
+				Content: zoomContent{
+					Head: zoomHead{Text: title},
+					Body: []zoomBody{{Type: "message", Text: msg}},
+				},
+				IsMarkdownSupport: true,
+			}
+
+			bs, err := json.Marshal(zm)
+			if err != nil {
+				_ = level.Error(n.logger).Log("msg", "ZoomNotifier: encode message error", "error", err.Error())
+				return err
+			}
+
+			if err := notifier.GetSendBudget().Acquire(ctx, n.logger); err != nil {
+				return err
+			}
+
+			if err := n.sendMessage(ctx, z, u, bs); err != nil {
+				_ = level.Error(n.logger).Log("msg", "ZoomNotifier: send message error", "to", toJID, "error", err.Error())
+				return err
+			}
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "ZoomNotifier: send message", "robot", z.ZoomConfig.RobotJID, "to", toJID)
+
+		return nil
+	}
+
+	group := async.NewGroup(ctx)
+	for _, zoom := range n.zoom {
+		z := zoom
+		for _, jid := range z.ToJIDs {
+			toJID := jid
+			group.Add(func(stopCh chan interface{}) {
+				stopCh <- send(z, toJID)
+			})
+		}
+	}
+
+	return group.Wait()
+}
+
+// Send the message, the message will be sent again with a new token if the token is invalid.
+func (n *Notifier) sendMessage(ctx context.Context, z *config.Zoom, u string, bs []byte) error {
+
+	notifier.GetRetryBudget().Request()
+	for attempt := 0; ; attempt++ {
+		token, err := n.getToken(ctx, z)
+		if err != nil {
+			_ = level.Error(n.logger).Log("msg", "ZoomNotifier: get access token error", "error", err.Error())
+			return err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(bs))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		_, err = notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err == nil {
+			return nil
+		}
+
+		var he *notifier.HttpError
+		if attempt > 0 || !errors.As(err, &he) || he.StatusCode != http.StatusUnauthorized {
+			return err
+		}
+
+		// The failure is returned immediately if the retry budget shared by all notifiers is exhausted.
+		if !notifier.GetRetryBudget().AllowRetry() {
+			_ = level.Warn(n.logger).Log("msg", "ZoomNotifier: retry budget exhausted, stop retrying", "attempt", attempt+1)
+			return err
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "ZoomNotifier: token is invalid, retry with a new token")
+		n.ats.InvalidToken(ctx, tokenKey(z), n.logger)
+	}
+}
+
+// Get the access token by the client credentials of the chatbot.
+func (n *Notifier) getToken(ctx context.Context, z *config.Zoom) (string, error) {
+
+	get := func(ctx context.Context) (string, time.Duration, error) {
+
+		secret, err := n.notifierCfg.GetSecretData(z.GetNamespace(), z.ZoomConfig.ClientSecret)
+		if err != nil {
+			return "", 0, err
+		}
+
+		u, err := notifier.UrlWithPath(z.ZoomConfig.OAuthURL, tokenPath)
+		if err != nil {
+			return "", 0, err
+		}
+
+		u, err = notifier.UrlWithParameters(u, map[string]string{"grant_type": "client_credentials"})
+		if err != nil {
+			return "", 0, err
+		}
+
+		request, err := http.NewRequest(http.MethodPost, u, nil)
+		if err != nil {
+			return "", 0, err
+		}
+		request.SetBasicAuth(z.ZoomConfig.ClientID, secret)
+
+		body, err := notifier.DoHttpRequest(ctx, &http.Client{Timeout: n.timeout}, request)
+		if err != nil {
+			return "", 0, err
+		}
+
+		resp := &tokenResponse{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			return "", 0, err
+		}
+
+		if len(resp.AccessToken) == 0 {
+			return "", 0, fmt.Errorf("zoom token response has no access token")
+		}
+
+		expires := time.Duration(resp.ExpiresIn) * time.Second
+		if expires > ExpiresMargin {
+			expires = expires - ExpiresMargin
+		}
+
+		_ = level.Debug(n.logger).Log("msg", "ZoomNotifier: get token", "key", tokenKey(z), "expires", expires.String())
+		return resp.AccessToken, expires, nil
+	}
+
+	return n.ats.GetToken(ctx, tokenKey(z), get)
+}
+
+// The key of the access token.
+func tokenKey(z *config.Zoom) string {
+	return z.ZoomConfig.ClientID + " | " + z.ZoomConfig.AccountID
+}
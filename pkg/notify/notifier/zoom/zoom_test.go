@@ -0,0 +1,335 @@
+package zoom
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	json "github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/internal/testutil"
+	"github.com/prometheus/alertmanager/template"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testNamespace    = testutil.Namespace
+	testClientSecret = "client-secret"
+)
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	_ = os.Setenv("ZOOM_CLIENT_SECRET", testClientSecret)
+	os.Exit(m.Run())
+}
+
+// A stub of the OAuth and the chatbot API of Zoom, it issues a new token for each token request.
+type zoomServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	messages []zoomMessage
+	tokens   []string
+	// The tokens rejected by the chatbot API.
+	rejected map[string]bool
+}
+
+func newZoomServer(t *testing.T) *zoomServer {
+
+	s := &zoomServer{rejected: make(map[string]bool)}
+	var issued int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("grant_type") != "client_credentials" {
+			t.Errorf("expected the client credentials grant, got %s %s", r.Method, r.URL.RawQuery)
+		}
+
+		id, secret, ok := r.BasicAuth()
+		if !ok || secret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"reason":"Invalid client_id or client_secret","error":"invalid_client"}`))
+			return
+		}
+
+		token := fmt.Sprintf("%s-token-%d", id, atomic.AddInt32(&issued, 1))
+		s.mu.Lock()
+		s.tokens = append(s.tokens, token)
+		s.mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"access_token":"%s","token_type":"bearer","expires_in":3599}`, token)
+	})
+	mux.HandleFunc(messagePath, func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		rejected := s.rejected[token]
+		s.mu.Unlock()
+		if rejected {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":124,"message":"Invalid access token."}`))
+			return
+		}
+
+		var msg zoomMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode message error, %s", err)
+		}
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(`{"message_id":"20200101000000000_abc","robot_jid":"` + msg.RobotJID + `"}`))
+	})
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+func (s *zoomServer) sent() []zoomMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]zoomMessage(nil), s.messages...)
+}
+
+func (s *zoomServer) issued() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.tokens...)
+}
+
+// Create a receiver sending to the stub, the client id identifies the access token, so it is unique for each receiver.
+func newReceiver(url string, toJIDs ...string) *config.Zoom {
+
+	z := config.NewZoomReceiver().(*config.Zoom)
+	z.SetNamespace(testNamespace)
+	z.ToJIDs = toJIDs
+	z.ZoomConfig = &config.ZoomConfig{
+		APIURL:    url,
+		OAuthURL:  url,
+		ClientID:  fmt.Sprintf("client-%d", time.Now().UnixNano()),
+		AccountID: "account",
+		RobotJID:  "robot@xmpp.zoom.us",
+		ClientSecret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "env://ZOOM_CLIENT_SECRET"},
+		},
+	}
+
+	return z
+}
+
+func newNotifier(t *testing.T, opts *v1alpha1.ZoomOptions, receivers ...*config.Zoom) *Notifier {
+
+	c := testutil.NewConfig(nil, &v1alpha1.Options{Zoom: opts})
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		rs = append(rs, r)
+	}
+
+	n, err := NewZoomNotifier(log.NewNopLogger(), rs, c)
+	if err != nil {
+		t.Fatalf("create notifier error, %s", err)
+	}
+
+	return n.(*Notifier)
+}
+
+func newData(names ...string) template.Data {
+
+	data := template.Data{Receiver: "test", Status: "firing"}
+	for _, name := range names {
+		data.Alerts = append(data.Alerts, template.Alert{
+			Status:   "firing",
+			Labels:   template.KV{"alertname": name},
+			StartsAt: time.Now(),
+		})
+	}
+
+	return data
+}
+
+func TestNotify(t *testing.T) {
+
+	s := newZoomServer(t)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "channel1@conference.xmpp.zoom.us", "channel2@conference.xmpp.zoom.us")
+	n := newNotifier(t, nil, r)
+
+	if errs := n.Notify(context.Background(), newData("alert1", "alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The token is shared by the messages to all channels.
+	if tokens := s.issued(); len(tokens) != 1 {
+		t.Errorf("expected 1 token issued, got %v", tokens)
+	}
+
+	msgs := s.sent()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+
+	var jids []string
+	for _, msg := range msgs {
+		jids = append(jids, msg.ToJID)
+
+		if msg.RobotJID != "robot@xmpp.zoom.us" || msg.AccountID != "account" || !msg.IsMarkdownSupport {
+			t.Errorf("unexpected message %+v", msg)
+		}
+		if msg.Content.Head.Text != "2 alerts firing" {
+			t.Errorf("expected the head %q, got %q", "2 alerts firing", msg.Content.Head.Text)
+		}
+		if len(msg.Content.Body) != 1 || msg.Content.Body[0].Type != "message" ||
+			strings.TrimSpace(msg.Content.Body[0].Text) != "[firing] alert1\n[firing] alert2" {
+			t.Errorf("unexpected body %+v", msg.Content.Body)
+		}
+	}
+
+	sort.Strings(jids)
+	if want := []string{"channel1@conference.xmpp.zoom.us", "channel2@conference.xmpp.zoom.us"}; strings.Join(jids, ",") != strings.Join(want, ",") {
+		t.Errorf("expected the messages sent to %v, got %v", want, jids)
+	}
+}
+
+func TestNotifySplit(t *testing.T) {
+
+	s := newZoomServer(t)
+	defer s.Close()
+
+	n := newNotifier(t, &v1alpha1.ZoomOptions{MessageMaxSize: 100}, newReceiver(s.URL, "channel@conference.xmpp.zoom.us"))
+
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("alert%02d", i))
+	}
+	if errs := n.Notify(context.Background(), newData(names...)); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	msgs := s.sent()
+	if len(msgs) < 2 {
+		t.Fatalf("expected the message split, got %d messages", len(msgs))
+	}
+
+	// The head is sent with each part.
+	var body string
+	for i, msg := range msgs {
+		if size := len(msg.Content.Body[0].Text); size > 100 {
+			t.Errorf("message %d: expected at most 100 bytes, got %d", i, size)
+		}
+		if msg.Content.Head.Text != "20 alerts firing" {
+			t.Errorf("message %d: unexpected head %q", i, msg.Content.Head.Text)
+		}
+		body += msg.Content.Body[0].Text
+	}
+
+	for _, name := range names {
+		if !strings.Contains(body, name) {
+			t.Errorf("expected %s sent", name)
+		}
+	}
+}
+
+func TestNotifyTokenInvalid(t *testing.T) {
+
+	s := newZoomServer(t)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "channel@conference.xmpp.zoom.us")
+	n := newNotifier(t, nil, r)
+	if errs := n.Notify(context.Background(), newData("alert1")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The token revoked is refreshed, and the message is sent again with the new token.
+	s.mu.Lock()
+	s.rejected[s.tokens[0]] = true
+	s.mu.Unlock()
+
+	if errs := n.Notify(context.Background(), newData("alert2")); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	if tokens := s.issued(); len(tokens) != 2 {
+		t.Errorf("expected the token refreshed, got %v", tokens)
+	}
+	if len(s.sent()) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyTokenError(t *testing.T) {
+
+	s := newZoomServer(t)
+	defer s.Close()
+
+	r := newReceiver(s.URL, "channel@conference.xmpp.zoom.us")
+	_ = os.Setenv("ZOOM_WRONG_SECRET", "wrong")
+	r.ZoomConfig.ClientSecret = &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "env://ZOOM_WRONG_SECRET"}}
+	n := newNotifier(t, nil, r)
+
+	errs := n.Notify(context.Background(), newData("alert1"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "401") {
+		t.Fatalf("expected the error of the token exchange, got %v", errs)
+	}
+
+	if len(s.sent()) != 0 {
+		t.Errorf("expected no message sent, got %d", len(s.sent()))
+	}
+}
+
+func TestNotifyInvalidReceiver(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		modify func(z *config.Zoom)
+	}{
+		{"no client id", func(z *config.Zoom) { z.ZoomConfig.ClientID = "" }},
+		{"no client secret", func(z *config.Zoom) { z.ZoomConfig.ClientSecret = nil }},
+		{"no robot", func(z *config.Zoom) { z.ZoomConfig.RobotJID = "" }},
+		{"no account", func(z *config.Zoom) { z.ZoomConfig.AccountID = "" }},
+		{"no channel", func(z *config.Zoom) { z.ToJIDs = nil }},
+	}
+
+	for _, tt := range tests {
+		r := newReceiver("", "channel@conference.xmpp.zoom.us")
+		tt.modify(r)
+		if n := newNotifier(t, nil, r); len(n.zoom) != 0 {
+			t.Errorf("%s: expected the receiver ignored, got %d", tt.name, len(n.zoom))
+		}
+	}
+}
+
+func TestNewNotifierDefaultURL(t *testing.T) {
+
+	r := newReceiver("", "channel@conference.xmpp.zoom.us")
+
+	// The notifiers are created concurrently with the same receiver, the default urls are not written to it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := newNotifier(t, nil, r)
+			for _, z := range n.zoom {
+				if z.ZoomConfig.APIURL != DefaultApiURL || z.ZoomConfig.OAuthURL != DefaultOAuthURL {
+					t.Errorf("expected the default urls, got %s and %s", z.ZoomConfig.APIURL, z.ZoomConfig.OAuthURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(r.ZoomConfig.APIURL) != 0 || len(r.ZoomConfig.OAuthURL) != 0 {
+		t.Errorf("expected the receiver not changed, got %s and %s", r.ZoomConfig.APIURL, r.ZoomConfig.OAuthURL)
+	}
+}
@@ -2,56 +2,159 @@ package notify
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
 	"github.com/kubesphere/notification-manager/pkg/async"
+	"github.com/kubesphere/notification-manager/pkg/metrics"
 	"github.com/kubesphere/notification-manager/pkg/notify/config"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier/dingtalk"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/discord"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier/email"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/file"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/googlechat"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/kafka"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/line"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/matrix"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/opsgenie"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/pagerduty"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/rocketchat"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier/slack"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/sms"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/sns"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/teams"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/telegram"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier/webhook"
 	"github.com/kubesphere/notification-manager/pkg/notify/notifier/wechat"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier/zoom"
 	"github.com/prometheus/alertmanager/template"
+	"reflect"
+	"strings"
+	"time"
 )
 
-type Factory func(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) notifier.Notifier
+// Factory creates the notifier of the receivers, the error is returned if the notifier can not be created.
+type Factory func(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config) (notifier.Notifier, error)
+
+// The factory of the notifier and the type of the receivers sent by it.
+type factory struct {
+	newNotifier  Factory
+	receiverType reflect.Type
+}
 
 var (
-	factories map[string]Factory
+	factories map[string]*factory
 )
 
 func init() {
-	Register("Email", email.NewEmailNotifier)
-	Register("Wechat", wechat.NewWechatNotifier)
-	Register("Slack", slack.NewSlackNotifier)
-	Register("Webhook", webhook.NewWebhookNotifier)
-	Register("DingTalk", dingtalk.NewDingTalkNotifier)
+	Register("Email", email.NewEmailNotifier, config.NewEmailReceiver)
+	Register("Wechat", wechat.NewWechatNotifier, config.NewWechatReceiver)
+	Register("Slack", slack.NewSlackNotifier, config.NewSlackReceiver)
+	Register("Webhook", webhook.NewWebhookNotifier, config.NewWebhookReceiver)
+	Register("DingTalk", dingtalk.NewDingTalkNotifier, config.NewDingTalkReceiver)
+	Register("Teams", teams.NewTeamsNotifier, config.NewTeamsReceiver)
+	Register("Discord", discord.NewDiscordNotifier, config.NewDiscordReceiver)
+	Register("PagerDuty", pagerduty.NewPagerDutyNotifier, config.NewPagerDutyReceiver)
+	Register("Opsgenie", opsgenie.NewOpsgenieNotifier, config.NewOpsgenieReceiver)
+	Register("Telegram", telegram.NewTelegramNotifier, config.NewTelegramReceiver)
+	Register("SMS", sms.NewSMSNotifier, config.NewSMSReceiver)
+	Register("Matrix", matrix.NewMatrixNotifier, config.NewMatrixReceiver)
+	Register("GoogleChat", googlechat.NewGoogleChatNotifier, config.NewGoogleChatReceiver)
+	Register("SNS", sns.NewSNSNotifier, config.NewSNSReceiver)
+	Register("Kafka", kafka.NewKafkaNotifier, config.NewKafkaReceiver)
+	Register("Line", line.NewLineNotifier, config.NewLineReceiver)
+	Register("File", file.NewFileNotifier, config.NewFileReceiver)
+	Register("Zoom", zoom.NewZoomNotifier, config.NewZoomReceiver)
+	Register("RocketChat", rocketchat.NewRocketChatNotifier, config.NewRocketChatReceiver)
 }
 
-func Register(name string, factory Factory) {
+// Register registers the factory of the notifier sending to the receivers created by newReceiver,
+// the receivers are passed to the factory by the type of them.
+func Register(name string, f Factory, newReceiver func() config.Receiver) {
 	if factories == nil {
-		factories = make(map[string]Factory)
+		factories = make(map[string]*factory)
 	}
 
-	factories[name] = factory
+	factories[name] = &factory{newNotifier: f, receiverType: reflect.TypeOf(newReceiver())}
 }
 
 type Notification struct {
 	Notifiers []notifier.Notifier
 	Data      template.Data
+	// The alerts sent by each notifier, they are filtered by the alert selector of the receivers.
+	alerts []template.Data
+	// The type and the receivers of each notifier, the result of the sending is recorded for each receiver.
+	types     []string
+	receivers [][]config.Receiver
+	logger    log.Logger
+	// The errors of creating the notifiers, they are returned with the errors of sending.
+	errs []error
 }
 
 func NewNotification(logger log.Logger, receivers []config.Receiver, notifierCfg *config.Config, data template.Data) *Notification {
 
-	n := &Notification{Data: data}
+	n := &Notification{Data: data, logger: logger}
 
 	if receivers == nil || len(receivers) == 0 {
 		return n
 	}
 
-	for _, f := range factories {
-		if f != nil {
-			n.Notifiers = append(n.Notifiers, f(logger, receivers, notifierCfg))
+	// The receivers with the same alert selector receive the same alerts.
+	selectors := make(map[string]*v1alpha1.AlertSelector)
+	groups := make(map[string][]config.Receiver)
+	for _, r := range receivers {
+		if !r.Enabled() {
+			continue
+		}
+
+		key := ""
+		if s := r.GetAlertSelector(); s != nil {
+			k, err := notifier.Md5key(s)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "get alert selector key error", "error", err.Error())
+				continue
+			}
+			key = k
+			selectors[key] = s
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	for key, rs := range groups {
+		d, err := notifier.FilterAlerts(data, selectors[key])
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "filter alerts error", "error", err.Error())
+			continue
+		}
+
+		if len(d.Alerts) == 0 {
+			continue
+		}
+
+		for name, f := range factories {
+			if f == nil {
+				continue
+			}
+
+			// Only the factories of the receivers are called, so that the notifiers not used are not created.
+			frs := f.receiversOf(rs)
+			if len(frs) == 0 {
+				continue
+			}
+
+			nf, err := f.newNotifier(logger, frs, notifierCfg)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "create notifier error", "notifier", name, "error", err.Error())
+				n.errs = append(n.errs, fmt.Errorf("create %s notifier error, %s", name, err.Error()))
+				continue
+			}
+
+			n.Notifiers = append(n.Notifiers, nf)
+			n.alerts = append(n.alerts, d)
+			n.types = append(n.types, strings.ToLower(name))
+			n.receivers = append(n.receivers, frs)
 		}
 	}
 
@@ -60,15 +163,87 @@ func NewNotification(logger log.Logger, receivers []config.Receiver, notifierCfg
 
 func (n *Notification) Notify(ctx context.Context) []error {
 
+	// The receivers are kept while paused, only the notifications are dropped.
+	if notifier.Paused() {
+		_ = level.Info(n.logger).Log("msg", "notifications are paused, drop the notification", "alerts", len(n.Data.Alerts))
+		return nil
+	}
+
 	group := async.NewGroup(ctx)
-	for _, notify := range n.Notifiers {
+	for i, notify := range n.Notifiers {
 		if notify != nil {
 			nf := notify
+			data := n.Data
+			if i < len(n.alerts) {
+				data = n.alerts[i]
+			}
+			notifierType, receivers := "", []config.Receiver(nil)
+			if i < len(n.types) {
+				notifierType, receivers = n.types[i], n.receivers[i]
+			}
 			group.Add(func(stopCh chan interface{}) {
-				stopCh <- nf.Notify(ctx, n.Data)
+				start := time.Now()
+				err := nf.Notify(ctx, data)
+				record(notifierType, sentReceivers(nf, receivers), start, err)
+				stopCh <- err
 			})
 		}
 	}
 
-	return group.Wait()
+	return append(group.Wait(), n.errs...)
+}
+
+// The receivers of the notifier created by the factory.
+func (f *factory) receiversOf(receivers []config.Receiver) []config.Receiver {
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		if reflect.TypeOf(r) == f.receiverType {
+			rs = append(rs, r)
+		}
+	}
+
+	return rs
+}
+
+// The receivers which something was sent to by the notifier, the results of the receivers
+// which all alerts were buffered are recorded when the alerts are sent.
+func sentReceivers(nf notifier.Notifier, receivers []config.Receiver) []config.Receiver {
+
+	d, ok := nf.(notifier.Deferrer)
+	if !ok {
+		return receivers
+	}
+
+	deferred := make(map[string]bool)
+	for _, name := range d.Deferred() {
+		deferred[name] = true
+	}
+
+	var rs []config.Receiver
+	for _, r := range receivers {
+		if !deferred[r.GetName()] {
+			rs = append(rs, r)
+		}
+	}
+
+	return rs
+}
+
+// Record the result of the sending started at `start` for each receiver of the notifier,
+// the receivers sent by the same notifier share the result.
+func record(notifierType string, receivers []config.Receiver, start time.Time, errs []error) {
+
+	var result error
+	for _, err := range errs {
+		if err != nil {
+			result = err
+			break
+		}
+	}
+
+	for _, r := range receivers {
+		metrics.ObserveSend(notifierType, r.GetName(), start, result)
+		notifier.GetStatusRegistry().Record(notifierType, r.GetName(), start, result)
+	}
 }
@@ -0,0 +1,453 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/log"
+	"github.com/kubesphere/notification-manager/pkg/apis/v1alpha1"
+	"github.com/kubesphere/notification-manager/pkg/metrics"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"io/ioutil"
+	"k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testNamespace = "default"
+
+func TestMain(m *testing.M) {
+
+	// The secrets are referenced by the environment variables, which are resolved in the namespace of notification manager.
+	_ = os.Setenv("NAMESPACE", testNamespace)
+	_ = os.Setenv("WECHAT_SECRET", "secret")
+	os.Exit(m.Run())
+}
+
+// A notifier returning the errors given, it counts the notifications received.
+type fakeNotifier struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, _ template.Data) []error {
+	f.calls++
+	return f.errs
+}
+
+func newWechatReceiver(name string) config.Receiver {
+	r := config.NewWechatReceiver()
+	r.SetName(name)
+	return r
+}
+
+func TestNotifyRecordsMetrics(t *testing.T) {
+
+	ok, failed := &fakeNotifier{}, &fakeNotifier{errs: []error{errors.New("send error")}}
+	n := &Notification{
+		Notifiers: []notifier.Notifier{ok, failed},
+		Data:      template.Data{Alerts: template.Alerts{{Status: "firing"}}},
+		types:     []string{"wechat", "wechat"},
+		receivers: [][]config.Receiver{
+			{newWechatReceiver("metrics-ok")},
+			{newWechatReceiver("metrics-failed")},
+		},
+	}
+
+	success := metrics.SentTotal.WithLabelValues("wechat", "metrics-ok", metrics.ResultSuccess)
+	failure := metrics.SentTotal.WithLabelValues("wechat", "metrics-failed", metrics.ResultFailure)
+	s0, f0 := testutil.ToFloat64(success), testutil.ToFloat64(failure)
+
+	if errs := n.Notify(context.Background()); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	if ok.calls != 1 || failed.calls != 1 {
+		t.Fatalf("expected each notifier to be called once, got %d and %d", ok.calls, failed.calls)
+	}
+
+	if v := testutil.ToFloat64(success) - s0; v != 1 {
+		t.Errorf("expected 1 successful send, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(failure) - f0; v != 1 {
+		t.Errorf("expected 1 failed send, got %v", v)
+	}
+}
+
+// Find the status of the receiver in the registry.
+func statusOf(notifierType, receiver string) *notifier.ReceiverStatus {
+
+	for _, s := range notifier.GetStatusRegistry().List() {
+		if s.Type == notifierType && s.Receiver == receiver {
+			return &s
+		}
+	}
+
+	return nil
+}
+
+func TestNotifyRecordsStatus(t *testing.T) {
+
+	// The first message is sent, and the second one is rejected.
+	var sent int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/gettoken") {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+
+		if atomic.AddInt32(&sent, 1) > 1 {
+			_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid userid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer s.Close()
+
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/template.tmpl"}},
+	}
+
+	// The registry is global, the name of the receiver is unique even if the test runs several times.
+	name := fmt.Sprintf("status-wechat-%d", time.Now().UnixNano())
+	w := config.NewWechatReceiver().(*config.Wechat)
+	w.SetName(name)
+	w.SetNamespace(testNamespace)
+	w.ToUser = "user1"
+	w.MsgType = config.WechatText
+	w.WechatConfig = &config.WechatConfig{
+		APIURL:  s.URL + "/",
+		CorpID:  "status-corp",
+		AgentID: "1000002",
+		APISecret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "env://WECHAT_SECRET"},
+		},
+	}
+
+	notify := func(alertname string) []error {
+		data := template.Data{
+			Status: "firing",
+			Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": alertname}, StartsAt: time.Now()}},
+		}
+		return NewNotification(log.NewNopLogger(), []config.Receiver{w}, c, data).Notify(context.Background())
+	}
+
+	if errs := notify("alert1"); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	status := statusOf("wechat", name)
+	if status == nil || status.LastSuccessTime == nil || status.LastErrorTime != nil {
+		t.Fatalf("expected the success recorded, got %+v", status)
+	}
+	success := *status.LastSuccessTime
+
+	if errs := notify("alert2"); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	// Both the last success and the last error are kept.
+	status = statusOf("wechat", name)
+	if status.LastSuccessTime == nil || !status.LastSuccessTime.Equal(success) {
+		t.Errorf("expected the last success time %s, got %v", success, status.LastSuccessTime)
+	}
+	if status.LastErrorTime == nil || !strings.Contains(status.LastError, "40003") {
+		t.Errorf("expected the error of wechat recorded, got %q at %v", status.LastError, status.LastErrorTime)
+	}
+	if !status.LastSendTime.Equal(*status.LastErrorTime) {
+		t.Errorf("expected the last send time %s, got %s", *status.LastErrorTime, status.LastSendTime)
+	}
+}
+
+func TestNotifyRecordsStatusBuffered(t *testing.T) {
+
+	received := make(chan struct{}, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/gettoken") {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		received <- struct{}{}
+	}))
+	defer s.Close()
+
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/template.tmpl"}},
+	}
+
+	// The alerts of the receiver are coalesced, they are sent after the delay.
+	name := fmt.Sprintf("status-buffered-%d", time.Now().UnixNano())
+	w := config.NewWechatReceiver().(*config.Wechat)
+	w.SetName(name)
+	w.SetNamespace(testNamespace)
+	w.ToUser = "user1"
+	w.MsgType = config.WechatText
+	w.CoalesceDelay = time.Millisecond * 200
+	w.WechatConfig = &config.WechatConfig{
+		APIURL:  s.URL + "/",
+		CorpID:  name,
+		AgentID: "1000002",
+		APISecret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "env://WECHAT_SECRET"},
+		},
+	}
+
+	data := template.Data{
+		Status: "firing",
+		Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": "alert1"}, StartsAt: time.Now()}},
+	}
+	if errs := NewNotification(log.NewNopLogger(), []config.Receiver{w}, c, data).Notify(context.Background()); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	// The alerts are only buffered, so the success is not recorded yet.
+	if status := statusOf("wechat", name); status != nil {
+		t.Fatalf("expected no result recorded before the alerts sent, got %+v", status)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the buffered alerts sent after the delay")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	status := statusOf("wechat", name)
+	for status == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+		status = statusOf("wechat", name)
+	}
+	if status == nil || status.LastSuccessTime == nil {
+		t.Errorf("expected the success recorded when the alerts sent, got %+v", status)
+	}
+}
+
+func TestNotifySendBudget(t *testing.T) {
+
+	var received int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "send-budget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The budget is shared by the webhook and the file notifier, the sends beyond it are dropped without waiting.
+	// The limit is applied when the global options are loaded.
+	notifier.GetSendBudget().SetLimit(2)
+	notifier.GetSendBudget().SetQueueSize(0)
+	defer notifier.GetSendBudget().SetQueueSize(notifier.DefaultSendBudgetQueueSize)
+	defer notifier.GetSendBudget().SetLimit(0)
+
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/template.tmpl"}},
+	}
+
+	webhook := config.NewWebhookReceiver().(*config.Webhook)
+	webhook.SetName("budget-webhook")
+	webhook.WebhookConfig = &config.WebhookConfig{URL: s.URL}
+	file := config.NewFileReceiver().(*config.File)
+	file.SetName("budget-file")
+	file.FileConfig = &config.FileConfig{Path: filepath.Join(dir, "alerts.log")}
+
+	notify := func(alertname string) []error {
+		data := template.Data{
+			Status: "firing",
+			Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": alertname}, StartsAt: time.Now()}},
+		}
+		return NewNotification(log.NewNopLogger(), []config.Receiver{webhook, file}, c, data).Notify(context.Background())
+	}
+
+	if errs := notify("alert1"); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+
+	errs := notify("alert2")
+	if len(errs) != 2 {
+		t.Fatalf("expected both notifiers dropped, got %v", errs)
+	}
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "global send budget exhausted") {
+			t.Errorf("expected the budget exhausted, got %s", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&received); n != 1 {
+		t.Errorf("expected 1 webhook request, got %d", n)
+	}
+	bs, err := ioutil.ReadFile(file.FileConfig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(bs), "\n"); n != 1 {
+		t.Errorf("expected 1 notification written, got %d", n)
+	}
+}
+
+func TestNotifyPaused(t *testing.T) {
+
+	var received int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer s.Close()
+
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/template.tmpl"}},
+	}
+
+	webhook := config.NewWebhookReceiver().(*config.Webhook)
+	webhook.SetName("paused-webhook")
+	webhook.WebhookConfig = &config.WebhookConfig{URL: s.URL}
+
+	notify := func(alertname string) []error {
+		data := template.Data{
+			Status: "firing",
+			Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": alertname}, StartsAt: time.Now()}},
+		}
+		return NewNotification(log.NewNopLogger(), []config.Receiver{webhook}, c, data).Notify(context.Background())
+	}
+
+	// The notifications are dropped without error while paused.
+	notifier.SetPaused(true)
+	defer notifier.SetPaused(false)
+	if errs := notify("alert1"); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	if n := atomic.LoadInt32(&received); n != 0 {
+		t.Fatalf("expected no request while paused, got %d", n)
+	}
+
+	notifier.SetPaused(false)
+	if errs := notify("alert2"); len(errs) != 0 {
+		t.Fatalf("expected no error, got %v", errs)
+	}
+	if n := atomic.LoadInt32(&received); n != 1 {
+		t.Errorf("expected the notification sent after resumed, got %d requests", n)
+	}
+}
+
+func TestNotifyCreateError(t *testing.T) {
+
+	var received int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer s.Close()
+
+	// The template path is a bad pattern, so that none of the notifiers can be created.
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/["}},
+	}
+
+	webhook := config.NewWebhookReceiver().(*config.Webhook)
+	webhook.SetName("create-error-webhook")
+	webhook.WebhookConfig = &config.WebhookConfig{URL: s.URL}
+
+	data := template.Data{
+		Status: "firing",
+		Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": "alert1"}, StartsAt: time.Now()}},
+	}
+	n := NewNotification(log.NewNopLogger(), []config.Receiver{webhook}, c, data)
+	if len(n.Notifiers) != 0 {
+		t.Fatalf("expected no notifier created, got %d", len(n.Notifiers))
+	}
+
+	// The errors of creating are returned by Notify rather than dropped, only the notifier of the receiver is created,
+	// so the error is not repeated for the notifiers not used.
+	errs := n.Notify(context.Background())
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "create Webhook notifier error, get template error") {
+		t.Errorf("expected the error of creating the webhook notifier only, got %v", errs)
+	}
+
+	if n := atomic.LoadInt32(&received); n != 0 {
+		t.Errorf("expected no request, got %d", n)
+	}
+}
+
+func TestNotifyCreateErrorFactory(t *testing.T) {
+
+	// The fake notifier sends to the wechat receivers too.
+	Register("Fake", func(log.Logger, []config.Receiver, *config.Config) (notifier.Notifier, error) {
+		return nil, errors.New("fake error")
+	}, config.NewWechatReceiver)
+	defer delete(factories, "Fake")
+
+	receiver := config.NewWechatReceiver().(*config.Wechat)
+	receiver.SetName(fmt.Sprintf("create-error-%d", time.Now().UnixNano()))
+
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/template.tmpl"}},
+	}
+
+	data := template.Data{
+		Status: "firing",
+		Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": "alert1"}, StartsAt: time.Now()}},
+	}
+
+	// Only the failed factory is skipped, the wechat notifier is still created.
+	n := NewNotification(log.NewNopLogger(), []config.Receiver{receiver}, c, data)
+	if len(n.Notifiers) != 1 || len(n.receivers[0]) != 1 || n.receivers[0][0] != receiver {
+		t.Errorf("expected the wechat notifier of the receiver created, got %d notifiers", len(n.Notifiers))
+	}
+
+	if len(n.errs) != 1 || n.errs[0].Error() != "create Fake notifier error, fake error" {
+		t.Errorf("expected the error of the fake factory, got %v", n.errs)
+	}
+}
+
+func TestNotifyReceiversOfFactory(t *testing.T) {
+
+	wechat, webhook := config.NewWechatReceiver(), config.NewWebhookReceiver()
+	wechat.SetName("wechat")
+	webhook.SetName("webhook")
+
+	// The receivers are passed to the factory by the type registered with it, rather than the name of it.
+	called := false
+	Register("Fake", func(log.Logger, []config.Receiver, *config.Config) (notifier.Notifier, error) {
+		called = true
+		return nil, errors.New("fake error")
+	}, config.NewWebhookReceiver)
+	defer delete(factories, "Fake")
+
+	f := factories["Fake"]
+	if rs := f.receiversOf([]config.Receiver{wechat, webhook}); len(rs) != 1 || rs[0] != webhook {
+		t.Errorf("expected the webhook receiver, got %v", rs)
+	}
+
+	// The factories without receivers are not called.
+	c := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, nil, nil)
+	c.ReceiverOpts = &v1alpha1.Options{
+		Global: &v1alpha1.GlobalOptions{TemplateFiles: []string{"testdata/template.tmpl"}},
+	}
+	data := template.Data{
+		Status: "firing",
+		Alerts: template.Alerts{{Status: "firing", Labels: template.KV{"alertname": "alert1"}, StartsAt: time.Now()}},
+	}
+
+	n := NewNotification(log.NewNopLogger(), []config.Receiver{wechat}, c, data)
+	if called || len(n.errs) != 0 {
+		t.Errorf("expected the fake factory not called, got the errors %v", n.errs)
+	}
+}
@@ -2,15 +2,21 @@ package v1
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/json-iterator/go"
 	"github.com/kubesphere/notification-manager/pkg/async"
 	"github.com/kubesphere/notification-manager/pkg/notify"
 	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,6 +26,12 @@ type HttpHandler struct {
 	webhookTimeout time.Duration
 	wkrTimeout     time.Duration
 	notifierCfg    *config.Config
+	// The notifications being sent, the shutdown waits for them to finish.
+	mutex    sync.Mutex
+	closing  bool
+	inflight sync.WaitGroup
+	// Get the bearer token of the admin endpoints, such as pause and resume, they are disabled if it is nil.
+	adminToken func() (string, error)
 }
 
 type response struct {
@@ -49,6 +61,11 @@ func (h *HttpHandler) CreateNotificationfromAlerts(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if !h.track() {
+		h.handle(w, &response{http.StatusServiceUnavailable, "Notification manager is shutting down"})
+		return
+	}
+
 	//	if alerts, err := json.MarshalIndent(data, "", "  "); err != nil {
 	//		_ = level.Error(h.logger).Log("msg", "Failed to encode alerts:", "err", err)
 	//	} else {
@@ -64,6 +81,9 @@ func (h *HttpHandler) CreateNotificationfromAlerts(w http.ResponseWriter, r *htt
 	case <-ctx.Done():
 		_ = level.Warn(h.logger).Log("msg", "Running out of queue capacity in "+h.webhookTimeout.String(), "error", ctx.Err())
 		h.handle(w, &response{http.StatusInternalServerError, "Running out of queue capacity with error: " + ctx.Err().Error()})
+		// The notification is not sent, so it is not being sent any more.
+		h.inflight.Done()
+		return
 	}
 
 	worker := func(ctx context.Context, wkload template.Data, stopCh chan struct{}) error {
@@ -126,6 +146,16 @@ func (h *HttpHandler) CreateNotificationfromAlerts(w http.ResponseWriter, r *htt
 			errs := group.Wait()
 			if errs != nil && len(errs) > 0 {
 				_ = level.Error(h.logger).Log("msg", "Worker: notification sent error")
+				for _, e := range errs {
+					// The send error could be wrapped by the other errors.
+					var se *notifier.SendError
+					if errors.As(e, &se) {
+						_ = level.Error(h.logger).Log("msg", "Worker: send notification error", "type", se.NotifierType,
+							"receiver", se.Receiver, "target", se.Target, "error", se.Err.Error())
+					} else {
+						_ = level.Error(h.logger).Log("msg", "Worker: send notification error", "error", e.Error())
+					}
+				}
 			}
 
 			_ = level.Debug(h.logger).Log("msg", "Worker: notification sent")
@@ -145,6 +175,7 @@ func (h *HttpHandler) CreateNotificationfromAlerts(w http.ResponseWriter, r *htt
 
 	// launch one worker goroutine for each received alert to create notification for it
 	go func(semCh chan struct{}, timeout time.Duration) {
+		defer h.inflight.Done()
 		_ = level.Debug(h.logger).Log("msg", "Begins to send notification...")
 
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -171,8 +202,47 @@ func (h *HttpHandler) CreateNotificationfromAlerts(w http.ResponseWriter, r *htt
 	h.handle(w, &response{http.StatusOK, "Notification request accepted"})
 }
 
+// Track the notification being sent, it returns false if the handler is shutting down.
+func (h *HttpHandler) track() bool {
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closing {
+		return false
+	}
+
+	h.inflight.Add(1)
+	return true
+}
+
+// Shutdown stops accepting new notifications, waits for the notifications being sent to finish, and then sends
+// the alerts buffered by the throttle, the coalescing and the digest, or returns when the context is done.
+// The buffered alerts are dropped if the notifications are paused.
+func (h *HttpHandler) Shutdown(ctx context.Context) error {
+
+	h.mutex.Lock()
+	h.closing = true
+	h.mutex.Unlock()
+
+	ch := make(chan struct{})
+	go func() {
+		h.inflight.Wait()
+		notifier.GetPriorityThrottler().Flush()
+		notifier.GetDigester().Flush()
+		close(ch)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ch:
+		return nil
+	}
+}
+
 func (h *HttpHandler) ServeMetrics(w http.ResponseWriter, r *http.Request) {
-	h.handle(w, &response{http.StatusOK, "metrics"})
+	promhttp.Handler().ServeHTTP(w, r)
 }
 
 func (h *HttpHandler) ServeReload(w http.ResponseWriter, r *http.Request) {
@@ -191,6 +261,63 @@ func (h *HttpHandler) ServeStatus(w http.ResponseWriter, r *http.Request) {
 	h.handle(w, &response{http.StatusOK, "status"})
 }
 
+// SetAdminToken sets the function getting the bearer token of the admin endpoints.
+func (h *HttpHandler) SetAdminToken(f func() (string, error)) {
+	h.adminToken = f
+}
+
+// Whether the request carries the admin token, the response is written if it does not.
+func (h *HttpHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+
+	if h.adminToken == nil {
+		h.handle(w, &response{http.StatusForbidden, "the admin endpoints are disabled"})
+		return false
+	}
+
+	token, err := h.adminToken()
+	if err != nil {
+		h.handle(w, &response{http.StatusInternalServerError, "get admin token error, " + err.Error()})
+		return false
+	}
+
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if len(token) == 0 || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+		h.handle(w, &response{http.StatusUnauthorized, "unauthorized admin request"})
+		return false
+	}
+
+	return true
+}
+
+// ServePause pauses all notifications, the notifications are dropped until resumed.
+func (h *HttpHandler) ServePause(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+func (h *HttpHandler) ServeResume(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+func (h *HttpHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	if err := h.notifierCfg.SetPaused(r.Context(), paused); err != nil {
+		h.handle(w, &response{http.StatusInternalServerError, "store pause state error, " + err.Error()})
+		return
+	}
+
+	if paused {
+		_ = level.Info(h.logger).Log("msg", "notifications are paused")
+		h.handle(w, &response{http.StatusOK, "paused"})
+	} else {
+		_ = level.Info(h.logger).Log("msg", "notifications are resumed")
+		h.handle(w, &response{http.StatusOK, "resumed"})
+	}
+}
+
 func (h *HttpHandler) handle(w http.ResponseWriter, resp *response) {
 	bytes, _ := jsoniter.Marshal(resp)
 	msg := string(bytes[:])
@@ -211,3 +338,11 @@ func (h *HttpHandler) GetReceivers(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(bs)
 	return
 }
+
+// GetReceiverStatus outputs the last send time and the last error of each receiver.
+func (h *HttpHandler) GetReceiverStatus(w http.ResponseWriter, r *http.Request) {
+
+	bs, _ := jsoniter.MarshalIndent(notifier.GetStatusRegistry().List(), "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bs)
+}
@@ -0,0 +1,216 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kit/kit/log"
+	"github.com/json-iterator/go"
+	"github.com/kubesphere/notification-manager/pkg/notify/config"
+	"github.com/kubesphere/notification-manager/pkg/notify/notifier"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHandler(webhookTimeout time.Duration) (*HttpHandler, *httptest.Server) {
+	// The worker queue is full, so the requests wait for the queue until the webhook timeout.
+	semCh := make(chan struct{}, 1)
+	semCh <- struct{}{}
+
+	h := New(log.NewNopLogger(), semCh, webhookTimeout, time.Second, nil)
+	srv := httptest.NewServer(http.HandlerFunc(h.CreateNotificationfromAlerts))
+	return h, srv
+}
+
+func post(url string) (int, error) {
+	resp, err := http.Post(url, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestShutdownWaitsForInflight(t *testing.T) {
+	h, srv := newTestHandler(300 * time.Millisecond)
+	defer srv.Close()
+
+	statusCh := make(chan int, 1)
+	go func() {
+		status, err := post(srv.URL)
+		if err != nil {
+			t.Error(err)
+		}
+		statusCh <- status
+	}()
+	// Wait for the request to be tracked.
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected shutdown to wait for the request, returned after %s", elapsed)
+	}
+
+	if status := <-statusCh; status != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, status)
+	}
+}
+
+func TestShutdownGracePeriod(t *testing.T) {
+	h := New(log.NewNopLogger(), make(chan struct{}, 1), time.Second, time.Second, nil)
+	if !h.track() {
+		t.Fatal("expected the notification to be tracked")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := h.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected shutdown to wait for the grace period, returned after %s", elapsed)
+	}
+
+	h.inflight.Done()
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected no error after the notification finished, got %s", err)
+	}
+}
+
+func TestShutdownRejectsNewRequests(t *testing.T) {
+	h, srv := newTestHandler(time.Second)
+	defer srv.Close()
+
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if h.track() {
+		t.Error("expected the notification to be rejected after shutdown")
+	}
+
+	status, err := post(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, status)
+	}
+}
+
+func TestGetReceiverStatus(t *testing.T) {
+	now := time.Now()
+	notifier.GetStatusRegistry().Record("webhook", "status-ok", now, nil)
+	notifier.GetStatusRegistry().Record("webhook", "status-failed", now, errors.New("connection refused"))
+
+	h := New(log.NewNopLogger(), make(chan struct{}, 1), time.Second, time.Second, nil)
+	srv := httptest.NewServer(http.HandlerFunc(h.GetReceiverStatus))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected content type application/json, got %s", ct)
+	}
+
+	var statuses []notifier.ReceiverStatus
+	if err := jsoniter.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]notifier.ReceiverStatus)
+	for _, s := range statuses {
+		found[s.Receiver] = s
+	}
+
+	if s, ok := found["status-ok"]; !ok || s.LastSuccessTime == nil || s.LastError != "" {
+		t.Errorf("expected the success of status-ok, got %+v", s)
+	}
+	if s, ok := found["status-failed"]; !ok || s.LastError != "connection refused" || s.LastErrorTime == nil {
+		t.Errorf("expected the error of status-failed, got %+v", s)
+	}
+}
+
+func TestServePauseResume(t *testing.T) {
+
+	scheme := runtime.NewScheme()
+	_ = v1.AddToScheme(scheme)
+	if err := os.Setenv("NAMESPACE", "kubesphere-monitoring-system"); err != nil {
+		t.Fatalf("set namespace error, %s", err)
+	}
+
+	c := fake.NewFakeClientWithScheme(scheme)
+	cfg := config.NewWithClient(context.Background(), log.NewNopLogger(), nil, c, nil)
+	cfg.SetPauseConfigMap(config.DefaultPauseConfigMap)
+	defer notifier.SetPaused(false)
+
+	h := New(log.NewNopLogger(), make(chan struct{}, 1), time.Second, time.Second, cfg)
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		adminToken func() (string, error)
+		token      string
+		status     int
+		paused     bool
+	}{
+		{"disabled", h.ServePause, nil, "admin", http.StatusForbidden, false},
+		{"no token", h.ServePause, func() (string, error) { return "admin", nil }, "", http.StatusUnauthorized, false},
+		{"wrong token", h.ServePause, func() (string, error) { return "admin", nil }, "guest", http.StatusUnauthorized, false},
+		{"empty admin token", h.ServePause, func() (string, error) { return "", nil }, "", http.StatusUnauthorized, false},
+		{"token error", h.ServePause, func() (string, error) { return "", errors.New("secret not found") }, "admin", http.StatusInternalServerError, false},
+		{"pause", h.ServePause, func() (string, error) { return "admin", nil }, "admin", http.StatusOK, true},
+		// Pausing twice keeps the notifications paused.
+		{"pause twice", h.ServePause, func() (string, error) { return "admin", nil }, "admin", http.StatusOK, true},
+		{"resume", h.ServeResume, func() (string, error) { return "admin", nil }, "admin", http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		h.SetAdminToken(tt.adminToken)
+
+		srv := httptest.NewServer(tt.handler)
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+		if len(tt.token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+		if notifier.Paused() != tt.paused {
+			t.Errorf("%s: expected paused %t, got %t", tt.name, tt.paused, notifier.Paused())
+		}
+
+		// The pause state is stored in the configmap, so that it is shared by the replicas.
+		if tt.status == http.StatusOK {
+			cm := &v1.ConfigMap{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "kubesphere-monitoring-system", Name: config.DefaultPauseConfigMap}, cm); err != nil {
+				t.Fatalf("%s: get pause configmap error, %s", tt.name, err)
+			}
+			if got := cm.Data["paused"]; got != strconv.FormatBool(tt.paused) {
+				t.Errorf("%s: expected stored pause state %t, got %q", tt.name, tt.paused, got)
+			}
+		}
+	}
+}
@@ -8,15 +8,26 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/kubesphere/notification-manager/pkg/notify/config"
 	whv1 "github.com/kubesphere/notification-manager/pkg/webhook/v1"
+	"k8s.io/api/core/v1"
 	"net/http"
+	"os"
 	"time"
 )
 
+const (
+	adminTokenKey = "token"
+)
+
 type Options struct {
 	ListenAddress  string
 	WebhookTimeout string
 	WorkerTimeout  string
 	WorkerQueue    int
+	// The maximum time to wait for the notifications being sent when shutting down.
+	ShutdownTimeout string
+	// The name of the secret which stores the bearer token of the admin endpoints in the key token,
+	// it is in the namespace which notification manager in. The admin endpoints are disabled if it is empty.
+	AdminTokenSecret string
 }
 
 type Webhook struct {
@@ -24,19 +35,31 @@ type Webhook struct {
 	options *Options
 	logger  log.Logger
 	handler *whv1.HttpHandler
+	// The maximum time to wait for the notifications being sent when shutting down.
+	shutdownTimeout time.Duration
 }
 
 func New(logger log.Logger, notifierCfg *config.Config, o *Options) *Webhook {
 	webhookTimeout, _ := time.ParseDuration(o.WebhookTimeout)
 	wkrTimeout, _ := time.ParseDuration(o.WorkerTimeout)
+	shutdownTimeout, _ := time.ParseDuration(o.ShutdownTimeout)
 
 	h := &Webhook{
-		options: o,
-		logger:  logger,
+		options:         o,
+		logger:          logger,
+		shutdownTimeout: shutdownTimeout,
 	}
 
 	semCh := make(chan struct{}, h.options.WorkerQueue)
 	h.handler = whv1.New(logger, semCh, webhookTimeout, wkrTimeout, notifierCfg)
+	if len(o.AdminTokenSecret) > 0 {
+		h.handler.SetAdminToken(func() (string, error) {
+			return notifierCfg.GetSecretData(os.Getenv("NAMESPACE"), &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: o.AdminTokenSecret},
+				Key:                  adminTokenKey,
+			})
+		})
+	}
 	h.router = chi.NewRouter()
 
 	h.router.Use(middleware.RequestID)
@@ -44,11 +67,14 @@ func New(logger log.Logger, notifierCfg *config.Config, o *Options) *Webhook {
 	h.router.Use(middleware.Recoverer)
 	h.router.Use(middleware.Timeout(2 * webhookTimeout))
 	h.router.Get("/receivers", h.handler.GetReceivers)
+	h.router.Get("/receivers/status", h.handler.GetReceiverStatus)
 	h.router.Post("/api/v2/alerts", h.handler.CreateNotificationfromAlerts)
 	h.router.Get("/metrics", h.handler.ServeMetrics)
 	h.router.Get("/-/reload", h.handler.ServeReload)
 	h.router.Get("/-/ready", h.handler.ServeHealthCheck)
 	h.router.Get("/-/live", h.handler.ServeReadinessCheck)
+	h.router.Post("/-/pause", h.handler.ServePause)
+	h.router.Post("/-/resume", h.handler.ServeResume)
 	h.router.Get("/status", h.handler.ServeStatus)
 
 	return h
@@ -66,11 +92,19 @@ func (h *Webhook) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			// We received an interrupt signal, shut down.
-			if err := httpSrv.Shutdown(ctx); err != nil {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), h.shutdownTimeout)
+			defer cancel()
+			if err := httpSrv.Shutdown(shutdownCtx); err != nil {
 				// Error from closing listeners, or context timeout:
 				_ = level.Error(h.logger).Log("msg", "Shutdown HTTP server", "err", err)
 			}
 			_ = level.Info(h.logger).Log("msg", "Shutdown HTTP server")
+
+			// Wait for the notifications being sent, they will be abandoned if the timeout is reached.
+			if err := h.handler.Shutdown(shutdownCtx); err != nil {
+				_ = level.Error(h.logger).Log("msg", "Wait for the notifications being sent", "err", err)
+			}
+			_ = level.Info(h.logger).Log("msg", "All notifications sent")
 			close(srvClosed)
 		}
 	}()